@@ -0,0 +1,95 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// Audit operations are the kinds of operation of an AuditEvent.
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+	AuditRead   = "read"
+)
+
+// Audit outcomes are the outcomes of the operation of an AuditEvent.
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+	AuditDenied  = "denied"
+)
+
+// AuditEvent records who performed an operation on a resource, when, from
+// where, and its outcome.
+type AuditEvent struct {
+	Time time.Time `json:"time"`
+
+	// UserID is the user that performed the operation, and AuthorizationID
+	// the token it used, if it used one.
+	UserID          *ID `json:"userID,omitempty"`
+	AuthorizationID *ID `json:"authorizationID,omitempty"`
+
+	// Operation is one of the audit operations, and Method and Path the API
+	// request of the operation.
+	Operation    string       `json:"operation"`
+	Method       string       `json:"method"`
+	Path         string       `json:"path"`
+	OrgID        *ID          `json:"orgID,omitempty"`
+	ResourceType ResourceType `json:"resourceType,omitempty"`
+	ResourceID   *ID          `json:"resourceID,omitempty"`
+
+	RemoteAddr string `json:"remoteAddr,omitempty"`
+	UserAgent  string `json:"userAgent,omitempty"`
+
+	// Outcome is one of the audit outcomes, and StatusCode the status of the
+	// response to the request.
+	Outcome    string `json:"outcome"`
+	StatusCode int    `json:"statusCode"`
+
+	// Permission is the permission that was denied, for denied operations.
+	Permission *Permission `json:"permission,omitempty"`
+}
+
+// AuditEventFilter represents a set of filters that restrict the returned audit events.
+type AuditEventFilter struct {
+	OrgID        *ID
+	UserID       *ID
+	ResourceType *ResourceType
+	ResourceID   *ID
+	Outcome      *string
+
+	// Since and Until restrict the events to those in the time range [Since, Until).
+	Since *time.Time
+	Until *time.Time
+}
+
+// Match reports whether ev passes the filter.
+func (f AuditEventFilter) Match(ev *AuditEvent) bool {
+	switch {
+	case f.OrgID != nil && (ev.OrgID == nil || *ev.OrgID != *f.OrgID):
+		return false
+	case f.UserID != nil && (ev.UserID == nil || *ev.UserID != *f.UserID):
+		return false
+	case f.ResourceType != nil && ev.ResourceType != *f.ResourceType:
+		return false
+	case f.ResourceID != nil && (ev.ResourceID == nil || *ev.ResourceID != *f.ResourceID):
+		return false
+	case f.Outcome != nil && ev.Outcome != *f.Outcome:
+		return false
+	case f.Since != nil && ev.Time.Before(*f.Since):
+		return false
+	case f.Until != nil && !ev.Time.Before(*f.Until):
+		return false
+	}
+	return true
+}
+
+// AuditService records and finds audit events.
+type AuditService interface {
+	// LogAuditEvent records an audit event.
+	LogAuditEvent(ctx context.Context, ev *AuditEvent) error
+
+	// FindAuditEvents returns the audit events that match filter, oldest first.
+	FindAuditEvents(ctx context.Context, filter AuditEventFilter, opt ...FindOptions) ([]*AuditEvent, int, error)
+}
@@ -0,0 +1,161 @@
+// Package audit records who performed the operations of the API, on which
+// resources, and with what outcome.
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.AuditService = (*FileService)(nil)
+
+// FileService is an AuditService that appends audit events to a file, one
+// JSON object per line. Events are never updated nor removed from the file.
+type FileService struct {
+	path string
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewFileService opens the audit log at path, and creates it if it does not exist.
+func NewFileService(path string) (*FileService, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileService{path: path, f: f}, nil
+}
+
+// Close closes the audit log.
+func (s *FileService) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+// LogAuditEvent appends ev to the audit log.
+func (s *FileService) LogAuditEvent(ctx context.Context, ev *influxdb.AuditEvent) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.f.Write(b)
+	return err
+}
+
+// FindAuditEvents scans the audit log for the events that match filter. The
+// log is streamed, and only the events of the requested page are kept, along
+// with the events past the offset of the page when it is descending.
+func (s *FileService) FindAuditEvents(ctx context.Context, filter influxdb.AuditEventFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	var o influxdb.FindOptions
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	p := newPage(o)
+
+	var n int
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// a partially written event is not part of the log
+			break
+		} else if err != nil {
+			return nil, 0, err
+		}
+
+		var ev influxdb.AuditEvent
+		if err := json.Unmarshal(line, &ev); err != nil {
+			return nil, 0, &influxdb.Error{
+				Code: influxdb.EInternal,
+				Msg:  "audit log is corrupt",
+				Err:  err,
+			}
+		}
+		if filter.Match(&ev) {
+			p.add(n, &ev)
+			n++
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return p.events(), n, nil
+}
+
+// page keeps the events of a page of the matching events of the log, which
+// are oldest first.
+type page struct {
+	opts influxdb.FindOptions
+
+	// evs are the events of an ascending page, or the most recent events of a
+	// descending page, in a ring of the size of the page and its offset.
+	evs  []*influxdb.AuditEvent
+	size int
+	next int
+}
+
+func newPage(opts influxdb.FindOptions) *page {
+	p := &page{opts: opts, evs: []*influxdb.AuditEvent{}}
+	if opts.Descending && opts.Limit > 0 {
+		p.size = opts.Offset + opts.Limit
+	}
+	return p
+}
+
+// add adds the i-th matching event ev to the page, if it is part of it.
+func (p *page) add(i int, ev *influxdb.AuditEvent) {
+	if !p.opts.Descending {
+		if i < p.opts.Offset || (p.opts.Limit > 0 && i >= p.opts.Offset+p.opts.Limit) {
+			return
+		}
+		p.evs = append(p.evs, ev)
+		return
+	}
+
+	if p.size == 0 || len(p.evs) < p.size {
+		p.evs = append(p.evs, ev)
+		return
+	}
+	p.evs[p.next] = ev
+	p.next = (p.next + 1) % p.size
+}
+
+// events returns the events of the page, in its order.
+func (p *page) events() []*influxdb.AuditEvent {
+	if !p.opts.Descending {
+		return p.evs
+	}
+
+	// the most recent events, oldest first, then newest first
+	evs := append(p.evs[p.next:len(p.evs):len(p.evs)], p.evs[:p.next]...)
+	for i, j := 0, len(evs)-1; i < j; i, j = i+1, j-1 {
+		evs[i], evs[j] = evs[j], evs[i]
+	}
+	if p.opts.Offset >= len(evs) {
+		return []*influxdb.AuditEvent{}
+	}
+	evs = evs[p.opts.Offset:]
+	if p.opts.Limit > 0 && p.opts.Limit < len(evs) {
+		evs = evs[:p.opts.Limit]
+	}
+	return evs
+}
@@ -0,0 +1,131 @@
+package audit_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/audit"
+)
+
+func TestFileService(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	svc, err := audit.NewFileService(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	orgA, orgB := influxdb.ID(1), influxdb.ID(2)
+	for i, ev := range []*influxdb.AuditEvent{
+		{OrgID: &orgA, Operation: influxdb.AuditCreate, Outcome: influxdb.AuditSuccess},
+		{OrgID: &orgB, Operation: influxdb.AuditUpdate, Outcome: influxdb.AuditDenied},
+		{OrgID: &orgA, Operation: influxdb.AuditDelete, Outcome: influxdb.AuditFailure},
+	} {
+		ev.Time = time.Unix(int64(i), 0).UTC()
+		if err := svc.LogAuditEvent(ctx, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := svc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the log is appended to once it is reopened
+	svc, err = audit.NewFileService(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer svc.Close()
+	if err := svc.LogAuditEvent(ctx, &influxdb.AuditEvent{Time: time.Unix(3, 0).UTC(), OrgID: &orgA, Operation: influxdb.AuditCreate}); err != nil {
+		t.Fatal(err)
+	}
+
+	operations := func(evs []*influxdb.AuditEvent) []string {
+		ops := make([]string, 0, len(evs))
+		for _, ev := range evs {
+			ops = append(ops, ev.Operation)
+		}
+		return ops
+	}
+	for _, tt := range []struct {
+		name   string
+		filter influxdb.AuditEventFilter
+		opts   []influxdb.FindOptions
+		exp    []string
+		total  int
+	}{
+		{
+			name:  "all",
+			exp:   []string{"create", "update", "delete", "create"},
+			total: 4,
+		},
+		{
+			name:   "org",
+			filter: influxdb.AuditEventFilter{OrgID: &orgA},
+			exp:    []string{"create", "delete", "create"},
+			total:  3,
+		},
+		{
+			name:   "since",
+			filter: influxdb.AuditEventFilter{Since: timePtr(time.Unix(1, 0))},
+			opts:   []influxdb.FindOptions{{Descending: true, Limit: 2}},
+			exp:    []string{"create", "delete"},
+			total:  3,
+		},
+		{
+			name:  "page",
+			opts:  []influxdb.FindOptions{{Offset: 1, Limit: 2}},
+			exp:   []string{"update", "delete"},
+			total: 4,
+		},
+		{
+			name:  "descending page",
+			opts:  []influxdb.FindOptions{{Descending: true, Offset: 1, Limit: 2}},
+			exp:   []string{"delete", "update"},
+			total: 4,
+		},
+		{
+			name:  "descending",
+			opts:  []influxdb.FindOptions{{Descending: true}},
+			exp:   []string{"create", "delete", "update", "create"},
+			total: 4,
+		},
+		{
+			name:  "past the end",
+			opts:  []influxdb.FindOptions{{Offset: 4, Limit: 2}},
+			exp:   []string{},
+			total: 4,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			evs, n, err := svc.FindAuditEvents(ctx, tt.filter, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := operations(evs); n != tt.total || len(got) != len(tt.exp) {
+				t.Fatalf("expected %d of %d events %v, got %d of %d %v", len(tt.exp), tt.total, tt.exp, len(got), n, got)
+			} else {
+				for i := range got {
+					if got[i] != tt.exp[i] {
+						t.Fatalf("expected events %v, got %v", tt.exp, got)
+					}
+				}
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
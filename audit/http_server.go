@@ -0,0 +1,139 @@
+package audit
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+// AuditHandler serves the audit events of an AuditService.
+type AuditHandler struct {
+	chi.Router
+	api *kithttp.API
+	log *zap.Logger
+	svc influxdb.AuditService
+}
+
+// NewHTTPAuditHandler constructs a new http server.
+func NewHTTPAuditHandler(log *zap.Logger, svc influxdb.AuditService) *AuditHandler {
+	h := &AuditHandler{
+		api: kithttp.NewAPI(kithttp.WithLog(log)),
+		log: log,
+		svc: svc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Get("/", h.handleGetAuditEvents)
+
+	h.Router = r
+	return h
+}
+
+const prefixAudit = "/api/v2/audit"
+
+func (h *AuditHandler) Prefix() string {
+	return prefixAudit
+}
+
+type auditEventsResponse struct {
+	Events []*influxdb.AuditEvent `json:"events"`
+}
+
+// handleGetAuditEvents is the HTTP handler for the GET /api/v2/audit route.
+func (h *AuditHandler) handleGetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	filter, err := decodeAuditEventFilter(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	opts, err := influxdb.DecodeFindOptions(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	evs, _, err := h.svc.FindAuditEvents(r.Context(), filter, *opts)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, auditEventsResponse{Events: evs})
+}
+
+func decodeAuditEventFilter(r *http.Request) (influxdb.AuditEventFilter, error) {
+	var filter influxdb.AuditEventFilter
+	qp := r.URL.Query()
+
+	for _, f := range []struct {
+		param string
+		id    **influxdb.ID
+	}{
+		{"orgID", &filter.OrgID},
+		{"userID", &filter.UserID},
+		{"resourceID", &filter.ResourceID},
+	} {
+		if v := qp.Get(f.param); v != "" {
+			id, err := influxdb.IDFromString(v)
+			if err != nil {
+				return filter, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  f.param + " is invalid",
+					Err:  err,
+				}
+			}
+			*f.id = id
+		}
+	}
+
+	if v := qp.Get("resourceType"); v != "" {
+		rt := influxdb.ResourceType(v)
+		if err := rt.Valid(); err != nil {
+			return filter, err
+		}
+		filter.ResourceType = &rt
+	}
+	if v := qp.Get("outcome"); v != "" {
+		switch v {
+		case influxdb.AuditSuccess, influxdb.AuditFailure, influxdb.AuditDenied:
+		default:
+			return filter, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "outcome must be one of success, failure or denied",
+			}
+		}
+		filter.Outcome = &v
+	}
+
+	for _, f := range []struct {
+		param string
+		t     **time.Time
+	}{
+		{"since", &filter.Since},
+		{"until", &filter.Until},
+	} {
+		if v := qp.Get(f.param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  f.param + " must be a RFC3339 time",
+					Err:  err,
+				}
+			}
+			*f.t = &t
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,185 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+type requestCtxKey struct{}
+
+// request is who performed an audited request, once it is authenticated, and
+// the permission most recently denied to it.
+type request struct {
+	mu     sync.Mutex
+	a      influxdb.Authorizer
+	denied *influxdb.Permission
+}
+
+// Denied records that the permission p was denied to the request of ctx, so
+// that the audit event of the request names the permission when the request
+// fails for it. It does nothing outside of requests that are audited.
+func Denied(ctx context.Context, p influxdb.Permission) {
+	req, ok := ctx.Value(requestCtxKey{}).(*request)
+	if !ok {
+		return
+	}
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	req.denied = &p
+}
+
+// Authenticated records who performed the audited requests it serves, from
+// the authorizer the authentication of the request sets on its context. It
+// must run after the request is authenticated, inside of Middleware.
+func Authenticated(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if req, ok := r.Context().Value(requestCtxKey{}).(*request); ok {
+			if a, err := icontext.GetAuthorizer(r.Context()); err == nil {
+				req.mu.Lock()
+				req.a = a
+				req.mu.Unlock()
+			}
+		}
+		next.ServeHTTP(w, r)
+	}
+	return http.HandlerFunc(fn)
+}
+
+func (req *request) authorizer() influxdb.Authorizer {
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	return req.a
+}
+
+func (req *request) permission() *influxdb.Permission {
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	return req.denied
+}
+
+// Middleware records an audit event for every API request that creates,
+// updates or deletes a resource, and for every request that is denied. It
+// must run before the request is authenticated, so that the requests that
+// fail to authenticate are audited, with Authenticated running after the
+// authentication to know who performed the requests.
+func Middleware(log *zap.Logger, svc influxdb.AuditService) kithttp.Middleware {
+	return func(next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			req := &request{}
+			r = r.WithContext(context.WithValue(r.Context(), requestCtxKey{}, req))
+			statusW := kithttp.NewStatusResponseWriter(w)
+
+			next.ServeHTTP(statusW, r)
+
+			a := req.authorizer()
+			if a == nil {
+				a, _ = icontext.GetAuthorizer(r.Context())
+			}
+			ev := newEvent(r, a, statusW.Code())
+			if ev.Outcome != influxdb.AuditDenied && (ev.Operation == influxdb.AuditRead || isDataPath(r.URL.Path)) {
+				return
+			}
+			if ev.Outcome == influxdb.AuditDenied {
+				ev.Permission = req.permission()
+			}
+			if err := svc.LogAuditEvent(r.Context(), ev); err != nil {
+				log.Error("Failed to record audit event", zap.String("method", ev.Method), zap.String("path", ev.Path), zap.Error(err))
+			}
+		}
+		return http.HandlerFunc(fn)
+	}
+}
+
+// isDataPath reports whether path writes or queries data, rather than
+// resources, which is only audited when it is denied.
+func isDataPath(path string) bool {
+	for _, prefix := range []string{"/api/v2/write", "/api/v2/query", "/write", "/query"} {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// newEvent creates the audit event of the request r, which a performed, if
+// it was authenticated, and was responded to with code.
+func newEvent(r *http.Request, a influxdb.Authorizer, code int) *influxdb.AuditEvent {
+	ev := &influxdb.AuditEvent{
+		Time:       time.Now().UTC(),
+		Operation:  operation(r.Method),
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		UserAgent:  r.UserAgent(),
+		StatusCode: code,
+		Outcome:    outcome(code),
+	}
+
+	if a != nil {
+		if id := a.GetUserID(); id.Valid() {
+			ev.UserID = &id
+		}
+		if auth, ok := a.(*influxdb.Authorization); ok {
+			id, orgID := auth.ID, auth.OrgID
+			if id.Valid() {
+				ev.AuthorizationID = &id
+			}
+			if orgID.Valid() {
+				ev.OrgID = &orgID
+			}
+		}
+	}
+	if orgID, err := influxdb.IDFromString(r.URL.Query().Get("orgID")); err == nil {
+		ev.OrgID = orgID
+	}
+
+	// the resource is the last resource type in the path, followed by its ID
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v2/"), "/")
+	for i, seg := range segments {
+		rt := influxdb.ResourceType(seg)
+		if rt.Valid() != nil {
+			continue
+		}
+		ev.ResourceType, ev.ResourceID = rt, nil
+		if i+1 >= len(segments) {
+			continue
+		}
+		if id, err := influxdb.IDFromString(segments[i+1]); err == nil {
+			ev.ResourceID = id
+			if rt == influxdb.OrgsResourceType {
+				ev.OrgID = id
+			}
+		}
+	}
+	return ev
+}
+
+func operation(method string) string {
+	switch method {
+	case http.MethodPost:
+		return influxdb.AuditCreate
+	case http.MethodPut, http.MethodPatch:
+		return influxdb.AuditUpdate
+	case http.MethodDelete:
+		return influxdb.AuditDelete
+	}
+	return influxdb.AuditRead
+}
+
+func outcome(code int) string {
+	switch {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return influxdb.AuditDenied
+	case code >= http.StatusBadRequest:
+		return influxdb.AuditFailure
+	}
+	return influxdb.AuditSuccess
+}
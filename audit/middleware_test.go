@@ -0,0 +1,124 @@
+package audit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/audit"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap/zaptest"
+)
+
+type recordingService struct {
+	audit.NopService
+	events []*influxdb.AuditEvent
+}
+
+func (s *recordingService) LogAuditEvent(ctx context.Context, ev *influxdb.AuditEvent) error {
+	s.events = append(s.events, ev)
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	orgID, bucketID := influxdb.ID(1), influxdb.ID(2)
+	auth := &influxdb.Authorization{
+		ID:          influxdb.ID(3),
+		UserID:      influxdb.ID(4),
+		OrgID:       orgID,
+		Status:      influxdb.Active,
+		Permissions: influxdb.ReadAllPermissions(),
+	}
+
+	svc := &recordingService{}
+	// the handler writes the bucket of the request
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, err := authorizer.AuthorizeWrite(r.Context(), influxdb.BucketsResourceType, bucketID, orgID); err != nil && r.Method != http.MethodGet {
+			kithttp.ErrorHandler(0).HandleHTTPError(r.Context(), err, w)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mw := audit.Middleware(zaptest.NewLogger(t), svc)(handler)
+
+	serve := func(method, path string) {
+		t.Helper()
+		r := httptest.NewRequest(method, path, nil)
+		r = r.WithContext(icontext.SetAuthorizer(r.Context(), auth))
+		mw.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	// reads and writes of data are not audited unless they are denied
+	serve(http.MethodGet, "/api/v2/buckets/"+bucketID.String())
+	serve(http.MethodPost, "/api/v2/write?orgID="+orgID.String())
+	if len(svc.events) != 1 {
+		t.Fatalf("expected only the denied write to be audited, got %d events", len(svc.events))
+	}
+
+	serve(http.MethodPatch, "/api/v2/buckets/"+bucketID.String())
+	if len(svc.events) != 2 {
+		t.Fatalf("expected the denied update to be audited, got %d events", len(svc.events))
+	}
+
+	ev := svc.events[1]
+	if ev.Operation != influxdb.AuditUpdate || ev.Outcome != influxdb.AuditDenied || ev.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("unexpected operation %q, outcome %q or status %d", ev.Operation, ev.Outcome, ev.StatusCode)
+	}
+	if ev.ResourceType != influxdb.BucketsResourceType || ev.ResourceID == nil || *ev.ResourceID != bucketID {
+		t.Fatalf("unexpected resource %s %v", ev.ResourceType, ev.ResourceID)
+	}
+	if ev.UserID == nil || *ev.UserID != auth.UserID || ev.AuthorizationID == nil || *ev.AuthorizationID != auth.ID || ev.OrgID == nil || *ev.OrgID != orgID {
+		t.Fatalf("unexpected user %v, authorization %v or org %v", ev.UserID, ev.AuthorizationID, ev.OrgID)
+	}
+	if ev.Permission == nil || ev.Permission.Action != influxdb.WriteAction || ev.Permission.Resource.Type != influxdb.BucketsResourceType {
+		t.Fatalf("expected the denied permission, got %v", ev.Permission)
+	}
+}
+
+func TestMiddleware_Unauthenticated(t *testing.T) {
+	auth := &influxdb.Authorization{
+		ID:          influxdb.ID(3),
+		UserID:      influxdb.ID(4),
+		OrgID:       influxdb.ID(1),
+		Status:      influxdb.Active,
+		Permissions: influxdb.ReadAllPermissions(),
+	}
+
+	svc := &recordingService{}
+	// the authentication rejects the requests without a token, and the
+	// handler behind it creates resources
+	handler := audit.Authenticated(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	authn := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r.WithContext(icontext.SetAuthorizer(r.Context(), auth)))
+	})
+	mw := audit.Middleware(zaptest.NewLogger(t), svc)(authn)
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v2/buckets", nil)
+	mw.ServeHTTP(httptest.NewRecorder(), r)
+	if len(svc.events) != 1 {
+		t.Fatalf("expected the unauthenticated request to be audited, got %d events", len(svc.events))
+	}
+	if ev := svc.events[0]; ev.Outcome != influxdb.AuditDenied || ev.UserID != nil {
+		t.Fatalf("unexpected outcome %q or user %v", ev.Outcome, ev.UserID)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/api/v2/buckets", nil)
+	r.Header.Set("Authorization", "Token secret")
+	mw.ServeHTTP(httptest.NewRecorder(), r)
+	if len(svc.events) != 2 {
+		t.Fatalf("expected the created bucket to be audited, got %d events", len(svc.events))
+	}
+	if ev := svc.events[1]; ev.Outcome != influxdb.AuditSuccess || ev.UserID == nil || *ev.UserID != auth.UserID {
+		t.Fatalf("unexpected outcome %q or user %v", ev.Outcome, ev.UserID)
+	}
+}
@@ -0,0 +1,22 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.AuditService = NopService{}
+
+// NopService is an AuditService that records no audit events.
+type NopService struct{}
+
+// LogAuditEvent does not record ev.
+func (NopService) LogAuditEvent(ctx context.Context, ev *influxdb.AuditEvent) error {
+	return nil
+}
+
+// FindAuditEvents finds no audit events.
+func (NopService) FindAuditEvents(ctx context.Context, filter influxdb.AuditEventFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+	return []*influxdb.AuditEvent{}, 0, nil
+}
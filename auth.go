@@ -3,6 +3,7 @@ package influxdb
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 // AuthorizationKind is returned by (*Authorization).Kind().
@@ -14,6 +15,15 @@ var ErrUnableToCreateToken = &Error{
 	Code: EInvalid,
 }
 
+const (
+	// ErrAuthorizationExpired is the error message for expired authorizations.
+	ErrAuthorizationExpired = "token has expired"
+
+	// ErrAuthorizationNotYetValid is the error message for authorizations used
+	// before the time they become valid.
+	ErrAuthorizationNotYetValid = "token is not yet valid"
+)
+
 // Authorization is an authorization. 🎉
 type Authorization struct {
 	ID          ID           `json:"id"`
@@ -24,6 +34,25 @@ type Authorization struct {
 	UserID      ID           `json:"userID,omitempty"`
 	Permissions []Permission `json:"permissions"`
 	CRUDLog
+
+	// TokenPrefix is the start of the token, which identifies the token once
+	// it is no longer returned. It is not secret.
+	TokenPrefix string `json:"tokenPrefix,omitempty"`
+
+	// TokenHash is the salted hash of the token, which is stored in place of
+	// the token.
+	TokenHash string `json:"-"`
+
+	// NotBefore is the time the authorization becomes valid, if it is not
+	// valid once it is created.
+	NotBefore *time.Time `json:"notBefore,omitempty"`
+
+	// ExpiresAt is the time the authorization is no longer valid, if it expires.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+
+	// RolePermissions are the permissions of the roles of the authorization,
+	// which are looked up as the authorization is used rather than stored.
+	RolePermissions []Permission `json:"-"`
 }
 
 // AuthorizationUpdate is the authorization update request.
@@ -43,9 +72,45 @@ func (a *Authorization) Valid() error {
 		}
 	}
 
+	if a.NotBefore != nil && a.ExpiresAt != nil && !a.NotBefore.Before(*a.ExpiresAt) {
+		return &Error{
+			Msg:  "authorization must expire after it becomes valid",
+			Code: EInvalid,
+		}
+	}
+
 	return nil
 }
 
+// Expired returns an error if the authorization is used outside of the time
+// it is valid.
+func (a *Authorization) Expired() error {
+	return a.ExpiredAt(time.Now())
+}
+
+// ExpiredAt returns an error if the authorization is not valid at now.
+func (a *Authorization) ExpiredAt(now time.Time) error {
+	if a.ExpiresAt != nil && !now.Before(*a.ExpiresAt) {
+		return &Error{
+			Code: EUnauthorized,
+			Msg:  ErrAuthorizationExpired,
+		}
+	}
+	if a.NotBefore != nil && now.Before(*a.NotBefore) {
+		return &Error{
+			Code: EUnauthorized,
+			Msg:  ErrAuthorizationNotYetValid,
+		}
+	}
+
+	return nil
+}
+
+// ExpiresBefore reports whether the authorization expires before t.
+func (a *Authorization) ExpiresBefore(t time.Time) bool {
+	return a.ExpiresAt != nil && a.ExpiresAt.Before(t)
+}
+
 // PermissionSet returns the set of permissions associated with the Authorization.
 func (a *Authorization) PermissionSet() (PermissionSet, error) {
 	if !a.IsActive() {
@@ -54,8 +119,16 @@ func (a *Authorization) PermissionSet() (PermissionSet, error) {
 			Msg:  "token is inactive",
 		}
 	}
+	if err := a.Expired(); err != nil {
+		return nil, err
+	}
 
-	return a.Permissions, nil
+	if len(a.RolePermissions) == 0 {
+		return a.Permissions, nil
+	}
+	ps := make(PermissionSet, 0, len(a.Permissions)+len(a.RolePermissions))
+	ps = append(ps, a.Permissions...)
+	return append(ps, a.RolePermissions...), nil
 }
 
 // IsActive is a stub for idpe.
@@ -121,4 +194,8 @@ type AuthorizationFilter struct {
 
 	OrgID *ID
 	Org   *string
+
+	// ExpiresBefore restricts the results to the authorizations that expire
+	// before the time.
+	ExpiresBefore *time.Time
 }
@@ -0,0 +1,55 @@
+package influxdb_test
+
+import (
+	"testing"
+	"time"
+
+	platform "github.com/influxdata/influxdb/v2"
+)
+
+func TestAuthorization_ExpiredAt(t *testing.T) {
+	now := time.Unix(1000, 0)
+	at := func(sec int64) *time.Time {
+		t := time.Unix(sec, 0)
+		return &t
+	}
+
+	tests := []struct {
+		name string
+		auth platform.Authorization
+		msg  string
+	}{
+		{
+			name: "no expiry",
+		},
+		{
+			name: "not expired",
+			auth: platform.Authorization{NotBefore: at(900), ExpiresAt: at(1100)},
+		},
+		{
+			name: "expired",
+			auth: platform.Authorization{ExpiresAt: at(1000)},
+			msg:  platform.ErrAuthorizationExpired,
+		},
+		{
+			name: "not yet valid",
+			auth: platform.Authorization{NotBefore: at(1001)},
+			msg:  platform.ErrAuthorizationNotYetValid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.auth.ExpiredAt(now)
+			if tt.msg == "" {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+			if platform.ErrorCode(err) != platform.EUnauthorized || platform.ErrorMessage(err) != tt.msg {
+				t.Fatalf("expected unauthorized error %q, got %v", tt.msg, err)
+			}
+		})
+	}
+}
@@ -131,11 +131,14 @@ type postAuthorizationRequest struct {
 	UserID      *influxdb.ID          `json:"userID,omitempty"`
 	Description string                `json:"description"`
 	Permissions []influxdb.Permission `json:"permissions"`
+	NotBefore   *time.Time            `json:"notBefore,omitempty"`
+	ExpiresAt   *time.Time            `json:"expiresAt,omitempty"`
 }
 
 type authResponse struct {
 	ID          influxdb.ID          `json:"id"`
 	Token       string               `json:"token"`
+	TokenPrefix string               `json:"tokenPrefix,omitempty"`
 	Status      influxdb.Status      `json:"status"`
 	Description string               `json:"description"`
 	OrgID       influxdb.ID          `json:"orgID"`
@@ -146,6 +149,8 @@ type authResponse struct {
 	Links       map[string]string    `json:"links"`
 	CreatedAt   time.Time            `json:"createdAt"`
 	UpdatedAt   time.Time            `json:"updatedAt"`
+	NotBefore   *time.Time           `json:"notBefore,omitempty"`
+	ExpiresAt   *time.Time           `json:"expiresAt,omitempty"`
 }
 
 // In the future, we would like only the service layer to look up the user and org to see if they are valid
@@ -165,6 +170,7 @@ func (h *AuthHandler) newAuthResponse(ctx context.Context, a *influxdb.Authoriza
 	res := &authResponse{
 		ID:          a.ID,
 		Token:       a.Token,
+		TokenPrefix: a.TokenPrefix,
 		Status:      a.Status,
 		Description: a.Description,
 		OrgID:       a.OrgID,
@@ -178,6 +184,8 @@ func (h *AuthHandler) newAuthResponse(ctx context.Context, a *influxdb.Authoriza
 		},
 		CreatedAt: a.CreatedAt,
 		UpdatedAt: a.UpdatedAt,
+		NotBefore: a.NotBefore,
+		ExpiresAt: a.ExpiresAt,
 	}
 	return res, nil
 }
@@ -189,6 +197,8 @@ func (p *postAuthorizationRequest) toInfluxdb(userID influxdb.ID) *influxdb.Auth
 		Description: p.Description,
 		Permissions: p.Permissions,
 		UserID:      userID,
+		NotBefore:   p.NotBefore,
+		ExpiresAt:   p.ExpiresAt,
 	}
 }
 
@@ -196,6 +206,7 @@ func (a *authResponse) toInfluxdb() *influxdb.Authorization {
 	res := &influxdb.Authorization{
 		ID:          a.ID,
 		Token:       a.Token,
+		TokenPrefix: a.TokenPrefix,
 		Status:      a.Status,
 		Description: a.Description,
 		OrgID:       a.OrgID,
@@ -204,6 +215,8 @@ func (a *authResponse) toInfluxdb() *influxdb.Authorization {
 			CreatedAt: a.CreatedAt,
 			UpdatedAt: a.UpdatedAt,
 		},
+		NotBefore: a.NotBefore,
+		ExpiresAt: a.ExpiresAt,
 	}
 	for _, p := range a.Permissions {
 		res.Permissions = append(res.Permissions, influxdb.Permission{Action: p.Action, Resource: p.Resource.Resource})
@@ -232,6 +245,8 @@ func newPostAuthorizationRequest(a *influxdb.Authorization) (*postAuthorizationR
 		Description: a.Description,
 		Permissions: a.Permissions,
 		Status:      a.Status,
+		NotBefore:   a.NotBefore,
+		ExpiresAt:   a.ExpiresAt,
 	}
 
 	if a.UserID.Valid() {
@@ -451,6 +466,18 @@ func decodeGetAuthorizationsRequest(ctx context.Context, r *http.Request) (*getA
 		req.filter.ID = id
 	}
 
+	if expiresBefore := qp.Get("expiresBefore"); expiresBefore != "" {
+		t, err := time.Parse(time.RFC3339, expiresBefore)
+		if err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "expiresBefore must be a RFC3339 time",
+				Err:  err,
+			}
+		}
+		req.filter.ExpiresBefore = &t
+	}
+
 	return req, nil
 }
 
@@ -2,7 +2,6 @@ package authorization
 
 import (
 	"context"
-	"encoding/json"
 
 	"github.com/buger/jsonparser"
 	influxdb "github.com/influxdata/influxdb/v2"
@@ -10,10 +9,6 @@ import (
 	jsonp "github.com/influxdata/influxdb/v2/pkg/jsonparser"
 )
 
-func authIndexKey(n string) []byte {
-	return []byte(n)
-}
-
 func authIndexBucket(tx kv.Tx) (kv.Bucket, error) {
 	b, err := tx.Bucket([]byte(authIndex))
 	if err != nil {
@@ -35,11 +30,14 @@ func encodeAuthorization(a *influxdb.Authorization) ([]byte, error) {
 		}
 	}
 
-	return json.Marshal(a)
+	if err := kv.HashAuthorizationToken(a); err != nil {
+		return nil, err
+	}
+	return kv.MarshalAuthorization(a)
 }
 
 func decodeAuthorization(b []byte, a *influxdb.Authorization) error {
-	if err := json.Unmarshal(b, a); err != nil {
+	if err := kv.UnmarshalAuthorization(b, a); err != nil {
 		return err
 	}
 	if a.Status == "" {
@@ -88,7 +86,11 @@ func (s *Store) CreateAuthorization(ctx context.Context, tx kv.Tx, a *influxdb.A
 		return err
 	}
 
-	if err := idx.Put(authIndexKey(a.Token), encodedID); err != nil {
+	key, err := kv.AuthTokenIndexKey(a.TokenPrefix, a.ID)
+	if err != nil {
+		return ErrInvalidAuthIDError(err)
+	}
+	if err := idx.Put(key, encodedID); err != nil {
 		return &influxdb.Error{
 			Code: influxdb.EInternal,
 			Err:  err,
@@ -142,29 +144,19 @@ func (s *Store) GetAuthorizationByID(ctx context.Context, tx kv.Tx, id influxdb.
 }
 
 func (s *Store) GetAuthorizationByToken(ctx context.Context, tx kv.Tx, token string) (*influxdb.Authorization, error) {
-	idx, err := authIndexBucket(tx)
+	// use the token to look up the authorization's ID
+	id, err := kv.FindAuthorizationIDByToken(tx, token)
 	if err != nil {
 		return nil, err
 	}
 
-	// use the token to look up the authorization's ID
-	idKey, err := idx.Get(authIndexKey(token))
-	if kv.IsNotFound(err) {
-		return nil, &influxdb.Error{
-			Code: influxdb.ENotFound,
-			Msg:  "authorization not found",
-		}
-	}
-
-	var id influxdb.ID
-	if err := id.Decode(idKey); err != nil {
-		return nil, &influxdb.Error{
-			Code: influxdb.EInvalid,
-			Err:  err,
-		}
+	a, err := s.GetAuthorizationByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
 	}
-
-	return s.GetAuthorizationByID(ctx, tx, id)
+	// only the hash of the token is stored, but the caller knows the token
+	a.Token = token
+	return a, nil
 }
 
 // ListAuthorizations returns all the authorizations matching a set of FindOptions. This function is used for
@@ -243,7 +235,11 @@ func (s *Store) UpdateAuthorization(ctx context.Context, tx kv.Tx, id influxdb.I
 		return nil, err
 	}
 
-	if err := idx.Put(authIndexKey(a.Token), encodedID); err != nil {
+	key, err := kv.AuthTokenIndexKey(a.TokenPrefix, a.ID)
+	if err != nil {
+		return nil, ErrInvalidAuthIDError(err)
+	}
+	if err := idx.Put(key, encodedID); err != nil {
 		return nil, &influxdb.Error{
 			Code: influxdb.EInternal,
 			Err:  err,
@@ -287,7 +283,11 @@ func (s *Store) DeleteAuthorization(ctx context.Context, tx kv.Tx, id influxdb.I
 		return err
 	}
 
-	if err := idx.Delete([]byte(a.Token)); err != nil {
+	key, err := kv.AuthTokenIndexKey(a.TokenPrefix, id)
+	if err != nil {
+		return ErrInvalidAuthID
+	}
+	if err := idx.Delete(key); err != nil {
 		return ErrInternalServiceError(err)
 	}
 
@@ -299,38 +299,20 @@ func (s *Store) DeleteAuthorization(ctx context.Context, tx kv.Tx, id influxdb.I
 }
 
 func (s *Store) uniqueAuthToken(ctx context.Context, tx kv.Tx, a *influxdb.Authorization) error {
-	err := unique(ctx, tx, authIndex, authIndexKey(a.Token))
-	if err == kv.NotUniqueError {
+	_, err := kv.FindAuthorizationIDByToken(tx, a.Token)
+	if err == nil {
 		// by returning a generic error we are trying to hide when
 		// a token is non-unique.
 		return influxdb.ErrUnableToCreateToken
 	}
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return nil
+	}
 	// otherwise, this is some sort of internal server error and we
 	// should provide some debugging information.
 	return err
 }
 
-func unique(ctx context.Context, tx kv.Tx, indexBucket, indexKey []byte) error {
-	bucket, err := tx.Bucket(indexBucket)
-	if err != nil {
-		return kv.UnexpectedIndexError(err)
-	}
-
-	_, err = bucket.Get(indexKey)
-	// if not found then this token is unique.
-	if kv.IsNotFound(err) {
-		return nil
-	}
-
-	// no error means this is not unique
-	if err == nil {
-		return kv.NotUniqueError
-	}
-
-	// any other error is some sort of internal server error
-	return kv.UnexpectedIndexError(err)
-}
-
 // uniqueID returns nil if the ID provided is unique, returns an error otherwise
 func uniqueID(ctx context.Context, tx kv.Tx, id influxdb.ID) error {
 	encodedID, err := id.Encode()
@@ -373,10 +355,10 @@ func authorizationsPredicateFn(f influxdb.AuthorizationFilter) kv.CursorPredicat
 	}
 
 	if f.Token != nil {
-		exp := *f.Token
+		exp := kv.AuthTokenPrefix(*f.Token)
 		return func(_, value []byte) bool {
-			// it is assumed that token never has escaped string data
-			got, _, _, err := jsonparser.Get(value, "token")
+			// it is assumed that token prefix never has escaped string data
+			got, _, _, err := jsonparser.Get(value, "tokenPrefix")
 			if err != nil {
 				return true
 			}
@@ -411,6 +393,15 @@ func authorizationsPredicateFn(f influxdb.AuthorizationFilter) kv.CursorPredicat
 }
 
 func filterAuthorizationsFn(filter influxdb.AuthorizationFilter) func(a *influxdb.Authorization) bool {
+	if filter.ExpiresBefore != nil {
+		before := *filter.ExpiresBefore
+		filter.ExpiresBefore = nil
+		fn := filterAuthorizationsFn(filter)
+		return func(a *influxdb.Authorization) bool {
+			return a.ExpiresBefore(before) && fn(a)
+		}
+	}
+
 	if filter.ID != nil {
 		return func(a *influxdb.Authorization) bool {
 			return a.ID == *filter.ID
@@ -419,7 +410,7 @@ func filterAuthorizationsFn(filter influxdb.AuthorizationFilter) func(a *influxd
 
 	if filter.Token != nil {
 		return func(a *influxdb.Authorization) bool {
-			return a.Token == *filter.Token
+			return kv.CompareAuthToken(a.TokenHash, *filter.Token)
 		}
 	}
 
@@ -51,20 +51,36 @@ func TestAuth(t *testing.T) {
 					t.Fatalf("expected 10 authorizations, got: %d", len(auths))
 				}
 
+				// only the hashes of the tokens are stored
+				for _, a := range auths {
+					if !kv.CompareAuthToken(a.TokenHash, fmt.Sprintf("randomtoken%d", a.ID)) {
+						t.Fatalf("expected the hash of the token of authorization %s", a.ID)
+					}
+					a.TokenHash = ""
+				}
+
 				expected := []*influxdb.Authorization{}
 				for i := 1; i <= 10; i++ {
 					expected = append(expected, &influxdb.Authorization{
-						ID:     influxdb.ID(i),
-						Token:  fmt.Sprintf("randomtoken%d", i),
-						OrgID:  influxdb.ID(i),
-						UserID: influxdb.ID(i),
-						Status: "active",
+						ID:          influxdb.ID(i),
+						TokenPrefix: "randomto",
+						OrgID:       influxdb.ID(i),
+						UserID:      influxdb.ID(i),
+						Status:      "active",
 					})
 				}
 				if !reflect.DeepEqual(auths, expected) {
 					t.Fatalf("expected identical authorizations: \n%+v\n%+v", auths, expected)
 				}
 
+				a, err := store.GetAuthorizationByToken(context.Background(), tx, "randomtoken3")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if a.ID != influxdb.ID(3) {
+					t.Fatalf("expected authorization 3 to be found by its token, got %s", a.ID)
+				}
+
 				// should not be able to create two authorizations with identical tokens
 				err = store.CreateAuthorization(context.Background(), tx, &influxdb.Authorization{
 					ID:     influxdb.ID(1),
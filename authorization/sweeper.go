@@ -0,0 +1,102 @@
+package authorization
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultSweepInterval is how often expired tokens are deleted.
+	DefaultSweepInterval = time.Hour
+
+	// DefaultExpiringWithin is how soon tokens must expire to be counted as
+	// expiring soon.
+	DefaultExpiringWithin = 7 * 24 * time.Hour
+)
+
+// ExpirySweeper deletes the authorizations that have expired, and counts the
+// authorizations that expire soon.
+type ExpirySweeper struct {
+	log            *zap.Logger
+	svc            influxdb.AuthorizationService
+	expiringWithin time.Duration
+
+	swept    prometheus.Counter
+	expiring prometheus.Gauge
+}
+
+// NewExpirySweeper creates an ExpirySweeper of the authorizations of svc,
+// which counts the authorizations that expire within expiringWithin as
+// expiring soon.
+func NewExpirySweeper(log *zap.Logger, svc influxdb.AuthorizationService, expiringWithin time.Duration) *ExpirySweeper {
+	return &ExpirySweeper{
+		log:            log,
+		svc:            svc,
+		expiringWithin: expiringWithin,
+		swept: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "auth",
+			Subsystem: "token",
+			Name:      "expired_deleted_total",
+			Help:      "Total number of expired tokens deleted",
+		}),
+		expiring: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "auth",
+			Subsystem: "token",
+			Name:      "expiring",
+			Help:      "Number of tokens that expire soon",
+		}),
+	}
+}
+
+// PrometheusCollectors returns the metrics of the sweeper.
+func (s *ExpirySweeper) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{s.swept, s.expiring}
+}
+
+// Run sweeps the authorizations every interval, and returns when ctx is done.
+func (s *ExpirySweeper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Sweep(ctx); err != nil {
+			s.log.Error("Failed to sweep expired tokens", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep deletes the authorizations that have expired, and returns the
+// authorizations that expire soon.
+func (s *ExpirySweeper) Sweep(ctx context.Context) ([]*influxdb.Authorization, error) {
+	now := time.Now()
+	before := now.Add(s.expiringWithin)
+	as, _, err := s.svc.FindAuthorizations(ctx, influxdb.AuthorizationFilter{ExpiresBefore: &before})
+	if err != nil {
+		return nil, err
+	}
+
+	expiring := make([]*influxdb.Authorization, 0, len(as))
+	for _, a := range as {
+		if a.ExpiresAt == nil || now.Before(*a.ExpiresAt) {
+			expiring = append(expiring, a)
+			continue
+		}
+		if err := s.svc.DeleteAuthorization(ctx, a.ID); err != nil {
+			s.log.Error("Failed to delete expired token", zap.String("authID", a.ID.String()), zap.Error(err))
+			continue
+		}
+		s.swept.Inc()
+	}
+	s.expiring.Set(float64(len(expiring)))
+	return expiring, nil
+}
@@ -0,0 +1,57 @@
+package authorization_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorization"
+	"github.com/influxdata/influxdb/v2/inmem"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestExpirySweeper(t *testing.T) {
+	now := time.Now()
+	expiresAt := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	orgID, userID := influxdb.ID(1), influxdb.ID(2)
+	auths := []*influxdb.Authorization{
+		{OrgID: orgID, UserID: userID, Token: "expired", ExpiresAt: expiresAt(-time.Hour)},
+		{OrgID: orgID, UserID: userID, Token: "expiring", ExpiresAt: expiresAt(time.Hour)},
+		{OrgID: orgID, UserID: userID, Token: "later", ExpiresAt: expiresAt(30 * 24 * time.Hour)},
+		{OrgID: orgID, UserID: userID, Token: "forever"},
+	}
+	svc, done := initAuthService(inmem.NewKVStore(), influxdbtesting.AuthorizationFields{
+		Users:          []*influxdb.User{{ID: userID, Name: "user"}},
+		Orgs:           []*influxdb.Organization{{ID: orgID, Name: "org"}},
+		Authorizations: auths,
+	}, t)
+	defer done()
+
+	sweeper := authorization.NewExpirySweeper(zaptest.NewLogger(t), svc, authorization.DefaultExpiringWithin)
+	expiring, err := sweeper.Sweep(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expiring) != 1 || expiring[0].ID != auths[1].ID {
+		t.Fatalf("expected only the expiring token to expire soon, got %v", expiring)
+	}
+
+	found, _, err := svc.FindAuthorizations(context.Background(), influxdb.AuthorizationFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != len(auths)-1 {
+		t.Fatalf("expected %d tokens, got %d", len(auths)-1, len(found))
+	}
+	for _, a := range found {
+		if a.ID == auths[0].ID {
+			t.Fatal("expected the expired token to be deleted")
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.AuditService = (*AuditService)(nil)
+
+// AuditService wraps a influxdb.AuditService and authorizes actions
+// against it appropriately.
+type AuditService struct {
+	s influxdb.AuditService
+}
+
+// NewAuditService constructs an instance of an authorizing audit service.
+func NewAuditService(s influxdb.AuditService) *AuditService {
+	return &AuditService{
+		s: s,
+	}
+}
+
+// LogAuditEvent records the audit event, which is recorded by the system
+// rather than on behalf of the authorizer on context.
+func (s *AuditService) LogAuditEvent(ctx context.Context, ev *influxdb.AuditEvent) error {
+	return s.s.LogAuditEvent(ctx, ev)
+}
+
+// FindAuditEvents checks to see if the authorizer on context has write access
+// to the org of the filter, or to all orgs when the filter has no org, which
+// the owners of the orgs have.
+func (s *AuditService) FindAuditEvents(ctx context.Context, filter influxdb.AuditEventFilter, opt ...influxdb.FindOptions) ([]*influxdb.AuditEvent, int, error) {
+	if filter.OrgID != nil {
+		if _, _, err := AuthorizeWriteOrg(ctx, *filter.OrgID); err != nil {
+			return nil, 0, err
+		}
+	} else if _, _, err := AuthorizeWriteGlobal(ctx, influxdb.OrgsResourceType); err != nil {
+		return nil, 0, err
+	}
+	return s.s.FindAuditEvents(ctx, filter, opt...)
+}
@@ -5,10 +5,11 @@ import (
 	"fmt"
 
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/audit"
 	icontext "github.com/influxdata/influxdb/v2/context"
 )
 
-func isAllowedAll(a influxdb.Authorizer, permissions []influxdb.Permission) error {
+func isAllowedAll(ctx context.Context, a influxdb.Authorizer, permissions []influxdb.Permission) error {
 	pset, err := a.PermissionSet()
 	if err != nil {
 		return err
@@ -16,6 +17,7 @@ func isAllowedAll(a influxdb.Authorizer, permissions []influxdb.Permission) erro
 
 	for _, p := range permissions {
 		if !pset.Allowed(p) {
+			audit.Denied(ctx, p)
 			return &influxdb.Error{
 				Code: influxdb.EUnauthorized,
 				Msg:  fmt.Sprintf("%s is unauthorized", p),
@@ -25,8 +27,8 @@ func isAllowedAll(a influxdb.Authorizer, permissions []influxdb.Permission) erro
 	return nil
 }
 
-func isAllowed(a influxdb.Authorizer, p influxdb.Permission) error {
-	return isAllowedAll(a, []influxdb.Permission{p})
+func isAllowed(ctx context.Context, a influxdb.Authorizer, p influxdb.Permission) error {
+	return isAllowedAll(ctx, a, []influxdb.Permission{p})
 }
 
 // IsAllowedAll checks to see if an action is authorized by ALL permissions.
@@ -36,7 +38,7 @@ func IsAllowedAll(ctx context.Context, permissions []influxdb.Permission) error
 	if err != nil {
 		return err
 	}
-	return isAllowedAll(a, permissions)
+	return isAllowedAll(ctx, a, permissions)
 }
 
 // IsAllowed checks to see if an action is authorized by retrieving the authorizer
@@ -61,6 +63,9 @@ func IsAllowedAny(ctx context.Context, permissions []influxdb.Permission) error
 			return nil
 		}
 	}
+	if len(permissions) > 0 {
+		audit.Denied(ctx, permissions[0])
+	}
 	return &influxdb.Error{
 		Code: influxdb.EUnauthorized,
 		Msg:  fmt.Sprintf("none of %v is authorized", permissions),
@@ -86,7 +91,7 @@ func authorize(ctx context.Context, a influxdb.Action, rt influxdb.ResourceType,
 	if err != nil {
 		return nil, influxdb.Permission{}, err
 	}
-	return auth, *p, isAllowed(auth, *p)
+	return auth, *p, isAllowed(ctx, auth, *p)
 }
 
 func authorizeReadSystemBucket(ctx context.Context, bid, oid influxdb.ID) (influxdb.Authorizer, influxdb.Permission, error) {
@@ -108,8 +113,33 @@ func AuthorizeReadBucket(ctx context.Context, bt influxdb.BucketType, bid, oid i
 	case influxdb.BucketTypeSystem:
 		return authorizeReadSystemBucket(ctx, bid, oid)
 	default:
-		return AuthorizeRead(ctx, influxdb.BucketsResourceType, bid, oid)
+		return authorizeReadBucket(ctx, bid, oid)
+	}
+}
+
+// authorizeReadBucket authorizes the reads of all of the bucket, and of the
+// points of the bucket that match a predicate of a permission.
+func authorizeReadBucket(ctx context.Context, bid, oid influxdb.ID) (influxdb.Authorizer, influxdb.Permission, error) {
+	p, err := influxdb.NewPermissionAtID(bid, influxdb.ReadAction, influxdb.BucketsResourceType, oid)
+	if err != nil {
+		return nil, influxdb.Permission{}, err
+	}
+	a, err := icontext.GetAuthorizer(ctx)
+	if err != nil {
+		return nil, influxdb.Permission{}, err
+	}
+	pset, err := a.PermissionSet()
+	if err != nil {
+		return nil, influxdb.Permission{}, err
+	}
+	if !pset.ReadsBucket(oid, bid) {
+		audit.Denied(ctx, *p)
+		return nil, influxdb.Permission{}, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  fmt.Sprintf("%s is unauthorized", p),
+		}
 	}
+	return a, *p, nil
 }
 
 // AuthorizeRead authorizes the user in the context to read the specified resource (identified by its type, ID, and orgID).
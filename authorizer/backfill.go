@@ -0,0 +1,91 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.BackfillService = (*BackfillService)(nil)
+
+// BackfillService wraps a influxdb.BackfillService and authorizes actions
+// against it appropriately.
+type BackfillService struct {
+	s  influxdb.BackfillService
+	ts influxdb.TaskService
+}
+
+// NewBackfillService constructs an instance of an authorizing backfill service.
+// The tasks of backfills are looked up in ts, which must not authorize.
+func NewBackfillService(ts influxdb.TaskService, s influxdb.BackfillService) *BackfillService {
+	return &BackfillService{
+		s:  s,
+		ts: ts,
+	}
+}
+
+// CreateBackfill checks to see if the authorizer on context has write access to the task.
+func (b *BackfillService) CreateBackfill(ctx context.Context, taskID influxdb.ID, bc influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	// Unauthenticated task lookup, to identify the task's organization.
+	task, err := b.ts.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != string(influxdb.TaskActive) {
+		return nil, ErrInactiveTask
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.TasksResourceType, task.ID, task.OrganizationID); err != nil {
+		return nil, err
+	}
+	return b.s.CreateBackfill(ctx, taskID, bc)
+}
+
+// FindBackfillByID checks to see if the authorizer on context has read access to the task.
+func (b *BackfillService) FindBackfillByID(ctx context.Context, taskID, id influxdb.ID) (*influxdb.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := b.authorizeRead(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return b.s.FindBackfillByID(ctx, taskID, id)
+}
+
+// FindBackfills checks to see if the authorizer on context has read access to the task.
+func (b *BackfillService) FindBackfills(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := b.authorizeRead(ctx, taskID); err != nil {
+		return nil, err
+	}
+	return b.s.FindBackfills(ctx, taskID)
+}
+
+// CancelBackfill checks to see if the authorizer on context has write access to the task.
+func (b *BackfillService) CancelBackfill(ctx context.Context, taskID, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	task, err := b.ts.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.TasksResourceType, task.ID, task.OrganizationID); err != nil {
+		return err
+	}
+	return b.s.CancelBackfill(ctx, taskID, id)
+}
+
+func (b *BackfillService) authorizeRead(ctx context.Context, taskID influxdb.ID) error {
+	task, err := b.ts.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	_, _, err = AuthorizeRead(ctx, influxdb.TasksResourceType, task.ID, task.OrganizationID)
+	return err
+}
@@ -0,0 +1,67 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.CompactionService = (*CompactionService)(nil)
+
+// CompactionService wraps a influxdb.CompactionService and authorizes actions
+// against it appropriately.
+type CompactionService struct {
+	s influxdb.CompactionService
+}
+
+// NewCompactionService constructs an instance of an authorizing compaction service.
+func NewCompactionService(s influxdb.CompactionService) *CompactionService {
+	return &CompactionService{
+		s: s,
+	}
+}
+
+// CompactionStatus checks to see if the authorizer on context has read access to all resources.
+func (c CompactionService) CompactionStatus(ctx context.Context) (*influxdb.CompactionStatus, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return c.s.CompactionStatus(ctx)
+}
+
+// PauseCompactions checks to see if the authorizer on context has operator access.
+func (c CompactionService) PauseCompactions(ctx context.Context) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return c.s.PauseCompactions(ctx)
+}
+
+// ResumeCompactions checks to see if the authorizer on context has operator access.
+func (c CompactionService) ResumeCompactions(ctx context.Context) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return c.s.ResumeCompactions(ctx)
+}
+
+// ScheduleCompaction checks to see if the authorizer on context has operator access.
+func (c CompactionService) ScheduleCompaction(ctx context.Context, typ influxdb.CompactionType) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return err
+	}
+	return c.s.ScheduleCompaction(ctx, typ)
+}
@@ -0,0 +1,57 @@
+package authorizer
+
+import (
+	"context"
+	"io"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.ReplicationService = (*ReplicationService)(nil)
+
+// ReplicationService wraps a influxdb.ReplicationService and authorizes actions
+// against it appropriately.
+type ReplicationService struct {
+	s influxdb.ReplicationService
+}
+
+// NewReplicationService constructs an instance of an authorizing replication service.
+func NewReplicationService(s influxdb.ReplicationService) *ReplicationService {
+	return &ReplicationService{
+		s: s,
+	}
+}
+
+// ReplicationStatus checks to see if the authorizer on context has read access to all resources.
+func (r ReplicationService) ReplicationStatus(ctx context.Context) (*influxdb.ReplicationStatus, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.ReadAllPermissions()); err != nil {
+		return nil, err
+	}
+	return r.s.ReplicationStatus(ctx)
+}
+
+// WALPosition checks to see if the authorizer on context has operator access.
+func (r ReplicationService) WALPosition(ctx context.Context) (*influxdb.WALPosition, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+	return r.s.WALPosition(ctx)
+}
+
+// ApplyWAL checks to see if the authorizer on context has operator access.
+func (r ReplicationService) ApplyWAL(ctx context.Context, pos influxdb.WALPosition, rd io.Reader) (*influxdb.WALPosition, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := IsAllowedAll(ctx, influxdb.OperPermissions()); err != nil {
+		return nil, err
+	}
+	return r.s.ApplyWAL(ctx, pos, rd)
+}
@@ -0,0 +1,177 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.RoleService = (*RoleService)(nil)
+
+// RoleService wraps a influxdb.RoleService and authorizes actions
+// against it appropriately.
+type RoleService struct {
+	s influxdb.RoleService
+}
+
+// NewRoleService constructs an instance of an authorizing role service.
+func NewRoleService(s influxdb.RoleService) *RoleService {
+	return &RoleService{
+		s: s,
+	}
+}
+
+// FindRoleByID checks to see if the authorizer on context has read access to the id provided.
+func (s *RoleService) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeRead(ctx, influxdb.RolesResourceType, r.ID, r.OrgID); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// FindRoles retrieves all roles that match the provided filter and then filters the list down to only the resources that are authorized.
+func (s *RoleService) FindRoles(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+	// TODO: we'll likely want to push this operation into the database since fetching the whole list of data will likely be expensive.
+	rs, _, err := s.s.FindRoles(ctx, filter, opt...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return AuthorizeFindRoles(ctx, rs)
+}
+
+// CreateRole checks to see if the authorizer on context has write access to the roles of the org,
+// and has all of the permissions of the role, so that it cannot grant more than it has.
+func (s *RoleService) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	if _, _, err := AuthorizeCreate(ctx, influxdb.RolesResourceType, r.OrgID); err != nil {
+		return err
+	}
+	if err := VerifyPermissions(ctx, r.Permissions); err != nil {
+		return err
+	}
+	return s.s.CreateRole(ctx, r)
+}
+
+// UpdateRole checks to see if the authorizer on context has write access to the role provided,
+// and has all of the new permissions of the role.
+func (s *RoleService) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.RolesResourceType, r.ID, r.OrgID); err != nil {
+		return nil, err
+	}
+	if upd.Permissions != nil {
+		if err := VerifyPermissions(ctx, *upd.Permissions); err != nil {
+			return nil, err
+		}
+	}
+	return s.s.UpdateRole(ctx, id, upd)
+}
+
+// DeleteRole checks to see if the authorizer on context has write access to the role provided.
+func (s *RoleService) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.RolesResourceType, r.ID, r.OrgID); err != nil {
+		return err
+	}
+	return s.s.DeleteRole(ctx, id)
+}
+
+// AssignRole checks to see if the authorizer on context has write access to the role provided,
+// and has all of the permissions of the role. Authorizations also require write access to them.
+func (s *RoleService) AssignRole(ctx context.Context, a *influxdb.RoleAssignment) error {
+	r, err := s.authorizeWriteRole(ctx, a.RoleID)
+	if err != nil {
+		return err
+	}
+	if err := VerifyPermissions(ctx, r.Permissions); err != nil {
+		return err
+	}
+	if a.ResourceType == influxdb.AuthorizationsResourceType {
+		// roles may only be assigned to the authorizations of their org
+		if _, _, err := AuthorizeWrite(ctx, influxdb.AuthorizationsResourceType, a.ResourceID, r.OrgID); err != nil {
+			return err
+		}
+	}
+	return s.s.AssignRole(ctx, a)
+}
+
+// UnassignRole checks to see if the authorizer on context has write access to the role provided.
+func (s *RoleService) UnassignRole(ctx context.Context, a influxdb.RoleAssignment) error {
+	if _, err := s.authorizeWriteRole(ctx, a.RoleID); err != nil {
+		return err
+	}
+	return s.s.UnassignRole(ctx, a)
+}
+
+func (s *RoleService) authorizeWriteRole(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	r, err := s.s.FindRoleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.RolesResourceType, r.ID, r.OrgID); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// FindRoleAssignments retrieves all role assignments that match the provided filter and then filters the list
+// down to the assignments of the roles that are authorized.
+func (s *RoleService) FindRoleAssignments(ctx context.Context, filter influxdb.RoleAssignmentFilter) ([]*influxdb.RoleAssignment, error) {
+	as, err := s.s.FindRoleAssignments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	authorized := make(map[influxdb.ID]bool)
+	aas := as[:0]
+	for _, a := range as {
+		ok, seen := authorized[a.RoleID]
+		if !seen {
+			r, err := s.s.FindRoleByID(ctx, a.RoleID)
+			if err != nil {
+				return nil, err
+			}
+			_, _, err = AuthorizeRead(ctx, influxdb.RolesResourceType, r.ID, r.OrgID)
+			if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+				return nil, err
+			}
+			ok = err == nil
+			authorized[a.RoleID] = ok
+		}
+		if ok {
+			aas = append(aas, a)
+		}
+	}
+	return aas, nil
+}
+
+// SetRolePermissions looks up the permissions of the roles of the authorizer,
+// which are the roles of an authorization, or the roles of the user of a
+// session, so that its permission set has them. svc is not authorized, as
+// the authorizer has not been authorized yet.
+func SetRolePermissions(ctx context.Context, svc influxdb.RolePermissionService, a influxdb.Authorizer) error {
+	switch a := a.(type) {
+	case *influxdb.Authorization:
+		ps, err := svc.FindRolePermissions(ctx, influxdb.AuthorizationsResourceType, a.ID)
+		if err != nil {
+			return err
+		}
+		a.RolePermissions = ps
+	case *influxdb.Session:
+		ps, err := svc.FindRolePermissions(ctx, influxdb.UsersResourceType, a.UserID)
+		if err != nil {
+			return err
+		}
+		a.RolePermissions = ps
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package authorizer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
+	influxdbcontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rolePermission(action influxdb.Action, rt influxdb.ResourceType, orgID influxdb.ID) influxdb.Permission {
+	return influxdb.Permission{
+		Action: action,
+		Resource: influxdb.Resource{
+			Type:  rt,
+			OrgID: &orgID,
+		},
+	}
+}
+
+func TestRoleService_CreateRole(t *testing.T) {
+	orgID := influxdb.ID(1)
+	writeRoles := rolePermission(influxdb.WriteAction, influxdb.RolesResourceType, orgID)
+	writeBuckets := rolePermission(influxdb.WriteAction, influxdb.BucketsResourceType, orgID)
+	readBuckets := rolePermission(influxdb.ReadAction, influxdb.BucketsResourceType, orgID)
+
+	tests := []struct {
+		name        string
+		permissions []influxdb.Permission
+		code        string
+	}{
+		{
+			name:        "authorized to write roles with the permissions of the role",
+			permissions: []influxdb.Permission{writeRoles, writeBuckets},
+		},
+		{
+			name:        "without the permissions of the role",
+			permissions: []influxdb.Permission{writeRoles, readBuckets},
+			code:        influxdb.EForbidden,
+		},
+		{
+			name:        "unauthorized to write roles",
+			permissions: []influxdb.Permission{writeBuckets},
+			code:        influxdb.EUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created bool
+			s := authorizer.NewRoleService(&mock.RoleService{
+				CreateRoleFn: func(context.Context, *influxdb.Role) error {
+					created = true
+					return nil
+				},
+			})
+
+			ctx := influxdbcontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, tt.permissions))
+			err := s.CreateRole(ctx, &influxdb.Role{
+				OrgID:       orgID,
+				Name:        "bucket-writer",
+				Permissions: []influxdb.Permission{writeBuckets},
+			})
+			if tt.code == "" {
+				require.NoError(t, err)
+				assert.True(t, created)
+				return
+			}
+			assert.Equal(t, tt.code, influxdb.ErrorCode(err))
+			assert.False(t, created)
+		})
+	}
+}
+
+func TestRoleService_FindRoles(t *testing.T) {
+	orgOne, orgTwo := influxdb.ID(1), influxdb.ID(2)
+	s := authorizer.NewRoleService(&mock.RoleService{
+		FindRolesFn: func(context.Context, influxdb.RoleFilter, ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+			return []*influxdb.Role{
+				{ID: 10, OrgID: orgOne, Name: "a"},
+				{ID: 11, OrgID: orgTwo, Name: "b"},
+			}, 2, nil
+		},
+	})
+
+	ctx := influxdbcontext.SetAuthorizer(context.Background(), mock.NewMockAuthorizer(false, []influxdb.Permission{
+		rolePermission(influxdb.ReadAction, influxdb.RolesResourceType, orgOne),
+	}))
+	rs, n, err := s.FindRoles(ctx, influxdb.RoleFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Len(t, rs, 1)
+	assert.Equal(t, influxdb.ID(10), rs[0].ID)
+}
+
+func TestSetRolePermissions(t *testing.T) {
+	orgID := influxdb.ID(1)
+	authID, userID := influxdb.ID(20), influxdb.ID(30)
+
+	// the role starts with read, and is later changed to write
+	perms := []influxdb.Permission{rolePermission(influxdb.ReadAction, influxdb.BucketsResourceType, orgID)}
+	svc := &mock.RoleService{
+		FindRolePermissionsFn: func(_ context.Context, rt influxdb.ResourceType, id influxdb.ID) ([]influxdb.Permission, error) {
+			if rt == influxdb.AuthorizationsResourceType && id != authID ||
+				rt == influxdb.UsersResourceType && id != userID {
+				return nil, nil
+			}
+			return perms, nil
+		},
+	}
+
+	t.Run("authorization", func(t *testing.T) {
+		a := &influxdb.Authorization{ID: authID, OrgID: orgID, Status: influxdb.Active}
+		require.NoError(t, authorizer.SetRolePermissions(context.Background(), svc, a))
+		ps, err := a.PermissionSet()
+		require.NoError(t, err)
+		assert.True(t, ps.Allowed(perms[0]))
+	})
+
+	t.Run("session", func(t *testing.T) {
+		s := &influxdb.Session{UserID: userID}
+		require.NoError(t, authorizer.SetRolePermissions(context.Background(), svc, s))
+		assert.Equal(t, perms, s.RolePermissions)
+	})
+
+	t.Run("changes to the role propagate", func(t *testing.T) {
+		perms = []influxdb.Permission{rolePermission(influxdb.WriteAction, influxdb.BucketsResourceType, orgID)}
+
+		a := &influxdb.Authorization{ID: authID, OrgID: orgID, Status: influxdb.Active}
+		require.NoError(t, authorizer.SetRolePermissions(context.Background(), svc, a))
+		ps, err := a.PermissionSet()
+		require.NoError(t, err)
+		assert.True(t, ps.Allowed(perms[0]))
+		assert.False(t, ps.Allowed(rolePermission(influxdb.ReadAction, influxdb.BucketsResourceType, orgID)))
+	})
+
+	t.Run("without roles", func(t *testing.T) {
+		a := &influxdb.Authorization{ID: influxdb.ID(21), OrgID: orgID, Status: influxdb.Active}
+		require.NoError(t, authorizer.SetRolePermissions(context.Background(), svc, a))
+		assert.Empty(t, a.RolePermissions)
+	})
+}
@@ -0,0 +1,43 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.TaskDryRunService = (*TaskDryRunService)(nil)
+
+// TaskDryRunService wraps a influxdb.TaskDryRunService and authorizes actions
+// against it appropriately.
+type TaskDryRunService struct {
+	s  influxdb.TaskDryRunService
+	ts influxdb.TaskService
+}
+
+// NewTaskDryRunService constructs an instance of an authorizing task dry run service.
+// The tasks are looked up in ts, which must not authorize.
+func NewTaskDryRunService(ts influxdb.TaskService, s influxdb.TaskDryRunService) *TaskDryRunService {
+	return &TaskDryRunService{
+		s:  s,
+		ts: ts,
+	}
+}
+
+// DryRunTask checks to see if the authorizer on context has write access to the task,
+// since the task is executed with its own authorization, possibly with a different script.
+func (d *TaskDryRunService) DryRunTask(ctx context.Context, taskID influxdb.ID, req influxdb.TaskDryRunRequest) (*influxdb.TaskDryRun, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	// Unauthenticated task lookup, to identify the task's organization.
+	task, err := d.ts.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeWrite(ctx, influxdb.TasksResourceType, task.ID, task.OrganizationID); err != nil {
+		return nil, err
+	}
+	return d.s.DryRunTask(ctx, taskID, req)
+}
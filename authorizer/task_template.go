@@ -0,0 +1,117 @@
+package authorizer
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+var _ influxdb.TaskTemplateService = (*TaskTemplateService)(nil)
+
+// TaskTemplateService wraps a influxdb.TaskTemplateService and authorizes actions
+// against it appropriately. Task templates belong to the tasks of their org:
+// reading them requires reading all the tasks of the org, and changing them
+// requires writing all the tasks of the org, since it renders them again.
+type TaskTemplateService struct {
+	s influxdb.TaskTemplateService
+}
+
+// NewTaskTemplateService constructs an instance of an authorizing task template service.
+func NewTaskTemplateService(s influxdb.TaskTemplateService) *TaskTemplateService {
+	return &TaskTemplateService{
+		s: s,
+	}
+}
+
+// FindTaskTemplateByID checks to see if the authorizer on context has read access to the tasks of the template's org.
+func (s *TaskTemplateService) FindTaskTemplateByID(ctx context.Context, id influxdb.ID) (*influxdb.TaskTemplate, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	t, err := s.s.FindTaskTemplateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := AuthorizeOrgReadResource(ctx, influxdb.TasksResourceType, t.OrganizationID); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// FindTaskTemplates retrieves all task templates that match the provided filter and then filters the list down to only the templates that are authorized.
+func (s *TaskTemplateService) FindTaskTemplates(ctx context.Context, filter influxdb.TaskTemplateFilter) ([]*influxdb.TaskTemplate, int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	ts, _, err := s.s.FindTaskTemplates(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	templates := ts[:0]
+	for _, t := range ts {
+		_, _, err := AuthorizeOrgReadResource(ctx, influxdb.TasksResourceType, t.OrganizationID)
+		if err != nil && influxdb.ErrorCode(err) != influxdb.EUnauthorized {
+			return nil, 0, err
+		}
+		if influxdb.ErrorCode(err) == influxdb.EUnauthorized {
+			continue
+		}
+		templates = append(templates, t)
+	}
+	return templates, len(templates), nil
+}
+
+// CreateTaskTemplate checks to see if the authorizer on context has write access to the tasks of the org.
+func (s *TaskTemplateService) CreateTaskTemplate(ctx context.Context, t *influxdb.TaskTemplate) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if _, _, err := AuthorizeCreate(ctx, influxdb.TasksResourceType, t.OrganizationID); err != nil {
+		return err
+	}
+	return s.s.CreateTaskTemplate(ctx, t)
+}
+
+// UpdateTaskTemplate checks to see if the authorizer on context has write access to the tasks of the template's org.
+func (s *TaskTemplateService) UpdateTaskTemplate(ctx context.Context, id influxdb.ID, upd influxdb.TaskTemplateUpdate) (*influxdb.TaskTemplate, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := s.authorizeWrite(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.s.UpdateTaskTemplate(ctx, id, upd)
+}
+
+// PreviewTaskTemplateUpdate checks to see if the authorizer on context has write access to the tasks of the template's org.
+func (s *TaskTemplateService) PreviewTaskTemplateUpdate(ctx context.Context, id influxdb.ID, upd influxdb.TaskTemplateUpdate) ([]*influxdb.TaskTemplateDiff, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := s.authorizeWrite(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.s.PreviewTaskTemplateUpdate(ctx, id, upd)
+}
+
+// DeleteTaskTemplate checks to see if the authorizer on context has write access to the tasks of the template's org.
+func (s *TaskTemplateService) DeleteTaskTemplate(ctx context.Context, id influxdb.ID) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := s.authorizeWrite(ctx, id); err != nil {
+		return err
+	}
+	return s.s.DeleteTaskTemplate(ctx, id)
+}
+
+func (s *TaskTemplateService) authorizeWrite(ctx context.Context, id influxdb.ID) error {
+	t, err := s.s.FindTaskTemplateByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	_, _, err = AuthorizeOrgWriteResource(ctx, influxdb.TasksResourceType, t.OrganizationID)
+	return err
+}
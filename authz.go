@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"strings"
 )
 
 var (
@@ -75,10 +76,18 @@ type Resource struct {
 	Type  ResourceType `json:"type"`
 	ID    *ID          `json:"id,omitempty"`
 	OrgID *ID          `json:"orgID,omitempty"`
+	// Predicate restricts a bucket resource to the points that match it.
+	Predicate *ResourcePredicate `json:"predicate,omitempty"`
 }
 
 // String stringifies a resource
 func (r Resource) String() string {
+	if r.Predicate != nil {
+		pred := r.Predicate
+		r.Predicate = nil
+		return r.String() + pred.String()
+	}
+
 	if r.OrgID != nil && r.ID != nil {
 		return filepath.Join(string(OrgsResourceType), r.OrgID.String(), string(r.Type), r.ID.String())
 	}
@@ -94,6 +103,81 @@ func (r Resource) String() string {
 	return string(r.Type)
 }
 
+// ResourcePredicate restricts a permission on a bucket to the points of a
+// measurement, and/or to the points with all of a set of tag values. The
+// predicates of the permissions to read a bucket are added to the predicates
+// of the reads of the bucket, and the points written with a permission to
+// write a bucket must match its predicate.
+type ResourcePredicate struct {
+	Measurement string `json:"measurement,omitempty"`
+	Tags        []Tag  `json:"tags,omitempty"`
+}
+
+// String stringifies a resource predicate.
+func (p ResourcePredicate) String() string {
+	var conds []string
+	if p.Measurement != "" {
+		conds = append(conds, "_measurement="+p.Measurement)
+	}
+	for _, t := range p.Tags {
+		conds = append(conds, t.Key+"="+t.Value)
+	}
+	return "[" + strings.Join(conds, ",") + "]"
+}
+
+// Valid returns an error if the predicate matches all points, or if it
+// has an invalid tag.
+func (p ResourcePredicate) Valid() error {
+	if p.Measurement == "" && len(p.Tags) == 0 {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "resource predicate requires a measurement or a tag",
+		}
+	}
+	for _, t := range p.Tags {
+		if err := t.Valid(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Covers returns whether all the points that match o also match p.
+func (p ResourcePredicate) Covers(o ResourcePredicate) bool {
+	if p.Measurement != "" && p.Measurement != o.Measurement {
+		return false
+	}
+	for _, t := range p.Tags {
+		if !o.hasTag(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchesPoint returns whether a point of the measurement, with the tag values
+// returned by tagValue, matches the predicate.
+func (p ResourcePredicate) MatchesPoint(measurement string, tagValue func(key string) string) bool {
+	if p.Measurement != "" && p.Measurement != measurement {
+		return false
+	}
+	for _, t := range p.Tags {
+		if tagValue(t.Key) != t.Value {
+			return false
+		}
+	}
+	return true
+}
+
+func (p ResourcePredicate) hasTag(tag Tag) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
 const (
 	// AuthorizationsResourceType gives permissions to one or more authorizations.
 	AuthorizationsResourceType = ResourceType("authorizations") // 0
@@ -131,6 +215,8 @@ const (
 	ChecksResourceType = ResourceType("checks") // 16
 	// DBRPType gives permission to one or more DBRPs.
 	DBRPResourceType = ResourceType("dbrp") // 17
+	// RolesResourceType gives permission to one or more roles.
+	RolesResourceType = ResourceType("roles") // 18
 )
 
 // AllResourceTypes is the list of all known resource types.
@@ -153,6 +239,7 @@ var AllResourceTypes = []ResourceType{
 	NotificationEndpointResourceType, // 15
 	ChecksResourceType,               // 16
 	DBRPResourceType,                 // 17
+	RolesResourceType,                // 18
 	// NOTE: when modifying this list, please update the swagger for components.schemas.Permission resource enum.
 }
 
@@ -171,6 +258,7 @@ var OrgResourceTypes = []ResourceType{
 	NotificationEndpointResourceType, // 15
 	ChecksResourceType,               // 16
 	DBRPResourceType,                 // 17
+	RolesResourceType,                // 18
 }
 
 // Valid checks if the resource type is a member of the ResourceType enum.
@@ -199,6 +287,7 @@ func (t ResourceType) Valid() (err error) {
 	case NotificationEndpointResourceType: // 15
 	case ChecksResourceType: // 16
 	case DBRPResourceType: // 17
+	case RolesResourceType: // 18
 	default:
 		err = ErrInvalidResourceType
 	}
@@ -212,6 +301,60 @@ func (ps PermissionSet) Allowed(p Permission) bool {
 	return PermissionAllowed(p, ps)
 }
 
+// ReadsBucket returns whether the permission set allows to read all of the
+// bucket, or the points of the bucket that match the predicate of a
+// permission. The reads of the bucket are restricted to the predicates by
+// storage, see BucketPredicates.
+func (ps PermissionSet) ReadsBucket(orgID, bucketID ID) bool {
+	bucket := Permission{
+		Action: ReadAction,
+		Resource: Resource{
+			Type:  BucketsResourceType,
+			ID:    &bucketID,
+			OrgID: &orgID,
+		},
+	}
+	if ps.Allowed(bucket) {
+		return true
+	}
+	for _, p := range ps {
+		if p.Resource.Predicate != nil && p.Action == ReadAction &&
+			p.Resource.ID != nil && *p.Resource.ID == bucketID {
+			return true
+		}
+	}
+	return false
+}
+
+// BucketPredicates returns the predicates of the permissions of the set to
+// act on the points of the bucket. It returns nil when the set has a
+// permission to act on all the points of the bucket, or none to act on it.
+func (ps PermissionSet) BucketPredicates(a Action, orgID, bucketID ID) []ResourcePredicate {
+	bucket := Permission{
+		Action: a,
+		Resource: Resource{
+			Type:  BucketsResourceType,
+			ID:    &bucketID,
+			OrgID: &orgID,
+		},
+	}
+
+	var preds []ResourcePredicate
+	for _, p := range ps {
+		if p.Resource.Predicate == nil {
+			if p.Matches(bucket) {
+				return nil
+			}
+			continue
+		}
+		if p.Action == a && p.Resource.Type == BucketsResourceType &&
+			p.Resource.ID != nil && *p.Resource.ID == bucketID {
+			preds = append(preds, *p.Resource.Predicate)
+		}
+	}
+	return preds
+}
+
 // Permission defines an action and a resource.
 type Permission struct {
 	Action   Action   `json:"action"`
@@ -219,6 +362,10 @@ type Permission struct {
 }
 
 // Matches returns whether or not one permission matches the other.
+//
+// A permission with a predicate only matches the permissions on its bucket
+// with a predicate it covers, and never the permission to act on all of its
+// bucket. See PermissionSet.ReadsBucket for finding and querying the bucket.
 func (p Permission) Matches(perm Permission) bool {
 	if p.Action != perm.Action {
 		return false
@@ -228,6 +375,16 @@ func (p Permission) Matches(perm Permission) bool {
 		return false
 	}
 
+	if p.Resource.Predicate != nil {
+		if p.Resource.ID == nil || perm.Resource.ID == nil || *p.Resource.ID != *perm.Resource.ID {
+			return false
+		}
+		if perm.Resource.Predicate == nil {
+			return false
+		}
+		return p.Resource.Predicate.Covers(*perm.Resource.Predicate)
+	}
+
 	if p.Resource.OrgID == nil && p.Resource.ID == nil {
 		return true
 	}
@@ -293,6 +450,22 @@ func (p *Permission) Valid() error {
 		}
 	}
 
+	if pred := p.Resource.Predicate; pred != nil {
+		if p.Resource.Type != BucketsResourceType || p.Resource.ID == nil {
+			return &Error{
+				Code: EInvalid,
+				Msg:  "predicate permissions require a bucket id",
+			}
+		}
+		if err := pred.Valid(); err != nil {
+			return &Error{
+				Code: EInvalid,
+				Err:  err,
+				Msg:  "invalid predicate for permission",
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package influxdb_test
 
 import (
+	"reflect"
 	"testing"
 
 	platform "github.com/influxdata/influxdb/v2"
@@ -229,6 +230,93 @@ func TestAuthorizer_PermissionAllowed(t *testing.T) {
 			},
 			allowed: false,
 		},
+		{
+			name: "predicate permission to read does not match the read of its bucket",
+			permission: platform.Permission{
+				Action:   platform.ReadAction,
+				Resource: bucketResource(1, nil),
+			},
+			permissions: []platform.Permission{
+				{
+					Action:   platform.ReadAction,
+					Resource: bucketResource(1, customerPredicate("a")),
+				},
+			},
+			allowed: false,
+		},
+		{
+			name: "predicate permission to write does not match the write of its bucket",
+			permission: platform.Permission{
+				Action:   platform.WriteAction,
+				Resource: bucketResource(1, nil),
+			},
+			permissions: []platform.Permission{
+				{
+					Action:   platform.WriteAction,
+					Resource: bucketResource(1, customerPredicate("a")),
+				},
+			},
+			allowed: false,
+		},
+		{
+			name: "predicate permission does not match another bucket",
+			permission: platform.Permission{
+				Action:   platform.ReadAction,
+				Resource: bucketResource(2, nil),
+			},
+			permissions: []platform.Permission{
+				{
+					Action:   platform.ReadAction,
+					Resource: bucketResource(1, customerPredicate("a")),
+				},
+			},
+			allowed: false,
+		},
+		{
+			name: "predicate permission matches a narrower predicate",
+			permission: platform.Permission{
+				Action: platform.WriteAction,
+				Resource: bucketResource(1, &platform.ResourcePredicate{
+					Measurement: "cpu",
+					Tags:        []platform.Tag{{Key: "host", Value: "h1"}, {Key: "customer", Value: "a"}},
+				}),
+			},
+			permissions: []platform.Permission{
+				{
+					Action:   platform.WriteAction,
+					Resource: bucketResource(1, customerPredicate("a")),
+				},
+			},
+			allowed: true,
+		},
+		{
+			name: "predicate permission does not match another predicate",
+			permission: platform.Permission{
+				Action:   platform.WriteAction,
+				Resource: bucketResource(1, customerPredicate("b")),
+			},
+			permissions: []platform.Permission{
+				{
+					Action:   platform.WriteAction,
+					Resource: bucketResource(1, customerPredicate("a")),
+				},
+			},
+			allowed: false,
+		},
+		{
+			name: "bucket permission matches a predicate",
+			permission: platform.Permission{
+				Action:   platform.WriteAction,
+				Resource: bucketResource(1, customerPredicate("b")),
+			},
+			permissions: []platform.Permission{
+				{
+					Action:   platform.WriteAction,
+					Resource: bucketResource(1, nil),
+				},
+			},
+			allowed: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -285,6 +373,45 @@ func TestPermission_Valid(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid bucket permission with a predicate",
+			fields: fields{
+				Action:   platform.ReadAction,
+				Resource: bucketResource(1, customerPredicate("a")),
+			},
+		},
+		{
+			name: "invalid bucket permission with a predicate without an ID",
+			fields: fields{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:      platform.BucketsResourceType,
+					OrgID:     influxdbtesting.IDPtr(1),
+					Predicate: customerPredicate("a"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid permission with a predicate of a resource other than a bucket",
+			fields: fields{
+				Action: platform.ReadAction,
+				Resource: platform.Resource{
+					Type:      platform.TasksResourceType,
+					ID:        influxdbtesting.IDPtr(1),
+					Predicate: customerPredicate("a"),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid bucket permission with an empty predicate",
+			fields: fields{
+				Action:   platform.ReadAction,
+				Resource: bucketResource(1, &platform.ResourcePredicate{}),
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid permission without an action",
 			fields: fields{
@@ -417,6 +544,17 @@ func TestPermission_String(t *testing.T) {
 			},
 			want: `write:buckets/0000000000000001`,
 		},
+		{
+			name: "valid permission with a predicate",
+			fields: fields{
+				Action: platform.ReadAction,
+				Resource: bucketResource(1, &platform.ResourcePredicate{
+					Measurement: "cpu",
+					Tags:        []platform.Tag{{Key: "customer", Value: "a"}},
+				}),
+			},
+			want: `read:orgs/0000000000000001/buckets/0000000000000001[_measurement=cpu,customer=a]`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -435,3 +573,103 @@ func validID() *platform.ID {
 	id := platform.ID(100)
 	return &id
 }
+
+func TestPermissionSet_BucketPredicates(t *testing.T) {
+	a, b := customerPredicate("a"), customerPredicate("b")
+	tests := []struct {
+		name        string
+		permissions platform.PermissionSet
+		want        []platform.ResourcePredicate
+	}{
+		{
+			name: "predicates of the bucket",
+			permissions: platform.PermissionSet{
+				{Action: platform.ReadAction, Resource: bucketResource(1, a)},
+				{Action: platform.ReadAction, Resource: bucketResource(1, b)},
+				{Action: platform.WriteAction, Resource: bucketResource(1, b)},
+				{Action: platform.ReadAction, Resource: bucketResource(2, b)},
+			},
+			want: []platform.ResourcePredicate{*a, *b},
+		},
+		{
+			name: "none with a permission to read all the bucket",
+			permissions: platform.PermissionSet{
+				{Action: platform.ReadAction, Resource: bucketResource(1, a)},
+				{Action: platform.ReadAction, Resource: platform.Resource{Type: platform.BucketsResourceType, OrgID: influxdbtesting.IDPtr(1)}},
+			},
+		},
+		{
+			name: "none without a permission to read the bucket",
+			permissions: platform.PermissionSet{
+				{Action: platform.ReadAction, Resource: bucketResource(2, a)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.permissions.BucketPredicates(platform.ReadAction, 1, 1)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got predicates %v, expected %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissionSet_ReadsBucket(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions platform.PermissionSet
+		reads       bool
+	}{
+		{
+			name: "a permission to read all of the bucket",
+			permissions: platform.PermissionSet{
+				{Action: platform.ReadAction, Resource: bucketResource(1, nil)},
+			},
+			reads: true,
+		},
+		{
+			name: "a permission to read the points of the bucket with a predicate",
+			permissions: platform.PermissionSet{
+				{Action: platform.ReadAction, Resource: bucketResource(1, customerPredicate("a"))},
+			},
+			reads: true,
+		},
+		{
+			name: "not a permission to write the points of the bucket with a predicate",
+			permissions: platform.PermissionSet{
+				{Action: platform.WriteAction, Resource: bucketResource(1, customerPredicate("a"))},
+			},
+		},
+		{
+			name: "not a permission to read another bucket with a predicate",
+			permissions: platform.PermissionSet{
+				{Action: platform.ReadAction, Resource: bucketResource(2, customerPredicate("a"))},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.permissions.ReadsBucket(1, 1); got != tt.reads {
+				t.Errorf("got reads = %v, expected reads = %v", got, tt.reads)
+			}
+		})
+	}
+}
+
+func bucketResource(id platform.ID, pred *platform.ResourcePredicate) platform.Resource {
+	return platform.Resource{
+		Type:      platform.BucketsResourceType,
+		OrgID:     influxdbtesting.IDPtr(1),
+		ID:        &id,
+		Predicate: pred,
+	}
+}
+
+func customerPredicate(customer string) *platform.ResourcePredicate {
+	return &platform.ResourcePredicate{
+		Tags: []platform.Tag{{Key: "customer", Value: customer}},
+	}
+}
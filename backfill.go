@@ -0,0 +1,91 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultBackfillParallelism is the number of runs of a backfill that
+	// execute at once when no parallelism is requested.
+	DefaultBackfillParallelism = 1
+
+	// MaxBackfillParallelism is the largest number of runs of a backfill that
+	// may execute at once.
+	MaxBackfillParallelism = 10
+)
+
+// Backfill is a set of runs of a task, one for every point of the task's
+// schedule in a historical time range.
+type Backfill struct {
+	ID          ID         `json:"id"`
+	TaskID      ID         `json:"taskID"`
+	Start       time.Time  `json:"start"`
+	End         time.Time  `json:"end"`
+	Parallelism int        `json:"parallelism"`
+	Status      string     `json:"status"`
+	Total       int        `json:"total"`     // Total is the number of schedule points in the range
+	Completed   int        `json:"completed"` // Completed is the number of runs that have finished, successfully or not
+	Failed      int        `json:"failed"`    // Failed is the number of completed runs that failed
+	CreatedAt   time.Time  `json:"createdAt"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Done reports whether all runs of the backfill have finished, or it was canceled.
+func (b *Backfill) Done() bool {
+	return b.Status != RunStarted.String()
+}
+
+// BackfillCreate is the set of values to create a backfill with.
+type BackfillCreate struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Parallelism int       `json:"parallelism,omitempty"`
+}
+
+// Validate returns an error if the time range or parallelism are invalid.
+func (b BackfillCreate) Validate() error {
+	if b.Start.IsZero() || b.End.IsZero() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "backfill start and end are required",
+		}
+	}
+	if !b.Start.Before(b.End) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "backfill start must be before end",
+		}
+	}
+	if b.End.After(time.Now()) {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "backfill end must not be in the future",
+		}
+	}
+	if b.Parallelism < 0 || b.Parallelism > MaxBackfillParallelism {
+		return &Error{
+			Code: EInvalid,
+			Msg:  fmt.Sprintf("backfill parallelism must be between 1 and %d", MaxBackfillParallelism),
+		}
+	}
+	return nil
+}
+
+// BackfillService runs tasks over historical time ranges.
+type BackfillService interface {
+	// CreateBackfill enqueues a run of the task for every point of its schedule
+	// between the start and end of bc, inclusive.
+	CreateBackfill(ctx context.Context, taskID ID, bc BackfillCreate) (*Backfill, error)
+
+	// FindBackfillByID returns the progress of a single backfill of a task.
+	FindBackfillByID(ctx context.Context, taskID, id ID) (*Backfill, error)
+
+	// FindBackfills returns the backfills of a task.
+	FindBackfills(ctx context.Context, taskID ID) ([]*Backfill, error)
+
+	// CancelBackfill stops enqueuing the runs of a backfill and cancels those
+	// that are executing.
+	CancelBackfill(ctx context.Context, taskID, id ID) error
+}
@@ -34,16 +34,39 @@ const InfiniteRetention = 0
 
 // Bucket is a bucket. 🎉
 type Bucket struct {
-	ID                  ID            `json:"id,omitempty"`
-	OrgID               ID            `json:"orgID,omitempty"`
-	Type                BucketType    `json:"type"`
-	Name                string        `json:"name"`
-	Description         string        `json:"description"`
-	RetentionPolicyName string        `json:"rp,omitempty"` // This to support v1 sources
-	RetentionPeriod     time.Duration `json:"retentionPeriod"`
+	ID                  ID                     `json:"id,omitempty"`
+	OrgID               ID                     `json:"orgID,omitempty"`
+	Type                BucketType             `json:"type"`
+	Name                string                 `json:"name"`
+	Description         string                 `json:"description"`
+	RetentionPolicyName string                 `json:"rp,omitempty"` // This to support v1 sources
+	RetentionPeriod     time.Duration          `json:"retentionPeriod"`
+	StorageSettings     *BucketStorageSettings `json:"storageSettings,omitempty"`
 	CRUDLog
 }
 
+// BucketStorageSettings overrides the storage engine's compaction planner and
+// cache snapshot settings for the data of a bucket. Zero values use the
+// engine's configuration.
+type BucketStorageSettings struct {
+	// CompactFullWriteColdDuration is how long the bucket must go without
+	// writes before a full compaction is planned.
+	CompactFullWriteColdDuration time.Duration `json:"compactFullWriteColdDuration,omitempty"`
+
+	// CacheSnapshotMemorySize is the size of the bucket's cached data at which
+	// the cache is snapshot.
+	CacheSnapshotMemorySize uint64 `json:"cacheSnapshotMemorySize,omitempty"`
+
+	// CacheSnapshotWriteColdDuration is how long the bucket must go without
+	// writes before its cached data is snapshot.
+	CacheSnapshotWriteColdDuration time.Duration `json:"cacheSnapshotWriteColdDuration,omitempty"`
+}
+
+// IsZero returns true if no setting is overridden.
+func (s *BucketStorageSettings) IsZero() bool {
+	return s == nil || *s == BucketStorageSettings{}
+}
+
 // BucketType differentiates system buckets from user buckets.
 type BucketType int
 
@@ -101,9 +124,10 @@ type BucketService interface {
 // BucketUpdate represents updates to a bucket.
 // Only fields which are set are updated.
 type BucketUpdate struct {
-	Name            *string        `json:"name,omitempty"`
-	Description     *string        `json:"description,omitempty"`
-	RetentionPeriod *time.Duration `json:"retentionPeriod,omitempty"`
+	Name            *string                `json:"name,omitempty"`
+	Description     *string                `json:"description,omitempty"`
+	RetentionPeriod *time.Duration         `json:"retentionPeriod,omitempty"`
+	StorageSettings *BucketStorageSettings `json:"storageSettings,omitempty"`
 }
 
 // BucketFilter represents a set of filter that restrict the returned results.
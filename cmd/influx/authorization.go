@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"io"
+	"time"
 
 	platform "github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/cmd/influx/internal"
@@ -13,10 +14,30 @@ import (
 type token struct {
 	ID          platform.ID `json:"id"`
 	Token       string      `json:"token"`
+	TokenPrefix string      `json:"tokenPrefix,omitempty"`
 	Status      string      `json:"status"`
 	UserName    string      `json:"userName"`
 	UserID      platform.ID `json:"userID"`
 	Permissions []string    `json:"permissions"`
+	ExpiresAt   *time.Time  `json:"expiresAt,omitempty"`
+}
+
+// displayToken returns the token, or the prefix of the token when only the
+// prefix is known, which is the case for tokens that are not newly created.
+func (t token) displayToken() string {
+	if t.Token == "" && t.TokenPrefix != "" {
+		return t.TokenPrefix + "..."
+	}
+	return t.Token
+}
+
+// displayExpiresAt returns the time the token expires at, or nothing for
+// tokens that do not expire.
+func (t token) displayExpiresAt() string {
+	if t.ExpiresAt == nil {
+		return ""
+	}
+	return t.ExpiresAt.Format(time.RFC3339)
 }
 
 func cmdAuth(f *globalFlags, opt genericCLIOpts) *cobra.Command {
@@ -43,8 +64,9 @@ var authCRUDFlags struct {
 }
 
 var authCreateFlags struct {
-	user string
-	org  organization
+	user      string
+	org       organization
+	expiresIn time.Duration
 
 	writeUserPermission bool
 	readUserPermission  bool
@@ -89,6 +111,7 @@ func authCreateCmd() *cobra.Command {
 	authCreateFlags.org.register(cmd, false)
 
 	cmd.Flags().StringVarP(&authCreateFlags.user, "user", "u", "", "The user name")
+	cmd.Flags().DurationVarP(&authCreateFlags.expiresIn, "expires-in", "", 0, "The duration after which the token expires, the token does not expire when unset")
 	registerPrintOptions(cmd, &authCRUDFlags.hideHeaders, &authCRUDFlags.json)
 
 	cmd.Flags().BoolVarP(&authCreateFlags.writeUserPermission, "write-user", "", false, "Grants the permission to perform mutative actions against organization users")
@@ -251,6 +274,10 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 		Permissions: permissions,
 		OrgID:       orgID,
 	}
+	if authCreateFlags.expiresIn > 0 {
+		expiresAt := time.Now().Add(authCreateFlags.expiresIn).UTC()
+		authorization.ExpiresAt = &expiresAt
+	}
 
 	if userName := authCreateFlags.user; userName != "" {
 		user, err := userSvc.FindUser(context.Background(), platform.UserFilter{
@@ -287,6 +314,8 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 		token: token{
 			ID:          authorization.ID,
 			Token:       authorization.Token,
+			TokenPrefix: authorization.TokenPrefix,
+			ExpiresAt:   authorization.ExpiresAt,
 			Status:      string(authorization.Status),
 			UserName:    user.Name,
 			UserID:      user.ID,
@@ -296,9 +325,10 @@ func authorizationCreateF(cmd *cobra.Command, args []string) error {
 }
 
 var authorizationFindFlags struct {
-	org    organization
-	user   string
-	userID string
+	org           organization
+	user          string
+	userID        string
+	expiresWithin time.Duration
 }
 
 func authFindCmd() *cobra.Command {
@@ -313,6 +343,7 @@ func authFindCmd() *cobra.Command {
 	registerPrintOptions(cmd, &authCRUDFlags.hideHeaders, &authCRUDFlags.json)
 	cmd.Flags().StringVarP(&authorizationFindFlags.user, "user", "u", "", "The user")
 	cmd.Flags().StringVarP(&authorizationFindFlags.userID, "user-id", "", "", "The user ID")
+	cmd.Flags().DurationVarP(&authorizationFindFlags.expiresWithin, "expires-within", "", 0, "Only list the tokens that expire within the duration")
 
 	cmd.Flags().StringVarP(&authCRUDFlags.id, "id", "i", "", "The authorization ID")
 
@@ -358,6 +389,10 @@ func authorizationFindF(cmd *cobra.Command, args []string) error {
 		}
 		filter.OrgID = oID
 	}
+	if authorizationFindFlags.expiresWithin > 0 {
+		expiresBefore := time.Now().Add(authorizationFindFlags.expiresWithin).UTC()
+		filter.ExpiresBefore = &expiresBefore
+	}
 
 	authorizations, _, err := s.FindAuthorizations(context.Background(), filter)
 	if err != nil {
@@ -379,6 +414,8 @@ func authorizationFindF(cmd *cobra.Command, args []string) error {
 		tokens = append(tokens, token{
 			ID:          a.ID,
 			Token:       a.Token,
+			TokenPrefix: a.TokenPrefix,
+			ExpiresAt:   a.ExpiresAt,
 			Status:      string(a.Status),
 			UserName:    user.Name,
 			UserID:      a.UserID,
@@ -450,6 +487,8 @@ func authorizationDeleteF(cmd *cobra.Command, args []string) error {
 		token: token{
 			ID:          a.ID,
 			Token:       a.Token,
+			TokenPrefix: a.TokenPrefix,
+			ExpiresAt:   a.ExpiresAt,
 			Status:      string(a.Status),
 			UserName:    user.Name,
 			UserID:      user.ID,
@@ -516,6 +555,8 @@ func authorizationActiveF(cmd *cobra.Command, args []string) error {
 		token: token{
 			ID:          a.ID,
 			Token:       a.Token,
+			TokenPrefix: a.TokenPrefix,
+			ExpiresAt:   a.ExpiresAt,
 			Status:      string(a.Status),
 			UserName:    user.Name,
 			UserID:      user.ID,
@@ -582,6 +623,8 @@ func authorizationInactiveF(cmd *cobra.Command, args []string) error {
 		token: token{
 			ID:          a.ID,
 			Token:       a.Token,
+			TokenPrefix: a.TokenPrefix,
+			ExpiresAt:   a.ExpiresAt,
 			Status:      string(a.Status),
 			UserName:    user.Name,
 			UserID:      user.ID,
@@ -618,6 +661,7 @@ func writeTokens(w io.Writer, printOpts tokenPrintOpt) error {
 		"User Name",
 		"User ID",
 		"Permissions",
+		"Expires At",
 	}
 	if printOpts.deleted {
 		headers = append(headers, "Deleted")
@@ -631,10 +675,11 @@ func writeTokens(w io.Writer, printOpts tokenPrintOpt) error {
 	for _, t := range printOpts.tokens {
 		m := map[string]interface{}{
 			"ID":          t.ID.String(),
-			"Token":       t.Token,
+			"Token":       t.displayToken(),
 			"User Name":   t.UserName,
 			"User ID":     t.UserID.String(),
 			"Permissions": t.Permissions,
+			"Expires At":  t.displayExpiresAt(),
 		}
 		if printOpts.deleted {
 			m["Deleted"] = true
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/http"
+	"github.com/spf13/cobra"
+)
+
+type compactionSVCFn func() (influxdb.CompactionService, error)
+
+func cmdCompaction(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+	builder := newCmdCompactionBuilder(newCompactionService, opt)
+	builder.globalFlags = f
+	return builder.cmd()
+}
+
+type cmdCompactionBuilder struct {
+	genericCLIOpts
+	*globalFlags
+
+	svcFn compactionSVCFn
+
+	json        bool
+	hideHeaders bool
+	typ         string
+}
+
+func newCmdCompactionBuilder(svcFn compactionSVCFn, opt genericCLIOpts) *cmdCompactionBuilder {
+	return &cmdCompactionBuilder{
+		genericCLIOpts: opt,
+		svcFn:          svcFn,
+	}
+}
+
+func (b *cmdCompactionBuilder) cmd() *cobra.Command {
+	cmd := b.newCmd("compaction", nil, false)
+	cmd.Short = "Storage engine compaction management commands"
+	cmd.Run = seeHelp
+	cmd.AddCommand(
+		b.cmdStatus(),
+		b.cmdPause(),
+		b.cmdResume(),
+		b.cmdRun(),
+	)
+	return cmd
+}
+
+func (b *cmdCompactionBuilder) cmdStatus() *cobra.Command {
+	cmd := b.newCmd("status", b.cmdStatusRunEFn, true)
+	cmd.Short = "List active and queued compactions"
+	cmd.Aliases = []string{"list", "ls"}
+
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdCompactionBuilder) cmdStatusRunEFn(cmd *cobra.Command, args []string) error {
+	svc, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	status, err := svc.CompactionStatus(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to retrieve compaction status: %v", err)
+	}
+	return b.printStatus(status)
+}
+
+func (b *cmdCompactionBuilder) cmdPause() *cobra.Command {
+	cmd := b.newCmd("pause", b.cmdPauseRunEFn, true)
+	cmd.Short = "Abort running compactions and stop new ones from starting"
+
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdCompactionBuilder) cmdPauseRunEFn(cmd *cobra.Command, args []string) error {
+	svc, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := svc.PauseCompactions(ctx); err != nil {
+		return fmt.Errorf("failed to pause compactions: %v", err)
+	}
+
+	status, err := svc.CompactionStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve compaction status: %v", err)
+	}
+	return b.printStatus(status)
+}
+
+func (b *cmdCompactionBuilder) cmdResume() *cobra.Command {
+	cmd := b.newCmd("resume", b.cmdResumeRunEFn, true)
+	cmd.Short = "Resume paused compactions"
+
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdCompactionBuilder) cmdResumeRunEFn(cmd *cobra.Command, args []string) error {
+	svc, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := svc.ResumeCompactions(ctx); err != nil {
+		return fmt.Errorf("failed to resume compactions: %v", err)
+	}
+
+	status, err := svc.CompactionStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve compaction status: %v", err)
+	}
+	return b.printStatus(status)
+}
+
+func (b *cmdCompactionBuilder) cmdRun() *cobra.Command {
+	cmd := b.newCmd("run", b.cmdRunRunEFn, true)
+	cmd.Short = "Schedule a full or optimize compaction"
+
+	cmd.Flags().StringVarP(&b.typ, "type", "t", string(influxdb.CompactionTypeFull), `The type of compaction to run, "full" or "optimize"`)
+	b.registerPrintFlags(cmd)
+
+	return cmd
+}
+
+func (b *cmdCompactionBuilder) cmdRunRunEFn(cmd *cobra.Command, args []string) error {
+	typ := influxdb.CompactionType(b.typ)
+	if err := typ.Valid(); err != nil {
+		return err
+	}
+
+	svc, err := b.svcFn()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := svc.ScheduleCompaction(ctx, typ); err != nil {
+		return fmt.Errorf("failed to schedule %s compaction: %v", typ, err)
+	}
+
+	status, err := svc.CompactionStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve compaction status: %v", err)
+	}
+	return b.printStatus(status)
+}
+
+func (b *cmdCompactionBuilder) registerPrintFlags(cmd *cobra.Command) {
+	registerPrintOptions(cmd, &b.hideHeaders, &b.json)
+}
+
+func (b *cmdCompactionBuilder) printStatus(status *influxdb.CompactionStatus) error {
+	if b.json {
+		return b.writeJSON(status)
+	}
+
+	w := b.newTabWriter()
+	w.HideHeaders(b.hideHeaders)
+
+	w.WriteHeaders("Level", "Paused", "Active", "Queued", "Completed", "Errors")
+	for _, l := range status.Levels {
+		w.Write(map[string]interface{}{
+			"Level":     l.Level,
+			"Paused":    status.Paused,
+			"Active":    l.Active,
+			"Queued":    l.Queued,
+			"Completed": l.Completed,
+			"Errors":    l.Errors,
+		})
+	}
+	w.Flush()
+
+	var compactions int
+	for _, l := range status.Levels {
+		compactions += len(l.Compactions)
+	}
+	if compactions == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(b.w)
+
+	w = b.newTabWriter()
+	defer w.Flush()
+	w.HideHeaders(b.hideHeaders)
+
+	w.WriteHeaders("Level", "State", "Started", "Progress", "Files")
+	for _, l := range status.Levels {
+		for _, c := range l.Compactions {
+			state, started, progress := "queued", "", ""
+			if c.Active {
+				state = "active"
+				progress = fmt.Sprintf("%.0f%%", c.Progress*100)
+				if c.StartedAt != nil {
+					started = c.StartedAt.Format(time.RFC3339)
+				}
+			}
+			w.Write(map[string]interface{}{
+				"Level":    l.Level,
+				"State":    state,
+				"Started":  started,
+				"Progress": progress,
+				"Files":    strings.Join(c.Files, ","),
+			})
+		}
+	}
+	return nil
+}
+
+func newCompactionService() (influxdb.CompactionService, error) {
+	if flags.local {
+		return nil, fmt.Errorf("local flag not supported for compaction command")
+	}
+
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	return &http.CompactionService{Client: httpClient}, nil
+}
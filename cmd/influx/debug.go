@@ -28,6 +28,7 @@ var inspectReportTSMFlags struct {
 	pattern  string
 	exact    bool
 	detailed bool
+	codecs   bool
 	organization
 	bucketID string
 	dataDir  string
@@ -63,6 +64,9 @@ in the following ways:
 	* Series cardinality for each measurement;
 	* Number of field keys for each measurement; and
 	* Number of tag values for each tag key.
+
+The --codecs flag reads every block to report the number of blocks, points,
+encoded size and compression ratio for each block type and codec.
 `,
 		RunE: inspectReportTSMF,
 	}
@@ -70,6 +74,7 @@ in the following ways:
 	inspectReportTSMCommand.Flags().StringVarP(&inspectReportTSMFlags.pattern, "pattern", "", "", "only process TSM files containing pattern")
 	inspectReportTSMCommand.Flags().BoolVarP(&inspectReportTSMFlags.exact, "exact", "", false, "calculate and exact cardinality count. Warning, may use significant memory...")
 	inspectReportTSMCommand.Flags().BoolVarP(&inspectReportTSMFlags.detailed, "detailed", "", false, "emit series cardinality segmented by measurements, tag keys and fields. Warning, may take a while.")
+	inspectReportTSMCommand.Flags().BoolVarP(&inspectReportTSMFlags.codecs, "codecs", "", false, "emit compression ratios segmented by block type and codec. Warning, reads all block data.")
 
 	inspectReportTSMFlags.organization.register(inspectReportTSMCommand, false)
 	inspectReportTSMCommand.Flags().StringVarP(&inspectReportTSMFlags.bucketID, "bucket-id", "", "", "process only data belonging to bucket ID. Requires org flag to be set.")
@@ -94,6 +99,7 @@ func inspectReportTSMF(cmd *cobra.Command, args []string) error {
 		Pattern:  inspectReportTSMFlags.pattern,
 		Detailed: inspectReportTSMFlags.detailed,
 		Exact:    inspectReportTSMFlags.exact,
+		Codecs:   inspectReportTSMFlags.codecs,
 	}
 
 	if (inspectReportTSMFlags.organization.name == "" || inspectReportTSMFlags.organization.id == "") && inspectReportTSMFlags.bucketID != "" {
@@ -223,6 +223,7 @@ func influxCmd(opts ...genericCLIOptFn) *cobra.Command {
 		cmdAuth,
 		cmdBackup,
 		cmdBucket,
+		cmdCompaction,
 		cmdDelete,
 		cmdOrganization,
 		cmdPing,
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/influxdata/influxdb/v2"
@@ -29,6 +30,7 @@ func cmdTask(f *globalFlags, opt genericCLIOpts) *cobra.Command {
 	cmd.AddCommand(
 		taskLogCmd(opt),
 		taskRunCmd(opt),
+		taskBackfillCmd(opt),
 		taskCreateCmd(opt),
 		taskDeleteCmd(opt),
 		taskFindCmd(opt),
@@ -44,17 +46,22 @@ var taskPrintFlags struct {
 }
 
 var taskCreateFlags struct {
-	org  organization
-	file string
+	org      organization
+	file     string
+	template string
+	params   []string
 }
 
 func taskCreateCmd(opt genericCLIOpts) *cobra.Command {
 	cmd := opt.newCmd("create [script literal or -f /path/to/script.flux]", taskCreateF, true)
 	cmd.Args = cobra.MaximumNArgs(1)
 	cmd.Short = "Create task"
-	cmd.Long = `Create a task with a Flux script provided via the first argument or a file or stdin`
+	cmd.Long = `Create a task with a Flux script provided via the first argument or a file or stdin,
+or instantiate a task from a task template with the values of its parameters`
 
 	cmd.Flags().StringVarP(&taskCreateFlags.file, "file", "f", "", "Path to Flux script file")
+	cmd.Flags().StringVarP(&taskCreateFlags.template, "template", "t", "", "ID of the task template to instantiate the task from")
+	cmd.Flags().StringArrayVarP(&taskCreateFlags.params, "param", "p", nil, "Value of a template parameter as key=value; may be repeated")
 	taskCreateFlags.org.register(cmd, false)
 	registerPrintOptions(cmd, &taskPrintFlags.hideHeaders, &taskPrintFlags.json)
 
@@ -75,15 +82,27 @@ func taskCreateF(cmd *cobra.Command, args []string) error {
 		Client: client,
 	}
 
-	flux, err := readFluxQuery(args, taskCreateFlags.file)
-	if err != nil {
-		return fmt.Errorf("error parsing flux script: %s", err)
-	}
-
 	tc := influxdb.TaskCreate{
-		Flux:         flux,
 		Organization: taskCreateFlags.org.name,
 	}
+	if taskCreateFlags.template != "" {
+		if len(args) > 0 || taskCreateFlags.file != "" {
+			return fmt.Errorf("cannot specify both a flux script and a template")
+		}
+		if err := tc.TemplateID.DecodeFromString(taskCreateFlags.template); err != nil {
+			return fmt.Errorf("error parsing template ID: %s", err)
+		}
+		if tc.TemplateParams, err = parseTemplateParams(taskCreateFlags.params); err != nil {
+			return err
+		}
+	} else {
+		if len(taskCreateFlags.params) > 0 {
+			return fmt.Errorf("cannot specify template parameters without a template")
+		}
+		if tc.Flux, err = readFluxQuery(args, taskCreateFlags.file); err != nil {
+			return fmt.Errorf("error parsing flux script: %s", err)
+		}
+	}
 	if taskCreateFlags.org.id != "" || taskCreateFlags.org.name != "" {
 		svc, err := newOrganizationService()
 		if err != nil {
@@ -111,6 +130,21 @@ func taskCreateF(cmd *cobra.Command, args []string) error {
 	)
 }
 
+// parseTemplateParams parses the values of template parameters given as
+// key=value. The values are left as strings for the server to convert to the
+// types the template declares.
+func parseTemplateParams(params []string) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(params))
+	for _, p := range params {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid template parameter %q, expected key=value", p)
+		}
+		values[kv[0]] = kv[1]
+	}
+	return values, nil
+}
+
 var taskFindFlags struct {
 	user    string
 	id      string
@@ -616,3 +650,245 @@ func runRetryF(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+func taskBackfillCmd(opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("backfill", nil, false)
+	cmd.Run = seeHelp
+	cmd.Short = "Run a task over a historical time range"
+	cmd.AddCommand(
+		taskBackfillCreateCmd(opt),
+		taskBackfillFindCmd(opt),
+		taskBackfillCancelCmd(opt),
+	)
+
+	return cmd
+}
+
+var taskBackfillCreateFlags struct {
+	taskID      string
+	start       string
+	end         string
+	parallelism int
+	wait        bool
+}
+
+func taskBackfillCreateCmd(opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("create", taskBackfillCreateF, true)
+	cmd.Short = "Queue a run of a task for every point of its schedule in a time range"
+
+	registerPrintOptions(cmd, &taskPrintFlags.hideHeaders, &taskPrintFlags.json)
+	cmd.Flags().StringVarP(&taskBackfillCreateFlags.taskID, "task-id", "i", "", "task id (required)")
+	cmd.Flags().StringVarP(&taskBackfillCreateFlags.start, "start", "", "", "the start time in RFC3339 format, exp 2009-01-02T23:00:00Z (required)")
+	cmd.Flags().StringVarP(&taskBackfillCreateFlags.end, "end", "", "", "the end time in RFC3339 format, exp 2009-01-02T23:00:00Z (required)")
+	cmd.Flags().IntVarP(&taskBackfillCreateFlags.parallelism, "parallelism", "p", influxdb.DefaultBackfillParallelism, "the number of runs to execute at once")
+	cmd.Flags().BoolVarP(&taskBackfillCreateFlags.wait, "wait", "w", false, "wait for the backfill to finish, printing its progress")
+	cmd.MarkFlagRequired("task-id")
+	cmd.MarkFlagRequired("start")
+	cmd.MarkFlagRequired("end")
+
+	return cmd
+}
+
+func taskBackfillCreateF(cmd *cobra.Command, args []string) error {
+	client, err := newHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	s := &http.TaskService{
+		Client: client,
+	}
+
+	var taskID influxdb.ID
+	if err := taskID.DecodeFromString(taskBackfillCreateFlags.taskID); err != nil {
+		return err
+	}
+
+	bc := influxdb.BackfillCreate{
+		Parallelism: taskBackfillCreateFlags.parallelism,
+	}
+	if bc.Start, err = time.Parse(time.RFC3339, taskBackfillCreateFlags.start); err != nil {
+		return fmt.Errorf("invalid start time: %v", err)
+	}
+	if bc.End, err = time.Parse(time.RFC3339, taskBackfillCreateFlags.end); err != nil {
+		return fmt.Errorf("invalid end time: %v", err)
+	}
+
+	ctx := context.Background()
+	b, err := s.CreateBackfill(ctx, taskID, bc)
+	if err != nil {
+		return err
+	}
+
+	if taskBackfillCreateFlags.wait {
+		if b, err = waitForBackfill(ctx, cmd.ErrOrStderr(), s, b); err != nil {
+			return err
+		}
+	}
+
+	return printBackfill(cmd.OutOrStdout(), b)
+}
+
+// waitForBackfill polls the progress of b until it is done, printing it to w.
+func waitForBackfill(ctx context.Context, w io.Writer, s *http.TaskService, b *influxdb.Backfill) (*influxdb.Backfill, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for !b.Done() {
+		fmt.Fprintf(w, "Backfill %s: %d/%d runs completed, %d failed\n", b.ID, b.Completed, b.Total, b.Failed)
+		<-ticker.C
+
+		var err error
+		if b, err = s.FindBackfillByID(ctx, b.TaskID, b.ID); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+var taskBackfillFindFlags struct {
+	taskID     string
+	backfillID string
+}
+
+func taskBackfillFindCmd(opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("list", taskBackfillFindF, true)
+	cmd.Short = "List backfills of a task and their progress"
+	cmd.Aliases = []string{"find", "ls"}
+
+	registerPrintOptions(cmd, &taskPrintFlags.hideHeaders, &taskPrintFlags.json)
+	cmd.Flags().StringVarP(&taskBackfillFindFlags.taskID, "task-id", "i", "", "task id (required)")
+	cmd.Flags().StringVarP(&taskBackfillFindFlags.backfillID, "backfill-id", "b", "", "backfill id")
+	cmd.MarkFlagRequired("task-id")
+
+	return cmd
+}
+
+func taskBackfillFindF(cmd *cobra.Command, args []string) error {
+	client, err := newHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	s := &http.TaskService{
+		Client: client,
+	}
+
+	var taskID influxdb.ID
+	if err := taskID.DecodeFromString(taskBackfillFindFlags.taskID); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if taskBackfillFindFlags.backfillID != "" {
+		var id influxdb.ID
+		if err := id.DecodeFromString(taskBackfillFindFlags.backfillID); err != nil {
+			return err
+		}
+		b, err := s.FindBackfillByID(ctx, taskID, id)
+		if err != nil {
+			return err
+		}
+		return printBackfill(cmd.OutOrStdout(), b)
+	}
+
+	bs, err := s.FindBackfills(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	return printBackfills(cmd.OutOrStdout(), bs)
+}
+
+var taskBackfillCancelFlags struct {
+	taskID     string
+	backfillID string
+}
+
+func taskBackfillCancelCmd(opt genericCLIOpts) *cobra.Command {
+	cmd := opt.newCmd("cancel", taskBackfillCancelF, true)
+	cmd.Short = "Cancel a backfill and its queued and executing runs"
+
+	cmd.Flags().StringVarP(&taskBackfillCancelFlags.taskID, "task-id", "i", "", "task id (required)")
+	cmd.Flags().StringVarP(&taskBackfillCancelFlags.backfillID, "backfill-id", "b", "", "backfill id (required)")
+	cmd.MarkFlagRequired("task-id")
+	cmd.MarkFlagRequired("backfill-id")
+
+	return cmd
+}
+
+func taskBackfillCancelF(cmd *cobra.Command, args []string) error {
+	client, err := newHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	s := &http.TaskService{
+		Client: client,
+	}
+
+	var taskID, id influxdb.ID
+	if err := taskID.DecodeFromString(taskBackfillCancelFlags.taskID); err != nil {
+		return err
+	}
+	if err := id.DecodeFromString(taskBackfillCancelFlags.backfillID); err != nil {
+		return err
+	}
+
+	if err := s.CancelBackfill(context.Background(), taskID, id); err != nil {
+		return err
+	}
+
+	fmt.Printf("Backfill %s of task %s canceled.\n", id, taskID)
+
+	return nil
+}
+
+func printBackfill(w io.Writer, b *influxdb.Backfill) error {
+	if taskPrintFlags.json {
+		return writeJSON(w, b)
+	}
+	return printBackfills(w, []*influxdb.Backfill{b})
+}
+
+func printBackfills(w io.Writer, bs []*influxdb.Backfill) error {
+	if taskPrintFlags.json {
+		if bs == nil {
+			// guarantee we never return a null value from CLI
+			bs = make([]*influxdb.Backfill, 0)
+		}
+		return writeJSON(w, bs)
+	}
+
+	tabW := internal.NewTabWriter(w)
+	defer tabW.Flush()
+
+	tabW.HideHeaders(taskPrintFlags.hideHeaders)
+
+	tabW.WriteHeaders(
+		"ID",
+		"TaskID",
+		"Status",
+		"Start",
+		"End",
+		"Parallelism",
+		"Completed",
+		"Failed",
+		"Total",
+	)
+
+	for _, b := range bs {
+		tabW.Write(map[string]interface{}{
+			"ID":          b.ID,
+			"TaskID":      b.TaskID,
+			"Status":      b.Status,
+			"Start":       b.Start.Format(time.RFC3339),
+			"End":         b.End.Format(time.RFC3339),
+			"Parallelism": b.Parallelism,
+			"Completed":   b.Completed,
+			"Failed":      b.Failed,
+			"Total":       b.Total,
+		})
+	}
+
+	return nil
+}
@@ -16,6 +16,7 @@ var reportTSMFlags = struct {
 	pattern  string
 	exact    bool
 	detailed bool
+	codecs   bool
 
 	orgID, bucketID string
 	dataDir         string
@@ -52,13 +53,17 @@ in the following ways:
 	* Series cardinality for each bucket;
 	* Series cardinality for each measurement;
 	* Number of field keys for each measurement; and
-	* Number of tag values for each tag key.`,
+	* Number of tag values for each tag key.
+
+The --codecs flag reads every block to report the number of blocks, points,
+encoded size and compression ratio for each block type and codec.`,
 		RunE: inspectReportTSMF,
 	}
 
 	reportTSMCommand.Flags().StringVarP(&reportTSMFlags.pattern, "pattern", "", "", "only process TSM files containing pattern")
 	reportTSMCommand.Flags().BoolVarP(&reportTSMFlags.exact, "exact", "", false, "calculate and exact cardinality count. Warning, may use significant memory...")
 	reportTSMCommand.Flags().BoolVarP(&reportTSMFlags.detailed, "detailed", "", false, "emit series cardinality segmented by measurements, tag keys and fields. Warning, may take a while.")
+	reportTSMCommand.Flags().BoolVarP(&reportTSMFlags.codecs, "codecs", "", false, "emit compression ratios segmented by block type and codec. Warning, reads all block data.")
 
 	reportTSMCommand.Flags().StringVarP(&reportTSMFlags.orgID, "org-id", "", "", "process only data belonging to organization ID.")
 	reportTSMCommand.Flags().StringVarP(&reportTSMFlags.bucketID, "bucket-id", "", "", "process only data belonging to bucket ID. Requires org flag to be set.")
@@ -82,6 +87,7 @@ func inspectReportTSMF(cmd *cobra.Command, args []string) error {
 		Pattern:  reportTSMFlags.pattern,
 		Detailed: reportTSMFlags.detailed,
 		Exact:    reportTSMFlags.exact,
+		Codecs:   reportTSMFlags.codecs,
 	}
 
 	if reportTSMFlags.orgID == "" && reportTSMFlags.bucketID != "" {
@@ -28,8 +28,11 @@ type Engine interface {
 	reads.Viewer
 	storage.PointsWriter
 	storage.BucketDeleter
+	storage.BucketSettingsSetter
 	prom.PrometheusCollector
 	influxdb.BackupService
+	influxdb.CompactionService
+	influxdb.ReplicationService
 
 	SeriesCardinality() int64
 
@@ -125,6 +128,11 @@ func (t *TemporaryEngine) DeleteBucket(ctx context.Context, orgID, bucketID infl
 	return t.engine.DeleteBucket(ctx, orgID, bucketID)
 }
 
+// SetBucketSettings overrides the storage settings of a bucket.
+func (t *TemporaryEngine) SetBucketSettings(bucketID influxdb.ID, settings *influxdb.BucketStorageSettings) {
+	t.engine.SetBucketSettings(bucketID, settings)
+}
+
 // WithLogger sets the logger on the engine. It must be called before Open.
 func (t *TemporaryEngine) WithLogger(log *zap.Logger) {
 	t.log = log.With(zap.String("service", "temporary_engine"))
@@ -167,6 +175,34 @@ func (t *TemporaryEngine) Flush(ctx context.Context) {
 	}
 }
 
+func (t *TemporaryEngine) CompactionStatus(ctx context.Context) (*influxdb.CompactionStatus, error) {
+	return t.engine.CompactionStatus(ctx)
+}
+
+func (t *TemporaryEngine) PauseCompactions(ctx context.Context) error {
+	return t.engine.PauseCompactions(ctx)
+}
+
+func (t *TemporaryEngine) ResumeCompactions(ctx context.Context) error {
+	return t.engine.ResumeCompactions(ctx)
+}
+
+func (t *TemporaryEngine) ScheduleCompaction(ctx context.Context, typ influxdb.CompactionType) error {
+	return t.engine.ScheduleCompaction(ctx, typ)
+}
+
+func (t *TemporaryEngine) ReplicationStatus(ctx context.Context) (*influxdb.ReplicationStatus, error) {
+	return t.engine.ReplicationStatus(ctx)
+}
+
+func (t *TemporaryEngine) WALPosition(ctx context.Context) (*influxdb.WALPosition, error) {
+	return t.engine.WALPosition(ctx)
+}
+
+func (t *TemporaryEngine) ApplyWAL(ctx context.Context, pos influxdb.WALPosition, r io.Reader) (*influxdb.WALPosition, error) {
+	return t.engine.ApplyWAL(ctx, pos, r)
+}
+
 func (t *TemporaryEngine) CreateBackup(ctx context.Context) (int, []string, error) {
 	return t.engine.CreateBackup(ctx)
 }
@@ -6,17 +6,20 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"net"
 	nethttp "net/http"
 	_ "net/http/pprof" // needed to add pprof to our binary.
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/influxdata/flux"
 	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/audit"
 	"github.com/influxdata/influxdb/v2/authorization"
 	"github.com/influxdata/influxdb/v2/authorizer"
 	"github.com/influxdata/influxdb/v2/bolt"
@@ -36,6 +39,7 @@ import (
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
 	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/ldap"
 	influxlogger "github.com/influxdata/influxdb/v2/logger"
 	"github.com/influxdata/influxdb/v2/nats"
 	"github.com/influxdata/influxdb/v2/pkger"
@@ -43,9 +47,11 @@ import (
 	"github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxdb/v2/query/control"
 	"github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/v2/role"
 	"github.com/influxdata/influxdb/v2/session"
 	"github.com/influxdata/influxdb/v2/snowflake"
 	"github.com/influxdata/influxdb/v2/source"
+	"github.com/influxdata/influxdb/v2/sqlkv"
 	"github.com/influxdata/influxdb/v2/storage"
 	storageflux "github.com/influxdata/influxdb/v2/storage/flux"
 	"github.com/influxdata/influxdb/v2/storage/readservice"
@@ -56,6 +62,7 @@ import (
 	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
 	"github.com/influxdata/influxdb/v2/telemetry"
 	"github.com/influxdata/influxdb/v2/tenant"
+	"github.com/influxdata/influxdb/v2/toml"
 	_ "github.com/influxdata/influxdb/v2/tsdb/tsi1" // needed for tsi1
 	_ "github.com/influxdata/influxdb/v2/tsdb/tsm1" // needed for tsm1
 	"github.com/influxdata/influxdb/v2/vault"
@@ -73,6 +80,11 @@ const (
 	BoltStore = "bolt"
 	// MemoryStore stores all REST resources in memory (useful for testing).
 	MemoryStore = "memory"
+	// SQLiteStore stores all REST resources in a SQLite database.
+	SQLiteStore = "sqlite"
+	// PostgresStore stores all REST resources in a PostgreSQL database,
+	// which can be shared by multiple nodes.
+	PostgresStore = "postgres"
 
 	// LogTracing enables tracing via zap logs
 	LogTracing = "log"
@@ -166,7 +178,12 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			DestP:   &l.storeType,
 			Flag:    "store",
 			Default: "bolt",
-			Desc:    "backing store for REST resources (bolt or memory)",
+			Desc:    "backing store for REST resources (bolt, memory, sqlite or postgres)",
+		},
+		{
+			DestP: &l.storeDSN,
+			Flag:  "store-dsn",
+			Desc:  "data source name of the sqlite or postgres store; the path of the database file for sqlite, defaulting to influxd.sqlite next to bolt-path",
 		},
 		{
 			DestP:   &l.testing,
@@ -180,12 +197,56 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: filepath.Join(dir, "engine"),
 			Desc:    "path to persistent engine files",
 		},
+		{
+			DestP:   &l.StorageConfig.Replication.Follower,
+			Flag:    "wal-replication-follower",
+			Default: false,
+			Desc:    "run the storage engine as a read-only hot standby that applies the WAL segments shipped by a leader",
+		},
+		{
+			DestP: &l.StorageConfig.Replication.FollowerURL,
+			Flag:  "wal-replication-follower-url",
+			Desc:  "URL of the follower influxd to ship WAL segments to",
+		},
+		{
+			DestP: &l.StorageConfig.Replication.FollowerToken,
+			Flag:  "wal-replication-follower-token",
+			Desc:  "operator token used to authenticate with the follower influxd",
+		},
+		{
+			DestP:   &l.StorageConfig.Replication.ShipLiveSegment,
+			Flag:    "wal-replication-ship-live-segment",
+			Default: false,
+			Desc:    "ship the WAL segment currently being written to, as well as closed segments",
+		},
+		{
+			DestP:   (*time.Duration)(&l.StorageConfig.Replication.ShipInterval),
+			Flag:    "wal-replication-ship-interval",
+			Default: time.Duration(storage.DefaultReplicationShipInterval),
+			Desc:    "how often new WAL data is shipped to the follower",
+		},
+		{
+			DestP:   &l.walReplicationMaxLagSize,
+			Flag:    "wal-replication-max-lag-size",
+			Default: int(storage.DefaultReplicationMaxLagSize),
+			Desc:    "bytes of WAL data not yet shipped to the follower above which WAL segments written to TSM files are removed anyway, after which the follower must be restored from a backup (0 keeps them until shipped)",
+		},
 		{
 			DestP:   &l.secretStore,
 			Flag:    "secret-store",
 			Default: "bolt",
 			Desc:    "data store for secrets (bolt or vault)",
 		},
+		{
+			DestP: &l.secretMasterKeys,
+			Flag:  "secret-master-keys",
+			Desc:  "base64 encoded 256-bit keys with which bolt secrets are encrypted at rest; the first key encrypts, the others are previous keys whose secrets are re-encrypted with it at startup",
+		},
+		{
+			DestP: &l.secretMasterKeysFile,
+			Flag:  "secret-master-keys-file",
+			Desc:  "path to a file of secret master keys, one per line, in the order of secret-master-keys",
+		},
 		{
 			DestP:   &l.reportingDisabled,
 			Flag:    "reporting-disabled",
@@ -278,6 +339,192 @@ func buildLauncherCommand(l *Launcher, cmd *cobra.Command) {
 			Default: false,
 			Desc:    "disables the task scheduler",
 		},
+		{
+			DestP:   &l.taskRetryBackoff,
+			Flag:    "task-retry-backoff",
+			Default: executor.DefaultRetryBackoff,
+			Desc:    "how long to wait before the first automatic retry of a failed task run; the wait doubles with each attempt",
+		},
+		{
+			DestP:   &l.taskRetryMaxBackoff,
+			Flag:    "task-retry-max-backoff",
+			Default: executor.DefaultMaxRetryBackoff,
+			Desc:    "the longest to wait before an automatic retry of a failed task run",
+		},
+		{
+			DestP:   &l.taskRunRetention,
+			Flag:    "task-run-retention",
+			Default: time.Duration(0),
+			Desc:    "how long the history of task runs is kept for tasks without their own run retention; runs that were never finished are pruned as well (0 keeps completed runs as long as the system bucket does)",
+		},
+		{
+			DestP:   &l.taskRunRetentionCount,
+			Flag:    "task-run-retention-count",
+			Default: 0,
+			Desc:    "how many of the most recent completed runs are kept for tasks without their own run retention (0 keeps all of them)",
+		},
+		{
+			DestP: &l.oidcConfig.Issuer,
+			Flag:  "oidc-issuer",
+			Desc:  "URL of the OpenID Connect identity provider that users sign in with at /api/v2/signin/oidc (users only sign in with passwords when unset)",
+		},
+		{
+			DestP: &l.oidcConfig.ClientID,
+			Flag:  "oidc-client-id",
+			Desc:  "client ID registered with the OpenID Connect identity provider",
+		},
+		{
+			DestP: &l.oidcConfig.ClientSecret,
+			Flag:  "oidc-client-secret",
+			Desc:  "client secret registered with the OpenID Connect identity provider",
+		},
+		{
+			DestP: &l.oidcConfig.StateKey,
+			Flag:  "oidc-state-key",
+			Desc:  "secret that seals the state of OpenID Connect sign ins in a browser cookie, shared by the nodes that serve the same users (defaults to oidc-client-secret)",
+		},
+		{
+			DestP: &l.oidcConfig.RedirectURL,
+			Flag:  "oidc-redirect-url",
+			Desc:  "external URL of /api/v2/signin/oidc/callback, registered with the OpenID Connect identity provider",
+		},
+		{
+			DestP:   &l.oidcConfig.Scopes,
+			Flag:    "oidc-scopes",
+			Default: []string{"profile", "email", "groups"},
+			Desc:    "scopes requested from the OpenID Connect identity provider in addition to openid",
+		},
+		{
+			DestP:   &l.oidcConfig.UsernameClaim,
+			Flag:    "oidc-username-claim",
+			Default: "preferred_username",
+			Desc:    "claim of the ID token that is the name of the user",
+		},
+		{
+			DestP:   &l.oidcConfig.GroupsClaim,
+			Flag:    "oidc-groups-claim",
+			Default: "groups",
+			Desc:    "claim of the ID token that lists the groups of the user",
+		},
+		{
+			DestP: &l.oidcGroupMappings,
+			Flag:  "oidc-group-mapping",
+			Desc:  "mapping of a group of the identity provider to a role in an organization, of the form group=org:role where role is owner or member; users are removed from the organizations of the mappings of groups they leave",
+		},
+		{
+			DestP: &l.ldapConfig.URL,
+			Flag:  "ldap-url",
+			Desc:  "URL of the LDAP directory that users sign in against, of the form ldap://host:port or ldaps://host:port (users sign in with their local passwords when unset)",
+		},
+		{
+			DestP: &l.ldapConfig.StartTLS,
+			Flag:  "ldap-start-tls",
+			Desc:  "upgrade ldap:// connections to the LDAP directory to TLS",
+		},
+		{
+			DestP: &l.ldapConfig.InsecureSkipVerify,
+			Flag:  "ldap-insecure-skip-verify",
+			Desc:  "skip the verification of the TLS certificate of the LDAP directory",
+		},
+		{
+			DestP: &l.ldapConfig.CACertPath,
+			Flag:  "ldap-ca-cert",
+			Desc:  "path of the PEM certificates that the TLS certificate of the LDAP directory is verified with (the system certificates when unset)",
+		},
+		{
+			DestP:   &l.ldapConfig.Timeout,
+			Flag:    "ldap-timeout",
+			Default: ldap.DefaultTimeout,
+			Desc:    "how long the requests to the LDAP directory may take",
+		},
+		{
+			DestP: &l.ldapConfig.BindDN,
+			Flag:  "ldap-bind-dn",
+			Desc:  "DN that users are searched in the LDAP directory with (users are searched anonymously when unset)",
+		},
+		{
+			DestP: &l.ldapConfig.BindPassword,
+			Flag:  "ldap-bind-password",
+			Desc:  "password of the bind DN",
+		},
+		{
+			DestP: &l.ldapConfig.UserBaseDN,
+			Flag:  "ldap-user-base-dn",
+			Desc:  "DN under which users are searched in the LDAP directory",
+		},
+		{
+			DestP:   &l.ldapConfig.UserFilter,
+			Flag:    "ldap-user-filter",
+			Default: ldap.DefaultUserFilter,
+			Desc:    "filter that users are searched with, in which %s is the name of the user that signs in",
+		},
+		{
+			DestP:   &l.ldapConfig.GroupAttribute,
+			Flag:    "ldap-group-attribute",
+			Default: ldap.DefaultGroupAttribute,
+			Desc:    "attribute of users that lists the DNs of their groups",
+		},
+		{
+			DestP: &l.ldapConfig.GroupBaseDN,
+			Flag:  "ldap-group-base-dn",
+			Desc:  "DN under which the groups of users are searched in the LDAP directory (groups are only read from the group attribute of users when unset)",
+		},
+		{
+			DestP:   &l.ldapConfig.GroupFilter,
+			Flag:    "ldap-group-filter",
+			Default: ldap.DefaultGroupFilter,
+			Desc:    "filter that the groups of users are searched with, in which %s is the DN of the user",
+		},
+		{
+			DestP: &l.ldapGroupMappings,
+			Flag:  "ldap-group-mapping",
+			Desc:  "mapping of the DN of a group of the LDAP directory to a role in an organization, of the form group=org:role where role is owner or member; users are removed from the organizations of the mappings of groups they leave",
+		},
+		{
+			DestP: &l.ldapConfig.LocalUsers,
+			Flag:  "ldap-local-users",
+			Desc:  "users that sign in with their local passwords rather than against the LDAP directory, such as the initial operator user",
+		},
+		{
+			DestP: &l.auditLogPath,
+			Flag:  "audit-log-path",
+			Desc:  "path of the append-only log of the API requests that create, update or delete resources, or that are denied (no requests are audited when unset)",
+		},
+		{
+			DestP:   &l.tokenSweepInterval,
+			Flag:    "token-sweep-interval",
+			Default: authorization.DefaultSweepInterval,
+			Desc:    "how often expired tokens are deleted (0 does not delete them)",
+		},
+		{
+			DestP:   &l.taskOrgConcurrency,
+			Flag:    "task-org-concurrency",
+			Default: 0,
+			Desc:    "the maximum number of task runs of an organization that are executed at once; the queued runs of organizations take turns (0 does not limit the runs)",
+		},
+		{
+			DestP:   &l.taskOrgMemoryBytes,
+			Flag:    "task-org-query-memory-bytes",
+			Default: 0,
+			Desc:    "the maximum number of bytes the query of a task run is allowed to use; it cannot exceed query-memory-bytes (0 uses query-memory-bytes)",
+		},
+		{
+			DestP: &l.taskOrgLimits,
+			Flag:  "task-org-limit",
+			Desc:  "limits of the task runs of an organization, in place of task-org-concurrency and task-org-query-memory-bytes, of the form orgID=concurrency[:memoryBytes] (0 keeps the limit of all organizations)",
+		},
+		{
+			DestP:   &l.taskLeaseTTL,
+			Flag:    "task-lease-ttl",
+			Default: time.Duration(0),
+			Desc:    "how long a scheduler owns a task after it last renewed its lease; enables sharing tasks between influxd instances that use the same store (0 disables task leases)",
+		},
+		{
+			DestP:   &l.taskLeaseOwner,
+			Flag:    "task-lease-owner",
+			Default: "",
+			Desc:    "the name this instance holds task leases under; defaults to the hostname and a random ID",
+		},
 		{
 			DestP:   &l.concurrencyQuota,
 			Flag:    "query-concurrency",
@@ -325,6 +572,7 @@ type Launcher struct {
 	running bool
 
 	storeType            string
+	storeDSN             string
 	assetsPath           string
 	testing              bool
 	sessionLength        int // in minutes
@@ -339,6 +587,9 @@ type Launcher struct {
 	enginePath      string
 	secretStore     string
 
+	secretMasterKeys     []string
+	secretMasterKeysFile string
+
 	enableNewMetaStore bool
 
 	featureFlags map[string]string
@@ -352,10 +603,13 @@ type Launcher struct {
 
 	boltClient    *bolt.Client
 	kvStore       kv.Store
+	sqlStore      *sqlkv.KVStore
 	kvService     *kv.Service
 	engine        Engine
 	StorageConfig storage.Config
 
+	walReplicationMaxLagSize int
+
 	queryController *control.Controller
 
 	httpPort    int
@@ -366,10 +620,30 @@ type Launcher struct {
 	natsServer *nats.Server
 	natsPort   int
 
-	noTasks            bool
-	scheduler          stoppingScheduler
-	executor           *executor.Executor
-	taskControlService taskbackend.TaskControlService
+	tokenSweepInterval time.Duration
+
+	auditLogPath string
+	auditService *audit.FileService
+
+	oidcConfig        session.OIDCConfig
+	oidcGroupMappings []string
+	ldapConfig        ldap.Config
+	ldapGroupMappings []string
+
+	noTasks               bool
+	taskRetryBackoff      time.Duration
+	taskRetryMaxBackoff   time.Duration
+	taskLeaseTTL          time.Duration
+	taskLeaseOwner        string
+	taskRunRetention      time.Duration
+	taskRunRetentionCount int
+	taskOrgConcurrency    int
+	taskOrgMemoryBytes    int
+	taskOrgLimits         []string
+	scheduler             stoppingScheduler
+	executor              *executor.Executor
+	writeTrigger          *taskbackend.WriteTrigger
+	taskControlService    taskbackend.TaskControlService
 
 	jaegerTracerCloser io.Closer
 	log                *zap.Logger
@@ -439,15 +713,35 @@ func (m *Launcher) Shutdown(ctx context.Context) {
 	m.log.Info("Stopping", zap.String("service", "task"))
 
 	m.scheduler.Stop()
+	if m.writeTrigger != nil {
+		m.writeTrigger.Close()
+	}
+	if m.executor != nil {
+		m.executor.Close()
+	}
 
 	m.log.Info("Stopping", zap.String("service", "nats"))
 	m.natsServer.Close()
 
+	if m.auditService != nil {
+		m.log.Info("Stopping", zap.String("service", "audit"))
+		if err := m.auditService.Close(); err != nil {
+			m.log.Info("Failed closing audit log", zap.Error(err))
+		}
+	}
+
 	m.log.Info("Stopping", zap.String("service", "bolt"))
 	if err := m.boltClient.Close(); err != nil {
 		m.log.Info("Failed closing bolt", zap.Error(err))
 	}
 
+	if m.sqlStore != nil {
+		m.log.Info("Stopping", zap.String("service", "kvstore-"+m.storeType))
+		if err := m.sqlStore.Close(); err != nil {
+			m.log.Info("Failed closing sql store", zap.Error(err))
+		}
+	}
+
 	m.log.Info("Stopping", zap.String("service", "query"))
 	if err := m.queryController.Shutdown(ctx); err != nil && err != context.Canceled {
 		m.log.Info("Failed closing query service", zap.Error(err))
@@ -547,8 +841,15 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	secretMasterKeys, err := readSecretMasterKeys(m.secretMasterKeys, m.secretMasterKeysFile)
+	if err != nil {
+		m.log.Error("Invalid secret master keys", zap.Error(err))
+		return err
+	}
+
 	serviceConfig := kv.ServiceConfig{
-		SessionLength: time.Duration(m.sessionLength) * time.Minute,
+		SessionLength:    time.Duration(m.sessionLength) * time.Minute,
+		SecretMasterKeys: secretMasterKeys,
 	}
 
 	flushers := flushers{}
@@ -568,8 +869,26 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		if m.testing {
 			flushers = append(flushers, store)
 		}
+	case SQLiteStore, PostgresStore:
+		driver, dsn := sqlkv.SQLite, m.storeDSN
+		if m.storeType == PostgresStore {
+			driver = sqlkv.Postgres
+		} else if dsn == "" {
+			dsn = filepath.Join(filepath.Dir(m.boltPath), "influxd.sqlite")
+		}
+		store := sqlkv.NewKVStore(m.log.With(zap.String("service", "kvstore-"+m.storeType)), driver, dsn)
+		if err := store.Open(ctx); err != nil {
+			m.log.Error("Failed opening sql store", zap.Error(err))
+			return err
+		}
+		m.sqlStore = store
+		m.kvStore = store
+		m.kvService = kv.NewService(m.log.With(zap.String("store", "kv")), store, serviceConfig)
+		if m.testing {
+			flushers = append(flushers, store)
+		}
 	default:
-		err := fmt.Errorf("unknown store type %s; expected bolt or memory", m.storeType)
+		err := fmt.Errorf("unknown store type %s; expected bolt, memory, sqlite or postgres", m.storeType)
 		m.log.Error("Failed opening bolt", zap.Error(err))
 		return err
 	}
@@ -579,6 +898,11 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	if _, err := m.kvService.RotateSecrets(ctx); err != nil {
+		m.log.Error("Failed to encrypt secrets", zap.Error(err))
+		return err
+	}
+
 	m.reg = prom.NewRegistry(m.log.With(zap.String("service", "prom_registry")))
 	m.reg.MustRegister(
 		prometheus.NewGoCollector(),
@@ -642,19 +966,39 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	roleSvc, err := role.NewService(ctx, m.kvStore, orgSvc, userSvc, authSvc)
+	if err != nil {
+		return err
+	}
+	// tasks run with the roles of their owners
+	m.kvService.WithRolePermissions(func(tx kv.Tx, userID platform.ID) ([]platform.Permission, error) {
+		return roleSvc.RolePermissions(tx, platform.UsersResourceType, userID)
+	})
+
 	chronografSvc, err := server.NewServiceV2(ctx, m.boltClient.DB())
 	if err != nil {
 		m.log.Error("Failed creating chronograf service", zap.Error(err))
 		return err
 	}
 
+	m.StorageConfig.Replication.MaxLagSize = toml.Size(m.walReplicationMaxLagSize)
+	engineOpts := []storage.Option{storage.WithRetentionEnforcer(bucketSvc)}
+	if rc := m.StorageConfig.Replication; rc.Leader() {
+		client, err := http.NewHTTPClient(rc.FollowerURL, rc.FollowerToken, false)
+		if err != nil {
+			m.log.Error("Failed to create WAL replication follower client", zap.Error(err))
+			return err
+		}
+		engineOpts = append(engineOpts, storage.WithWALFollower(&http.ReplicationService{Client: client}))
+	}
+
 	if m.testing {
 		// the testing engine will write/read into a temporary directory
-		engine := NewTemporaryEngine(m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+		engine := NewTemporaryEngine(m.StorageConfig, engineOpts...)
 		flushers = append(flushers, engine)
 		m.engine = engine
 	} else {
-		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, storage.WithRetentionEnforcer(bucketSvc))
+		m.engine = storage.NewEngine(m.enginePath, m.StorageConfig, engineOpts...)
 	}
 	m.engine.WithLogger(m.log)
 	if err := m.engine.Open(ctx); err != nil {
@@ -664,15 +1008,19 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	// The Engine's metrics must be registered after it opens.
 	m.reg.MustRegister(m.engine.PrometheusCollectors()...)
 
+	// tasks with a trigger run on the writes to their bucket, including the writes of other tasks
+	m.writeTrigger = taskbackend.NewWriteTrigger(ctx, m.log.With(zap.String("service", "task-trigger")))
+	writeTrigger := m.writeTrigger
+
 	var (
 		deleteService platform.DeleteService = m.engine
-		pointsWriter  storage.PointsWriter   = m.engine
+		pointsWriter  storage.PointsWriter   = taskbackend.NewTriggerPointsWriter(m.engine, writeTrigger)
 		backupService platform.BackupService = m.engine
 	)
 
 	deps, err := influxdb.NewDependencies(
 		storageflux.NewReader(readservice.NewStore(m.engine)),
-		m.engine,
+		pointsWriter,
 		authorizer.NewBucketService(bucketSvc, userResourceSvc),
 		authorizer.NewOrgService(orgSvc),
 		authorizer.NewSecretService(secretSvc),
@@ -700,29 +1048,60 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 	m.reg.MustRegister(m.queryController.PrometheusCollectors()...)
 
 	var storageQueryService = readservice.NewProxyQueryService(m.queryController)
-	var taskSvc platform.TaskService
+	var (
+		taskSvc         platform.TaskService
+		taskTemplateSvc platform.TaskTemplateService
+	)
 	{
 		// create the task stack
 		combinedTaskService := taskbackend.NewAnalyticalStorage(m.log.With(zap.String("service", "task-analytical-store")), m.kvService, m.kvService, m.kvService, pointsWriter, query.QueryServiceBridge{AsyncQueryService: m.queryController})
 
+		orgLimits := executor.OrgLimits{
+			Concurrency:      m.taskOrgConcurrency,
+			MemoryBytesQuota: int64(m.taskOrgMemoryBytes),
+			Overrides:        make(map[platform.ID]executor.OrgLimits, len(m.taskOrgLimits)),
+		}
+		for _, s := range m.taskOrgLimits {
+			orgID, limits, err := executor.ParseOrgLimits(s)
+			if err != nil {
+				m.log.Error("Invalid task org limit", zap.Error(err))
+				return err
+			}
+			orgLimits.Overrides[orgID] = limits
+		}
+
 		executor, executorMetrics := executor.NewExecutor(
 			m.log.With(zap.String("service", "task-executor")),
 			query.QueryServiceBridge{AsyncQueryService: m.queryController},
 			authSvc,
 			combinedTaskService,
 			combinedTaskService,
+			executor.WithRetryBackoff(m.taskRetryBackoff, m.taskRetryMaxBackoff),
+			executor.WithNotifier(executor.NewEndpointNotifier(notificationEndpointStore, secretSvc)),
+			executor.WithOrgLimits(orgLimits),
 		)
 		m.executor = executor
 		m.reg.MustRegister(executorMetrics.PrometheusCollectors()...)
+		writeTrigger.SetRunFunc(func(ctx context.Context, taskID platform.ID, trigger platform.RunTrigger) error {
+			_, err := executor.TriggerRun(ctx, taskID, trigger)
+			return err
+		})
 		schLogger := m.log.With(zap.String("service", "task-scheduler"))
 
+		var leaser scheduler.Leaser
+		if m.taskLeaseTTL > 0 {
+			owner := m.taskLeaseOwner
+			if owner == "" {
+				hostname, _ := os.Hostname()
+				owner = hostname + "-" + snowflake.NewIDGenerator().ID().String()
+			}
+			leaser = taskbackend.NewTaskLeaser(m.kvService, owner, m.taskLeaseTTL)
+			schLogger.Info("Sharing tasks with other instances", zap.String("owner", owner), zap.Duration("ttl", m.taskLeaseTTL))
+		}
+
 		var sch stoppingScheduler = &scheduler.NoopScheduler{}
 		if !m.noTasks {
-			var (
-				sm  *scheduler.SchedulerMetrics
-				err error
-			)
-			sch, sm, err = scheduler.NewScheduler(
+			treeSch, sm, err := scheduler.NewScheduler(
 				executor,
 				taskbackend.NewSchedulableTaskService(m.kvService),
 				scheduler.WithOnErrorFn(func(ctx context.Context, taskID scheduler.ID, scheduledAt time.Time, err error) {
@@ -732,11 +1111,17 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 						zap.Time("scheduledAt", scheduledAt),
 						zap.Error(err))
 				}),
+				// renew well before the leases expire, so that a slow renewal does not lose them
+				scheduler.WithLeaser(leaser, m.taskLeaseTTL/3),
 			)
 			if err != nil {
 				m.log.Fatal("could not start task scheduler", zap.Error(err))
 			}
 			m.reg.MustRegister(sm.PrometheusCollectors()...)
+
+			// tasks that depend on others run once the scheduler learns their upstream runs succeeded
+			executor.SetRunFinishedFunc(treeSch.RunFinished)
+			sch = treeSch
 		}
 
 		m.scheduler = sch
@@ -745,9 +1130,11 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		taskCoord := coordinator.NewCoordinator(
 			coordLogger,
 			sch,
-			executor)
+			executor,
+			coordinator.WithTrigger(writeTrigger))
 
 		taskSvc = middleware.New(combinedTaskService, taskCoord)
+		taskTemplateSvc = middleware.NewTaskTemplateService(m.kvService, combinedTaskService, taskCoord)
 		m.taskControlService = combinedTaskService
 		if err := taskbackend.TaskNotifyCoordinatorOfExisting(
 			ctx,
@@ -755,12 +1142,48 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			combinedTaskService,
 			taskCoord,
 			func(ctx context.Context, taskID platform.ID, runID platform.ID) error {
+				if leaser != nil {
+					// only the instance that owns the task resumes its runs
+					lctx, err := leaser.Lease(ctx, scheduler.ID(taskID))
+					if err == scheduler.ErrLeaseHeld {
+						return nil
+					}
+					if err != nil {
+						return err
+					}
+					ctx = lctx
+				}
 				_, err := executor.ResumeCurrentRun(ctx, taskID, runID)
 				return err
 			},
 			coordLogger); err != nil {
 			m.log.Error("Failed to resume existing tasks", zap.Error(err))
 		}
+
+		if leaser != nil && !m.noTasks {
+			// pick up the tasks created, updated and deleted through other instances
+			m.wg.Add(1)
+			go func() {
+				defer m.wg.Done()
+				taskbackend.SyncCoordinatorWithTasks(ctx, coordLogger, combinedTaskService, taskCoord, m.taskLeaseTTL)
+			}()
+		}
+
+		// tasks may set their own run retention, so the pruner runs without one
+		pruner := taskbackend.NewRunPruner(
+			m.log.With(zap.String("service", "task-run-pruner")),
+			m.kvService,
+			m.kvService,
+			m.kvService,
+			deleteService,
+			query.QueryServiceBridge{AsyncQueryService: m.queryController},
+			taskbackend.RunRetention{MaxAge: m.taskRunRetention, MaxCount: m.taskRunRetentionCount},
+		)
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			pruner.Run(ctx, taskbackend.DefaultRunPruneInterval)
+		}()
 	}
 
 	dbrpSvc, err := dbrp.NewService(ctx, authorizer.NewBucketService(bucketSvc, userResourceSvc), m.kvStore)
@@ -880,6 +1303,32 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		sessionSvc = session.NewServiceController(flagger, m.kvService, sessionSvc)
 	}
 
+	if m.tokenSweepInterval > 0 {
+		sweeper := authorization.NewExpirySweeper(m.log.With(zap.String("service", "token-sweeper")), authSvc, authorization.DefaultExpiringWithin)
+		m.reg.MustRegister(sweeper.PrometheusCollectors()...)
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			sweeper.Run(ctx, m.tokenSweepInterval)
+		}()
+	}
+
+	// Wrap the BucketService in a storage backed one that will ensure deleted buckets
+	// are removed from the storage engine, and bucket storage settings are applied.
+	storageBucketSvc := storage.NewBucketService(bucketSvc, m.engine)
+	if err := storageBucketSvc.LoadBucketSettings(ctx); err != nil {
+		m.log.Error("Failed to load bucket storage settings", zap.Error(err))
+		return err
+	}
+
+	if m.auditLogPath != "" {
+		m.auditService, err = audit.NewFileService(m.auditLogPath)
+		if err != nil {
+			m.log.Error("Failed to open audit log", zap.String("path", m.auditLogPath), zap.Error(err))
+			return err
+		}
+	}
+
 	m.apibackend = &http.APIBackend{
 		AssetsPath:           m.assetsPath,
 		HTTPErrorHandler:     kithttp.ErrorHandler(0),
@@ -890,11 +1339,13 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		PointsWriter:         pointsWriter,
 		DeleteService:        deleteService,
 		BackupService:        backupService,
+		CompactionService:    m.engine,
+		ReplicationService:   m.engine,
 		KVBackupService:      m.kvService,
 		AuthorizationService: authSvc,
 		AlgoWProxy:           &http.NoopProxyHandler{},
-		// Wrap the BucketService in a storage backed one that will ensure deleted buckets are removed from the storage engine.
-		BucketService:                   storage.NewBucketService(bucketSvc, m.engine),
+		// Storage backed BucketService; see storageBucketSvc above.
+		BucketService:                   storageBucketSvc,
 		SessionService:                  sessionSvc,
 		UserService:                     userSvc,
 		DBRPService:                     dbrpSvc,
@@ -909,9 +1360,14 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		SourceService:                   sourceSvc,
 		VariableService:                 variableSvc,
 		PasswordsService:                passwdsSvc,
+		RoleService:                     roleSvc,
+		RolePermissionService:           roleSvc,
 		InfluxQLService:                 storageQueryService,
 		FluxService:                     storageQueryService,
 		TaskService:                     taskSvc,
+		BackfillService:                 m.executor,
+		TaskDryRunService:               m.executor,
+		TaskTemplateService:             taskTemplateSvc,
 		TelegrafService:                 telegrafSvc,
 		NotificationRuleStore:           notificationRuleSvc,
 		NotificationEndpointService:     endpoints.NewService(notificationEndpointStore, secretSvc, userResourceSvc, orgSvc),
@@ -948,6 +1404,7 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 			pkger.WithNotificationEndpointSVC(authorizer.NewNotificationEndpointService(b.NotificationEndpointService, authedURMSVC, authedOrgSVC)),
 			pkger.WithNotificationRuleSVC(authorizer.NewNotificationRuleStore(b.NotificationRuleStore, authedURMSVC, authedOrgSVC)),
 			pkger.WithOrganizationService(authorizer.NewOrgService(b.OrganizationService)),
+			pkger.WithRoleSVC(authorizer.NewRoleService(b.RoleService)),
 			pkger.WithSecretSVC(authorizer.NewSecretService(b.SecretService)),
 			pkger.WithTaskSVC(authorizer.NewTaskService(pkgerLogger, b.TaskService)),
 			pkger.WithTelegrafSVC(authorizer.NewTelegrafConfigService(b.TelegrafService, b.UserResourceMappingService)),
@@ -999,21 +1456,71 @@ func (m *Launcher) run(ctx context.Context) (err error) {
 		authHTTPServer = kithttp.NewFeatureHandler(feature.NewAuthPackage(), flagger, oldHandler, newHandler, newHandler.Prefix())
 	}
 
+	roleHTTPServer := role.NewHTTPRoleHandler(m.log.With(zap.String("handler", "role")), authorizer.NewRoleService(roleSvc))
+
+	var auditHTTPServer *audit.AuditHandler
+	{
+		var auditSvc platform.AuditService = audit.NopService{}
+		if m.auditService != nil {
+			auditSvc = m.auditService
+			m.apibackend.AuditService = m.auditService
+		}
+		auditHTTPServer = audit.NewHTTPAuditHandler(m.log.With(zap.String("handler", "audit")), authorizer.NewAuditService(auditSvc))
+	}
+
+	var oidcHTTPServer *session.OIDCHandler
+	if m.oidcConfig.Enabled() {
+		for _, s := range m.oidcGroupMappings {
+			mapping, err := session.ParseGroupMapping(s)
+			if err != nil {
+				m.log.Error("Invalid OpenID Connect group mapping", zap.Error(err))
+				return err
+			}
+			m.oidcConfig.GroupMappings = append(m.oidcConfig.GroupMappings, mapping)
+		}
+		oidcHTTPServer = session.NewOIDCHandler(m.log.With(zap.String("handler", "oidc")), m.oidcConfig, sessionSvc, userSvc, orgSvc, userResourceSvc)
+	}
+
+	var sessionOpts []session.SessionHandlerOption
+	if m.ldapConfig.Enabled() {
+		for _, s := range m.ldapGroupMappings {
+			mapping, err := session.ParseGroupMapping(s)
+			if err != nil {
+				m.log.Error("Invalid LDAP group mapping", zap.Error(err))
+				return err
+			}
+			m.ldapConfig.GroupMappings = append(m.ldapConfig.GroupMappings, mapping)
+		}
+		signInSvc, err := ldap.NewSignInService(m.log.With(zap.String("service", "ldap")), m.ldapConfig, userSvc, passwdsSvc, orgSvc, userResourceSvc)
+		if err != nil {
+			m.log.Error("Failed to configure LDAP sign in", zap.Error(err))
+			return err
+		}
+		m.apibackend.SignInService = signInSvc
+		sessionOpts = append(sessionOpts, session.WithSignInService(signInSvc))
+	}
+
 	var oldSessionHandler nethttp.Handler
 	var sessionHTTPServer *session.SessionHandler
 	{
 		oldSessionHandler = http.NewSessionHandler(m.log.With(zap.String("handler", "old_session")), http.NewSessionBackend(m.log, m.apibackend))
-		sessionHTTPServer = session.NewSessionHandler(m.log.With(zap.String("handler", "session")), sessionSvc, userSvc, passwdsSvc)
+		sessionHTTPServer = session.NewSessionHandler(m.log.With(zap.String("handler", "session")), sessionSvc, userSvc, passwdsSvc, sessionOpts...)
 	}
 
 	{
-		platformHandler := http.NewPlatformHandler(m.apibackend,
+		opts := []http.APIHandlerOptFn{
 			http.WithResourceHandler(pkgHTTPServer),
 			http.WithResourceHandler(onboardHTTPServer),
 			http.WithResourceHandler(authHTTPServer),
+			http.WithResourceHandler(auditHTTPServer),
+			http.WithResourceHandler(roleHTTPServer),
 			http.WithResourceHandler(kithttp.NewFeatureHandler(feature.SessionService(), flagger, oldSessionHandler, sessionHTTPServer.SignInResourceHandler(), sessionHTTPServer.SignInResourceHandler().Prefix())),
 			http.WithResourceHandler(kithttp.NewFeatureHandler(feature.SessionService(), flagger, oldSessionHandler, sessionHTTPServer.SignOutResourceHandler(), sessionHTTPServer.SignOutResourceHandler().Prefix())),
-		)
+		}
+		if oidcHTTPServer != nil {
+			opts = append(opts, http.WithResourceHandler(oidcHTTPServer))
+		}
+		platformHandler := http.NewPlatformHandler(m.apibackend, opts...)
 
 		httpLogger := m.log.With(zap.String("service", "http"))
 		m.httpServer.Handler = http.NewHandlerFromRegistry(
@@ -1092,6 +1599,32 @@ func isAddressPortAvailable(address string, port int) (bool, error) {
 	return false, nil
 }
 
+// readSecretMasterKeys parses the secret master keys given as flags, followed
+// by those in the file at path, if any.
+func readSecretMasterKeys(encoded []string, path string) ([][]byte, error) {
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(b), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				encoded = append(encoded, line)
+			}
+		}
+	}
+
+	keys := make([][]byte, 0, len(encoded))
+	for _, s := range encoded {
+		key, err := kv.ParseSecretMasterKey(s)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
 // OrganizationService returns the internal organization service.
 func (m *Launcher) OrganizationService() platform.OrganizationService {
 	return m.apibackend.OrganizationService
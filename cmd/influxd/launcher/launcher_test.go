@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	nethttp "net/http"
+	"os"
+	"path/filepath"
 	"testing"
 
 	platform "github.com/influxdata/influxdb/v2"
@@ -42,6 +44,25 @@ func TestLauncher_Setup(t *testing.T) {
 	}
 }
 
+func TestLauncher_SQLiteStore(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx, "--store", "sqlite")
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	if _, err := os.Stat(filepath.Join(l.Path, "influxd.sqlite")); err != nil {
+		t.Fatalf("expected sqlite database next to bolt-path: %v", err)
+	}
+
+	l.WritePointsOrFail(t, `m,k=v f=100i 946684800000000000`)
+
+	qs := `from(bucket:"BUCKET") |> range(start:2000-01-01T00:00:00Z,stop:2000-01-02T00:00:00Z)`
+	exp := `,result,table,_start,_stop,_time,_value,_field,_measurement,k` + "\r\n" +
+		`,_result,0,2000-01-01T00:00:00Z,2000-01-02T00:00:00Z,2000-01-01T00:00:00Z,100,f,m,v` + "\r\n\r\n"
+	if got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, qs); got != exp {
+		t.Errorf("unexpected query results: got %q, want %q", got, exp)
+	}
+}
+
 // This is to mimic chronograf using cookies as sessions
 // rather than authorizations
 func TestLauncher_SetupWithUsers(t *testing.T) {
@@ -744,3 +744,60 @@ from(bucket: "%s")
 		t.Fatal(err)
 	}
 }
+
+func TestLauncher_Task_DryRun(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	data := `
+#datatype,string,long,dateTime:RFC3339,double,string,string,string
+#group,false,false,false,false,true,true,true
+#default,_result,,,,,,
+,result,table,_time,_value,_field,_measurement,host
+,,0,2018-05-22T19:53:26Z,1.5,usage,cpu,a
+`
+	task, err := l.TaskService(t).CreateTask(ctx, influxdb.TaskCreate{
+		OrganizationID: l.Org.ID,
+		Flux: fmt.Sprintf(`import "csv"
+option task = {name: "dry run", every: 1h}
+csv.from(csv: "%s")
+    |> range(start: 2018-05-21T00:00:00Z, stop: 2018-05-23T00:00:00Z)
+    |> to(bucket: "%s", org: "%s")
+`, data, l.Bucket.Name, l.Org.Name),
+		Status: string(influxdb.TaskInactive),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dr, err := l.TaskService(t).DryRunTask(ctx, task.ID, influxdb.TaskDryRunRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dr.Writes) != 1 || dr.Writes[0].BucketID != l.Bucket.ID || dr.Writes[0].Points != "cpu,host=a usage=1.5 1527018806000000000\n" {
+		t.Fatalf("unexpected writes %+v", dr.Writes)
+	}
+	if !strings.Contains(dr.Results, "usage") {
+		t.Fatalf("expected the results of the task, got:\n%s", dr.Results)
+	}
+	if dr.Plan == "" {
+		t.Fatal("expected the plan of the task")
+	}
+
+	// nothing was written to storage
+	res := l.MustExecuteQuery(fmt.Sprintf(`from(bucket: "%s") |> range(start: 2018-05-15T00:00:00Z, stop: 2018-06-01T00:00:00Z)`, l.Bucket.Name))
+	defer res.Done()
+	var tables int
+	for _, r := range res.Results {
+		if err := r.Tables().Do(func(flux.Table) error {
+			tables++
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if tables != 0 {
+		t.Fatalf("expected nothing to be written, got %d tables", tables)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	nethttp "net/http"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -247,3 +248,96 @@ func TestStorage_CacheSnapshot_Age(t *testing.T) {
 		t.Fatalf("got %d series in TSM files, expected %d", got, exp)
 	}
 }
+
+func TestLauncher_PredicatePermissions(t *testing.T) {
+	l := launcher.RunTestLauncherOrFail(t, ctx)
+	l.SetupOrFail(t)
+	defer l.ShutdownOrFail(t, ctx)
+
+	// the customers share the bucket, and each one has a token to read and
+	// write the points with its customer tag
+	token := func(customer string) string {
+		pred := &influxdb.ResourcePredicate{
+			Tags: []influxdb.Tag{{Key: "customer", Value: customer}},
+		}
+		var ps []influxdb.Permission
+		for _, a := range []influxdb.Action{influxdb.ReadAction, influxdb.WriteAction} {
+			ps = append(ps, influxdb.Permission{
+				Action: a,
+				Resource: influxdb.Resource{
+					Type:      influxdb.BucketsResourceType,
+					OrgID:     &l.Org.ID,
+					ID:        &l.Bucket.ID,
+					Predicate: pred,
+				},
+			})
+		}
+		a := &influxdb.Authorization{
+			OrgID:       l.Org.ID,
+			UserID:      l.User.ID,
+			Permissions: ps,
+		}
+		if err := l.AuthorizationService(t).CreateAuthorization(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+		return a.Token
+	}
+	tokenA, tokenB := token("a"), token("b")
+
+	write := func(token, data string) int {
+		resp, err := nethttp.DefaultClient.Do(l.NewHTTPRequestOrFail(t, "POST", fmt.Sprintf("/api/v2/write?org=%s&bucket=%s", l.Org.ID, l.Bucket.ID), token, data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := resp.Body.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+
+	if code := write(tokenA, "cpu,customer=a,host=ha f=1i\nsecret_a,customer=a f=2i"); code != nethttp.StatusNoContent {
+		t.Fatalf("unexpected status code of write of customer a: %d", code)
+	}
+	if code := write(tokenB, "cpu,customer=b,host=hb f=3i\nsecret_b,customer=b,region_b=r f=4i"); code != nethttp.StatusNoContent {
+		t.Fatalf("unexpected status code of write of customer b: %d", code)
+	}
+	if code := write(tokenA, "cpu,customer=b,host=ha f=5i"); code != nethttp.StatusForbidden {
+		t.Fatalf("unexpected status code of write of customer a to customer b: %d", code)
+	}
+
+	// the points, and the schema of the points, of customer b must not be
+	// readable by customer a
+	leaks := []string{"customer=b", ",b,", "hb", "secret_b", "region_b", "3", "4", "5"}
+	for _, qs := range []string{
+		`from(bucket:"BUCKET") |> range(start:-1h) |> drop(columns: ["_start", "_stop", "_time"])`,
+		`from(bucket:"BUCKET") |> range(start:-1h) |> filter(fn: (r) => r.customer == "b")`,
+		`import "influxdata/influxdb/v1" v1.measurements(bucket:"BUCKET")`,
+		`import "influxdata/influxdb/v1" v1.tagKeys(bucket:"BUCKET")`,
+		`import "influxdata/influxdb/v1" v1.tagValues(bucket:"BUCKET", tag:"customer")`,
+		`import "influxdata/influxdb/v1" v1.tagValues(bucket:"BUCKET", tag:"host")`,
+		`import "influxdata/influxdb/v1" v1.measurementTagValues(bucket:"BUCKET", measurement:"cpu", tag:"host")`,
+	} {
+		got := l.FluxQueryOrFail(t, l.Org, tokenA, qs)
+		for _, leak := range leaks {
+			if strings.Contains(got, leak) {
+				t.Errorf("query %s of customer a leaks %q of customer b:\n%s", qs, leak, got)
+			}
+		}
+	}
+
+	// the token of the setup reads all the points
+	qs := `import "influxdata/influxdb/v1" v1.tagValues(bucket:"BUCKET", tag:"customer")`
+	exp := `,result,table,_value` + "\r\n" +
+		`,_result,0,a` + "\r\n" +
+		`,_result,0,b` + "\r\n\r\n"
+	if got := l.FluxQueryOrFail(t, l.Org, l.Auth.Token, qs); !cmp.Equal(got, exp) {
+		t.Errorf("unexpected query results -got/+exp\n%s", cmp.Diff(got, exp))
+	}
+	qs = `import "influxdata/influxdb/v1" v1.measurements(bucket:"BUCKET")`
+	exp = `,result,table,_value` + "\r\n" +
+		`,_result,0,cpu` + "\r\n" +
+		`,_result,0,secret_a` + "\r\n\r\n"
+	if got := l.FluxQueryOrFail(t, l.Org, tokenA, qs); !cmp.Equal(got, exp) {
+		t.Errorf("unexpected query results -got/+exp\n%s", cmp.Diff(got, exp))
+	}
+}
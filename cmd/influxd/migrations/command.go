@@ -0,0 +1,292 @@
+// Package migrations provides commands to list, apply and undo the migrations
+// of the metadata stored in boltdb.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/internal/fs"
+	"github.com/influxdata/influxdb/v2/kit/cli"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/logger"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+type flags struct {
+	store      string
+	boltPath   string
+	to         string
+	dryRun     bool
+	backupPath string
+}
+
+// NewCommand creates the migrations command.
+func NewCommand() *cobra.Command {
+	var f flags
+
+	base := &cobra.Command{
+		Use:   "migrations",
+		Short: "List, apply and undo the migrations of the metadata",
+		Long: `
+These commands list the migrations of the metadata stored in boltdb, apply
+outstanding migrations before an upgrade, or undo migrations to roll back a
+failed upgrade.
+
+The metadata is backed up before any migration is applied or undone. Some
+migrations cannot be undone, such as the one that hashes the tokens of
+authorizations: nothing is undone when rolling back past one of them.
+
+NOTES:
+
+* The influxd server should not be running when using these commands
+  as they open its bolt database.
+* Only the bolt store is supported. The metadata of the memory store is
+  not persisted, and the metadata of the sqlite and postgres stores is
+  migrated as influxd starts.
+`,
+	}
+
+	dir, err := fs.InfluxDir()
+	if err != nil {
+		panic(fmt.Errorf("failed to determine influx directory: %s", err))
+	}
+
+	cli.BindOptions(base, []cli.Opt{
+		{
+			DestP:      &f.store,
+			Flag:       "store",
+			Default:    "bolt",
+			Desc:       "backing store of the metadata; only bolt is supported",
+			Persistent: true,
+		},
+		{
+			DestP:      &f.boltPath,
+			Flag:       "bolt-path",
+			Default:    filepath.Join(dir, bolt.DefaultFilename),
+			Desc:       "path to boltdb database",
+			Persistent: true,
+		},
+	})
+
+	// errors of running the migrations are not errors of usage, so the
+	// subcommands silence the usage.
+	list := &cobra.Command{
+		Use:          "list",
+		Short:        "List the migrations and their states",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return f.list(context.Background(), cmd.OutOrStdout())
+		},
+	}
+
+	up := &cobra.Command{
+		Use:          "up",
+		Short:        "Apply the outstanding migrations",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return f.up(context.Background(), cmd.OutOrStdout())
+		},
+	}
+	cli.BindOptions(up, []cli.Opt{
+		{
+			DestP: &f.to,
+			Flag:  "to",
+			Desc:  "name of the last migration to apply; all outstanding migrations are applied if not set",
+		},
+		{
+			DestP: &f.dryRun,
+			Flag:  "dry-run",
+			Desc:  "list the migrations that would be applied without applying them",
+		},
+		{
+			DestP: &f.backupPath,
+			Flag:  "backup-path",
+			Desc:  "path of the backup of the bolt database taken before applying migrations; defaults to the bolt-path suffixed with the time",
+		},
+	})
+
+	down := &cobra.Command{
+		Use:          "down",
+		Short:        "Undo the migrations applied after a migration",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return f.down(context.Background(), cmd.OutOrStdout())
+		},
+	}
+	cli.BindOptions(down, []cli.Opt{
+		{
+			DestP: &f.to,
+			Flag:  "to",
+			Desc:  "name of the migration to roll back to; it remains applied",
+		},
+		{
+			DestP: &f.dryRun,
+			Flag:  "dry-run",
+			Desc:  "list the migrations that would be undone without undoing them",
+		},
+		{
+			DestP: &f.backupPath,
+			Flag:  "backup-path",
+			Desc:  "path of the backup of the bolt database taken before undoing migrations; defaults to the bolt-path suffixed with the time",
+		},
+	})
+
+	base.AddCommand(list, up, down)
+
+	return base
+}
+
+func (f *flags) list(ctx context.Context, w io.Writer) error {
+	store, migrator, closeStore, err := f.open(ctx, zap.NewNop())
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	migrations, err := migrator.List(ctx, store)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tName\tState\tStarted At\tFinished At")
+	for _, m := range migrations {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", m.ID, m.Name, m.State, formatTime(m.StartedAt), formatTime(m.FinishedAt))
+	}
+	return tw.Flush()
+}
+
+func (f *flags) up(ctx context.Context, w io.Writer) error {
+	store, migrator, closeStore, err := f.open(ctx, logger.New(w))
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	migrations, err := migrator.PlanUp(ctx, store, f.to)
+	if err != nil {
+		return err
+	}
+
+	return f.migrate(ctx, w, store, migrations, "apply", "applied", func() error {
+		return migrator.UpTo(ctx, store, f.to)
+	})
+}
+
+func (f *flags) down(ctx context.Context, w io.Writer) error {
+	if f.to == "" {
+		return errors.New("the name of the migration to roll back to must be set with --to")
+	}
+
+	store, migrator, closeStore, err := f.open(ctx, logger.New(w))
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	migrations, err := migrator.PlanDown(ctx, store, f.to)
+	if err != nil {
+		return err
+	}
+
+	return f.migrate(ctx, w, store, migrations, "undo", "undone", func() error {
+		return migrator.DownTo(ctx, store, f.to)
+	})
+}
+
+// migrate lists the migrations, and applies or undoes them with fn after
+// backing up the store unless it is a dry run.
+func (f *flags) migrate(ctx context.Context, w io.Writer, store *bolt.KVStore, migrations []kv.Migration, verb, pastVerb string, fn func() error) error {
+	if len(migrations) == 0 {
+		fmt.Fprintf(w, "No migrations to %s\n", verb)
+		return nil
+	}
+
+	if f.dryRun {
+		fmt.Fprintf(w, "Migrations that would be %s:\n", pastVerb)
+	} else {
+		fmt.Fprintf(w, "Migrations to %s:\n", verb)
+	}
+	for _, m := range migrations {
+		fmt.Fprintf(w, "  %s %s\n", m.ID, m.Name)
+	}
+
+	if f.dryRun {
+		return nil
+	}
+
+	backupPath := f.backupPath
+	if backupPath == "" {
+		backupPath = fmt.Sprintf("%s.%s.bak", f.boltPath, time.Now().UTC().Format("20060102T150405Z"))
+	}
+	if err := backup(ctx, store, backupPath); err != nil {
+		return fmt.Errorf("failed to back up bolt database: %v", err)
+	}
+	fmt.Fprintf(w, "Backed up bolt database to %s\n", backupPath)
+
+	if err := fn(); err != nil {
+		return fmt.Errorf("%v; the bolt database can be restored from %s", err, backupPath)
+	}
+
+	fmt.Fprintf(w, "Migrations %s\n", pastVerb)
+	return nil
+}
+
+// open opens the bolt store at the bolt path, with the migrator of its
+// metadata.
+func (f *flags) open(ctx context.Context, log *zap.Logger) (*bolt.KVStore, *kv.Migrator, func(), error) {
+	if f.store != "bolt" {
+		return nil, nil, nil, fmt.Errorf("migrations of the %s store are not supported; only bolt is", f.store)
+	}
+	if _, err := os.Stat(f.boltPath); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open bolt database: %v", err)
+	}
+
+	store := bolt.NewKVStore(log.With(zap.String("service", "kvstore-bolt")), f.boltPath)
+	if err := store.Open(ctx); err != nil {
+		return nil, nil, nil, fmt.Errorf("%v; is influxd running?", err)
+	}
+
+	migrator := kv.NewService(log.With(zap.String("store", "kv")), store).Migrator
+	if err := migrator.Initialize(ctx, store); err != nil {
+		store.Close()
+		return nil, nil, nil, err
+	}
+
+	return store, migrator, func() { store.Close() }, nil
+}
+
+// backup writes a copy of the bolt database of store to path.
+func backup(ctx context.Context, store *bolt.KVStore, path string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	if err := store.Backup(ctx, file); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+
+	return file.Close()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,178 @@
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2/bolt"
+	"github.com/influxdata/influxdb/v2/kv"
+	"go.uber.org/zap/zaptest"
+)
+
+// newBoltPath returns the path of a bolt database in a temporary directory,
+// with its migrations applied up to the migration named to.
+func newBoltPath(t *testing.T, to string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "influxd-migrations-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	ctx := context.Background()
+	path := filepath.Join(dir, bolt.DefaultFilename)
+	store := bolt.NewKVStore(zaptest.NewLogger(t), path)
+	if err := store.Open(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	migrator := kv.NewService(zaptest.NewLogger(t), store).Migrator
+	if err := migrator.Initialize(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.UpTo(ctx, store, to); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func run(t *testing.T, boltPath string, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	cmd := NewCommand()
+	cmd.SetArgs(append(args, "--bolt-path", boltPath))
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+// states returns the names and states of the migrations listed by the list
+// command.
+func states(t *testing.T, boltPath string) []string {
+	t.Helper()
+
+	out, err := run(t, boltPath, "list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var states []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n")[1:] {
+		fields := strings.Split(line, "  ")
+		var cols []string
+		for _, f := range fields {
+			if f = strings.TrimSpace(f); f != "" {
+				cols = append(cols, f)
+			}
+		}
+		states = append(states, cols[1]+" ("+cols[2]+")")
+	}
+	return states
+}
+
+func backups(t *testing.T, boltPath string) []string {
+	t.Helper()
+
+	matches, err := filepath.Glob(boltPath + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestCommand_Up(t *testing.T) {
+	path := newBoltPath(t, "initial migration")
+
+	out, err := run(t, path, "up", "--dry-run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"add index", "hash authorization tokens"} {
+		if !strings.Contains(out, name) {
+			t.Errorf("expected the dry run to list %q, got:\n%s", name, out)
+		}
+	}
+	if got := backups(t, path); len(got) != 0 {
+		t.Errorf("expected no backup of a dry run, got %v", got)
+	}
+	if got := states(t, path); got[len(got)-1] != "hash authorization tokens (down)" {
+		t.Errorf("expected the dry run to apply nothing, got %v", got)
+	}
+
+	backupPath := path + ".backup"
+	if _, err := run(t, path, "up", "--backup-path", backupPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(backupPath); err != nil {
+		t.Errorf("expected a backup: %v", err)
+	}
+	for _, state := range states(t, path) {
+		if !strings.HasSuffix(state, "(up)") {
+			t.Errorf("expected all migrations to be applied, got %s", state)
+		}
+	}
+
+	out, err = run(t, path, "up")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "No migrations to apply") {
+		t.Errorf("expected no migrations to apply, got:\n%s", out)
+	}
+}
+
+func TestCommand_Down(t *testing.T) {
+	path := newBoltPath(t, "")
+	applied := states(t, path)
+
+	t.Run("requires a migration to roll back to", func(t *testing.T) {
+		if _, err := run(t, path, "down"); err == nil {
+			t.Fatal("expected an error without --to")
+		}
+	})
+
+	t.Run("past an irreversible migration", func(t *testing.T) {
+		for _, args := range [][]string{
+			{"down", "--to", "initial migration", "--dry-run"},
+			{"down", "--to", "initial migration"},
+		} {
+			if _, err := run(t, path, args...); !errors.Is(err, kv.ErrMigrationIrreversible) {
+				t.Errorf("%v: expected migration irreversible error, got %v", args, err)
+			}
+		}
+
+		if got := backups(t, path); len(got) != 0 {
+			t.Errorf("expected no backup, got %v", got)
+		}
+		if got := states(t, path); strings.Join(got, ",") != strings.Join(applied, ",") {
+			t.Errorf("expected no migration to be undone, got %v, expected %v", got, applied)
+		}
+	})
+
+	t.Run("to the last migration", func(t *testing.T) {
+		out, err := run(t, path, "down", "--to", "hash authorization tokens")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out, "No migrations to undo") {
+			t.Errorf("expected no migrations to undo, got:\n%s", out)
+		}
+	})
+}
+
+func TestCommand_Store(t *testing.T) {
+	path := newBoltPath(t, "")
+
+	if _, err := run(t, path, "list", "--store", "sqlite"); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Fatalf("expected the sqlite store to be rejected, got %v", err)
+	}
+}
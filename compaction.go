@@ -0,0 +1,72 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// CompactionType is the type of compaction that may be scheduled on demand.
+type CompactionType string
+
+const (
+	// CompactionTypeFull compacts all data stored into as few files as possible.
+	CompactionTypeFull CompactionType = "full"
+
+	// CompactionTypeOptimize rewrites fully compacted generations into fewer,
+	// larger files without recompressing the blocks.
+	CompactionTypeOptimize CompactionType = "optimize"
+)
+
+// Valid returns an error if the compaction type is unknown.
+func (t CompactionType) Valid() error {
+	switch t {
+	case CompactionTypeFull, CompactionTypeOptimize:
+		return nil
+	default:
+		return &Error{
+			Code: EInvalid,
+			Msg:  `compaction type must be one of "full" or "optimize"`,
+		}
+	}
+}
+
+// CompactionService represents the compaction functions of the storage engine.
+type CompactionService interface {
+	// CompactionStatus returns the state of active and queued compactions.
+	CompactionStatus(ctx context.Context) (*CompactionStatus, error)
+	// PauseCompactions aborts running compactions and stops new ones from
+	// starting until ResumeCompactions is called. Cache snapshots continue.
+	PauseCompactions(ctx context.Context) error
+	// ResumeCompactions restarts compactions paused by PauseCompactions.
+	ResumeCompactions(ctx context.Context) error
+	// ScheduleCompaction requests a compaction of the given type. Compactions
+	// scheduled while compactions are paused run once they are resumed.
+	ScheduleCompaction(ctx context.Context, typ CompactionType) error
+}
+
+// CompactionStatus is the state of the compactions of the storage engine.
+type CompactionStatus struct {
+	Paused bool                    `json:"paused"`
+	Levels []CompactionLevelStatus `json:"levels"`
+}
+
+// CompactionLevelStatus is the state of the compactions of a single level.
+// Level is one of "1", "2", "3", "optimize" or "full".
+type CompactionLevelStatus struct {
+	Level       string       `json:"level"`
+	Active      uint64       `json:"active"`
+	Queued      uint64       `json:"queued"`
+	Completed   uint64       `json:"completed"`
+	Errors      uint64       `json:"errors"`
+	Compactions []Compaction `json:"compactions,omitempty"`
+}
+
+// Compaction is a single active or queued compaction.
+type Compaction struct {
+	Active bool     `json:"active"`
+	Files  []string `json:"files"`
+	// StartedAt and Progress are only set for active compactions. Progress is
+	// the estimated fraction of the input files written, between 0 and 1.
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	Progress  float64    `json:"progress,omitempty"`
+}
@@ -11,6 +11,8 @@ type contextKey string
 
 const (
 	authorizerCtxKey contextKey = "influx/authorizer/v1"
+	taskLeaseCtxKey  contextKey = "influx/task-lease/v1"
+	taskRunCtxKey    contextKey = "influx/task-run/v1"
 )
 
 // SetAuthorizer sets an authorizer on context.
@@ -66,3 +68,27 @@ func GetUserID(ctx context.Context) (influxdb.ID, error) {
 	}
 	return a.GetUserID(), nil
 }
+
+// SetTaskLease sets the lease of a scheduler on a task on context. Runs of the
+// task recorded with the context are rejected once the lease is lost.
+func SetTaskLease(ctx context.Context, l *influxdb.TaskLease) context.Context {
+	return context.WithValue(ctx, taskLeaseCtxKey, l)
+}
+
+// GetTaskLease retrieves the lease on a task from context.
+func GetTaskLease(ctx context.Context) (*influxdb.TaskLease, bool) {
+	l, ok := ctx.Value(taskLeaseCtxKey).(*influxdb.TaskLease)
+	return l, ok && l != nil
+}
+
+// SetTaskRun sets the task that a run executes on context. Points written
+// with the context do not trigger the task.
+func SetTaskRun(ctx context.Context, taskID influxdb.ID) context.Context {
+	return context.WithValue(ctx, taskRunCtxKey, taskID)
+}
+
+// GetTaskRun retrieves the task that a run executes from context.
+func GetTaskRun(ctx context.Context) (influxdb.ID, bool) {
+	id, ok := ctx.Value(taskRunCtxKey).(influxdb.ID)
+	return id, ok && id.Valid()
+}
@@ -57,6 +57,8 @@ type APIBackend struct {
 	PointsWriter                    storage.PointsWriter
 	DeleteService                   influxdb.DeleteService
 	BackupService                   influxdb.BackupService
+	CompactionService               influxdb.CompactionService
+	ReplicationService              influxdb.ReplicationService
 	KVBackupService                 influxdb.KVBackupService
 	AuthorizationService            influxdb.AuthorizationService
 	DBRPService                     influxdb.DBRPMappingServiceV2
@@ -74,9 +76,15 @@ type APIBackend struct {
 	SourceService                   influxdb.SourceService
 	VariableService                 influxdb.VariableService
 	PasswordsService                influxdb.PasswordsService
+	SignInService                   influxdb.SignInService
+	RoleService                     influxdb.RoleService
+	RolePermissionService           influxdb.RolePermissionService
 	InfluxQLService                 query.ProxyQueryService
 	FluxService                     query.ProxyQueryService
 	TaskService                     influxdb.TaskService
+	BackfillService                 influxdb.BackfillService
+	TaskDryRunService               influxdb.TaskDryRunService
+	TaskTemplateService             influxdb.TaskTemplateService
 	CheckService                    influxdb.CheckService
 	TelegrafService                 influxdb.TelegrafConfigStore
 	ScraperTargetStoreService       influxdb.ScraperTargetStoreService
@@ -89,6 +97,10 @@ type APIBackend struct {
 	NotificationEndpointService     influxdb.NotificationEndpointService
 	Flagger                         feature.Flagger
 	FlagsHandler                    http.Handler
+
+	// AuditService records the API requests that mutate resources, or that
+	// are denied. Requests are not audited when it is nil.
+	AuditService influxdb.AuditService
 }
 
 // PrometheusCollectors exposes the prometheus collectors associated with an APIBackend.
@@ -187,9 +199,21 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	taskLogger := b.Logger.With(zap.String("handler", "bucket"))
 	taskBackend := NewTaskBackend(taskLogger, b)
 	taskBackend.TaskService = authorizer.NewTaskService(taskLogger, b.TaskService)
+	if b.BackfillService != nil {
+		taskBackend.BackfillService = authorizer.NewBackfillService(b.TaskService, b.BackfillService)
+	}
+	if b.TaskDryRunService != nil {
+		taskBackend.TaskDryRunService = authorizer.NewTaskDryRunService(b.TaskService, b.TaskDryRunService)
+	}
 	taskHandler := NewTaskHandler(b.Logger, taskBackend)
 	h.Mount(prefixTasks, taskHandler)
 
+	if b.TaskTemplateService != nil {
+		taskTemplateBackend := NewTaskTemplateBackend(b.Logger.With(zap.String("handler", "taskTemplate")), b)
+		taskTemplateBackend.TaskTemplateService = authorizer.NewTaskTemplateService(b.TaskTemplateService)
+		h.Mount(prefixTaskTemplates, NewTaskTemplateHandler(b.Logger, taskTemplateBackend))
+	}
+
 	telegrafBackend := NewTelegrafBackend(b.Logger.With(zap.String("handler", "telegraf")), b)
 	telegrafBackend.TelegrafService = authorizer.NewTelegrafConfigService(b.TelegrafService, b.UserResourceMappingService)
 	h.Mount(prefixTelegrafPlugins, NewTelegrafHandler(b.Logger, telegrafBackend))
@@ -211,6 +235,14 @@ func NewAPIHandler(b *APIBackend, opts ...APIHandlerOptFn) *APIHandler {
 	backupBackend.BackupService = authorizer.NewBackupService(backupBackend.BackupService)
 	h.Mount(prefixBackup, NewBackupHandler(backupBackend))
 
+	compactionBackend := NewCompactionBackend(b.Logger.With(zap.String("handler", "compaction")), b)
+	compactionBackend.CompactionService = authorizer.NewCompactionService(b.CompactionService)
+	h.Mount(prefixCompactions, NewCompactionHandler(b.Logger, compactionBackend))
+
+	replicationBackend := NewReplicationBackend(b.Logger.With(zap.String("handler", "replication")), b)
+	replicationBackend.ReplicationService = authorizer.NewReplicationService(b.ReplicationService)
+	h.Mount(prefixReplication, NewReplicationHandler(b.Logger, replicationBackend))
+
 	h.Mount(dbrp.PrefixDBRP, dbrp.NewHTTPHandler(b.Logger, b.DBRPService))
 
 	writeBackend := NewWriteBackend(b.Logger.With(zap.String("handler", "write")), b)
@@ -233,6 +265,7 @@ var apiLinks = map[string]interface{}{
 	"authorizations": "/api/v2/authorizations",
 	"backup":         "/api/v2/backup",
 	"buckets":        "/api/v2/buckets",
+	"compactions":    "/api/v2/compactions",
 	"dashboards":     "/api/v2/dashboards",
 	"external": map[string]string{
 		"statusFeed": "https://www.influxdata.com/feed/json",
@@ -250,12 +283,13 @@ var apiLinks = map[string]interface{}{
 		"analyze":     "/api/v2/query/analyze",
 		"suggestions": "/api/v2/query/suggestions",
 	},
-	"setup":    "/api/v2/setup",
-	"signin":   "/api/v2/signin",
-	"signout":  "/api/v2/signout",
-	"sources":  "/api/v2/sources",
-	"scrapers": "/api/v2/scrapers",
-	"swagger":  "/api/v2/swagger.json",
+	"replication": "/api/v2/replication",
+	"setup":       "/api/v2/setup",
+	"signin":      "/api/v2/signin",
+	"signout":     "/api/v2/signout",
+	"sources":     "/api/v2/sources",
+	"scrapers":    "/api/v2/scrapers",
+	"swagger":     "/api/v2/swagger.json",
 	"system": map[string]string{
 		"metrics": "/metrics",
 		"debug":   "/debug/pprof",
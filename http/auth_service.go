@@ -79,6 +79,7 @@ func NewAuthorizationHandler(log *zap.Logger, b *AuthorizationBackend) *Authoriz
 type authResponse struct {
 	ID          platform.ID          `json:"id"`
 	Token       string               `json:"token"`
+	TokenPrefix string               `json:"tokenPrefix,omitempty"`
 	Status      platform.Status      `json:"status"`
 	Description string               `json:"description"`
 	OrgID       platform.ID          `json:"orgID"`
@@ -89,12 +90,15 @@ type authResponse struct {
 	Links       map[string]string    `json:"links"`
 	CreatedAt   time.Time            `json:"createdAt"`
 	UpdatedAt   time.Time            `json:"updatedAt"`
+	NotBefore   *time.Time           `json:"notBefore,omitempty"`
+	ExpiresAt   *time.Time           `json:"expiresAt,omitempty"`
 }
 
 func newAuthResponse(a *platform.Authorization, org *platform.Organization, user *platform.User, ps []permissionResponse) *authResponse {
 	res := &authResponse{
 		ID:          a.ID,
 		Token:       a.Token,
+		TokenPrefix: a.TokenPrefix,
 		Status:      a.Status,
 		Description: a.Description,
 		OrgID:       a.OrgID,
@@ -108,6 +112,8 @@ func newAuthResponse(a *platform.Authorization, org *platform.Organization, user
 		},
 		CreatedAt: a.CreatedAt,
 		UpdatedAt: a.UpdatedAt,
+		NotBefore: a.NotBefore,
+		ExpiresAt: a.ExpiresAt,
 	}
 	return res
 }
@@ -116,6 +122,7 @@ func (a *authResponse) toPlatform() *platform.Authorization {
 	res := &platform.Authorization{
 		ID:          a.ID,
 		Token:       a.Token,
+		TokenPrefix: a.TokenPrefix,
 		Status:      a.Status,
 		Description: a.Description,
 		OrgID:       a.OrgID,
@@ -124,6 +131,8 @@ func (a *authResponse) toPlatform() *platform.Authorization {
 			CreatedAt: a.CreatedAt,
 			UpdatedAt: a.UpdatedAt,
 		},
+		NotBefore: a.NotBefore,
+		ExpiresAt: a.ExpiresAt,
 	}
 	for _, p := range a.Permissions {
 		res.Permissions = append(res.Permissions, platform.Permission{Action: p.Action, Resource: p.Resource.Resource})
@@ -246,6 +255,8 @@ type postAuthorizationRequest struct {
 	UserID      *platform.ID          `json:"userID,omitempty"`
 	Description string                `json:"description"`
 	Permissions []platform.Permission `json:"permissions"`
+	NotBefore   *time.Time            `json:"notBefore,omitempty"`
+	ExpiresAt   *time.Time            `json:"expiresAt,omitempty"`
 }
 
 func (p *postAuthorizationRequest) toPlatform(userID platform.ID) *platform.Authorization {
@@ -255,6 +266,8 @@ func (p *postAuthorizationRequest) toPlatform(userID platform.ID) *platform.Auth
 		Description: p.Description,
 		Permissions: p.Permissions,
 		UserID:      userID,
+		NotBefore:   p.NotBefore,
+		ExpiresAt:   p.ExpiresAt,
 	}
 }
 
@@ -264,6 +277,8 @@ func newPostAuthorizationRequest(a *platform.Authorization) (*postAuthorizationR
 		Description: a.Description,
 		Permissions: a.Permissions,
 		Status:      a.Status,
+		NotBefore:   a.NotBefore,
+		ExpiresAt:   a.ExpiresAt,
 	}
 
 	if a.UserID.Valid() {
@@ -426,6 +441,18 @@ func decodeGetAuthorizationsRequest(ctx context.Context, r *http.Request) (*getA
 		req.filter.ID = id
 	}
 
+	if expiresBefore := qp.Get("expiresBefore"); expiresBefore != "" {
+		t, err := time.Parse(time.RFC3339, expiresBefore)
+		if err != nil {
+			return nil, &platform.Error{
+				Code: platform.EInvalid,
+				Msg:  "expiresBefore must be a RFC3339 time",
+				Err:  err,
+			}
+		}
+		req.filter.ExpiresBefore = &t
+	}
+
 	return req, nil
 }
 
@@ -674,6 +701,9 @@ func (s *AuthorizationService) FindAuthorizations(ctx context.Context, filter pl
 	if filter.Org != nil {
 		params = append(params, [2]string{"org", *filter.Org})
 	}
+	if filter.ExpiresBefore != nil {
+		params = append(params, [2]string{"expiresBefore", filter.ExpiresBefore.Format(time.RFC3339)})
+	}
 
 	var as authsResponse
 	err := s.Client.
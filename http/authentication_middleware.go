@@ -9,6 +9,7 @@ import (
 
 	"github.com/influxdata/httprouter"
 	platform "github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/authorizer"
 	platcontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/jsonweb"
 	"github.com/opentracing/opentracing-go"
@@ -26,6 +27,10 @@ type AuthenticationHandler struct {
 	TokenParser          *jsonweb.TokenParser
 	SessionRenewDisabled bool
 
+	// RolePermissionService looks up the permissions of the roles of tokens
+	// and of the users of sessions. Roles are not evaluated when it is nil.
+	RolePermissionService platform.RolePermissionService
+
 	// This is only really used for it's lookup method the specific http
 	// handler used to register routes does not matter.
 	noAuthRouter *httprouter.Router
@@ -106,6 +111,14 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// tokens may only be used in the time they are valid
+	if a, ok := auth.(*platform.Authorization); ok {
+		if err := a.Expired(); err != nil {
+			h.unauthorized(ctx, w, err)
+			return
+		}
+	}
+
 	// jwt based auth is permission based rather than identity based
 	// and therefor has no associated user. if the user ID is invalid
 	// disregard the user active check
@@ -116,6 +129,15 @@ func (h *AuthenticationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	// the permissions of roles are looked up at every request, so that the
+	// changes to roles apply to their holders
+	if h.RolePermissionService != nil {
+		if err := authorizer.SetRolePermissions(ctx, h.RolePermissionService, auth); err != nil {
+			h.unauthorized(ctx, w, err)
+			return
+		}
+	}
+
 	ctx = platcontext.SetAuthorizer(ctx, auth)
 
 	if span := opentracing.SpanFromContext(ctx); span != nil {
@@ -114,6 +114,60 @@ func TestAuthenticationHandler(t *testing.T) {
 				code: http.StatusUnauthorized,
 			},
 		},
+		{
+			name: "token expired",
+			fields: fields{
+				AuthorizationService: &mock.AuthorizationService{
+					FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+						expiresAt := time.Now().Add(-time.Minute)
+						return &platform.Authorization{ExpiresAt: &expiresAt}, nil
+					},
+				},
+				SessionService: mock.NewSessionService(),
+			},
+			args: args{
+				token: "abc123",
+			},
+			wants: wants{
+				code: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "token not yet valid",
+			fields: fields{
+				AuthorizationService: &mock.AuthorizationService{
+					FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+						notBefore := time.Now().Add(time.Hour)
+						return &platform.Authorization{NotBefore: &notBefore}, nil
+					},
+				},
+				SessionService: mock.NewSessionService(),
+			},
+			args: args{
+				token: "abc123",
+			},
+			wants: wants{
+				code: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "token not expired",
+			fields: fields{
+				AuthorizationService: &mock.AuthorizationService{
+					FindAuthorizationByTokenFn: func(ctx context.Context, token string) (*platform.Authorization, error) {
+						expiresAt := time.Now().Add(time.Hour)
+						return &platform.Authorization{ExpiresAt: &expiresAt}, nil
+					},
+				},
+				SessionService: mock.NewSessionService(),
+			},
+			args: args{
+				token: "abc123",
+			},
+			wants: wants{
+				code: http.StatusOK,
+			},
+		},
 		{
 			name: "associated user is inactive",
 			fields: fields{
@@ -132,13 +132,14 @@ func NewBucketHandler(log *zap.Logger, b *BucketBackend) *BucketHandler {
 
 // bucket is used for serialization/deserialization with duration string syntax.
 type bucket struct {
-	ID                  influxdb.ID     `json:"id,omitempty"`
-	OrgID               influxdb.ID     `json:"orgID,omitempty"`
-	Type                string          `json:"type"`
-	Description         string          `json:"description,omitempty"`
-	Name                string          `json:"name"`
-	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
-	RetentionRules      []retentionRule `json:"retentionRules"`
+	ID                  influxdb.ID      `json:"id,omitempty"`
+	OrgID               influxdb.ID      `json:"orgID,omitempty"`
+	Type                string           `json:"type"`
+	Description         string           `json:"description,omitempty"`
+	Name                string           `json:"name"`
+	RetentionPolicyName string           `json:"rp,omitempty"` // This to support v1 sources
+	RetentionRules      []retentionRule  `json:"retentionRules"`
+	StorageSettings     *storageSettings `json:"storageSettings,omitempty"`
 	influxdb.CRUDLog
 }
 
@@ -160,6 +161,49 @@ func (rr *retentionRule) RetentionPeriod() (time.Duration, error) {
 	return t, nil
 }
 
+// storageSettings is used for serialization/deserialization of the storage
+// engine settings of a bucket, with durations in seconds.
+type storageSettings struct {
+	CompactFullWriteColdSeconds   int64  `json:"compactFullWriteColdSeconds,omitempty"`
+	CacheSnapshotMemoryBytes      uint64 `json:"cacheSnapshotMemoryBytes,omitempty"`
+	CacheSnapshotWriteColdSeconds int64  `json:"cacheSnapshotWriteColdSeconds,omitempty"`
+}
+
+func (ss *storageSettings) OK() error {
+	if ss == nil {
+		return nil
+	}
+	if ss.CompactFullWriteColdSeconds < 0 || ss.CacheSnapshotWriteColdSeconds < 0 {
+		return &influxdb.Error{
+			Code: influxdb.EUnprocessableEntity,
+			Msg:  "storage settings durations must not be negative",
+		}
+	}
+	return nil
+}
+
+func (ss *storageSettings) toInfluxDB() *influxdb.BucketStorageSettings {
+	if ss == nil {
+		return nil
+	}
+	return &influxdb.BucketStorageSettings{
+		CompactFullWriteColdDuration:   time.Duration(ss.CompactFullWriteColdSeconds) * time.Second,
+		CacheSnapshotMemorySize:        ss.CacheSnapshotMemoryBytes,
+		CacheSnapshotWriteColdDuration: time.Duration(ss.CacheSnapshotWriteColdSeconds) * time.Second,
+	}
+}
+
+func newStorageSettings(s *influxdb.BucketStorageSettings) *storageSettings {
+	if s == nil {
+		return nil
+	}
+	return &storageSettings{
+		CompactFullWriteColdSeconds:   int64(s.CompactFullWriteColdDuration.Round(time.Second) / time.Second),
+		CacheSnapshotMemoryBytes:      s.CacheSnapshotMemorySize,
+		CacheSnapshotWriteColdSeconds: int64(s.CacheSnapshotWriteColdDuration.Round(time.Second) / time.Second),
+	}
+}
+
 func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 	if b == nil {
 		return nil, nil
@@ -186,6 +230,7 @@ func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 		Name:                b.Name,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     d,
+		StorageSettings:     b.StorageSettings.toInfluxDB(),
 		CRUDLog:             b.CRUDLog,
 	}, nil
 }
@@ -212,15 +257,17 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 		Description:         pb.Description,
 		RetentionPolicyName: pb.RetentionPolicyName,
 		RetentionRules:      rules,
+		StorageSettings:     newStorageSettings(pb.StorageSettings),
 		CRUDLog:             pb.CRUDLog,
 	}
 }
 
 // bucketUpdate is used for serialization/deserialization with retention rules.
 type bucketUpdate struct {
-	Name           *string         `json:"name,omitempty"`
-	Description    *string         `json:"description,omitempty"`
-	RetentionRules []retentionRule `json:"retentionRules,omitempty"`
+	Name            *string          `json:"name,omitempty"`
+	Description     *string          `json:"description,omitempty"`
+	RetentionRules  []retentionRule  `json:"retentionRules,omitempty"`
+	StorageSettings *storageSettings `json:"storageSettings,omitempty"`
 }
 
 func (b *bucketUpdate) OK() error {
@@ -230,7 +277,7 @@ func (b *bucketUpdate) OK() error {
 			return err
 		}
 	}
-	return nil
+	return b.StorageSettings.OK()
 }
 
 func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
@@ -248,6 +295,7 @@ func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
 		Name:            b.Name,
 		Description:     b.Description,
 		RetentionPeriod: &d,
+		StorageSettings: b.StorageSettings.toInfluxDB(),
 	}
 }
 
@@ -257,9 +305,10 @@ func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
 	}
 
 	up := &bucketUpdate{
-		Name:           pb.Name,
-		Description:    pb.Description,
-		RetentionRules: []retentionRule{},
+		Name:            pb.Name,
+		Description:     pb.Description,
+		RetentionRules:  []retentionRule{},
+		StorageSettings: newStorageSettings(pb.StorageSettings),
 	}
 
 	if pb.RetentionPeriod != nil {
@@ -336,11 +385,12 @@ func (h *BucketHandler) handlePostBucket(w http.ResponseWriter, r *http.Request)
 }
 
 type postBucketRequest struct {
-	OrgID               influxdb.ID     `json:"orgID,omitempty"`
-	Name                string          `json:"name"`
-	Description         string          `json:"description"`
-	RetentionPolicyName string          `json:"rp,omitempty"` // This to support v1 sources
-	RetentionRules      []retentionRule `json:"retentionRules"`
+	OrgID               influxdb.ID      `json:"orgID,omitempty"`
+	Name                string           `json:"name"`
+	Description         string           `json:"description"`
+	RetentionPolicyName string           `json:"rp,omitempty"` // This to support v1 sources
+	RetentionRules      []retentionRule  `json:"retentionRules"`
+	StorageSettings     *storageSettings `json:"storageSettings,omitempty"`
 }
 
 func (b *postBucketRequest) OK() error {
@@ -361,6 +411,10 @@ func (b *postBucketRequest) OK() error {
 		}
 	}
 
+	if err := b.StorageSettings.OK(); err != nil {
+		return err
+	}
+
 	// names starting with an underscore are reserved for system buckets
 	if err := validBucketName(b.toInfluxDB()); err != nil {
 		return &influxdb.Error{
@@ -386,6 +440,7 @@ func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
 		Type:                influxdb.BucketTypeUser,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     dur,
+		StorageSettings:     b.StorageSettings.toInfluxDB(),
 	}
 }
 
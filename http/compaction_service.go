@@ -0,0 +1,171 @@
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// CompactionBackend is all services and associated parameters required to
+// construct the CompactionHandler.
+type CompactionBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	CompactionService influxdb.CompactionService
+}
+
+// NewCompactionBackend returns a new instance of CompactionBackend.
+func NewCompactionBackend(log *zap.Logger, b *APIBackend) *CompactionBackend {
+	return &CompactionBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		CompactionService: b.CompactionService,
+	}
+}
+
+// CompactionHandler represents an HTTP API handler for storage compactions.
+type CompactionHandler struct {
+	*httprouter.Router
+	api *kithttp.API
+	log *zap.Logger
+
+	CompactionService influxdb.CompactionService
+}
+
+const (
+	prefixCompactions     = "/api/v2/compactions"
+	compactionsPausePath  = "/api/v2/compactions/pause"
+	compactionsResumePath = "/api/v2/compactions/resume"
+)
+
+// NewCompactionHandler returns a new instance of CompactionHandler.
+func NewCompactionHandler(log *zap.Logger, b *CompactionBackend) *CompactionHandler {
+	h := &CompactionHandler{
+		Router: NewRouter(b.HTTPErrorHandler),
+		api:    kithttp.NewAPI(kithttp.WithLog(log)),
+		log:    log,
+
+		CompactionService: b.CompactionService,
+	}
+
+	h.HandlerFunc("GET", prefixCompactions, h.handleGetCompactions)
+	h.HandlerFunc("POST", prefixCompactions, h.handlePostCompaction)
+	h.HandlerFunc("POST", compactionsPausePath, h.handlePauseCompactions)
+	h.HandlerFunc("POST", compactionsResumePath, h.handleResumeCompactions)
+
+	return h
+}
+
+type postCompactionRequest struct {
+	Type influxdb.CompactionType `json:"type"`
+}
+
+func (h *CompactionHandler) handleGetCompactions(w http.ResponseWriter, r *http.Request) {
+	status, err := h.CompactionService.CompactionStatus(r.Context())
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, status)
+}
+
+func (h *CompactionHandler) handlePostCompaction(w http.ResponseWriter, r *http.Request) {
+	var req postCompactionRequest
+	if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	if err := req.Type.Valid(); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.CompactionService.ScheduleCompaction(r.Context(), req.Type); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Compaction scheduled", zap.String("type", string(req.Type)))
+
+	h.api.Respond(w, r, http.StatusAccepted, req)
+}
+
+func (h *CompactionHandler) handlePauseCompactions(w http.ResponseWriter, r *http.Request) {
+	if err := h.CompactionService.PauseCompactions(r.Context()); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Compactions paused")
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
+
+func (h *CompactionHandler) handleResumeCompactions(w http.ResponseWriter, r *http.Request) {
+	if err := h.CompactionService.ResumeCompactions(r.Context()); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Compactions resumed")
+
+	h.api.Respond(w, r, http.StatusNoContent, nil)
+}
+
+// CompactionService connects to Influx via HTTP using tokens to manage compactions.
+type CompactionService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.CompactionService = (*CompactionService)(nil)
+
+// CompactionStatus returns the active and queued compactions.
+func (s *CompactionService) CompactionStatus(ctx context.Context) (*influxdb.CompactionStatus, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var status influxdb.CompactionStatus
+	err := s.Client.
+		Get(prefixCompactions).
+		DecodeJSON(&status).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// PauseCompactions pauses compactions.
+func (s *CompactionService) PauseCompactions(ctx context.Context) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.Client.
+		Post(nil, compactionsPausePath).
+		Do(ctx)
+}
+
+// ResumeCompactions resumes paused compactions.
+func (s *CompactionService) ResumeCompactions(ctx context.Context) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.Client.
+		Post(nil, compactionsResumePath).
+		Do(ctx)
+}
+
+// ScheduleCompaction requests a compaction of the given type.
+func (s *CompactionService) ScheduleCompaction(ctx context.Context, typ influxdb.CompactionType) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.Client.
+		PostJSON(postCompactionRequest{Type: typ}, prefixCompactions).
+		Do(ctx)
+}
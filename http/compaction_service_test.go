@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCompactionService(t *testing.T) {
+	started := time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC)
+	expStatus := &influxdb.CompactionStatus{
+		Paused: true,
+		Levels: []influxdb.CompactionLevelStatus{
+			{
+				Level:     "1",
+				Active:    1,
+				Queued:    1,
+				Completed: 10,
+				Compactions: []influxdb.Compaction{
+					{Active: true, Files: []string{"01-01.tsm", "02-01.tsm"}, StartedAt: &started, Progress: 0.5},
+					{Files: []string{"03-01.tsm", "04-01.tsm"}},
+				},
+			},
+			{Level: "full", Errors: 2},
+		},
+	}
+
+	var (
+		paused    bool
+		scheduled []influxdb.CompactionType
+	)
+	svc := mock.NewCompactionService()
+	svc.CompactionStatusF = func(context.Context) (*influxdb.CompactionStatus, error) {
+		return expStatus, nil
+	}
+	svc.PauseCompactionsF = func(context.Context) error {
+		paused = true
+		return nil
+	}
+	svc.ResumeCompactionsF = func(context.Context) error {
+		paused = false
+		return nil
+	}
+	svc.ScheduleCompactionF = func(_ context.Context, typ influxdb.CompactionType) error {
+		scheduled = append(scheduled, typ)
+		return nil
+	}
+
+	handler := NewCompactionHandler(zaptest.NewLogger(t), &CompactionBackend{
+		log:               zaptest.NewLogger(t),
+		HTTPErrorHandler:  kithttp.ErrorHandler(0),
+		CompactionService: svc,
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	httpClient, err := NewHTTPClient(server.URL, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &CompactionService{Client: httpClient}
+	ctx := context.Background()
+
+	status, err := client.CompactionStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(status, expStatus) {
+		t.Fatalf("status mismatch: got %+v, exp %+v", status, expStatus)
+	}
+
+	if err := client.PauseCompactions(ctx); err != nil {
+		t.Fatal(err)
+	} else if !paused {
+		t.Fatal("expected compactions to be paused")
+	}
+	if err := client.ResumeCompactions(ctx); err != nil {
+		t.Fatal(err)
+	} else if paused {
+		t.Fatal("expected compactions to be resumed")
+	}
+
+	if err := client.ScheduleCompaction(ctx, influxdb.CompactionTypeOptimize); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.ScheduleCompaction(ctx, "minor"); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid compaction type error, got %v", err)
+	}
+	if exp := []influxdb.CompactionType{influxdb.CompactionTypeOptimize}; !reflect.DeepEqual(scheduled, exp) {
+		t.Fatalf("scheduled compactions mismatch: got %v, exp %v", scheduled, exp)
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/influxdata/influxdb/v2/audit"
 	"github.com/influxdata/influxdb/v2/kit/feature"
 	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
 )
 
 // PlatformHandler is a collection of all the service handlers.
@@ -19,7 +21,11 @@ type PlatformHandler struct {
 func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler {
 	h := NewAuthenticationHandler(b.Logger, b.HTTPErrorHandler)
 	h.Handler = feature.NewHandler(b.Logger, b.Flagger, feature.Flags(), NewAPIHandler(b, opts...))
+	if b.AuditService != nil {
+		h.Handler = audit.Authenticated(h.Handler)
+	}
 	h.AuthorizationService = b.AuthorizationService
+	h.RolePermissionService = b.RolePermissionService
 	h.SessionService = b.SessionService
 	h.SessionRenewDisabled = b.SessionRenewDisabled
 	h.UserService = b.UserService
@@ -27,6 +33,8 @@ func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler
 	h.RegisterNoAuthRoute("GET", "/api/v2")
 	h.RegisterNoAuthRoute("POST", "/api/v2/signin")
 	h.RegisterNoAuthRoute("POST", "/api/v2/signout")
+	h.RegisterNoAuthRoute("GET", "/api/v2/signin/oidc")
+	h.RegisterNoAuthRoute("GET", "/api/v2/signin/oidc/callback")
 	h.RegisterNoAuthRoute("POST", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/setup")
 	h.RegisterNoAuthRoute("GET", "/api/v2/swagger.json")
@@ -34,7 +42,12 @@ func NewPlatformHandler(b *APIBackend, opts ...APIHandlerOptFn) *PlatformHandler
 	assetHandler := NewAssetHandler()
 	assetHandler.Path = b.AssetsPath
 
-	wrappedHandler := kithttp.SetCORS(h)
+	// the requests that fail to authenticate are audited as well
+	var wrappedHandler http.Handler = h
+	if b.AuditService != nil {
+		wrappedHandler = audit.Middleware(b.Logger.With(zap.String("service", "audit")), b.AuditService)(wrappedHandler)
+	}
+	wrappedHandler = kithttp.SetCORS(wrappedHandler)
 	wrappedHandler = kithttp.SkipOptions(wrappedHandler)
 
 	return &PlatformHandler{
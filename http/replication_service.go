@@ -0,0 +1,189 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+// ReplicationBackend is all services and associated parameters required to
+// construct the ReplicationHandler.
+type ReplicationBackend struct {
+	log *zap.Logger
+	influxdb.HTTPErrorHandler
+
+	ReplicationService influxdb.ReplicationService
+}
+
+// NewReplicationBackend returns a new instance of ReplicationBackend.
+func NewReplicationBackend(log *zap.Logger, b *APIBackend) *ReplicationBackend {
+	return &ReplicationBackend{
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		ReplicationService: b.ReplicationService,
+	}
+}
+
+// ReplicationHandler represents an HTTP API handler for WAL replication.
+type ReplicationHandler struct {
+	*httprouter.Router
+	api *kithttp.API
+	log *zap.Logger
+
+	ReplicationService influxdb.ReplicationService
+}
+
+const (
+	prefixReplication  = "/api/v2/replication"
+	replicationWALPath = "/api/v2/replication/wal"
+)
+
+// NewReplicationHandler returns a new instance of ReplicationHandler.
+func NewReplicationHandler(log *zap.Logger, b *ReplicationBackend) *ReplicationHandler {
+	h := &ReplicationHandler{
+		Router: NewRouter(b.HTTPErrorHandler),
+		api:    kithttp.NewAPI(kithttp.WithLog(log)),
+		log:    log,
+
+		ReplicationService: b.ReplicationService,
+	}
+
+	h.HandlerFunc("GET", prefixReplication, h.handleGetReplication)
+	h.HandlerFunc("GET", replicationWALPath, h.handleGetWALPosition)
+	h.HandlerFunc("POST", replicationWALPath, h.handlePostWAL)
+
+	return h
+}
+
+func (h *ReplicationHandler) handleGetReplication(w http.ResponseWriter, r *http.Request) {
+	status, err := h.ReplicationService.ReplicationStatus(r.Context())
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, status)
+}
+
+func (h *ReplicationHandler) handleGetWALPosition(w http.ResponseWriter, r *http.Request) {
+	pos, err := h.ReplicationService.WALPosition(r.Context())
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.api.Respond(w, r, http.StatusOK, pos)
+}
+
+func (h *ReplicationHandler) handlePostWAL(w http.ResponseWriter, r *http.Request) {
+	pos, err := decodeWALPosition(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	ack, err := h.ReplicationService.ApplyWAL(r.Context(), pos, r.Body)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("WAL applied",
+		zap.Int("segment", ack.Segment),
+		zap.Int64("from", pos.Offset),
+		zap.Int64("to", ack.Offset))
+
+	h.api.Respond(w, r, http.StatusOK, ack)
+}
+
+func decodeWALPosition(r *http.Request) (influxdb.WALPosition, error) {
+	qp := r.URL.Query()
+
+	segment, err := strconv.Atoi(qp.Get("segment"))
+	if err != nil || segment < 0 {
+		return influxdb.WALPosition{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "segment must be a non-negative integer",
+		}
+	}
+	offset, err := strconv.ParseInt(qp.Get("offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return influxdb.WALPosition{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "offset must be a non-negative integer",
+		}
+	}
+	return influxdb.WALPosition{Segment: segment, Offset: offset}, nil
+}
+
+// ReplicationService connects to Influx via HTTP using tokens to manage and
+// take part in WAL replication.
+type ReplicationService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.ReplicationService = (*ReplicationService)(nil)
+
+// ReplicationStatus returns the WAL replication state of the server.
+func (s *ReplicationService) ReplicationStatus(ctx context.Context) (*influxdb.ReplicationStatus, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var status influxdb.ReplicationStatus
+	err := s.Client.
+		Get(prefixReplication).
+		DecodeJSON(&status).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// WALPosition returns the position up to which the follower has applied the
+// WAL of its leader.
+func (s *ReplicationService) WALPosition(ctx context.Context) (*influxdb.WALPosition, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var pos influxdb.WALPosition
+	err := s.Client.
+		Get(replicationWALPath).
+		DecodeJSON(&pos).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pos, nil
+}
+
+// ApplyWAL ships the WAL data read from r, which starts at pos, to the follower.
+func (s *ReplicationService) ApplyWAL(ctx context.Context, pos influxdb.WALPosition, r io.Reader) (*influxdb.WALPosition, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	body := func(w io.Writer) (string, string, error) {
+		_, err := io.Copy(w, r)
+		return "Content-Type", "application/octet-stream", err
+	}
+
+	var ack influxdb.WALPosition
+	err := s.Client.
+		Post(body, replicationWALPath).
+		QueryParams(
+			[2]string{"segment", strconv.Itoa(pos.Segment)},
+			[2]string{"offset", strconv.FormatInt(pos.Offset, 10)},
+		).
+		DecodeJSON(&ack).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ack, nil
+}
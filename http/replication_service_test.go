@@ -0,0 +1,91 @@
+package http
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestReplicationService(t *testing.T) {
+	lastAck := time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC)
+	expStatus := &influxdb.ReplicationStatus{
+		Role:        influxdb.ReplicationRoleLeader,
+		Position:    &influxdb.WALPosition{Segment: 3, Offset: 1024},
+		LagBytes:    2048,
+		LagSegments: 1,
+		LastAck:     &lastAck,
+	}
+
+	var applied string
+	svc := mock.NewReplicationService()
+	svc.ReplicationStatusF = func(context.Context) (*influxdb.ReplicationStatus, error) {
+		return expStatus, nil
+	}
+	svc.WALPositionF = func(context.Context) (*influxdb.WALPosition, error) {
+		return &influxdb.WALPosition{Segment: 3, Offset: 1024}, nil
+	}
+	svc.ApplyWALF = func(_ context.Context, pos influxdb.WALPosition, r io.Reader) (*influxdb.WALPosition, error) {
+		if pos.Offset != 1024 {
+			return nil, &influxdb.Error{Code: influxdb.EConflict, Msg: "unexpected offset"}
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		applied = string(data)
+		return &influxdb.WALPosition{Segment: pos.Segment, Offset: pos.Offset + int64(len(data))}, nil
+	}
+
+	handler := NewReplicationHandler(zaptest.NewLogger(t), &ReplicationBackend{
+		log:                zaptest.NewLogger(t),
+		HTTPErrorHandler:   kithttp.ErrorHandler(0),
+		ReplicationService: svc,
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	httpClient, err := NewHTTPClient(server.URL, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &ReplicationService{Client: httpClient}
+	ctx := context.Background()
+
+	status, err := client.ReplicationStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(status, expStatus) {
+		t.Fatalf("status mismatch: got %+v, exp %+v", status, expStatus)
+	}
+
+	pos, err := client.WALPosition(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ack, err := client.ApplyWAL(ctx, *pos, strings.NewReader("segment data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := (influxdb.WALPosition{Segment: 3, Offset: 1036}); *ack != exp {
+		t.Fatalf("position mismatch: got %v, exp %v", *ack, exp)
+	}
+	if exp := "segment data"; applied != exp {
+		t.Fatalf("applied data mismatch: got %q, exp %q", applied, exp)
+	}
+
+	if _, err := client.ApplyWAL(ctx, influxdb.WALPosition{Segment: 3}, strings.NewReader("")); influxdb.ErrorCode(err) != influxdb.EConflict {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+}
@@ -22,6 +22,7 @@ type SessionBackend struct {
 
 	PasswordsService platform.PasswordsService
 	SessionService   platform.SessionService
+	SignInService    platform.SignInService
 	UserService      platform.UserService
 }
 
@@ -33,6 +34,7 @@ func NewSessionBackend(log *zap.Logger, b *APIBackend) *SessionBackend {
 
 		PasswordsService: b.PasswordsService,
 		SessionService:   b.SessionService,
+		SignInService:    b.SignInService,
 		UserService:      b.UserService,
 	}
 }
@@ -45,6 +47,7 @@ type SessionHandler struct {
 
 	PasswordsService platform.PasswordsService
 	SessionService   platform.SessionService
+	SignInService    platform.SignInService
 	UserService      platform.UserService
 }
 
@@ -57,6 +60,7 @@ func NewSessionHandler(log *zap.Logger, b *SessionBackend) *SessionHandler {
 
 		PasswordsService: b.PasswordsService,
 		SessionService:   b.SessionService,
+		SignInService:    b.SignInService,
 		UserService:      b.UserService,
 	}
 
@@ -75,15 +79,7 @@ func (h *SessionHandler) handleSignin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	u, err := h.UserService.FindUser(ctx, platform.UserFilter{
-		Name: &req.Username,
-	})
-	if err != nil {
-		UnauthorizedError(ctx, h, w)
-		return
-	}
-
-	if err := h.PasswordsService.ComparePassword(ctx, u.ID, req.Password); err != nil {
+	if err := h.signIn(ctx, req.Username, req.Password); err != nil {
 		// Don't log here, it should already be handled by the service
 		UnauthorizedError(ctx, h, w)
 		return
@@ -99,6 +95,23 @@ func (h *SessionHandler) handleSignin(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// signIn verifies the credentials with the SignInService when there is one,
+// and otherwise with the local password of the user.
+func (h *SessionHandler) signIn(ctx context.Context, username, password string) error {
+	if h.SignInService != nil {
+		_, err := h.SignInService.SignIn(ctx, username, password)
+		return err
+	}
+
+	u, err := h.UserService.FindUser(ctx, platform.UserFilter{
+		Name: &username,
+	})
+	if err != nil {
+		return err
+	}
+	return h.PasswordsService.ComparePassword(ctx, u.ID, password)
+}
+
 type signinRequest struct {
 	Username string
 	Password string
@@ -0,0 +1,210 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+const (
+	tasksIDBackfillPath   = "/api/v2/tasks/:id/backfill"
+	tasksIDBackfillIDPath = "/api/v2/tasks/:id/backfill/:bid"
+)
+
+type backfillResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.Backfill
+}
+
+func newBackfillResponse(b influxdb.Backfill) backfillResponse {
+	return backfillResponse{
+		Links: map[string]string{
+			"self": fmt.Sprintf("/api/v2/tasks/%s/backfill/%s", b.TaskID, b.ID),
+			"task": fmt.Sprintf("/api/v2/tasks/%s", b.TaskID),
+			"runs": fmt.Sprintf("/api/v2/tasks/%s/runs", b.TaskID),
+		},
+		Backfill: b,
+	}
+}
+
+type backfillsResponse struct {
+	Backfills []backfillResponse `json:"backfills"`
+}
+
+func newBackfillsResponse(bs []*influxdb.Backfill) backfillsResponse {
+	res := backfillsResponse{Backfills: []backfillResponse{}}
+	for _, b := range bs {
+		res.Backfills = append(res.Backfills, newBackfillResponse(*b))
+	}
+	return res
+}
+
+func (h *TaskHandler) handlePostBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var bc influxdb.BackfillCreate
+	if err := json.NewDecoder(r.Body).Decode(&bc); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Err:  err,
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode request",
+		}, w)
+		return
+	}
+
+	b, err := h.BackfillService.CreateBackfill(ctx, taskID, bc)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusCreated, newBackfillResponse(*b)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *TaskHandler) handleGetBackfills(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	bs, err := h.BackfillService.FindBackfills(ctx, taskID)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, newBackfillsResponse(bs)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *TaskHandler) handleGetBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, id, err := decodeBackfillIDs(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	b, err := h.BackfillService.FindBackfillByID(ctx, taskID, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, newBackfillResponse(*b)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *TaskHandler) handleCancelBackfill(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, id, err := decodeBackfillIDs(ctx)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.BackfillService.CancelBackfill(ctx, taskID, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeBackfillIDs(ctx context.Context) (taskID, id influxdb.ID, err error) {
+	if taskID, err = decodeIDFromCtx(ctx, "id"); err != nil {
+		return 0, 0, err
+	}
+	if id, err = decodeIDFromCtx(ctx, "bid"); err != nil {
+		return 0, 0, err
+	}
+	return taskID, id, nil
+}
+
+var _ influxdb.BackfillService = (*TaskService)(nil)
+
+// CreateBackfill enqueues a run of a task for every point of its schedule in a time range.
+func (t TaskService) CreateBackfill(ctx context.Context, taskID influxdb.ID, bc influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var b backfillResponse
+	err := t.Client.
+		PostJSON(bc, taskIDBackfillPath(taskID)).
+		DecodeJSON(&b).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &b.Backfill, nil
+}
+
+// FindBackfillByID returns the progress of a single backfill of a task.
+func (t TaskService) FindBackfillByID(ctx context.Context, taskID, id influxdb.ID) (*influxdb.Backfill, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var b backfillResponse
+	err := t.Client.
+		Get(taskIDBackfillPath(taskID), id.String()).
+		DecodeJSON(&b).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &b.Backfill, nil
+}
+
+// FindBackfills returns the backfills of a task.
+func (t TaskService) FindBackfills(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Backfill, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res backfillsResponse
+	err := t.Client.
+		Get(taskIDBackfillPath(taskID)).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bs := make([]*influxdb.Backfill, 0, len(res.Backfills))
+	for i := range res.Backfills {
+		bs = append(bs, &res.Backfills[i].Backfill)
+	}
+	return bs, nil
+}
+
+// CancelBackfill cancels a backfill of a task.
+func (t TaskService) CancelBackfill(ctx context.Context, taskID, id influxdb.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return t.Client.
+		Delete(taskIDBackfillPath(taskID), id.String()).
+		Do(ctx)
+}
+
+func taskIDBackfillPath(id influxdb.ID) string {
+	return path.Join(prefixTasks, id.String(), "backfill")
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTaskHandler_Backfill(t *testing.T) {
+	var (
+		taskID = influxdb.ID(1)
+		start  = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		end    = start.Add(time.Hour)
+		exp    = &influxdb.Backfill{
+			ID:          2,
+			TaskID:      taskID,
+			Start:       start,
+			End:         end,
+			Parallelism: 2,
+			Status:      influxdb.RunStarted.String(),
+			Total:       61,
+			Completed:   3,
+			CreatedAt:   start,
+		}
+		canceled influxdb.ID
+	)
+
+	svc := mock.NewBackfillService()
+	svc.CreateBackfillF = func(_ context.Context, id influxdb.ID, bc influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+		if id != taskID || !bc.Start.Equal(start) || !bc.End.Equal(end) || bc.Parallelism != 2 {
+			t.Errorf("unexpected backfill request for task %s: %+v", id, bc)
+		}
+		return exp, nil
+	}
+	svc.FindBackfillByIDF = func(_ context.Context, tid, id influxdb.ID) (*influxdb.Backfill, error) {
+		if id != exp.ID {
+			return nil, influxdb.ErrBackfillNotFound
+		}
+		return exp, nil
+	}
+	svc.FindBackfillsF = func(context.Context, influxdb.ID) ([]*influxdb.Backfill, error) {
+		return []*influxdb.Backfill{exp}, nil
+	}
+	svc.CancelBackfillF = func(_ context.Context, tid, id influxdb.ID) error {
+		canceled = id
+		return nil
+	}
+
+	handler := NewTaskHandler(zaptest.NewLogger(t), &TaskBackend{
+		log:              zaptest.NewLogger(t),
+		HTTPErrorHandler: kithttp.ErrorHandler(0),
+		BackfillService:  svc,
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	httpClient, err := NewHTTPClient(server.URL, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &TaskService{Client: httpClient}
+	ctx := context.Background()
+
+	b, err := client.CreateBackfill(ctx, taskID, influxdb.BackfillCreate{Start: start, End: end, Parallelism: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b, exp) {
+		t.Fatalf("backfill mismatch: got %+v, exp %+v", b, exp)
+	}
+
+	if b, err = client.FindBackfillByID(ctx, taskID, exp.ID); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(b, exp) {
+		t.Fatalf("backfill mismatch: got %+v, exp %+v", b, exp)
+	}
+	if _, err := client.FindBackfillByID(ctx, taskID, exp.ID+1); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	bs, err := client.FindBackfills(ctx, taskID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bs) != 1 || !reflect.DeepEqual(bs[0], exp) {
+		t.Fatalf("backfills mismatch: got %+v", bs)
+	}
+
+	if err := client.CancelBackfill(ctx, taskID, exp.ID); err != nil {
+		t.Fatal(err)
+	}
+	if canceled != exp.ID {
+		t.Fatalf("expected backfill %s to be canceled, got %s", exp.ID, canceled)
+	}
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+)
+
+const tasksIDDryRunPath = "/api/v2/tasks/:id/dryrun"
+
+type dryRunResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.TaskDryRun
+}
+
+func newDryRunResponse(dr influxdb.TaskDryRun) dryRunResponse {
+	return dryRunResponse{
+		Links: map[string]string{
+			"task": fmt.Sprintf("/api/v2/tasks/%s", dr.TaskID),
+		},
+		TaskDryRun: dr,
+	}
+}
+
+func (h *TaskHandler) handlePostDryRun(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskID, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	var req influxdb.TaskDryRunRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.HandleHTTPError(ctx, &influxdb.Error{
+				Err:  err,
+				Code: influxdb.EInvalid,
+				Msg:  "failed to decode request",
+			}, w)
+			return
+		}
+	}
+
+	dr, err := h.TaskDryRunService.DryRunTask(ctx, taskID, req)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, newDryRunResponse(*dr)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+var _ influxdb.TaskDryRunService = (*TaskService)(nil)
+
+// DryRunTask executes a task once without writing to storage.
+func (t TaskService) DryRunTask(ctx context.Context, taskID influxdb.ID, req influxdb.TaskDryRunRequest) (*influxdb.TaskDryRun, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var dr dryRunResponse
+	err := t.Client.
+		PostJSON(req, path.Join(prefixTasks, taskID.String(), "dryrun")).
+		DecodeJSON(&dr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &dr.TaskDryRun, nil
+}
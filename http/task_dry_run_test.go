@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"github.com/influxdata/influxdb/v2/mock"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTaskHandler_DryRun(t *testing.T) {
+	var (
+		taskID       = influxdb.ID(1)
+		scheduledFor = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		script       = `option task = {name: "a", every: 1h} from(bucket: "b") |> range(start: -1h)`
+		exp          = &influxdb.TaskDryRun{
+			TaskID:       taskID,
+			ScheduledFor: scheduledFor,
+			Results:      "#datatype,string,long\r\n",
+			Writes: []influxdb.TaskDryRunWrite{
+				{BucketID: 2, Points: "cpu,host=a usage=1.5 100\n"},
+			},
+			Plan:       "digraph {}",
+			Statistics: flux.Statistics{TotalDuration: time.Second},
+		}
+	)
+
+	svc := mock.NewTaskDryRunService()
+	svc.DryRunTaskF = func(_ context.Context, id influxdb.ID, req influxdb.TaskDryRunRequest) (*influxdb.TaskDryRun, error) {
+		if id != taskID || !req.ScheduledFor.Equal(scheduledFor) || req.Flux == nil || *req.Flux != script {
+			t.Errorf("unexpected dry run request for task %s: %+v", id, req)
+		}
+		return exp, nil
+	}
+
+	handler := NewTaskHandler(zaptest.NewLogger(t), &TaskBackend{
+		log:               zaptest.NewLogger(t),
+		HTTPErrorHandler:  kithttp.ErrorHandler(0),
+		TaskDryRunService: svc,
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	httpClient, err := NewHTTPClient(server.URL, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &TaskService{Client: httpClient}
+
+	dr, err := client.DryRunTask(context.Background(), taskID, influxdb.TaskDryRunRequest{ScheduledFor: scheduledFor, Flux: &script})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(dr, exp) {
+		t.Fatalf("dry run mismatch: got %+v, exp %+v", dr, exp)
+	}
+}
@@ -34,6 +34,8 @@ type TaskBackend struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	BucketService              influxdb.BucketService
+	BackfillService            influxdb.BackfillService
+	TaskDryRunService          influxdb.TaskDryRunService
 }
 
 // NewTaskBackend returns a new instance of TaskBackend.
@@ -49,6 +51,8 @@ func NewTaskBackend(log *zap.Logger, b *APIBackend) *TaskBackend {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		BucketService:              b.BucketService,
+		BackfillService:            b.BackfillService,
+		TaskDryRunService:          b.TaskDryRunService,
 	}
 }
 
@@ -65,6 +69,8 @@ type TaskHandler struct {
 	LabelService               influxdb.LabelService
 	UserService                influxdb.UserService
 	BucketService              influxdb.BucketService
+	BackfillService            influxdb.BackfillService
+	TaskDryRunService          influxdb.TaskDryRunService
 }
 
 const (
@@ -97,6 +103,8 @@ func NewTaskHandler(log *zap.Logger, b *TaskBackend) *TaskHandler {
 		LabelService:               b.LabelService,
 		UserService:                b.UserService,
 		BucketService:              b.BucketService,
+		BackfillService:            b.BackfillService,
+		TaskDryRunService:          b.TaskDryRunService,
 	}
 
 	h.HandlerFunc("GET", prefixTasks, h.handleGetTasks)
@@ -139,6 +147,17 @@ func NewTaskHandler(log *zap.Logger, b *TaskBackend) *TaskHandler {
 	h.HandlerFunc("POST", tasksIDRunsIDRetryPath, h.handleRetryRun)
 	h.HandlerFunc("DELETE", tasksIDRunsIDPath, h.handleCancelRun)
 
+	if h.BackfillService != nil {
+		h.HandlerFunc("POST", tasksIDBackfillPath, h.handlePostBackfill)
+		h.HandlerFunc("GET", tasksIDBackfillPath, h.handleGetBackfills)
+		h.HandlerFunc("GET", tasksIDBackfillIDPath, h.handleGetBackfill)
+		h.HandlerFunc("DELETE", tasksIDBackfillIDPath, h.handleCancelBackfill)
+	}
+
+	if h.TaskDryRunService != nil {
+		h.HandlerFunc("POST", tasksIDDryRunPath, h.handlePostDryRun)
+	}
+
 	labelBackend := &LabelBackend{
 		HTTPErrorHandler: b.HTTPErrorHandler,
 		log:              b.log.With(zap.String("handler", "label")),
@@ -155,23 +174,31 @@ func NewTaskHandler(log *zap.Logger, b *TaskBackend) *TaskHandler {
 // Task is a package-specific Task format that preserves the expected format for the API,
 // where time values are represented as strings
 type Task struct {
-	ID              influxdb.ID            `json:"id"`
-	OrganizationID  influxdb.ID            `json:"orgID"`
-	Organization    string                 `json:"org"`
-	OwnerID         influxdb.ID            `json:"ownerID"`
-	Name            string                 `json:"name"`
-	Description     string                 `json:"description,omitempty"`
-	Status          string                 `json:"status"`
-	Flux            string                 `json:"flux"`
-	Every           string                 `json:"every,omitempty"`
-	Cron            string                 `json:"cron,omitempty"`
-	Offset          string                 `json:"offset,omitempty"`
-	LatestCompleted string                 `json:"latestCompleted,omitempty"`
-	LastRunStatus   string                 `json:"lastRunStatus,omitempty"`
-	LastRunError    string                 `json:"lastRunError,omitempty"`
-	CreatedAt       string                 `json:"createdAt,omitempty"`
-	UpdatedAt       string                 `json:"updatedAt,omitempty"`
-	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	ID              influxdb.ID                `json:"id"`
+	OrganizationID  influxdb.ID                `json:"orgID"`
+	Organization    string                     `json:"org"`
+	OwnerID         influxdb.ID                `json:"ownerID"`
+	Name            string                     `json:"name"`
+	Description     string                     `json:"description,omitempty"`
+	Status          string                     `json:"status"`
+	Flux            string                     `json:"flux"`
+	Every           string                     `json:"every,omitempty"`
+	Cron            string                     `json:"cron,omitempty"`
+	Offset          string                     `json:"offset,omitempty"`
+	Upstream        []influxdb.ID              `json:"upstream,omitempty"`
+	TemplateID      influxdb.ID                `json:"templateID,omitempty"`
+	TemplateParams  map[string]interface{}     `json:"templateParams,omitempty"`
+	Notification    *influxdb.TaskNotification `json:"notification,omitempty"`
+	Trigger         *influxdb.TaskTrigger      `json:"trigger,omitempty"`
+	RunRetention    *influxdb.TaskRunRetention `json:"runRetention,omitempty"`
+	LatestCompleted string                     `json:"latestCompleted,omitempty"`
+	LastRunStatus   string                     `json:"lastRunStatus,omitempty"`
+	LastRunError    string                     `json:"lastRunError,omitempty"`
+	CreatedAt       string                     `json:"createdAt,omitempty"`
+	UpdatedAt       string                     `json:"updatedAt,omitempty"`
+	Metadata        map[string]interface{}     `json:"metadata,omitempty"`
+
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
 }
 
 type taskResponse struct {
@@ -211,12 +238,20 @@ func NewFrontEndTask(t influxdb.Task) Task {
 		Every:           t.Every,
 		Cron:            t.Cron,
 		Offset:          offset,
+		Upstream:        t.Upstream,
+		TemplateID:      t.TemplateID,
+		TemplateParams:  t.TemplateParams,
+		Notification:    t.Notification,
+		Trigger:         t.Trigger,
+		RunRetention:    t.RunRetention,
 		LatestCompleted: latestCompleted,
 		LastRunStatus:   t.LastRunStatus,
 		LastRunError:    t.LastRunError,
 		CreatedAt:       createdAt,
 		UpdatedAt:       updatedAt,
 		Metadata:        t.Metadata,
+
+		ConsecutiveFailures: t.ConsecutiveFailures,
 	}
 }
 
@@ -343,7 +378,11 @@ type httpRun struct {
 	StartedAt    *time.Time     `json:"startedAt,omitempty"`
 	FinishedAt   *time.Time     `json:"finishedAt,omitempty"`
 	RequestedAt  *time.Time     `json:"requestedAt,omitempty"`
+	Attempt      int            `json:"attempt,omitempty"`
+	RetryOf      influxdb.ID    `json:"retryOf,omitempty"`
 	Log          []influxdb.Log `json:"log,omitempty"`
+
+	Trigger *influxdb.RunTrigger `json:"trigger,omitempty"`
 }
 
 func newRunResponse(r influxdb.Run) runResponse {
@@ -351,8 +390,11 @@ func newRunResponse(r influxdb.Run) runResponse {
 		ID:           r.ID,
 		TaskID:       r.TaskID,
 		Status:       r.Status,
+		Attempt:      r.Attempt,
+		RetryOf:      r.RetryOf,
 		Log:          r.Log,
 		ScheduledFor: &r.ScheduledFor,
+		Trigger:      r.Trigger,
 	}
 
 	if !r.StartedAt.IsZero() {
@@ -378,10 +420,13 @@ func newRunResponse(r influxdb.Run) runResponse {
 
 func convertRun(r httpRun) *influxdb.Run {
 	run := &influxdb.Run{
-		ID:     r.ID,
-		TaskID: r.TaskID,
-		Status: r.Status,
-		Log:    r.Log,
+		ID:      r.ID,
+		TaskID:  r.TaskID,
+		Status:  r.Status,
+		Attempt: r.Attempt,
+		RetryOf: r.RetryOf,
+		Log:     r.Log,
+		Trigger: r.Trigger,
 	}
 
 	if r.StartedAt != nil {
@@ -408,7 +453,8 @@ type runsResponse struct {
 	Runs  []*runResponse    `json:"runs"`
 }
 
-func newRunsResponse(rs []*influxdb.Run, taskID influxdb.ID) runsResponse {
+func newRunsResponse(rs []*influxdb.Run, filter influxdb.RunFilter) runsResponse {
+	taskID := filter.Task
 	r := runsResponse{
 		Links: map[string]string{
 			"self": fmt.Sprintf("/api/v2/tasks/%s/runs", taskID),
@@ -417,6 +463,18 @@ func newRunsResponse(rs []*influxdb.Run, taskID influxdb.ID) runsResponse {
 		Runs: make([]*runResponse, len(rs)),
 	}
 
+	// a full page of runs listed after a cursor links to the next page
+	limit := filter.Limit
+	if limit == 0 {
+		limit = influxdb.TaskDefaultPageSize
+	}
+	if filter.Cursor != nil && len(rs) > 0 && len(rs) >= limit {
+		next := url.Values{}
+		next.Set("cursor", influxdb.NewRunCursor(rs[len(rs)-1]).String())
+		next.Set("limit", strconv.Itoa(limit))
+		r.Links["next"] = fmt.Sprintf("/api/v2/tasks/%s/runs?%s", taskID, next.Encode())
+	}
+
 	for i := range rs {
 		rs := newRunResponse(*rs[i])
 		r.Runs[i] = &rs
@@ -526,6 +584,22 @@ func decodeGetTasksRequest(ctx context.Context, r *http.Request, orgs influxdb.O
 		req.filter.Name = &name
 	}
 
+	if upstream := qp.Get("upstream"); upstream != "" {
+		id, err := influxdb.IDFromString(upstream)
+		if err != nil {
+			return nil, err
+		}
+		req.filter.Upstream = id
+	}
+
+	if templateID := qp.Get("templateID"); templateID != "" {
+		id, err := influxdb.IDFromString(templateID)
+		if err != nil {
+			return nil, err
+		}
+		req.filter.TemplateID = id
+	}
+
 	return req, nil
 }
 
@@ -954,7 +1028,7 @@ func (h *TaskHandler) handleGetRuns(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := encodeResponse(ctx, w, http.StatusOK, newRunsResponse(runs, req.filter.Task)); err != nil {
+	if err := encodeResponse(ctx, w, http.StatusOK, newRunsResponse(runs, req.filter)); err != nil {
 		logEncodingError(h.log, r, err)
 		return
 	}
@@ -1003,6 +1077,15 @@ func decodeGetRunsRequest(ctx context.Context, r *http.Request) (*getRunsRequest
 		req.filter.Limit = i
 	}
 
+	// an empty cursor starts paging from the most recently scheduled run
+	if _, ok := qp["cursor"]; ok {
+		c, err := influxdb.ParseRunCursor(qp.Get("cursor"))
+		if err != nil {
+			return nil, err
+		}
+		req.filter.Cursor = c
+	}
+
 	var at, bt string
 	var afterTime, beforeTime time.Time
 	if at = qp.Get("afterTime"); at != "" {
@@ -1460,6 +1543,10 @@ func (t TaskService) FindTasks(ctx context.Context, filter influxdb.TaskFilter)
 		params = append(params, [2]string{"type", *filter.Type})
 	}
 
+	if filter.TemplateID != nil {
+		params = append(params, [2]string{"templateID", filter.TemplateID.String()})
+	}
+
 	var tr tasksResponse
 	err := t.Client.
 		Get(prefixTasks).
@@ -1570,6 +1657,10 @@ func (t TaskService) FindRuns(ctx context.Context, filter influxdb.RunFilter) ([
 
 	params = append(params, [2]string{"limit", strconv.Itoa(filter.Limit)})
 
+	if filter.Cursor != nil {
+		params = append(params, [2]string{"cursor", filter.Cursor.String()})
+	}
+
 	var rs runsResponse
 	err := t.Client.
 		Get(taskIDRunsPath(filter.Task)).
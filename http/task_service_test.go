@@ -706,6 +706,7 @@ func TestTaskHandler_handleGetRuns(t *testing.T) {
 	}
 	type args struct {
 		taskID influxdb.ID
+		query  string
 	}
 	type wants struct {
 		statusCode  int
@@ -772,6 +773,67 @@ func TestTaskHandler_handleGetRuns(t *testing.T) {
       "requestedAt": "2018-12-01T17:00:13Z"
     }
   ]
+}`,
+			},
+		},
+		{
+			name: "get runs after a cursor",
+			fields: fields{
+				taskService: &mock.TaskService{
+					FindRunsFn: func(ctx context.Context, f influxdb.RunFilter) ([]*influxdb.Run, int, error) {
+						if f.Cursor == nil || !f.Cursor.IsZero() || f.Limit != 1 {
+							return nil, 0, fmt.Errorf("unexpected filter %+v", f)
+						}
+						scheduledFor, _ := time.Parse(time.RFC3339, "2018-12-01T17:00:13Z")
+						startedAt, _ := time.Parse(time.RFC3339Nano, "2018-12-01T17:00:03.155645Z")
+						finishedAt, _ := time.Parse(time.RFC3339Nano, "2018-12-01T17:00:13.155645Z")
+						requestedAt, _ := time.Parse(time.RFC3339, "2018-12-01T17:00:13Z")
+						runs := []*influxdb.Run{
+							{
+								ID:           influxdb.ID(2),
+								TaskID:       f.Task,
+								Status:       "success",
+								ScheduledFor: scheduledFor,
+								StartedAt:    startedAt,
+								FinishedAt:   finishedAt,
+								RequestedAt:  requestedAt,
+							},
+						}
+						return runs, len(runs), nil
+					},
+				},
+			},
+			args: args{
+				taskID: 1,
+				query:  "?cursor=&limit=1",
+			},
+			wants: wants{
+				statusCode:  http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				body: `
+{
+  "links": {
+    "self": "/api/v2/tasks/0000000000000001/runs",
+    "task": "/api/v2/tasks/0000000000000001",
+    "next": "/api/v2/tasks/0000000000000001/runs?cursor=MTU0MzY4MzYxMy8wMDAwMDAwMDAwMDAwMDAy&limit=1"
+  },
+  "runs": [
+    {
+      "links": {
+        "self": "/api/v2/tasks/0000000000000001/runs/0000000000000002",
+        "task": "/api/v2/tasks/0000000000000001",
+        "retry": "/api/v2/tasks/0000000000000001/runs/0000000000000002/retry",
+        "logs": "/api/v2/tasks/0000000000000001/runs/0000000000000002/logs"
+      },
+      "id": "0000000000000002",
+      "taskID": "0000000000000001",
+      "status": "success",
+      "scheduledFor": "2018-12-01T17:00:13Z",
+      "startedAt": "2018-12-01T17:00:03.155645Z",
+      "finishedAt": "2018-12-01T17:00:13.155645Z",
+      "requestedAt": "2018-12-01T17:00:13Z"
+    }
+  ]
 }`,
 			},
 		},
@@ -779,7 +841,7 @@ func TestTaskHandler_handleGetRuns(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := httptest.NewRequest("GET", "http://any.url", nil)
+			r := httptest.NewRequest("GET", "http://any.url"+tt.args.query, nil)
 			r = r.WithContext(context.WithValue(
 				context.Background(),
 				httprouter.ParamsKey,
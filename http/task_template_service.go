@@ -0,0 +1,369 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/influxdata/httprouter"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/pkg/httpc"
+	"go.uber.org/zap"
+)
+
+const (
+	prefixTaskTemplates = "/api/v2/taskTemplates"
+)
+
+// TaskTemplateBackend is all services and associated parameters required to construct
+// the TaskTemplateHandler.
+type TaskTemplateBackend struct {
+	influxdb.HTTPErrorHandler
+	log                 *zap.Logger
+	TaskTemplateService influxdb.TaskTemplateService
+}
+
+// NewTaskTemplateBackend creates a backend used by the task template handler.
+func NewTaskTemplateBackend(log *zap.Logger, b *APIBackend) *TaskTemplateBackend {
+	return &TaskTemplateBackend{
+		HTTPErrorHandler:    b.HTTPErrorHandler,
+		log:                 log,
+		TaskTemplateService: b.TaskTemplateService,
+	}
+}
+
+// TaskTemplateHandler is the handler for the task template service
+type TaskTemplateHandler struct {
+	*httprouter.Router
+
+	influxdb.HTTPErrorHandler
+	log *zap.Logger
+
+	TaskTemplateService influxdb.TaskTemplateService
+}
+
+// NewTaskTemplateHandler creates a new TaskTemplateHandler
+func NewTaskTemplateHandler(log *zap.Logger, b *TaskTemplateBackend) *TaskTemplateHandler {
+	h := &TaskTemplateHandler{
+		Router:           NewRouter(b.HTTPErrorHandler),
+		HTTPErrorHandler: b.HTTPErrorHandler,
+		log:              log,
+
+		TaskTemplateService: b.TaskTemplateService,
+	}
+
+	entityPath := fmt.Sprintf("%s/:id", prefixTaskTemplates)
+	entityPreviewPath := fmt.Sprintf("%s/preview", entityPath)
+
+	h.HandlerFunc("GET", prefixTaskTemplates, h.handleGetTaskTemplates)
+	h.HandlerFunc("POST", prefixTaskTemplates, h.handlePostTaskTemplate)
+	h.HandlerFunc("GET", entityPath, h.handleGetTaskTemplate)
+	h.HandlerFunc("PATCH", entityPath, h.handlePatchTaskTemplate)
+	h.HandlerFunc("DELETE", entityPath, h.handleDeleteTaskTemplate)
+	h.HandlerFunc("POST", entityPreviewPath, h.handlePostTaskTemplatePreview)
+
+	return h
+}
+
+type taskTemplateResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.TaskTemplate
+}
+
+func newTaskTemplateResponse(t *influxdb.TaskTemplate) taskTemplateResponse {
+	return taskTemplateResponse{
+		Links: map[string]string{
+			"self":  path.Join(prefixTaskTemplates, t.ID.String()),
+			"org":   path.Join(prefixOrganizations, t.OrganizationID.String()),
+			"tasks": fmt.Sprintf("%s?orgID=%s&templateID=%s", prefixTasks, t.OrganizationID, t.ID),
+		},
+		TaskTemplate: *t,
+	}
+}
+
+type taskTemplatesResponse struct {
+	TaskTemplates []taskTemplateResponse `json:"taskTemplates"`
+}
+
+func newTaskTemplatesResponse(ts []*influxdb.TaskTemplate) taskTemplatesResponse {
+	res := taskTemplatesResponse{TaskTemplates: []taskTemplateResponse{}}
+	for _, t := range ts {
+		res.TaskTemplates = append(res.TaskTemplates, newTaskTemplateResponse(t))
+	}
+	return res
+}
+
+type taskTemplateDiffsResponse struct {
+	Diffs []*influxdb.TaskTemplateDiff `json:"diffs"`
+}
+
+func (h *TaskTemplateHandler) handleGetTaskTemplates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var filter influxdb.TaskTemplateFilter
+	qp := r.URL.Query()
+	if orgID := qp.Get("orgID"); orgID != "" {
+		id, err := influxdb.IDFromString(orgID)
+		if err != nil {
+			h.HandleHTTPError(ctx, err, w)
+			return
+		}
+		filter.OrganizationID = id
+	}
+	if name := qp.Get("name"); name != "" {
+		filter.Name = &name
+	}
+
+	ts, _, err := h.TaskTemplateService.FindTaskTemplates(ctx, filter)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskTemplatesResponse(ts)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *TaskTemplateHandler) handlePostTaskTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var t influxdb.TaskTemplate
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Err:  err,
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode request",
+		}, w)
+		return
+	}
+	if !t.OrganizationID.Valid() {
+		h.HandleHTTPError(ctx, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "invalid organization id",
+		}, w)
+		return
+	}
+
+	if err := h.TaskTemplateService.CreateTaskTemplate(ctx, &t); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusCreated, newTaskTemplateResponse(&t)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *TaskTemplateHandler) handleGetTaskTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	t, err := h.TaskTemplateService.FindTaskTemplateByID(ctx, id)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskTemplateResponse(t)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *TaskTemplateHandler) handlePatchTaskTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, upd, err := decodeTaskTemplateUpdate(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	t, err := h.TaskTemplateService.UpdateTaskTemplate(ctx, id, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, newTaskTemplateResponse(t)); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *TaskTemplateHandler) handlePostTaskTemplatePreview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, upd, err := decodeTaskTemplateUpdate(r)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	diffs, err := h.TaskTemplateService.PreviewTaskTemplateUpdate(ctx, id, upd)
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	if diffs == nil {
+		diffs = []*influxdb.TaskTemplateDiff{}
+	}
+	if err := encodeResponse(ctx, w, http.StatusOK, taskTemplateDiffsResponse{Diffs: diffs}); err != nil {
+		logEncodingError(h.log, r, err)
+		return
+	}
+}
+
+func (h *TaskTemplateHandler) handleDeleteTaskTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := decodeIDFromCtx(ctx, "id")
+	if err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+
+	if err := h.TaskTemplateService.DeleteTaskTemplate(ctx, id); err != nil {
+		h.HandleHTTPError(ctx, err, w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func decodeTaskTemplateUpdate(r *http.Request) (influxdb.ID, influxdb.TaskTemplateUpdate, error) {
+	var upd influxdb.TaskTemplateUpdate
+
+	id, err := decodeIDFromCtx(r.Context(), "id")
+	if err != nil {
+		return 0, upd, err
+	}
+	if err := json.NewDecoder(r.Body).Decode(&upd); err != nil {
+		return 0, upd, &influxdb.Error{
+			Err:  err,
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode request",
+		}
+	}
+	return id, upd, nil
+}
+
+// TaskTemplateService connects to Influx via HTTP using tokens to manage task templates.
+type TaskTemplateService struct {
+	Client *httpc.Client
+}
+
+var _ influxdb.TaskTemplateService = (*TaskTemplateService)(nil)
+
+// FindTaskTemplateByID returns a single task template.
+func (s *TaskTemplateService) FindTaskTemplateByID(ctx context.Context, id influxdb.ID) (*influxdb.TaskTemplate, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var tr taskTemplateResponse
+	err := s.Client.
+		Get(prefixTaskTemplates, id.String()).
+		DecodeJSON(&tr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tr.TaskTemplate, nil
+}
+
+// FindTaskTemplates returns the task templates that match a filter and their count.
+func (s *TaskTemplateService) FindTaskTemplates(ctx context.Context, filter influxdb.TaskTemplateFilter) ([]*influxdb.TaskTemplate, int, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var params [][2]string
+	if filter.OrganizationID != nil {
+		params = append(params, [2]string{"orgID", filter.OrganizationID.String()})
+	}
+	if filter.Name != nil {
+		params = append(params, [2]string{"name", *filter.Name})
+	}
+
+	var res taskTemplatesResponse
+	err := s.Client.
+		Get(prefixTaskTemplates).
+		QueryParams(params...).
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ts := make([]*influxdb.TaskTemplate, 0, len(res.TaskTemplates))
+	for i := range res.TaskTemplates {
+		ts = append(ts, &res.TaskTemplates[i].TaskTemplate)
+	}
+	return ts, len(ts), nil
+}
+
+// CreateTaskTemplate creates a new task template and sets t.ID with the new identifier.
+func (s *TaskTemplateService) CreateTaskTemplate(ctx context.Context, t *influxdb.TaskTemplate) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var tr taskTemplateResponse
+	err := s.Client.
+		PostJSON(t, prefixTaskTemplates).
+		DecodeJSON(&tr).
+		Do(ctx)
+	if err != nil {
+		return err
+	}
+	*t = tr.TaskTemplate
+	return nil
+}
+
+// UpdateTaskTemplate updates a task template and renders the tasks
+// instantiated from it again.
+func (s *TaskTemplateService) UpdateTaskTemplate(ctx context.Context, id influxdb.ID, upd influxdb.TaskTemplateUpdate) (*influxdb.TaskTemplate, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var tr taskTemplateResponse
+	err := s.Client.
+		PatchJSON(upd, prefixTaskTemplates, id.String()).
+		DecodeJSON(&tr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tr.TaskTemplate, nil
+}
+
+// PreviewTaskTemplateUpdate returns how an update of a task template would
+// change the tasks instantiated from it.
+func (s *TaskTemplateService) PreviewTaskTemplateUpdate(ctx context.Context, id influxdb.ID, upd influxdb.TaskTemplateUpdate) ([]*influxdb.TaskTemplateDiff, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	var res taskTemplateDiffsResponse
+	err := s.Client.
+		PostJSON(upd, prefixTaskTemplates, id.String(), "preview").
+		DecodeJSON(&res).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return res.Diffs, nil
+}
+
+// DeleteTaskTemplate removes a task template.
+func (s *TaskTemplateService) DeleteTaskTemplate(ctx context.Context, id influxdb.ID) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.Client.
+		Delete(prefixTaskTemplates, id.String()).
+		Do(ctx)
+}
@@ -244,10 +244,20 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if pset, err := a.PermissionSet(); err != nil || !pset.Allowed(*p) {
+	pset, err := a.PermissionSet()
+	if err != nil {
 		handleError(nil, influxdb.EForbidden, "insufficient permissions for write")
 		return
 	}
+	// a permission to write the points of the bucket that match a predicate
+	// requires every point to match one
+	var preds []influxdb.ResourcePredicate
+	if !pset.Allowed(*p) {
+		if preds = pset.BucketPredicates(influxdb.WriteAction, org.ID, bucket.ID); len(preds) == 0 {
+			handleError(nil, influxdb.EForbidden, "insufficient permissions for write")
+			return
+		}
+	}
 
 	data, err := readWriteRequest(ctx, r.Body, r.Header.Get("Content-Encoding"), h.maxBatchSizeBytes)
 	if err != nil {
@@ -301,7 +311,19 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.PointsWriter.WritePoints(ctx, points); err != nil {
+	if len(preds) > 0 {
+		for _, pt := range points {
+			if !pointMatchesAny(pt, preds) {
+				handleError(nil, influxdb.EForbidden, "insufficient permissions for write of points not matching the predicates of the permissions")
+				return
+			}
+		}
+	}
+
+	if err := h.PointsWriter.WritePoints(ctx, points); err == storage.ErrReadOnly {
+		handleError(err, influxdb.EForbidden, "cannot write to a read-only replication follower")
+		return
+	} else if err != nil {
 		log.Error("Error writing points", zap.Error(err))
 		handleError(err, influxdb.EInternal, "unexpected error writing points to database")
 		return
@@ -310,6 +332,21 @@ func (h *WriteHandler) handleWrite(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// pointMatchesAny returns whether the point matches any of the predicates.
+func pointMatchesAny(pt models.Point, preds []influxdb.ResourcePredicate) bool {
+	tags := pt.Tags()
+	measurement := string(tags.Get(models.MeasurementTagKeyBytes))
+	tagValue := func(key string) string {
+		return string(tags.Get([]byte(key)))
+	}
+	for _, pred := range preds {
+		if pred.MatchesPoint(measurement, tagValue) {
+			return true
+		}
+	}
+	return false
+}
+
 func decodeWriteRequest(ctx context.Context, r *http.Request) (*postWriteRequest, error) {
 	qp := r.URL.Query()
 	p := qp.Get("precision")
@@ -241,6 +241,56 @@ func TestWriteHandler_handleWrite(t *testing.T) {
 				body: `{"code":"forbidden","message":"insufficient permissions for write"}`,
 			},
 		},
+		{
+			name: "points matching the predicate of the permission are accepted",
+			request: request{
+				org:    "043e0780ee2b1000",
+				bucket: "04504b356e23b000",
+				body:   "m1,customer=a f1=1\nm1,customer=a,t1=v1 f1=2",
+				auth:   bucketPredicateWritePermission("043e0780ee2b1000", "04504b356e23b000", "a"),
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 204,
+			},
+		},
+		{
+			name: "forbidden to write points not matching the predicate of the permission",
+			request: request{
+				org:    "043e0780ee2b1000",
+				bucket: "04504b356e23b000",
+				body:   "m1,customer=a f1=1\nm1,customer=b f1=1",
+				auth:   bucketPredicateWritePermission("043e0780ee2b1000", "04504b356e23b000", "a"),
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 403,
+				body: `{"code":"forbidden","message":"insufficient permissions for write of points not matching the predicates of the permissions"}`,
+			},
+		},
+		{
+			name: "forbidden to write points without the tag of the predicate of the permission",
+			request: request{
+				org:    "043e0780ee2b1000",
+				bucket: "04504b356e23b000",
+				body:   "m1,t1=v1 f1=1",
+				auth:   bucketPredicateWritePermission("043e0780ee2b1000", "04504b356e23b000", "a"),
+			},
+			state: state{
+				org:    testOrg("043e0780ee2b1000"),
+				bucket: testBucket("043e0780ee2b1000", "04504b356e23b000"),
+			},
+			wants: wants{
+				code: 403,
+				body: `{"code":"forbidden","message":"insufficient permissions for write of points not matching the predicates of the permissions"}`,
+			},
+		},
 		{
 			// authorization extraction happens in a different middleware.
 			name: "no authorizer is an internal error",
@@ -397,6 +447,14 @@ func bucketWritePermission(org, bucket string) *influxdb.Authorization {
 	}
 }
 
+func bucketPredicateWritePermission(org, bucket, customer string) *influxdb.Authorization {
+	a := bucketWritePermission(org, bucket)
+	a.Permissions[0].Resource.Predicate = &influxdb.ResourcePredicate{
+		Tags: []influxdb.Tag{{Key: "customer", Value: customer}},
+	}
+	return a
+}
+
 func testOrg(org string) *influxdb.Organization {
 	oid := influxtesting.MustIDBase16(org)
 	return &influxdb.Organization{
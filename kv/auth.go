@@ -2,7 +2,6 @@ package kv
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/buger/jsonparser"
@@ -106,27 +105,18 @@ func (s *Service) FindAuthorizationByToken(ctx context.Context, n string) (*infl
 }
 
 func (s *Service) findAuthorizationByToken(ctx context.Context, tx Tx, n string) (*influxdb.Authorization, error) {
-	idx, err := authIndexBucket(tx)
+	id, err := FindAuthorizationIDByToken(tx, n)
 	if err != nil {
 		return nil, err
 	}
 
-	a, err := idx.Get(authIndexKey(n))
-	if IsNotFound(err) {
-		return nil, &influxdb.Error{
-			Code: influxdb.ENotFound,
-			Msg:  "authorization not found",
-		}
-	}
-
-	var id influxdb.ID
-	if err := id.Decode(a); err != nil {
-		return nil, &influxdb.Error{
-			Code: influxdb.EInvalid,
-			Err:  err,
-		}
+	a, err := s.findAuthorizationByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
 	}
-	return s.findAuthorizationByID(ctx, tx, id)
+	// only the hash of the token is stored, but the caller knows the token
+	a.Token = n
+	return a, nil
 }
 
 func authorizationsPredicateFn(f influxdb.AuthorizationFilter) CursorPredicateFunc {
@@ -145,10 +135,10 @@ func authorizationsPredicateFn(f influxdb.AuthorizationFilter) CursorPredicateFu
 	}
 
 	if f.Token != nil {
-		exp := *f.Token
+		exp := AuthTokenPrefix(*f.Token)
 		return func(_, value []byte) bool {
-			// it is assumed that token never has escaped string data
-			got, _, _, err := jsonparser.Get(value, "token")
+			// it is assumed that token prefix never has escaped string data
+			got, _, _, err := jsonparser.Get(value, "tokenPrefix")
 			if err != nil {
 				return true
 			}
@@ -183,6 +173,15 @@ func authorizationsPredicateFn(f influxdb.AuthorizationFilter) CursorPredicateFu
 }
 
 func filterAuthorizationsFn(filter influxdb.AuthorizationFilter) func(a *influxdb.Authorization) bool {
+	if filter.ExpiresBefore != nil {
+		before := *filter.ExpiresBefore
+		filter.ExpiresBefore = nil
+		fn := filterAuthorizationsFn(filter)
+		return func(a *influxdb.Authorization) bool {
+			return a.ExpiresBefore(before) && fn(a)
+		}
+	}
+
 	if filter.ID != nil {
 		return func(a *influxdb.Authorization) bool {
 			return a.ID == *filter.ID
@@ -191,7 +190,7 @@ func filterAuthorizationsFn(filter influxdb.AuthorizationFilter) func(a *influxd
 
 	if filter.Token != nil {
 		return func(a *influxdb.Authorization) bool {
-			return a.Token == *filter.Token
+			return CompareAuthToken(a.TokenHash, *filter.Token)
 		}
 	}
 
@@ -323,11 +322,11 @@ func (s *Service) createAuthorization(ctx context.Context, tx Tx, a *influxdb.Au
 		return influxdb.ErrUnableToCreateToken
 	}
 
-	if err := s.uniqueAuthToken(ctx, tx, a); err != nil {
-		return err
-	}
-
-	if a.Token == "" {
+	if a.Token != "" {
+		if err := s.uniqueAuthToken(ctx, tx, a); err != nil {
+			return err
+		}
+	} else {
 		token, err := s.TokenGenerator.Token()
 		if err != nil {
 			return &influxdb.Error{
@@ -357,6 +356,7 @@ func (s *Service) PutAuthorization(ctx context.Context, a *influxdb.Authorizatio
 	})
 }
 
+// encodeAuthorization returns the stored form of a, with the hash of its token.
 func encodeAuthorization(a *influxdb.Authorization) ([]byte, error) {
 	switch a.Status {
 	case influxdb.Active, influxdb.Inactive:
@@ -369,10 +369,17 @@ func encodeAuthorization(a *influxdb.Authorization) ([]byte, error) {
 		}
 	}
 
-	return json.Marshal(a)
+	if err := HashAuthorizationToken(a); err != nil {
+		return nil, err
+	}
+	return MarshalAuthorization(a)
 }
 
 func (s *Service) putAuthorization(ctx context.Context, tx Tx, a *influxdb.Authorization) error {
+	return putAuthorization(tx, a)
+}
+
+func putAuthorization(tx Tx, a *influxdb.Authorization) error {
 	v, err := encodeAuthorization(a)
 	if err != nil {
 		return &influxdb.Error{
@@ -394,7 +401,14 @@ func (s *Service) putAuthorization(ctx context.Context, tx Tx, a *influxdb.Autho
 		return err
 	}
 
-	if err := idx.Put(authIndexKey(a.Token), encodedID); err != nil {
+	key, err := AuthTokenIndexKey(a.TokenPrefix, a.ID)
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Err:  err,
+		}
+	}
+	if err := idx.Put(key, encodedID); err != nil {
 		return &influxdb.Error{
 			Code: influxdb.EInternal,
 			Err:  err,
@@ -415,12 +429,8 @@ func (s *Service) putAuthorization(ctx context.Context, tx Tx, a *influxdb.Autho
 	return nil
 }
 
-func authIndexKey(n string) []byte {
-	return []byte(n)
-}
-
 func decodeAuthorization(b []byte, a *influxdb.Authorization) error {
-	if err := json.Unmarshal(b, a); err != nil {
+	if err := UnmarshalAuthorization(b, a); err != nil {
 		return err
 	}
 	if a.Status == "" {
@@ -481,7 +491,13 @@ func (s *Service) deleteAuthorization(ctx context.Context, tx Tx, id influxdb.ID
 		return err
 	}
 
-	if err := idx.Delete(authIndexKey(a.Token)); err != nil {
+	key, err := AuthTokenIndexKey(a.TokenPrefix, id)
+	if err != nil {
+		return &influxdb.Error{
+			Err: err,
+		}
+	}
+	if err := idx.Delete(key); err != nil {
 		return &influxdb.Error{
 			Err: err,
 		}
@@ -559,12 +575,15 @@ func UnexpectedAuthIndexError(err error) *influxdb.Error {
 }
 
 func (s *Service) uniqueAuthToken(ctx context.Context, tx Tx, a *influxdb.Authorization) error {
-	err := s.unique(ctx, tx, authIndex, authIndexKey(a.Token))
-	if err == NotUniqueError {
+	_, err := FindAuthorizationIDByToken(tx, a.Token)
+	if err == nil {
 		// by returning a generic error we are trying to hide when
 		// a token is non-unique.
 		return influxdb.ErrUnableToCreateToken
 	}
+	if influxdb.ErrorCode(err) == influxdb.ENotFound {
+		return nil
+	}
 	// otherwise, this is some sort of internal server error and we
 	// should provide some debugging information.
 	return err
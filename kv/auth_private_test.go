@@ -44,14 +44,14 @@ func Test_authorizationsPredicateFn(t *testing.T) {
 		fn := authorizationsPredicateFn(f)
 
 		t.Run("does match", func(t *testing.T) {
-			a := &influxdb.Authorization{ID: 10, OrgID: 2, Token: val}
+			a := &influxdb.Authorization{ID: 10, OrgID: 2, TokenPrefix: AuthTokenPrefix(val)}
 			if got, exp := fn(nil, mustMarshal(t, a)), true; got != exp {
 				t.Errorf("unexpected result -got/+exp\n%s", cmp.Diff(got, exp))
 			}
 		})
 
 		t.Run("does not match", func(t *testing.T) {
-			a := &influxdb.Authorization{ID: 10, OrgID: 2, Token: "no_no_no"}
+			a := &influxdb.Authorization{ID: 10, OrgID: 2, TokenPrefix: AuthTokenPrefix("no_no_no")}
 			if got, exp := fn(nil, mustMarshal(t, a)), false; got != exp {
 				t.Errorf("unexpected result -got/+exp\n%s", cmp.Diff(got, exp))
 			}
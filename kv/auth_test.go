@@ -1,10 +1,12 @@
 package kv_test
 
 import (
+	"bytes"
 	"context"
 	"testing"
 
 	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
 	"github.com/influxdata/influxdb/v2/kv"
 	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
 	"go.uber.org/zap/zaptest"
@@ -76,3 +78,89 @@ func initAuthorizationService(s kv.Store, f influxdbtesting.AuthorizationFields,
 		}
 	}
 }
+
+func TestService_HashAuthTokens(t *testing.T) {
+	ctx := context.Background()
+	store := inmem.NewKVStore()
+	svc := kv.NewService(zaptest.NewLogger(t), store)
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// an authorization stored, and indexed, by its token before tokens were hashed
+	const token = "legacy-plaintext-token"
+	id := influxdb.ID(1)
+	encodedID, _ := id.Encode()
+	if err := store.Update(ctx, func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("authorizationsv1"))
+		if err != nil {
+			return err
+		}
+		if err := b.Put(encodedID, []byte(`{"id":"0000000000000001","token":"`+token+`","status":"active","orgID":"0000000000000002","permissions":[]}`)); err != nil {
+			return err
+		}
+		idx, err := tx.Bucket([]byte("authorizationindexv1"))
+		if err != nil {
+			return err
+		}
+		return idx.Put([]byte(token), encodedID)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var migration kv.MigrationSpec
+	for _, m := range svc.Migrator.MigrationSpecs {
+		if m.MigrationName() == "hash authorization tokens" {
+			migration = m
+		}
+	}
+	if migration == nil {
+		t.Fatal("expected a migration that hashes authorization tokens")
+	}
+	if err := migration.Up(ctx, store); err != nil {
+		t.Fatal(err)
+	}
+
+	// the token is no longer stored anywhere
+	if err := store.View(ctx, func(tx kv.Tx) error {
+		for _, bucket := range []string{"authorizationsv1", "authorizationindexv1"} {
+			b, err := tx.Bucket([]byte(bucket))
+			if err != nil {
+				return err
+			}
+			cur, err := b.ForwardCursor(nil)
+			if err != nil {
+				return err
+			}
+			for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+				if bytes.Contains(k, []byte(token)) || bytes.Contains(v, []byte(token)) {
+					t.Errorf("expected the token not to be stored in %s, got %s: %s", bucket, k, v)
+				}
+			}
+			cur.Close()
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := svc.FindAuthorizationByToken(ctx, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.ID != id {
+		t.Fatalf("expected authorization %s to be found by its token, got %s", id, a.ID)
+	}
+
+	a, err = svc.FindAuthorizationByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Token != "" || a.TokenPrefix != kv.AuthTokenPrefix(token) {
+		t.Fatalf("expected only the prefix of the token, got token %q and prefix %q", a.Token, a.TokenPrefix)
+	}
+
+	if _, err := svc.FindAuthorizationByToken(ctx, "legacy-plaintext-other"); influxdb.ErrorCode(err) != influxdb.ENotFound {
+		t.Fatalf("expected a token with the same prefix not to be found, got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package kv
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	influxdb "github.com/influxdata/influxdb/v2"
+)
+
+const (
+	// authTokenPrefixLen is the number of characters of a token kept as its prefix.
+	authTokenPrefixLen = 8
+
+	authTokenSaltLen  = 16
+	authTokenHashAlgo = "sha256"
+)
+
+var errAuthorizationNotFound = &influxdb.Error{
+	Code: influxdb.ENotFound,
+	Msg:  "authorization not found",
+}
+
+// AuthTokenPrefix returns the prefix of token, by which the authorization of
+// the token is indexed. It is not secret.
+func AuthTokenPrefix(token string) string {
+	if len(token) > authTokenPrefixLen {
+		return token[:authTokenPrefixLen]
+	}
+	return token
+}
+
+// HashAuthToken returns a salted hash of token, which is stored in place of
+// the token.
+func HashAuthToken(token string) (string, error) {
+	salt := make([]byte, authTokenSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return authTokenHashAlgo + ":" + hex.EncodeToString(salt) + ":" + hex.EncodeToString(sumAuthToken(salt, token)), nil
+}
+
+// CompareAuthToken reports whether hash is the hash of token.
+func CompareAuthToken(hash, token string) bool {
+	parts := strings.Split(hash, ":")
+	if len(parts) != 3 || parts[0] != authTokenHashAlgo {
+		return false
+	}
+	salt, err := hex.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	sum, err := hex.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(sum, sumAuthToken(salt, token)) == 1
+}
+
+func sumAuthToken(salt []byte, token string) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(token))
+	return h.Sum(nil)
+}
+
+// HashAuthorizationToken replaces the token of a with its salted hash and
+// prefix, unless it is hashed already. The token of a is kept, so that it can
+// be returned to whoever created the authorization.
+func HashAuthorizationToken(a *influxdb.Authorization) error {
+	if a.Token == "" || (a.TokenHash != "" && CompareAuthToken(a.TokenHash, a.Token)) {
+		return nil
+	}
+	hash, err := HashAuthToken(a.Token)
+	if err != nil {
+		return err
+	}
+	a.TokenHash = hash
+	a.TokenPrefix = AuthTokenPrefix(a.Token)
+	return nil
+}
+
+// AuthTokenIndexKey returns the key of the authorization id, with a token
+// with the prefix, in the authorization index.
+func AuthTokenIndexKey(prefix string, id influxdb.ID) ([]byte, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(prefix), encodedID...), nil
+}
+
+// storedAuthorization is the stored form of an authorization, which holds the
+// hash of its token rather than the token.
+type storedAuthorization struct {
+	*influxdb.Authorization
+
+	// Token shadows the token of the authorization, so that it is not stored.
+	Token     string `json:"token,omitempty"`
+	TokenHash string `json:"tokenHash,omitempty"`
+}
+
+// MarshalAuthorization returns the stored form of a, which must have its
+// token hashed.
+func MarshalAuthorization(a *influxdb.Authorization) ([]byte, error) {
+	return json.Marshal(storedAuthorization{Authorization: a, TokenHash: a.TokenHash})
+}
+
+// UnmarshalAuthorization decodes the stored form of an authorization into a.
+// The token of a is left empty.
+func UnmarshalAuthorization(b []byte, a *influxdb.Authorization) error {
+	s := storedAuthorization{Authorization: a}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	a.TokenHash = s.TokenHash
+	return nil
+}
+
+// FindAuthorizationIDByToken looks up the authorization of token in the index
+// of the prefixes of tokens, and returns its ID if the hash of its token
+// matches token.
+func FindAuthorizationIDByToken(tx Tx, token string) (influxdb.ID, error) {
+	if token == "" {
+		return 0, errAuthorizationNotFound
+	}
+
+	idx, err := authIndexBucket(tx)
+	if err != nil {
+		return 0, err
+	}
+	b, err := tx.Bucket(authBucket)
+	if err != nil {
+		return 0, err
+	}
+
+	prefix := []byte(AuthTokenPrefix(token))
+	cur, err := idx.ForwardCursor(prefix, WithCursorPrefix(prefix))
+	if err != nil {
+		return 0, err
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		stored, err := b.Get(v)
+		if IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		a := &influxdb.Authorization{}
+		if err := UnmarshalAuthorization(stored, a); err != nil {
+			return 0, err
+		}
+		if CompareAuthToken(a.TokenHash, token) {
+			return a.ID, nil
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return 0, err
+	}
+
+	return 0, errAuthorizationNotFound
+}
+
+// hashAuthTokens is the migration that replaces the token of every
+// authorization with its salted hash, and re-indexes the authorizations by
+// the prefixes of their tokens.
+func hashAuthTokens(ctx context.Context, store Store) error {
+	return store.Update(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(authBucket)
+		if err != nil {
+			return err
+		}
+		idx, err := authIndexBucket(tx)
+		if err != nil {
+			return err
+		}
+
+		type legacyAuthorization struct {
+			Token string `json:"token"`
+		}
+		var (
+			auths  []*influxdb.Authorization
+			tokens []string
+		)
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			var legacy legacyAuthorization
+			if err := json.Unmarshal(v, &legacy); err != nil {
+				return err
+			}
+			if legacy.Token == "" {
+				continue
+			}
+			a := &influxdb.Authorization{}
+			if err := decodeAuthorization(v, a); err != nil {
+				return err
+			}
+			a.Token = legacy.Token
+			auths = append(auths, a)
+			tokens = append(tokens, legacy.Token)
+		}
+		if err := cur.Err(); err != nil {
+			return err
+		}
+		if err := cur.Close(); err != nil {
+			return err
+		}
+
+		for i, a := range auths {
+			// the legacy index is keyed by the token itself
+			if err := idx.Delete([]byte(tokens[i])); err != nil {
+				return err
+			}
+			if err := HashAuthorizationToken(a); err != nil {
+				return err
+			}
+			if err := putAuthorization(tx, a); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -687,6 +687,14 @@ func (s *Service) updateBucket(ctx context.Context, tx Tx, id influxdb.ID, upd i
 		b.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.StorageSettings != nil {
+		b.StorageSettings = nil
+		if !upd.StorageSettings.IsZero() {
+			settings := *upd.StorageSettings
+			b.StorageSettings = &settings
+		}
+	}
+
 	if upd.Description != nil {
 		b.Description = *upd.Description
 	}
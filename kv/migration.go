@@ -17,6 +17,14 @@ var (
 	// ErrMigrationSpecNotFound is returned when a migration specification is missing
 	// for an already applied migration.
 	ErrMigrationSpecNotFound = errors.New("migration specification not found")
+
+	// ErrMigrationNotFound is returned when a migration to migrate up or down to
+	// is not specified.
+	ErrMigrationNotFound = errors.New("migration not found")
+
+	// ErrMigrationIrreversible is returned when an irreversible migration is
+	// to be undone.
+	ErrMigrationIrreversible = errors.New("migration cannot be undone")
 )
 
 // MigrationState is a type for describing the state of a migration.
@@ -83,6 +91,30 @@ func (a AnonymousMigration) Up(ctx context.Context, store Store) error { return
 // Down calls the underlying down migration func.
 func (a AnonymousMigration) Down(ctx context.Context, store Store) error { return a.down(ctx, store) }
 
+// IrreversibleMigration is a migration which cannot be undone, such as one that
+// discards data. The migrator does not undo any migration when asked to undo
+// one of them.
+type IrreversibleMigration struct {
+	name string
+	up   MigrationFunc
+}
+
+// NewIrreversibleMigration constructs a new migration from a name string and an up function.
+func NewIrreversibleMigration(name string, up MigrationFunc) IrreversibleMigration {
+	return IrreversibleMigration{name, up}
+}
+
+// Name returns the name of the migration.
+func (a IrreversibleMigration) MigrationName() string { return a.name }
+
+// Up calls the underlying up migration func.
+func (a IrreversibleMigration) Up(ctx context.Context, store Store) error { return a.up(ctx, store) }
+
+// Down returns ErrMigrationIrreversible.
+func (a IrreversibleMigration) Down(ctx context.Context, store Store) error {
+	return fmt.Errorf("migration %q: %w", a.name, ErrMigrationIrreversible)
+}
+
 // Migrator is a type which manages migrations.
 // It takes a list of migration specifications and undo (down) all or apply (up) outstanding migrations.
 // It records the state of the world in store under the migrations bucket.
@@ -149,6 +181,13 @@ func (m *Migrator) List(ctx context.Context, store Store) (migrations []Migratio
 //
 // Up would apply migration 0002 and then 0003.
 func (m *Migrator) Up(ctx context.Context, store Store) error {
+	return m.UpTo(ctx, store, "")
+}
+
+// UpTo applies each outstanding migration in order, like Up, up to and
+// including the migration named to. All outstanding migrations are applied
+// if to is empty.
+func (m *Migrator) UpTo(ctx context.Context, store Store, to string) error {
 	wrapErr := func(err error) error {
 		if err == nil {
 			return nil
@@ -157,23 +196,16 @@ func (m *Migrator) Up(ctx context.Context, store Store) error {
 		return fmt.Errorf("up: %w", err)
 	}
 
-	var lastMigration int
-	if err := m.walk(ctx, store, func(id influxdb.ID, mig Migration) {
-		// we're interested in the last up migration
-		if mig.State == UpMigrationState {
-			lastMigration = int(id)
-		}
-	}); err != nil {
+	migrations, err := m.PlanUp(ctx, store, to)
+	if err != nil {
 		return wrapErr(err)
 	}
 
-	for idx, spec := range m.MigrationSpecs[lastMigration:] {
+	for _, migration := range migrations {
+		spec := m.MigrationSpecs[int(migration.ID)-1]
+
 		startedAt := m.now()
-		migration := Migration{
-			ID:        influxdb.ID(lastMigration + idx + 1),
-			Name:      spec.MigrationName(),
-			StartedAt: &startedAt,
-		}
+		migration.StartedAt = &startedAt
 
 		m.logMigrationEvent(UpMigrationState, migration, "started")
 
@@ -199,6 +231,35 @@ func (m *Migrator) Up(ctx context.Context, store Store) error {
 	return nil
 }
 
+// PlanUp returns the migrations UpTo would apply, in the order it would apply
+// them, without applying them.
+func (m *Migrator) PlanUp(ctx context.Context, store Store, to string) ([]Migration, error) {
+	until, err := m.indexOf(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastMigration int
+	if err := m.walk(ctx, store, func(id influxdb.ID, mig Migration) {
+		// we're interested in the last up migration
+		if mig.State == UpMigrationState {
+			lastMigration = int(id)
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for idx := lastMigration; idx < until; idx++ {
+		migrations = append(migrations, Migration{
+			ID:   influxdb.ID(idx + 1),
+			Name: m.MigrationSpecs[idx].MigrationName(),
+		})
+	}
+
+	return migrations, nil
+}
+
 // Down applies the down operation of each currently applied migration.
 // Migrations are applied in reverse order from the highest indexed migration in a down state.
 //
@@ -208,7 +269,14 @@ func (m *Migrator) Up(ctx context.Context, store Store) error {
 // 0003 add index "foo on baz" | (down)
 //
 // Down would call down() on 0002 and then on 0001.
-func (m *Migrator) Down(ctx context.Context, store Store) (err error) {
+func (m *Migrator) Down(ctx context.Context, store Store) error {
+	return m.DownTo(ctx, store, "")
+}
+
+// DownTo applies the down operation of each currently applied migration in
+// reverse order, like Down, down to but excluding the migration named to,
+// which remains applied. All migrations are undone if to is empty.
+func (m *Migrator) DownTo(ctx context.Context, store Store, to string) error {
 	wrapErr := func(err error) error {
 		if err == nil {
 			return nil
@@ -217,45 +285,82 @@ func (m *Migrator) Down(ctx context.Context, store Store) (err error) {
 		return fmt.Errorf("down: %w", err)
 	}
 
-	var migrations []struct {
-		MigrationSpec
-		Migration
-	}
-
-	if err := m.walk(ctx, store, func(id influxdb.ID, mig Migration) {
-		migrations = append(
-			migrations,
-			struct {
-				MigrationSpec
-				Migration
-			}{
-				m.MigrationSpecs[int(id)-1],
-				mig,
-			},
-		)
-	}); err != nil {
+	migrations, err := m.PlanDown(ctx, store, to)
+	if err != nil {
 		return wrapErr(err)
 	}
 
-	for i := len(migrations) - 1; i >= 0; i-- {
-		migration := migrations[i]
+	for _, migration := range migrations {
+		spec := m.MigrationSpecs[int(migration.ID)-1]
 
-		m.logMigrationEvent(DownMigrationState, migration.Migration, "started")
+		m.logMigrationEvent(DownMigrationState, migration, "started")
 
-		if err := migration.MigrationSpec.Down(ctx, store); err != nil {
+		if err := spec.Down(ctx, store); err != nil {
 			return wrapErr(err)
 		}
 
-		if err := m.deleteMigration(ctx, store, migration.Migration); err != nil {
+		if err := m.deleteMigration(ctx, store, migration); err != nil {
 			return wrapErr(err)
 		}
 
-		m.logMigrationEvent(DownMigrationState, migration.Migration, "completed")
+		m.logMigrationEvent(DownMigrationState, migration, "completed")
 	}
 
 	return nil
 }
 
+// PlanDown returns the migrations DownTo would undo, in the order it would
+// undo them, without undoing them. It returns ErrMigrationIrreversible if one
+// of them cannot be undone, so that none of them is.
+func (m *Migrator) PlanDown(ctx context.Context, store Store, to string) ([]Migration, error) {
+	var keep int
+	if to != "" {
+		idx, err := m.indexOf(to)
+		if err != nil {
+			return nil, err
+		}
+		keep = idx
+	}
+
+	var migrations []Migration
+	if err := m.walk(ctx, store, func(id influxdb.ID, mig Migration) {
+		if int(id) > keep {
+			migrations = append(migrations, mig)
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, mig := range migrations {
+		if _, ok := m.MigrationSpecs[int(mig.ID)-1].(IrreversibleMigration); ok {
+			return nil, fmt.Errorf("migration %q: %w", mig.Name, ErrMigrationIrreversible)
+		}
+	}
+
+	// undo the most recent migration first
+	for i, j := 0, len(migrations)-1; i < j; i, j = i+1, j-1 {
+		migrations[i], migrations[j] = migrations[j], migrations[i]
+	}
+
+	return migrations, nil
+}
+
+// indexOf returns the number of migrations up to and including the migration
+// named name, or the number of all migrations if name is empty.
+func (m *Migrator) indexOf(name string) (int, error) {
+	if name == "" {
+		return len(m.MigrationSpecs), nil
+	}
+
+	for idx, spec := range m.MigrationSpecs {
+		if spec.MigrationName() == name {
+			return idx + 1, nil
+		}
+	}
+
+	return 0, fmt.Errorf("migration %q: %w", name, ErrMigrationNotFound)
+}
+
 func (m *Migrator) logMigrationEvent(state MigrationState, mig Migration, event string) {
 	m.logger.Info(fmt.Sprintf("Migration %q %s (%s)", mig.Name, event, state))
 }
@@ -64,7 +64,7 @@ func (s *Service) loadSecret(ctx context.Context, tx Tx, orgID influxdb.ID, k st
 		return "", err
 	}
 
-	v, err := decodeSecretValue(val)
+	v, err := s.decodeSecretValue(key, val)
 	if err != nil {
 		return "", err
 	}
@@ -141,7 +141,10 @@ func (s *Service) putSecret(ctx context.Context, tx Tx, orgID influxdb.ID, k, v
 		return err
 	}
 
-	val := encodeSecretValue(v)
+	val, err := s.encodeSecretValue(key, v)
+	if err != nil {
+		return err
+	}
 
 	b, err := tx.Bucket(secretBucket)
 	if err != nil {
@@ -184,8 +187,12 @@ func decodeSecretKey(key []byte) (influxdb.ID, string, error) {
 	return id, k, nil
 }
 
-func decodeSecretValue(val []byte) (string, error) {
-	// store the secret value base64 encoded so that it's marginally better than plaintext
+func (s *Service) decodeSecretValue(key, val []byte) (string, error) {
+	if isEncryptedSecretValue(val) {
+		return decryptSecretValue(s.Config.SecretMasterKeys, key, val)
+	}
+
+	// secrets stored without a master key are base64 encoded so that it's marginally better than plaintext
 	v, err := base64.StdEncoding.DecodeString(string(val))
 	if err != nil {
 		return "", err
@@ -194,10 +201,14 @@ func decodeSecretValue(val []byte) (string, error) {
 	return string(v), nil
 }
 
-func encodeSecretValue(v string) []byte {
+func (s *Service) encodeSecretValue(key []byte, v string) ([]byte, error) {
+	if len(s.Config.SecretMasterKeys) > 0 {
+		return encryptSecretValue(s.Config.SecretMasterKeys[0], key, v)
+	}
+
 	val := make([]byte, base64.StdEncoding.EncodedLen(len(v)))
 	base64.StdEncoding.Encode(val, []byte(v))
-	return val
+	return val, nil
 }
 
 // PutSecrets puts all provided secrets and overwrites any previous values.
@@ -0,0 +1,243 @@
+package kv
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	// SecretMasterKeyLen is the length in bytes of a secret master key.
+	SecretMasterKeyLen = 32
+
+	secretCipherAlgo     = "aes256gcm"
+	secretMasterKeyIDLen = 8
+)
+
+var secretCipherPrefix = []byte(secretCipherAlgo + ":")
+
+// ParseSecretMasterKey decodes a base64 encoded secret master key.
+func ParseSecretMasterKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("secret master key is not base64 encoded: %v", err)
+	}
+	if len(key) != SecretMasterKeyLen {
+		return nil, fmt.Errorf("secret master key must be %d bytes long, got %d", SecretMasterKeyLen, len(key))
+	}
+	return key, nil
+}
+
+// secretMasterKeyIDOf returns the identifier of a master key, stored alongside
+// the secrets encrypted with it. It does not reveal the key.
+func secretMasterKeyIDOf(masterKey []byte) string {
+	sum := sha256.Sum256(masterKey)
+	return hex.EncodeToString(sum[:secretMasterKeyIDLen])
+}
+
+func isEncryptedSecretValue(val []byte) bool {
+	return bytes.HasPrefix(val, secretCipherPrefix)
+}
+
+// encryptSecretValue envelope encrypts the secret value v stored at key: v is
+// encrypted with a data key of its own, and the data key is encrypted with
+// masterKey. The key of the secret is authenticated with v, so that a value
+// cannot be moved to another key or organization.
+func encryptSecretValue(masterKey, key []byte, v string) ([]byte, error) {
+	dataKey := make([]byte, SecretMasterKeyLen)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	wrapped, err := sealSecret(masterKey, dataKey, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := sealSecret(dataKey, []byte(v), key)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(strings.Join([]string{
+		secretCipherAlgo,
+		secretMasterKeyIDOf(masterKey),
+		base64.StdEncoding.EncodeToString(wrapped),
+		base64.StdEncoding.EncodeToString(ciphertext),
+	}, ":")), nil
+}
+
+// decryptSecretValue decrypts the secret value val stored at key with the one
+// of masterKeys it was encrypted with.
+func decryptSecretValue(masterKeys [][]byte, key, val []byte) (string, error) {
+	parts := strings.Split(string(val), ":")
+	if len(parts) != 4 || parts[0] != secretCipherAlgo {
+		return "", &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "secret value is malformed",
+		}
+	}
+
+	var masterKey []byte
+	for _, k := range masterKeys {
+		if secretMasterKeyIDOf(k) == parts[1] {
+			masterKey = k
+			break
+		}
+	}
+	if masterKey == nil {
+		return "", &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  fmt.Sprintf("secret is encrypted with master key %s, which is not configured", parts[1]),
+		}
+	}
+
+	wrapped, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := openSecret(masterKey, wrapped, nil)
+	if err != nil {
+		return "", err
+	}
+
+	v, err := openSecret(dataKey, ciphertext, key)
+	if err != nil {
+		return "", err
+	}
+
+	return string(v), nil
+}
+
+// sealSecret encrypts plaintext with AES-GCM and prepends the nonce to the
+// ciphertext.
+func sealSecret(key, plaintext, additional []byte) ([]byte, error) {
+	gcm, err := newSecretGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+// openSecret decrypts ciphertext sealed by sealSecret.
+func openSecret(key, ciphertext, additional []byte) ([]byte, error) {
+	gcm, err := newSecretGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "secret value is malformed",
+		}
+	}
+
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, additional)
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInternal,
+			Msg:  "failed to decrypt secret value",
+			Err:  err,
+		}
+	}
+	return plaintext, nil
+}
+
+func newSecretGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// RotateSecrets encrypts all secrets that are not encrypted with the current
+// secret master key, which are the secrets stored unencrypted or encrypted with
+// a previous master key. It returns the number of secrets encrypted.
+func (s *Service) RotateSecrets(ctx context.Context) (int, error) {
+	if len(s.Config.SecretMasterKeys) == 0 {
+		return 0, nil
+	}
+
+	current := []byte(secretCipherAlgo + ":" + secretMasterKeyIDOf(s.Config.SecretMasterKeys[0]) + ":")
+
+	var n int
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		b, err := tx.Bucket(secretBucket)
+		if err != nil {
+			return err
+		}
+
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+
+		// collect the secrets first, as the bucket must not be modified while
+		// it is iterated.
+		var keys, vals [][]byte
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			if bytes.HasPrefix(v, current) {
+				continue
+			}
+			keys = append(keys, append([]byte(nil), k...))
+			vals = append(vals, append([]byte(nil), v...))
+		}
+		if err := cur.Err(); err != nil {
+			return err
+		}
+		if err := cur.Close(); err != nil {
+			return err
+		}
+
+		for i, k := range keys {
+			v, err := s.decodeSecretValue(k, vals[i])
+			if err != nil {
+				return err
+			}
+
+			val, err := s.encodeSecretValue(k, v)
+			if err != nil {
+				return err
+			}
+
+			if err := b.Put(k, val); err != nil {
+				return err
+			}
+		}
+
+		n = len(keys)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if n > 0 {
+		s.log.Info("Encrypted secrets with the current secret master key", zap.Int("count", n))
+	}
+	return n, nil
+}
@@ -1,7 +1,9 @@
 package kv_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"testing"
 
 	"github.com/influxdata/influxdb/v2"
@@ -27,8 +29,25 @@ func initBoltSecretService(f influxdbtesting.SecretServiceFields, t *testing.T)
 	}
 }
 
-func initSecretService(s kv.Store, f influxdbtesting.SecretServiceFields, t *testing.T) (influxdb.SecretService, func()) {
-	svc := kv.NewService(zaptest.NewLogger(t), s)
+func TestBoltSecretService_Encrypted(t *testing.T) {
+	influxdbtesting.SecretService(func(f influxdbtesting.SecretServiceFields, t *testing.T) (influxdb.SecretService, func()) {
+		s, closeBolt, err := NewTestBoltStore(t)
+		if err != nil {
+			t.Fatalf("failed to create new kv store: %v", err)
+		}
+
+		svc, closeSvc := initSecretService(s, f, t, kv.ServiceConfig{
+			SecretMasterKeys: [][]byte{newSecretMasterKey(t, 1)},
+		})
+		return svc, func() {
+			closeSvc()
+			closeBolt()
+		}
+	}, t)
+}
+
+func initSecretService(s kv.Store, f influxdbtesting.SecretServiceFields, t *testing.T, configs ...kv.ServiceConfig) (influxdb.SecretService, func()) {
+	svc := kv.NewService(zaptest.NewLogger(t), s, configs...)
 	ctx := context.Background()
 	if err := svc.Initialize(ctx); err != nil {
 		t.Fatalf("error initializing secret service: %v", err)
@@ -44,3 +63,126 @@ func initSecretService(s kv.Store, f influxdbtesting.SecretServiceFields, t *tes
 
 	return svc, func() {}
 }
+
+func TestSecretService_Encryption(t *testing.T) {
+	const orgID = influxdb.ID(1)
+	ctx := context.Background()
+	oldKey, newKey := newSecretMasterKey(t, 1), newSecretMasterKey(t, 2)
+
+	store, closeStore, err := NewTestInmemStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeStore()
+
+	newService := func(keys ...[]byte) *kv.Service {
+		t.Helper()
+		svc := kv.NewService(zaptest.NewLogger(t), store, kv.ServiceConfig{SecretMasterKeys: keys})
+		if err := svc.Initialize(ctx); err != nil {
+			t.Fatal(err)
+		}
+		return svc
+	}
+
+	loadSecret := func(svc *kv.Service, k, want string) {
+		t.Helper()
+		got, err := svc.LoadSecret(ctx, orgID, k)
+		if err != nil {
+			t.Fatalf("failed to load secret %s: %v", k, err)
+		}
+		if got != want {
+			t.Errorf("secret %s: got %q, want %q", k, got, want)
+		}
+	}
+
+	rotate := func(svc *kv.Service, want int) {
+		t.Helper()
+		n, err := svc.RotateSecrets(ctx)
+		if err != nil {
+			t.Fatalf("failed to rotate secrets: %v", err)
+		}
+		if n != want {
+			t.Errorf("got %d secrets rotated, want %d", n, want)
+		}
+	}
+
+	// a secret stored before encryption was configured.
+	if err := newService().PutSecret(ctx, orgID, "legacy", "plaintext-one"); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := newService(oldKey)
+	loadSecret(svc, "legacy", "plaintext-one")
+	if err := svc.PutSecret(ctx, orgID, "old", "plaintext-two"); err != nil {
+		t.Fatal(err)
+	}
+	rotate(svc, 1)
+	rotate(svc, 0)
+	assertSecretsEncrypted(t, store, "plaintext-one", "plaintext-two")
+
+	// the previous key decrypts the secrets until they are rotated.
+	svc = newService(newKey, oldKey)
+	loadSecret(svc, "legacy", "plaintext-one")
+	loadSecret(svc, "old", "plaintext-two")
+	rotate(svc, 2)
+
+	svc = newService(newKey)
+	loadSecret(svc, "legacy", "plaintext-one")
+	loadSecret(svc, "old", "plaintext-two")
+
+	for _, svc := range []*kv.Service{newService(), newService(oldKey)} {
+		if _, err := svc.LoadSecret(ctx, orgID, "old"); err == nil {
+			t.Error("expected secret encrypted with an unknown master key to fail to load")
+		}
+	}
+}
+
+func TestParseSecretMasterKey(t *testing.T) {
+	key := newSecretMasterKey(t, 1)
+	got, err := kv.ParseSecretMasterKey(base64.StdEncoding.EncodeToString(key) + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, key) {
+		t.Errorf("got key %x, want %x", got, key)
+	}
+
+	for _, s := range []string{"not base64!", base64.StdEncoding.EncodeToString(key[:16])} {
+		if _, err := kv.ParseSecretMasterKey(s); err == nil {
+			t.Errorf("expected error parsing secret master key %q", s)
+		}
+	}
+}
+
+func newSecretMasterKey(t *testing.T, b byte) []byte {
+	t.Helper()
+	return bytes.Repeat([]byte{b}, kv.SecretMasterKeyLen)
+}
+
+// assertSecretsEncrypted asserts that none of the plaintexts is stored in
+// the secrets bucket, even base64 encoded.
+func assertSecretsEncrypted(t *testing.T, store kv.Store, plaintexts ...string) {
+	t.Helper()
+	err := store.View(context.Background(), func(tx kv.Tx) error {
+		b, err := tx.Bucket([]byte("secretsv1"))
+		if err != nil {
+			return err
+		}
+		cur, err := b.ForwardCursor(nil)
+		if err != nil {
+			return err
+		}
+		defer cur.Close()
+		for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+			for _, p := range plaintexts {
+				if bytes.Contains(v, []byte(p)) || bytes.Contains(v, []byte(base64.StdEncoding.EncodeToString([]byte(p)))) {
+					t.Errorf("secret %q is stored unencrypted: %s", k, v)
+				}
+			}
+		}
+		return cur.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
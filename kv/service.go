@@ -49,8 +49,13 @@ type Service struct {
 	urmByUserIndex *Index
 
 	disableAuthorizationsForMaxPermissions func(context.Context) bool
+	rolePermissions                        RolePermissionsFunc
 }
 
+// RolePermissionsFunc returns the permissions of the roles of the user within
+// tx, which is a transaction on the store of the roles.
+type RolePermissionsFunc func(tx Tx, userID influxdb.ID) ([]influxdb.Permission, error)
+
 // NewService returns an instance of a Service.
 func NewService(log *zap.Logger, kv Store, configs ...ServiceConfig) *Service {
 	s := &Service{
@@ -99,6 +104,12 @@ func NewService(log *zap.Logger, kv Store, configs ...ServiceConfig) *Service {
 		),
 		// add index user resource mappings by user id
 		s.urmByUserIndex.Migration(),
+		// store the salted hashes of authorization tokens in place of the tokens,
+		// which cannot be restored
+		NewIrreversibleMigration(
+			"hash authorization tokens",
+			hashAuthTokens,
+		),
 		// and new migrations below here (and move this comment down):
 	)
 
@@ -122,6 +133,12 @@ func NewService(log *zap.Logger, kv Store, configs ...ServiceConfig) *Service {
 type ServiceConfig struct {
 	SessionLength time.Duration
 	Clock         clock.Clock
+
+	// SecretMasterKeys are the master keys with which secrets are encrypted
+	// at rest. The first key encrypts secrets, the others are previous keys
+	// that are only used to decrypt secrets until they are rotated. Secrets
+	// are stored unencrypted if there are none.
+	SecretMasterKeys [][]byte
 }
 
 // AutoMigrationStore is a Store which also describes whether or not
@@ -274,3 +291,10 @@ func (s *Service) WithSpecialOrgBucketIDs(gen influxdb.IDGenerator) {
 func (s *Service) WithMaxPermissionFunc(fn func(context.Context) bool) {
 	s.disableAuthorizationsForMaxPermissions = fn
 }
+
+// WithRolePermissions sets the function that looks up the permissions of the
+// roles of users, which are added to their max permissions, so that tasks run
+// with the roles of their owners. The roles must be in the store of the service.
+func (s *Service) WithRolePermissions(fn RolePermissionsFunc) {
+	s.rolePermissions = fn
+}
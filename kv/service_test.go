@@ -3,6 +3,7 @@ package kv_test
 import (
 	"context"
 	"io"
+	"reflect"
 	"testing"
 	"time"
 
@@ -39,7 +40,7 @@ func TestNewService(t *testing.T) {
 
 	s = kv.NewService(zaptest.NewLogger(t), mockStore{}, config)
 
-	if s.Config != config {
+	if !reflect.DeepEqual(s.Config, config) {
 		t.Errorf("Service config not set by constructor")
 	}
 }
@@ -0,0 +1,154 @@
+package kv_test
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/sqlkv"
+	influxdbtesting "github.com/influxdata/influxdb/v2/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+// postgresDSNEnv is the environment variable holding the data source name of
+// the PostgreSQL database the tests run against. The tests drop its tables.
+const postgresDSNEnv = "INFLUXDB_TEST_POSTGRES_DSN"
+
+func NewTestSQLiteStore(t *testing.T) (kv.Store, func(), error) {
+	dir, err := ioutil.TempDir("", "influxdata-sqlite-")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := sqlkv.NewKVStore(zaptest.NewLogger(t), sqlkv.SQLite, filepath.Join(dir, "influxd.sqlite"))
+	if err := s.Open(context.Background()); err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+
+	close := func() {
+		s.Close()
+		os.RemoveAll(dir)
+	}
+
+	return s, close, nil
+}
+
+func NewTestPostgresStore(t *testing.T) (kv.Store, func(), error) {
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresDSNEnv)
+	}
+
+	db, err := sql.Open(sqlkv.Postgres, dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer db.Close()
+	if _, err := db.Exec("DROP TABLE IF EXISTS kv_entries, kv_buckets"); err != nil {
+		return nil, nil, err
+	}
+
+	s := sqlkv.NewKVStore(zaptest.NewLogger(t), sqlkv.Postgres, dsn)
+	if err := s.Open(context.Background()); err != nil {
+		return nil, nil, err
+	}
+
+	return s, func() { s.Close() }, nil
+}
+
+// TestSQLStoreServices runs the service test suites against the SQL stores.
+func TestSQLStoreServices(t *testing.T) {
+	stores := []struct {
+		name string
+		new  func(*testing.T) (kv.Store, func(), error)
+	}{
+		{name: "sqlite", new: NewTestSQLiteStore},
+		{name: "postgres", new: NewTestPostgresStore},
+	}
+
+	for _, st := range stores {
+		newStore := func(t *testing.T) (kv.Store, func()) {
+			t.Helper()
+			s, closeStore, err := st.new(t)
+			if err != nil {
+				t.Fatalf("failed to create new kv store: %v", err)
+			}
+			return s, closeStore
+		}
+
+		t.Run(st.name, func(t *testing.T) {
+			t.Run("AuthorizationService", func(t *testing.T) {
+				influxdbtesting.AuthorizationService(func(f influxdbtesting.AuthorizationFields, t *testing.T) (influxdb.AuthorizationService, string, func()) {
+					s, closeStore := newStore(t)
+					svc, op, closeSvc := initAuthorizationService(s, f, t)
+					return svc, op, func() { closeSvc(); closeStore() }
+				}, t)
+			})
+			t.Run("BucketService", func(t *testing.T) {
+				influxdbtesting.BucketService(func(f influxdbtesting.BucketFields, t *testing.T) (influxdb.BucketService, string, func()) {
+					s, closeStore := newStore(t)
+					svc, op, closeSvc := initBucketService(s, f, t)
+					return svc, op, func() { closeSvc(); closeStore() }
+				}, t)
+			})
+			t.Run("DashboardService", func(t *testing.T) {
+				influxdbtesting.DashboardService(func(f influxdbtesting.DashboardFields, t *testing.T) (influxdb.DashboardService, string, func()) {
+					s, closeStore := newStore(t)
+					svc, op, closeSvc := initDashboardService(s, f, t)
+					return svc, op, func() { closeSvc(); closeStore() }
+				}, t)
+			})
+			t.Run("LabelService", func(t *testing.T) {
+				influxdbtesting.LabelService(func(f influxdbtesting.LabelFields, t *testing.T) (influxdb.LabelService, string, func()) {
+					s, closeStore := newStore(t)
+					svc, op, closeSvc := initLabelService(s, f, t)
+					return svc, op, func() { closeSvc(); closeStore() }
+				}, t)
+			})
+			t.Run("OrganizationService", func(t *testing.T) {
+				influxdbtesting.OrganizationService(func(f influxdbtesting.OrganizationFields, t *testing.T) (influxdb.OrganizationService, string, func()) {
+					s, closeStore := newStore(t)
+					svc, op, closeSvc := initOrganizationService(s, f, t)
+					return svc, op, func() { closeSvc(); closeStore() }
+				}, t)
+			})
+			t.Run("SecretService", func(t *testing.T) {
+				influxdbtesting.SecretService(func(f influxdbtesting.SecretServiceFields, t *testing.T) (influxdb.SecretService, func()) {
+					s, closeStore := newStore(t)
+					svc, closeSvc := initSecretService(s, f, t)
+					return svc, func() { closeSvc(); closeStore() }
+				}, t)
+			})
+			t.Run("SessionService", func(t *testing.T) {
+				influxdbtesting.SessionService(func(f influxdbtesting.SessionFields, t *testing.T) (influxdb.SessionService, string, func()) {
+					s, closeStore := newStore(t)
+					svc, op, closeSvc := initSessionService(s, f, t)
+					return svc, op, func() { closeSvc(); closeStore() }
+				}, t)
+			})
+			t.Run("UserResourceMappingService", func(t *testing.T) {
+				influxdbtesting.UserResourceMappingService(initURMServiceFunc(st.new), t)
+			})
+			t.Run("UserService", func(t *testing.T) {
+				influxdbtesting.UserService(func(f influxdbtesting.UserFields, t *testing.T) (influxdb.UserService, string, func()) {
+					s, closeStore := newStore(t)
+					svc, op, closeSvc := initUserService(s, f, t)
+					return svc, op, func() { closeSvc(); closeStore() }
+				}, t)
+			})
+			t.Run("VariableService", func(t *testing.T) {
+				influxdbtesting.VariableService(func(f influxdbtesting.VariableFields, t *testing.T) (influxdb.VariableService, string, func()) {
+					s, closeStore := newStore(t)
+					svc, op, closeSvc := initVariableService(s, f, t)
+					return svc, op, func() { closeSvc(); closeStore() }
+				}, t)
+			})
+		})
+	}
+}
@@ -3,6 +3,7 @@ package kv
 import (
 	"context"
 	"encoding/json"
+	"sort"
 	"strings"
 	"time"
 
@@ -22,6 +23,10 @@ import (
 //   <taskID>/latestCompleted: run data for the latest completed run of a task
 // taskIndexBucket
 //   <orgID>/<taskID>: index for tasks by org
+// taskLeaseBucket
+//   <taskID>: the lease of a scheduler on the task
+// taskDownstreamBucket
+//   <upstreamTaskID>/<taskID>: index for the tasks that depend on an upstream task
 
 // We may want to add a <taskName>/<taskID> index to allow us to look up tasks by task name.
 
@@ -29,30 +34,40 @@ var (
 	taskBucket      = []byte("tasksv1")
 	taskRunBucket   = []byte("taskRunsv1")
 	taskIndexBucket = []byte("taskIndexsv1")
+
+	taskDownstreamBucket = []byte("taskDownstreamsv1")
 )
 
 var _ influxdb.TaskService = (*Service)(nil)
 
 type kvTask struct {
-	ID              influxdb.ID            `json:"id"`
-	Type            string                 `json:"type,omitempty"`
-	OrganizationID  influxdb.ID            `json:"orgID"`
-	Organization    string                 `json:"org"`
-	OwnerID         influxdb.ID            `json:"ownerID"`
-	Name            string                 `json:"name"`
-	Description     string                 `json:"description,omitempty"`
-	Status          string                 `json:"status"`
-	Flux            string                 `json:"flux"`
-	Every           string                 `json:"every,omitempty"`
-	Cron            string                 `json:"cron,omitempty"`
-	LastRunStatus   string                 `json:"lastRunStatus,omitempty"`
-	LastRunError    string                 `json:"lastRunError,omitempty"`
-	Offset          influxdb.Duration      `json:"offset,omitempty"`
-	LatestCompleted time.Time              `json:"latestCompleted,omitempty"`
-	LatestScheduled time.Time              `json:"latestScheduled,omitempty"`
-	CreatedAt       time.Time              `json:"createdAt,omitempty"`
-	UpdatedAt       time.Time              `json:"updatedAt,omitempty"`
-	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+	ID              influxdb.ID                `json:"id"`
+	Type            string                     `json:"type,omitempty"`
+	OrganizationID  influxdb.ID                `json:"orgID"`
+	Organization    string                     `json:"org"`
+	OwnerID         influxdb.ID                `json:"ownerID"`
+	Name            string                     `json:"name"`
+	Description     string                     `json:"description,omitempty"`
+	Status          string                     `json:"status"`
+	Flux            string                     `json:"flux"`
+	Every           string                     `json:"every,omitempty"`
+	Cron            string                     `json:"cron,omitempty"`
+	LastRunStatus   string                     `json:"lastRunStatus,omitempty"`
+	LastRunError    string                     `json:"lastRunError,omitempty"`
+	Offset          influxdb.Duration          `json:"offset,omitempty"`
+	Upstream        []influxdb.ID              `json:"upstream,omitempty"`
+	TemplateID      influxdb.ID                `json:"templateID,omitempty"`
+	TemplateParams  map[string]interface{}     `json:"templateParams,omitempty"`
+	Notification    *influxdb.TaskNotification `json:"notification,omitempty"`
+	Trigger         *influxdb.TaskTrigger      `json:"trigger,omitempty"`
+	RunRetention    *influxdb.TaskRunRetention `json:"runRetention,omitempty"`
+	LatestCompleted time.Time                  `json:"latestCompleted,omitempty"`
+	LatestScheduled time.Time                  `json:"latestScheduled,omitempty"`
+	CreatedAt       time.Time                  `json:"createdAt,omitempty"`
+	UpdatedAt       time.Time                  `json:"updatedAt,omitempty"`
+	Metadata        map[string]interface{}     `json:"metadata,omitempty"`
+
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
 }
 
 func kvToInfluxTask(k *kvTask) *influxdb.Task {
@@ -71,11 +86,19 @@ func kvToInfluxTask(k *kvTask) *influxdb.Task {
 		LastRunStatus:   k.LastRunStatus,
 		LastRunError:    k.LastRunError,
 		Offset:          k.Offset.Duration,
+		Upstream:        k.Upstream,
+		TemplateID:      k.TemplateID,
+		TemplateParams:  k.TemplateParams,
+		Notification:    k.Notification,
+		Trigger:         k.Trigger,
+		RunRetention:    k.RunRetention,
 		LatestCompleted: k.LatestCompleted,
 		LatestScheduled: k.LatestScheduled,
 		CreatedAt:       k.CreatedAt,
 		UpdatedAt:       k.UpdatedAt,
 		Metadata:        k.Metadata,
+
+		ConsecutiveFailures: k.ConsecutiveFailures,
 	}
 }
 
@@ -89,6 +112,15 @@ func (s *Service) initializeTasks(ctx context.Context, tx Tx) error {
 	if _, err := tx.Bucket(taskIndexBucket); err != nil {
 		return err
 	}
+	if _, err := tx.Bucket(taskLeaseBucket); err != nil {
+		return err
+	}
+	if _, err := tx.Bucket(taskDownstreamBucket); err != nil {
+		return err
+	}
+	if _, err := tx.Bucket(taskTemplateBucket); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -488,6 +520,24 @@ func newTaskMatchFn(f influxdb.TaskFilter, org *influxdb.Organization) func(t *i
 		}
 	}
 
+	if f.Upstream != nil {
+		expected := *f.Upstream
+		prevFn := fn
+		fn = func(t *influxdb.Task) bool {
+			res := prevFn == nil || prevFn(t)
+			return res && hasUpstream(t, expected)
+		}
+	}
+
+	if f.TemplateID != nil {
+		expected := *f.TemplateID
+		prevFn := fn
+		fn = func(t *influxdb.Task) bool {
+			res := prevFn == nil || prevFn(t)
+			return res && t.TemplateID == expected
+		}
+	}
+
 	return fn
 }
 
@@ -593,6 +643,13 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 	// 	return nil, influxdb.ErrInvalidOwnerID
 	// }
 
+	if tc.TemplateID.Valid() {
+		tc.Flux, err = s.renderTemplateTask(ctx, tx, org.ID, tc.TemplateID, tc.TemplateParams)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	opt, err := options.FromScript(tc.Flux)
 	if err != nil {
 		return nil, influxdb.ErrTaskOptionParse(err)
@@ -616,11 +673,27 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 		Flux:            tc.Flux,
 		Every:           opt.Every.String(),
 		Cron:            opt.Cron,
+		Upstream:        tc.Upstream,
+		TemplateID:      tc.TemplateID,
+		TemplateParams:  tc.TemplateParams,
+		Notification:    tc.Notification,
+		Trigger:         tc.Trigger,
+		RunRetention:    tc.RunRetention,
 		CreatedAt:       createdAt,
 		LatestCompleted: createdAt,
 		LatestScheduled: createdAt,
 	}
 
+	if err := s.validateUpstream(ctx, tx, task); err != nil {
+		return nil, err
+	}
+	if err := s.validateNotification(ctx, tx, task); err != nil {
+		return nil, err
+	}
+	if err := s.validateTrigger(ctx, tx, task); err != nil {
+		return nil, err
+	}
+
 	if opt.Offset != nil {
 		off, err := time.ParseDuration(opt.Offset.String())
 		if err != nil {
@@ -667,6 +740,11 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
 	}
 
+	// write the downstream index
+	if err := updateTaskDownstreamIndex(tx, task.ID, nil, task.Upstream); err != nil {
+		return nil, err
+	}
+
 	if err := s.createTaskURM(ctx, tx, task); err != nil {
 		s.log.Info("Error creating user resource mapping for task", zap.Stringer("taskID", task.ID), zap.Error(err))
 	}
@@ -697,6 +775,176 @@ func (s *Service) createTask(ctx context.Context, tx Tx, tc influxdb.TaskCreate)
 	return task, nil
 }
 
+// validateNotification returns an error if the task notifies a notification
+// endpoint of another organization, or one that does not exist.
+func (s *Service) validateNotification(ctx context.Context, tx Tx, task *influxdb.Task) error {
+	if task.Notification == nil {
+		return nil
+	}
+	if err := task.Notification.Validate(); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  err.Error(),
+			Op:   "task",
+		}
+	}
+
+	e, err := s.findNotificationEndpointByID(ctx, tx, task.Notification.EndpointID)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound || (err == nil && e.GetOrgID() != task.OrganizationID) {
+		return influxdb.ErrInvalidTaskNotificationEndpoint(task.Notification.EndpointID)
+	}
+	return err
+}
+
+// validateTrigger returns an error if the task is triggered by writes to a
+// bucket of another organization, or one that does not exist.
+func (s *Service) validateTrigger(ctx context.Context, tx Tx, task *influxdb.Task) error {
+	if task.Trigger == nil {
+		return nil
+	}
+	if err := task.Trigger.Validate(); err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  err.Error(),
+			Op:   "task",
+		}
+	}
+
+	b, err := s.findBucketByID(ctx, tx, task.Trigger.BucketID)
+	if influxdb.ErrorCode(err) == influxdb.ENotFound || (err == nil && b.OrgID != task.OrganizationID) {
+		return influxdb.ErrInvalidTaskTriggerBucket(task.Trigger.BucketID)
+	}
+	return err
+}
+
+// validateUpstream checks that the upstream tasks of task exist in the task's
+// organization, and that depending on them does not make task depend on itself.
+// Duplicate upstream tasks are removed.
+func (s *Service) validateUpstream(ctx context.Context, tx Tx, task *influxdb.Task) error {
+	if len(task.Upstream) == 0 {
+		task.Upstream = nil
+		return nil
+	}
+
+	var (
+		upstream []influxdb.ID
+		seen     = make(map[influxdb.ID]bool, len(task.Upstream))
+	)
+	for _, id := range task.Upstream {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		if id == task.ID {
+			return influxdb.ErrTaskDependencyCycle
+		}
+		up, err := s.findTaskByID(ctx, tx, id)
+		if err == influxdb.ErrTaskNotFound || (err == nil && up.OrganizationID != task.OrganizationID) {
+			return influxdb.ErrInvalidUpstreamTask(id)
+		}
+		if err != nil {
+			return err
+		}
+		upstream = append(upstream, id)
+	}
+	task.Upstream = upstream
+
+	// walk the graph upstream of the task; reaching the task again is a cycle.
+	visited := make(map[influxdb.ID]bool)
+	stack := append([]influxdb.ID(nil), upstream...)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if id == task.ID {
+			return influxdb.ErrTaskDependencyCycle
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+
+		up, err := s.findTaskByID(ctx, tx, id)
+		if err == influxdb.ErrTaskNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		stack = append(stack, up.Upstream...)
+	}
+	return nil
+}
+
+// updateTaskDownstreamIndex replaces the downstream index entries of the task
+// with id for the upstream tasks prev with those for the upstream tasks next.
+func updateTaskDownstreamIndex(tx Tx, id influxdb.ID, prev, next []influxdb.ID) error {
+	if len(prev) == 0 && len(next) == 0 {
+		return nil
+	}
+
+	b, err := tx.Bucket(taskDownstreamBucket)
+	if err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	encodedID, err := id.Encode()
+	if err != nil {
+		return influxdb.ErrInvalidTaskID
+	}
+
+	for _, up := range prev {
+		key, err := taskDownstreamKey(up, id)
+		if err != nil {
+			return err
+		}
+		if err := b.Delete(key); err != nil {
+			return influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+	}
+	for _, up := range next {
+		key, err := taskDownstreamKey(up, id)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(key, encodedID); err != nil {
+			return influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+	}
+	return nil
+}
+
+// hasDownstreamTasks reports whether any task depends on the task with id.
+func hasDownstreamTasks(tx Tx, id influxdb.ID) (bool, error) {
+	b, err := tx.Bucket(taskDownstreamBucket)
+	if err != nil {
+		return false, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	encodedID, err := id.Encode()
+	if err != nil {
+		return false, influxdb.ErrInvalidTaskID
+	}
+	prefix := append(encodedID, '/')
+
+	cur, err := b.ForwardCursor(prefix, WithCursorPrefix(prefix))
+	if err != nil {
+		return false, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	k, _ := cur.Next()
+	return k != nil, nil
+}
+
+func hasUpstream(t *influxdb.Task, id influxdb.ID) bool {
+	for _, up := range t.Upstream {
+		if up == id {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *Service) createTaskURM(ctx context.Context, tx Tx, t *influxdb.Task) error {
 	// TODO(jsteenb2): should not be getting authorizer inside the store, should terminate at the
 	//  transport layer then pass user id everywhere else.
@@ -740,6 +988,27 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 
 	updatedAt := s.clock.Now().UTC()
 
+	// render the task again from its template, or detach it from its template
+	// when its flux is updated.
+	if upd.TemplateParams != nil {
+		if !task.TemplateID.Valid() {
+			return nil, influxdb.ErrTaskNotFromTemplate
+		}
+		flux, err := s.renderTemplateTask(ctx, tx, task.OrganizationID, task.TemplateID, upd.TemplateParams)
+		if err != nil {
+			return nil, err
+		}
+		upd.Flux = &flux
+		upd.Options = options.Options{}
+		task.TemplateParams = upd.TemplateParams
+		if len(task.TemplateParams) == 0 {
+			task.TemplateParams = nil
+		}
+	} else if !upd.Options.IsZero() || upd.Flux != nil {
+		task.TemplateID = 0
+		task.TemplateParams = nil
+	}
+
 	// update the flux script
 	if !upd.Options.IsZero() || upd.Flux != nil {
 		if err = upd.UpdateFlux(task.Flux); err != nil {
@@ -783,6 +1052,48 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		}
 	}
 
+	if upd.Upstream != nil {
+		prevUpstream := task.Upstream
+		task.Upstream = *upd.Upstream
+		if err := s.validateUpstream(ctx, tx, task); err != nil {
+			return nil, err
+		}
+		if err := updateTaskDownstreamIndex(tx, task.ID, prevUpstream, task.Upstream); err != nil {
+			return nil, err
+		}
+		task.UpdatedAt = updatedAt
+	}
+
+	if upd.Notification != nil {
+		task.Notification = upd.Notification
+		if !task.Notification.EndpointID.Valid() {
+			task.Notification = nil
+		}
+		if err := s.validateNotification(ctx, tx, task); err != nil {
+			return nil, err
+		}
+		task.UpdatedAt = updatedAt
+	}
+
+	if upd.Trigger != nil {
+		task.Trigger = upd.Trigger
+		if !task.Trigger.BucketID.Valid() {
+			task.Trigger = nil
+		}
+		if err := s.validateTrigger(ctx, tx, task); err != nil {
+			return nil, err
+		}
+		task.UpdatedAt = updatedAt
+	}
+
+	if upd.RunRetention != nil {
+		task.RunRetention = upd.RunRetention
+		if task.RunRetention.IsZero() {
+			task.RunRetention = nil
+		}
+		task.UpdatedAt = updatedAt
+	}
+
 	if upd.Metadata != nil {
 		task.Metadata = upd.Metadata
 		task.UpdatedAt = updatedAt
@@ -805,6 +1116,10 @@ func (s *Service) updateTask(ctx context.Context, tx Tx, id influxdb.ID, upd inf
 		}
 	}
 
+	if upd.ConsecutiveFailures != nil {
+		task.ConsecutiveFailures = *upd.ConsecutiveFailures
+	}
+
 	if upd.LastRunStatus != nil {
 		task.LastRunStatus = *upd.LastRunStatus
 		if *upd.LastRunStatus == "failed" && upd.LastRunError != nil {
@@ -888,6 +1203,20 @@ func (s *Service) deleteTask(ctx context.Context, tx Tx, id influxdb.ID) error {
 		return err
 	}
 
+	// refuse to leave tasks waiting on an upstream task that no longer exists
+	hasDownstream, err := hasDownstreamTasks(tx, task.ID)
+	if err != nil {
+		return err
+	}
+	if hasDownstream {
+		return influxdb.ErrTaskHasDownstream
+	}
+
+	// remove the downstream index
+	if err := updateTaskDownstreamIndex(tx, task.ID, task.Upstream, nil); err != nil {
+		return err
+	}
+
 	// remove the orgs index
 	orgKey, err := taskOrgKey(task.OrganizationID, task.ID)
 	if err != nil {
@@ -934,6 +1263,10 @@ func (s *Service) deleteTask(ctx context.Context, tx Tx, id influxdb.ID) error {
 		return influxdb.ErrUnexpectedTaskBucketErr(err)
 	}
 
+	if err := s.deleteTaskLease(ctx, tx, task.ID); err != nil {
+		return err
+	}
+
 	if err := s.deleteUserResourceMapping(ctx, tx, influxdb.UserResourceMappingFilter{
 		ResourceID: task.ID,
 	}); err != nil {
@@ -1023,6 +1356,10 @@ func (s *Service) findRuns(ctx context.Context, tx Tx, filter influxdb.RunFilter
 		return nil, 0, influxdb.ErrOutOfBoundsLimit
 	}
 
+	if filter.Cursor != nil {
+		return s.findRunsAfterCursor(ctx, tx, filter)
+	}
+
 	var runs []*influxdb.Run
 	// manual runs
 	manualRuns, err := s.manualRuns(ctx, tx, filter.Task)
@@ -1051,6 +1388,33 @@ func (s *Service) findRuns(ctx context.Context, tx Tx, filter influxdb.RunFilter
 	return runs, len(runs), nil
 }
 
+// findRunsAfterCursor returns the manual and currently running runs listed
+// after the cursor of the filter, from the most recently scheduled.
+func (s *Service) findRunsAfterCursor(ctx context.Context, tx Tx, filter influxdb.RunFilter) ([]*influxdb.Run, int, error) {
+	manualRuns, err := s.manualRuns(ctx, tx, filter.Task)
+	if err != nil {
+		return nil, 0, err
+	}
+	currentlyRunning, err := s.currentlyRunning(ctx, tx, filter.Task)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var runs []*influxdb.Run
+	for _, run := range append(manualRuns, currentlyRunning...) {
+		if filter.Cursor.Precedes(run) {
+			runs = append(runs, run)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return influxdb.NewRunCursor(runs[i]).Precedes(runs[j])
+	})
+	if len(runs) > filter.Limit {
+		runs = runs[:filter.Limit]
+	}
+	return runs, len(runs), nil
+}
+
 // FindRunByID returns a single run.
 func (s *Service) FindRunByID(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error) {
 	var run *influxdb.Run
@@ -1287,6 +1651,10 @@ func (s *Service) CreateRun(ctx context.Context, taskID influxdb.ID, scheduledFo
 	return r, err
 }
 func (s *Service) createRun(ctx context.Context, tx Tx, taskID influxdb.ID, scheduledFor time.Time, runAt time.Time) (*influxdb.Run, error) {
+	if err := s.checkTaskLease(ctx, tx, taskID); err != nil {
+		return nil, err
+	}
+
 	id := s.IDGenerator.ID()
 	t := time.Unix(scheduledFor.Unix(), 0).UTC()
 
@@ -1320,6 +1688,130 @@ func (s *Service) createRun(ctx context.Context, tx Tx, taskID influxdb.ID, sche
 	return &run, nil
 }
 
+// CreateRetryRun creates the next attempt of the failed run runID, which must
+// not have been finished yet. The attempt is added to the currently running
+// runs with the scheduled time, run time and trigger of runID, and is linked to
+// it. Like every other run, it is only created while the task lease in ctx, if
+// any, is still held.
+func (s *Service) CreateRetryRun(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error) {
+	var r *influxdb.Run
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		run, err := s.createRetryRun(ctx, tx, taskID, runID)
+		if err != nil {
+			return err
+		}
+		r = run
+		return nil
+	})
+	return r, err
+}
+
+func (s *Service) createRetryRun(ctx context.Context, tx Tx, taskID, runID influxdb.ID) (*influxdb.Run, error) {
+	if err := s.checkTaskLease(ctx, tx, taskID); err != nil {
+		return nil, err
+	}
+
+	prev, err := s.findRunByID(ctx, tx, taskID, runID)
+	if err != nil {
+		return nil, err
+	}
+	if prev.Status != influxdb.RunFail.String() {
+		return nil, influxdb.ErrRunNotFailed
+	}
+
+	// runs created before they could be retried are their first attempt.
+	attempt := prev.Attempt
+	if attempt == 0 {
+		attempt = 1
+	}
+
+	run := influxdb.Run{
+		ID:           s.IDGenerator.ID(),
+		TaskID:       taskID,
+		ScheduledFor: prev.ScheduledFor,
+		RunAt:        prev.RunAt,
+		RequestedAt:  prev.RequestedAt,
+		Status:       influxdb.RunScheduled.String(),
+		Attempt:      attempt + 1,
+		RetryOf:      prev.ID,
+		Log:          []influxdb.Log{},
+		Trigger:      prev.Trigger,
+	}
+
+	b, err := tx.Bucket(taskRunBucket)
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	runBytes, err := json.Marshal(run)
+	if err != nil {
+		return nil, influxdb.ErrInternalTaskServiceError(err)
+	}
+
+	runKey, err := taskRunKey(taskID, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Put(runKey, runBytes); err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	return &run, nil
+}
+
+// CreateTriggeredRun creates a run, scheduled for now, of the writes in the
+// time range of trigger, and adds it to the currently running runs.
+func (s *Service) CreateTriggeredRun(ctx context.Context, taskID influxdb.ID, trigger influxdb.RunTrigger) (*influxdb.Run, error) {
+	var r *influxdb.Run
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		run, err := s.createTriggeredRun(ctx, tx, taskID, trigger)
+		if err != nil {
+			return err
+		}
+		r = run
+		return nil
+	})
+	return r, err
+}
+
+func (s *Service) createTriggeredRun(ctx context.Context, tx Tx, taskID influxdb.ID, trigger influxdb.RunTrigger) (*influxdb.Run, error) {
+	if err := s.checkTaskLease(ctx, tx, taskID); err != nil {
+		return nil, err
+	}
+
+	now := s.clock.Now().UTC()
+	run := influxdb.Run{
+		ID:           s.IDGenerator.ID(),
+		TaskID:       taskID,
+		ScheduledFor: now.Truncate(time.Second),
+		RunAt:        now,
+		RequestedAt:  now,
+		Status:       influxdb.RunScheduled.String(),
+		Log:          []influxdb.Log{},
+		Trigger:      &trigger,
+	}
+
+	b, err := tx.Bucket(taskRunBucket)
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	runBytes, err := json.Marshal(run)
+	if err != nil {
+		return nil, influxdb.ErrInternalTaskServiceError(err)
+	}
+
+	runKey, err := taskRunKey(taskID, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Put(runKey, runBytes); err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	return &run, nil
+}
+
 func (s *Service) CurrentlyRunning(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Run, error) {
 	var runs []*influxdb.Run
 	err := s.kv.View(ctx, func(tx Tx) error {
@@ -1378,6 +1870,95 @@ func (s *Service) currentlyRunning(ctx context.Context, tx Tx, taskID influxdb.I
 	return runs, nil
 }
 
+// PruneRuns deletes the run records of a task that were last active before the
+// given time, along with the manual runs requested before it. Runs that are
+// scheduled or started are still in progress and are kept however old they
+// are, so the records left behind are the ones of runs that ended but were
+// never finished. It returns the number of runs deleted.
+func (s *Service) PruneRuns(ctx context.Context, taskID influxdb.ID, before time.Time) (int, error) {
+	var n int
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		pruned, err := s.pruneRuns(ctx, tx, taskID, before)
+		if err != nil {
+			return err
+		}
+		n = pruned
+		return nil
+	})
+	return n, err
+}
+
+func (s *Service) pruneRuns(ctx context.Context, tx Tx, taskID influxdb.ID, before time.Time) (int, error) {
+	bucket, err := tx.Bucket(taskRunBucket)
+	if err != nil {
+		return 0, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	var n int
+	runs, err := s.currentlyRunning(ctx, tx, taskID)
+	if err != nil {
+		return 0, err
+	}
+	for _, r := range runs {
+		if runInProgress(r) || !runLastActive(r).Before(before) {
+			continue
+		}
+		key, err := taskRunKey(taskID, r.ID)
+		if err != nil {
+			return 0, err
+		}
+		if err := bucket.Delete(key); err != nil {
+			return 0, influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+		n++
+	}
+
+	mRuns, err := s.manualRuns(ctx, tx, taskID)
+	if err != nil {
+		return 0, err
+	}
+	kept := mRuns[:0]
+	for _, r := range mRuns {
+		if runLastActive(r).Before(before) {
+			n++
+			continue
+		}
+		kept = append(kept, r)
+	}
+	if len(kept) == len(mRuns) {
+		return n, nil
+	}
+
+	mRunsBytes, err := json.Marshal(kept)
+	if err != nil {
+		return 0, influxdb.ErrInternalTaskServiceError(err)
+	}
+	runsKey, err := taskManualRunKey(taskID)
+	if err != nil {
+		return 0, err
+	}
+	if err := bucket.Put(runsKey, mRunsBytes); err != nil {
+		return 0, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	return n, nil
+}
+
+// runInProgress returns whether a run is scheduled or started.
+func runInProgress(r *influxdb.Run) bool {
+	return r.Status == influxdb.RunScheduled.String() || r.Status == influxdb.RunStarted.String()
+}
+
+// runLastActive returns the last time a run was requested, due or started.
+func runLastActive(r *influxdb.Run) time.Time {
+	last := r.RunAt
+	for _, t := range []time.Time{r.RequestedAt, r.StartedAt} {
+		if t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
+
 func (s *Service) ManualRuns(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Run, error) {
 	var runs []*influxdb.Run
 	err := s.kv.View(ctx, func(tx Tx) error {
@@ -1507,16 +2088,24 @@ func (s *Service) FinishRun(ctx context.Context, taskID, runID influxdb.ID) (*in
 }
 
 func (s *Service) finishRun(ctx context.Context, tx Tx, taskID, runID influxdb.ID) (*influxdb.Run, error) {
+	if err := s.checkTaskLease(ctx, tx, taskID); err != nil {
+		return nil, err
+	}
+
 	// get the run
 	r, err := s.findRunByID(ctx, tx, taskID, runID)
 	if err != nil {
 		return nil, err
 	}
 
-	// tell task to update latest completed
-	scheduled := r.ScheduledFor
+	// tell task to update latest completed, which triggered runs are not part
+	// of, as they are not scheduled.
+	var latestCompleted *time.Time
+	if r.Trigger == nil {
+		latestCompleted = &r.ScheduledFor
+	}
 	_, err = s.updateTask(ctx, tx, taskID, influxdb.TaskUpdate{
-		LatestCompleted: &scheduled,
+		LatestCompleted: latestCompleted,
 		LastRunStatus:   &r.Status,
 		LastRunError: func() *string {
 			if r.Status == "failed" {
@@ -1551,6 +2140,53 @@ func (s *Service) finishRun(ctx context.Context, tx Tx, taskID, runID influxdb.I
 	return r, nil
 }
 
+// CountRunResult counts a final run of a task in the consecutive failures of
+// the task: a failed run adds to them and a successful run resets them. It
+// returns the number of consecutive failed runs including the run, if it
+// failed, or preceding it, if it succeeded. Other states are not counted.
+func (s *Service) CountRunResult(ctx context.Context, taskID influxdb.ID, state influxdb.RunStatus) (int, error) {
+	var failures int
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		n, err := s.countRunResult(ctx, tx, taskID, state)
+		if err != nil {
+			return err
+		}
+		failures = n
+		return nil
+	})
+	return failures, err
+}
+
+func (s *Service) countRunResult(ctx context.Context, tx Tx, taskID influxdb.ID, state influxdb.RunStatus) (int, error) {
+	if err := s.checkTaskLease(ctx, tx, taskID); err != nil {
+		return 0, err
+	}
+
+	task, err := s.findTaskByID(ctx, tx, taskID)
+	if err != nil {
+		return 0, err
+	}
+
+	failures := task.ConsecutiveFailures
+	var count int
+	switch state {
+	case influxdb.RunFail:
+		failures++
+		count = failures
+	case influxdb.RunSuccess:
+		if failures == 0 {
+			return 0, nil
+		}
+	default:
+		return failures, nil
+	}
+
+	if _, err := s.updateTask(ctx, tx, taskID, influxdb.TaskUpdate{ConsecutiveFailures: &count}); err != nil {
+		return 0, err
+	}
+	return failures, nil
+}
+
 // UpdateRunState sets the run state at the respective time.
 func (s *Service) UpdateRunState(ctx context.Context, taskID, runID influxdb.ID, when time.Time, state influxdb.RunStatus) error {
 	err := s.kv.Update(ctx, func(tx Tx) error {
@@ -1682,6 +2318,19 @@ func taskOrgKey(orgID, taskID influxdb.ID) ([]byte, error) {
 	return []byte(string(encodedOrgID) + "/" + string(encodedID)), nil
 }
 
+func taskDownstreamKey(upstreamID, taskID influxdb.ID) ([]byte, error) {
+	encodedUpstreamID, err := upstreamID.Encode()
+	if err != nil {
+		return nil, influxdb.ErrInvalidTaskID
+	}
+	encodedID, err := taskID.Encode()
+	if err != nil {
+		return nil, influxdb.ErrInvalidTaskID
+	}
+
+	return []byte(string(encodedUpstreamID) + "/" + string(encodedID)), nil
+}
+
 func taskRunKey(taskID, runID influxdb.ID) ([]byte, error) {
 	encodedID, err := taskID.Encode()
 	if err != nil {
@@ -0,0 +1,185 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+)
+
+var taskLeaseBucket = []byte("taskLeasesv1")
+
+var _ influxdb.TaskLeaseService = (*Service)(nil)
+
+// AcquireTaskLease acquires, or renews, the lease of owner on a task for ttl.
+func (s *Service) AcquireTaskLease(ctx context.Context, taskID influxdb.ID, owner string, ttl time.Duration) (*influxdb.TaskLease, error) {
+	var l *influxdb.TaskLease
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		lease, err := s.acquireTaskLease(ctx, tx, taskID, owner, ttl)
+		if err != nil {
+			return err
+		}
+		l = lease
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (s *Service) acquireTaskLease(ctx context.Context, tx Tx, taskID influxdb.ID, owner string, ttl time.Duration) (*influxdb.TaskLease, error) {
+	now := s.clock.Now().UTC()
+
+	l, err := s.findTaskLease(ctx, tx, taskID)
+	if err != nil && err != influxdb.ErrTaskLeaseNotFound {
+		return nil, err
+	}
+
+	switch {
+	case l == nil:
+		l = &influxdb.TaskLease{TaskID: taskID, Owner: owner, Token: 1}
+	case l.Owner == owner:
+		// renewing keeps the token, even when the lease expired, as long as
+		// nobody else acquired it in the meantime.
+	case !l.Expired(now):
+		return nil, influxdb.ErrTaskLeaseHeld
+	default:
+		l.Owner = owner
+		l.Token++
+	}
+	l.Expires = now.Add(ttl)
+
+	if err := s.putTaskLease(ctx, tx, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// ReleaseTaskLease gives up the lease of owner on a task.
+func (s *Service) ReleaseTaskLease(ctx context.Context, taskID influxdb.ID, owner string) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		l, err := s.findTaskLease(ctx, tx, taskID)
+		if err == influxdb.ErrTaskLeaseNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if l.Owner != owner {
+			return nil
+		}
+
+		// the lease is kept, expired, so that the next owner gets a new token.
+		l.Owner = ""
+		l.Expires = time.Time{}
+		return s.putTaskLease(ctx, tx, l)
+	})
+}
+
+// FindTaskLease returns the current lease on a task.
+func (s *Service) FindTaskLease(ctx context.Context, taskID influxdb.ID) (*influxdb.TaskLease, error) {
+	var l *influxdb.TaskLease
+	err := s.kv.View(ctx, func(tx Tx) error {
+		lease, err := s.findTaskLease(ctx, tx, taskID)
+		if err != nil {
+			return err
+		}
+		l = lease
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (s *Service) findTaskLease(ctx context.Context, tx Tx, taskID influxdb.ID) (*influxdb.TaskLease, error) {
+	key, err := taskID.Encode()
+	if err != nil {
+		return nil, influxdb.ErrInvalidTaskID
+	}
+
+	b, err := tx.Bucket(taskLeaseBucket)
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	v, err := b.Get(key)
+	if IsNotFound(err) {
+		return nil, influxdb.ErrTaskLeaseNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	l := &influxdb.TaskLease{}
+	if err := json.Unmarshal(v, l); err != nil {
+		return nil, influxdb.ErrInternalTaskServiceError(err)
+	}
+	return l, nil
+}
+
+func (s *Service) putTaskLease(ctx context.Context, tx Tx, l *influxdb.TaskLease) error {
+	key, err := l.TaskID.Encode()
+	if err != nil {
+		return influxdb.ErrInvalidTaskID
+	}
+
+	b, err := tx.Bucket(taskLeaseBucket)
+	if err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	v, err := json.Marshal(l)
+	if err != nil {
+		return influxdb.ErrInternalTaskServiceError(err)
+	}
+
+	if err := b.Put(key, v); err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	return nil
+}
+
+func (s *Service) deleteTaskLease(ctx context.Context, tx Tx, taskID influxdb.ID) error {
+	key, err := taskID.Encode()
+	if err != nil {
+		return influxdb.ErrInvalidTaskID
+	}
+
+	b, err := tx.Bucket(taskLeaseBucket)
+	if err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	if err := b.Delete(key); err != nil && !IsNotFound(err) {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	return nil
+}
+
+// checkTaskLease fences out schedulers that lost their lease on a task: when
+// ctx carries a lease, it must still be the current lease on the task.
+func (s *Service) checkTaskLease(ctx context.Context, tx Tx, taskID influxdb.ID) error {
+	held, ok := icontext.GetTaskLease(ctx)
+	if !ok {
+		return nil
+	}
+
+	l, err := s.findTaskLease(ctx, tx, taskID)
+	if err == influxdb.ErrTaskLeaseNotFound {
+		return influxdb.ErrTaskLeaseLost
+	}
+	if err != nil {
+		return err
+	}
+	if l.Owner != held.Owner || l.Token != held.Token {
+		return influxdb.ErrTaskLeaseLost
+	}
+	return nil
+}
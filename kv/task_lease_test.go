@@ -0,0 +1,116 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+)
+
+func TestService_TaskLease(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	c := clock.NewMock()
+	c.Set(time.Unix(1000, 0))
+
+	ts := newService(t, ctx, c)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	task, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           `option task = {name: "a task",every: 1h} from(bucket:"test") |> range(start:-1h)`,
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+		Status:         string(influxdb.TaskActive),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ts.Service.FindTaskLease(ctx, task.ID); err != influxdb.ErrTaskLeaseNotFound {
+		t.Fatalf("expected lease not found, got %v", err)
+	}
+
+	a, err := ts.Service.AcquireTaskLease(ctx, task.ID, "a", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Owner != "a" || a.Token != 1 || !a.Expires.Equal(c.Now().Add(time.Minute)) {
+		t.Fatalf("unexpected lease %+v", a)
+	}
+
+	// another owner cannot acquire a lease that has not expired
+	if _, err := ts.Service.AcquireTaskLease(ctx, task.ID, "b", time.Minute); err != influxdb.ErrTaskLeaseHeld {
+		t.Fatalf("expected lease held, got %v", err)
+	}
+
+	// renewing keeps the token
+	c.Add(30 * time.Second)
+	renewed, err := ts.Service.AcquireTaskLease(ctx, task.ID, "a", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renewed.Token != a.Token || !renewed.Expires.Equal(c.Now().Add(time.Minute)) {
+		t.Fatalf("unexpected renewed lease %+v", renewed)
+	}
+
+	aCtx := icontext.SetTaskLease(ctx, renewed)
+	run, err := ts.Service.CreateRun(aCtx, task.ID, c.Now(), c.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// once expired, another owner takes over with a new token
+	c.Add(2 * time.Minute)
+	b, err := ts.Service.AcquireTaskLease(ctx, task.ID, "b", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Owner != "b" || b.Token != a.Token+1 {
+		t.Fatalf("unexpected lease %+v", b)
+	}
+
+	// the stale owner can no longer record runs
+	if _, err := ts.Service.FinishRun(aCtx, task.ID, run.ID); err != influxdb.ErrTaskLeaseLost {
+		t.Fatalf("expected lease lost finishing run, got %v", err)
+	}
+	if _, err := ts.Service.CreateRun(aCtx, task.ID, c.Now(), c.Now()); err != influxdb.ErrTaskLeaseLost {
+		t.Fatalf("expected lease lost creating run, got %v", err)
+	}
+	if err := ts.Service.UpdateRunState(ctx, task.ID, run.ID, c.Now(), influxdb.RunFail); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Service.CreateRetryRun(aCtx, task.ID, run.ID); err != influxdb.ErrTaskLeaseLost {
+		t.Fatalf("expected lease lost creating retry run, got %v", err)
+	}
+
+	bCtx := icontext.SetTaskLease(ctx, b)
+	if _, err := ts.Service.FinishRun(bCtx, task.ID, run.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// releasing lets another owner acquire the lease before it expires
+	if err := ts.Service.ReleaseTaskLease(ctx, task.ID, "b"); err != nil {
+		t.Fatal(err)
+	}
+	a, err = ts.Service.AcquireTaskLease(ctx, task.ID, "a", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Owner != "a" || a.Token != b.Token+1 {
+		t.Fatalf("unexpected lease %+v", a)
+	}
+
+	// deleting the task deletes its lease
+	if err := ts.Service.DeleteTask(ctx, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Service.FindTaskLease(ctx, task.ID); err != influxdb.ErrTaskLeaseNotFound {
+		t.Fatalf("expected lease not found, got %v", err)
+	}
+}
@@ -0,0 +1,326 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var taskTemplateBucket = []byte("taskTemplatesv1")
+
+var _ influxdb.TaskTemplateService = (*Service)(nil)
+
+// FindTaskTemplateByID returns a single task template.
+func (s *Service) FindTaskTemplateByID(ctx context.Context, id influxdb.ID) (*influxdb.TaskTemplate, error) {
+	var t *influxdb.TaskTemplate
+	err := s.kv.View(ctx, func(tx Tx) error {
+		tmpl, err := s.findTaskTemplateByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		t = tmpl
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (s *Service) findTaskTemplateByID(ctx context.Context, tx Tx, id influxdb.ID) (*influxdb.TaskTemplate, error) {
+	key, err := id.Encode()
+	if err != nil {
+		return nil, influxdb.ErrTaskTemplateNotFound
+	}
+
+	b, err := tx.Bucket(taskTemplateBucket)
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	v, err := b.Get(key)
+	if IsNotFound(err) {
+		return nil, influxdb.ErrTaskTemplateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t := &influxdb.TaskTemplate{}
+	if err := json.Unmarshal(v, t); err != nil {
+		return nil, influxdb.ErrInternalTaskServiceError(err)
+	}
+	return t, nil
+}
+
+// FindTaskTemplates returns the task templates that match a filter and their count.
+func (s *Service) FindTaskTemplates(ctx context.Context, filter influxdb.TaskTemplateFilter) ([]*influxdb.TaskTemplate, int, error) {
+	var ts []*influxdb.TaskTemplate
+	err := s.kv.View(ctx, func(tx Tx) error {
+		tmpls, err := s.findTaskTemplates(ctx, tx, filter)
+		if err != nil {
+			return err
+		}
+		ts = tmpls
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return ts, len(ts), nil
+}
+
+func (s *Service) findTaskTemplates(ctx context.Context, tx Tx, filter influxdb.TaskTemplateFilter) ([]*influxdb.TaskTemplate, error) {
+	b, err := tx.Bucket(taskTemplateBucket)
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	c, err := b.ForwardCursor(nil)
+	if err != nil {
+		return nil, influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	defer c.Close()
+
+	ts := []*influxdb.TaskTemplate{}
+	for k, v := c.Next(); k != nil; k, v = c.Next() {
+		t := &influxdb.TaskTemplate{}
+		if err := json.Unmarshal(v, t); err != nil {
+			return nil, influxdb.ErrInternalTaskServiceError(err)
+		}
+		if filter.OrganizationID != nil && t.OrganizationID != *filter.OrganizationID {
+			continue
+		}
+		if filter.Name != nil && t.Name != *filter.Name {
+			continue
+		}
+		ts = append(ts, t)
+	}
+
+	return ts, c.Err()
+}
+
+// CreateTaskTemplate creates a new task template and sets t.ID with the new identifier.
+func (s *Service) CreateTaskTemplate(ctx context.Context, t *influxdb.TaskTemplate) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		return s.createTaskTemplate(ctx, tx, t)
+	})
+}
+
+func (s *Service) createTaskTemplate(ctx context.Context, tx Tx, t *influxdb.TaskTemplate) error {
+	if _, err := s.findOrganizationByID(ctx, tx, t.OrganizationID); err != nil {
+		return err
+	}
+	if err := t.Valid(); err != nil {
+		return err
+	}
+
+	t.ID = s.IDGenerator.ID()
+	t.CreatedAt = s.clock.Now().UTC()
+	t.UpdatedAt = t.CreatedAt
+	return s.putTaskTemplate(ctx, tx, t)
+}
+
+func (s *Service) putTaskTemplate(ctx context.Context, tx Tx, t *influxdb.TaskTemplate) error {
+	key, err := t.ID.Encode()
+	if err != nil {
+		return influxdb.ErrTaskTemplateNotFound
+	}
+
+	b, err := tx.Bucket(taskTemplateBucket)
+	if err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+
+	v, err := json.Marshal(t)
+	if err != nil {
+		return influxdb.ErrInternalTaskServiceError(err)
+	}
+	if err := b.Put(key, v); err != nil {
+		return influxdb.ErrUnexpectedTaskBucketErr(err)
+	}
+	return nil
+}
+
+// UpdateTaskTemplate updates a task template and renders the tasks
+// instantiated from it again. Nothing is updated if any of the tasks
+// cannot be rendered from the updated template.
+func (s *Service) UpdateTaskTemplate(ctx context.Context, id influxdb.ID, upd influxdb.TaskTemplateUpdate) (*influxdb.TaskTemplate, error) {
+	var t *influxdb.TaskTemplate
+	err := s.kv.Update(ctx, func(tx Tx) error {
+		tmpl, err := s.updateTaskTemplate(ctx, tx, id, upd)
+		if err != nil {
+			return err
+		}
+		t = tmpl
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (s *Service) updateTaskTemplate(ctx context.Context, tx Tx, id influxdb.ID, upd influxdb.TaskTemplateUpdate) (*influxdb.TaskTemplate, error) {
+	t, err := s.findTaskTemplateByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	upd.Apply(t)
+	if err := t.Valid(); err != nil {
+		return nil, err
+	}
+
+	tasks, err := s.findTemplateTasks(ctx, tx, t)
+	if err != nil {
+		return nil, err
+	}
+	// render every task before writing anything, so that stores without
+	// rollback are left unchanged too.
+	for _, task := range tasks {
+		if _, err := t.Render(task.TemplateParams); err != nil {
+			return nil, &influxdb.Error{
+				Code: influxdb.ErrorCode(err),
+				Msg:  fmt.Sprintf("could not render task %s from the updated template", task.ID),
+				Err:  err,
+			}
+		}
+	}
+
+	t.UpdatedAt = s.clock.Now().UTC()
+	if err := s.putTaskTemplate(ctx, tx, t); err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		if _, err := s.updateTask(ctx, tx, task.ID, influxdb.TaskUpdate{TemplateParams: templateParams(task)}); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// PreviewTaskTemplateUpdate returns how an update of a task template would
+// change the tasks instantiated from it, without updating anything.
+func (s *Service) PreviewTaskTemplateUpdate(ctx context.Context, id influxdb.ID, upd influxdb.TaskTemplateUpdate) ([]*influxdb.TaskTemplateDiff, error) {
+	var diffs []*influxdb.TaskTemplateDiff
+	err := s.kv.View(ctx, func(tx Tx) error {
+		t, err := s.findTaskTemplateByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		upd.Apply(t)
+		if err := t.Valid(); err != nil {
+			return err
+		}
+
+		tasks, err := s.findTemplateTasks(ctx, tx, t)
+		if err != nil {
+			return err
+		}
+
+		diffs = make([]*influxdb.TaskTemplateDiff, 0, len(tasks))
+		for _, task := range tasks {
+			diff := &influxdb.TaskTemplateDiff{
+				TaskID:   task.ID,
+				TaskName: task.Name,
+				OldFlux:  task.Flux,
+			}
+			flux, err := t.Render(task.TemplateParams)
+			if err != nil {
+				diff.Error = err.Error()
+			} else {
+				diff.NewFlux = flux
+			}
+			diffs = append(diffs, diff)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return diffs, nil
+}
+
+// DeleteTaskTemplate removes a task template. Templates that tasks are
+// instantiated from cannot be removed.
+func (s *Service) DeleteTaskTemplate(ctx context.Context, id influxdb.ID) error {
+	return s.kv.Update(ctx, func(tx Tx) error {
+		t, err := s.findTaskTemplateByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		tasks, err := s.findTemplateTasks(ctx, tx, t)
+		if err != nil {
+			return err
+		}
+		if len(tasks) > 0 {
+			return influxdb.ErrTaskTemplateInUse
+		}
+
+		key, err := id.Encode()
+		if err != nil {
+			return influxdb.ErrTaskTemplateNotFound
+		}
+		b, err := tx.Bucket(taskTemplateBucket)
+		if err != nil {
+			return influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+		if err := b.Delete(key); err != nil {
+			return influxdb.ErrUnexpectedTaskBucketErr(err)
+		}
+		return nil
+	})
+}
+
+// findTemplateTasks returns all the tasks instantiated from a template.
+func (s *Service) findTemplateTasks(ctx context.Context, tx Tx, t *influxdb.TaskTemplate) ([]*influxdb.Task, error) {
+	filter := influxdb.TaskFilter{
+		OrganizationID: &t.OrganizationID,
+		TemplateID:     &t.ID,
+		Limit:          influxdb.TaskMaxPageSize,
+	}
+
+	var tasks []*influxdb.Task
+	for {
+		ts, _, err := s.findTasksByOrg(ctx, tx, filter)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, ts...)
+		if len(ts) < filter.Limit {
+			return tasks, nil
+		}
+		filter.After = &ts[len(ts)-1].ID
+	}
+}
+
+// renderTemplateTask returns the Flux of a task of an organization
+// instantiated from a template with the given parameters.
+func (s *Service) renderTemplateTask(ctx context.Context, tx Tx, orgID, templateID influxdb.ID, params map[string]interface{}) (string, error) {
+	t, err := s.findTaskTemplateByID(ctx, tx, templateID)
+	if err != nil {
+		return "", err
+	}
+	if t.OrganizationID != orgID {
+		return "", influxdb.ErrTaskTemplateNotFound
+	}
+	return t.Render(params)
+}
+
+// templateParams returns the template parameters of a task, which are never
+// nil, so that updating the task with them renders it again.
+func templateParams(task *influxdb.Task) map[string]interface{} {
+	if task.TemplateParams == nil {
+		return map[string]interface{}{}
+	}
+	return task.TemplateParams
+}
@@ -0,0 +1,124 @@
+package kv_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+)
+
+func TestService_TaskTemplate(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	tmpl := &influxdb.TaskTemplate{
+		OrganizationID: ts.Org.ID,
+		Name:           "downsample",
+		Flux:           `option task = {name: params.name, every: 1h} from(bucket: params.bucket) |> range(start: -1h)`,
+		Params: []influxdb.TaskTemplateParam{
+			{Name: "name", Type: influxdb.TaskTemplateParamString},
+			{Name: "bucket", Type: influxdb.TaskTemplateParamString, Default: "telegraf"},
+		},
+	}
+	if err := ts.Service.CreateTaskTemplate(ctx, tmpl); err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+		TemplateID:     tmpl.ID,
+		TemplateParams: map[string]interface{}{"name": "cpu"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Name != "cpu" || task.TemplateID != tmpl.ID || !strings.Contains(task.Flux, `"telegraf"`) {
+		t.Fatalf("unexpected task instantiated from the template %+v", task)
+	}
+
+	// a template that tasks are instantiated from cannot be deleted
+	if err := ts.Service.DeleteTaskTemplate(ctx, tmpl.ID); err != influxdb.ErrTaskTemplateInUse {
+		t.Fatalf("expected template in use, got %v", err)
+	}
+
+	// previewing an update does not change the task
+	flux := `option task = {name: params.name, every: 1h} from(bucket: params.bucket) |> range(start: -2h)`
+	diffs, err := ts.Service.PreviewTaskTemplateUpdate(ctx, tmpl.ID, influxdb.TaskTemplateUpdate{Flux: &flux})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 1 || diffs[0].TaskID != task.ID || diffs[0].OldFlux != task.Flux || !strings.Contains(diffs[0].NewFlux, "-2h") {
+		t.Fatalf("unexpected diffs %+v", diffs)
+	}
+	found, err := ts.Service.FindTaskByID(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.Flux != task.Flux {
+		t.Fatalf("expected the preview to leave the task unchanged, got:\n%s", found.Flux)
+	}
+
+	// updating the template renders its tasks again
+	if _, err := ts.Service.UpdateTaskTemplate(ctx, tmpl.ID, influxdb.TaskTemplateUpdate{Flux: &flux}); err != nil {
+		t.Fatal(err)
+	}
+	found, err = ts.Service.FindTaskByID(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(found.Flux, "-2h") {
+		t.Fatalf("expected the task to be rendered again, got:\n%s", found.Flux)
+	}
+
+	// an update that cannot render the tasks is rejected as a whole
+	params := []influxdb.TaskTemplateParam{
+		{Name: "name", Type: influxdb.TaskTemplateParamString},
+		{Name: "bucket", Type: influxdb.TaskTemplateParamString},
+	}
+	if _, err := ts.Service.UpdateTaskTemplate(ctx, tmpl.ID, influxdb.TaskTemplateUpdate{Params: &params}); err == nil {
+		t.Fatal("expected an error for a task missing a required parameter")
+	}
+	got, err := ts.Service.FindTaskTemplateByID(ctx, tmpl.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Params[1].Default == nil {
+		t.Fatal("expected the failed update to leave the template unchanged")
+	}
+
+	// updating the parameters of the task renders it again
+	found, err = ts.Service.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{
+		TemplateParams: map[string]interface{}{"name": "cpu", "bucket": "metrics"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(found.Flux, `"metrics"`) {
+		t.Fatalf("expected the task to be rendered with the new parameters, got:\n%s", found.Flux)
+	}
+
+	// updating the flux of the task detaches it from the template
+	taskFlux := `option task = {name: "cpu", every: 1h} from(bucket: "metrics") |> range(start: -3h)`
+	found, err = ts.Service.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Flux: &taskFlux})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found.TemplateID.Valid() || found.TemplateParams != nil {
+		t.Fatalf("expected the task to be detached from the template, got %+v", found)
+	}
+
+	if err := ts.Service.DeleteTaskTemplate(ctx, tmpl.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Service.FindTaskTemplateByID(ctx, tmpl.ID); err != influxdb.ErrTaskTemplateNotFound {
+		t.Fatalf("expected template not found, got %v", err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/influxdata/influxdb/v2"
 	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
 	_ "github.com/influxdata/influxdb/v2/query/builtin"
 	"github.com/influxdata/influxdb/v2/task/servicetest"
 	"go.uber.org/zap/zaptest"
@@ -188,6 +189,50 @@ func TestRetrieveTaskWithBadAuth(t *testing.T) {
 	}
 }
 
+func TestService_TaskRolePermissions(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	other := influxdb.ID(1000)
+	rolePerm := influxdb.Permission{
+		Action:   influxdb.WriteAction,
+		Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, OrgID: &other},
+	}
+	ts.Service.WithRolePermissions(func(tx kv.Tx, userID influxdb.ID) ([]influxdb.Permission, error) {
+		if userID != ts.User.ID {
+			return nil, nil
+		}
+		return []influxdb.Permission{rolePerm}, nil
+	})
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+	task, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           `option task = {name: "a task",every: 1h} from(bucket:"test") |> range(start:-1h)`,
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+		Status:         string(influxdb.TaskActive),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the task runs with the roles of its owner
+	found, err := ts.Service.FindTaskByID(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := found.Authorization.PermissionSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ps.Allowed(rolePerm) {
+		t.Fatalf("expected the task to have the permission of the role of its owner, got %v", ps)
+	}
+}
+
 func TestService_UpdateTask_InactiveToActive(t *testing.T) {
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	defer cancelFunc()
@@ -238,6 +283,150 @@ func TestService_UpdateTask_InactiveToActive(t *testing.T) {
 	}
 }
 
+func TestService_TaskNotification(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	orgID := ts.Org.ID
+	e := &endpoint.Slack{
+		Base: endpoint.Base{Name: "slack", OrgID: &orgID, Status: influxdb.Active},
+		URL:  "http://localhost:7777",
+	}
+	if err := ts.Service.CreateNotificationEndpoint(ctx, e, ts.User.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	flux := `option task = {name: "a task",every: 1h} from(bucket:"test") |> range(start:-1h)`
+	if _, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           flux,
+		OrganizationID: orgID,
+		OwnerID:        ts.User.ID,
+		Notification:   &influxdb.TaskNotification{EndpointID: influxdb.ID(1000)},
+	}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected an invalid error for a missing endpoint, got %v", err)
+	}
+
+	task, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           flux,
+		OrganizationID: orgID,
+		OwnerID:        ts.User.ID,
+		Notification:   &influxdb.TaskNotification{EndpointID: e.GetID(), Failures: 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Notification == nil || task.Notification.EndpointID != e.GetID() || task.Notification.Threshold() != 3 {
+		t.Fatalf("unexpected notification %+v", task.Notification)
+	}
+
+	// a notification without an endpoint removes the notification
+	task, err = ts.Service.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Notification: &influxdb.TaskNotification{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Notification != nil {
+		t.Fatalf("expected the notification to be removed, got %+v", task.Notification)
+	}
+}
+
+func TestService_CountRunResult(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	task, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           `option task = {name: "a task",every: 1h} from(bucket:"test") |> range(start:-1h)`,
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		state influxdb.RunStatus
+		exp   int
+		count int
+	}{
+		{state: influxdb.RunSuccess, exp: 0, count: 0},
+		{state: influxdb.RunFail, exp: 1, count: 1},
+		{state: influxdb.RunFail, exp: 2, count: 2},
+		{state: influxdb.RunCanceled, exp: 2, count: 2},
+		{state: influxdb.RunSuccess, exp: 2, count: 0},
+	} {
+		n, err := ts.Service.CountRunResult(ctx, task.ID, tt.state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != tt.exp {
+			t.Fatalf("expected %d failures counting a %s run, got %d", tt.exp, tt.state, n)
+		}
+		found, err := ts.Service.FindTaskByID(ctx, task.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.ConsecutiveFailures != tt.count {
+			t.Fatalf("expected the task to have %d consecutive failures after a %s run, got %d", tt.count, tt.state, found.ConsecutiveFailures)
+		}
+	}
+}
+
+func TestService_TaskTrigger(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	b := &influxdb.Bucket{OrgID: ts.Org.ID, Name: "raw"}
+	if err := ts.Service.CreateBucket(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+
+	flux := `option task = {name: "a task",every: 1h} from(bucket:"raw") |> range(start:-1h)`
+	if _, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           flux,
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+		Trigger:        &influxdb.TaskTrigger{BucketID: influxdb.ID(1000)},
+	}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected an invalid error for a missing bucket, got %v", err)
+	}
+
+	task, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           flux,
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+		Trigger:        &influxdb.TaskTrigger{BucketID: b.ID, Measurement: "cpu"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Trigger == nil || task.Trigger.BucketID != b.ID || task.Trigger.Measurement != "cpu" {
+		t.Fatalf("unexpected trigger %+v", task.Trigger)
+	}
+
+	// a trigger without a bucket removes the trigger
+	task, err = ts.Service.UpdateTask(ctx, task.ID, influxdb.TaskUpdate{Trigger: &influxdb.TaskTrigger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Trigger != nil {
+		t.Fatalf("expected the trigger to be removed, got %+v", task.Trigger)
+	}
+}
+
 func TestTaskRunCancellation(t *testing.T) {
 	store, close, err := NewTestBoltStore(t)
 	if err != nil {
@@ -307,3 +496,131 @@ func TestTaskRunCancellation(t *testing.T) {
 		t.Fatalf("expected task run to be cancelled")
 	}
 }
+
+func TestService_CreateRetryRun(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	task, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           `option task = {name: "a task",every: 1h} from(bucket:"test") |> range(start:-1h)`,
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	run, err := ts.Service.CreateRun(ctx, task.ID, now, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ts.Service.CreateRetryRun(ctx, task.ID, run.ID); err != influxdb.ErrRunNotFailed {
+		t.Fatalf("expected run not failed, got %v", err)
+	}
+
+	if err := ts.Service.UpdateRunState(ctx, task.ID, run.ID, now, influxdb.RunFail); err != nil {
+		t.Fatal(err)
+	}
+	retry, err := ts.Service.CreateRetryRun(ctx, task.ID, run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retry.ID == run.ID || retry.RetryOf != run.ID || retry.Attempt != 2 || !retry.ScheduledFor.Equal(run.ScheduledFor) {
+		t.Fatalf("unexpected retry run %+v of run %+v", retry, run)
+	}
+	if retry.Status != influxdb.RunScheduled.String() {
+		t.Fatalf("expected retry run to be scheduled, got %q", retry.Status)
+	}
+
+	running, err := ts.Service.CurrentlyRunning(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(running) != 2 {
+		t.Fatalf("expected the failed run and its retry to be currently running, got %d runs", len(running))
+	}
+}
+
+func TestService_PruneRuns(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	ts := newService(t, ctx, nil)
+	defer ts.Close()
+
+	ctx = icontext.SetAuthorizer(ctx, &ts.Auth)
+
+	task, err := ts.Service.CreateTask(ctx, influxdb.TaskCreate{
+		Flux:           `option task = {name: "a task",every: 1h} from(bucket:"test") |> range(start:-1h)`,
+		OrganizationID: ts.Org.ID,
+		OwnerID:        ts.User.ID,
+		Status:         string(influxdb.TaskActive),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a run that failed but was never finished, one that is still executing,
+	// one that is due and a queued manual run
+	stale, err := ts.Service.CreateRun(ctx, task.ID, time.Unix(1000, 0), time.Unix(1000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.Service.UpdateRunState(ctx, task.ID, stale.ID, time.Unix(1000, 0), influxdb.RunFail); err != nil {
+		t.Fatal(err)
+	}
+	executing, err := ts.Service.CreateRun(ctx, task.ID, time.Unix(1100, 0), time.Unix(1100, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.Service.UpdateRunState(ctx, task.ID, executing.ID, time.Unix(1100, 0), influxdb.RunStarted); err != nil {
+		t.Fatal(err)
+	}
+	due := time.Now().Add(time.Hour).Truncate(time.Second)
+	current, err := ts.Service.CreateRun(ctx, task.ID, due, due)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Service.ForceRun(ctx, task.ID, 2000); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := ts.Service.PruneRuns(ctx, task.ID, time.Unix(2000, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 pruned run, got %d", n)
+	}
+	if _, err := ts.Service.FindRunByID(ctx, task.ID, stale.ID); err != influxdb.ErrRunNotFound {
+		t.Fatalf("expected the stale run to be pruned, got %v", err)
+	}
+
+	// the manual run was requested before now
+	n, err = ts.Service.PruneRuns(ctx, task.ID, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 pruned run, got %d", n)
+	}
+
+	runs, _, err := ts.Service.FindRuns(ctx, influxdb.RunFilter{Task: task.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept := map[influxdb.ID]bool{}
+	for _, r := range runs {
+		kept[r.ID] = true
+	}
+	if len(runs) != 2 || !kept[executing.ID] || !kept[current.ID] {
+		t.Fatalf("expected only the executing and due runs to be kept, got %v", runs)
+	}
+}
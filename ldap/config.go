@@ -0,0 +1,114 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/session"
+)
+
+const (
+	// DefaultUserFilter finds users by their uid.
+	DefaultUserFilter = "(uid=%s)"
+	// DefaultGroupAttribute is the attribute of users that lists the DNs of
+	// their groups.
+	DefaultGroupAttribute = "memberOf"
+	// DefaultGroupFilter finds the groups that list users as their members.
+	DefaultGroupFilter = "(member=%s)"
+	// DefaultTimeout is how long the requests to the directory may take.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Config configures the sign in of users against an LDAP directory.
+type Config struct {
+	// URL is the address of the directory, of the form ldap://host:port or
+	// ldaps://host:port.
+	URL string
+	// StartTLS upgrades ldap:// connections to TLS.
+	StartTLS bool
+	// InsecureSkipVerify skips the verification of the certificate of the
+	// directory, and CACertPath is the PEM file of the certificates that
+	// the certificate of the directory is verified with.
+	InsecureSkipVerify bool
+	CACertPath         string
+	Timeout            time.Duration
+
+	// BindDN and BindPassword are the credentials that users are searched
+	// with. Users are searched anonymously when there is no BindDN.
+	BindDN       string
+	BindPassword string
+
+	// UserBaseDN is where users are searched, with UserFilter, in which %s is
+	// the name of the user that signs in.
+	UserBaseDN string
+	UserFilter string
+
+	// GroupAttribute is the attribute of users that lists the DNs of their
+	// groups. When there is a GroupBaseDN, groups are searched there as well,
+	// with GroupFilter, in which %s is the DN of the user.
+	GroupAttribute string
+	GroupBaseDN    string
+	GroupFilter    string
+
+	// GroupMappings map the groups of users, by DN, to their roles in orgs.
+	GroupMappings []session.GroupMapping
+
+	// LocalUsers sign in with their local passwords rather than against the
+	// directory, such as the initial operator user, so that they can sign
+	// in while the directory is not available.
+	LocalUsers []string
+}
+
+// Enabled reports whether the sign in against a directory is configured.
+func (c Config) Enabled() bool {
+	return c.URL != ""
+}
+
+func (c Config) withDefaults() Config {
+	if c.UserFilter == "" {
+		c.UserFilter = DefaultUserFilter
+	}
+	if c.GroupAttribute == "" {
+		c.GroupAttribute = DefaultGroupAttribute
+	}
+	if c.GroupFilter == "" {
+		c.GroupFilter = DefaultGroupFilter
+	}
+	if c.Timeout == 0 {
+		c.Timeout = DefaultTimeout
+	}
+	return c
+}
+
+// tlsConfig returns the TLS configuration of the connections to the directory.
+func (c Config) tlsConfig() (*tls.Config, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LDAP URL %q: %v", c.URL, err)
+	}
+	switch u.Scheme {
+	case "ldap", "ldaps":
+	default:
+		return nil, fmt.Errorf("LDAP URL %q is not ldap:// or ldaps://", c.URL)
+	}
+
+	config := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CACertPath != "" {
+		pem, err := ioutil.ReadFile(c.CACertPath)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %q", c.CACertPath)
+		}
+	}
+	return config, nil
+}
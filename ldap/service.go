@@ -0,0 +1,223 @@
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/session"
+	"go.uber.org/zap"
+)
+
+// oauthIDPrefix prefixes the OAuth IDs of the users of the directory, which
+// are followed by their DNs.
+const oauthIDPrefix = "ldap#"
+
+// ErrInvalidCredentials is returned when the credentials of a sign in are not
+// valid.
+var ErrInvalidCredentials = &influxdb.Error{
+	Code: influxdb.EUnauthorized,
+	Msg:  "invalid username or password",
+}
+
+var _ influxdb.SignInService = (*SignInService)(nil)
+
+// SignInService signs users in against an LDAP directory. The users are
+// created the first time they sign in, and their memberships of orgs follow
+// their groups in the directory.
+type SignInService struct {
+	log    *zap.Logger
+	config Config
+	tls    *tls.Config
+	local  map[string]bool
+
+	userSvc influxdb.UserService
+	passSvc influxdb.PasswordsService
+	orgSvc  influxdb.OrganizationService
+	urmSvc  influxdb.UserResourceMappingService
+}
+
+// NewSignInService returns a new instance of SignInService.
+func NewSignInService(log *zap.Logger, config Config, userSvc influxdb.UserService, passSvc influxdb.PasswordsService, orgSvc influxdb.OrganizationService, urmSvc influxdb.UserResourceMappingService) (*SignInService, error) {
+	config = config.withDefaults()
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// DNs are not case sensitive, so neither are the groups of the mappings
+	mappings := make([]session.GroupMapping, len(config.GroupMappings))
+	for i, m := range config.GroupMappings {
+		m.Group = strings.ToLower(m.Group)
+		mappings[i] = m
+	}
+	config.GroupMappings = mappings
+
+	local := make(map[string]bool, len(config.LocalUsers))
+	for _, name := range config.LocalUsers {
+		local[name] = true
+	}
+
+	return &SignInService{
+		log:     log,
+		config:  config,
+		tls:     tlsConfig,
+		local:   local,
+		userSvc: userSvc,
+		passSvc: passSvc,
+		orgSvc:  orgSvc,
+		urmSvc:  urmSvc,
+	}, nil
+}
+
+// SignIn verifies the credentials against the directory, or against the local
+// password of the local users, and returns the user.
+func (s *SignInService) SignIn(ctx context.Context, username, password string) (*influxdb.User, error) {
+	// an empty password is an unauthenticated bind, which always succeeds
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+	if s.local[username] {
+		return s.signInLocal(ctx, username, password)
+	}
+
+	dn, groups, err := s.authenticate(username, password)
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.findOrCreateUser(ctx, username, dn)
+	if err != nil {
+		return nil, err
+	}
+	if err := session.SyncGroupRoles(ctx, s.log, s.orgSvc, s.urmSvc, u.ID, s.config.GroupMappings, groups); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (s *SignInService) signInLocal(ctx context.Context, username, password string) (*influxdb.User, error) {
+	u, err := s.userSvc.FindUser(ctx, influxdb.UserFilter{Name: &username})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.passSvc.ComparePassword(ctx, u.ID, password); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// authenticate binds to the directory as the user, and returns the DN of the
+// user and the DNs of their groups.
+func (s *SignInService) authenticate(username, password string) (string, []string, error) {
+	conn, err := s.dial()
+	if err != nil {
+		s.log.Error("Failed to connect to LDAP directory", zap.String("url", s.config.URL), zap.Error(err))
+		return "", nil, err
+	}
+	defer conn.Close()
+
+	if s.config.BindDN != "" {
+		if err := conn.Bind(s.config.BindDN, s.config.BindPassword); err != nil {
+			s.log.Error("Failed to bind to LDAP directory", zap.String("bindDN", s.config.BindDN), zap.Error(err))
+			return "", nil, err
+		}
+	}
+
+	res, err := conn.Search(goldap.NewSearchRequest(
+		s.config.UserBaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 2, 0, false,
+		fmt.Sprintf(s.config.UserFilter, goldap.EscapeFilter(username)),
+		[]string{s.config.GroupAttribute},
+		nil,
+	))
+	if err != nil && !goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) {
+		return "", nil, err
+	}
+	if res == nil || len(res.Entries) != 1 {
+		// names that are not unique cannot sign in
+		return "", nil, ErrInvalidCredentials
+	}
+	user := res.Entries[0]
+
+	if err := conn.Bind(user.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return "", nil, ErrInvalidCredentials
+		}
+		return "", nil, err
+	}
+
+	var groups []string
+	for _, g := range user.GetEqualFoldAttributeValues(s.config.GroupAttribute) {
+		groups = append(groups, strings.ToLower(g))
+	}
+	if s.config.GroupBaseDN != "" {
+		res, err := conn.Search(goldap.NewSearchRequest(
+			s.config.GroupBaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false,
+			fmt.Sprintf(s.config.GroupFilter, goldap.EscapeFilter(user.DN)),
+			[]string{"dn"},
+			nil,
+		))
+		if err != nil {
+			return "", nil, err
+		}
+		for _, e := range res.Entries {
+			groups = append(groups, strings.ToLower(e.DN))
+		}
+	}
+	return user.DN, groups, nil
+}
+
+func (s *SignInService) dial() (*goldap.Conn, error) {
+	conn, err := goldap.DialURL(s.config.URL,
+		goldap.DialWithDialer(&net.Dialer{Timeout: s.config.Timeout}),
+		goldap.DialWithTLSConfig(s.tls),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(s.config.Timeout)
+
+	if s.config.StartTLS && !strings.HasPrefix(s.config.URL, "ldaps://") {
+		if err := conn.StartTLS(s.tls); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// findOrCreateUser returns the user of the directory entry dn, which is
+// created the first time the user signs in. Users that were not created by
+// the directory cannot sign in with it.
+func (s *SignInService) findOrCreateUser(ctx context.Context, name, dn string) (*influxdb.User, error) {
+	oauthID := oauthIDPrefix + strings.ToLower(dn)
+
+	u, err := s.userSvc.FindUser(ctx, influxdb.UserFilter{Name: &name})
+	if err == nil {
+		if u.OAuthID != oauthID {
+			s.log.Info("User is not linked to LDAP directory", zap.String("user", name))
+			return nil, ErrInvalidCredentials
+		}
+		if u.Status == influxdb.Inactive {
+			return nil, ErrInvalidCredentials
+		}
+		return u, nil
+	}
+	if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return nil, err
+	}
+
+	u = &influxdb.User{
+		Name:    name,
+		OAuthID: oauthID,
+		Status:  influxdb.Active,
+	}
+	if err := s.userSvc.CreateUser(ctx, u); err != nil {
+		return nil, err
+	}
+	s.log.Info("Created user of LDAP directory", zap.String("user", name), zap.String("userID", u.ID.String()))
+	return u, nil
+}
@@ -0,0 +1,312 @@
+package ldap
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/session"
+	"github.com/influxdata/influxdb/v2/tenant"
+	"go.uber.org/zap/zaptest"
+)
+
+// testEntry is an entry of a testDirectory, with the password it binds with.
+type testEntry struct {
+	dn       string
+	password string
+	attrs    map[string][]string
+}
+
+// testDirectory is an in-process LDAP server, which serves the simple binds
+// and the equality searches of its entries.
+type testDirectory struct {
+	t        *testing.T
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	entries []testEntry
+}
+
+func newTestDirectory(t *testing.T, entries ...testEntry) *testDirectory {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &testDirectory{t: t, listener: l, entries: entries}
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			d.wg.Add(1)
+			go func() {
+				defer d.wg.Done()
+				d.serve(conn)
+			}()
+		}
+	}()
+	return d
+}
+
+func (d *testDirectory) URL() string {
+	return "ldap://" + d.listener.Addr().String()
+}
+
+func (d *testDirectory) Close() {
+	d.listener.Close()
+	d.wg.Wait()
+}
+
+func (d *testDirectory) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		p, err := ber.ReadPacket(conn)
+		if err != nil || len(p.Children) < 2 {
+			return
+		}
+		id, op := p.Children[0].Value, p.Children[1]
+		switch op.Tag {
+		case goldap.ApplicationBindRequest:
+			code := goldap.LDAPResultInvalidCredentials
+			dn, password := op.Children[1].Data.String(), op.Children[2].Data.String()
+			if e, ok := d.entry(dn); ok && password != "" && e.password == password {
+				code = goldap.LDAPResultSuccess
+			}
+			d.respond(conn, id, goldap.ApplicationBindResponse, code)
+		case goldap.ApplicationSearchRequest:
+			base := strings.ToLower(op.Children[0].Data.String())
+			limit := int(op.Children[3].Value.(int64))
+			code := goldap.LDAPResultSuccess
+			d.mu.Lock()
+			var found []testEntry
+			for _, e := range d.entries {
+				if strings.HasSuffix(strings.ToLower(e.dn), base) && match(e, op.Children[6]) {
+					found = append(found, e)
+				}
+			}
+			d.mu.Unlock()
+			if limit > 0 && len(found) > limit {
+				found, code = found[:limit], goldap.LDAPResultSizeLimitExceeded
+			}
+			for _, e := range found {
+				entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, goldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+				entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "DN"))
+				attrs := ber.NewSequence("Attributes")
+				for name, values := range e.attrs {
+					attr := ber.NewSequence("Attribute")
+					attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+					vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+					for _, v := range values {
+						vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "Value"))
+					}
+					attr.AppendChild(vals)
+					attrs.AppendChild(attr)
+				}
+				entry.AppendChild(attrs)
+				d.write(conn, id, entry)
+			}
+			d.respond(conn, id, goldap.ApplicationSearchResultDone, code)
+		default:
+			return
+		}
+	}
+}
+
+func (d *testDirectory) entry(dn string) (testEntry, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, e := range d.entries {
+		if strings.EqualFold(e.dn, dn) {
+			return e, true
+		}
+	}
+	return testEntry{}, false
+}
+
+// match reports whether e matches the filter, of equality matches and their
+// conjunctions and disjunctions.
+func match(e testEntry, filter *ber.Packet) bool {
+	switch filter.Tag {
+	case goldap.FilterAnd, goldap.FilterOr:
+		for _, f := range filter.Children {
+			if match(e, f) != (filter.Tag == goldap.FilterAnd) {
+				return filter.Tag != goldap.FilterAnd
+			}
+		}
+		return filter.Tag == goldap.FilterAnd
+	case goldap.FilterEqualityMatch:
+		name, value := filter.Children[0].Data.String(), filter.Children[1].Data.String()
+		for k, vs := range e.attrs {
+			if !strings.EqualFold(k, name) {
+				continue
+			}
+			for _, v := range vs {
+				if strings.EqualFold(v, value) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (d *testDirectory) respond(conn net.Conn, id interface{}, tag ber.Tag, code int) {
+	res := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	res.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	res.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	res.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	d.write(conn, id, res)
+}
+
+func (d *testDirectory) write(conn net.Conn, id interface{}, op *ber.Packet) {
+	p := ber.NewSequence("LDAP Message")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "Message ID"))
+	p.AppendChild(op)
+	if _, err := conn.Write(p.Bytes()); err != nil {
+		d.t.Log(err)
+	}
+}
+
+func TestSignInService(t *testing.T) {
+	const (
+		admins = "cn=Admins,ou=groups,dc=example,dc=com"
+		devs   = "cn=devs,ou=groups,dc=example,dc=com"
+		jane   = "uid=jane,ou=people,dc=example,dc=com"
+	)
+	dir := newTestDirectory(t,
+		testEntry{dn: "cn=influxdb,dc=example,dc=com", password: "service"},
+		testEntry{dn: jane, password: "secret", attrs: map[string][]string{
+			"uid":      {"jane"},
+			"memberOf": {admins},
+		}},
+		testEntry{dn: "uid=local,ou=people,dc=example,dc=com", password: "secret", attrs: map[string][]string{
+			"uid": {"local"},
+		}},
+		testEntry{dn: "uid=twin,ou=people,dc=example,dc=com", password: "secret", attrs: map[string][]string{"uid": {"twin"}}},
+		testEntry{dn: "uid=twin,ou=others,dc=example,dc=com", password: "secret", attrs: map[string][]string{"uid": {"twin"}}},
+		testEntry{dn: devs, attrs: map[string][]string{"member": {jane}}},
+	)
+	defer dir.Close()
+
+	ctx := context.Background()
+	st, err := tenant.NewStore(inmem.NewKVStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := tenant.NewService(st)
+	acme, other := &influxdb.Organization{Name: "acme"}, &influxdb.Organization{Name: "other"}
+	for _, o := range []*influxdb.Organization{acme, other} {
+		if err := ts.CreateOrganization(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	operator := &influxdb.User{Name: "operator", Status: influxdb.Active}
+	for _, u := range []*influxdb.User{operator, {Name: "local", Status: influxdb.Active}} {
+		if err := ts.CreateUser(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ts.SetPassword(ctx, operator.ID, "operator-password"); err != nil {
+		t.Fatal(err)
+	}
+
+	svc, err := NewSignInService(zaptest.NewLogger(t), Config{
+		URL:          dir.URL(),
+		BindDN:       "cn=influxdb,dc=example,dc=com",
+		BindPassword: "service",
+		UserBaseDN:   "dc=example,dc=com",
+		GroupBaseDN:  "ou=groups,dc=example,dc=com",
+		GroupMappings: []session.GroupMapping{
+			{Group: "cn=admins,ou=groups,dc=example,dc=com", Org: "acme", Role: influxdb.Owner},
+			{Group: devs, Org: "other", Role: influxdb.Member},
+		},
+		LocalUsers: []string{"operator"},
+	}, ts, ts, ts, ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	role := func(u *influxdb.User, o *influxdb.Organization) influxdb.UserType {
+		t.Helper()
+		urms, _, err := ts.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.OrgsResourceType,
+			ResourceID:   o.ID,
+			UserID:       u.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(urms) == 0 {
+			return ""
+		}
+		return urms[0].UserType
+	}
+
+	// the first sign in creates the user, with the roles of their groups
+	u, err := svc.SignIn(ctx, "jane", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "jane" || u.OAuthID != "ldap#"+jane {
+		t.Fatalf("unexpected user %+v", u)
+	}
+	if r := role(u, acme); r != influxdb.Owner {
+		t.Fatalf("expected the owner of acme, got %q", r)
+	}
+	if r := role(u, other); r != influxdb.Member {
+		t.Fatalf("expected a member of other, got %q", r)
+	}
+
+	// the roles follow the groups of the user at every sign in
+	dir.mu.Lock()
+	dir.entries[len(dir.entries)-1].attrs = nil
+	dir.mu.Unlock()
+	again, err := svc.SignIn(ctx, "jane", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != u.ID {
+		t.Fatalf("expected the user of the first sign in, got %v", again.ID)
+	}
+	if r := role(u, other); r != "" {
+		t.Fatalf("expected the user to be removed from other, got %q", r)
+	}
+
+	for _, tt := range []struct {
+		name, username, password string
+	}{
+		{name: "wrong password", username: "jane", password: "wrong"},
+		{name: "empty password", username: "jane", password: ""},
+		{name: "unknown user", username: "john", password: "secret"},
+		{name: "ambiguous user", username: "twin", password: "secret"},
+		{name: "filter injection", username: "*", password: "secret"},
+		{name: "local user not linked to the directory", username: "local", password: "secret"},
+		{name: "wrong local password", username: "operator", password: "secret"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.SignIn(ctx, tt.username, tt.password); err == nil {
+				t.Fatalf("expected %s not to sign in", tt.username)
+			}
+		})
+	}
+
+	// local users sign in with their local passwords, even without the directory
+	dir.Close()
+	u, err = svc.SignIn(ctx, "operator", "operator-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.ID != operator.ID {
+		t.Fatalf("expected the operator, got %v", u.ID)
+	}
+}
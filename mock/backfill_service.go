@@ -0,0 +1,56 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.BackfillService = &BackfillService{}
+
+// BackfillService is a mock backfill service.
+type BackfillService struct {
+	CreateBackfillF   func(ctx context.Context, taskID influxdb.ID, bc influxdb.BackfillCreate) (*influxdb.Backfill, error)
+	FindBackfillByIDF func(ctx context.Context, taskID, id influxdb.ID) (*influxdb.Backfill, error)
+	FindBackfillsF    func(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Backfill, error)
+	CancelBackfillF   func(ctx context.Context, taskID, id influxdb.ID) error
+}
+
+// NewBackfillService returns a mock BackfillService where its methods will
+// return zero values.
+func NewBackfillService() *BackfillService {
+	return &BackfillService{
+		CreateBackfillF: func(ctx context.Context, taskID influxdb.ID, bc influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+			return &influxdb.Backfill{TaskID: taskID, Start: bc.Start, End: bc.End, Parallelism: bc.Parallelism}, nil
+		},
+		FindBackfillByIDF: func(ctx context.Context, taskID, id influxdb.ID) (*influxdb.Backfill, error) {
+			return nil, influxdb.ErrBackfillNotFound
+		},
+		FindBackfillsF: func(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Backfill, error) {
+			return nil, nil
+		},
+		CancelBackfillF: func(ctx context.Context, taskID, id influxdb.ID) error {
+			return nil
+		},
+	}
+}
+
+// CreateBackfill calls CreateBackfillF.
+func (s *BackfillService) CreateBackfill(ctx context.Context, taskID influxdb.ID, bc influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+	return s.CreateBackfillF(ctx, taskID, bc)
+}
+
+// FindBackfillByID calls FindBackfillByIDF.
+func (s *BackfillService) FindBackfillByID(ctx context.Context, taskID, id influxdb.ID) (*influxdb.Backfill, error) {
+	return s.FindBackfillByIDF(ctx, taskID, id)
+}
+
+// FindBackfills calls FindBackfillsF.
+func (s *BackfillService) FindBackfills(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Backfill, error) {
+	return s.FindBackfillsF(ctx, taskID)
+}
+
+// CancelBackfill calls CancelBackfillF.
+func (s *BackfillService) CancelBackfill(ctx context.Context, taskID, id influxdb.ID) error {
+	return s.CancelBackfillF(ctx, taskID, id)
+}
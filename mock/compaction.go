@@ -0,0 +1,50 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.CompactionService = &CompactionService{}
+
+// CompactionService is a mock compaction service.
+type CompactionService struct {
+	CompactionStatusF   func(ctx context.Context) (*influxdb.CompactionStatus, error)
+	PauseCompactionsF   func(ctx context.Context) error
+	ResumeCompactionsF  func(ctx context.Context) error
+	ScheduleCompactionF func(ctx context.Context, typ influxdb.CompactionType) error
+}
+
+// NewCompactionService returns a mock CompactionService where its methods will
+// return zero values.
+func NewCompactionService() *CompactionService {
+	return &CompactionService{
+		CompactionStatusF: func(ctx context.Context) (*influxdb.CompactionStatus, error) {
+			return &influxdb.CompactionStatus{}, nil
+		},
+		PauseCompactionsF:   func(ctx context.Context) error { return nil },
+		ResumeCompactionsF:  func(ctx context.Context) error { return nil },
+		ScheduleCompactionF: func(ctx context.Context, typ influxdb.CompactionType) error { return nil },
+	}
+}
+
+// CompactionStatus calls CompactionStatusF.
+func (s *CompactionService) CompactionStatus(ctx context.Context) (*influxdb.CompactionStatus, error) {
+	return s.CompactionStatusF(ctx)
+}
+
+// PauseCompactions calls PauseCompactionsF.
+func (s *CompactionService) PauseCompactions(ctx context.Context) error {
+	return s.PauseCompactionsF(ctx)
+}
+
+// ResumeCompactions calls ResumeCompactionsF.
+func (s *CompactionService) ResumeCompactions(ctx context.Context) error {
+	return s.ResumeCompactionsF(ctx)
+}
+
+// ScheduleCompaction calls ScheduleCompactionF.
+func (s *CompactionService) ScheduleCompaction(ctx context.Context, typ influxdb.CompactionType) error {
+	return s.ScheduleCompactionF(ctx, typ)
+}
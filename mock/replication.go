@@ -0,0 +1,48 @@
+package mock
+
+import (
+	"context"
+	"io"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.ReplicationService = &ReplicationService{}
+
+// ReplicationService is a mock replication service.
+type ReplicationService struct {
+	ReplicationStatusF func(ctx context.Context) (*influxdb.ReplicationStatus, error)
+	WALPositionF       func(ctx context.Context) (*influxdb.WALPosition, error)
+	ApplyWALF          func(ctx context.Context, pos influxdb.WALPosition, r io.Reader) (*influxdb.WALPosition, error)
+}
+
+// NewReplicationService returns a mock ReplicationService where its methods will
+// return zero values.
+func NewReplicationService() *ReplicationService {
+	return &ReplicationService{
+		ReplicationStatusF: func(ctx context.Context) (*influxdb.ReplicationStatus, error) {
+			return &influxdb.ReplicationStatus{}, nil
+		},
+		WALPositionF: func(ctx context.Context) (*influxdb.WALPosition, error) {
+			return &influxdb.WALPosition{}, nil
+		},
+		ApplyWALF: func(ctx context.Context, pos influxdb.WALPosition, r io.Reader) (*influxdb.WALPosition, error) {
+			return &pos, nil
+		},
+	}
+}
+
+// ReplicationStatus calls ReplicationStatusF.
+func (s *ReplicationService) ReplicationStatus(ctx context.Context) (*influxdb.ReplicationStatus, error) {
+	return s.ReplicationStatusF(ctx)
+}
+
+// WALPosition calls WALPositionF.
+func (s *ReplicationService) WALPosition(ctx context.Context) (*influxdb.WALPosition, error) {
+	return s.WALPositionF(ctx)
+}
+
+// ApplyWAL calls ApplyWALF.
+func (s *ReplicationService) ApplyWAL(ctx context.Context, pos influxdb.WALPosition, r io.Reader) (*influxdb.WALPosition, error) {
+	return s.ApplyWALF(ctx, pos, r)
+}
@@ -0,0 +1,94 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	_ influxdb.RoleService           = (*RoleService)(nil)
+	_ influxdb.RolePermissionService = (*RoleService)(nil)
+)
+
+// RoleService is a mock implementation of influxdb.RoleService. The methods
+// without a func return zero values.
+type RoleService struct {
+	FindRoleByIDFn        func(ctx context.Context, id influxdb.ID) (*influxdb.Role, error)
+	FindRolesFn           func(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error)
+	CreateRoleFn          func(ctx context.Context, r *influxdb.Role) error
+	UpdateRoleFn          func(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error)
+	DeleteRoleFn          func(ctx context.Context, id influxdb.ID) error
+	AssignRoleFn          func(ctx context.Context, a *influxdb.RoleAssignment) error
+	UnassignRoleFn        func(ctx context.Context, a influxdb.RoleAssignment) error
+	FindRoleAssignmentsFn func(ctx context.Context, filter influxdb.RoleAssignmentFilter) ([]*influxdb.RoleAssignment, error)
+	FindRolePermissionsFn func(ctx context.Context, rt influxdb.ResourceType, id influxdb.ID) ([]influxdb.Permission, error)
+}
+
+// NewRoleService returns a mock of RoleService where its methods will return zero values.
+func NewRoleService() *RoleService {
+	return &RoleService{}
+}
+
+func (s *RoleService) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	if s.FindRoleByIDFn == nil {
+		return nil, nil
+	}
+	return s.FindRoleByIDFn(ctx, id)
+}
+
+func (s *RoleService) FindRoles(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+	if s.FindRolesFn == nil {
+		return nil, 0, nil
+	}
+	return s.FindRolesFn(ctx, filter, opt...)
+}
+
+func (s *RoleService) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	if s.CreateRoleFn == nil {
+		return nil
+	}
+	return s.CreateRoleFn(ctx, r)
+}
+
+func (s *RoleService) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	if s.UpdateRoleFn == nil {
+		return nil, nil
+	}
+	return s.UpdateRoleFn(ctx, id, upd)
+}
+
+func (s *RoleService) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	if s.DeleteRoleFn == nil {
+		return nil
+	}
+	return s.DeleteRoleFn(ctx, id)
+}
+
+func (s *RoleService) AssignRole(ctx context.Context, a *influxdb.RoleAssignment) error {
+	if s.AssignRoleFn == nil {
+		return nil
+	}
+	return s.AssignRoleFn(ctx, a)
+}
+
+func (s *RoleService) UnassignRole(ctx context.Context, a influxdb.RoleAssignment) error {
+	if s.UnassignRoleFn == nil {
+		return nil
+	}
+	return s.UnassignRoleFn(ctx, a)
+}
+
+func (s *RoleService) FindRoleAssignments(ctx context.Context, filter influxdb.RoleAssignmentFilter) ([]*influxdb.RoleAssignment, error) {
+	if s.FindRoleAssignmentsFn == nil {
+		return nil, nil
+	}
+	return s.FindRoleAssignmentsFn(ctx, filter)
+}
+
+func (s *RoleService) FindRolePermissions(ctx context.Context, rt influxdb.ResourceType, id influxdb.ID) ([]influxdb.Permission, error) {
+	if s.FindRolePermissionsFn == nil {
+		return nil, nil
+	}
+	return s.FindRolePermissionsFn(ctx, rt, id)
+}
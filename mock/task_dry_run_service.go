@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.TaskDryRunService = &TaskDryRunService{}
+
+// TaskDryRunService is a mock task dry run service.
+type TaskDryRunService struct {
+	DryRunTaskF func(ctx context.Context, taskID influxdb.ID, req influxdb.TaskDryRunRequest) (*influxdb.TaskDryRun, error)
+}
+
+// NewTaskDryRunService returns a mock TaskDryRunService where its methods will
+// return zero values.
+func NewTaskDryRunService() *TaskDryRunService {
+	return &TaskDryRunService{
+		DryRunTaskF: func(ctx context.Context, taskID influxdb.ID, req influxdb.TaskDryRunRequest) (*influxdb.TaskDryRun, error) {
+			return &influxdb.TaskDryRun{TaskID: taskID, ScheduledFor: req.ScheduledFor}, nil
+		},
+	}
+}
+
+// DryRunTask calls DryRunTaskF.
+func (s *TaskDryRunService) DryRunTask(ctx context.Context, taskID influxdb.ID, req influxdb.TaskDryRunRequest) (*influxdb.TaskDryRun, error) {
+	return s.DryRunTaskF(ctx, taskID, req)
+}
@@ -5,11 +5,9 @@ import (
 	"time"
 
 	"github.com/influxdata/influxdb/v2"
-	"github.com/influxdata/influxdb/v2/task/backend"
 )
 
 var _ influxdb.TaskService = (*TaskService)(nil)
-var _ backend.TaskControlService = (*TaskControlService)(nil)
 
 type TaskService struct {
 	FindTaskByIDFn    func(context.Context, influxdb.ID) (*influxdb.Task, error)
@@ -130,18 +128,27 @@ func (s *TaskService) ForceRun(ctx context.Context, taskID influxdb.ID, schedule
 }
 
 type TaskControlService struct {
-	CreateRunFn        func(ctx context.Context, taskID influxdb.ID, scheduledFor time.Time, runAt time.Time) (*influxdb.Run, error)
-	CurrentlyRunningFn func(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Run, error)
-	ManualRunsFn       func(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Run, error)
-	StartManualRunFn   func(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error)
-	FinishRunFn        func(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error)
-	UpdateRunStateFn   func(ctx context.Context, taskID, runID influxdb.ID, when time.Time, state influxdb.RunStatus) error
-	AddRunLogFn        func(ctx context.Context, taskID, runID influxdb.ID, when time.Time, log string) error
+	CreateRunFn          func(ctx context.Context, taskID influxdb.ID, scheduledFor time.Time, runAt time.Time) (*influxdb.Run, error)
+	CreateRetryRunFn     func(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error)
+	CreateTriggeredRunFn func(ctx context.Context, taskID influxdb.ID, trigger influxdb.RunTrigger) (*influxdb.Run, error)
+	CurrentlyRunningFn   func(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Run, error)
+	ManualRunsFn         func(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Run, error)
+	StartManualRunFn     func(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error)
+	FinishRunFn          func(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error)
+	CountRunResultFn     func(ctx context.Context, taskID influxdb.ID, state influxdb.RunStatus) (int, error)
+	UpdateRunStateFn     func(ctx context.Context, taskID, runID influxdb.ID, when time.Time, state influxdb.RunStatus) error
+	AddRunLogFn          func(ctx context.Context, taskID, runID influxdb.ID, when time.Time, log string) error
 }
 
 func (tcs *TaskControlService) CreateRun(ctx context.Context, taskID influxdb.ID, scheduledFor time.Time, runAt time.Time) (*influxdb.Run, error) {
 	return tcs.CreateRunFn(ctx, taskID, scheduledFor, runAt)
 }
+func (tcs *TaskControlService) CreateRetryRun(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error) {
+	return tcs.CreateRetryRunFn(ctx, taskID, runID)
+}
+func (tcs *TaskControlService) CreateTriggeredRun(ctx context.Context, taskID influxdb.ID, trigger influxdb.RunTrigger) (*influxdb.Run, error) {
+	return tcs.CreateTriggeredRunFn(ctx, taskID, trigger)
+}
 func (tcs *TaskControlService) CurrentlyRunning(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Run, error) {
 	return tcs.CurrentlyRunningFn(ctx, taskID)
 }
@@ -154,6 +161,9 @@ func (tcs *TaskControlService) StartManualRun(ctx context.Context, taskID, runID
 func (tcs *TaskControlService) FinishRun(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error) {
 	return tcs.FinishRunFn(ctx, taskID, runID)
 }
+func (tcs *TaskControlService) CountRunResult(ctx context.Context, taskID influxdb.ID, state influxdb.RunStatus) (int, error) {
+	return tcs.CountRunResultFn(ctx, taskID, state)
+}
 func (tcs *TaskControlService) UpdateRunState(ctx context.Context, taskID, runID influxdb.ID, when time.Time, state influxdb.RunStatus) error {
 	return tcs.UpdateRunStateFn(ctx, taskID, runID, when, state)
 }
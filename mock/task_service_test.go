@@ -0,0 +1,10 @@
+package mock_test
+
+import (
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/task/backend"
+)
+
+// the mock package cannot import task/backend, which depends on packages that
+// test with the mocks.
+var _ backend.TaskControlService = (*mock.TaskControlService)(nil)
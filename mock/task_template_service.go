@@ -0,0 +1,74 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+var _ influxdb.TaskTemplateService = &TaskTemplateService{}
+
+// TaskTemplateService is a mock task template service.
+type TaskTemplateService struct {
+	FindTaskTemplateByIDF      func(ctx context.Context, id influxdb.ID) (*influxdb.TaskTemplate, error)
+	FindTaskTemplatesF         func(ctx context.Context, filter influxdb.TaskTemplateFilter) ([]*influxdb.TaskTemplate, int, error)
+	CreateTaskTemplateF        func(ctx context.Context, t *influxdb.TaskTemplate) error
+	UpdateTaskTemplateF        func(ctx context.Context, id influxdb.ID, upd influxdb.TaskTemplateUpdate) (*influxdb.TaskTemplate, error)
+	PreviewTaskTemplateUpdateF func(ctx context.Context, id influxdb.ID, upd influxdb.TaskTemplateUpdate) ([]*influxdb.TaskTemplateDiff, error)
+	DeleteTaskTemplateF        func(ctx context.Context, id influxdb.ID) error
+}
+
+// NewTaskTemplateService returns a mock TaskTemplateService where its methods will
+// return zero values.
+func NewTaskTemplateService() *TaskTemplateService {
+	return &TaskTemplateService{
+		FindTaskTemplateByIDF: func(ctx context.Context, id influxdb.ID) (*influxdb.TaskTemplate, error) {
+			return nil, influxdb.ErrTaskTemplateNotFound
+		},
+		FindTaskTemplatesF: func(ctx context.Context, filter influxdb.TaskTemplateFilter) ([]*influxdb.TaskTemplate, int, error) {
+			return nil, 0, nil
+		},
+		CreateTaskTemplateF: func(ctx context.Context, t *influxdb.TaskTemplate) error {
+			return nil
+		},
+		UpdateTaskTemplateF: func(ctx context.Context, id influxdb.ID, upd influxdb.TaskTemplateUpdate) (*influxdb.TaskTemplate, error) {
+			return nil, influxdb.ErrTaskTemplateNotFound
+		},
+		PreviewTaskTemplateUpdateF: func(ctx context.Context, id influxdb.ID, upd influxdb.TaskTemplateUpdate) ([]*influxdb.TaskTemplateDiff, error) {
+			return nil, influxdb.ErrTaskTemplateNotFound
+		},
+		DeleteTaskTemplateF: func(ctx context.Context, id influxdb.ID) error {
+			return nil
+		},
+	}
+}
+
+// FindTaskTemplateByID calls FindTaskTemplateByIDF.
+func (s *TaskTemplateService) FindTaskTemplateByID(ctx context.Context, id influxdb.ID) (*influxdb.TaskTemplate, error) {
+	return s.FindTaskTemplateByIDF(ctx, id)
+}
+
+// FindTaskTemplates calls FindTaskTemplatesF.
+func (s *TaskTemplateService) FindTaskTemplates(ctx context.Context, filter influxdb.TaskTemplateFilter) ([]*influxdb.TaskTemplate, int, error) {
+	return s.FindTaskTemplatesF(ctx, filter)
+}
+
+// CreateTaskTemplate calls CreateTaskTemplateF.
+func (s *TaskTemplateService) CreateTaskTemplate(ctx context.Context, t *influxdb.TaskTemplate) error {
+	return s.CreateTaskTemplateF(ctx, t)
+}
+
+// UpdateTaskTemplate calls UpdateTaskTemplateF.
+func (s *TaskTemplateService) UpdateTaskTemplate(ctx context.Context, id influxdb.ID, upd influxdb.TaskTemplateUpdate) (*influxdb.TaskTemplate, error) {
+	return s.UpdateTaskTemplateF(ctx, id, upd)
+}
+
+// PreviewTaskTemplateUpdate calls PreviewTaskTemplateUpdateF.
+func (s *TaskTemplateService) PreviewTaskTemplateUpdate(ctx context.Context, id influxdb.ID, upd influxdb.TaskTemplateUpdate) ([]*influxdb.TaskTemplateDiff, error) {
+	return s.PreviewTaskTemplateUpdateF(ctx, id, upd)
+}
+
+// DeleteTaskTemplate calls DeleteTaskTemplateF.
+func (s *TaskTemplateService) DeleteTaskTemplate(ctx context.Context, id influxdb.ID) error {
+	return s.DeleteTaskTemplateF(ctx, id)
+}
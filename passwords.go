@@ -13,3 +13,11 @@ type PasswordsService interface {
 	// updates to the new password.
 	CompareAndSetPassword(ctx context.Context, userID ID, old, new string) error
 }
+
+// SignInService verifies the credentials of users that sign in, such as
+// against an external directory, rather than with their local passwords.
+type SignInService interface {
+	// SignIn returns the user of the credentials. Credentials that are not
+	// valid return errors.
+	SignIn(ctx context.Context, username, password string) (*User, error)
+}
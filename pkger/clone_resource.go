@@ -57,6 +57,7 @@ var kindPriorities = map[Kind]int{
 	KindVariable:                      12,
 	KindDashboard:                     13,
 	KindTelegraf:                      14,
+	KindRole:                          15,
 }
 
 type exportKey struct {
@@ -84,6 +85,7 @@ type resourceExporter struct {
 	labelSVC    influxdb.LabelService
 	endpointSVC influxdb.NotificationEndpointService
 	ruleSVC     influxdb.NotificationRuleStore
+	roleSVC     influxdb.RoleService
 	taskSVC     influxdb.TaskService
 	teleSVC     influxdb.TelegrafConfigStore
 	varSVC      influxdb.VariableService
@@ -101,6 +103,7 @@ func newResourceExporter(svc *Service) *resourceExporter {
 		labelSVC:    svc.labelSVC,
 		endpointSVC: svc.endpointSVC,
 		ruleSVC:     svc.ruleSVC,
+		roleSVC:     svc.roleSVC,
 		taskSVC:     svc.taskSVC,
 		teleSVC:     svc.teleSVC,
 		varSVC:      svc.varSVC,
@@ -248,6 +251,12 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		endpointObjectName := object.Name()
 
 		mapResource(rule.GetOrgID(), rule.GetID(), KindNotificationRule, NotificationRuleToObject(r.Name, endpointObjectName, rule))
+	case r.Kind.is(KindRole):
+		role, err := ex.roleSVC.FindRoleByID(ctx, r.ID)
+		if err != nil {
+			return err
+		}
+		mapResource(role.OrgID, uniqByNameResID, KindRole, RoleToObject(r.Name, *role))
 	case r.Kind.is(KindTask):
 		t, err := ex.taskSVC.FindTaskByID(ctx, r.ID)
 		if err != nil {
@@ -922,6 +931,26 @@ func TaskToObject(name string, t influxdb.Task) Object {
 	query := strings.TrimSpace(taskFluxRegex.ReplaceAllString(t.Flux, ""))
 
 	o := newObject(KindTask, name)
+	if n := t.Notification; n != nil {
+		notification := Resource{fieldTaskNotificationEndpointID: n.EndpointID.String()}
+		if n.Failures > 0 {
+			notification[fieldTaskNotificationFailures] = n.Failures
+		}
+		if n.Recovery {
+			notification[fieldTaskNotificationRecovery] = n.Recovery
+		}
+		o.Spec[fieldTaskNotification] = notification
+	}
+	if t.TemplateID.Valid() {
+		assignNonZeroStrings(o.Spec, map[string]string{
+			fieldDescription:    t.Description,
+			fieldTaskTemplateID: t.TemplateID.String(),
+		})
+		if len(t.TemplateParams) > 0 {
+			o.Spec[fieldTaskParams] = t.TemplateParams
+		}
+		return o
+	}
 	assignNonZeroStrings(o.Spec, map[string]string{
 		fieldTaskCron:    t.Cron,
 		fieldDescription: t.Description,
@@ -946,6 +975,32 @@ func TelegrafToObject(name string, t influxdb.TelegrafConfig) Object {
 	return o
 }
 
+// RoleToObject converts an influxdb.Role to a pkger.Object. The resources of
+// its permissions are of the org the role is applied to, so the org is dropped.
+func RoleToObject(name string, r influxdb.Role) Object {
+	if name == "" {
+		name = r.Name
+	}
+
+	o := newObject(KindRole, name)
+	assignNonZeroStrings(o.Spec, map[string]string{fieldDescription: r.Description})
+
+	perms := make([]Resource, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		perm := Resource{
+			fieldRolePermissionAction: string(p.Action),
+			fieldType:                 string(p.Resource.Type),
+		}
+		if p.Resource.ID != nil && p.Resource.Type != influxdb.OrgsResourceType {
+			perm[fieldRolePermissionID] = p.Resource.ID.String()
+		}
+		perms = append(perms, perm)
+	}
+	o.Spec[fieldRolePermissions] = perms
+
+	return o
+}
+
 // VariableToObject converts an influxdb.Variable to a pkger.Object.
 func VariableToObject(name string, v influxdb.Variable) Object {
 	if name == "" {
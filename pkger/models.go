@@ -26,6 +26,7 @@ const (
 	KindNotificationEndpointSlack     Kind = "NotificationEndpointSlack"
 	KindNotificationRule              Kind = "NotificationRule"
 	KindPackage                       Kind = "Package"
+	KindRole                          Kind = "Role"
 	KindTask                          Kind = "Task"
 	KindTelegraf                      Kind = "Telegraf"
 	KindVariable                      Kind = "Variable"
@@ -43,6 +44,7 @@ var kinds = map[Kind]bool{
 	KindNotificationEndpointPagerDuty: true,
 	KindNotificationEndpointSlack:     true,
 	KindNotificationRule:              true,
+	KindRole:                          true,
 	KindTask:                          true,
 	KindTelegraf:                      true,
 	KindVariable:                      true,
@@ -91,6 +93,8 @@ func (k Kind) ResourceType() influxdb.ResourceType {
 		return influxdb.NotificationEndpointResourceType
 	case KindNotificationRule:
 		return influxdb.NotificationRuleResourceType
+	case KindRole:
+		return influxdb.RolesResourceType
 	case KindTask:
 		return influxdb.TasksResourceType
 	case KindTelegraf:
@@ -151,6 +155,7 @@ type Diff struct {
 	LabelMappings         []DiffLabelMapping         `json:"labelMappings"`
 	NotificationEndpoints []DiffNotificationEndpoint `json:"notificationEndpoints"`
 	NotificationRules     []DiffNotificationRule     `json:"notificationRules"`
+	Roles                 []DiffRole                 `json:"roles"`
 	Tasks                 []DiffTask                 `json:"tasks"`
 	Telegrafs             []DiffTelegraf             `json:"telegrafConfigs"`
 	Variables             []DiffVariable             `json:"variables"`
@@ -171,6 +176,12 @@ func (d Diff) HasConflicts() bool {
 		}
 	}
 
+	for _, r := range d.Roles {
+		if r.hasConflict() {
+			return true
+		}
+	}
+
 	for _, v := range d.Variables {
 		if v.hasConflict() {
 			return true
@@ -359,6 +370,27 @@ type (
 	}
 )
 
+type (
+	// DiffRole is a diff of an individual role.
+	DiffRole struct {
+		DiffIdentifier
+
+		New DiffRoleValues  `json:"new"`
+		Old *DiffRoleValues `json:"old"`
+	}
+
+	// DiffRoleValues are the varying values for a role.
+	DiffRoleValues struct {
+		Name        string                  `json:"name"`
+		Description string                  `json:"description"`
+		Permissions []SummaryRolePermission `json:"permissions"`
+	}
+)
+
+func (d DiffRole) hasConflict() bool {
+	return !d.IsNew() && d.Old != nil && !reflect.DeepEqual(*d.Old, d.New)
+}
+
 type (
 	// DiffTask is a diff of an individual task.
 	DiffTask struct {
@@ -370,13 +402,17 @@ type (
 
 	// DiffTaskValues are the values for an individual task.
 	DiffTaskValues struct {
-		Name        string          `json:"name"`
-		Cron        string          `json:"cron"`
-		Description string          `json:"description"`
-		Every       string          `json:"every"`
-		Offset      string          `json:"offset"`
-		Query       string          `json:"query"`
-		Status      influxdb.Status `json:"status"`
+		Name        string                 `json:"name"`
+		Cron        string                 `json:"cron"`
+		Description string                 `json:"description"`
+		Every       string                 `json:"every"`
+		Offset      string                 `json:"offset"`
+		Query       string                 `json:"query"`
+		Status      influxdb.Status        `json:"status"`
+		TemplateID  SafeID                 `json:"templateID,omitempty"`
+		Params      map[string]interface{} `json:"params,omitempty"`
+
+		Notification *influxdb.TaskNotification `json:"notification,omitempty"`
 	}
 )
 
@@ -421,6 +457,7 @@ type Summary struct {
 	LabelMappings         []SummaryLabelMapping         `json:"labelMappings"`
 	MissingEnvs           []string                      `json:"missingEnvRefs"`
 	MissingSecrets        []string                      `json:"missingSecrets"`
+	Roles                 []SummaryRole                 `json:"roles"`
 	Tasks                 []SummaryTask                 `json:"summaryTask"`
 	TelegrafConfigs       []SummaryTelegraf             `json:"telegrafConfigs"`
 	Variables             []SummaryVariable             `json:"variables"`
@@ -611,6 +648,24 @@ type SummaryLabelMapping struct {
 	LabelID         SafeID                `json:"labelID"`
 }
 
+// SummaryRole provides a summary of a pkg role.
+type SummaryRole struct {
+	ID          SafeID                  `json:"id,omitempty"`
+	OrgID       SafeID                  `json:"orgID,omitempty"`
+	PkgName     string                  `json:"pkgName"`
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Permissions []SummaryRolePermission `json:"permissions"`
+}
+
+// SummaryRolePermission provides a summary of a permission of a pkg role. The
+// resources of the permission are always of the org the role is applied to.
+type SummaryRolePermission struct {
+	Action       influxdb.Action       `json:"action"`
+	ResourceType influxdb.ResourceType `json:"resourceType"`
+	ResourceID   SafeID                `json:"resourceID,omitempty"`
+}
+
 // SummaryTask provides a summary of a task.
 type SummaryTask struct {
 	ID          SafeID          `json:"id"`
@@ -623,6 +678,11 @@ type SummaryTask struct {
 	Query       string          `json:"query"`
 	Status      influxdb.Status `json:"status"`
 
+	TemplateID SafeID                 `json:"templateID,omitempty"`
+	Params     map[string]interface{} `json:"params,omitempty"`
+
+	Notification *influxdb.TaskNotification `json:"notification,omitempty"`
+
 	LabelAssociations []SummaryLabel `json:"labelAssociations"`
 }
 
@@ -281,6 +281,7 @@ type Pkg struct {
 	mDashboards            map[string]*dashboard
 	mNotificationEndpoints map[string]*notificationEndpoint
 	mNotificationRules     map[string]*notificationRule
+	mRoles                 map[string]*role
 	mTasks                 map[string]*task
 	mTelegrafs             map[string]*telegraf
 	mVariables             map[string]*variable
@@ -338,6 +339,7 @@ func (p *Pkg) Summary() Summary {
 		Labels:                []SummaryLabel{},
 		MissingEnvs:           p.missingEnvRefs(),
 		MissingSecrets:        p.missingSecrets(),
+		Roles:                 []SummaryRole{},
 		Tasks:                 []SummaryTask{},
 		TelegrafConfigs:       []SummaryTelegraf{},
 		Variables:             []SummaryVariable{},
@@ -378,6 +380,10 @@ func (p *Pkg) Summary() Summary {
 		sum.NotificationRules = append(sum.NotificationRules, r.summarize())
 	}
 
+	for _, r := range p.roles() {
+		sum.Roles = append(sum.Roles, r.summarize())
+	}
+
 	for _, t := range p.tasks() {
 		sum.Tasks = append(sum.Tasks, t.summarize())
 	}
@@ -440,6 +446,9 @@ func (p *Pkg) Contains(k Kind, pkgName string) bool {
 	case KindNotificationRule:
 		_, ok := p.mNotificationRules[pkgName]
 		return ok
+	case KindRole:
+		_, ok := p.mRoles[pkgName]
+		return ok
 	case KindTask:
 		_, ok := p.mTasks[pkgName]
 		return ok
@@ -590,6 +599,15 @@ func (p *Pkg) notificationRules() []*notificationRule {
 	return rules
 }
 
+func (p *Pkg) roles() []*role {
+	roles := make([]*role, 0, len(p.mRoles))
+	for _, r := range p.mRoles {
+		roles = append(roles, r)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].PkgName() < roles[j].PkgName() })
+	return roles
+}
+
 func (p *Pkg) missingEnvRefs() []string {
 	envRefs := make([]string, 0)
 	for envRef, matching := range p.mEnv {
@@ -708,6 +726,7 @@ func (p *Pkg) graphResources() error {
 		p.graphDashboards,
 		p.graphNotificationEndpoints,
 		p.graphNotificationRules,
+		p.graphRoles,
 		p.graphTasks,
 		p.graphTelegrafs,
 	}
@@ -1025,6 +1044,35 @@ func (p *Pkg) graphNotificationRules() *parseErr {
 	})
 }
 
+func (p *Pkg) graphRoles() *parseErr {
+	p.mRoles = make(map[string]*role)
+	tracker := p.trackNames(true)
+	return p.eachResource(KindRole, func(o Object) []validationErr {
+		ident, errs := tracker(o)
+		if len(errs) > 0 {
+			return errs
+		}
+
+		newRole := &role{
+			identity:    ident,
+			Description: o.Spec.stringShort(fieldDescription),
+		}
+
+		for _, perm := range o.Spec.slcResource(fieldRolePermissions) {
+			newRole.Permissions = append(newRole.Permissions, rolePermission{
+				Action:       influxdb.Action(normStr(perm.stringShort(fieldRolePermissionAction))),
+				ResourceType: influxdb.ResourceType(strings.TrimSpace(perm.stringShort(fieldType))),
+				ResourceID:   strings.TrimSpace(perm.stringShort(fieldRolePermissionID)),
+			})
+		}
+
+		p.mRoles[newRole.PkgName()] = newRole
+		p.setRefs(newRole.name, newRole.displayName)
+
+		return newRole.valid()
+	})
+}
+
 func (p *Pkg) graphTasks() *parseErr {
 	p.mTasks = make(map[string]*task)
 	tracker := p.trackNames(false)
@@ -1042,6 +1090,17 @@ func (p *Pkg) graphTasks() *parseErr {
 			offset:      o.Spec.durationShort(fieldOffset),
 			query:       strings.TrimSpace(o.Spec.stringShort(fieldQuery)),
 			status:      normStr(o.Spec.stringShort(fieldStatus)),
+			templateID:  o.Spec.stringShort(fieldTaskTemplateID),
+		}
+		if params, ok := ifaceToResource(o.Spec[fieldTaskParams]); ok {
+			t.params = params
+		}
+		if n, ok := ifaceToResource(o.Spec[fieldTaskNotification]); ok {
+			t.notification = &taskNotification{
+				endpointID: n.stringShort(fieldTaskNotificationEndpointID),
+				failures:   n.intShort(fieldTaskNotificationFailures),
+				recovery:   n.boolShort(fieldTaskNotificationRecovery),
+			}
 		}
 
 		failures := p.parseNestedLabels(o.Spec, func(l *label) error {
@@ -1662,7 +1662,131 @@ func toSummaryTagRules(tagRules []struct{ k, v, op string }) []SummaryTagRule {
 }
 
 const (
-	fieldTaskCron = "cron"
+	fieldRolePermissions      = "permissions"
+	fieldRolePermissionAction = "action"
+	fieldRolePermissionID     = "id"
+)
+
+type role struct {
+	identity
+
+	Description string
+	Permissions []rolePermission
+}
+
+// rolePermission is a permission of a role. A role only has permissions for
+// the resources of the org it is applied to, so the org is not part of it.
+type rolePermission struct {
+	Action       influxdb.Action
+	ResourceType influxdb.ResourceType
+	ResourceID   string
+}
+
+func (r *role) ResourceType() influxdb.ResourceType {
+	return KindRole.ResourceType()
+}
+
+func (r *role) summarize() SummaryRole {
+	return SummaryRole{
+		PkgName:     r.PkgName(),
+		Name:        r.Name(),
+		Description: r.Description,
+		Permissions: r.summaryPermissions(),
+	}
+}
+
+func (r *role) summaryPermissions() []SummaryRolePermission {
+	out := make([]SummaryRolePermission, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		sp := SummaryRolePermission{
+			Action:       p.Action,
+			ResourceType: p.ResourceType,
+		}
+		if id, err := influxdb.IDFromString(p.ResourceID); err == nil {
+			sp.ResourceID = SafeID(*id)
+		}
+		out = append(out, sp)
+	}
+	return out
+}
+
+// influxPermissions provides the permissions of the role for the resources
+// of the org.
+func (r *role) influxPermissions(orgID influxdb.ID) []influxdb.Permission {
+	out := make([]influxdb.Permission, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		orgID := orgID
+		perm := influxdb.Permission{
+			Action:   p.Action,
+			Resource: influxdb.Resource{Type: p.ResourceType},
+		}
+		switch {
+		case p.ResourceType == influxdb.OrgsResourceType:
+			perm.Resource.ID = &orgID
+		default:
+			perm.Resource.OrgID = &orgID
+			if id, err := influxdb.IDFromString(p.ResourceID); err == nil {
+				perm.Resource.ID = id
+			}
+		}
+		out = append(out, perm)
+	}
+	return out
+}
+
+func (r *role) valid() []validationErr {
+	var failures []validationErr
+	if err, ok := isValidName(r.Name(), 1); !ok {
+		failures = append(failures, err)
+	}
+
+	for i, p := range r.Permissions {
+		var ff []validationErr
+		if p.Action != influxdb.ReadAction && p.Action != influxdb.WriteAction {
+			ff = append(ff, validationErr{
+				Field: fieldRolePermissionAction,
+				Msg:   fmt.Sprintf(`action must be either "read" or "write"; got %q`, p.Action),
+			})
+		}
+		if err := p.ResourceType.Valid(); err != nil {
+			ff = append(ff, validationErr{
+				Field: fieldType,
+				Msg:   fmt.Sprintf("type %q is not a valid resource type", p.ResourceType),
+			})
+		}
+		if p.ResourceID != "" {
+			if _, err := influxdb.IDFromString(p.ResourceID); err != nil {
+				ff = append(ff, validationErr{
+					Field: fieldRolePermissionID,
+					Msg:   "id must be a valid resource id",
+				})
+			}
+		}
+		if len(ff) > 0 {
+			failures = append(failures, validationErr{
+				Field:  fieldRolePermissions,
+				Index:  intPtr(i),
+				Nested: ff,
+			})
+		}
+	}
+
+	if len(failures) > 0 {
+		return []validationErr{
+			objectValidationErr(fieldSpec, failures...),
+		}
+	}
+	return nil
+}
+
+const (
+	fieldTaskCron                   = "cron"
+	fieldTaskNotification           = "notification"
+	fieldTaskNotificationEndpointID = "endpointID"
+	fieldTaskNotificationFailures   = "failures"
+	fieldTaskNotificationRecovery   = "recovery"
+	fieldTaskParams                 = "params"
+	fieldTaskTemplateID             = "templateID"
 )
 
 type task struct {
@@ -1675,9 +1799,23 @@ type task struct {
 	query       string
 	status      string
 
+	// a task instantiated from a template takes its flux, and schedule,
+	// from the template rendered with params.
+	templateID string
+	params     map[string]interface{}
+
+	notification *taskNotification
+
 	labels sortedLabels
 }
 
+// taskNotification is the notification endpoint a task notifies of its failures.
+type taskNotification struct {
+	endpointID string
+	failures   int
+	recovery   bool
+}
+
 func (t *task) Labels() []*label {
 	return t.labels
 }
@@ -1704,6 +1842,28 @@ func (t *task) flux() string {
 	return translator.flux()
 }
 
+// TemplateID returns the ID of the template the task is instantiated from, if any.
+func (t *task) TemplateID() influxdb.ID {
+	var id influxdb.ID
+	if t.templateID != "" {
+		_ = id.DecodeFromString(t.templateID)
+	}
+	return id
+}
+
+// Notification returns the notification of the failures of the task, if any.
+func (t *task) Notification() *influxdb.TaskNotification {
+	if t.notification == nil {
+		return nil
+	}
+	n := &influxdb.TaskNotification{
+		Failures: t.notification.failures,
+		Recovery: t.notification.recovery,
+	}
+	_ = n.EndpointID.DecodeFromString(t.notification.endpointID)
+	return n
+}
+
 func (t *task) summarize() SummaryTask {
 	return SummaryTask{
 		PkgName:     t.PkgName(),
@@ -1714,6 +1874,10 @@ func (t *task) summarize() SummaryTask {
 		Offset:      durToStr(t.offset),
 		Query:       t.query,
 		Status:      t.Status(),
+		TemplateID:  SafeID(t.TemplateID()),
+		Params:      t.params,
+
+		Notification: t.Notification(),
 
 		LabelAssociations: toSummaryLabels(t.labels...),
 	}
@@ -1724,6 +1888,88 @@ func (t *task) valid() []validationErr {
 	if err, ok := isValidName(t.Name(), 1); !ok {
 		vErrs = append(vErrs, err)
 	}
+	if t.templateID != "" {
+		vErrs = append(vErrs, t.validTemplate()...)
+	} else {
+		vErrs = append(vErrs, t.validQuery()...)
+	}
+
+	if t.notification != nil {
+		vErrs = append(vErrs, t.validNotification()...)
+	}
+
+	if status := t.Status(); status != influxdb.Active && status != influxdb.Inactive {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldStatus,
+			Msg:   "must be 1 of [active, inactive]",
+		})
+	}
+
+	if len(vErrs) > 0 {
+		return []validationErr{
+			objectValidationErr(fieldSpec, vErrs...),
+		}
+	}
+
+	return nil
+}
+
+func (t *task) validNotification() []validationErr {
+	var nErrs []validationErr
+	if !t.Notification().EndpointID.Valid() {
+		nErrs = append(nErrs, validationErr{
+			Field: fieldTaskNotificationEndpointID,
+			Msg:   "must be a valid ID",
+		})
+	}
+	if t.notification.failures < 0 {
+		nErrs = append(nErrs, validationErr{
+			Field: fieldTaskNotificationFailures,
+			Msg:   "must be a positive value",
+		})
+	}
+	if len(nErrs) > 0 {
+		return []validationErr{objectValidationErr(fieldTaskNotification, nErrs...)}
+	}
+	return nil
+}
+
+func (t *task) validTemplate() []validationErr {
+	var vErrs []validationErr
+	if !t.TemplateID().Valid() {
+		vErrs = append(vErrs, validationErr{
+			Field: fieldTaskTemplateID,
+			Msg:   "must be a valid ID",
+		})
+	}
+	for field, set := range map[string]bool{
+		fieldTaskCron: t.cron != "",
+		fieldEvery:    t.every != 0,
+		fieldOffset:   t.offset != 0,
+		fieldQuery:    t.query != "",
+	} {
+		if set {
+			vErrs = append(vErrs, validationErr{
+				Field: field,
+				Msg:   "must not be provided with a templateID, the template provides it",
+			})
+		}
+	}
+	sort.Slice(vErrs, func(i, j int) bool {
+		return vErrs[i].Field < vErrs[j].Field
+	})
+	return vErrs
+}
+
+func (t *task) validQuery() []validationErr {
+	if len(t.params) > 0 {
+		return []validationErr{{
+			Field: fieldTaskParams,
+			Msg:   "must not be provided without a templateID",
+		}}
+	}
+
+	var vErrs []validationErr
 	if t.cron == "" && t.every == 0 {
 		vErrs = append(vErrs,
 			validationErr{
@@ -1743,21 +1989,7 @@ func (t *task) valid() []validationErr {
 			Msg:   "must provide a non zero value",
 		})
 	}
-
-	if status := t.Status(); status != influxdb.Active && status != influxdb.Inactive {
-		vErrs = append(vErrs, validationErr{
-			Field: fieldStatus,
-			Msg:   "must be 1 of [active, inactive]",
-		})
-	}
-
-	if len(vErrs) > 0 {
-		return []validationErr{
-			objectValidationErr(fieldSpec, vErrs...),
-		}
-	}
-
-	return nil
+	return vErrs
 }
 
 var fluxRegex = regexp.MustCompile(`import\s+\".*\"`)
@@ -3420,6 +3420,50 @@ spec:
 			})
 		})
 
+		t.Run("instantiated from a template", func(t *testing.T) {
+			pkg, err := Parse(EncodingYAML, FromString(`apiVersion: influxdata.com/v2alpha1
+kind: Task
+metadata:
+  name: task-0
+spec:
+  description: desc_0
+  templateID: "0000000000000001"
+  params:
+    bucket: rucket_1
+    threshold: 90
+`))
+			require.NoError(t, err)
+
+			tasks := pkg.Summary().Tasks
+			require.Len(t, tasks, 1)
+			assert.Equal(t, "task-0", tasks[0].Name)
+			assert.Equal(t, "desc_0", tasks[0].Description)
+			assert.Equal(t, SafeID(1), tasks[0].TemplateID)
+			assert.Equal(t, map[string]interface{}{"bucket": "rucket_1", "threshold": 90}, tasks[0].Params)
+			assert.Empty(t, tasks[0].Query)
+		})
+
+		t.Run("notifies a notification endpoint", func(t *testing.T) {
+			pkg, err := Parse(EncodingYAML, FromString(`apiVersion: influxdata.com/v2alpha1
+kind: Task
+metadata:
+  name: task-0
+spec:
+  every: 10m
+  query:  >
+    from(bucket: "rucket_1") |> yield(name: "mean")
+  notification:
+    endpointID: "0000000000000002"
+    failures: 3
+    recovery: true
+`))
+			require.NoError(t, err)
+
+			tasks := pkg.Summary().Tasks
+			require.Len(t, tasks, 1)
+			assert.Equal(t, &influxdb.TaskNotification{EndpointID: 2, Failures: 3, Recovery: true}, tasks[0].Notification)
+		})
+
 		t.Run("handles bad config", func(t *testing.T) {
 			tests := []struct {
 				kind   Kind
@@ -3474,6 +3518,61 @@ spec:
   description: desc_0
   every: 10m
   offset: 15s
+`,
+					},
+				},
+				{
+					kind: KindTask,
+					resErr: testPkgResourceError{
+						name:           "template with query",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldQuery},
+						pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: Task
+metadata:
+  name: task-0
+spec:
+  templateID: "0000000000000001"
+  query:  >
+    from(bucket: "rucket_1") |> yield(name: "mean")
+`,
+					},
+				},
+				{
+					kind: KindTask,
+					resErr: testPkgResourceError{
+						name:           "params without template",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldTaskParams},
+						pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: Task
+metadata:
+  name: task-0
+spec:
+  every: 10m
+  query:  >
+    from(bucket: "rucket_1") |> yield(name: "mean")
+  params:
+    bucket: rucket_1
+`,
+					},
+				},
+				{
+					kind: KindTask,
+					resErr: testPkgResourceError{
+						name:           "notification without endpoint",
+						validationErrs: 1,
+						valFields:      []string{fieldSpec, fieldTaskNotification, fieldTaskNotificationEndpointID},
+						pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: Task
+metadata:
+  name: task-0
+spec:
+  every: 10m
+  query:  >
+    from(bucket: "rucket_1") |> yield(name: "mean")
+  notification:
+    failures: 3
 `,
 					},
 				},
@@ -3578,6 +3677,115 @@ spec:
 		})
 	})
 
+	t.Run("pkg with roles", func(t *testing.T) {
+		t.Run("happy path", func(t *testing.T) {
+			testfileRunner(t, "testdata/roles", func(t *testing.T, pkg *Pkg) {
+				roles := pkg.Summary().Roles
+				require.Len(t, roles, 2)
+
+				actual := roles[0]
+				assert.Equal(t, "bucket-writer", actual.PkgName)
+				assert.Equal(t, "bucket-writer", actual.Name)
+				expected := []SummaryRolePermission{
+					{Action: influxdb.WriteAction, ResourceType: influxdb.BucketsResourceType, ResourceID: 10},
+				}
+				assert.Equal(t, expected, actual.Permissions)
+
+				actual = roles[1]
+				assert.Equal(t, "dashboard-editor", actual.PkgName)
+				assert.Equal(t, "Dashboard Editor", actual.Name)
+				assert.Equal(t, "edits dashboards", actual.Description)
+				expected = []SummaryRolePermission{
+					{Action: influxdb.ReadAction, ResourceType: influxdb.DashboardsResourceType},
+					{Action: influxdb.WriteAction, ResourceType: influxdb.DashboardsResourceType},
+					{Action: influxdb.ReadAction, ResourceType: influxdb.OrgsResourceType},
+				}
+				assert.Equal(t, expected, actual.Permissions)
+			})
+		})
+
+		t.Run("handles bad config", func(t *testing.T) {
+			tests := []testPkgResourceError{
+				{
+					name:           "missing name",
+					validationErrs: 1,
+					valFields:      []string{fieldMetadata, fieldName},
+					pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: Role
+metadata:
+spec:
+`,
+				},
+				{
+					name:           "invalid action",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldRolePermissions},
+					pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: Role
+metadata:
+  name: role-0
+spec:
+  permissions:
+    - action: delete
+      type: buckets
+`,
+				},
+				{
+					name:           "invalid resource type",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldRolePermissions},
+					pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: Role
+metadata:
+  name: role-0
+spec:
+  permissions:
+    - action: read
+      type: rockets
+`,
+				},
+				{
+					name:           "invalid resource id",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldRolePermissions},
+					pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: Role
+metadata:
+  name: role-0
+spec:
+  permissions:
+    - action: read
+      type: buckets
+      id: not-an-id
+`,
+				},
+				{
+					name:           "duplicate names",
+					validationErrs: 1,
+					valFields:      []string{fieldSpec, fieldName},
+					pkgStr: `apiVersion: influxdata.com/v2alpha1
+kind: Role
+metadata:
+  name: role-0
+spec:
+  name: role
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Role
+metadata:
+  name: role-1
+spec:
+  name: role
+`,
+				},
+			}
+
+			for _, tt := range tests {
+				testPkgErrors(t, KindRole, tt)
+			}
+		})
+	})
+
 	t.Run("pkg with telegraf and label associations", func(t *testing.T) {
 		t.Run("with valid fields", func(t *testing.T) {
 			testfileRunner(t, "testdata/telegraf", func(t *testing.T, pkg *Pkg) {
@@ -86,6 +86,7 @@ type serviceOpt struct {
 	endpointSVC influxdb.NotificationEndpointService
 	orgSVC      influxdb.OrganizationService
 	ruleSVC     influxdb.NotificationRuleStore
+	roleSVC     influxdb.RoleService
 	secretSVC   influxdb.SecretService
 	taskSVC     influxdb.TaskService
 	teleSVC     influxdb.TelegrafConfigStore
@@ -172,6 +173,13 @@ func WithLabelSVC(labelSVC influxdb.LabelService) ServiceSetterFn {
 	}
 }
 
+// WithRoleSVC sets the role service.
+func WithRoleSVC(roleSVC influxdb.RoleService) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.roleSVC = roleSVC
+	}
+}
+
 // WithSecretSVC sets the secret service.
 func WithSecretSVC(secretSVC influxdb.SecretService) ServiceSetterFn {
 	return func(opt *serviceOpt) {
@@ -228,6 +236,7 @@ type Service struct {
 	endpointSVC influxdb.NotificationEndpointService
 	orgSVC      influxdb.OrganizationService
 	ruleSVC     influxdb.NotificationRuleStore
+	roleSVC     influxdb.RoleService
 	secretSVC   influxdb.SecretService
 	taskSVC     influxdb.TaskService
 	teleSVC     influxdb.TelegrafConfigStore
@@ -263,6 +272,7 @@ func NewService(opts ...ServiceSetterFn) *Service {
 		endpointSVC: opt.endpointSVC,
 		orgSVC:      opt.orgSVC,
 		ruleSVC:     opt.ruleSVC,
+		roleSVC:     opt.roleSVC,
 		secretSVC:   opt.secretSVC,
 		taskSVC:     opt.taskSVC,
 		teleSVC:     opt.teleSVC,
@@ -551,6 +561,22 @@ func (s *Service) cloneOrgNotificationRules(ctx context.Context, orgID influxdb.
 	return resources, nil
 }
 
+func (s *Service) cloneOrgRoles(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
+	roles, _, err := s.roleSVC.FindRoles(ctx, influxdb.RoleFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]ResourceToClone, 0, len(roles))
+	for _, r := range roles {
+		resources = append(resources, ResourceToClone{
+			Kind: KindRole,
+			ID:   r.ID,
+		})
+	}
+	return resources, nil
+}
+
 func (s *Service) cloneOrgTasks(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
 	tasks, _, err := s.taskSVC.FindTasks(ctx, influxdb.TaskFilter{OrganizationID: &orgID})
 	if err != nil {
@@ -648,6 +674,7 @@ func (s *Service) filterOrgResourceKinds(resourceKindFilters []Kind) []struct {
 		KindLabel:                s.cloneOrgLabels,
 		KindNotificationEndpoint: s.cloneOrgNotificationEndpoints,
 		KindNotificationRule:     s.cloneOrgNotificationRules,
+		KindRole:                 s.cloneOrgRoles,
 		KindTask:                 s.cloneOrgTasks,
 		KindTelegraf:             s.cloneOrgTelegrafs,
 		KindVariable:             s.cloneOrgVariables,
@@ -750,6 +777,7 @@ func (s *Service) dryRun(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opt A
 	s.dryRunChecks(ctx, orgID, state.mChecks)
 	s.dryRunDashboards(ctx, orgID, state.mDashboards)
 	s.dryRunLabels(ctx, orgID, state.mLabels)
+	s.dryRunRoles(ctx, orgID, state.mRoles)
 	s.dryRunTasks(ctx, orgID, state.mTasks)
 	s.dryRunTelegrafConfigs(ctx, orgID, state.mTelegrafs)
 	s.dryRunVariables(ctx, orgID, state.mVariables)
@@ -901,6 +929,29 @@ func (s *Service) dryRunNotificationRules(ctx context.Context, orgID influxdb.ID
 	return nil
 }
 
+func (s *Service) dryRunRoles(ctx context.Context, orgID influxdb.ID, roles map[string]*stateRole) {
+	for _, stateRole := range roles {
+		stateRole.orgID = orgID
+		var existing *influxdb.Role
+		if stateRole.ID() != 0 {
+			existing, _ = s.roleSVC.FindRoleByID(ctx, stateRole.ID())
+		} else {
+			name := stateRole.parserRole.Name()
+			existingRoles, _, _ := s.roleSVC.FindRoles(ctx, influxdb.RoleFilter{
+				OrgID: &orgID,
+				Name:  &name,
+			})
+			if len(existingRoles) > 0 {
+				existing = existingRoles[0]
+			}
+		}
+		if IsNew(stateRole.stateStatus) && existing != nil {
+			stateRole.stateStatus = StateStatusExists
+		}
+		stateRole.existing = existing
+	}
+}
+
 func (s *Service) dryRunSecrets(ctx context.Context, orgID influxdb.ID, pkg *Pkg) error {
 	pkgSecrets := pkg.mSecrets
 	if len(pkgSecrets) == 0 {
@@ -1263,6 +1314,7 @@ func (s *Service) applyState(ctx context.Context, coordinator *rollbackCoordinat
 			s.applyBuckets(ctx, state.buckets()),
 			s.applyChecks(ctx, state.checks()),
 			s.applyDashboards(ctx, state.dashboards()),
+			s.applyRoles(ctx, state.roles()),
 			endpointApp,
 			s.applyTasks(ctx, state.tasks()),
 			s.applyTelegrafs(ctx, userID, state.telegrafConfigs()),
@@ -2139,6 +2191,124 @@ func (s *Service) applySecrets(secrets map[string]string) applier {
 	}
 }
 
+func (s *Service) applyRoles(ctx context.Context, roles []*stateRole) applier {
+	const resource = "role"
+
+	mutex := new(doMutex)
+	rollbackRoles := make([]*stateRole, 0, len(roles))
+
+	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody {
+		var r *stateRole
+		mutex.Do(func() {
+			roles[i].orgID = orgID
+			r = roles[i]
+		})
+		if !r.shouldApply() {
+			return nil
+		}
+
+		influxRole, err := s.applyRole(ctx, r)
+		if err != nil {
+			return &applyErrBody{
+				name: r.parserRole.PkgName(),
+				msg:  err.Error(),
+			}
+		}
+
+		mutex.Do(func() {
+			roles[i].id = influxRole.ID
+			rollbackRoles = append(rollbackRoles, roles[i])
+		})
+		return nil
+	}
+
+	return applier{
+		creater: creater{
+			entries: len(roles),
+			fn:      createFn,
+		},
+		rollbacker: rollbacker{
+			resource: resource,
+			fn:       func(_ influxdb.ID) error { return s.rollbackRoles(ctx, rollbackRoles) },
+		},
+	}
+}
+
+func (s *Service) applyRole(ctx context.Context, r *stateRole) (influxdb.Role, error) {
+	switch {
+	case IsRemoval(r.stateStatus):
+		if err := s.roleSVC.DeleteRole(ctx, r.ID()); err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+			return influxdb.Role{}, ierrors.Wrap(err, "removing existing role")
+		}
+		if r.existing == nil {
+			return influxdb.Role{}, nil
+		}
+		return *r.existing, nil
+	case IsExisting(r.stateStatus) && r.existing != nil:
+		name := r.parserRole.Name()
+		perms := r.parserRole.influxPermissions(r.orgID)
+		updatedRole, err := s.roleSVC.UpdateRole(ctx, r.ID(), influxdb.RoleUpdate{
+			Name:        &name,
+			Description: &r.parserRole.Description,
+			Permissions: &perms,
+		})
+		if err != nil {
+			return influxdb.Role{}, ierrors.Wrap(err, "updating existing role")
+		}
+		return *updatedRole, nil
+	default:
+		influxRole := influxdb.Role{
+			OrgID:       r.orgID,
+			Name:        r.parserRole.Name(),
+			Description: r.parserRole.Description,
+			Permissions: r.parserRole.influxPermissions(r.orgID),
+		}
+		if err := s.roleSVC.CreateRole(ctx, &influxRole); err != nil {
+			return influxdb.Role{}, ierrors.Wrap(err, "creating new role")
+		}
+		return influxRole, nil
+	}
+}
+
+func (s *Service) rollbackRoles(ctx context.Context, roles []*stateRole) error {
+	rollbackFn := func(r *stateRole) error {
+		var err error
+		switch {
+		case IsRemoval(r.stateStatus):
+			if r.existing == nil {
+				return nil
+			}
+			err = ierrors.Wrap(s.roleSVC.CreateRole(ctx, r.existing), "rolling back removed role")
+		case IsExisting(r.stateStatus):
+			if r.existing == nil {
+				return nil
+			}
+			_, err = s.roleSVC.UpdateRole(ctx, r.ID(), influxdb.RoleUpdate{
+				Name:        &r.existing.Name,
+				Description: &r.existing.Description,
+				Permissions: &r.existing.Permissions,
+			})
+			err = ierrors.Wrap(err, "rolling back updated role")
+		default:
+			err = ierrors.Wrap(s.roleSVC.DeleteRole(ctx, r.ID()), "rolling back created role")
+		}
+		return err
+	}
+
+	var errs []string
+	for _, r := range roles {
+		if err := rollbackFn(r); err != nil {
+			errs = append(errs, fmt.Sprintf("error for role[%q]: %s", r.ID(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
 func (s *Service) applyTasks(ctx context.Context, tasks []*stateTask) applier {
 	const resource = "tasks"
 
@@ -2192,6 +2362,21 @@ func (s *Service) applyTask(ctx context.Context, userID influxdb.ID, t *stateTas
 			return influxdb.Task{}, ierrors.Wrap(err, "failed to delete task")
 		}
 		return *t.existing, nil
+	case IsExisting(t.stateStatus) && t.existing != nil && t.parserTask.templateID != "":
+		if t.existing.TemplateID != t.parserTask.TemplateID() {
+			return influxdb.Task{}, errors.New("failed to update task: cannot instantiate an existing task from another template")
+		}
+		newStatus := string(t.parserTask.Status())
+		updatedTask, err := s.taskSVC.UpdateTask(ctx, t.ID(), influxdb.TaskUpdate{
+			Status:         &newStatus,
+			Description:    &t.parserTask.description,
+			TemplateParams: templateParams(t.parserTask.params),
+			Notification:   notificationUpdate(t.parserTask.Notification()),
+		})
+		if err != nil {
+			return influxdb.Task{}, ierrors.Wrap(err, "failed to update task")
+		}
+		return *updatedTask, nil
 	case IsExisting(t.stateStatus) && t.existing != nil:
 		newFlux := t.parserTask.flux()
 		newStatus := string(t.parserTask.Status())
@@ -2207,24 +2392,32 @@ func (s *Service) applyTask(ctx context.Context, userID influxdb.ID, t *stateTas
 		}
 
 		updatedTask, err := s.taskSVC.UpdateTask(ctx, t.ID(), influxdb.TaskUpdate{
-			Flux:        &newFlux,
-			Status:      &newStatus,
-			Description: &t.parserTask.description,
-			Options:     opt,
+			Flux:         &newFlux,
+			Status:       &newStatus,
+			Description:  &t.parserTask.description,
+			Notification: notificationUpdate(t.parserTask.Notification()),
+			Options:      opt,
 		})
 		if err != nil {
 			return influxdb.Task{}, ierrors.Wrap(err, "failed to update task")
 		}
 		return *updatedTask, nil
 	default:
-		newTask, err := s.taskSVC.CreateTask(ctx, influxdb.TaskCreate{
+		tc := influxdb.TaskCreate{
 			Type:           influxdb.TaskSystemType,
-			Flux:           t.parserTask.flux(),
 			OwnerID:        userID,
 			Description:    t.parserTask.description,
 			Status:         string(t.parserTask.Status()),
 			OrganizationID: t.orgID,
-		})
+			Notification:   t.parserTask.Notification(),
+		}
+		if t.parserTask.templateID != "" {
+			tc.TemplateID = t.parserTask.TemplateID()
+			tc.TemplateParams = t.parserTask.params
+		} else {
+			tc.Flux = t.parserTask.flux()
+		}
+		newTask, err := s.taskSVC.CreateTask(ctx, tc)
 		if err != nil {
 			return influxdb.Task{}, ierrors.Wrap(err, "failed to create task")
 		}
@@ -2232,6 +2425,24 @@ func (s *Service) applyTask(ctx context.Context, userID influxdb.ID, t *stateTas
 	}
 }
 
+// templateParams returns the template parameters of a task, which are never
+// nil, so that updating the task with them renders it again.
+func templateParams(params map[string]interface{}) map[string]interface{} {
+	if params == nil {
+		return map[string]interface{}{}
+	}
+	return params
+}
+
+// notificationUpdate returns the notification of a task, which is never nil,
+// so that updating the task with it removes a notification the task no longer has.
+func notificationUpdate(n *influxdb.TaskNotification) *influxdb.TaskNotification {
+	if n == nil {
+		return &influxdb.TaskNotification{}
+	}
+	return n
+}
+
 func (s *Service) rollbackTasks(ctx context.Context, tasks []*stateTask) error {
 	rollbackFn := func(t *stateTask) error {
 		if !IsNew(t.stateStatus) && t.existing == nil {
@@ -2241,20 +2452,39 @@ func (s *Service) rollbackTasks(ctx context.Context, tasks []*stateTask) error {
 		var err error
 		switch t.stateStatus {
 		case StateStatusRemove:
-			newTask, err := s.taskSVC.CreateTask(ctx, influxdb.TaskCreate{
+			tc := influxdb.TaskCreate{
 				Type:           t.existing.Type,
-				Flux:           t.existing.Flux,
 				OwnerID:        t.existing.OwnerID,
 				Description:    t.existing.Description,
 				Status:         t.existing.Status,
 				OrganizationID: t.orgID,
 				Metadata:       t.existing.Metadata,
-			})
+				Notification:   t.existing.Notification,
+			}
+			if t.existing.TemplateID.Valid() {
+				tc.TemplateID = t.existing.TemplateID
+				tc.TemplateParams = t.existing.TemplateParams
+			} else {
+				tc.Flux = t.existing.Flux
+			}
+			newTask, err := s.taskSVC.CreateTask(ctx, tc)
 			if err != nil {
 				return ierrors.Wrap(err, "failed to rollback removed task")
 			}
 			t.existing = newTask
 		case StateStatusExists:
+			if t.existing.TemplateID.Valid() {
+				_, err = s.taskSVC.UpdateTask(ctx, t.ID(), influxdb.TaskUpdate{
+					Status:         &t.existing.Status,
+					Description:    &t.existing.Description,
+					Metadata:       t.existing.Metadata,
+					TemplateParams: templateParams(t.existing.TemplateParams),
+					Notification:   notificationUpdate(t.existing.Notification),
+				})
+				err = ierrors.Wrap(err, "failed to rollback updated task")
+				break
+			}
+
 			opt := options.Options{
 				Name: t.existing.Name,
 				Cron: t.existing.Cron,
@@ -2267,11 +2497,12 @@ func (s *Service) rollbackTasks(ctx context.Context, tasks []*stateTask) error {
 			}
 
 			_, err = s.taskSVC.UpdateTask(ctx, t.ID(), influxdb.TaskUpdate{
-				Flux:        &t.existing.Flux,
-				Status:      &t.existing.Status,
-				Description: &t.existing.Description,
-				Metadata:    t.existing.Metadata,
-				Options:     opt,
+				Flux:         &t.existing.Flux,
+				Status:       &t.existing.Status,
+				Description:  &t.existing.Description,
+				Metadata:     t.existing.Metadata,
+				Notification: notificationUpdate(t.existing.Notification),
+				Options:      opt,
 			})
 			err = ierrors.Wrap(err, "failed to rollback updated task")
 		default:
@@ -2785,6 +3016,17 @@ func (s *Service) updateStackAfterSuccess(ctx context.Context, stackID influxdb.
 			),
 		})
 	}
+	for _, r := range state.mRoles {
+		if IsRemoval(r.stateStatus) {
+			continue
+		}
+		stackResources = append(stackResources, StackResource{
+			APIVersion: APIVersion,
+			ID:         r.ID(),
+			Kind:       KindRole,
+			PkgName:    r.parserRole.PkgName(),
+		})
+	}
 	for _, t := range state.mTasks {
 		if IsRemoval(t.stateStatus) {
 			continue
@@ -2917,6 +3159,13 @@ func (s *Service) updateStackAfterRollback(ctx context.Context, stackID influxdb
 				res.Associations = newAss
 			}
 		}
+		for _, r := range state.mRoles {
+			res, ok := existingResources[newKey(KindRole, r.parserRole.PkgName())]
+			if ok && res.ID != r.ID() {
+				hasChanges = true
+				res.ID = r.existing.ID
+			}
+		}
 		for _, t := range state.mTasks {
 			res, ok := existingResources[newKey(KindTask, t.parserTask.PkgName())]
 			if ok && res.ID != t.ID() {
@@ -160,6 +160,7 @@ func (s *loggingMW) summaryLogFields(sum Summary) []zap.Field {
 		{key: "endpoints", val: len(sum.NotificationEndpoints)},
 		{key: "labels", val: len(sum.Labels)},
 		{key: "label_mappings", val: len(sum.LabelMappings)},
+		{key: "roles", val: len(sum.Roles)},
 		{key: "rules", val: len(sum.NotificationRules)},
 		{key: "secrets", val: len(sum.MissingSecrets)},
 		{key: "tasks", val: len(sum.Tasks)},
@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"sort"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -33,6 +34,7 @@ func TestService(t *testing.T) {
 			endpointSVC: mock.NewNotificationEndpointService(),
 			orgSVC:      mock.NewOrganizationService(),
 			ruleSVC:     mock.NewNotificationRuleStore(),
+			roleSVC:     mock.NewRoleService(),
 			taskSVC:     mock.NewTaskService(),
 			teleSVC:     mock.NewTelegrafConfigStore(),
 			varSVC:      mock.NewVariableService(),
@@ -52,6 +54,7 @@ func TestService(t *testing.T) {
 			WithNotificationEndpointSVC(opt.endpointSVC),
 			WithNotificationRuleSVC(opt.ruleSVC),
 			WithOrganizationService(opt.orgSVC),
+			WithRoleSVC(opt.roleSVC),
 			WithSecretSVC(opt.secretSVC),
 			WithTaskSVC(opt.taskSVC),
 			WithTelegrafSVC(opt.teleSVC),
@@ -392,6 +395,77 @@ func TestService(t *testing.T) {
 			})
 		})
 
+		t.Run("roles", func(t *testing.T) {
+			testfileRunner(t, "testdata/roles.yml", func(t *testing.T, pkg *Pkg) {
+				orgID := influxdb.ID(100)
+
+				fakeRoleSVC := mock.NewRoleService()
+				fakeRoleSVC.FindRolesFn = func(_ context.Context, f influxdb.RoleFilter, _ ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+					if *f.OrgID != orgID || *f.Name != "Dashboard Editor" {
+						return nil, 0, nil
+					}
+					return []*influxdb.Role{
+						{
+							ID:    1,
+							OrgID: orgID,
+							Name:  "Dashboard Editor",
+							Permissions: []influxdb.Permission{
+								{
+									Action:   influxdb.ReadAction,
+									Resource: influxdb.Resource{Type: influxdb.DashboardsResourceType, OrgID: &orgID},
+								},
+							},
+						},
+					}, 1, nil
+				}
+				svc := newTestService(WithRoleSVC(fakeRoleSVC))
+
+				_, diff, err := svc.DryRun(context.TODO(), orgID, 0, pkg)
+				require.NoError(t, err)
+
+				require.Len(t, diff.Roles, 2)
+
+				expected := DiffRole{
+					DiffIdentifier: DiffIdentifier{
+						PkgName:     "bucket-writer",
+						StateStatus: StateStatusNew,
+					},
+					New: DiffRoleValues{
+						Name: "bucket-writer",
+						Permissions: []SummaryRolePermission{
+							{Action: influxdb.WriteAction, ResourceType: influxdb.BucketsResourceType, ResourceID: 10},
+						},
+					},
+				}
+				assert.Equal(t, expected, diff.Roles[0])
+
+				expected = DiffRole{
+					DiffIdentifier: DiffIdentifier{
+						ID:          1,
+						PkgName:     "dashboard-editor",
+						StateStatus: StateStatusExists,
+					},
+					Old: &DiffRoleValues{
+						Name: "Dashboard Editor",
+						Permissions: []SummaryRolePermission{
+							{Action: influxdb.ReadAction, ResourceType: influxdb.DashboardsResourceType},
+						},
+					},
+					New: DiffRoleValues{
+						Name:        "Dashboard Editor",
+						Description: "edits dashboards",
+						Permissions: []SummaryRolePermission{
+							{Action: influxdb.ReadAction, ResourceType: influxdb.DashboardsResourceType},
+							{Action: influxdb.WriteAction, ResourceType: influxdb.DashboardsResourceType},
+							{Action: influxdb.ReadAction, ResourceType: influxdb.OrgsResourceType},
+						},
+					},
+				}
+				assert.Equal(t, expected, diff.Roles[1])
+				assert.True(t, diff.HasConflicts())
+			})
+		})
+
 		t.Run("variables", func(t *testing.T) {
 			testfileRunner(t, "testdata/variables.json", func(t *testing.T, pkg *Pkg) {
 				fakeVarSVC := mock.NewVariableService()
@@ -1361,6 +1435,140 @@ func TestService(t *testing.T) {
 			})
 		})
 
+		t.Run("roles", func(t *testing.T) {
+			t.Run("successfully creates pkg of roles", func(t *testing.T) {
+				testfileRunner(t, "testdata/roles.yml", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					var (
+						mu      sync.Mutex
+						created []influxdb.Role
+					)
+					fakeRoleSVC := mock.NewRoleService()
+					fakeRoleSVC.CreateRoleFn = func(_ context.Context, r *influxdb.Role) error {
+						if err := r.Valid(); err != nil {
+							return err
+						}
+						mu.Lock()
+						defer mu.Unlock()
+						r.ID = influxdb.ID(len(created) + 1)
+						created = append(created, *r)
+						return nil
+					}
+
+					svc := newTestService(WithRoleSVC(fakeRoleSVC))
+
+					sum, _, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.Roles, 2)
+					require.Len(t, created, 2)
+					for _, actual := range sum.Roles {
+						assert.Equal(t, SafeID(orgID), actual.OrgID)
+						assert.Contains(t, []SafeID{1, 2}, actual.ID)
+					}
+
+					sort.Slice(created, func(i, j int) bool { return created[i].Name < created[j].Name })
+					bucketID := influxdb.ID(10)
+					expected := []influxdb.Permission{
+						{
+							Action:   influxdb.WriteAction,
+							Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, ID: &bucketID, OrgID: &orgID},
+						},
+					}
+					assert.Equal(t, expected, created[1].Permissions)
+
+					expected = []influxdb.Permission{
+						{
+							Action:   influxdb.ReadAction,
+							Resource: influxdb.Resource{Type: influxdb.DashboardsResourceType, OrgID: &orgID},
+						},
+						{
+							Action:   influxdb.WriteAction,
+							Resource: influxdb.Resource{Type: influxdb.DashboardsResourceType, OrgID: &orgID},
+						},
+						{
+							Action:   influxdb.ReadAction,
+							Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: &orgID},
+						},
+					}
+					assert.Equal(t, "Dashboard Editor", created[0].Name)
+					assert.Equal(t, expected, created[0].Permissions)
+				})
+			})
+
+			t.Run("rolls back all created roles on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/roles.yml", func(t *testing.T, pkg *Pkg) {
+					var deletes int
+					fakeRoleSVC := mock.NewRoleService()
+					fakeRoleSVC.CreateRoleFn = func(_ context.Context, r *influxdb.Role) error {
+						// the bucket writer fails, and the dashboard editor before should be rolled back
+						if r.Name == "bucket-writer" {
+							return errors.New("blowed up ")
+						}
+						r.ID = influxdb.ID(1)
+						return nil
+					}
+					fakeRoleSVC.DeleteRoleFn = func(_ context.Context, id influxdb.ID) error {
+						deletes++
+						return nil
+					}
+
+					svc := newTestService(WithRoleSVC(fakeRoleSVC))
+
+					_, _, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
+
+					assert.Equal(t, 1, deletes)
+				})
+			})
+
+			t.Run("will not apply role if no changes to be applied", func(t *testing.T) {
+				testfileRunner(t, "testdata/roles.yml", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+					bucketID := influxdb.ID(10)
+
+					fakeRoleSVC := mock.NewRoleService()
+					fakeRoleSVC.FindRolesFn = func(_ context.Context, f influxdb.RoleFilter, _ ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+						if *f.Name != "bucket-writer" {
+							return nil, 0, nil
+						}
+						return []*influxdb.Role{
+							{
+								ID:    1,
+								OrgID: orgID,
+								Name:  "bucket-writer",
+								Permissions: []influxdb.Permission{
+									{
+										Action:   influxdb.WriteAction,
+										Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, ID: &bucketID, OrgID: &orgID},
+									},
+								},
+							},
+						}, 1, nil
+					}
+					var creates int
+					fakeRoleSVC.CreateRoleFn = func(_ context.Context, r *influxdb.Role) error {
+						creates++
+						r.ID = influxdb.ID(2)
+						return nil
+					}
+					fakeRoleSVC.UpdateRoleFn = func(_ context.Context, id influxdb.ID, _ influxdb.RoleUpdate) (*influxdb.Role, error) {
+						return nil, errors.New("this role should not be updated")
+					}
+
+					svc := newTestService(WithRoleSVC(fakeRoleSVC))
+
+					sum, _, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.Roles, 2)
+					assert.Equal(t, SafeID(1), sum.Roles[0].ID)
+					assert.Equal(t, 1, creates)
+				})
+			})
+		})
+
 		t.Run("variables", func(t *testing.T) {
 			t.Run("successfully creates pkg of variables", func(t *testing.T) {
 				testfileRunner(t, "testdata/variables.yml", func(t *testing.T, pkg *Pkg) {
@@ -2680,6 +2888,57 @@ func TestService(t *testing.T) {
 				})
 			})
 
+			t.Run("role", func(t *testing.T) {
+				orgID := influxdb.ID(3)
+				bucketID := influxdb.ID(10)
+				expected := influxdb.Role{
+					ID:          1,
+					OrgID:       orgID,
+					Name:        "role name",
+					Description: "desc",
+					Permissions: []influxdb.Permission{
+						{
+							Action:   influxdb.WriteAction,
+							Resource: influxdb.Resource{Type: influxdb.BucketsResourceType, ID: &bucketID, OrgID: &orgID},
+						},
+						{
+							Action:   influxdb.ReadAction,
+							Resource: influxdb.Resource{Type: influxdb.OrgsResourceType, ID: &orgID},
+						},
+					},
+				}
+
+				roleSVC := mock.NewRoleService()
+				roleSVC.FindRoleByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Role, error) {
+					if id != expected.ID {
+						return nil, errors.New("uh ohhh, wrong id here: " + id.String())
+					}
+					return &expected, nil
+				}
+
+				svc := newTestService(WithRoleSVC(roleSVC), WithLabelSVC(mock.NewLabelService()))
+
+				pkg, err := svc.CreatePkg(context.TODO(), CreateWithExistingResources(ResourceToClone{
+					Kind: KindRole,
+					ID:   expected.ID,
+				}))
+				require.NoError(t, err)
+
+				newPkg := encodeAndDecode(t, pkg)
+
+				roles := newPkg.Summary().Roles
+				require.Len(t, roles, 1)
+
+				actual := roles[0]
+				assert.Equal(t, expected.Name, actual.Name)
+				assert.Equal(t, expected.Description, actual.Description)
+				expectedPerms := []SummaryRolePermission{
+					{Action: influxdb.WriteAction, ResourceType: influxdb.BucketsResourceType, ResourceID: 10},
+					{Action: influxdb.ReadAction, ResourceType: influxdb.OrgsResourceType},
+				}
+				assert.Equal(t, expectedPerms, actual.Permissions)
+			})
+
 			t.Run("variable", func(t *testing.T) {
 				tests := []struct {
 					name        string
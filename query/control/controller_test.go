@@ -684,6 +684,54 @@ func TestController_PerQueryMemoryLimit(t *testing.T) {
 	}
 }
 
+func TestController_RequestMemoryLimit(t *testing.T) {
+	ctrl, err := control.New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer shutdown(t, ctrl)
+
+	quota := config.MemoryBytesQuotaPerQuery / 2
+	run := func(allocate int) error {
+		compiler := &mock.Compiler{
+			CompileFn: func(ctx context.Context) (flux.Program, error) {
+				return &mock.Program{
+					ExecuteFn: func(ctx context.Context, q *mock.Query, alloc *memory.Allocator) {
+						defer func() {
+							if err, ok := recover().(error); ok && err != nil {
+								q.SetErr(err)
+							}
+						}()
+
+						mem := arrow.NewAllocator(alloc)
+						b := mem.Allocate(allocate)
+						mem.Free(b)
+					},
+				}, nil
+			},
+		}
+
+		req := makeRequest(compiler)
+		req.MemoryBytesQuota = quota
+		q, err := ctrl.Query(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for range q.Results() {
+			// discard the results
+		}
+		q.Done()
+		return q.Err()
+	}
+
+	if err := run(int(quota)); err != nil {
+		t.Fatalf("unexpected error within the memory quota of the request: %v", err)
+	}
+	if err := run(int(quota + 1)); err == nil {
+		t.Fatal("expected error about the memory quota of the request exceeded")
+	}
+}
+
 func TestController_ConcurrencyQuota(t *testing.T) {
 	const (
 		numQueries       = 3
@@ -6,6 +6,7 @@ import (
 	"sync/atomic"
 
 	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/influxdb/v2/query"
 )
 
 type memoryManager struct {
@@ -43,9 +44,18 @@ func (m *memoryManager) addUnusedMemoryBytes(amount int64) int64 {
 // createAllocator will construct an allocator and memory manager
 // for the given query.
 func (c *Controller) createAllocator(q *Query) {
+	quota := c.memory.memoryBytesQuotaPerQuery
+	if req := query.RequestFromContext(q.parentCtx); req != nil && req.MemoryBytesQuota > 0 && req.MemoryBytesQuota < quota {
+		quota = req.MemoryBytesQuota
+	}
+	limit := c.memory.initialBytesQuotaPerQuery
+	if limit > quota {
+		limit = quota
+	}
 	q.memoryManager = &queryMemoryManager{
 		m:     c.memory,
-		limit: c.memory.initialBytesQuotaPerQuery,
+		quota: quota,
+		limit: limit,
 	}
 	q.alloc = &memory.Allocator{
 		// Use an anonymous function to ensure the value is copied.
@@ -56,7 +66,11 @@ func (c *Controller) createAllocator(q *Query) {
 
 // queryMemoryManager is a memory manager for a specific query.
 type queryMemoryManager struct {
-	m     *memoryManager
+	m *memoryManager
+
+	// quota is the maximum amount of memory that may be
+	// allocated to the query.
+	quota int64
 	limit int64
 	given int64
 }
@@ -72,8 +86,8 @@ type queryMemoryManager struct {
 // too much about the specific message or structure.
 func (q *queryMemoryManager) RequestMemory(want int64) (got int64, err error) {
 	// It can be determined statically if we are going to violate
-	// the memoryBytesQuotaPerQuery.
-	if q.limit+want > q.m.memoryBytesQuotaPerQuery {
+	// the quota of the query.
+	if q.limit+want > q.quota {
 		return 0, errors.New("query hit hard limit")
 	}
 
@@ -118,12 +132,12 @@ func (q *queryMemoryManager) RequestMemory(want int64) (got int64, err error) {
 func (q *queryMemoryManager) giveMemory(want, unused int64) int64 {
 	// If we can safely double the limit, then just do that.
 	if q.limit > want && q.limit < unused {
-		if q.limit*2 <= q.m.memoryBytesQuotaPerQuery {
+		if q.limit*2 <= q.quota {
 			return q.limit
 		}
 		// Doubling the limit sends us over the quota.
 		// Determine what would be our maximum amount.
-		max := q.m.memoryBytesQuotaPerQuery - q.limit
+		max := q.quota - q.limit
 		if max > want {
 			return max
 		}
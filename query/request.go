@@ -33,6 +33,11 @@ type Request struct {
 	// Source represents the ultimate source of the request.
 	Source string `json:"source"`
 
+	// MemoryBytesQuota is the maximum number of bytes the query may use,
+	// when it is less than the memory quota per query of the controller.
+	// A zero value does not limit the query any further.
+	MemoryBytesQuota int64 `json:"-"`
+
 	// compilerMappings maps compiler types to creation methods
 	compilerMappings flux.CompilerMappings
 
@@ -13,7 +13,10 @@ import (
 
 type key int
 
-const dependenciesKey key = iota
+const (
+	dependenciesKey key = iota
+	pointsWriterKey
+)
 
 type StorageDependencies struct {
 	FromDeps   FromDependencies
@@ -26,7 +29,18 @@ func (d StorageDependencies) Inject(ctx context.Context) context.Context {
 }
 
 func GetStorageDependencies(ctx context.Context) StorageDependencies {
-	return ctx.Value(dependenciesKey).(StorageDependencies)
+	deps := ctx.Value(dependenciesKey).(StorageDependencies)
+	if w, ok := ctx.Value(pointsWriterKey).(storage.PointsWriter); ok {
+		deps.ToDeps.PointsWriter = w
+	}
+	return deps
+}
+
+// ContextWithPointsWriter returns a context under which the to() functions
+// write their points to w instead of the points writer of their dependencies,
+// so that queries can be executed without writing to storage.
+func ContextWithPointsWriter(ctx context.Context, w storage.PointsWriter) context.Context {
+	return context.WithValue(ctx, pointsWriterKey, w)
 }
 
 // PrometheusCollectors satisfies the prom.PrometheusCollector interface.
@@ -0,0 +1,59 @@
+package influxdb
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Roles of a storage engine taking part in WAL replication.
+const (
+	ReplicationRoleLeader   = "leader"
+	ReplicationRoleFollower = "follower"
+)
+
+// WALPosition is a position within the WAL segments of a replication leader.
+type WALPosition struct {
+	// Segment is the ID of the leader's WAL segment.
+	Segment int `json:"segment"`
+	// Offset is the number of bytes of the segment that have been applied.
+	Offset int64 `json:"offset"`
+}
+
+// ReplicationStatus describes the state of WAL replication of a storage engine.
+type ReplicationStatus struct {
+	// Role is the replication role of the engine; empty if replication is not
+	// configured.
+	Role string `json:"role,omitempty"`
+
+	// Position is the last position acknowledged by the follower.
+	Position *WALPosition `json:"position,omitempty"`
+
+	// LagBytes and LagSegments describe the WAL data the leader has not yet
+	// had acknowledged by its follower. They are always zero on a follower.
+	LagBytes    int64 `json:"lagBytes"`
+	LagSegments int   `json:"lagSegments"`
+
+	// LastAck is when the position was last acknowledged.
+	LastAck *time.Time `json:"lastAck,omitempty"`
+
+	// LastError is the last error encountered shipping segments, if any.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// ReplicationService represents a storage engine taking part in WAL
+// replication. Leaders ship their WAL segments to a follower, which applies
+// them to its own engine.
+type ReplicationService interface {
+	// ReplicationStatus returns the replication state of the engine.
+	ReplicationStatus(ctx context.Context) (*ReplicationStatus, error)
+
+	// WALPosition returns the position up to which the follower has applied
+	// the leader's WAL.
+	WALPosition(ctx context.Context) (*WALPosition, error)
+
+	// ApplyWAL applies the WAL entries read from r, which start at pos of the
+	// leader's WAL, and returns the new position. Trailing partial entries are
+	// not applied and must be shipped again from the returned position.
+	ApplyWAL(ctx context.Context, pos WALPosition, r io.Reader) (*WALPosition, error)
+}
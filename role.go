@@ -0,0 +1,167 @@
+package influxdb
+
+import (
+	"context"
+	"fmt"
+)
+
+// ErrRoleNotFound is the error for a missing Role.
+const ErrRoleNotFound = "role not found"
+
+const (
+	OpFindRoleByID        = "FindRoleByID"
+	OpFindRoles           = "FindRoles"
+	OpCreateRole          = "CreateRole"
+	OpUpdateRole          = "UpdateRole"
+	OpDeleteRole          = "DeleteRole"
+	OpAssignRole          = "AssignRole"
+	OpUnassignRole        = "UnassignRole"
+	OpFindRoleAssignments = "FindRoleAssignments"
+)
+
+// RoleService represents a service for managing roles and their assignments.
+type RoleService interface {
+	// FindRoleByID returns a single role by ID.
+	FindRoleByID(ctx context.Context, id ID) (*Role, error)
+
+	// FindRoles returns a list of roles that match filter and the total count of matching roles.
+	// Additional options provide pagination & sorting.
+	FindRoles(ctx context.Context, filter RoleFilter, opt ...FindOptions) ([]*Role, int, error)
+
+	// CreateRole creates a new role and sets r.ID with the new identifier.
+	CreateRole(ctx context.Context, r *Role) error
+
+	// UpdateRole updates a single role with a changeset.
+	// Returns the new role state after update.
+	UpdateRole(ctx context.Context, id ID, upd RoleUpdate) (*Role, error)
+
+	// DeleteRole removes a role by ID, along with its assignments.
+	DeleteRole(ctx context.Context, id ID) error
+
+	// AssignRole assigns a role to a user or an authorization.
+	AssignRole(ctx context.Context, a *RoleAssignment) error
+
+	// UnassignRole removes the assignment of a role.
+	UnassignRole(ctx context.Context, a RoleAssignment) error
+
+	// FindRoleAssignments returns a list of role assignments that match filter.
+	FindRoleAssignments(ctx context.Context, filter RoleAssignmentFilter) ([]*RoleAssignment, error)
+}
+
+// RolePermissionService looks up the permissions of the roles of users and
+// authorizations, which are given to them as they authenticate.
+type RolePermissionService interface {
+	// FindRolePermissions returns the permissions of the roles assigned to the
+	// resource, which is a user or an authorization.
+	FindRolePermissions(ctx context.Context, rt ResourceType, id ID) ([]Permission, error)
+}
+
+// Role is a named set of permissions of an org, which is assigned to users
+// and authorizations. The holders of a role have the permissions of the role
+// as they are at the time of every request, so that changes to the role apply
+// to all of them.
+type Role struct {
+	ID          ID           `json:"id,omitempty"`
+	OrgID       ID           `json:"orgID"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Permissions []Permission `json:"permissions"`
+	CRUDLog
+}
+
+// Valid returns an error if the role is invalid. The permissions of a role
+// may only be for the resources of its org.
+func (r *Role) Valid() error {
+	if r.Name == "" {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "role name is required",
+		}
+	}
+	if !r.OrgID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "orgID is required",
+		}
+	}
+
+	for _, p := range r.Permissions {
+		if err := p.Valid(); err != nil {
+			return err
+		}
+		ofOrg := p.Resource.OrgID != nil && *p.Resource.OrgID == r.OrgID
+		isOrg := p.Resource.Type == OrgsResourceType && p.Resource.ID != nil && *p.Resource.ID == r.OrgID
+		if !ofOrg && !isOrg {
+			return &Error{
+				Code: EInvalid,
+				Msg:  fmt.Sprintf("permission %s is not for org id %s", p, r.OrgID),
+			}
+		}
+	}
+	return nil
+}
+
+// RoleUpdate is the changeset of a role.
+type RoleUpdate struct {
+	Name        *string       `json:"name,omitempty"`
+	Description *string       `json:"description,omitempty"`
+	Permissions *[]Permission `json:"permissions,omitempty"`
+}
+
+// Apply applies the changeset to the role.
+func (u RoleUpdate) Apply(r *Role) {
+	if u.Name != nil {
+		r.Name = *u.Name
+	}
+	if u.Description != nil {
+		r.Description = *u.Description
+	}
+	if u.Permissions != nil {
+		r.Permissions = *u.Permissions
+	}
+}
+
+// RoleFilter represents a set of filters that restrict the returned roles.
+type RoleFilter struct {
+	ID    *ID
+	OrgID *ID
+	Name  *string
+}
+
+// RoleAssignment assigns a role to a user, or to an authorization.
+type RoleAssignment struct {
+	RoleID       ID           `json:"roleID"`
+	ResourceType ResourceType `json:"resourceType"`
+	ResourceID   ID           `json:"resourceID"`
+}
+
+// Valid returns an error if the assignment is invalid.
+func (a RoleAssignment) Valid() error {
+	if !a.RoleID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "role id is required",
+		}
+	}
+	if a.ResourceType != UsersResourceType && a.ResourceType != AuthorizationsResourceType {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "roles may only be assigned to users and authorizations",
+		}
+	}
+	if !a.ResourceID.Valid() {
+		return &Error{
+			Code: EInvalid,
+			Msg:  "resource id is required",
+		}
+	}
+	return nil
+}
+
+// RoleAssignmentFilter represents a set of filters that restrict the returned
+// role assignments.
+type RoleAssignmentFilter struct {
+	RoleID       *ID
+	ResourceType *ResourceType
+	ResourceID   *ID
+}
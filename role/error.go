@@ -0,0 +1,42 @@
+package role
+
+import (
+	"github.com/influxdata/influxdb/v2"
+)
+
+var (
+	// ErrRoleNotFound is used when the specified role cannot be found.
+	ErrRoleNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  influxdb.ErrRoleNotFound,
+	}
+
+	// ErrAssignmentNotFound is used when the specified role assignment
+	// cannot be found.
+	ErrAssignmentNotFound = &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  "role assignment not found",
+	}
+
+	// ErrInvalidRoleID is used when the ID of the role cannot be encoded.
+	ErrInvalidRoleID = &influxdb.Error{
+		Code: influxdb.EInvalid,
+		Msg:  "role ID is invalid",
+	}
+)
+
+// ErrRoleAlreadyExists is used when the org already has a role of the name.
+func ErrRoleAlreadyExists(name string) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EConflict,
+		Msg:  "role with name " + name + " already exists",
+	}
+}
+
+// ErrInternalService is used when the error comes from an internal system.
+func ErrInternalService(err error) *influxdb.Error {
+	return &influxdb.Error{
+		Code: influxdb.EInternal,
+		Err:  err,
+	}
+}
@@ -0,0 +1,293 @@
+package role
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	"go.uber.org/zap"
+)
+
+const prefixRoles = "/api/v2/roles"
+
+// RoleHandler represents an HTTP API handler for roles and their assignments.
+type RoleHandler struct {
+	chi.Router
+	api     *kithttp.API
+	log     *zap.Logger
+	roleSvc influxdb.RoleService
+}
+
+// NewHTTPRoleHandler constructs a new http server.
+func NewHTTPRoleHandler(log *zap.Logger, roleSvc influxdb.RoleService) *RoleHandler {
+	h := &RoleHandler{
+		api:     kithttp.NewAPI(kithttp.WithLog(log)),
+		log:     log,
+		roleSvc: roleSvc,
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+
+	r.Route("/", func(r chi.Router) {
+		r.Post("/", h.handlePostRole)
+		r.Get("/", h.handleGetRoles)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.handleGetRole)
+			r.Patch("/", h.handlePatchRole)
+			r.Delete("/", h.handleDeleteRole)
+
+			for _, rt := range []influxdb.ResourceType{influxdb.UsersResourceType, influxdb.AuthorizationsResourceType} {
+				rt := rt
+				r.Route("/"+string(rt), func(r chi.Router) {
+					r.Get("/", h.handleGetAssignments(rt))
+					r.Post("/", h.handlePostAssignment(rt))
+					r.Delete("/{resourceID}", h.handleDeleteAssignment(rt))
+				})
+			}
+		})
+	})
+
+	h.Router = r
+	return h
+}
+
+// Prefix is necessary to mount the router as a resource handler
+func (h *RoleHandler) Prefix() string {
+	return prefixRoles
+}
+
+type roleResponse struct {
+	Links map[string]string `json:"links"`
+	influxdb.Role
+}
+
+func newRoleResponse(r *influxdb.Role) roleResponse {
+	self := fmt.Sprintf("%s/%s", prefixRoles, r.ID)
+	return roleResponse{
+		Links: map[string]string{
+			"self":           self,
+			"org":            fmt.Sprintf("/api/v2/orgs/%s", r.OrgID),
+			"users":          self + "/users",
+			"authorizations": self + "/authorizations",
+		},
+		Role: *r,
+	}
+}
+
+type rolesResponse struct {
+	Links map[string]string `json:"links"`
+	Roles []roleResponse    `json:"roles"`
+}
+
+type assignmentsResponse struct {
+	Assignments []*influxdb.RoleAssignment `json:"assignments"`
+}
+
+// handlePostRole is the HTTP handler for the POST /api/v2/roles route.
+func (h *RoleHandler) handlePostRole(w http.ResponseWriter, r *http.Request) {
+	var role influxdb.Role
+	if err := h.api.DecodeJSON(r.Body, &role); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.roleSvc.CreateRole(r.Context(), &role); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Role created", zap.String("role", fmt.Sprint(role)))
+
+	h.api.Respond(w, r, http.StatusCreated, newRoleResponse(&role))
+}
+
+// handleGetRoles is the HTTP handler for the GET /api/v2/roles route.
+func (h *RoleHandler) handleGetRoles(w http.ResponseWriter, r *http.Request) {
+	var filter influxdb.RoleFilter
+	qp := r.URL.Query()
+	if v := qp.Get("orgID"); v != "" {
+		id, err := influxdb.IDFromString(v)
+		if err != nil {
+			h.api.Err(w, r, &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "orgID is invalid",
+				Err:  err,
+			})
+			return
+		}
+		filter.OrgID = id
+	}
+	if name := qp.Get("name"); name != "" {
+		filter.Name = &name
+	}
+	opts, err := influxdb.DecodeFindOptions(r)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	roles, _, err := h.roleSvc.FindRoles(r.Context(), filter, *opts)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	res := rolesResponse{
+		Links: map[string]string{"self": prefixRoles},
+		Roles: []roleResponse{},
+	}
+	for _, role := range roles {
+		res.Roles = append(res.Roles, newRoleResponse(role))
+	}
+	h.api.Respond(w, r, http.StatusOK, res)
+}
+
+// handleGetRole is the HTTP handler for the GET /api/v2/roles/:id route.
+func (h *RoleHandler) handleGetRole(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	role, err := h.roleSvc.FindRoleByID(r.Context(), *id)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	h.api.Respond(w, r, http.StatusOK, newRoleResponse(role))
+}
+
+// handlePatchRole is the HTTP handler for the PATCH /api/v2/roles/:id route.
+func (h *RoleHandler) handlePatchRole(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	var upd influxdb.RoleUpdate
+	if err := h.api.DecodeJSON(r.Body, &upd); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	role, err := h.roleSvc.UpdateRole(r.Context(), *id, upd)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Role updated", zap.String("role", fmt.Sprint(role)))
+
+	h.api.Respond(w, r, http.StatusOK, newRoleResponse(role))
+}
+
+// handleDeleteRole is the HTTP handler for the DELETE /api/v2/roles/:id route.
+func (h *RoleHandler) handleDeleteRole(w http.ResponseWriter, r *http.Request) {
+	id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	if err := h.roleSvc.DeleteRole(r.Context(), *id); err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	h.log.Debug("Role deleted", zap.String("roleID", id.String()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetAssignments is the HTTP handler for the GET /api/v2/roles/:id/users
+// and GET /api/v2/roles/:id/authorizations routes.
+func (h *RoleHandler) handleGetAssignments(rt influxdb.ResourceType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+
+		as, err := h.roleSvc.FindRoleAssignments(r.Context(), influxdb.RoleAssignmentFilter{
+			RoleID:       id,
+			ResourceType: &rt,
+		})
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+
+		h.api.Respond(w, r, http.StatusOK, assignmentsResponse{Assignments: as})
+	}
+}
+
+// handlePostAssignment is the HTTP handler for the POST /api/v2/roles/:id/users
+// and POST /api/v2/roles/:id/authorizations routes.
+func (h *RoleHandler) handlePostAssignment(rt influxdb.ResourceType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+
+		var req struct {
+			ID influxdb.ID `json:"id"`
+		}
+		if err := h.api.DecodeJSON(r.Body, &req); err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+
+		a := &influxdb.RoleAssignment{
+			RoleID:       *id,
+			ResourceType: rt,
+			ResourceID:   req.ID,
+		}
+		if err := h.roleSvc.AssignRole(r.Context(), a); err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		h.log.Debug("Role assigned", zap.String("assignment", fmt.Sprint(a)))
+
+		h.api.Respond(w, r, http.StatusCreated, a)
+	}
+}
+
+// handleDeleteAssignment is the HTTP handler for the DELETE /api/v2/roles/:id/users/:resourceID
+// and DELETE /api/v2/roles/:id/authorizations/:resourceID routes.
+func (h *RoleHandler) handleDeleteAssignment(rt influxdb.ResourceType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := influxdb.IDFromString(chi.URLParam(r, "id"))
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+		resourceID, err := influxdb.IDFromString(chi.URLParam(r, "resourceID"))
+		if err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+
+		if err := h.roleSvc.UnassignRole(r.Context(), influxdb.RoleAssignment{
+			RoleID:       *id,
+			ResourceType: rt,
+			ResourceID:   *resourceID,
+		}); err != nil {
+			h.api.Err(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,544 @@
+// Package role stores the roles of orgs, which are named sets of permissions,
+// and their assignments to users and authorizations.
+package role
+
+// The Service stores roles and their assignments in four kv buckets:
+//  - one for storing roles by ID;
+//  - one for indexing roles by org ID and name, which are unique;
+//  - one for storing assignments by role ID, resource ID and resource type;
+//  - one for indexing assignments by resource ID, role ID and resource type,
+//    which finds the roles of users and authorizations.
+//
+// Deleting a role deletes its assignments.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/snowflake"
+)
+
+var (
+	roleBucket            = []byte("rolesv1")
+	roleIndexBucket       = []byte("rolesbyorgandnameindexv1")
+	assignmentBucket      = []byte("roleassignmentsv1")
+	assignmentIndexBucket = []byte("roleassignmentsbyresourceindexv1")
+)
+
+var (
+	_ influxdb.RoleService           = (*Service)(nil)
+	_ influxdb.RolePermissionService = (*Service)(nil)
+)
+
+// Service implements influxdb.RoleService on a kv.Store.
+type Service struct {
+	store kv.Store
+	IDGen influxdb.IDGenerator
+	now   func() time.Time
+
+	orgSvc  influxdb.OrganizationService
+	userSvc influxdb.UserService
+	authSvc influxdb.AuthorizationService
+}
+
+// NewService returns a new instance of Service. The org, user and
+// authorization services verify the orgs of roles and the holders of
+// assignments exist.
+func NewService(ctx context.Context, st kv.Store, orgSvc influxdb.OrganizationService, userSvc influxdb.UserService, authSvc influxdb.AuthorizationService) (*Service, error) {
+	if err := st.Update(ctx, func(tx kv.Tx) error {
+		for _, b := range [][]byte{roleBucket, roleIndexBucket, assignmentBucket, assignmentIndexBucket} {
+			if _, err := tx.Bucket(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return &Service{
+		store:   st,
+		IDGen:   snowflake.NewDefaultIDGenerator(),
+		now:     time.Now,
+		orgSvc:  orgSvc,
+		userSvc: userSvc,
+		authSvc: authSvc,
+	}, nil
+}
+
+func encodeID(id influxdb.ID) []byte {
+	b, _ := id.Encode()
+	return b
+}
+
+func indexKey(orgID influxdb.ID, name string) []byte {
+	return append(encodeID(orgID), name...)
+}
+
+// assignmentKey is the key of a in the assignment bucket, and indexKey is its
+// key in the index of the resources.
+func assignmentKeys(a influxdb.RoleAssignment) (key, indexKey []byte) {
+	role, resource := encodeID(a.RoleID), encodeID(a.ResourceID)
+	key = append(append(append([]byte{}, role...), resource...), a.ResourceType...)
+	indexKey = append(append(append([]byte{}, resource...), role...), a.ResourceType...)
+	return key, indexKey
+}
+
+// FindRoleByID returns the role of the ID.
+func (s *Service) FindRoleByID(ctx context.Context, id influxdb.ID) (*influxdb.Role, error) {
+	var r *influxdb.Role
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		r, err = s.findRoleByID(tx, id)
+		return err
+	})
+	return r, err
+}
+
+func (s *Service) findRoleByID(tx kv.Tx, id influxdb.ID) (*influxdb.Role, error) {
+	encodedID, err := id.Encode()
+	if err != nil {
+		return nil, ErrInvalidRoleID
+	}
+	b, err := tx.Bucket(roleBucket)
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	v, err := b.Get(encodedID)
+	if kv.IsNotFound(err) {
+		return nil, ErrRoleNotFound
+	}
+	if err != nil {
+		return nil, ErrInternalService(err)
+	}
+	r := &influxdb.Role{}
+	if err := json.Unmarshal(v, r); err != nil {
+		return nil, ErrInternalService(err)
+	}
+	return r, nil
+}
+
+// FindRoles returns the roles that match filter, ordered by name when the
+// filter has an org, and otherwise by ID.
+func (s *Service) FindRoles(ctx context.Context, filter influxdb.RoleFilter, opt ...influxdb.FindOptions) ([]*influxdb.Role, int, error) {
+	if filter.ID != nil {
+		r, err := s.FindRoleByID(ctx, *filter.ID)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return []*influxdb.Role{}, 0, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if !filterFunc(r, filter) {
+			return []*influxdb.Role{}, 0, nil
+		}
+		return []*influxdb.Role{r}, 1, nil
+	}
+
+	rs := []*influxdb.Role{}
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		add := func(v []byte) error {
+			r := &influxdb.Role{}
+			if err := json.Unmarshal(v, r); err != nil {
+				return ErrInternalService(err)
+			}
+			if filterFunc(r, filter) {
+				rs = append(rs, r)
+			}
+			return nil
+		}
+
+		if filter.OrgID == nil {
+			return walk(tx, roleBucket, nil, func(k, v []byte) error {
+				return add(v)
+			})
+		}
+
+		b, err := tx.Bucket(roleBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		prefix := encodeID(*filter.OrgID)
+		if filter.Name != nil {
+			prefix = indexKey(*filter.OrgID, *filter.Name)
+		}
+		return walk(tx, roleIndexBucket, prefix, func(k, id []byte) error {
+			v, err := b.Get(id)
+			if err != nil {
+				return ErrInternalService(err)
+			}
+			return add(v)
+		})
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rs = applyFindOptions(rs, opt...)
+	return rs, len(rs), nil
+}
+
+func filterFunc(r *influxdb.Role, filter influxdb.RoleFilter) bool {
+	return (filter.ID == nil || r.ID == *filter.ID) &&
+		(filter.OrgID == nil || r.OrgID == *filter.OrgID) &&
+		(filter.Name == nil || r.Name == *filter.Name)
+}
+
+func applyFindOptions(rs []*influxdb.Role, opt ...influxdb.FindOptions) []*influxdb.Role {
+	if len(opt) == 0 {
+		return rs
+	}
+	o := opt[0]
+
+	if o.Descending {
+		for i, j := 0, len(rs)-1; i < j; i, j = i+1, j-1 {
+			rs[i], rs[j] = rs[j], rs[i]
+		}
+	}
+	if o.Offset > 0 {
+		if o.Offset >= len(rs) {
+			return []*influxdb.Role{}
+		}
+		rs = rs[o.Offset:]
+	}
+	if o.Limit > 0 && o.Limit < len(rs) {
+		rs = rs[:o.Limit]
+	}
+	return rs
+}
+
+// walk calls fn with the keys and values of the bucket that start with prefix.
+func walk(tx kv.Tx, bucket, prefix []byte, fn func(k, v []byte) error) error {
+	b, err := tx.Bucket(bucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	var opts []kv.CursorOption
+	if len(prefix) > 0 {
+		opts = append(opts, kv.WithCursorPrefix(prefix))
+	}
+	cur, err := b.ForwardCursor(prefix, opts...)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	defer cur.Close()
+
+	for k, v := cur.Next(); k != nil; k, v = cur.Next() {
+		if err := fn(k, v); err != nil {
+			return err
+		}
+	}
+	if err := cur.Err(); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+// CreateRole creates the role, of a name that is unique in its org.
+func (s *Service) CreateRole(ctx context.Context, r *influxdb.Role) error {
+	if err := r.Valid(); err != nil {
+		return err
+	}
+	if _, err := s.orgSvc.FindOrganizationByID(ctx, r.OrgID); err != nil {
+		return err
+	}
+
+	r.ID = s.IDGen.ID()
+	now := s.now()
+	r.SetCreatedAt(now)
+	r.SetUpdatedAt(now)
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		return s.putRole(tx, r, nil)
+	})
+}
+
+// putRole stores r, and moves its index entry from the name of old when it
+// was renamed.
+func (s *Service) putRole(tx kv.Tx, r *influxdb.Role, old *influxdb.Role) error {
+	idx, err := tx.Bucket(roleIndexBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	encodedID := encodeID(r.ID)
+
+	key := indexKey(r.OrgID, r.Name)
+	id, err := idx.Get(key)
+	if err == nil && !bytes.Equal(id, encodedID) {
+		return ErrRoleAlreadyExists(r.Name)
+	}
+	if err != nil && !kv.IsNotFound(err) {
+		return ErrInternalService(err)
+	}
+	if old != nil && old.Name != r.Name {
+		if err := idx.Delete(indexKey(old.OrgID, old.Name)); err != nil {
+			return ErrInternalService(err)
+		}
+	}
+	if err := idx.Put(key, encodedID); err != nil {
+		return ErrInternalService(err)
+	}
+
+	v, err := json.Marshal(r)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	b, err := tx.Bucket(roleBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Put(encodedID, v); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+// UpdateRole updates the role, whose holders have its new permissions from
+// then on.
+func (s *Service) UpdateRole(ctx context.Context, id influxdb.ID, upd influxdb.RoleUpdate) (*influxdb.Role, error) {
+	var r *influxdb.Role
+	err := s.store.Update(ctx, func(tx kv.Tx) error {
+		old, err := s.findRoleByID(tx, id)
+		if err != nil {
+			return err
+		}
+		updated := *old
+		upd.Apply(&updated)
+		if err := updated.Valid(); err != nil {
+			return err
+		}
+		updated.SetUpdatedAt(s.now())
+		if err := s.putRole(tx, &updated, old); err != nil {
+			return err
+		}
+		r = &updated
+		return nil
+	})
+	return r, err
+}
+
+// DeleteRole deletes the role and its assignments.
+func (s *Service) DeleteRole(ctx context.Context, id influxdb.ID) error {
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		r, err := s.findRoleByID(tx, id)
+		if err != nil {
+			return err
+		}
+
+		as, err := s.findAssignments(tx, influxdb.RoleAssignmentFilter{RoleID: &id})
+		if err != nil {
+			return err
+		}
+		for _, a := range as {
+			if err := s.deleteAssignment(tx, *a); err != nil {
+				return err
+			}
+		}
+
+		idx, err := tx.Bucket(roleIndexBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if err := idx.Delete(indexKey(r.OrgID, r.Name)); err != nil {
+			return ErrInternalService(err)
+		}
+		b, err := tx.Bucket(roleBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if err := b.Delete(encodeID(id)); err != nil {
+			return ErrInternalService(err)
+		}
+		return nil
+	})
+}
+
+// AssignRole assigns the role to a user, or to an authorization of the org of
+// the role.
+func (s *Service) AssignRole(ctx context.Context, a *influxdb.RoleAssignment) error {
+	if err := a.Valid(); err != nil {
+		return err
+	}
+	r, err := s.FindRoleByID(ctx, a.RoleID)
+	if err != nil {
+		return err
+	}
+	switch a.ResourceType {
+	case influxdb.UsersResourceType:
+		if _, err := s.userSvc.FindUserByID(ctx, a.ResourceID); err != nil {
+			return err
+		}
+	case influxdb.AuthorizationsResourceType:
+		auth, err := s.authSvc.FindAuthorizationByID(ctx, a.ResourceID)
+		if err != nil {
+			return err
+		}
+		if auth.OrgID != r.OrgID {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Msg:  "role may only be assigned to the authorizations of its org",
+			}
+		}
+	}
+
+	key, indexKey := assignmentKeys(*a)
+	v, err := json.Marshal(a)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		// the role may be deleted since it was found
+		if _, err := s.findRoleByID(tx, a.RoleID); err != nil {
+			return err
+		}
+		b, err := tx.Bucket(assignmentBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if err := b.Put(key, v); err != nil {
+			return ErrInternalService(err)
+		}
+		idx, err := tx.Bucket(assignmentIndexBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if err := idx.Put(indexKey, key); err != nil {
+			return ErrInternalService(err)
+		}
+		return nil
+	})
+}
+
+// UnassignRole removes the assignment of the role.
+func (s *Service) UnassignRole(ctx context.Context, a influxdb.RoleAssignment) error {
+	if err := a.Valid(); err != nil {
+		return err
+	}
+	return s.store.Update(ctx, func(tx kv.Tx) error {
+		key, _ := assignmentKeys(a)
+		b, err := tx.Bucket(assignmentBucket)
+		if err != nil {
+			return ErrInternalService(err)
+		}
+		if _, err := b.Get(key); kv.IsNotFound(err) {
+			return ErrAssignmentNotFound
+		} else if err != nil {
+			return ErrInternalService(err)
+		}
+		return s.deleteAssignment(tx, a)
+	})
+}
+
+func (s *Service) deleteAssignment(tx kv.Tx, a influxdb.RoleAssignment) error {
+	key, indexKey := assignmentKeys(a)
+	b, err := tx.Bucket(assignmentBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := b.Delete(key); err != nil {
+		return ErrInternalService(err)
+	}
+	idx, err := tx.Bucket(assignmentIndexBucket)
+	if err != nil {
+		return ErrInternalService(err)
+	}
+	if err := idx.Delete(indexKey); err != nil {
+		return ErrInternalService(err)
+	}
+	return nil
+}
+
+// FindRoleAssignments returns the assignments that match filter.
+func (s *Service) FindRoleAssignments(ctx context.Context, filter influxdb.RoleAssignmentFilter) ([]*influxdb.RoleAssignment, error) {
+	var as []*influxdb.RoleAssignment
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		as, err = s.findAssignments(tx, filter)
+		return err
+	})
+	return as, err
+}
+
+func (s *Service) findAssignments(tx kv.Tx, filter influxdb.RoleAssignmentFilter) ([]*influxdb.RoleAssignment, error) {
+	as := []*influxdb.RoleAssignment{}
+	add := func(v []byte) error {
+		a := &influxdb.RoleAssignment{}
+		if err := json.Unmarshal(v, a); err != nil {
+			return ErrInternalService(err)
+		}
+		if (filter.RoleID == nil || a.RoleID == *filter.RoleID) &&
+			(filter.ResourceType == nil || a.ResourceType == *filter.ResourceType) &&
+			(filter.ResourceID == nil || a.ResourceID == *filter.ResourceID) {
+			as = append(as, a)
+		}
+		return nil
+	}
+
+	var err error
+	switch {
+	case filter.RoleID != nil:
+		err = walk(tx, assignmentBucket, encodeID(*filter.RoleID), func(k, v []byte) error {
+			return add(v)
+		})
+	case filter.ResourceID != nil:
+		b, berr := tx.Bucket(assignmentBucket)
+		if berr != nil {
+			return nil, ErrInternalService(berr)
+		}
+		err = walk(tx, assignmentIndexBucket, encodeID(*filter.ResourceID), func(k, key []byte) error {
+			v, err := b.Get(key)
+			if err != nil {
+				return ErrInternalService(err)
+			}
+			return add(v)
+		})
+	default:
+		err = walk(tx, assignmentBucket, nil, func(k, v []byte) error {
+			return add(v)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return as, nil
+}
+
+// FindRolePermissions returns the permissions of the roles assigned to the
+// resource, which are looked up in a single transaction.
+func (s *Service) FindRolePermissions(ctx context.Context, rt influxdb.ResourceType, id influxdb.ID) ([]influxdb.Permission, error) {
+	var ps []influxdb.Permission
+	err := s.store.View(ctx, func(tx kv.Tx) error {
+		var err error
+		ps, err = s.RolePermissions(tx, rt, id)
+		return err
+	})
+	return ps, err
+}
+
+// RolePermissions returns the permissions of the roles assigned to the
+// resource within tx, so that the services that share the store of the roles
+// look them up in their own transactions.
+func (s *Service) RolePermissions(tx kv.Tx, rt influxdb.ResourceType, id influxdb.ID) ([]influxdb.Permission, error) {
+	as, err := s.findAssignments(tx, influxdb.RoleAssignmentFilter{
+		ResourceType: &rt,
+		ResourceID:   &id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var ps []influxdb.Permission
+	for _, a := range as {
+		r, err := s.findRoleByID(tx, a.RoleID)
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ps = append(ps, r.Permissions...)
+	}
+	return ps, nil
+}
@@ -0,0 +1,289 @@
+package role_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/role"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	orgOneID  = influxdb.ID(1)
+	orgTwoID  = influxdb.ID(2)
+	userID    = influxdb.ID(10)
+	authOneID = influxdb.ID(20)
+	authTwoID = influxdb.ID(21)
+	bucketOne = influxdb.ID(30)
+	missingID = influxdb.ID(99)
+	numRoles  = 3
+)
+
+func newTestService(t *testing.T) *role.Service {
+	t.Helper()
+	return newTestServiceOnStore(t, inmem.NewKVStore())
+}
+
+func newTestServiceOnStore(t *testing.T, st kv.Store) *role.Service {
+	t.Helper()
+
+	orgSvc := mock.NewOrganizationService()
+	orgSvc.FindOrganizationByIDF = func(_ context.Context, id influxdb.ID) (*influxdb.Organization, error) {
+		if id != orgOneID && id != orgTwoID {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "organization not found"}
+		}
+		return &influxdb.Organization{ID: id}, nil
+	}
+	userSvc := mock.NewUserService()
+	userSvc.FindUserByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.User, error) {
+		if id != userID {
+			return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "user not found"}
+		}
+		return &influxdb.User{ID: id}, nil
+	}
+	authSvc := mock.NewAuthorizationService()
+	authSvc.FindAuthorizationByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Authorization, error) {
+		switch id {
+		case authOneID:
+			return &influxdb.Authorization{ID: id, OrgID: orgOneID}, nil
+		case authTwoID:
+			return &influxdb.Authorization{ID: id, OrgID: orgTwoID}, nil
+		}
+		return nil, &influxdb.Error{Code: influxdb.ENotFound, Msg: "authorization not found"}
+	}
+
+	svc, err := role.NewService(context.Background(), st, orgSvc, userSvc, authSvc)
+	require.NoError(t, err)
+	svc.IDGen = mock.NewMockIDGenerator()
+	return svc
+}
+
+func newRole(orgID influxdb.ID, name string, perms ...influxdb.Permission) *influxdb.Role {
+	return &influxdb.Role{
+		OrgID:       orgID,
+		Name:        name,
+		Permissions: perms,
+	}
+}
+
+func bucketPermission(action influxdb.Action, orgID influxdb.ID) influxdb.Permission {
+	id := bucketOne
+	return influxdb.Permission{
+		Action: action,
+		Resource: influxdb.Resource{
+			Type:  influxdb.BucketsResourceType,
+			ID:    &id,
+			OrgID: &orgID,
+		},
+	}
+}
+
+func TestService_Roles(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	writer := newRole(orgOneID, "bucket-writer", bucketPermission(influxdb.WriteAction, orgOneID))
+	require.NoError(t, svc.CreateRole(ctx, writer))
+	require.True(t, writer.ID.Valid())
+	require.NoError(t, svc.CreateRole(ctx, newRole(orgOneID, "bucket-writer-2")))
+	require.NoError(t, svc.CreateRole(ctx, newRole(orgTwoID, "bucket-writer")))
+
+	t.Run("names are unique in an org", func(t *testing.T) {
+		err := svc.CreateRole(ctx, newRole(orgOneID, "bucket-writer"))
+		assert.Equal(t, influxdb.EConflict, influxdb.ErrorCode(err))
+	})
+
+	t.Run("permissions must be of the org", func(t *testing.T) {
+		err := svc.CreateRole(ctx, newRole(orgOneID, "other", bucketPermission(influxdb.ReadAction, orgTwoID)))
+		assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+	})
+
+	t.Run("the org must exist", func(t *testing.T) {
+		err := svc.CreateRole(ctx, newRole(missingID, "other"))
+		assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+	})
+
+	t.Run("find", func(t *testing.T) {
+		r, err := svc.FindRoleByID(ctx, writer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, writer.Permissions, r.Permissions)
+
+		_, err = svc.FindRoleByID(ctx, missingID)
+		assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+
+		rs, n, err := svc.FindRoles(ctx, influxdb.RoleFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, numRoles, n)
+		assert.Len(t, rs, numRoles)
+
+		orgID := orgOneID
+		rs, _, err = svc.FindRoles(ctx, influxdb.RoleFilter{OrgID: &orgID})
+		require.NoError(t, err)
+		require.Len(t, rs, 2)
+		assert.Equal(t, "bucket-writer", rs[0].Name)
+		assert.Equal(t, "bucket-writer-2", rs[1].Name)
+
+		name := "bucket-writer"
+		rs, _, err = svc.FindRoles(ctx, influxdb.RoleFilter{OrgID: &orgID, Name: &name})
+		require.NoError(t, err)
+		require.Len(t, rs, 1)
+		assert.Equal(t, writer.ID, rs[0].ID)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		name := "bucket-reader"
+		perms := []influxdb.Permission{bucketPermission(influxdb.ReadAction, orgOneID)}
+		r, err := svc.UpdateRole(ctx, writer.ID, influxdb.RoleUpdate{Name: &name, Permissions: &perms})
+		require.NoError(t, err)
+		assert.Equal(t, name, r.Name)
+		assert.Equal(t, perms, r.Permissions)
+
+		orgID := orgOneID
+		old := "bucket-writer"
+		rs, _, err := svc.FindRoles(ctx, influxdb.RoleFilter{OrgID: &orgID, Name: &old})
+		require.NoError(t, err)
+		assert.Empty(t, rs)
+
+		taken := "bucket-writer-2"
+		_, err = svc.UpdateRole(ctx, writer.ID, influxdb.RoleUpdate{Name: &taken})
+		assert.Equal(t, influxdb.EConflict, influxdb.ErrorCode(err))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, svc.DeleteRole(ctx, writer.ID))
+		_, err := svc.FindRoleByID(ctx, writer.ID)
+		assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+
+		// the name of a deleted role may be reused
+		require.NoError(t, svc.CreateRole(ctx, newRole(orgOneID, "bucket-reader")))
+	})
+}
+
+func TestService_Assignments(t *testing.T) {
+	ctx := context.Background()
+	svc := newTestService(t)
+
+	r := newRole(orgOneID, "bucket-writer", bucketPermission(influxdb.WriteAction, orgOneID))
+	require.NoError(t, svc.CreateRole(ctx, r))
+	other := newRole(orgOneID, "other")
+	require.NoError(t, svc.CreateRole(ctx, other))
+
+	toUser := &influxdb.RoleAssignment{RoleID: r.ID, ResourceType: influxdb.UsersResourceType, ResourceID: userID}
+	toAuth := &influxdb.RoleAssignment{RoleID: r.ID, ResourceType: influxdb.AuthorizationsResourceType, ResourceID: authOneID}
+	require.NoError(t, svc.AssignRole(ctx, toUser))
+	require.NoError(t, svc.AssignRole(ctx, toAuth))
+	require.NoError(t, svc.AssignRole(ctx, &influxdb.RoleAssignment{RoleID: other.ID, ResourceType: influxdb.UsersResourceType, ResourceID: userID}))
+
+	t.Run("invalid assignments", func(t *testing.T) {
+		tests := []struct {
+			name string
+			a    influxdb.RoleAssignment
+			code string
+		}{
+			{
+				name: "missing user",
+				a:    influxdb.RoleAssignment{RoleID: r.ID, ResourceType: influxdb.UsersResourceType, ResourceID: missingID},
+				code: influxdb.ENotFound,
+			},
+			{
+				name: "authorization of another org",
+				a:    influxdb.RoleAssignment{RoleID: r.ID, ResourceType: influxdb.AuthorizationsResourceType, ResourceID: authTwoID},
+				code: influxdb.EInvalid,
+			},
+			{
+				name: "missing role",
+				a:    influxdb.RoleAssignment{RoleID: missingID, ResourceType: influxdb.UsersResourceType, ResourceID: userID},
+				code: influxdb.ENotFound,
+			},
+			{
+				name: "resource other than users and authorizations",
+				a:    influxdb.RoleAssignment{RoleID: r.ID, ResourceType: influxdb.BucketsResourceType, ResourceID: bucketOne},
+				code: influxdb.EInvalid,
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				err := svc.AssignRole(ctx, &tt.a)
+				assert.Equal(t, tt.code, influxdb.ErrorCode(err))
+			})
+		}
+	})
+
+	t.Run("find by role", func(t *testing.T) {
+		as, err := svc.FindRoleAssignments(ctx, influxdb.RoleAssignmentFilter{RoleID: &r.ID})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []*influxdb.RoleAssignment{toUser, toAuth}, as)
+
+		rt := influxdb.AuthorizationsResourceType
+		as, err = svc.FindRoleAssignments(ctx, influxdb.RoleAssignmentFilter{RoleID: &r.ID, ResourceType: &rt})
+		require.NoError(t, err)
+		assert.Equal(t, []*influxdb.RoleAssignment{toAuth}, as)
+	})
+
+	t.Run("find by resource", func(t *testing.T) {
+		id, rt := userID, influxdb.UsersResourceType
+		as, err := svc.FindRoleAssignments(ctx, influxdb.RoleAssignmentFilter{ResourceType: &rt, ResourceID: &id})
+		require.NoError(t, err)
+		require.Len(t, as, 2)
+	})
+
+	t.Run("find permissions", func(t *testing.T) {
+		ps, err := svc.FindRolePermissions(ctx, influxdb.UsersResourceType, userID)
+		require.NoError(t, err)
+		assert.Equal(t, r.Permissions, ps)
+
+		ps, err = svc.FindRolePermissions(ctx, influxdb.AuthorizationsResourceType, authOneID)
+		require.NoError(t, err)
+		assert.Equal(t, r.Permissions, ps)
+
+		ps, err = svc.FindRolePermissions(ctx, influxdb.AuthorizationsResourceType, authTwoID)
+		require.NoError(t, err)
+		assert.Empty(t, ps)
+	})
+
+	t.Run("unassign", func(t *testing.T) {
+		require.NoError(t, svc.UnassignRole(ctx, *toAuth))
+		err := svc.UnassignRole(ctx, *toAuth)
+		assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+
+		id, rt := authOneID, influxdb.AuthorizationsResourceType
+		as, err := svc.FindRoleAssignments(ctx, influxdb.RoleAssignmentFilter{ResourceType: &rt, ResourceID: &id})
+		require.NoError(t, err)
+		assert.Empty(t, as)
+	})
+
+	t.Run("deleting a role deletes its assignments", func(t *testing.T) {
+		require.NoError(t, svc.DeleteRole(ctx, r.ID))
+
+		id, rt := userID, influxdb.UsersResourceType
+		as, err := svc.FindRoleAssignments(ctx, influxdb.RoleAssignmentFilter{ResourceType: &rt, ResourceID: &id})
+		require.NoError(t, err)
+		require.Len(t, as, 1)
+		assert.Equal(t, other.ID, as[0].RoleID)
+	})
+}
+
+func TestService_RolePermissionsInTx(t *testing.T) {
+	ctx := context.Background()
+	st := inmem.NewKVStore()
+	svc := newTestServiceOnStore(t, st)
+
+	r := newRole(orgOneID, "bucket-writer", bucketPermission(influxdb.WriteAction, orgOneID))
+	require.NoError(t, svc.CreateRole(ctx, r))
+	require.NoError(t, svc.AssignRole(ctx, &influxdb.RoleAssignment{RoleID: r.ID, ResourceType: influxdb.UsersResourceType, ResourceID: userID}))
+
+	// the services that share the store look up the roles in their own
+	// transactions, such as the kv service does for the owners of tasks
+	err := st.Update(ctx, func(tx kv.Tx) error {
+		ps, err := svc.RolePermissions(tx, influxdb.UsersResourceType, userID)
+		require.NoError(t, err)
+		assert.Equal(t, r.Permissions, ps)
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -40,6 +40,10 @@ type Session struct {
 	ExpiresAt   time.Time    `json:"expiresAt"`
 	UserID      ID           `json:"userID,omitempty"`
 	Permissions []Permission `json:"permissions,omitempty"`
+
+	// RolePermissions are the permissions of the roles of the user, which are
+	// looked up as the session is used rather than stored.
+	RolePermissions []Permission `json:"-"`
 }
 
 // Expired returns an error if the session is expired.
@@ -63,7 +67,12 @@ func (s *Session) PermissionSet() (PermissionSet, error) {
 		}
 	}
 
-	return s.Permissions, nil
+	if len(s.RolePermissions) == 0 {
+		return s.Permissions, nil
+	}
+	ps := make(PermissionSet, 0, len(s.Permissions)+len(s.RolePermissions))
+	ps = append(ps, s.Permissions...)
+	return append(ps, s.RolePermissions...), nil
 }
 
 // Kind returns session and is used for auditing.
@@ -81,11 +90,12 @@ func (s *Session) GetUserID() ID {
 // but at the user's max privs.
 func (s *Session) EphemeralAuth(orgID ID) *Authorization {
 	return &Authorization{
-		ID:          s.ID,
-		OrgID:       orgID,
-		Status:      Active,
-		UserID:      s.UserID,
-		Permissions: s.Permissions,
+		ID:              s.ID,
+		OrgID:           orgID,
+		Status:          Active,
+		UserID:          s.UserID,
+		Permissions:     s.Permissions,
+		RolePermissions: s.RolePermissions,
 	}
 }
 
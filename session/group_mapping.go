@@ -0,0 +1,109 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+// GroupMapping makes the users of a group of an external directory, such as
+// an identity provider, members, or owners, of an org.
+type GroupMapping struct {
+	Group string
+	Org   string
+	Role  influxdb.UserType
+}
+
+// ParseGroupMapping parses a mapping of the form group=org:role, where the
+// role is owner or member.
+func ParseGroupMapping(s string) (GroupMapping, error) {
+	var m GroupMapping
+	i := strings.LastIndex(s, "=")
+	j := strings.LastIndex(s, ":")
+	if i <= 0 || j <= i+1 || j == len(s)-1 {
+		return m, fmt.Errorf("group mapping %q is not of the form group=org:role", s)
+	}
+	m.Group, m.Org, m.Role = s[:i], s[i+1:j], influxdb.UserType(s[j+1:])
+	if m.Role != influxdb.Owner && m.Role != influxdb.Member {
+		return m, fmt.Errorf("group mapping %q has role %q, which is not owner or member", s, m.Role)
+	}
+	return m, nil
+}
+
+// SyncGroupRoles makes the user a member, or owner, of the orgs that groups
+// map to, and removes them from the other orgs of the mappings. The orgs that
+// no mapping names are left as they are.
+func SyncGroupRoles(ctx context.Context, log *zap.Logger, orgSvc influxdb.OrganizationService, urmSvc influxdb.UserResourceMappingService, userID influxdb.ID, mappings []GroupMapping, groups []string) error {
+	if len(mappings) == 0 {
+		return nil
+	}
+
+	member := make(map[string]bool, len(groups))
+	for _, g := range groups {
+		member[g] = true
+	}
+	roles := make(map[string]influxdb.UserType)
+	for _, m := range mappings {
+		if _, ok := roles[m.Org]; !ok {
+			roles[m.Org] = ""
+		}
+		// owners are members as well
+		if member[m.Group] && roles[m.Org] != influxdb.Owner {
+			roles[m.Org] = m.Role
+		}
+	}
+	orgs := make([]string, 0, len(roles))
+	for org := range roles {
+		orgs = append(orgs, org)
+	}
+	sort.Strings(orgs)
+
+	for _, name := range orgs {
+		name, role := name, roles[name]
+		o, err := orgSvc.FindOrganization(ctx, influxdb.OrganizationFilter{Name: &name})
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			log.Warn("Organization of group mapping not found", zap.String("org", name))
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		urms, _, err := urmSvc.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.OrgsResourceType,
+			ResourceID:   o.ID,
+			UserID:       userID,
+		})
+		if err != nil {
+			return err
+		}
+		var current influxdb.UserType
+		if len(urms) > 0 {
+			current = urms[0].UserType
+		}
+		if current == role {
+			continue
+		}
+
+		if current != "" {
+			if err := urmSvc.DeleteUserResourceMapping(ctx, o.ID, userID); err != nil {
+				return err
+			}
+		}
+		if role != "" {
+			if err := urmSvc.CreateUserResourceMapping(ctx, &influxdb.UserResourceMapping{
+				UserID:       userID,
+				UserType:     role,
+				MappingType:  influxdb.UserMappingType,
+				ResourceType: influxdb.OrgsResourceType,
+				ResourceID:   o.ID,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
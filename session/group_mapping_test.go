@@ -0,0 +1,22 @@
+package session
+
+import (
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestParseGroupMapping(t *testing.T) {
+	m, err := ParseGroupMapping("cn=admins,dc=example=acme:owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Group != "cn=admins,dc=example" || m.Org != "acme" || m.Role != influxdb.Owner {
+		t.Fatalf("unexpected mapping %+v", m)
+	}
+	for _, s := range []string{"admins", "admins=acme", "admins=acme:", "=acme:owner", "admins=acme:admin"} {
+		if _, err := ParseGroupMapping(s); err == nil {
+			t.Errorf("expected %q to be invalid", s)
+		}
+	}
+}
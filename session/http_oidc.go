@@ -0,0 +1,344 @@
+package session
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	"github.com/influxdata/influxdb/v2"
+	kithttp "github.com/influxdata/influxdb/v2/kit/transport/http"
+	irand "github.com/influxdata/influxdb/v2/rand"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+)
+
+const (
+	prefixOIDCSignIn = "/api/v2/signin/oidc"
+
+	// oidcSignInTTL is how long users have to sign in with the identity
+	// provider once they are redirected to it.
+	oidcSignInTTL = 10 * time.Minute
+
+	cookieOIDCStateName = "oidc_state"
+
+	defaultOIDCUsernameClaim = "preferred_username"
+	defaultOIDCGroupsClaim   = "groups"
+)
+
+// oidcSignIn is a sign in that waits for the callback of the identity
+// provider. It is sealed in the state cookie of the browser that signs in.
+type oidcSignIn struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Redirect string `json:"redirect"`
+	Expires  int64  `json:"expires"`
+}
+
+// OIDCHandler signs users in with an OpenID Connect identity provider, with
+// the authorization code flow and PKCE. The users are created the first time
+// they sign in, and their memberships of orgs follow their groups.
+type OIDCHandler struct {
+	chi.Router
+	api *kithttp.API
+	log *zap.Logger
+
+	config   OIDCConfig
+	provider *oidcProvider
+	tokenGen influxdb.TokenGenerator
+
+	sessionSvc influxdb.SessionService
+	userSvc    influxdb.UserService
+	orgSvc     influxdb.OrganizationService
+	urmSvc     influxdb.UserResourceMappingService
+
+	// state seals and opens the sign ins.
+	state cipher.AEAD
+}
+
+// NewOIDCHandler returns a new instance of OIDCHandler.
+func NewOIDCHandler(log *zap.Logger, config OIDCConfig, sessionSvc influxdb.SessionService, userSvc influxdb.UserService, orgSvc influxdb.OrganizationService, urmSvc influxdb.UserResourceMappingService) *OIDCHandler {
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = defaultOIDCUsernameClaim
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = defaultOIDCGroupsClaim
+	}
+
+	key := config.StateKey
+	if key == "" {
+		key = config.ClientSecret
+	}
+	if key == "" {
+		log.Warn("Sign ins with the identity provider only complete on the node that starts them, without a state key")
+		b := make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, b); err != nil {
+			panic(err)
+		}
+		key = string(b)
+	}
+
+	h := &OIDCHandler{
+		api:      kithttp.NewAPI(kithttp.WithLog(log)),
+		log:      log,
+		config:   config,
+		provider: newOIDCProvider(config),
+		// the tokens are URL safe without padding, as PKCE requires
+		tokenGen:   irand.NewTokenGenerator(48),
+		sessionSvc: sessionSvc,
+		userSvc:    userSvc,
+		orgSvc:     orgSvc,
+		urmSvc:     urmSvc,
+		state:      newStateAEAD(key),
+	}
+
+	r := chi.NewRouter()
+	r.Use(
+		middleware.Recoverer,
+		middleware.RequestID,
+		middleware.RealIP,
+	)
+	r.Get("/", h.handleSignIn)
+	r.Get("/callback", h.handleCallback)
+
+	h.Router = r
+	return h
+}
+
+// Prefix is necessary to mount the router as a resource handler
+func (h *OIDCHandler) Prefix() string { return prefixOIDCSignIn }
+
+// handleSignIn is the HTTP handler for the GET /api/v2/signin/oidc route,
+// which redirects to the identity provider.
+func (h *OIDCHandler) handleSignIn(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	config, err := h.provider.oauth2Config(ctx)
+	if err != nil {
+		h.log.Error("Failed to discover identity provider", zap.String("issuer", h.config.Issuer), zap.Error(err))
+		h.api.Err(w, r, &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "identity provider is unavailable",
+		})
+		return
+	}
+
+	var s oidcSignIn
+	s.State, err = h.tokenGen.Token()
+	if err == nil {
+		s.Nonce, err = h.tokenGen.Token()
+	}
+	if err == nil {
+		s.Verifier, err = h.tokenGen.Token()
+	}
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+	s.Redirect = localRedirect(r.URL.Query().Get("redirect"))
+	s.Expires = time.Now().Add(oidcSignInTTL).Unix()
+
+	sealed, err := h.seal(s)
+	if err != nil {
+		h.api.Err(w, r, err)
+		return
+	}
+
+	// the sign in is bound to the browser, so that its state cannot be used
+	// to sign in someone else
+	http.SetCookie(w, &http.Cookie{
+		Name:     cookieOIDCStateName,
+		Value:    sealed,
+		Path:     prefixOIDCSignIn,
+		MaxAge:   int(oidcSignInTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   h.secureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, config.AuthCodeURL(s.State,
+		oauth2.SetAuthURLParam("nonce", s.Nonce),
+		oauth2.SetAuthURLParam("code_challenge", pkceChallenge(s.Verifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	), http.StatusFound)
+}
+
+// handleCallback is the HTTP handler for the GET /api/v2/signin/oidc/callback
+// route, which the identity provider redirects to once users signed in.
+func (h *OIDCHandler) handleCallback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	u, redirect, err := h.callback(ctx, r)
+	if err != nil {
+		h.log.Info("Failed to sign in with identity provider", zap.Error(err))
+		h.api.Err(w, r, ErrUnauthorized)
+		return
+	}
+
+	s, err := h.sessionSvc.CreateSession(ctx, u.Name)
+	if err != nil {
+		h.api.Err(w, r, ErrUnauthorized)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{Name: cookieOIDCStateName, Path: prefixOIDCSignIn, MaxAge: -1, HttpOnly: true, Secure: h.secureCookies()})
+	encodeCookieSession(w, s)
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+// callback completes the sign in of the callback request r, and returns the
+// signed in user and where to redirect them.
+func (h *OIDCHandler) callback(ctx context.Context, r *http.Request) (*influxdb.User, string, error) {
+	qp := r.URL.Query()
+	if e := qp.Get("error"); e != "" {
+		return nil, "", fmt.Errorf("identity provider error %q: %s", e, qp.Get("error_description"))
+	}
+
+	c, err := r.Cookie(cookieOIDCStateName)
+	if err != nil {
+		return nil, "", fmt.Errorf("sign in state is missing")
+	}
+	s, err := h.open(c.Value)
+	if err != nil {
+		return nil, "", err
+	}
+	if state := qp.Get("state"); state == "" || state != s.State {
+		return nil, "", fmt.Errorf("sign in state does not match")
+	}
+	if time.Now().Unix() > s.Expires {
+		return nil, "", fmt.Errorf("sign in expired")
+	}
+
+	config, err := h.provider.oauth2Config(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	tok, err := config.Exchange(ctx, qp.Get("code"), oauth2.SetAuthURLParam("code_verifier", s.Verifier))
+	if err != nil {
+		return nil, "", err
+	}
+	raw, ok := tok.Extra("id_token").(string)
+	if !ok {
+		return nil, "", fmt.Errorf("identity provider returned no ID token")
+	}
+	claims, err := h.provider.verify(ctx, raw, s.Nonce)
+	if err != nil {
+		return nil, "", err
+	}
+
+	u, err := h.findOrCreateUser(ctx, claims)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := SyncGroupRoles(ctx, h.log, h.orgSvc, h.urmSvc, u.ID, h.config.GroupMappings, claims.strings(h.config.GroupsClaim)); err != nil {
+		return nil, "", err
+	}
+	return u, s.Redirect, nil
+}
+
+// secureCookies reports whether the cookies of the sign in are only sent over
+// HTTPS, which they are unless the callback is registered over plain HTTP.
+func (h *OIDCHandler) secureCookies() bool {
+	return !strings.HasPrefix(h.config.RedirectURL, "http://")
+}
+
+// newStateAEAD returns the cipher that seals sign ins with key.
+func newStateAEAD(key string) cipher.AEAD {
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return aead
+}
+
+// seal encrypts and authenticates s, so that it can be kept by the browser.
+func (h *OIDCHandler) seal(s oidcSignIn) (string, error) {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, h.state.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(h.state.Seal(nonce, nonce, b, []byte(cookieOIDCStateName))), nil
+}
+
+// open returns the sign in sealed in v.
+func (h *OIDCHandler) open(v string) (oidcSignIn, error) {
+	var s oidcSignIn
+	b, err := base64.RawURLEncoding.DecodeString(v)
+	if err != nil || len(b) < h.state.NonceSize() {
+		return s, errors.New("sign in state is invalid")
+	}
+	b, err = h.state.Open(nil, b[:h.state.NonceSize()], b[h.state.NonceSize():], []byte(cookieOIDCStateName))
+	if err != nil {
+		return s, errors.New("sign in state is invalid")
+	}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return s, errors.New("sign in state is invalid")
+	}
+	return s, nil
+}
+
+// findOrCreateUser returns the user of claims, which is created the first
+// time the user signs in. Users that were not created by the identity
+// provider cannot sign in with it.
+func (h *OIDCHandler) findOrCreateUser(ctx context.Context, claims oidcClaims) (*influxdb.User, error) {
+	oauthID := claims.string("iss") + "#" + claims.string("sub")
+	name := claims.string(h.config.UsernameClaim)
+	if name == "" {
+		name = claims.string("email")
+	}
+	if name == "" {
+		name = claims.string("sub")
+	}
+
+	u, err := h.userSvc.FindUser(ctx, influxdb.UserFilter{Name: &name})
+	if err == nil {
+		if u.OAuthID != oauthID {
+			return nil, fmt.Errorf("user %q is not linked to the identity provider", name)
+		}
+		if u.Status == influxdb.Inactive {
+			return nil, fmt.Errorf("user %q is inactive", name)
+		}
+		return u, nil
+	}
+	if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return nil, err
+	}
+
+	u = &influxdb.User{
+		Name:    name,
+		OAuthID: oauthID,
+		Status:  influxdb.Active,
+	}
+	if err := h.userSvc.CreateUser(ctx, u); err != nil {
+		return nil, err
+	}
+	h.log.Info("Created user of identity provider", zap.String("user", name), zap.String("userID", u.ID.String()))
+	return u, nil
+}
+
+// localRedirect returns redirect if it is a path of this host, so that the
+// sign in cannot redirect elsewhere.
+func localRedirect(redirect string) string {
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		return "/"
+	}
+	return redirect
+}
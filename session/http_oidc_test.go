@@ -0,0 +1,293 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	gojwt "github.com/dgrijalva/jwt-go"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/tenant"
+	"go.uber.org/zap/zaptest"
+)
+
+// stubIdP is an OpenID Connect identity provider that authorizes a single
+// user, with the claims of the test.
+type stubIdP struct {
+	*httptest.Server
+	t      *testing.T
+	key    *rsa.PrivateKey
+	claims gojwt.MapClaims
+
+	// the code challenge and nonce of the last authorization
+	challenge, nonce string
+}
+
+func newStubIdP(t *testing.T) *stubIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &stubIdP{t: t, key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"jwks_uri":               idp.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.PostFormValue("code") != "code" || pkceChallenge(r.PostFormValue("code_verifier")) != idp.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		claims := gojwt.MapClaims{
+			"iss":   idp.URL,
+			"aud":   "client",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": idp.nonce,
+		}
+		for k, v := range idp.claims {
+			claims[k] = v
+		}
+		tok := gojwt.NewWithClaims(gojwt.SigningMethodRS256, claims)
+		tok.Header["kid"] = "key"
+		raw, err := tok.SignedString(key)
+		if err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     raw,
+		})
+	})
+	idp.Server = httptest.NewServer(mux)
+	return idp
+}
+
+// signIn signs in through h, and returns the response to the callback.
+func (idp *stubIdP) signIn(h *OIDCHandler) *httptest.ResponseRecorder {
+	idp.t.Helper()
+	return idp.signInAcross(h, h, nil)
+}
+
+// signInAcross starts to sign in through start and completes the sign in
+// through callback, with the state cookie changed by tamper if it is set, and
+// returns the response to the callback.
+func (idp *stubIdP) signInAcross(start, callback *OIDCHandler, tamper func(*http.Cookie)) *httptest.ResponseRecorder {
+	t := idp.t
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	start.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?redirect=/orgs", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("expected a redirect to the identity provider, got %d: %s", w.Code, w.Body.String())
+	}
+	loc, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	qp := loc.Query()
+	if qp.Get("code_challenge_method") != "S256" || qp.Get("client_id") != "client" {
+		t.Fatalf("unexpected authorization request %s", loc)
+	}
+	idp.challenge, idp.nonce = qp.Get("code_challenge"), qp.Get("nonce")
+
+	r := httptest.NewRequest(http.MethodGet, "/callback?code=code&state="+url.QueryEscape(qp.Get("state")), nil)
+	for _, c := range w.Result().Cookies() {
+		if c.Name == cookieOIDCStateName && tamper != nil {
+			tamper(c)
+		}
+		r.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	callback.ServeHTTP(w, r)
+	return w
+}
+
+func TestOIDCHandler(t *testing.T) {
+	idp := newStubIdP(t)
+	defer idp.Close()
+
+	ctx := context.Background()
+	st, err := tenant.NewStore(inmem.NewKVStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := tenant.NewService(st)
+	acme, other := &influxdb.Organization{Name: "acme"}, &influxdb.Organization{Name: "other"}
+	for _, o := range []*influxdb.Organization{acme, other} {
+		if err := ts.CreateOrganization(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ts.CreateUser(ctx, &influxdb.User{Name: "local"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := mock.NewSessionService()
+	sessions.CreateSessionFn = func(ctx context.Context, user string) (*influxdb.Session, error) {
+		return &influxdb.Session{Key: "session-" + user}, nil
+	}
+	h := NewOIDCHandler(zaptest.NewLogger(t), OIDCConfig{
+		Issuer:      idp.URL,
+		ClientID:    "client",
+		RedirectURL: "http://influxdb/api/v2/signin/oidc/callback",
+		GroupMappings: []GroupMapping{
+			{Group: "admins", Org: "acme", Role: influxdb.Owner},
+			{Group: "devs", Org: "acme", Role: influxdb.Member},
+			{Group: "devs", Org: "other", Role: influxdb.Member},
+		},
+	}, sessions, ts, ts, ts)
+
+	role := func(u *influxdb.User, o *influxdb.Organization) influxdb.UserType {
+		t.Helper()
+		urms, _, err := ts.FindUserResourceMappings(ctx, influxdb.UserResourceMappingFilter{
+			ResourceType: influxdb.OrgsResourceType,
+			ResourceID:   o.ID,
+			UserID:       u.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(urms) == 0 {
+			return ""
+		}
+		return urms[0].UserType
+	}
+
+	// the first sign in creates the user, with the roles of their groups
+	idp.claims = gojwt.MapClaims{"sub": "1234", "preferred_username": "jane", "groups": []string{"admins", "devs"}}
+	w := idp.signIn(h)
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/orgs" {
+		t.Fatalf("expected a redirect to the sign in redirect, got %d %q: %s", w.Code, w.Header().Get("Location"), w.Body.String())
+	}
+	var session string
+	for _, c := range w.Result().Cookies() {
+		if c.Name == cookieSessionName {
+			session = c.Value
+		}
+	}
+	if session != "session-jane" {
+		t.Fatalf("expected the session of the user, got %q", session)
+	}
+
+	u, err := ts.FindUser(ctx, influxdb.UserFilter{Name: strPtr("jane")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.OAuthID != idp.URL+"#1234" {
+		t.Fatalf("unexpected OAuth ID %q", u.OAuthID)
+	}
+	if r := role(u, acme); r != influxdb.Owner {
+		t.Fatalf("expected the owner of acme, got %q", r)
+	}
+	if r := role(u, other); r != influxdb.Member {
+		t.Fatalf("expected a member of other, got %q", r)
+	}
+
+	// the roles follow the groups of the user at every sign in
+	idp.claims["groups"] = []string{"admins"}
+	if w := idp.signIn(h); w.Code != http.StatusFound {
+		t.Fatalf("expected the user to sign in again, got %d: %s", w.Code, w.Body.String())
+	}
+	if r := role(u, other); r != "" {
+		t.Fatalf("expected the user to be removed from other, got %q", r)
+	}
+
+	// users that were not created by the identity provider cannot sign in with it
+	idp.claims = gojwt.MapClaims{"sub": "5678", "preferred_username": "local"}
+	if w := idp.signIn(h); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a local user not to sign in, got %d", w.Code)
+	}
+
+	// the ID token must be of the nonce of the sign in
+	idp.claims = gojwt.MapClaims{"sub": "1234", "preferred_username": "jane", "nonce": "replayed"}
+	if w := idp.signIn(h); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a replayed ID token not to sign in, got %d", w.Code)
+	}
+}
+
+func TestOIDCHandler_State(t *testing.T) {
+	idp := newStubIdP(t)
+	defer idp.Close()
+
+	st, err := tenant.NewStore(inmem.NewKVStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := tenant.NewService(st)
+	sessions := mock.NewSessionService()
+	sessions.CreateSessionFn = func(ctx context.Context, user string) (*influxdb.Session, error) {
+		return &influxdb.Session{Key: "session-" + user}, nil
+	}
+	newHandler := func(stateKey string) *OIDCHandler {
+		return NewOIDCHandler(zaptest.NewLogger(t), OIDCConfig{
+			Issuer:      idp.URL,
+			ClientID:    "client",
+			RedirectURL: "https://influxdb/api/v2/signin/oidc/callback",
+			StateKey:    stateKey,
+		}, sessions, ts, ts, ts)
+	}
+	a, b, other := newHandler("shared"), newHandler("shared"), newHandler("other")
+	idp.claims = gojwt.MapClaims{"sub": "1234", "preferred_username": "jane"}
+
+	// the state cookie only goes over HTTPS
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != cookieOIDCStateName || !cookies[0].Secure || !cookies[0].HttpOnly {
+		t.Fatalf("expected a secure state cookie, got %v", cookies)
+	}
+
+	// the nodes that share the state key complete the sign ins of each other
+	if w := idp.signInAcross(a, b, nil); w.Code != http.StatusFound {
+		t.Fatalf("expected the sign in to complete on another node, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := idp.signInAcross(a, other, nil); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected the sign in not to complete with another state key, got %d", w.Code)
+	}
+
+	// the state cookie cannot be changed by the browser
+	tamper := func(c *http.Cookie) {
+		v := []byte(c.Value)
+		if i := len(v) / 2; v[i] == 'A' {
+			v[i] = 'B'
+		} else {
+			v[i] = 'A'
+		}
+		c.Value = string(v)
+	}
+	if w := idp.signInAcross(a, a, tamper); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a tampered sign in not to complete, got %d", w.Code)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -24,11 +24,23 @@ type SessionHandler struct {
 
 	sessionSvc influxdb.SessionService
 	passSvc    influxdb.PasswordsService
+	signInSvc  influxdb.SignInService
 	userSvc    influxdb.UserService
 }
 
+// SessionHandlerOption configures a SessionHandler.
+type SessionHandlerOption func(*SessionHandler)
+
+// WithSignInService makes the handler verify the credentials of sign ins with
+// svc, rather than with the local passwords of users.
+func WithSignInService(svc influxdb.SignInService) SessionHandlerOption {
+	return func(h *SessionHandler) {
+		h.signInSvc = svc
+	}
+}
+
 // NewSessionHandler returns a new instance of SessionHandler.
-func NewSessionHandler(log *zap.Logger, sessionSvc influxdb.SessionService, userSvc influxdb.UserService, passwordsSvc influxdb.PasswordsService) *SessionHandler {
+func NewSessionHandler(log *zap.Logger, sessionSvc influxdb.SessionService, userSvc influxdb.UserService, passwordsSvc influxdb.PasswordsService, opts ...SessionHandlerOption) *SessionHandler {
 	svr := &SessionHandler{
 		api: kithttp.NewAPI(kithttp.WithLog(log)),
 		log: log,
@@ -37,6 +49,9 @@ func NewSessionHandler(log *zap.Logger, sessionSvc influxdb.SessionService, user
 		sessionSvc: sessionSvc,
 		userSvc:    userSvc,
 	}
+	for _, opt := range opts {
+		opt(svr)
+	}
 
 	return svr
 }
@@ -85,15 +100,7 @@ func (h *SessionHandler) handleSignin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	u, err := h.userSvc.FindUser(ctx, influxdb.UserFilter{
-		Name: &req.Username,
-	})
-	if err != nil {
-		h.api.Err(w, r, ErrUnauthorized)
-		return
-	}
-
-	if err := h.passSvc.ComparePassword(ctx, u.ID, req.Password); err != nil {
+	if err := h.signIn(ctx, req.Username, req.Password); err != nil {
 		h.api.Err(w, r, ErrUnauthorized)
 		return
 	}
@@ -108,6 +115,23 @@ func (h *SessionHandler) handleSignin(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// signIn verifies the credentials with the sign in service when there is one,
+// and otherwise with the local password of the user.
+func (h *SessionHandler) signIn(ctx context.Context, username, password string) error {
+	if h.signInSvc != nil {
+		_, err := h.signInSvc.SignIn(ctx, username, password)
+		return err
+	}
+
+	u, err := h.userSvc.FindUser(ctx, influxdb.UserFilter{
+		Name: &username,
+	})
+	if err != nil {
+		return err
+	}
+	return h.passSvc.ComparePassword(ctx, u.ID, password)
+}
+
 type signinRequest struct {
 	Username string
 	Password string
@@ -12,10 +12,35 @@ import (
 	"go.uber.org/zap/zaptest"
 )
 
+// signInServiceFunc is an influxdb.SignInService of a func.
+type signInServiceFunc func(ctx context.Context, username, password string) (*influxdb.User, error)
+
+func (f signInServiceFunc) SignIn(ctx context.Context, username, password string) (*influxdb.User, error) {
+	return f(ctx, username, password)
+}
+
 func TestSessionHandler_handleSignin(t *testing.T) {
+	session := &mock.SessionService{
+		CreateSessionFn: func(context.Context, string) (*influxdb.Session, error) {
+			return &influxdb.Session{
+				ID:        influxdb.ID(0),
+				Key:       "abc123xyz",
+				CreatedAt: time.Date(2018, 9, 26, 0, 0, 0, 0, time.UTC),
+				ExpiresAt: time.Date(2030, 9, 26, 0, 0, 0, 0, time.UTC),
+				UserID:    influxdb.ID(1),
+			}, nil
+		},
+	}
+	wrongPassword := &mock.PasswordsService{
+		ComparePasswordFn: func(context.Context, influxdb.ID, string) error {
+			return ErrUnauthorized
+		},
+	}
+
 	type fields struct {
 		PasswordsService influxdb.PasswordsService
 		SessionService   influxdb.SessionService
+		SignInService    influxdb.SignInService
 	}
 	type args struct {
 		user     string
@@ -61,6 +86,62 @@ func TestSessionHandler_handleSignin(t *testing.T) {
 				code:   http.StatusNoContent,
 			},
 		},
+		{
+			name: "wrong password",
+			fields: fields{
+				SessionService:   session,
+				PasswordsService: wrongPassword,
+			},
+			args: args{
+				user:     "user1",
+				password: "wrong",
+			},
+			wants: wants{
+				code: http.StatusUnauthorized,
+			},
+		},
+		{
+			name: "successful sign in service",
+			fields: fields{
+				SessionService:   session,
+				PasswordsService: wrongPassword,
+				SignInService: signInServiceFunc(func(_ context.Context, username, password string) (*influxdb.User, error) {
+					if username != "user1" || password != "supersecret" {
+						return nil, ErrUnauthorized
+					}
+					return &influxdb.User{ID: 1, Name: username}, nil
+				}),
+			},
+			args: args{
+				user:     "user1",
+				password: "supersecret",
+			},
+			wants: wants{
+				cookie: "session=abc123xyz",
+				code:   http.StatusNoContent,
+			},
+		},
+		{
+			name: "sign in service rejects credentials",
+			fields: fields{
+				SessionService: session,
+				PasswordsService: &mock.PasswordsService{
+					ComparePasswordFn: func(context.Context, influxdb.ID, string) error {
+						return nil
+					},
+				},
+				SignInService: signInServiceFunc(func(context.Context, string, string) (*influxdb.User, error) {
+					return nil, ErrUnauthorized
+				}),
+			},
+			args: args{
+				user:     "user1",
+				password: "supersecret",
+			},
+			wants: wants{
+				code: http.StatusUnauthorized,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -69,7 +150,11 @@ func TestSessionHandler_handleSignin(t *testing.T) {
 			userSVC.FindUserFn = func(_ context.Context, f influxdb.UserFilter) (*influxdb.User, error) {
 				return &influxdb.User{ID: 1}, nil
 			}
-			h := NewSessionHandler(zaptest.NewLogger(t), tt.fields.SessionService, userSVC, tt.fields.PasswordsService)
+			var opts []SessionHandlerOption
+			if tt.fields.SignInService != nil {
+				opts = append(opts, WithSignInService(tt.fields.SignInService))
+			}
+			h := NewSessionHandler(zaptest.NewLogger(t), tt.fields.SessionService, userSVC, tt.fields.PasswordsService, opts...)
 
 			server := httptest.NewServer(h.SignInResourceHandler())
 			client := server.Client()
@@ -0,0 +1,247 @@
+package session
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	gojwt "github.com/dgrijalva/jwt-go"
+	"golang.org/x/oauth2"
+)
+
+// OIDCConfig configures the sign in of users with an OpenID Connect identity
+// provider.
+type OIDCConfig struct {
+	// Issuer is the URL of the identity provider, where its configuration is
+	// discovered.
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+
+	// RedirectURL is the URL of the callback of the sign in, which is
+	// registered with the identity provider.
+	RedirectURL string
+
+	// Scopes are requested in addition to the openid scope.
+	Scopes []string
+
+	// UsernameClaim is the claim of the ID token that is the name of the user,
+	// and GroupsClaim the claim that lists the groups of the user.
+	UsernameClaim string
+	GroupsClaim   string
+
+	// GroupMappings map the groups of users to their roles in orgs.
+	GroupMappings []GroupMapping
+
+	// StateKey seals the state of the sign ins in a cookie of the browser,
+	// so that any node that shares it completes the sign ins of the others.
+	// It defaults to the ClientSecret, or to a key of the node when there is
+	// none.
+	StateKey string
+}
+
+// Enabled reports whether the sign in with an identity provider is configured.
+func (c OIDCConfig) Enabled() bool {
+	return c.Issuer != "" && c.ClientID != ""
+}
+
+// oidcClaims are the claims of an ID token.
+type oidcClaims map[string]interface{}
+
+func (c oidcClaims) string(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// strings returns the claim name, which is either a list of strings or a
+// single string.
+func (c oidcClaims) strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		ss := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				ss = append(ss, s)
+			}
+		}
+		return ss
+	}
+	return nil
+}
+
+// oidcProvider discovers the endpoints and keys of an identity provider, and
+// verifies the ID tokens it issues.
+type oidcProvider struct {
+	config OIDCConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	oauth2   *oauth2.Config
+	jwksURI  string
+	keys     map[string]*rsa.PublicKey
+	keysTime time.Time
+}
+
+func newOIDCProvider(config OIDCConfig) *oidcProvider {
+	return &oidcProvider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// oauth2Config discovers the configuration of the provider, the first time
+// it is needed.
+func (p *oidcProvider) oauth2Config(ctx context.Context) (*oauth2.Config, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.oauth2 != nil {
+		return p.oauth2, nil
+	}
+
+	var discovery struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+	}
+	url := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(ctx, url, &discovery); err != nil {
+		return nil, err
+	}
+	if discovery.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("identity provider issuer %q does not match %q", discovery.Issuer, p.config.Issuer)
+	}
+
+	p.jwksURI = discovery.JWKSURI
+	p.oauth2 = &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: p.config.ClientSecret,
+		RedirectURL:  p.config.RedirectURL,
+		Scopes:       append([]string{"openid"}, p.config.Scopes...),
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  discovery.AuthorizationEndpoint,
+			TokenURL: discovery.TokenEndpoint,
+		},
+	}
+	return p.oauth2, nil
+}
+
+// key returns the key kid of the provider, which refreshes its keys when it
+// does not know kid, at most once a minute.
+func (p *oidcProvider) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if k, ok := p.keys[kid]; ok {
+		return k, nil
+	}
+	if time.Since(p.keysTime) < time.Minute {
+		return nil, fmt.Errorf("identity provider key %q not found", kid)
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := p.getJSON(ctx, p.jwksURI, &jwks); err != nil {
+		return nil, err
+	}
+	p.keys = make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	p.keysTime = time.Now()
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		p.keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	k, ok := p.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("identity provider key %q not found", kid)
+	}
+	return k, nil
+}
+
+// verify verifies the ID token raw, which must have been issued by the
+// provider to the client for the sign in of nonce, and returns its claims.
+func (p *oidcProvider) verify(ctx context.Context, raw, nonce string) (oidcClaims, error) {
+	mc := gojwt.MapClaims{}
+	_, err := gojwt.ParseWithClaims(raw, mc, func(t *gojwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*gojwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims := oidcClaims(mc)
+	if iss := claims.string("iss"); iss != p.config.Issuer {
+		return nil, fmt.Errorf("ID token issuer %q does not match %q", iss, p.config.Issuer)
+	}
+	audience := false
+	for _, aud := range claims.strings("aud") {
+		audience = audience || aud == p.config.ClientID
+	}
+	if !audience {
+		return nil, fmt.Errorf("ID token is not issued to client %q", p.config.ClientID)
+	}
+	if !mc.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, fmt.Errorf("ID token has no expiry")
+	}
+	if claims.string("nonce") != nonce {
+		return nil, fmt.Errorf("ID token nonce does not match the sign in")
+	}
+	if claims.string("sub") == "" {
+		return nil, fmt.Errorf("ID token has no subject")
+	}
+	return claims, nil
+}
+
+func (p *oidcProvider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("identity provider responded to %s with status %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// pkceChallenge returns the S256 code challenge of verifier.
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
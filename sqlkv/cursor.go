@@ -0,0 +1,228 @@
+package sqlkv
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/influxdata/influxdb/v2/kv"
+)
+
+// cursorBatchSize is the number of pairs a cursor reads per query.
+const cursorBatchSize = 1000
+
+// ForwardCursor retrieves a cursor for iterating through the entries
+// in the key value store in a given direction (ascending / descending).
+func (b *Bucket) ForwardCursor(seek []byte, opts ...kv.CursorOption) (kv.ForwardCursor, error) {
+	config := kv.NewCursorConfig(opts...)
+	if config.Prefix != nil && !bytes.HasPrefix(seek, config.Prefix) {
+		return nil, fmt.Errorf("seek bytes %q not prefixed with %q: %w", string(seek), string(config.Prefix), kv.ErrSeekMissingPrefix)
+	}
+
+	return &Cursor{
+		bucket:  b,
+		config:  config,
+		forward: true,
+		seek:    append([]byte(nil), seek...),
+	}, nil
+}
+
+// Cursor retrieves a cursor for iterating through the entries
+// in the key value store.
+func (b *Bucket) Cursor(hints ...kv.CursorHint) (kv.Cursor, error) {
+	return &Cursor{
+		bucket: b,
+		config: kv.NewCursorConfig(kv.WithCursorHints(hints...)),
+	}, nil
+}
+
+// Cursor is a struct for iterating through the entries
+// in the key value store. It reads the pairs in batches, and reads them again
+// from its position when pairs are put or deleted in its transaction.
+type Cursor struct {
+	bucket *Bucket
+	config kv.CursorConfig
+
+	// forward is set for forward cursors, which start at seek.
+	forward bool
+	seek    []byte
+	started bool
+
+	// key is the key of the pair last read, the position of the cursor.
+	key []byte
+	// batch are the pairs after key in the direction desc.
+	batch []kv.Pair
+	desc  bool
+	// more is set if there may be pairs after those of batch.
+	more bool
+	// writes are the writes of the transaction when batch was read.
+	writes int
+
+	err    error
+	closed bool
+}
+
+// Close closes the cursor.
+func (c *Cursor) Close() error {
+	c.closed = true
+	c.batch = nil
+	return nil
+}
+
+// Err returns the error, if any, of reading the entries.
+func (c *Cursor) Err() error {
+	return c.err
+}
+
+// Seek seeks for the first key that matches the prefix provided.
+func (c *Cursor) Seek(prefix []byte) ([]byte, []byte) {
+	return c.read(prefix, true, false, false)
+}
+
+// First retrieves the first key value pair in the bucket.
+func (c *Cursor) First() ([]byte, []byte) {
+	return c.read(nil, true, false, false)
+}
+
+// Last retrieves the last key value pair in the bucket.
+func (c *Cursor) Last() ([]byte, []byte) {
+	return c.read(nil, true, true, false)
+}
+
+// Next retrieves the next key in the bucket.
+func (c *Cursor) Next() ([]byte, []byte) {
+	if c.forward && !c.started {
+		// the first pair of a forward cursor.
+		desc := c.config.Direction == kv.CursorDescending
+		return c.read(c.seek, true, desc, c.config.SkipFirst)
+	}
+	return c.move(c.config.Direction == kv.CursorDescending)
+}
+
+// Prev retrieves the previous key in the bucket.
+func (c *Cursor) Prev() ([]byte, []byte) {
+	return c.move(c.config.Direction != kv.CursorDescending)
+}
+
+// move retrieves the pair after the position of the cursor in the direction desc.
+func (c *Cursor) move(desc bool) ([]byte, []byte) {
+	if !c.started {
+		return c.read(nil, true, desc, false)
+	}
+	if c.closed || c.err != nil || c.key == nil {
+		return nil, nil
+	}
+	if c.desc != desc || c.writes != c.bucket.tx.writes {
+		return c.read(c.key, false, desc, false)
+	}
+	return c.next()
+}
+
+// read positions the cursor at key and retrieves the first pair from it in the
+// direction desc. The pair at key is included if inclusive is set, the first
+// pair read is skipped if skip is set.
+func (c *Cursor) read(key []byte, inclusive, desc, skip bool) ([]byte, []byte) {
+	if c.closed {
+		return nil, nil
+	}
+
+	c.started = true
+	c.desc = desc
+	c.readBatch(key, inclusive)
+	if skip && len(c.batch) > 0 {
+		c.key, c.batch = c.batch[0].Key, c.batch[1:]
+	}
+	return c.next()
+}
+
+// next retrieves the next pair of the batch matching the predicate of the
+// cursor, reading further batches as needed.
+func (c *Cursor) next() ([]byte, []byte) {
+	for c.err == nil {
+		for len(c.batch) > 0 {
+			p := c.batch[0]
+			c.key, c.batch = p.Key, c.batch[1:]
+			if fn := c.config.Hints.PredicateFn; fn == nil || fn(p.Key, p.Value) {
+				return p.Key, p.Value
+			}
+		}
+
+		if !c.more {
+			return nil, nil
+		}
+		c.readBatch(c.key, false)
+	}
+	return nil, nil
+}
+
+// readBatch reads the pairs from key in the direction of the cursor.
+func (c *Cursor) readBatch(key []byte, inclusive bool) {
+	var (
+		query = "SELECT key, value FROM kv_entries WHERE bucket = ?"
+		args  = []interface{}{c.bucket.name}
+	)
+
+	if len(key) > 0 {
+		op := ">"
+		if c.desc {
+			op = "<"
+		}
+		if inclusive {
+			op += "="
+		}
+		query += " AND key " + op + " ?"
+		args = append(args, key)
+	}
+
+	if len(c.config.Prefix) > 0 {
+		query += " AND key >= ?"
+		args = append(args, c.config.Prefix)
+		if end := prefixEnd(c.config.Prefix); end != nil {
+			query += " AND key < ?"
+			args = append(args, end)
+		}
+	}
+
+	if c.desc {
+		query += " ORDER BY key DESC"
+	} else {
+		query += " ORDER BY key ASC"
+	}
+	query += fmt.Sprintf(" LIMIT %d", cursorBatchSize)
+
+	c.batch, c.more, c.writes = nil, false, c.bucket.tx.writes
+
+	rows, err := c.bucket.tx.query(query, args...)
+	if err != nil {
+		c.err = err
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p kv.Pair
+		if err := rows.Scan(&p.Key, &p.Value); err != nil {
+			c.err = err
+			return
+		}
+		c.batch = append(c.batch, p)
+	}
+	if err := rows.Err(); err != nil {
+		c.err = err
+		return
+	}
+
+	c.more = len(c.batch) == cursorBatchSize
+}
+
+// prefixEnd returns the smallest key greater than all keys with prefix, or nil
+// if there is none.
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
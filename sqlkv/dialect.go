@@ -0,0 +1,86 @@
+package sqlkv
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SQLite is the driver of SQLite databases, for a single node.
+	SQLite = "sqlite3"
+	// Postgres is the driver of PostgreSQL databases, which can be shared
+	// by multiple nodes.
+	Postgres = "postgres"
+)
+
+// dialect describes the differences between the databases of the drivers.
+type dialect struct {
+	// schema are the statements creating the tables, if they do not exist.
+	schema []string
+	// viewTxOptions are the options of view transactions.
+	viewTxOptions *sql.TxOptions
+	// lockUpdate is run at the start of update transactions to serialize
+	// them across processes.
+	lockUpdate string
+	// numberedParams is set if the placeholders of parameters are numbered.
+	numberedParams bool
+}
+
+var dialects = map[string]*dialect{
+	SQLite: {
+		// the updates of other processes are serialized by the locking
+		// of SQLite itself.
+		schema: []string{
+			`CREATE TABLE IF NOT EXISTS kv_buckets (name BLOB PRIMARY KEY)`,
+			`CREATE TABLE IF NOT EXISTS kv_entries (
+				bucket BLOB NOT NULL,
+				key BLOB NOT NULL,
+				value BLOB NOT NULL,
+				PRIMARY KEY (bucket, key)
+			)`,
+		},
+	},
+	Postgres: {
+		schema: []string{
+			`CREATE TABLE IF NOT EXISTS kv_buckets (name BYTEA PRIMARY KEY)`,
+			`CREATE TABLE IF NOT EXISTS kv_entries (
+				bucket BYTEA NOT NULL,
+				key BYTEA NOT NULL,
+				value BYTEA NOT NULL,
+				PRIMARY KEY (bucket, key)
+			)`,
+		},
+		// views read a snapshot of the store, as boltdb's do.
+		viewTxOptions: &sql.TxOptions{
+			Isolation: sql.LevelRepeatableRead,
+			ReadOnly:  true,
+		},
+		// the key of the advisory lock is arbitrary, but must be the same
+		// for all nodes.
+		lockUpdate:     "SELECT pg_advisory_xact_lock(8086)",
+		numberedParams: true,
+	},
+}
+
+// rebind replaces the ? placeholders of query with those of the dialect.
+func (d *dialect) rebind(query string) string {
+	if !d.numberedParams {
+		return query
+	}
+
+	var (
+		b strings.Builder
+		n int
+	)
+	for _, r := range query {
+		if r != '?' {
+			b.WriteRune(r)
+			continue
+		}
+		n++
+		b.WriteByte('$')
+		b.WriteString(strconv.Itoa(n))
+	}
+	return b.String()
+}
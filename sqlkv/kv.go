@@ -0,0 +1,415 @@
+package sqlkv
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/kv"
+	_ "github.com/lib/pq"           // registers the postgres driver.
+	_ "github.com/mattn/go-sqlite3" // registers the sqlite3 driver.
+	"go.uber.org/zap"
+)
+
+// check that *KVStore implement kv.Store interface.
+var _ kv.Store = (*KVStore)(nil)
+
+// ensure *KVStore implements kv.AutoMigrationStore.
+var _ kv.AutoMigrationStore = (*KVStore)(nil)
+
+// ErrKeyRequired is returned when a key is put without a key.
+var ErrKeyRequired = errors.New("key required")
+
+// KVStore is a kv.Store backed by a SQL database. A single node uses SQLite,
+// nodes sharing their metadata use PostgreSQL.
+//
+// All pairs are stored in a single table, ordered by bucket and key. Update
+// transactions are serialized, like those of boltdb.
+type KVStore struct {
+	driver  string
+	dsn     string
+	dialect *dialect
+	db      *sql.DB
+	log     *zap.Logger
+
+	// mu serializes the update transactions of the process. The updates
+	// of different processes are serialized by the dialect.
+	mu sync.Mutex
+
+	// buckets holds the names of the buckets known to exist.
+	buckets sync.Map
+}
+
+// NewKVStore returns an instance of KVStore with the database of the driver,
+// SQLite or Postgres, at the data source name dsn. The dsn of SQLite is the path
+// of its file.
+func NewKVStore(log *zap.Logger, driver, dsn string) *KVStore {
+	return &KVStore{
+		driver: driver,
+		dsn:    dsn,
+		log:    log,
+	}
+}
+
+// AutoMigrate returns itself as it is safe to automatically apply migrations on initialization.
+func (s *KVStore) AutoMigrate() kv.Store {
+	return s
+}
+
+// Open connects to the database and creates its tables if they do not exist.
+func (s *KVStore) Open(ctx context.Context) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	d, ok := dialects[s.driver]
+	if !ok {
+		return fmt.Errorf("unknown sql driver %q; expected %s or %s", s.driver, SQLite, Postgres)
+	}
+	s.dialect = d
+
+	dsn := s.dsn
+	if s.driver == SQLite {
+		// Ensure the required directory structure exists.
+		path := strings.TrimPrefix(dsn, "file:")
+		if i := strings.IndexByte(path, '?'); i >= 0 {
+			path = path[:i]
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return fmt.Errorf("unable to create directory %s: %v", path, err)
+		}
+		if !strings.Contains(dsn, "?") {
+			dsn += "?_busy_timeout=5000&_journal_mode=WAL"
+		}
+	}
+
+	db, err := sql.Open(s.driver, dsn)
+	if err != nil {
+		return fmt.Errorf("unable to open %s database: %v", s.driver, err)
+	}
+
+	for _, stmt := range d.schema {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			db.Close()
+			return fmt.Errorf("unable to create %s tables: %v", s.driver, err)
+		}
+	}
+	s.db = db
+
+	s.log.Info("Resources opened", zap.String("driver", s.driver))
+	return nil
+}
+
+// Close the connection to the database.
+func (s *KVStore) Close() error {
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// Flush removes all pairs from the buckets.
+func (s *KVStore) Flush(ctx context.Context) {
+	_ = s.Update(ctx, func(tx kv.Tx) error {
+		_, err := tx.(*Tx).exec("DELETE FROM kv_entries")
+		return err
+	})
+}
+
+// View opens up a view transaction against the store.
+func (s *KVStore) View(ctx context.Context, fn func(tx kv.Tx) error) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	return s.tx(ctx, false, fn)
+}
+
+// Update opens up an update transaction against the store.
+func (s *KVStore) Update(ctx context.Context, fn func(tx kv.Tx) error) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.tx(ctx, true, fn)
+}
+
+func (s *KVStore) tx(ctx context.Context, writable bool, fn func(tx kv.Tx) error) (err error) {
+	opts := s.dialect.viewTxOptions
+	if writable {
+		opts = nil
+	}
+
+	sqlTx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	tx := &Tx{
+		tx:       sqlTx,
+		store:    s,
+		writable: writable,
+		ctx:      ctx,
+	}
+	defer func() {
+		if err != nil {
+			_ = sqlTx.Rollback()
+		}
+	}()
+
+	if writable && s.dialect.lockUpdate != "" {
+		if _, err := tx.exec(s.dialect.lockUpdate); err != nil {
+			return err
+		}
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := sqlTx.Commit(); err != nil {
+		return err
+	}
+
+	for _, name := range tx.created {
+		s.buckets.Store(name, struct{}{})
+	}
+	return nil
+}
+
+// Backup copies all K:Vs to a writer, in BoltDB format, so that it can be
+// restored into a bolt store.
+func (s *KVStore) Backup(ctx context.Context, w io.Writer) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	f, err := ioutil.TempFile("", "influxd-sqlkv-backup-")
+	if err != nil {
+		return err
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	err = db.Update(func(btx *bolt.Tx) error {
+		return s.View(ctx, func(tx kv.Tx) error {
+			return tx.(*Tx).copyTo(btx)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+// Tx is a light wrapper around a sql transaction. It implements kv.Tx.
+type Tx struct {
+	tx       *sql.Tx
+	store    *KVStore
+	writable bool
+	ctx      context.Context
+
+	// writes counts the pairs put and deleted, which invalidate the batches
+	// read by cursors.
+	writes int
+
+	// created holds the names of the buckets created by the transaction.
+	created []string
+}
+
+// Context returns the context for the transaction.
+func (tx *Tx) Context() context.Context {
+	return tx.ctx
+}
+
+// WithContext sets the context for the transaction.
+func (tx *Tx) WithContext(ctx context.Context) {
+	tx.ctx = ctx
+}
+
+func (tx *Tx) exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.tx.ExecContext(tx.ctx, tx.store.dialect.rebind(query), args...)
+}
+
+func (tx *Tx) query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.tx.QueryContext(tx.ctx, tx.store.dialect.rebind(query), args...)
+}
+
+func (tx *Tx) queryRow(query string, args ...interface{}) *sql.Row {
+	return tx.tx.QueryRowContext(tx.ctx, tx.store.dialect.rebind(query), args...)
+}
+
+// Bucket retrieves the bucket named b, creating it in writable transactions
+// if it does not exist.
+func (tx *Tx) Bucket(b []byte) (kv.Bucket, error) {
+	bkt := &Bucket{
+		tx:   tx,
+		name: append([]byte(nil), b...),
+	}
+
+	if _, ok := tx.store.buckets.Load(string(b)); ok {
+		return bkt, nil
+	}
+
+	if tx.writable {
+		if _, err := tx.exec("INSERT INTO kv_buckets (name) VALUES (?) ON CONFLICT DO NOTHING", bkt.name); err != nil {
+			return nil, err
+		}
+		tx.created = append(tx.created, string(b))
+		return bkt, nil
+	}
+
+	var exists int
+	err := tx.queryRow("SELECT 1 FROM kv_buckets WHERE name = ?", bkt.name).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return nil, kv.ErrTxNotWritable
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tx.store.buckets.Store(string(b), struct{}{})
+	return bkt, nil
+}
+
+// copyTo copies all buckets and their pairs into the bolt transaction btx.
+func (tx *Tx) copyTo(btx *bolt.Tx) error {
+	rows, err := tx.query("SELECT name FROM kv_buckets")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var names [][]byte
+	for rows.Next() {
+		var name []byte
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, name := range names {
+		if _, err := btx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+
+	rows, err = tx.query("SELECT bucket, key, value FROM kv_entries")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket, key, value []byte
+		if err := rows.Scan(&bucket, &key, &value); err != nil {
+			return err
+		}
+		b := btx.Bucket(bucket)
+		if b == nil {
+			return fmt.Errorf("pair of unknown bucket %q", bucket)
+		}
+		if err := b.Put(key, value); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Bucket implements kv.Bucket.
+type Bucket struct {
+	tx   *Tx
+	name []byte
+}
+
+// Get retrieves the value at the provided key.
+func (b *Bucket) Get(key []byte) ([]byte, error) {
+	var val []byte
+	err := b.tx.queryRow("SELECT value FROM kv_entries WHERE bucket = ? AND key = ?", b.name, key).Scan(&val)
+	if err == sql.ErrNoRows || (err == nil && len(val) == 0) {
+		return nil, kv.ErrKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return val, nil
+}
+
+// GetBatch retrieves the values for the provided keys.
+func (b *Bucket) GetBatch(keys ...[]byte) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+	for idx, key := range keys {
+		val, err := b.Get(key)
+		if kv.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		values[idx] = val
+	}
+
+	return values, nil
+}
+
+// Put sets the value at the provided key.
+func (b *Bucket) Put(key []byte, value []byte) error {
+	if !b.tx.writable {
+		return kv.ErrTxNotWritable
+	}
+	if len(key) == 0 {
+		return ErrKeyRequired
+	}
+
+	_, err := b.tx.exec(
+		"INSERT INTO kv_entries (bucket, key, value) VALUES (?, ?, ?) ON CONFLICT (bucket, key) DO UPDATE SET value = excluded.value",
+		b.name, key, nonNil(value),
+	)
+	b.tx.writes++
+	return err
+}
+
+// Delete removes the provided key.
+func (b *Bucket) Delete(key []byte) error {
+	if !b.tx.writable {
+		return kv.ErrTxNotWritable
+	}
+
+	_, err := b.tx.exec("DELETE FROM kv_entries WHERE bucket = ? AND key = ?", b.name, key)
+	b.tx.writes++
+	return err
+}
+
+// nonNil returns v, or an empty slice if it is nil, as drivers store nil as NULL.
+func nonNil(v []byte) []byte {
+	if v == nil {
+		return []byte{}
+	}
+	return v
+}
@@ -0,0 +1,265 @@
+package sqlkv_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/sqlkv"
+	platformtesting "github.com/influxdata/influxdb/v2/testing"
+	"go.uber.org/zap/zaptest"
+)
+
+// postgresDSNEnv is the environment variable holding the data source name of
+// the PostgreSQL database the tests run against. The tests drop its tables.
+const postgresDSNEnv = "INFLUXDB_TEST_POSTGRES_DSN"
+
+func NewTestSQLiteStore(t *testing.T) (*sqlkv.KVStore, func(), error) {
+	dir, err := ioutil.TempDir("", "influxdata-platform-sqlite-")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := sqlkv.NewKVStore(zaptest.NewLogger(t), sqlkv.SQLite, filepath.Join(dir, "influxd.sqlite"))
+	if err := s.Open(context.Background()); err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+
+	close := func() {
+		s.Close()
+		os.RemoveAll(dir)
+	}
+
+	return s, close, nil
+}
+
+func NewTestPostgresStore(t *testing.T) (*sqlkv.KVStore, func(), error) {
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresDSNEnv)
+	}
+
+	db, err := sql.Open(sqlkv.Postgres, dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer db.Close()
+	if _, err := db.Exec("DROP TABLE IF EXISTS kv_entries, kv_buckets"); err != nil {
+		return nil, nil, err
+	}
+
+	s := sqlkv.NewKVStore(zaptest.NewLogger(t), sqlkv.Postgres, dsn)
+	if err := s.Open(context.Background()); err != nil {
+		return nil, nil, err
+	}
+
+	return s, func() { s.Close() }, nil
+}
+
+var testStores = []struct {
+	name string
+	new  func(*testing.T) (*sqlkv.KVStore, func(), error)
+}{
+	{name: "sqlite", new: NewTestSQLiteStore},
+	{name: "postgres", new: NewTestPostgresStore},
+}
+
+func TestKVStore(t *testing.T) {
+	for _, ts := range testStores {
+		t.Run(ts.name, func(t *testing.T) {
+			platformtesting.KVStore(func(f platformtesting.KVStoreFields, t *testing.T) (kv.Store, func()) {
+				s, closeFn, err := ts.new(t)
+				if err != nil {
+					t.Fatalf("failed to create new kv store: %v", err)
+				}
+
+				err = s.Update(context.Background(), func(tx kv.Tx) error {
+					b, err := tx.Bucket(f.Bucket)
+					if err != nil {
+						return err
+					}
+
+					for _, p := range f.Pairs {
+						if err := b.Put(p.Key, p.Value); err != nil {
+							return err
+						}
+					}
+
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("failed to put keys: %v", err)
+				}
+				return s, closeFn
+			}, t)
+		})
+	}
+}
+
+func TestKVStore_Cursor(t *testing.T) {
+	for _, ts := range testStores {
+		t.Run(ts.name, func(t *testing.T) {
+			s, closeFn, err := ts.new(t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer closeFn()
+
+			ctx := context.Background()
+			// more keys than a cursor reads at once.
+			var keys [][]byte
+			for i := 0; i < 2500; i++ {
+				keys = append(keys, []byte{'k', byte(i >> 8), byte(i)})
+			}
+
+			err = s.Update(ctx, func(tx kv.Tx) error {
+				b, err := tx.Bucket([]byte("bucket"))
+				if err != nil {
+					return err
+				}
+				for _, k := range keys {
+					if err := b.Put(k, k); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			t.Run("reads all batches in both directions", func(t *testing.T) {
+				err := s.View(ctx, func(tx kv.Tx) error {
+					b, err := tx.Bucket([]byte("bucket"))
+					if err != nil {
+						return err
+					}
+
+					for _, dir := range []kv.CursorDirection{kv.CursorAscending, kv.CursorDescending} {
+						cur, err := b.ForwardCursor(nil, kv.WithCursorDirection(dir))
+						if err != nil {
+							return err
+						}
+
+						var n int
+						for k, _ := cur.Next(); k != nil; k, _ = cur.Next() {
+							want := keys[n]
+							if dir == kv.CursorDescending {
+								want = keys[len(keys)-1-n]
+							}
+							if !bytes.Equal(k, want) {
+								t.Fatalf("direction %d: got key %q at %d, want %q", dir, k, n, want)
+							}
+							n++
+						}
+						if err := cur.Err(); err != nil {
+							return err
+						}
+						if n != len(keys) {
+							t.Errorf("direction %d: got %d keys, want %d", dir, n, len(keys))
+						}
+					}
+					return nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			})
+
+			t.Run("sees the writes of its transaction", func(t *testing.T) {
+				err := s.Update(ctx, func(tx kv.Tx) error {
+					b, err := tx.Bucket([]byte("bucket"))
+					if err != nil {
+						return err
+					}
+
+					cur, err := b.ForwardCursor(nil)
+					if err != nil {
+						return err
+					}
+
+					var n int
+					for k, _ := cur.Next(); k != nil; k, _ = cur.Next() {
+						n++
+						// delete the next key, so every other key is read.
+						if err := b.Delete(keys[n]); err != nil {
+							return err
+						}
+						n++
+					}
+					if n != len(keys) {
+						t.Errorf("got %d keys read or deleted, want %d", n, len(keys))
+					}
+					return cur.Err()
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			})
+		})
+	}
+}
+
+func TestKVStore_Backup(t *testing.T) {
+	s, closeFn, err := NewTestSQLiteStore(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeFn()
+
+	ctx := context.Background()
+	err = s.Update(ctx, func(tx kv.Tx) error {
+		if _, err := tx.Bucket([]byte("empty")); err != nil {
+			return err
+		}
+		b, err := tx.Bucket([]byte("bucket"))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte("key"), []byte("value"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := ioutil.TempFile("", "influxdata-platform-sqlite-backup-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	if err := s.Backup(ctx, f); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	db, err := bolt.Open(f.Name(), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	err = db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket([]byte("empty")) == nil {
+			t.Error("expected empty bucket to be backed up")
+		}
+		b := tx.Bucket([]byte("bucket"))
+		if b == nil {
+			t.Fatal("expected bucket to be backed up")
+		}
+		if got := b.Get([]byte("key")); string(got) != "value" {
+			t.Errorf("got value %q, want %q", got, "value")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
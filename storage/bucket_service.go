@@ -13,11 +13,18 @@ type BucketDeleter interface {
 	DeleteBucket(context.Context, influxdb.ID, influxdb.ID) error
 }
 
+// BucketSettingsSetter defines the behaviour of applying the storage settings
+// of a bucket.
+type BucketSettingsSetter interface {
+	SetBucketSettings(bucketID influxdb.ID, settings *influxdb.BucketStorageSettings)
+}
+
 // BucketService wraps an existing influxdb.BucketService implementation.
 //
 // BucketService ensures that when a bucket is deleted, all stored data
 // associated with the bucket is either removed, or marked to be removed via a
-// future compaction.
+// future compaction. If the engine is a BucketSettingsSetter, the storage
+// settings of buckets are applied to it as buckets are created and updated.
 type BucketService struct {
 	inner  influxdb.BucketService
 	engine BucketDeleter
@@ -85,7 +92,11 @@ func (s *BucketService) CreateBucket(ctx context.Context, b *influxdb.Bucket) er
 	if s.inner == nil || s.engine == nil {
 		return errors.New("nil inner BucketService or Engine")
 	}
-	return s.inner.CreateBucket(ctx, b)
+	if err := s.inner.CreateBucket(ctx, b); err != nil {
+		return err
+	}
+	s.setBucketSettings(b.ID, b.StorageSettings)
+	return nil
 }
 
 // UpdateBucket updates a single bucket with changeset.
@@ -97,7 +108,14 @@ func (s *BucketService) UpdateBucket(ctx context.Context, id influxdb.ID, upd in
 	if s.inner == nil || s.engine == nil {
 		return nil, errors.New("nil inner BucketService or Engine")
 	}
-	return s.inner.UpdateBucket(ctx, id, upd)
+	b, err := s.inner.UpdateBucket(ctx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	if upd.StorageSettings != nil {
+		s.setBucketSettings(b.ID, b.StorageSettings)
+	}
+	return b, nil
 }
 
 // DeleteBucket removes a bucket by ID.
@@ -116,5 +134,34 @@ func (s *BucketService) DeleteBucket(ctx context.Context, bucketID influxdb.ID)
 	if err := s.engine.DeleteBucket(ctx, bucket.OrgID, bucketID); err != nil {
 		return err
 	}
-	return s.inner.DeleteBucket(ctx, bucketID)
+	if err := s.inner.DeleteBucket(ctx, bucketID); err != nil {
+		return err
+	}
+	s.setBucketSettings(bucketID, nil)
+	return nil
+}
+
+// LoadBucketSettings applies the storage settings of every existing bucket to
+// the engine. It should be called once the engine has been opened.
+func (s *BucketService) LoadBucketSettings(ctx context.Context) error {
+	if _, ok := s.engine.(BucketSettingsSetter); !ok {
+		return nil
+	}
+
+	buckets, _, err := s.inner.FindBuckets(ctx, influxdb.BucketFilter{})
+	if err != nil {
+		return err
+	}
+	for _, b := range buckets {
+		if !b.StorageSettings.IsZero() {
+			s.setBucketSettings(b.ID, b.StorageSettings)
+		}
+	}
+	return nil
+}
+
+func (s *BucketService) setBucketSettings(bucketID influxdb.ID, settings *influxdb.BucketStorageSettings) {
+	if e, ok := s.engine.(BucketSettingsSetter); ok {
+		e.SetBucketSettings(bucketID, settings)
+	}
 }
@@ -3,6 +3,7 @@ package storage_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/inmem"
@@ -55,6 +56,86 @@ func TestBucketService(t *testing.T) {
 	}
 }
 
+func TestBucketService_StorageSettings(t *testing.T) {
+	ctx := context.Background()
+	inmemService := newInMemKVSVC(t)
+
+	org := &influxdb.Organization{Name: "org1"}
+	if err := inmemService.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+
+	existing := &influxdb.Bucket{
+		OrgID:           org.ID,
+		Name:            "existing",
+		StorageSettings: &influxdb.BucketStorageSettings{CacheSnapshotMemorySize: 1024},
+	}
+	if err := inmemService.CreateBucket(ctx, existing); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := &MockSettingsSetter{settings: make(map[influxdb.ID]*influxdb.BucketStorageSettings)}
+	service := storage.NewBucketService(inmemService, engine)
+
+	// Settings of existing buckets are loaded.
+	if err := service.LoadBucketSettings(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := engine.settings[existing.ID]; got == nil || got.CacheSnapshotMemorySize != 1024 {
+		t.Fatalf("unexpected settings for existing bucket: %+v", got)
+	}
+
+	// Settings are applied when a bucket is created.
+	bucket := &influxdb.Bucket{
+		OrgID:           org.ID,
+		Name:            "created",
+		StorageSettings: &influxdb.BucketStorageSettings{CompactFullWriteColdDuration: time.Hour},
+	}
+	if err := service.CreateBucket(ctx, bucket); err != nil {
+		t.Fatal(err)
+	}
+	if got := engine.settings[bucket.ID]; got == nil || got.CompactFullWriteColdDuration != time.Hour {
+		t.Fatalf("unexpected settings for created bucket: %+v", got)
+	}
+
+	// Updates that leave the settings unchanged don't touch the engine.
+	engine.settings[bucket.ID] = nil
+	name := "renamed"
+	if _, err := service.UpdateBucket(ctx, bucket.ID, influxdb.BucketUpdate{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	if got := engine.settings[bucket.ID]; got != nil {
+		t.Fatalf("unexpected settings after rename: %+v", got)
+	}
+
+	// Settings are applied when updated.
+	if _, err := service.UpdateBucket(ctx, bucket.ID, influxdb.BucketUpdate{
+		StorageSettings: &influxdb.BucketStorageSettings{CacheSnapshotWriteColdDuration: time.Minute},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got := engine.settings[bucket.ID]; got == nil || got.CacheSnapshotWriteColdDuration != time.Minute {
+		t.Fatalf("unexpected settings after update: %+v", got)
+	}
+
+	// Settings are cleared when the bucket is deleted.
+	if err := service.DeleteBucket(ctx, bucket.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := engine.settings[bucket.ID]; !ok || got != nil {
+		t.Fatalf("expected settings to be cleared, got %+v", got)
+	}
+}
+
+type MockSettingsSetter struct {
+	MockDeleter
+	settings map[influxdb.ID]*influxdb.BucketStorageSettings
+}
+
+func (m *MockSettingsSetter) SetBucketSettings(bucketID influxdb.ID, settings *influxdb.BucketStorageSettings) {
+	m.settings[bucketID] = settings
+}
+
 type MockDeleter struct {
 	orgID, bucketID influxdb.ID
 }
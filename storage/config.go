@@ -1,9 +1,11 @@
 package storage
 
 import (
+	"errors"
 	"path/filepath"
 	"time"
 
+	"github.com/influxdata/influxdb/v2/storage/wal"
 	"github.com/influxdata/influxdb/v2/toml"
 	"github.com/influxdata/influxdb/v2/tsdb/seriesfile"
 	"github.com/influxdata/influxdb/v2/tsdb/tsi1"
@@ -41,6 +43,9 @@ type Config struct {
 	// Index config.
 	Index     tsi1.Config `toml:"index"`
 	IndexPath string      `toml:"index-path"` // Overrides the default path.
+
+	// WAL replication config.
+	Replication ReplicationConfig `toml:"replication"`
 }
 
 // NewConfig initialises a new config for an Engine.
@@ -51,6 +56,7 @@ func NewConfig() Config {
 		WAL:               tsm1.NewWALConfig(),
 		Engine:            tsm1.NewConfig(),
 		Index:             tsi1.NewConfig(),
+		Replication:       NewReplicationConfig(),
 	}
 }
 
@@ -85,3 +91,64 @@ func (c Config) GetEnginePath(base string) string {
 	}
 	return filepath.Join(base, DefaultEngineDirectoryName)
 }
+
+// Default WAL replication configuration values.
+const (
+	DefaultReplicationShipInterval = toml.Duration(wal.DefaultShipInterval)
+	DefaultReplicationMaxLagSize   = toml.Size(10 << 30) // 10GB
+)
+
+// ReplicationConfig holds the configuration for shipping the WAL of an engine
+// to a hot standby.
+type ReplicationConfig struct {
+	// Follower makes the engine a read-only replication follower, which is only
+	// written to by applying the WAL segments shipped by its leader.
+	Follower bool `toml:"follower"`
+
+	// FollowerURL is the URL of the follower influxd that WAL segments are
+	// shipped to. Setting it makes the engine a replication leader.
+	FollowerURL string `toml:"follower-url"`
+
+	// FollowerToken is the token used to authenticate with the follower. It
+	// must have operator permissions.
+	FollowerToken string `toml:"follower-token"`
+
+	// ShipLiveSegment ships the segment currently being written to, as well
+	// as closed segments. This reduces the lag of the follower at the cost of
+	// more frequent requests.
+	ShipLiveSegment bool `toml:"ship-live-segment"`
+
+	// ShipInterval is how often new WAL data is shipped to the follower.
+	ShipInterval toml.Duration `toml:"ship-interval"`
+
+	// MaxLagSize is the amount of WAL data not yet shipped to the follower
+	// above which segments already written to TSM files are removed anyway,
+	// so that a follower that is down cannot fill the disk of the leader.
+	// The follower must then be restored from a backup. A value of 0 keeps
+	// the segments until they are shipped.
+	MaxLagSize toml.Size `toml:"max-lag-size"`
+}
+
+// NewReplicationConfig initialises a new ReplicationConfig with default values.
+func NewReplicationConfig() ReplicationConfig {
+	return ReplicationConfig{
+		ShipInterval: DefaultReplicationShipInterval,
+		MaxLagSize:   DefaultReplicationMaxLagSize,
+	}
+}
+
+// Leader returns true if the engine ships its WAL to a follower.
+func (c ReplicationConfig) Leader() bool {
+	return c.FollowerURL != ""
+}
+
+// Validate returns an error if the configuration is invalid.
+func (c ReplicationConfig) Validate() error {
+	if c.Follower && c.Leader() {
+		return errors.New("replication: an engine cannot be both a leader and a follower")
+	}
+	if c.Leader() && c.ShipInterval <= 0 {
+		return errors.New("replication: ship-interval must be positive")
+	}
+	return nil
+}
@@ -59,6 +59,12 @@ type Engine struct {
 	engine  *tsm1.Engine
 	wal     *wal.WAL
 
+	walFollower     wal.Follower // Set when shipping the WAL to a follower.
+	shipper         *wal.Shipper
+	replMu          sync.Mutex           // Serialises applying WAL data on a follower.
+	replPos         influxdb.WALPosition // Position in the leader's WAL of a follower.
+	replLastApplied time.Time
+
 	retentionEnforcer        runner
 	retentionEnforcerLimiter runnable
 
@@ -178,6 +184,7 @@ func NewEngine(path string, c Config, options ...Option) *Engine {
 	e.wal = wal.NewWAL(c.GetWALPath(path))
 	e.wal.WithFsyncDelay(time.Duration(c.WAL.FsyncDelay))
 	e.wal.SetEnabled(c.WAL.Enabled)
+	e.wal.SetKeepEmptySegment(c.Replication.Leader())
 
 	// Initialise Engine
 	e.engine = tsm1.NewEngine(c.GetEnginePath(path), e.index, c.Engine, tsm1.WithSnapshotter(e))
@@ -260,12 +267,16 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 		return err
 	}
 
+	if err := e.openReplication(ctx); err != nil {
+		return err
+	}
+
 	e.closing = make(chan struct{})
 
 	// TODO(edd) background tasks will be run in priority order via a scheduler.
 	// For now we will just run on an interval as we only have the retention
-	// policy enforcer.
-	if e.retentionEnforcer != nil {
+	// policy enforcer. Followers apply the deletes of their leader's enforcer.
+	if e.retentionEnforcer != nil && !e.config.Replication.Follower {
 		e.runRetentionEnforcer()
 	}
 
@@ -296,28 +307,7 @@ func (e *Engine) replayWAL() error {
 	reader := wal.NewWALReader(walPaths)
 	reader.WithLogger(e.logger)
 	err = reader.Read(func(entry wal.WALEntry) error {
-		switch en := entry.(type) {
-		case *wal.WriteWALEntry:
-			points := tsm1.ValuesToPoints(en.Values)
-			err := e.writePointsLocked(context.Background(), tsdb.NewSeriesCollection(points), en.Values)
-			if _, ok := err.(tsdb.PartialWriteError); ok {
-				err = nil
-			}
-			return err
-
-		case *wal.DeleteBucketRangeWALEntry:
-			var pred tsm1.Predicate
-			if len(en.Predicate) > 0 {
-				pred, err = tsm1.UnmarshalPredicate(en.Predicate)
-				if err != nil {
-					return err
-				}
-			}
-
-			return e.deleteBucketRangeLocked(context.Background(), en.OrgID, en.BucketID, en.Min, en.Max, pred)
-		}
-
-		return nil
+		return e.applyWALEntryLocked(context.Background(), entry)
 	})
 
 	e.logger.Info("Reloaded WAL",
@@ -328,6 +318,34 @@ func (e *Engine) replayWAL() error {
 	return err
 }
 
+// applyWALEntryLocked applies a WAL entry to the index and engine, without
+// adding it to the WAL. It must be called under some sort of lock.
+func (e *Engine) applyWALEntryLocked(ctx context.Context, entry wal.WALEntry) error {
+	switch en := entry.(type) {
+	case *wal.WriteWALEntry:
+		points := tsm1.ValuesToPoints(en.Values)
+		err := e.writePointsLocked(ctx, tsdb.NewSeriesCollection(points), en.Values)
+		if _, ok := err.(tsdb.PartialWriteError); ok {
+			err = nil
+		}
+		return err
+
+	case *wal.DeleteBucketRangeWALEntry:
+		var pred tsm1.Predicate
+		if len(en.Predicate) > 0 {
+			var err error
+			pred, err = tsm1.UnmarshalPredicate(en.Predicate)
+			if err != nil {
+				return err
+			}
+		}
+
+		return e.deleteBucketRangeLocked(ctx, en.OrgID, en.BucketID, en.Min, en.Max, pred)
+	}
+
+	return nil
+}
+
 // EnableCompactions allows the series file, index, & underlying engine to compact.
 func (e *Engine) EnableCompactions() {
 	e.sfile.EnableCompactions()
@@ -425,6 +443,10 @@ func (e *Engine) Close() error {
 	// Wait for any other goroutines to finish.
 	e.wg.Wait()
 
+	if e.shipper != nil {
+		e.shipper.Close()
+	}
+
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	e.closing = nil
@@ -469,6 +491,10 @@ func (e *Engine) WritePoints(ctx context.Context, points []models.Point) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	if e.config.Replication.Follower {
+		return ErrReadOnly
+	}
+
 	collection, j := tsdb.NewSeriesCollection(points), 0
 
 	// dropPoint should be called whenever there is reason to drop a point from
@@ -612,6 +638,25 @@ func (e *Engine) CommitSegments(ctx context.Context, segs []string, fn func() er
 		return err
 	}
 
+	// Segments must be kept until they have been shipped to the follower,
+	// unless the follower has fallen too far behind.
+	if e.shipper != nil {
+		shipped, err := e.shipper.Shipped(segs)
+		if err != nil {
+			return err
+		}
+
+		max := int64(e.config.Replication.MaxLagSize)
+		if lag := e.shipper.Status().LagBytes; max > 0 && lag > max && len(shipped) < len(segs) {
+			e.logger.Error("Removing WAL segments not shipped to the follower, which must be restored from a backup of the leader",
+				zap.Int64("lag_bytes", lag),
+				zap.Int64("max_lag_bytes", max),
+				zap.Int("unshipped_segments", len(segs)-len(shipped)))
+		} else {
+			segs = shipped
+		}
+	}
+
 	return e.wal.Remove(ctx, segs)
 }
 
@@ -622,11 +667,21 @@ func (e *Engine) DeleteBucket(ctx context.Context, orgID, bucketID influxdb.ID)
 	return e.DeleteBucketRange(ctx, orgID, bucketID, math.MinInt64, math.MaxInt64)
 }
 
+// SetBucketSettings overrides the compaction planner and cache snapshot
+// settings of a bucket. Nil or zero settings remove the overrides.
+func (e *Engine) SetBucketSettings(bucketID influxdb.ID, settings *influxdb.BucketStorageSettings) {
+	e.engine.SetBucketSettings(bucketID, settings)
+}
+
 // DeleteBucketRange deletes an entire bucket from the storage engine.
 func (e *Engine) DeleteBucketRange(ctx context.Context, orgID, bucketID influxdb.ID, min, max int64) error {
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	if e.config.Replication.Follower {
+		return ErrReadOnly
+	}
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 	if e.closing == nil {
@@ -647,6 +702,10 @@ func (e *Engine) DeleteBucketRangePredicate(ctx context.Context, orgID, bucketID
 	span, ctx := tracing.StartSpanFromContext(ctx)
 	defer span.Finish()
 
+	if e.config.Replication.Follower {
+		return ErrReadOnly
+	}
+
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 	if e.closing == nil {
@@ -682,6 +741,73 @@ func (e *Engine) deleteBucketRangeLocked(ctx context.Context, orgID, bucketID in
 	return e.engine.DeletePrefixRange(ctx, name, min, max, pred)
 }
 
+// CompactionStatus returns the active and queued compactions of the engine.
+func (e *Engine) CompactionStatus(ctx context.Context) (*influxdb.CompactionStatus, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+	return e.engine.CompactionStatus(), nil
+}
+
+// PauseCompactions aborts running compactions and prevents new ones from
+// starting until ResumeCompactions is called.
+func (e *Engine) PauseCompactions(ctx context.Context) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+	e.engine.PauseCompactions()
+	return nil
+}
+
+// ResumeCompactions restarts compactions paused by PauseCompactions.
+func (e *Engine) ResumeCompactions(ctx context.Context) error {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+	e.engine.ResumeCompactions()
+	return nil
+}
+
+// ScheduleCompaction requests a full or optimize compaction of the engine.
+func (e *Engine) ScheduleCompaction(ctx context.Context, typ influxdb.CompactionType) error {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := typ.Valid(); err != nil {
+		return err
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return ErrEngineClosed
+	}
+
+	if typ == influxdb.CompactionTypeOptimize {
+		return e.engine.ScheduleOptimizeCompaction(ctx)
+	}
+	return e.engine.ScheduleFullCompaction(ctx)
+}
+
 // CreateBackup creates a "snapshot" of all TSM data in the Engine.
 //   1) Snapshot the cache to ensure the backup includes all data written before now.
 //   2) Create hard links to all TSM files, in a new directory within the engine root directory.
@@ -2,11 +2,15 @@ package storage_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
 	"math/rand"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -15,6 +19,8 @@ import (
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage"
 	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxdb/v2/storage/wal"
+	"github.com/influxdata/influxdb/v2/toml"
 	"github.com/influxdata/influxdb/v2/tsdb"
 	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
 	"github.com/prometheus/client_golang/prometheus"
@@ -507,6 +513,278 @@ func TestEngine_WriteConflictingBatch(t *testing.T) {
 // BenchmarkWritePoints_100K/wal_off_batch_size_10000-8     	       5	 216334467 ns/op	98397942 B/op	  756227 allocs/op
 // BenchmarkWritePoints_100K/wal_off_batch_size_100000-8    	       3	 360319162 ns/op	219879885 B/op	 2440234 allocs/op
 //
+func TestEngine_Replication(t *testing.T) {
+	followerConfig := storage.NewConfig()
+	followerConfig.Replication.Follower = true
+	follower := NewEngine(followerConfig, rand.Int(), rand.Int())
+	defer follower.Close()
+	follower.MustOpen()
+
+	leaderConfig := storage.NewConfig()
+	leaderConfig.Replication.FollowerURL = "http://follower"
+	leaderConfig.Replication.ShipLiveSegment = true
+	leaderConfig.Replication.ShipInterval = toml.Duration(10 * time.Millisecond)
+	leader := NewEngine(leaderConfig, rand.Int(), rand.Int(), storage.WithWALFollower(follower.Engine))
+	defer leader.Close()
+	leader.MustOpen()
+
+	waitFor := func(msg string, fn func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !fn() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", msg)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	pt := models.MustNewPoint(
+		tsdb.EncodeNameString(leader.org, leader.bucket),
+		models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+		map[string]interface{}{"value": 1.0},
+		time.Unix(1, 2),
+	)
+	if err := leader.WritePoints(context.Background(), []models.Point{pt}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("write to be replicated", func() bool { return follower.SeriesCardinality() == 1 })
+
+	// The follower is read-only.
+	if err := follower.WritePoints(context.Background(), []models.Point{pt}); err != storage.ErrReadOnly {
+		t.Fatalf("got %v, expected %v", err, storage.ErrReadOnly)
+	}
+	if err := follower.DeleteBucket(context.Background(), leader.org, leader.bucket); err != storage.ErrReadOnly {
+		t.Fatalf("got %v, expected %v", err, storage.ErrReadOnly)
+	}
+
+	if err := leader.DeleteBucket(context.Background(), leader.org, leader.bucket); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("delete to be replicated", func() bool { return follower.SeriesCardinality() == 0 })
+
+	// The follower has applied everything, but the leader may not have
+	// recorded its acknowledgement yet.
+	pos, err := follower.WALPosition(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var status *influxdb.ReplicationStatus
+	waitFor("position to be acknowledged", func() bool {
+		status, err = leader.ReplicationStatus(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return status.Position != nil && *status.Position == *pos
+	})
+	if got, exp := status.Role, influxdb.ReplicationRoleLeader; got != exp {
+		t.Fatalf("got role %q, expected %q", got, exp)
+	}
+	if status.LagBytes != 0 {
+		t.Fatalf("got lag of %d bytes, expected none", status.LagBytes)
+	}
+
+	// The position of the follower is persisted.
+	leader.Engine.Close()
+	follower.Engine.Close()
+	follower.MustOpen()
+	reopened, err := follower.WALPosition(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *reopened != *pos {
+		t.Fatalf("got position %v after reopening, expected %v", *reopened, *pos)
+	}
+}
+
+func TestEngine_Replication_MaxLagSize(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		maxLagSize toml.Size
+		exp        int
+	}{
+		{name: "segments kept until shipped", maxLagSize: 0, exp: 1},
+		{name: "segments removed when lag exceeded", maxLagSize: 1, exp: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			config := storage.NewConfig()
+			config.Replication.FollowerURL = "http://follower"
+			config.Replication.ShipInterval = toml.Duration(time.Hour)
+			config.Replication.MaxLagSize = tc.maxLagSize
+			leader := NewEngine(config, rand.Int(), rand.Int(), storage.WithWALFollower(unavailableFollower{}))
+			defer leader.Close()
+			leader.MustOpen()
+
+			pt := models.MustNewPoint(
+				tsdb.EncodeNameString(leader.org, leader.bucket),
+				models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": "server"}),
+				map[string]interface{}{"value": 1.0},
+				time.Unix(1, 2),
+			)
+			if err := leader.WritePoints(context.Background(), []models.Point{pt}); err != nil {
+				t.Fatal(err)
+			}
+
+			var segs []string
+			if err := leader.AcquireSegments(context.Background(), func(s []string) error {
+				segs = s
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			} else if len(segs) != 1 {
+				t.Fatalf("got %d closed segments, expected 1", len(segs))
+			}
+			if err := leader.CommitSegments(context.Background(), segs, func() error { return nil }); err != nil {
+				t.Fatal(err)
+			}
+
+			var n int
+			for _, fn := range segs {
+				if _, err := os.Stat(fn); err == nil {
+					n++
+				}
+			}
+			if n != tc.exp {
+				t.Fatalf("got %d unshipped segments kept, expected %d", n, tc.exp)
+			}
+		})
+	}
+}
+
+func TestEngine_Replication_UnshippedSegment(t *testing.T) {
+	followerConfig := storage.NewConfig()
+	followerConfig.Replication.Follower = true
+	follower := NewEngine(followerConfig, rand.Int(), rand.Int())
+	defer follower.Close()
+	follower.MustOpen()
+
+	leaderFollower := &toggledFollower{Follower: follower.Engine}
+	leaderConfig := storage.NewConfig()
+	leaderConfig.Replication.FollowerURL = "http://follower"
+	leaderConfig.Replication.ShipInterval = toml.Duration(10 * time.Millisecond)
+	leaderConfig.Replication.MaxLagSize = 1
+	leader := NewEngine(leaderConfig, rand.Int(), rand.Int(), storage.WithWALFollower(leaderFollower))
+	defer leader.Close()
+	leader.MustOpen()
+
+	waitFor := func(msg string, fn func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !fn() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", msg)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	followerPosition := func() influxdb.WALPosition {
+		t.Helper()
+		pos, err := follower.WALPosition(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *pos
+	}
+	// writeSegment writes a point to a segment that it closes, and returns
+	// the closed segments.
+	writeSegment := func(host string) []string {
+		t.Helper()
+		pt := models.MustNewPoint(
+			tsdb.EncodeNameString(leader.org, leader.bucket),
+			models.NewTags(map[string]string{models.FieldKeyTagKey: "value", models.MeasurementTagKey: "cpu", "host": host}),
+			map[string]interface{}{"value": 1.0},
+			time.Unix(1, 2),
+		)
+		if err := leader.WritePoints(context.Background(), []models.Point{pt}); err != nil {
+			t.Fatal(err)
+		}
+		var segs []string
+		if err := leader.AcquireSegments(context.Background(), func(s []string) error {
+			segs = s
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return segs
+	}
+
+	segs := writeSegment("a")
+	waitFor("segment to be shipped", func() bool { return followerPosition().Segment == 1 && follower.SeriesCardinality() == 1 })
+	if err := leader.CommitSegments(context.Background(), segs, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	// The follower is unreachable while the leader removes the next segment,
+	// as the lag exceeds its maximum.
+	leaderFollower.setAvailable(false)
+	segs = writeSegment("b")
+	if err := leader.CommitSegments(context.Background(), segs, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	writeSegment("c")
+	leaderFollower.setAvailable(true)
+	waitFor("follower to refuse the segment after the removed one", func() bool {
+		status, err := leader.ReplicationStatus(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Contains(status.LastError, "restored from a backup")
+	})
+
+	if got, exp := followerPosition().Segment, 1; got != exp {
+		t.Fatalf("got follower segment %d, expected %d", got, exp)
+	}
+	if got, exp := follower.SeriesCardinality(), int64(1); got != exp {
+		t.Fatalf("got follower series cardinality %d, expected %d", got, exp)
+	}
+}
+
+// toggledFollower is a wal.Follower that can be made unreachable.
+type toggledFollower struct {
+	wal.Follower
+
+	mu          sync.Mutex
+	unavailable bool
+}
+
+func (f *toggledFollower) setAvailable(available bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.unavailable = !available
+}
+
+func (f *toggledFollower) available() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.unavailable
+}
+
+func (f *toggledFollower) WALPosition(ctx context.Context) (*influxdb.WALPosition, error) {
+	if !f.available() {
+		return nil, errors.New("follower unavailable")
+	}
+	return f.Follower.WALPosition(ctx)
+}
+
+func (f *toggledFollower) ApplyWAL(ctx context.Context, pos influxdb.WALPosition, r io.Reader) (*influxdb.WALPosition, error) {
+	if !f.available() {
+		return nil, errors.New("follower unavailable")
+	}
+	return f.Follower.ApplyWAL(ctx, pos, r)
+}
+
+// unavailableFollower is a wal.Follower that cannot be reached.
+type unavailableFollower struct{}
+
+func (unavailableFollower) WALPosition(context.Context) (*influxdb.WALPosition, error) {
+	return nil, errors.New("follower unavailable")
+}
+
+func (unavailableFollower) ApplyWAL(context.Context, influxdb.WALPosition, io.Reader) (*influxdb.WALPosition, error) {
+	return nil, errors.New("follower unavailable")
+}
+
 func BenchmarkWritePoints_100K(b *testing.B) {
 	var engine *Engine
 
@@ -639,10 +917,11 @@ type Engine struct {
 }
 
 // NewEngine create a new wrapper around a storage engine.
-func NewEngine(c storage.Config, engineID, nodeID int) *Engine {
+func NewEngine(c storage.Config, engineID, nodeID int, options ...storage.Option) *Engine {
 	path, _ := ioutil.TempDir("", "storage_engine_test")
 
-	engine := storage.NewEngine(path, c, storage.WithEngineID(engineID), storage.WithNodeID(nodeID))
+	options = append([]storage.Option{storage.WithEngineID(engineID), storage.WithNodeID(nodeID)}, options...)
+	engine := storage.NewEngine(path, c, options...)
 
 	org, err := influxdb.IDFromString("3131313131313131")
 	if err != nil {
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"strconv"
 
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
 )
 
@@ -27,6 +29,54 @@ func WalkNode(v NodeVisitor, node *datatypes.Node) {
 	WalkChildren(v, node)
 }
 
+// RestrictPredicate returns a predicate that matches the series that match p
+// and any of the resource predicates. A nil p matches all series.
+func RestrictPredicate(p *datatypes.Predicate, preds []influxdb.ResourcePredicate) *datatypes.Predicate {
+	var anyOf []*datatypes.Node
+	for _, pred := range preds {
+		anyOf = append(anyOf, resourcePredicateNode(pred))
+	}
+	root := logicalNode(datatypes.LogicalOr, anyOf)
+
+	if p.GetRoot() != nil {
+		root = logicalNode(datatypes.LogicalAnd, []*datatypes.Node{p.Root, root})
+	}
+	return &datatypes.Predicate{Root: root}
+}
+
+func resourcePredicateNode(pred influxdb.ResourcePredicate) *datatypes.Node {
+	var all []*datatypes.Node
+	if pred.Measurement != "" {
+		all = append(all, tagEqualNode(models.MeasurementTagKey, pred.Measurement))
+	}
+	for _, t := range pred.Tags {
+		all = append(all, tagEqualNode(t.Key, t.Value))
+	}
+	return logicalNode(datatypes.LogicalAnd, all)
+}
+
+func tagEqualNode(key, value string) *datatypes.Node {
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeComparisonExpression,
+		Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonEqual},
+		Children: []*datatypes.Node{
+			{NodeType: datatypes.NodeTypeTagRef, Value: &datatypes.Node_TagRefValue{TagRefValue: key}},
+			{NodeType: datatypes.NodeTypeLiteral, Value: &datatypes.Node_StringValue{StringValue: value}},
+		},
+	}
+}
+
+func logicalNode(op datatypes.Node_Logical, children []*datatypes.Node) *datatypes.Node {
+	if len(children) == 1 {
+		return children[0]
+	}
+	return &datatypes.Node{
+		NodeType: datatypes.NodeTypeLogicalExpression,
+		Value:    &datatypes.Node_Logical_{Logical: op},
+		Children: children,
+	}
+}
+
 func PredicateToExprString(p *datatypes.Predicate) string {
 	if p == nil {
 		return "[none]"
@@ -3,8 +3,10 @@ package reads_test
 import (
 	"testing"
 
+	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/storage/reads"
 	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+	"github.com/influxdata/influxql"
 )
 
 func TestPredicateToExprString(t *testing.T) {
@@ -56,3 +58,50 @@ func TestPredicateToExprString(t *testing.T) {
 		})
 	}
 }
+
+func TestRestrictPredicate(t *testing.T) {
+	preds := []influxdb.ResourcePredicate{
+		{Measurement: "cpu", Tags: []influxdb.Tag{{Key: "customer", Value: "a"}}},
+		{Tags: []influxdb.Tag{{Key: "customer", Value: "b"}}},
+	}
+
+	t.Run("without a predicate", func(t *testing.T) {
+		got := reads.PredicateToExprString(reads.RestrictPredicate(nil, preds))
+		if wanted := "'\x00' = \"cpu\" AND 'customer' = \"a\" OR 'customer' = \"b\""; got != wanted {
+			t.Fatal("got:", got, "wanted:", wanted)
+		}
+	})
+
+	t.Run("with a predicate", func(t *testing.T) {
+		host := &datatypes.Predicate{
+			Root: &datatypes.Node{
+				NodeType: datatypes.NodeTypeComparisonExpression,
+				Value:    &datatypes.Node_Comparison_{Comparison: datatypes.ComparisonEqual},
+				Children: []*datatypes.Node{
+					{NodeType: datatypes.NodeTypeTagRef, Value: &datatypes.Node_TagRefValue{TagRefValue: "host"}},
+					{NodeType: datatypes.NodeTypeLiteral, Value: &datatypes.Node_StringValue{StringValue: "host1"}},
+				},
+			},
+		}
+		expr, err := reads.NodeToExpr(reads.RestrictPredicate(host, preds).Root, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cases := []struct {
+			tags    influxql.MapValuer
+			matches bool
+		}{
+			{tags: influxql.MapValuer{"\x00": "cpu", "customer": "a", "host": "host1"}, matches: true},
+			{tags: influxql.MapValuer{"\x00": "mem", "customer": "b", "host": "host1"}, matches: true},
+			{tags: influxql.MapValuer{"\x00": "mem", "customer": "a", "host": "host1"}},
+			{tags: influxql.MapValuer{"\x00": "cpu", "customer": "b", "host": "host2"}},
+			{tags: influxql.MapValuer{"\x00": "cpu", "customer": "c", "host": "host1"}},
+		}
+		for _, tc := range cases {
+			if got := reads.EvalExprBool(expr, tc.tags); got != tc.matches {
+				t.Errorf("got matches %v for %v, wanted %v", got, tc.tags, tc.matches)
+			}
+		}
+	})
+}
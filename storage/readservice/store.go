@@ -5,6 +5,8 @@ import (
 	"errors"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage/reads"
@@ -35,8 +37,13 @@ func (s *store) ReadFilter(ctx context.Context, req *datatypes.ReadFilterRequest
 		return nil, tracing.LogError(span, err)
 	}
 
+	predicate, err := restrictPredicate(ctx, source, req.Predicate)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+
 	var cur reads.SeriesCursor
-	if cur, err = reads.NewIndexSeriesCursor(ctx, source.GetOrgID(), source.GetBucketID(), req.Predicate, s.viewer); err != nil {
+	if cur, err = reads.NewIndexSeriesCursor(ctx, source.GetOrgID(), source.GetBucketID(), predicate, s.viewer); err != nil {
 		return nil, tracing.LogError(span, err)
 	} else if cur == nil {
 		return nil, nil
@@ -58,8 +65,13 @@ func (s *store) ReadGroup(ctx context.Context, req *datatypes.ReadGroupRequest)
 		return nil, tracing.LogError(span, err)
 	}
 
+	predicate, err := restrictPredicate(ctx, source, req.Predicate)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+
 	newCursor := func() (reads.SeriesCursor, error) {
-		return reads.NewIndexSeriesCursor(ctx, source.GetOrgID(), source.GetBucketID(), req.Predicate, s.viewer)
+		return reads.NewIndexSeriesCursor(ctx, source.GetOrgID(), source.GetBucketID(), predicate, s.viewer)
 	}
 
 	return reads.NewGroupResultSet(ctx, req, newCursor), nil
@@ -80,9 +92,17 @@ func (s *store) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cur
 		req.Range.End = models.MaxNanoTime
 	}
 
+	readSource, err := getReadSource(*req.TagsSource)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+	predicate, err := restrictPredicate(ctx, readSource, req.Predicate)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+
 	var expr influxql.Expr
-	var err error
-	if root := req.Predicate.GetRoot(); root != nil {
+	if root := predicate.GetRoot(); root != nil {
 		expr, err = reads.NodeToExpr(root, nil)
 		if err != nil {
 			return nil, tracing.LogError(span, err)
@@ -97,10 +117,6 @@ func (s *store) TagKeys(ctx context.Context, req *datatypes.TagKeysRequest) (cur
 		}
 	}
 
-	readSource, err := getReadSource(*req.TagsSource)
-	if err != nil {
-		return nil, tracing.LogError(span, err)
-	}
 	return s.viewer.TagKeys(ctx, readSource.GetOrgID(), readSource.GetBucketID(), req.Range.Start, req.Range.End, expr)
 }
 
@@ -123,9 +139,17 @@ func (s *store) TagValues(ctx context.Context, req *datatypes.TagValuesRequest)
 		return nil, tracing.LogError(span, errors.New("missing tag key"))
 	}
 
+	readSource, err := getReadSource(*req.TagsSource)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+	predicate, err := restrictPredicate(ctx, readSource, req.Predicate)
+	if err != nil {
+		return nil, tracing.LogError(span, err)
+	}
+
 	var expr influxql.Expr
-	var err error
-	if root := req.Predicate.GetRoot(); root != nil {
+	if root := predicate.GetRoot(); root != nil {
 		expr, err = reads.NodeToExpr(root, nil)
 		if err != nil {
 			return nil, tracing.LogError(span, err)
@@ -140,11 +164,31 @@ func (s *store) TagValues(ctx context.Context, req *datatypes.TagValuesRequest)
 		}
 	}
 
-	readSource, err := getReadSource(*req.TagsSource)
+	return s.viewer.TagValues(ctx, readSource.GetOrgID(), readSource.GetBucketID(), req.TagKey, req.Range.Start, req.Range.End, expr)
+}
+
+// restrictPredicate restricts the predicate of a read of the source to the
+// predicates of the permissions to read the bucket of the authorizer on ctx.
+// Reads without an authorizer are denied, as they cannot be restricted.
+func restrictPredicate(ctx context.Context, source readSource, predicate *datatypes.Predicate) (*datatypes.Predicate, error) {
+	a, err := icontext.GetAuthorizer(ctx)
 	if err != nil {
-		return nil, tracing.LogError(span, err)
+		return nil, &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  "reads of a bucket require an authorizer",
+			Err:  err,
+		}
 	}
-	return s.viewer.TagValues(ctx, readSource.GetOrgID(), readSource.GetBucketID(), req.TagKey, req.Range.Start, req.Range.End, expr)
+	ps, err := a.PermissionSet()
+	if err != nil {
+		return nil, err
+	}
+
+	preds := ps.BucketPredicates(influxdb.ReadAction, source.GetOrgID(), source.GetBucketID())
+	if len(preds) == 0 {
+		return predicate, nil
+	}
+	return reads.RestrictPredicate(predicate, preds), nil
 }
 
 func (s *store) GetSource(orgID, bucketID uint64) proto.Message {
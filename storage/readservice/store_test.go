@@ -0,0 +1,69 @@
+package readservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/storage/reads/datatypes"
+)
+
+func TestRestrictPredicate(t *testing.T) {
+	orgID, bucketID := influxdb.ID(1), influxdb.ID(2)
+	source := readSource{OrganizationID: uint64(orgID), BucketID: uint64(bucketID)}
+	bucket := func(pred *influxdb.ResourcePredicate) influxdb.Permission {
+		return influxdb.Permission{
+			Action: influxdb.ReadAction,
+			Resource: influxdb.Resource{
+				Type:      influxdb.BucketsResourceType,
+				OrgID:     &orgID,
+				ID:        &bucketID,
+				Predicate: pred,
+			},
+		}
+	}
+	predicate := &datatypes.Predicate{
+		Root: &datatypes.Node{
+			NodeType: datatypes.NodeTypeLiteral,
+			Value:    &datatypes.Node_BooleanValue{BooleanValue: true},
+		},
+	}
+
+	t.Run("without an authorizer", func(t *testing.T) {
+		_, err := restrictPredicate(context.Background(), source, predicate)
+		if code := influxdb.ErrorCode(err); code != influxdb.EUnauthorized {
+			t.Fatalf("got error code %q, expected %q", code, influxdb.EUnauthorized)
+		}
+	})
+
+	t.Run("permission to read all of the bucket", func(t *testing.T) {
+		ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+			Status:      influxdb.Active,
+			Permissions: []influxdb.Permission{bucket(nil)},
+		})
+		got, err := restrictPredicate(ctx, source, predicate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != predicate {
+			t.Fatalf("got predicate %v, expected it unchanged", got)
+		}
+	})
+
+	t.Run("permission with a predicate", func(t *testing.T) {
+		ctx := icontext.SetAuthorizer(context.Background(), &influxdb.Authorization{
+			Status: influxdb.Active,
+			Permissions: []influxdb.Permission{bucket(&influxdb.ResourcePredicate{
+				Tags: []influxdb.Tag{{Key: "customer", Value: "a"}},
+			})},
+		})
+		got, err := restrictPredicate(ctx, source, predicate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got == nil || got == predicate {
+			t.Fatalf("got predicate %v, expected it restricted", got)
+		}
+	})
+}
@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/storage/wal"
+)
+
+// replicationPositionFile is the name of the file, within the engine path, that
+// a follower persists its position in the leader's WAL to.
+const replicationPositionFile = "replication.json"
+
+// ErrReadOnly is returned when writing to, or deleting from, the engine of a
+// replication follower.
+var ErrReadOnly = &influxdb.Error{
+	Code: influxdb.EForbidden,
+	Msg:  "storage engine is a read-only replication follower",
+}
+
+// errNotFollower is returned when WAL data is shipped to an engine that is not
+// a replication follower.
+var errNotFollower = &influxdb.Error{
+	Code: influxdb.EMethodNotAllowed,
+	Msg:  "storage engine is not a replication follower",
+}
+
+// WithWALFollower sets the follower that the engine ships its WAL segments to
+// when it is configured as a replication leader.
+func WithWALFollower(f wal.Follower) Option {
+	return func(e *Engine) {
+		e.walFollower = f
+	}
+}
+
+// openReplication starts shipping WAL segments to the follower of a leader, or
+// loads the position of a follower.
+func (e *Engine) openReplication(ctx context.Context) error {
+	c := e.config.Replication
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if (c.Leader() || c.Follower) && !e.config.WAL.Enabled {
+		return fmt.Errorf("replication: the WAL must be enabled")
+	}
+
+	if c.Follower {
+		return e.loadReplicationPosition()
+	}
+
+	if !c.Leader() {
+		return nil
+	} else if e.walFollower == nil {
+		return fmt.Errorf("replication: no follower for %q", c.FollowerURL)
+	}
+
+	e.shipper = wal.NewShipper(e.wal, e.walFollower)
+	e.shipper.ShipLiveSegment = c.ShipLiveSegment
+	e.shipper.Interval = time.Duration(c.ShipInterval)
+	e.shipper.WithLogger(e.logger)
+	return e.shipper.Open(ctx)
+}
+
+// ReplicationStatus returns the WAL replication state of the engine.
+func (e *Engine) ReplicationStatus(ctx context.Context) (*influxdb.ReplicationStatus, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	if e.shipper != nil {
+		return e.shipper.Status(), nil
+	}
+	if !e.config.Replication.Follower {
+		return &influxdb.ReplicationStatus{}, nil
+	}
+
+	e.replMu.Lock()
+	defer e.replMu.Unlock()
+
+	pos := e.replPos
+	status := &influxdb.ReplicationStatus{
+		Role:     influxdb.ReplicationRoleFollower,
+		Position: &pos,
+	}
+	if !e.replLastApplied.IsZero() {
+		lastApplied := e.replLastApplied
+		status.LastAck = &lastApplied
+	}
+	return status, nil
+}
+
+// WALPosition returns the position up to which a follower has applied the WAL
+// of its leader.
+func (e *Engine) WALPosition(ctx context.Context) (*influxdb.WALPosition, error) {
+	span, _ := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if !e.config.Replication.Follower {
+		return nil, errNotFollower
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	e.replMu.Lock()
+	defer e.replMu.Unlock()
+
+	pos := e.replPos
+	return &pos, nil
+}
+
+// ApplyWAL applies the WAL data of the leader read from r to a follower. The
+// data must start at the follower's current position, or at the start of the
+// next segment. A follower that has applied nothing yet starts at any segment,
+// and one whose leader recreated its WAL starts again at the first segment.
+func (e *Engine) ApplyWAL(ctx context.Context, pos influxdb.WALPosition, r io.Reader) (*influxdb.WALPosition, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if !e.config.Replication.Follower {
+		return nil, errNotFollower
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closing == nil {
+		return nil, ErrEngineClosed
+	}
+
+	e.replMu.Lock()
+	defer e.replMu.Unlock()
+
+	if pos.Segment > e.replPos.Segment+1 && pos.Offset == 0 && e.replPos.Segment != 0 {
+		// The leader removed segments before shipping them, so applying the
+		// next one would leave a hole in the data of the follower.
+		return nil, &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg: fmt.Sprintf("WAL segments %d to %d were not shipped by the leader; the follower must be restored from a backup of the leader",
+				e.replPos.Segment+1, pos.Segment-1),
+		}
+	}
+	if (pos.Segment == e.replPos.Segment && pos.Offset != e.replPos.Offset) ||
+		(pos.Segment != e.replPos.Segment && pos.Offset != 0) ||
+		(pos.Segment < e.replPos.Segment && pos.Segment != 1) {
+		return nil, &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg: fmt.Sprintf("WAL data starts at segment %d offset %d, expected segment %d offset %d",
+				pos.Segment, pos.Offset, e.replPos.Segment, e.replPos.Offset),
+		}
+	}
+
+	n, err := wal.ReadEntries(r, func(entry wal.WALEntry) error {
+		// The entries are added to the WAL of the follower so that they are
+		// replayed by the follower if it restarts before snapshotting them.
+		switch en := entry.(type) {
+		case *wal.WriteWALEntry:
+			if _, err := e.wal.WriteMulti(ctx, en.Values); err != nil {
+				return err
+			}
+		case *wal.DeleteBucketRangeWALEntry:
+			if _, err := e.wal.DeleteBucketRange(en.OrgID, en.BucketID, en.Min, en.Max, en.Predicate); err != nil {
+				return err
+			}
+		}
+		return e.applyWALEntryLocked(ctx, entry)
+	})
+
+	if n > 0 || pos.Segment != e.replPos.Segment {
+		e.replPos = influxdb.WALPosition{Segment: pos.Segment, Offset: pos.Offset + n}
+		e.replLastApplied = time.Now().UTC()
+		if perr := e.saveReplicationPosition(); err == nil {
+			err = perr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	applied := e.replPos
+	return &applied, nil
+}
+
+// loadReplicationPosition loads the persisted position of a follower.
+func (e *Engine) loadReplicationPosition() error {
+	data, err := ioutil.ReadFile(filepath.Join(e.path, replicationPositionFile))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &e.replPos)
+}
+
+// saveReplicationPosition persists the position of a follower. It must be
+// called with replMu held.
+func (e *Engine) saveReplicationPosition() error {
+	data, err := json.Marshal(e.replPos)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(e.path, replicationPositionFile)
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0666); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
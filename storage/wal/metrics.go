@@ -39,6 +39,13 @@ type walMetrics struct {
 	CurrentSegmentBytes *prometheus.GaugeVec
 	Segments            *prometheus.GaugeVec
 	Writes              *prometheus.CounterVec
+
+	// Replication metrics, only set when shipping segments to a follower.
+	ReplicationLagBytes     *prometheus.GaugeVec
+	ReplicationLagSegments  *prometheus.GaugeVec
+	ReplicationLastAck      *prometheus.GaugeVec
+	ReplicationShippedBytes *prometheus.CounterVec
+	ReplicationErrors       *prometheus.CounterVec
 }
 
 // newWALMetrics initialises the prometheus metrics for tracking the WAL.
@@ -77,6 +84,36 @@ func newWALMetrics(labels prometheus.Labels) *walMetrics {
 			Name:      "writes_total",
 			Help:      "Number of writes to the WAL.",
 		}, writeNames),
+		ReplicationLagBytes: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: walSubsystem,
+			Name:      "replication_lag_bytes",
+			Help:      "Number of bytes of WAL segments not yet acknowledged by the follower.",
+		}, names),
+		ReplicationLagSegments: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: walSubsystem,
+			Name:      "replication_lag_segments",
+			Help:      "Number of WAL segments not yet fully acknowledged by the follower.",
+		}, names),
+		ReplicationLastAck: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: walSubsystem,
+			Name:      "replication_last_ack_seconds",
+			Help:      "Unix timestamp of the last acknowledgement from the follower.",
+		}, names),
+		ReplicationShippedBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: walSubsystem,
+			Name:      "replication_shipped_bytes_total",
+			Help:      "Number of bytes of WAL segments applied by the follower.",
+		}, names),
+		ReplicationErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: walSubsystem,
+			Name:      "replication_errors_total",
+			Help:      "Number of failed attempts to ship WAL segments to the follower.",
+		}, names),
 	}
 }
 
@@ -87,5 +124,10 @@ func (m *walMetrics) PrometheusCollectors() []prometheus.Collector {
 		m.CurrentSegmentBytes,
 		m.Segments,
 		m.Writes,
+		m.ReplicationLagBytes,
+		m.ReplicationLagSegments,
+		m.ReplicationLastAck,
+		m.ReplicationShippedBytes,
+		m.ReplicationErrors,
 	}
 }
@@ -0,0 +1,381 @@
+package wal
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"go.uber.org/zap"
+)
+
+// DefaultShipInterval is how often a Shipper ships new WAL data to its follower.
+const DefaultShipInterval = time.Second
+
+// Follower is a replica that a Shipper ships WAL segments to.
+type Follower interface {
+	// WALPosition returns the position up to which the follower has applied
+	// the WAL.
+	WALPosition(ctx context.Context) (*influxdb.WALPosition, error)
+
+	// ApplyWAL applies the WAL data read from r, which starts at pos, and
+	// returns the new position of the follower.
+	ApplyWAL(ctx context.Context, pos influxdb.WALPosition, r io.Reader) (*influxdb.WALPosition, error)
+}
+
+// Shipper ships the segments of a WAL to a follower, tracking the position
+// that the follower has acknowledged.
+//
+// Segments are shipped as they are stored on disk; their entries are
+// already snappy compressed.
+type Shipper struct {
+	wal      *WAL
+	follower Follower
+
+	// ShipLiveSegment controls whether the segment currently being written to
+	// is shipped, or only closed segments.
+	ShipLiveSegment bool
+
+	// Interval is how often new WAL data is shipped.
+	Interval time.Duration
+
+	mu          sync.RWMutex
+	pos         *influxdb.WALPosition // Last position acknowledged by the follower.
+	resync      bool                  // The position must be fetched from the follower.
+	lastAck     time.Time
+	lastErr     error
+	lagBytes    int64
+	lagSegments int
+
+	cancel func()
+	wg     sync.WaitGroup
+
+	logger *zap.Logger
+}
+
+// NewShipper returns a new Shipper shipping the segments of w to f.
+func NewShipper(w *WAL, f Follower) *Shipper {
+	return &Shipper{
+		wal:      w,
+		follower: f,
+		Interval: DefaultShipInterval,
+		resync:   true,
+		logger:   zap.NewNop(),
+	}
+}
+
+// WithLogger sets the logger on the Shipper. It must be called before Open.
+func (s *Shipper) WithLogger(log *zap.Logger) {
+	s.logger = log.With(zap.String("service", "wal-shipper"))
+}
+
+// Open starts shipping segments in the background. The WAL must already be open.
+func (s *Shipper) Open(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.Interval)
+		defer ticker.Stop()
+		for {
+			if err := s.Ship(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Info("Failed to ship WAL segments", zap.Error(err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return nil
+}
+
+// Close stops shipping segments.
+func (s *Shipper) Close() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	return nil
+}
+
+// Ship ships all WAL data that the follower has not yet acknowledged.
+func (s *Shipper) Ship(ctx context.Context) error {
+	err := s.ship(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastErr = err
+	if err != nil {
+		s.resync = true
+		s.wal.tracker.IncReplicationErrors()
+	}
+	if lagErr := s.updateLagLocked(); err == nil {
+		err = lagErr
+	}
+	return err
+}
+
+func (s *Shipper) ship(ctx context.Context) error {
+	all, err := SegmentFileNames(s.wal.Path())
+	if err != nil {
+		return err
+	} else if len(all) == 0 {
+		return nil // Nothing to ship, or to compare the follower's position to.
+	}
+
+	s.mu.RLock()
+	resync := s.resync
+	s.mu.RUnlock()
+
+	if resync {
+		pos, err := s.follower.WALPosition(ctx)
+		if err != nil {
+			return err
+		}
+
+		// The follower is ahead of every local segment, so the WAL must have
+		// been recreated. Ship everything again.
+		last, err := idFromFileName(all[len(all)-1])
+		if err != nil {
+			return err
+		}
+		if pos.Segment > last {
+			s.logger.Warn("Follower is ahead of the WAL, shipping all segments",
+				zap.Int("follower_segment", pos.Segment),
+				zap.Int("last_segment", last))
+			pos = &influxdb.WALPosition{}
+		}
+		s.ack(*pos, 0)
+	}
+
+	segments := all
+	if !s.ShipLiveSegment {
+		if segments, err = s.wal.ClosedSegments(); err != nil {
+			return err
+		}
+	}
+
+	pos := s.Position()
+	for _, fn := range segments {
+		id, err := idFromFileName(fn)
+		if err != nil {
+			return err
+		}
+		if id < pos.Segment {
+			continue
+		}
+
+		var offset int64
+		if id == pos.Segment {
+			offset = pos.Offset
+		}
+
+		stat, err := os.Stat(fn)
+		if err != nil {
+			return err
+		}
+		if offset >= stat.Size() {
+			continue
+		}
+
+		ack, err := s.shipSegment(ctx, fn, influxdb.WALPosition{Segment: id, Offset: offset}, stat.Size())
+		if err != nil {
+			return err
+		}
+		pos = *ack
+
+		// The follower stopped short of the end of the segment, for example
+		// because the last entry of the live segment was still being written.
+		if pos.Segment != id || pos.Offset < stat.Size() {
+			break
+		}
+	}
+	return nil
+}
+
+// shipSegment ships the segment file fn from pos up to size.
+func (s *Shipper) shipSegment(ctx context.Context, fn string, pos influxdb.WALPosition, size int64) (*influxdb.WALPosition, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.Seek(pos.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	ack, err := s.follower.ApplyWAL(ctx, pos, io.LimitReader(f, size-pos.Offset))
+	if err != nil {
+		return nil, err
+	}
+
+	var shipped int64
+	if ack.Segment == pos.Segment {
+		shipped = ack.Offset - pos.Offset
+	}
+	s.ack(*ack, shipped)
+	return ack, nil
+}
+
+// ack records a position acknowledged by the follower.
+func (s *Shipper) ack(pos influxdb.WALPosition, shipped int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pos = &pos
+	s.resync = false
+	s.lastAck = time.Now().UTC()
+
+	s.wal.tracker.SetReplicationLastAck(s.lastAck)
+	if shipped > 0 {
+		s.wal.tracker.AddReplicationShipped(shipped)
+	}
+}
+
+// updateLagLocked recalculates the amount of WAL data not yet acknowledged by
+// the follower. It must be called with s.mu held.
+func (s *Shipper) updateLagLocked() error {
+	segments, err := SegmentFileNames(s.wal.Path())
+	if err != nil {
+		return err
+	}
+
+	var (
+		bytes int64
+		n     int
+	)
+	for _, fn := range segments {
+		id, err := idFromFileName(fn)
+		if err != nil {
+			return err
+		}
+		stat, err := os.Stat(fn)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		lag := stat.Size()
+		if s.pos != nil {
+			if id < s.pos.Segment {
+				continue
+			} else if id == s.pos.Segment {
+				lag -= s.pos.Offset
+			}
+		}
+		if lag > 0 {
+			bytes += lag
+			n++
+		}
+	}
+
+	s.lagBytes, s.lagSegments = bytes, n
+	s.wal.tracker.SetReplicationLag(bytes, n)
+	return nil
+}
+
+// Position returns the last position acknowledged by the follower.
+func (s *Shipper) Position() influxdb.WALPosition {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.pos == nil {
+		return influxdb.WALPosition{}
+	}
+	return *s.pos
+}
+
+// Shipped returns the segment files of files that the follower has fully
+// acknowledged, and which can therefore be removed.
+func (s *Shipper) Shipped(files []string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.pos == nil {
+		return nil, nil
+	}
+
+	var shipped []string
+	for _, fn := range files {
+		id, err := idFromFileName(fn)
+		if err != nil {
+			return nil, err
+		}
+		if id > s.pos.Segment {
+			continue
+		} else if id == s.pos.Segment {
+			stat, err := os.Stat(fn)
+			if err != nil {
+				return nil, err
+			}
+			if s.pos.Offset < stat.Size() {
+				continue
+			}
+		}
+		shipped = append(shipped, fn)
+	}
+	return shipped, nil
+}
+
+// Status returns the replication status of the leader. The lag is calculated
+// from the current WAL segments, rather than as of the last shipment.
+func (s *Shipper) Status() *influxdb.ReplicationStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.updateLagLocked(); err != nil {
+		s.logger.Info("Failed to calculate replication lag", zap.Error(err))
+	}
+
+	status := &influxdb.ReplicationStatus{
+		Role:        influxdb.ReplicationRoleLeader,
+		LagBytes:    s.lagBytes,
+		LagSegments: s.lagSegments,
+	}
+	if s.pos != nil {
+		pos := *s.pos
+		status.Position = &pos
+	}
+	if !s.lastAck.IsZero() {
+		lastAck := s.lastAck
+		status.LastAck = &lastAck
+	}
+	if s.lastErr != nil {
+		status.LastError = s.lastErr.Error()
+	}
+	return status
+}
+
+// ReadEntries reads the WAL entries of a segment from r, calling fn with each
+// one. It returns the number of bytes of the entries successfully passed to
+// fn. A partial entry at the end of r is not an error; it is not read.
+func ReadEntries(r io.Reader, fn func(WALEntry) error) (int64, error) {
+	sr := NewWALSegmentReader(ioutil.NopCloser(r))
+	defer sr.Close()
+
+	var n int64
+	for sr.Next() {
+		entry, err := sr.Read()
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		} else if err != nil {
+			return n, err
+		}
+
+		if err := fn(entry); err != nil {
+			return n, err
+		}
+		n = sr.Count()
+	}
+	return n, nil
+}
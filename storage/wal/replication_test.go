@@ -0,0 +1,194 @@
+package wal
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/tsdb/value"
+)
+
+func TestShipper(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	w := NewWAL(dir)
+	if err := w.Open(context.Background()); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+	defer w.Close()
+
+	mustWrite := func(v float64) {
+		t.Helper()
+		if _, err := w.WriteMulti(context.Background(), map[string][]value.Value{
+			"cpu,host=A#!~#value": {value.NewValue(1, v)},
+		}); err != nil {
+			t.Fatalf("error writing points: %v", err)
+		}
+	}
+
+	mustWrite(1.1)
+	mustWrite(1.2)
+	if err := w.CloseSegment(); err != nil {
+		t.Fatalf("error closing segment: %v", err)
+	}
+	closed, err := w.ClosedSegments()
+	if err != nil {
+		t.Fatalf("error getting closed segments: %v", err)
+	}
+
+	f := &testFollower{}
+	s := NewShipper(w, f)
+	if err := s.Ship(context.Background()); err != nil {
+		t.Fatalf("error shipping: %v", err)
+	}
+
+	if got, exp := len(f.entries), 2; got != exp {
+		t.Fatalf("shipped entries mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := s.Position(), (influxdb.WALPosition{Segment: 1, Offset: MustFileSize(closed[0])}); got != exp {
+		t.Fatalf("position mismatch: got %v, exp %v", got, exp)
+	}
+	if shipped, err := s.Shipped(closed); err != nil {
+		t.Fatalf("error getting shipped segments: %v", err)
+	} else if got, exp := len(shipped), 1; got != exp {
+		t.Fatalf("shipped segments mismatch: got %v, exp %v", got, exp)
+	}
+
+	// Writes to the live segment are not shipped by default.
+	mustWrite(1.3)
+	if got, exp := s.Status().LagBytes, int64(w.currentSegmentWriter.size); got != exp {
+		t.Fatalf("lag bytes mismatch before shipping: got %v, exp %v", got, exp)
+	}
+	if err := s.Ship(context.Background()); err != nil {
+		t.Fatalf("error shipping: %v", err)
+	}
+	if got, exp := len(f.entries), 2; got != exp {
+		t.Fatalf("shipped entries mismatch: got %v, exp %v", got, exp)
+	}
+	status := s.Status()
+	if got, exp := status.LagSegments, 1; got != exp {
+		t.Fatalf("lag segments mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := status.LagBytes, int64(w.currentSegmentWriter.size); got != exp {
+		t.Fatalf("lag bytes mismatch: got %v, exp %v", got, exp)
+	}
+
+	s.ShipLiveSegment = true
+	if err := s.Ship(context.Background()); err != nil {
+		t.Fatalf("error shipping: %v", err)
+	}
+	if got, exp := len(f.entries), 3; got != exp {
+		t.Fatalf("shipped entries mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := s.Position(), (influxdb.WALPosition{Segment: 2, Offset: int64(w.currentSegmentWriter.size)}); got != exp {
+		t.Fatalf("position mismatch: got %v, exp %v", got, exp)
+	}
+	if status := s.Status(); status.LagBytes != 0 || status.LagSegments != 0 || status.LastAck == nil {
+		t.Fatalf("unexpected status: %+v", status)
+	}
+}
+
+func TestShipper_FollowerAhead(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	w := NewWAL(dir)
+	if err := w.Open(context.Background()); err != nil {
+		t.Fatalf("error opening WAL: %v", err)
+	}
+	defer w.Close()
+
+	if _, err := w.WriteMulti(context.Background(), map[string][]value.Value{
+		"cpu,host=A#!~#value": {value.NewValue(1, 1.1)},
+	}); err != nil {
+		t.Fatalf("error writing points: %v", err)
+	}
+
+	// The follower has applied segments of a previous incarnation of the WAL.
+	f := &testFollower{pos: influxdb.WALPosition{Segment: 10, Offset: 100}}
+	s := NewShipper(w, f)
+	s.ShipLiveSegment = true
+	if err := s.Ship(context.Background()); err != nil {
+		t.Fatalf("error shipping: %v", err)
+	}
+
+	if got, exp := len(f.entries), 1; got != exp {
+		t.Fatalf("shipped entries mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := f.pos.Segment, 1; got != exp {
+		t.Fatalf("follower segment mismatch: got %v, exp %v", got, exp)
+	}
+}
+
+func TestReadEntries_Partial(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWALSegmentWriter(nopWriteCloser{&buf})
+	for _, v := range []float64{1.1, 1.2} {
+		entry := &WriteWALEntry{Values: map[string][]value.Value{
+			"cpu,host=A#!~#value": {value.NewValue(1, v)},
+		}}
+		if err := w.Write(mustMarshalEntry(entry)); err != nil {
+			fatal(t, "write points", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		fatal(t, "flush", err)
+	}
+
+	// Drop the last byte of the second entry.
+	data := buf.Bytes()[:buf.Len()-1]
+
+	var entries int
+	n, err := ReadEntries(bytes.NewReader(data), func(WALEntry) error {
+		entries++
+		return nil
+	})
+	if err != nil {
+		fatal(t, "read entries", err)
+	}
+	if got, exp := entries, 1; got != exp {
+		t.Fatalf("entries mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := n, int64(w.size/2); got != exp {
+		t.Fatalf("bytes read mismatch: got %v, exp %v", got, exp)
+	}
+}
+
+// testFollower is a Follower that records the entries shipped to it.
+type testFollower struct {
+	pos     influxdb.WALPosition
+	entries []WALEntry
+}
+
+func (f *testFollower) WALPosition(context.Context) (*influxdb.WALPosition, error) {
+	pos := f.pos
+	return &pos, nil
+}
+
+func (f *testFollower) ApplyWAL(ctx context.Context, pos influxdb.WALPosition, r io.Reader) (*influxdb.WALPosition, error) {
+	n, err := ReadEntries(r, func(entry WALEntry) error {
+		f.entries = append(f.entries, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	f.pos = influxdb.WALPosition{Segment: pos.Segment, Offset: pos.Offset + n}
+	return f.WALPosition(ctx)
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func MustFileSize(path string) int64 {
+	stat, err := os.Stat(path)
+	if err != nil {
+		panic(err)
+	}
+	return stat.Size()
+}
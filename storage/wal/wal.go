@@ -113,6 +113,10 @@ type WAL struct {
 	defaultMetricLabels prometheus.Labels // N.B this must not be mutated after Open is called.
 
 	limiter limiter.Fixed
+
+	// keepEmptySegment reuses an empty last segment on open rather than
+	// removing it, so that segment IDs are never reused.
+	keepEmptySegment bool
 }
 
 // NewWAL initializes a new WAL at the given directory.
@@ -141,6 +145,14 @@ func (l *WAL) SetEnabled(enabled bool) {
 	l.enabled = enabled
 }
 
+// SetKeepEmptySegment sets if an empty last segment is reused rather than
+// removed when the WAL is opened, so that segment IDs are never reused. It is
+// needed when the position of a replication follower is tracked by segment ID,
+// and should be called before the WAL is opened.
+func (l *WAL) SetKeepEmptySegment(keep bool) {
+	l.keepEmptySegment = keep
+}
+
 // WithLogger sets the WAL's logger.
 func (l *WAL) WithLogger(log *zap.Logger) {
 	l.logger = log.With(zap.String("service", "wal"))
@@ -210,7 +222,7 @@ func (l *WAL) Open(ctx context.Context) error {
 			return err
 		}
 
-		if stat.Size() == 0 {
+		if stat.Size() == 0 && !l.keepEmptySegment {
 			os.Remove(lastSegment)
 			segments = segments[:len(segments)-1]
 			l.tracker.DecSegments()
@@ -683,6 +695,31 @@ func (t *walTracker) DecSegments() {
 	t.metrics.Segments.With(labels).Dec()
 }
 
+// SetReplicationLag sets the amount of WAL data not yet acknowledged by the follower.
+func (t *walTracker) SetReplicationLag(bytes int64, segments int) {
+	labels := t.labels
+	t.metrics.ReplicationLagBytes.With(labels).Set(float64(bytes))
+	t.metrics.ReplicationLagSegments.With(labels).Set(float64(segments))
+}
+
+// SetReplicationLastAck sets the time of the last acknowledgement from the follower.
+func (t *walTracker) SetReplicationLastAck(tm time.Time) {
+	labels := t.labels
+	t.metrics.ReplicationLastAck.With(labels).Set(float64(tm.UnixNano()) / float64(time.Second))
+}
+
+// AddReplicationShipped increases the number of bytes applied by the follower.
+func (t *walTracker) AddReplicationShipped(n int64) {
+	labels := t.labels
+	t.metrics.ReplicationShippedBytes.With(labels).Add(float64(n))
+}
+
+// IncReplicationErrors increases the number of failed shipping attempts by one.
+func (t *walTracker) IncReplicationErrors() {
+	labels := t.labels
+	t.metrics.ReplicationErrors.With(labels).Inc()
+}
+
 // WALEntry is record stored in each WAL segment.  Each entry has a type
 // and an opaque, type dependent byte slice data attribute.
 type WALEntry interface {
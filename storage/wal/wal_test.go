@@ -308,6 +308,51 @@ func TestWAL_ClosedSegments(t *testing.T) {
 	}
 }
 
+func TestWAL_Open_EmptyLastSegment(t *testing.T) {
+	for _, keep := range []bool{false, true} {
+		t.Run(fmt.Sprintf("keep=%v", keep), func(t *testing.T) {
+			dir := MustTempDir()
+			defer os.RemoveAll(dir)
+
+			w := NewWAL(dir)
+			if err := w.Open(context.Background()); err != nil {
+				t.Fatalf("error opening WAL: %v", err)
+			}
+			if _, err := w.WriteMulti(context.Background(), map[string][]value.Value{
+				"cpu,host=A#!~#value": {value.NewValue(1, 1.1)},
+			}); err != nil {
+				t.Fatalf("error writing points: %v", err)
+			}
+			// Closing the segment leaves an empty live segment.
+			if err := w.CloseSegment(); err != nil {
+				t.Fatalf("error closing segment: %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("error closing wal: %v", err)
+			}
+
+			w = NewWAL(dir)
+			w.SetKeepEmptySegment(keep)
+			defer w.Close()
+			if err := w.Open(context.Background()); err != nil {
+				t.Fatalf("error opening WAL: %v", err)
+			}
+
+			files, err := SegmentFileNames(dir)
+			if err != nil {
+				t.Fatalf("error getting segments: %v", err)
+			}
+			exp := 1
+			if keep {
+				exp = 2
+			}
+			if got := len(files); got != exp {
+				t.Fatalf("segment count mismatch: got %v, exp %v", got, exp)
+			}
+		})
+	}
+}
+
 func TestWALWriter_Corrupt(t *testing.T) {
 	dir := MustTempDir()
 	defer os.RemoveAll(dir)
@@ -2,10 +2,12 @@ package influxdb
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/influxdata/flux/ast"
@@ -57,6 +59,12 @@ type Task struct {
 	Every           string                 `json:"every,omitempty"`
 	Cron            string                 `json:"cron,omitempty"`
 	Offset          time.Duration          `json:"offset,omitempty"`
+	Upstream        []ID                   `json:"upstream,omitempty"`
+	TemplateID      ID                     `json:"templateID,omitempty"`
+	TemplateParams  map[string]interface{} `json:"templateParams,omitempty"`
+	Notification    *TaskNotification      `json:"notification,omitempty"`
+	Trigger         *TaskTrigger           `json:"trigger,omitempty"`
+	RunRetention    *TaskRunRetention      `json:"runRetention,omitempty"`
 	LatestCompleted time.Time              `json:"latestCompleted,omitempty"`
 	LatestScheduled time.Time              `json:"latestScheduled,omitempty"`
 	LastRunStatus   string                 `json:"lastRunStatus,omitempty"`
@@ -64,6 +72,10 @@ type Task struct {
 	CreatedAt       time.Time              `json:"createdAt,omitempty"`
 	UpdatedAt       time.Time              `json:"updatedAt,omitempty"`
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
+
+	// ConsecutiveFailures is the number of runs that failed, without being
+	// retried, since the last successful run.
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
 }
 
 // EffectiveCron returns the effective cron string of the options.
@@ -81,6 +93,98 @@ func (t *Task) EffectiveCron() string {
 	return ""
 }
 
+// TaskNotification configures the notifications of the failures of a task to
+// a notification endpoint.
+type TaskNotification struct {
+	EndpointID ID `json:"endpointID"`
+
+	// Failures is the number of consecutive failed runs at which the failure of
+	// the task is notified, once until the task recovers. It defaults to 1,
+	// notifying the first failed run.
+	Failures int `json:"failures,omitempty"`
+
+	// Recovery notifies the first successful run after notified failures.
+	Recovery bool `json:"recovery,omitempty"`
+}
+
+// Threshold returns the number of consecutive failed runs at which the
+// failure of the task is notified.
+func (n TaskNotification) Threshold() int {
+	if n.Failures <= 0 {
+		return 1
+	}
+	return n.Failures
+}
+
+// Validate returns an error if the notification is invalid.
+func (n TaskNotification) Validate() error {
+	switch {
+	case !n.EndpointID.Valid():
+		return errors.New("missing notification endpointID")
+	case n.Failures < 0:
+		return fmt.Errorf("invalid notification failures: %d", n.Failures)
+	}
+	return nil
+}
+
+// TaskTrigger runs a task when points are written to a bucket, in addition to
+// running it on its schedule.
+type TaskTrigger struct {
+	BucketID ID `json:"bucketID"`
+
+	// Measurement restricts the trigger to writes of points of a measurement.
+	Measurement string `json:"measurement,omitempty"`
+
+	// Debounce is how long the task waits after a write before it runs, so
+	// that the writes in that time trigger a single run.
+	Debounce Duration `json:"debounce"`
+}
+
+// Validate returns an error if the trigger is invalid.
+func (t TaskTrigger) Validate() error {
+	switch {
+	case !t.BucketID.Valid():
+		return errors.New("missing trigger bucketID")
+	case t.Debounce.Duration < 0:
+		return fmt.Errorf("invalid trigger debounce: %s", t.Debounce)
+	}
+	return nil
+}
+
+// TaskRunRetention bounds the history of runs kept for a task, in place of
+// the retention of the runs of all tasks.
+type TaskRunRetention struct {
+	// MaxAge is how long runs are kept. Zero keeps the retention of all tasks.
+	MaxAge Duration `json:"maxAge,omitempty"`
+
+	// MaxCount is how many of the most recent completed runs are kept. Zero
+	// keeps the retention of all tasks.
+	MaxCount int `json:"maxCount,omitempty"`
+}
+
+// IsZero returns whether the retention keeps the retention of all tasks.
+func (r TaskRunRetention) IsZero() bool {
+	return r.MaxAge.Duration == 0 && r.MaxCount == 0
+}
+
+// Validate returns an error if the retention is invalid.
+func (r TaskRunRetention) Validate() error {
+	switch {
+	case r.MaxAge.Duration < 0:
+		return fmt.Errorf("invalid run retention maxAge: %s", r.MaxAge)
+	case r.MaxCount < 0:
+		return fmt.Errorf("invalid run retention maxCount: %d", r.MaxCount)
+	}
+	return nil
+}
+
+// RunTrigger is the time range of the points whose write triggered a run. The
+// range includes Start and excludes Stop, as the range of a Flux query does.
+type RunTrigger struct {
+	Start time.Time `json:"start"`
+	Stop  time.Time `json:"stop"`
+}
+
 // Run is a record createId when a run of a task is scheduled.
 type Run struct {
 	ID           ID        `json:"id,omitempty"`
@@ -91,7 +195,13 @@ type Run struct {
 	StartedAt    time.Time `json:"startedAt,omitempty"`   // StartedAt is the time the executor begins running the task
 	FinishedAt   time.Time `json:"finishedAt,omitempty"`  // FinishedAt is the time the executor finishes running the task
 	RequestedAt  time.Time `json:"requestedAt,omitempty"` // RequestedAt is the time the coordinator told the scheduler to schedule the task
+	Attempt      int       `json:"attempt,omitempty"`     // Attempt is the number of the attempt when the run is an automatic retry of a failed run
+	RetryOf      ID        `json:"retryOf,omitempty"`     // RetryOf is the ID of the failed run that the run retries
 	Log          []Log     `json:"log,omitempty"`
+
+	// Trigger is the time range of the writes that triggered the run, if
+	// the run was triggered rather than scheduled.
+	Trigger *RunTrigger `json:"trigger,omitempty"`
 }
 
 // Log represents a link to a log resource
@@ -153,13 +263,47 @@ type TaskCreate struct {
 	OrganizationID ID                     `json:"orgID,omitempty"`
 	Organization   string                 `json:"org,omitempty"`
 	OwnerID        ID                     `json:"-"`
+	Upstream       []ID                   `json:"upstream,omitempty"`
 	Metadata       map[string]interface{} `json:"-"` // not to be set through a web request but rather used by a http service using tasks backend.
+
+	// TemplateID instantiates the task from a template in place of Flux,
+	// with the values of the template parameters in TemplateParams.
+	TemplateID     ID                     `json:"templateID,omitempty"`
+	TemplateParams map[string]interface{} `json:"templateParams,omitempty"`
+
+	// Notification notifies the failures of the task to a notification endpoint.
+	Notification *TaskNotification `json:"notification,omitempty"`
+
+	// Trigger runs the task on writes to a bucket.
+	Trigger *TaskTrigger `json:"trigger,omitempty"`
+
+	// RunRetention bounds the history of runs kept for the task.
+	RunRetention *TaskRunRetention `json:"runRetention,omitempty"`
 }
 
 func (t TaskCreate) Validate() error {
+	if t.Notification != nil {
+		if err := t.Notification.Validate(); err != nil {
+			return err
+		}
+	}
+	if t.Trigger != nil {
+		if err := t.Trigger.Validate(); err != nil {
+			return err
+		}
+	}
+	if t.RunRetention != nil {
+		if err := t.RunRetention.Validate(); err != nil {
+			return err
+		}
+	}
 	switch {
-	case t.Flux == "":
+	case t.Flux == "" && !t.TemplateID.Valid():
 		return errors.New("missing flux")
+	case t.Flux != "" && t.TemplateID.Valid():
+		return errors.New("cannot specify both flux and templateID")
+	case len(t.TemplateParams) > 0 && !t.TemplateID.Valid():
+		return errors.New("cannot specify templateParams without templateID")
 	case !t.OrganizationID.Valid() && t.Organization == "":
 		return errors.New("missing orgID and org")
 	case t.Status != "" && t.Status != TaskStatusActive && t.Status != TaskStatusInactive:
@@ -174,12 +318,34 @@ type TaskUpdate struct {
 	Status      *string `json:"status,omitempty"`
 	Description *string `json:"description,omitempty"`
 
+	// Upstream replaces the tasks that must succeed before this task runs.
+	// An empty slice removes all of them.
+	Upstream *[]ID `json:"upstream,omitempty"`
+
+	// TemplateParams renders the task again from its template with new values
+	// of the template parameters. Updating the Flux or the options of a task
+	// instead detaches it from its template.
+	TemplateParams map[string]interface{} `json:"templateParams,omitempty"`
+
+	// Notification replaces the notifications of the failures of the task.
+	// A notification without an endpoint removes them.
+	Notification *TaskNotification `json:"notification,omitempty"`
+
+	// Trigger replaces the trigger of the task. A trigger without a bucket
+	// removes it.
+	Trigger *TaskTrigger `json:"trigger,omitempty"`
+
+	// RunRetention replaces the retention of the runs of the task. A zero
+	// retention removes it.
+	RunRetention *TaskRunRetention `json:"runRetention,omitempty"`
+
 	// LatestCompleted us to set latest completed on startup to skip task catchup
-	LatestCompleted *time.Time             `json:"-"`
-	LatestScheduled *time.Time             `json:"-"`
-	LastRunStatus   *string                `json:"-"`
-	LastRunError    *string                `json:"-"`
-	Metadata        map[string]interface{} `json:"-"` // not to be set through a web request but rather used by a http service using tasks backend.
+	LatestCompleted     *time.Time             `json:"-"`
+	LatestScheduled     *time.Time             `json:"-"`
+	LastRunStatus       *string                `json:"-"`
+	LastRunError        *string                `json:"-"`
+	ConsecutiveFailures *int                   `json:"-"`
+	Metadata            map[string]interface{} `json:"-"` // not to be set through a web request but rather used by a http service using tasks backend.
 
 	// Options gets unmarshalled from json as if it was flat, with the same level as Flux and Status.
 	Options options.Options // when we unmarshal this gets unmarshalled from flat key-values
@@ -207,6 +373,16 @@ func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
 		Concurrency *int64 `json:"concurrency,omitempty"`
 
 		Retry *int64 `json:"retry,omitempty"`
+
+		Upstream *[]ID `json:"upstream,omitempty"`
+
+		TemplateParams map[string]interface{} `json:"templateParams,omitempty"`
+
+		Notification *TaskNotification `json:"notification,omitempty"`
+
+		Trigger *TaskTrigger `json:"trigger,omitempty"`
+
+		RunRetention *TaskRunRetention `json:"runRetention,omitempty"`
 	}{}
 
 	if err := json.Unmarshal(data, &jo); err != nil {
@@ -224,6 +400,11 @@ func (t *TaskUpdate) UnmarshalJSON(data []byte) error {
 	t.Options.Retry = jo.Retry
 	t.Flux = jo.Flux
 	t.Status = jo.Status
+	t.Upstream = jo.Upstream
+	t.TemplateParams = jo.TemplateParams
+	t.Notification = jo.Notification
+	t.Trigger = jo.Trigger
+	t.RunRetention = jo.RunRetention
 	return nil
 }
 
@@ -246,6 +427,16 @@ func (t *TaskUpdate) MarshalJSON() ([]byte, error) {
 		Concurrency *int64 `json:"concurrency,omitempty"`
 
 		Retry *int64 `json:"retry,omitempty"`
+
+		Upstream *[]ID `json:"upstream,omitempty"`
+
+		TemplateParams map[string]interface{} `json:"templateParams,omitempty"`
+
+		Notification *TaskNotification `json:"notification,omitempty"`
+
+		Trigger *TaskTrigger `json:"trigger,omitempty"`
+
+		RunRetention *TaskRunRetention `json:"runRetention,omitempty"`
 	}{}
 	jo.Name = t.Options.Name
 	jo.Cron = t.Options.Cron
@@ -259,10 +450,31 @@ func (t *TaskUpdate) MarshalJSON() ([]byte, error) {
 	jo.Retry = t.Options.Retry
 	jo.Flux = t.Flux
 	jo.Status = t.Status
+	jo.Upstream = t.Upstream
+	jo.TemplateParams = t.TemplateParams
+	jo.Notification = t.Notification
+	jo.Trigger = t.Trigger
+	jo.RunRetention = t.RunRetention
 	return json.Marshal(jo)
 }
 
 func (t *TaskUpdate) Validate() error {
+	if t.Notification != nil && t.Notification.EndpointID.Valid() {
+		if err := t.Notification.Validate(); err != nil {
+			return err
+		}
+	}
+	if t.Trigger != nil && t.Trigger.BucketID.Valid() {
+		if err := t.Trigger.Validate(); err != nil {
+			return err
+		}
+	}
+	if t.RunRetention != nil {
+		if err := t.RunRetention.Validate(); err != nil {
+			return err
+		}
+	}
+
 	switch {
 	case !t.Options.Every.IsZero() && t.Options.Cron != "":
 		return errors.New("cannot specify both every and cron")
@@ -274,8 +486,10 @@ func (t *TaskUpdate) Validate() error {
 		if _, err := time.ParseDuration(t.Options.Offset.String()); err != nil {
 			return fmt.Errorf("offset: %s, %s is invalid, the largest unit supported is h", t.Options.Offset.String(), err)
 		}
-	case t.Flux == nil && t.Status == nil && t.Options.IsZero():
+	case t.Flux == nil && t.Status == nil && t.Upstream == nil && t.TemplateParams == nil && t.Notification == nil && t.Trigger == nil && t.RunRetention == nil && t.Options.IsZero():
 		return errors.New("cannot update task without content")
+	case t.TemplateParams != nil && (t.Flux != nil || !t.Options.IsZero()):
+		return errors.New("cannot specify templateParams with flux or options")
 	case t.Status != nil && *t.Status != TaskStatusActive && *t.Status != TaskStatusInactive:
 		return fmt.Errorf("invalid task status: %q", *t.Status)
 	}
@@ -418,6 +632,8 @@ type TaskFilter struct {
 	User           *ID
 	Limit          int
 	Status         *string
+	Upstream       *ID // Upstream matches the tasks that depend on the task with this ID
+	TemplateID     *ID // TemplateID matches the tasks instantiated from the template with this ID
 }
 
 // QueryParams Converts TaskFilter fields to url query params.
@@ -443,6 +659,14 @@ func (f TaskFilter) QueryParams() map[string][]string {
 		qp["limit"] = []string{strconv.Itoa(f.Limit)}
 	}
 
+	if f.Upstream != nil {
+		qp["upstream"] = []string{f.Upstream.String()}
+	}
+
+	if f.TemplateID != nil {
+		qp["templateID"] = []string{f.TemplateID.String()}
+	}
+
 	return qp
 }
 
@@ -455,6 +679,73 @@ type RunFilter struct {
 	Limit      int
 	AfterTime  string
 	BeforeTime string
+
+	// Cursor, when set, orders the results from the most recently scheduled run,
+	// and restricts them to the runs listed after the run it points at.
+	Cursor *RunCursor
+}
+
+// RunCursor points at a run in the list of runs of a task, so that the list
+// can be paged through. The zero RunCursor points before the first run.
+type RunCursor struct {
+	ScheduledFor time.Time
+	RunID        ID
+}
+
+// NewRunCursor returns the cursor that points at r.
+func NewRunCursor(r *Run) *RunCursor {
+	return &RunCursor{ScheduledFor: r.ScheduledFor.UTC().Truncate(time.Second), RunID: r.ID}
+}
+
+// ParseRunCursor parses a cursor from its string representation.
+func ParseRunCursor(s string) (*RunCursor, error) {
+	if s == "" {
+		return &RunCursor{}, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidRunCursor
+	}
+	parts := strings.SplitN(string(b), "/", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidRunCursor
+	}
+	sec, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidRunCursor
+	}
+	id, err := IDFromString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidRunCursor
+	}
+	return &RunCursor{ScheduledFor: time.Unix(sec, 0).UTC(), RunID: *id}, nil
+}
+
+// IsZero reports whether c points before the first run.
+func (c RunCursor) IsZero() bool {
+	return c.ScheduledFor.IsZero() && c.RunID == 0
+}
+
+// String returns the opaque representation of the cursor.
+func (c RunCursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d/%s", c.ScheduledFor.Unix(), c.RunID)))
+}
+
+// Precedes reports whether the run c points at is listed before r, which
+// holds for runs scheduled earlier, and for runs with a lower ID that were
+// scheduled at the same time.
+func (c RunCursor) Precedes(r *Run) bool {
+	if c.IsZero() {
+		return true
+	}
+	sf := r.ScheduledFor.UTC().Truncate(time.Second)
+	if !sf.Equal(c.ScheduledFor) {
+		return sf.Before(c.ScheduledFor)
+	}
+	return r.ID < c.RunID
 }
 
 // LogFilter represents a set of filters that restrict the returned log results.
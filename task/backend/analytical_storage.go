@@ -5,11 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/query"
 	"github.com/influxdata/influxdb/v2/storage"
 	"go.uber.org/zap"
@@ -21,6 +23,8 @@ const (
 	startedAtField    = "startedAt"
 	finishedAtField   = "finishedAt"
 	requestedAtField  = "requestedAt"
+	attemptField      = "attempt"
+	retryOfField      = "retryOf"
 	logField          = "logs"
 
 	taskIDTag = "taskID"
@@ -132,8 +136,9 @@ func (as *AnalyticalStorage) FindRuns(ctx context.Context, filter influxdb.RunFi
 		return runs, n, err
 	}
 
-	// if we reached the limit lets stop here
-	if len(runs) >= filter.Limit {
+	// if we reached the limit lets stop here. Runs listed after a cursor are
+	// ordered by when they were scheduled, so completed runs may come first.
+	if len(runs) >= filter.Limit && filter.Cursor == nil {
 		return runs, n, err
 	}
 
@@ -152,6 +157,15 @@ func (as *AnalyticalStorage) FindRuns(ctx context.Context, filter influxdb.RunFi
 		filterPart = fmt.Sprintf(`|> filter(fn: (r) => r.runID > %q)`, filter.After.String())
 	}
 
+	cursorPart, limit := "", filter.Limit-len(runs)
+	if c := filter.Cursor; c != nil {
+		if !c.IsZero() {
+			sf := c.ScheduledFor.UTC().Format(time.RFC3339)
+			cursorPart = fmt.Sprintf(`|> filter(fn: (r) => r.scheduledFor < %q or (r.scheduledFor == %q and r.runID < %q))`, sf, sf, c.RunID.String())
+		}
+		limit = filter.Limit
+	}
+
 	// the data will be stored for 7 days in the system bucket so pulling 14d's is sufficient.
 	runsScript := fmt.Sprintf(`from(bucketID: %q)
 	  |> range(start: -14d)
@@ -159,33 +173,20 @@ func (as *AnalyticalStorage) FindRuns(ctx context.Context, filter influxdb.RunFi
 	  |> filter(fn: (r) => r._measurement == "runs" and r.taskID == %q)
 	  %s
 	  |> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
+	  %s
 	  |> group(columns: ["taskID"])
-	  |> sort(columns:["scheduledFor"], desc: true)
+	  |> sort(columns:["scheduledFor", "runID"], desc: true)
 	  |> limit(n:%d)
 
-	  `, sb.ID.String(), filter.Task.String(), filterPart, filter.Limit-len(runs))
+	  `, sb.ID.String(), filter.Task.String(), filterPart, cursorPart, limit)
 
 	// At this point we are behind authorization
 	// so we are faking a read only permission to the org's system bucket
-	runSystemBucketID := sb.ID
-	runAuth := &influxdb.Authorization{
-		Status: influxdb.Active,
-		ID:     sb.ID,
-		OrgID:  task.OrganizationID,
-		Permissions: []influxdb.Permission{
-			{
-				Action: influxdb.ReadAction,
-				Resource: influxdb.Resource{
-					Type:  influxdb.BucketsResourceType,
-					OrgID: &task.OrganizationID,
-					ID:    &runSystemBucketID,
-				},
-			},
-		},
-	}
+	runAuth := systemBucketAuth(task.OrganizationID, sb.ID)
 	request := &query.Request{Authorization: runAuth, OrganizationID: task.OrganizationID, Compiler: lang.FluxCompiler{Query: runsScript}}
 
-	ittr, err := as.qs.Query(ctx, request)
+	// storage restricts the reads to the permissions of the authorizer on the context
+	ittr, err := as.qs.Query(icontext.SetAuthorizer(ctx, runAuth), request)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -204,9 +205,38 @@ func (as *AnalyticalStorage) FindRuns(ctx context.Context, filter influxdb.RunFi
 	}
 	runs = as.combineRuns(runs, re.runs)
 
+	if filter.Cursor != nil {
+		sort.SliceStable(runs, func(i, j int) bool {
+			return influxdb.NewRunCursor(runs[i]).Precedes(runs[j])
+		})
+		if len(runs) > filter.Limit {
+			runs = runs[:filter.Limit]
+		}
+	}
+
 	return runs, len(runs), err
 }
 
+// systemBucketAuth returns a read only permission to the system bucket of an
+// org, for the queries that are made behind authorization.
+func systemBucketAuth(orgID, bucketID influxdb.ID) *influxdb.Authorization {
+	return &influxdb.Authorization{
+		Status: influxdb.Active,
+		ID:     bucketID,
+		OrgID:  orgID,
+		Permissions: []influxdb.Permission{
+			{
+				Action: influxdb.ReadAction,
+				Resource: influxdb.Resource{
+					Type:  influxdb.BucketsResourceType,
+					OrgID: &orgID,
+					ID:    &bucketID,
+				},
+			},
+		},
+	}
+}
+
 // remove any kv runs that exist in the list of completed runs
 func (as *AnalyticalStorage) combineRuns(currentRuns, completeRuns []*influxdb.Run) []*influxdb.Run {
 	crMap := map[influxdb.ID]int{}
@@ -263,25 +293,11 @@ func (as *AnalyticalStorage) FindRunByID(ctx context.Context, taskID, runID infl
 
 	// At this point we are behind authorization
 	// so we are faking a read only permission to the org's system bucket
-	runSystemBucketID := sb.ID
-	runAuth := &influxdb.Authorization{
-		ID:     sb.ID,
-		Status: influxdb.Active,
-		OrgID:  task.OrganizationID,
-		Permissions: []influxdb.Permission{
-			{
-				Action: influxdb.ReadAction,
-				Resource: influxdb.Resource{
-					Type:  influxdb.BucketsResourceType,
-					OrgID: &task.OrganizationID,
-					ID:    &runSystemBucketID,
-				},
-			},
-		},
-	}
+	runAuth := systemBucketAuth(task.OrganizationID, sb.ID)
 	request := &query.Request{Authorization: runAuth, OrganizationID: task.OrganizationID, Compiler: lang.FluxCompiler{Query: findRunScript}}
 
-	ittr, err := as.qs.Query(ctx, request)
+	// storage restricts the reads to the permissions of the authorizer on the context
+	ittr, err := as.qs.Query(icontext.SetAuthorizer(ctx, runAuth), request)
 	if err != nil {
 		return nil, err
 	}
@@ -390,6 +406,19 @@ func (re *runReader) readRuns(cr flux.ColReader) error {
 					continue
 				}
 				r.ScheduledFor = scheduled.UTC()
+			case attemptField:
+				if cr.Ints(j).IsValid(i) {
+					r.Attempt = int(cr.Ints(j).Value(i))
+				}
+			case retryOfField:
+				if cr.Strings(j).ValueString(i) != "" {
+					id, err := influxdb.IDFromString(cr.Strings(j).ValueString(i))
+					if err != nil {
+						re.log.Info("Failed to parse retryOf", zap.Error(err))
+						continue
+					}
+					r.RetryOf = *id
+				}
 			case statusTag:
 				r.Status = cr.Strings(j).ValueString(i)
 			case finishedAtField:
@@ -113,3 +113,163 @@ func TaskNotifyCoordinatorOfExisting(ctx context.Context, ts TaskService, tcs Ta
 
 	return nil
 }
+
+// SyncCoordinator is a Coordinator that is also told about tasks that were
+// updated or deleted.
+type SyncCoordinator interface {
+	Coordinator
+	TaskUpdated(ctx context.Context, from, to *influxdb.Task) error
+	TaskDeleted(ctx context.Context, id influxdb.ID) error
+}
+
+// SyncCoordinatorWithTasks tells the coordinator about the tasks that were
+// created, updated or deleted through other schedulers that share the store.
+// Every interval, it lists the next page of tasks by the provided task service,
+// and compares it with the tasks it knew about in the same range of IDs,
+// starting over from the first task once it reached the last one.
+// It returns when ctx is done.
+func SyncCoordinatorWithTasks(ctx context.Context, log *zap.Logger, ts TaskService, coord SyncCoordinator, interval time.Duration) {
+	known, err := findAllTasks(ctx, ts)
+	if err != nil {
+		log.Error("Failed to list tasks", zap.Error(err))
+		known = map[influxdb.ID]*influxdb.Task{}
+	}
+	syncer := &taskSync{
+		log:      log,
+		ts:       ts,
+		coord:    coord,
+		pageSize: influxdb.TaskMaxPageSize,
+		known:    known,
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := syncer.syncPage(ctx); err != nil {
+			log.Error("Failed to list tasks", zap.Error(err))
+		}
+	}
+}
+
+// taskSync syncs a coordinator with the tasks of a task service, one page at a time.
+type taskSync struct {
+	log      *zap.Logger
+	ts       TaskService
+	coord    SyncCoordinator
+	pageSize int
+
+	known map[influxdb.ID]*influxdb.Task
+	after *influxdb.ID // the last task of the previous page, nil to start over
+}
+
+// syncPage syncs the coordinator with the page of tasks after the previous one.
+func (s *taskSync) syncPage(ctx context.Context) error {
+	tasks, _, err := s.ts.FindTasks(ctx, influxdb.TaskFilter{
+		After: s.after,
+		Limit: s.pageSize,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the page covers the IDs after the previous page up to its last task,
+	// or all of the remaining IDs when it is the last page.
+	lastPage := len(tasks) < s.pageSize
+	inPage := func(id influxdb.ID) bool {
+		if s.after != nil && id <= *s.after {
+			return false
+		}
+		return lastPage || id <= tasks[len(tasks)-1].ID
+	}
+
+	page := make(map[influxdb.ID]*influxdb.Task, len(tasks))
+	for _, task := range tasks {
+		page[task.ID] = task
+	}
+	known := map[influxdb.ID]*influxdb.Task{}
+	for id, task := range s.known {
+		if inPage(id) {
+			known[id] = task
+		}
+	}
+
+	syncCoordinator(ctx, s.log, s.coord, known, page)
+
+	for id := range known {
+		delete(s.known, id)
+	}
+	for id, task := range page {
+		s.known[id] = task
+	}
+
+	if lastPage {
+		s.after = nil
+	} else {
+		after := tasks[len(tasks)-1].ID
+		s.after = &after
+	}
+	return nil
+}
+
+// syncCoordinator tells the coordinator about the differences between the
+// tasks it knows about and the current tasks.
+func syncCoordinator(ctx context.Context, log *zap.Logger, coord SyncCoordinator, known, tasks map[influxdb.ID]*influxdb.Task) {
+	for id, task := range tasks {
+		var err error
+		prev, ok := known[id]
+		switch {
+		case !ok:
+			if task.Status != string(influxdb.TaskActive) {
+				continue
+			}
+			err = coord.TaskCreated(ctx, task)
+		case prev.UpdatedAt.Equal(task.UpdatedAt) && prev.Status == task.Status:
+			continue
+		case prev.Status != string(influxdb.TaskActive) && task.Status != string(influxdb.TaskActive):
+			continue
+		default:
+			err = coord.TaskUpdated(ctx, prev, task)
+		}
+		if err != nil {
+			log.Error("Failed to sync task with coordinator", zap.String("taskID", id.String()), zap.Error(err))
+		}
+	}
+
+	for id := range known {
+		if _, ok := tasks[id]; ok {
+			continue
+		}
+		if err := coord.TaskDeleted(ctx, id); err != nil {
+			log.Error("Failed to sync deleted task with coordinator", zap.String("taskID", id.String()), zap.Error(err))
+		}
+	}
+}
+
+// findAllTasks lists all tasks by the provided task service, one page at a time.
+func findAllTasks(ctx context.Context, ts TaskService) (map[influxdb.ID]*influxdb.Task, error) {
+	all := map[influxdb.ID]*influxdb.Task{}
+	tasks, _, err := ts.FindTasks(ctx, influxdb.TaskFilter{})
+	if err != nil {
+		return nil, err
+	}
+	for len(tasks) > 0 {
+		for _, task := range tasks {
+			all[task.ID] = task
+		}
+
+		tasks, _, err = ts.FindTasks(ctx, influxdb.TaskFilter{
+			After: &tasks[len(tasks)-1].ID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return all, nil
+}
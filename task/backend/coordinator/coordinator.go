@@ -14,6 +14,7 @@ import (
 
 var _ middleware.Coordinator = (*Coordinator)(nil)
 var _ Executor = (*executor.Executor)(nil)
+var _ scheduler.DependentSchedulable = SchedulableTask{}
 
 // DefaultLimit is the maximum number of tasks that a given taskd server can own
 const DefaultLimit = 1000
@@ -24,11 +25,18 @@ type Executor interface {
 	Cancel(ctx context.Context, runID influxdb.ID) error
 }
 
+// Trigger is an abstraction of what runs tasks on writes, with only the functions needed by the coordinator
+type Trigger interface {
+	SetTask(task *influxdb.Task)
+	RemoveTask(id influxdb.ID)
+}
+
 // Coordinator is the intermediary between the scheduling/executing system and the rest of the task system
 type Coordinator struct {
-	log *zap.Logger
-	sch scheduler.Scheduler
-	ex  Executor
+	log     *zap.Logger
+	sch     scheduler.Scheduler
+	ex      Executor
+	trigger Trigger
 
 	limit int
 }
@@ -61,12 +69,29 @@ func (t SchedulableTask) LastScheduled() time.Time {
 	return t.lsc
 }
 
+// Upstream returns the IDs of the tasks the Task depends on
+func (t SchedulableTask) Upstream() []scheduler.ID {
+	ids := make([]scheduler.ID, 0, len(t.Task.Upstream))
+	for _, id := range t.Task.Upstream {
+		ids = append(ids, scheduler.ID(id))
+	}
+	return ids
+}
+
 func WithLimitOpt(i int) CoordinatorOption {
 	return func(c *Coordinator) {
 		c.limit = i
 	}
 }
 
+// WithTrigger is a CoordinatorOption that keeps t up to date with the triggers
+// of tasks, so that they run on writes.
+func WithTrigger(t Trigger) CoordinatorOption {
+	return func(c *Coordinator) {
+		c.trigger = t
+	}
+}
+
 // NewSchedulableTask transforms an influxdb task to a schedulable task type
 func NewSchedulableTask(task *influxdb.Task) (SchedulableTask, error) {
 
@@ -118,6 +143,9 @@ func (c *Coordinator) TaskCreated(ctx context.Context, task *influxdb.Task) erro
 		return err
 	}
 
+	if c.trigger != nil {
+		c.trigger.SetTask(task)
+	}
 	return nil
 }
 
@@ -140,11 +168,18 @@ func (c *Coordinator) TaskUpdated(ctx context.Context, from, to *influxdb.Task)
 		}
 	}
 
+	if c.trigger != nil {
+		c.trigger.SetTask(to)
+	}
 	return nil
 }
 
 //TaskDeleted asks the Scheduler to release the deleted task
 func (c *Coordinator) TaskDeleted(ctx context.Context, id influxdb.ID) error {
+	if c.trigger != nil {
+		c.trigger.RemoveTask(id)
+	}
+
 	tid := scheduler.ID(id)
 	if err := c.sch.Release(tid); err != nil && err != influxdb.ErrTaskNotClaimed {
 		return err
@@ -110,3 +110,125 @@ func (t *taskService) FindTasks(_ context.Context, filter influxdb.TaskFilter) (
 	tasks := t.otherPages[*filter.After]
 	return tasks, len(tasks), nil
 }
+
+type mockSyncCoordinator struct {
+	coordinator
+	updated []influxdb.ID
+	deleted []influxdb.ID
+}
+
+func (c *mockSyncCoordinator) TaskUpdated(_ context.Context, _, to *influxdb.Task) error {
+	c.updated = append(c.updated, to.ID)
+	return nil
+}
+
+func (c *mockSyncCoordinator) TaskDeleted(_ context.Context, id influxdb.ID) error {
+	c.deleted = append(c.deleted, id)
+	return nil
+}
+
+func Test_SyncCoordinator(t *testing.T) {
+	var (
+		coordinator = &mockSyncCoordinator{}
+		five        = influxdb.ID(5)
+		later       = aTime.Add(time.Minute)
+		known       = map[influxdb.ID]*influxdb.Task{
+			one:   {ID: one, Status: "active", UpdatedAt: aTime},
+			two:   {ID: two, Status: "active", UpdatedAt: aTime},
+			three: {ID: three, Status: "inactive", UpdatedAt: aTime},
+			five:  {ID: five, Status: "active", UpdatedAt: aTime},
+		}
+		tasks = map[influxdb.ID]*influxdb.Task{
+			// one is deleted
+			// updated
+			two: {ID: two, Status: "active", UpdatedAt: later},
+			// updated while inactive
+			three: {ID: three, Status: "inactive", UpdatedAt: later},
+			// created
+			four: {ID: four, Status: "active", UpdatedAt: later},
+			// unchanged
+			five: {ID: five, Status: "active", UpdatedAt: aTime},
+		}
+	)
+
+	syncCoordinator(context.Background(), zaptest.NewLogger(t), coordinator, known, tasks)
+
+	if diff := cmp.Diff([]*influxdb.Task{tasks[four]}, coordinator.tasks); diff != "" {
+		t.Errorf("unexpected created tasks sent to coordinator %v", diff)
+	}
+	if diff := cmp.Diff([]influxdb.ID{two}, coordinator.updated); diff != "" {
+		t.Errorf("unexpected updated tasks sent to coordinator %v", diff)
+	}
+	if diff := cmp.Diff([]influxdb.ID{one}, coordinator.deleted); diff != "" {
+		t.Errorf("unexpected deleted tasks sent to coordinator %v", diff)
+	}
+}
+
+func Test_SyncCoordinator_Pages(t *testing.T) {
+	var (
+		coordinator = &mockSyncCoordinator{}
+		five        = influxdb.ID(5)
+		later       = aTime.Add(time.Minute)
+		tasks       = &taskService{
+			pageOne: []*influxdb.Task{
+				{ID: one, Status: "active", UpdatedAt: aTime},
+				// updated
+				{ID: three, Status: "active", UpdatedAt: later},
+			},
+			otherPages: map[influxdb.ID][]*influxdb.Task{
+				// four is deleted, five is created
+				three: {{ID: five, Status: "active", UpdatedAt: later}},
+			},
+		}
+		syncer = &taskSync{
+			log:      zaptest.NewLogger(t),
+			ts:       tasks,
+			coord:    coordinator,
+			pageSize: 2,
+			known: map[influxdb.ID]*influxdb.Task{
+				one:   {ID: one, Status: "active", UpdatedAt: aTime},
+				three: {ID: three, Status: "active", UpdatedAt: aTime},
+				four:  {ID: four, Status: "active", UpdatedAt: aTime},
+			},
+		}
+	)
+
+	// the first page only covers the tasks up to three
+	if err := syncer.syncPage(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if tasks.filter.After != nil || tasks.filter.Limit != 2 {
+		t.Fatalf("unexpected filter for the first page %+v", tasks.filter)
+	}
+	if diff := cmp.Diff([]influxdb.ID{three}, coordinator.updated); diff != "" {
+		t.Errorf("unexpected updated tasks sent to coordinator %v", diff)
+	}
+	if len(coordinator.tasks) != 0 || len(coordinator.deleted) != 0 {
+		t.Fatalf("expected tasks after the first page not to be synced, got created %v and deleted %v", coordinator.tasks, coordinator.deleted)
+	}
+
+	// the last page covers all of the remaining tasks
+	if err := syncer.syncPage(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if tasks.filter.After == nil || *tasks.filter.After != three {
+		t.Fatalf("unexpected filter for the last page %+v", tasks.filter)
+	}
+	if diff := cmp.Diff([]*influxdb.Task{{ID: five, Status: "active", UpdatedAt: later}}, coordinator.tasks); diff != "" {
+		t.Errorf("unexpected created tasks sent to coordinator %v", diff)
+	}
+	if diff := cmp.Diff([]influxdb.ID{four}, coordinator.deleted); diff != "" {
+		t.Errorf("unexpected deleted tasks sent to coordinator %v", diff)
+	}
+	if syncer.after != nil {
+		t.Fatalf("expected to start over after the last page, got %v", *syncer.after)
+	}
+
+	// unchanged tasks are not synced again
+	if err := syncer.syncPage(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(coordinator.updated) != 1 || len(coordinator.tasks) != 1 || len(coordinator.deleted) != 1 {
+		t.Fatalf("expected no more changes, got updated %v, created %v and deleted %v", coordinator.updated, coordinator.tasks, coordinator.deleted)
+	}
+}
@@ -0,0 +1,252 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxBackfillRuns is the largest number of runs a single backfill may create.
+	maxBackfillRuns = 100000
+
+	// backfillRetention is how long the progress of a finished backfill is kept.
+	backfillRetention = 24 * time.Hour
+)
+
+var _ influxdb.BackfillService = (*Executor)(nil)
+
+// backfill is a backfill in progress. Its Backfill is guarded by the
+// executor's backfillMu.
+type backfill struct {
+	influxdb.Backfill
+	cancel context.CancelFunc
+}
+
+// CreateBackfill enqueues a run of the task for every point of its schedule in
+// the range of bc. At most bc.Parallelism runs are queued at once, and every
+// run is subject to the executor's limit func.
+//
+// The progress of a backfill is not persisted; runs that were already created
+// when the server restarts are resumed like any other run.
+func (e *Executor) CreateBackfill(ctx context.Context, taskID influxdb.ID, bc influxdb.BackfillCreate) (*influxdb.Backfill, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	if err := bc.Validate(); err != nil {
+		return nil, err
+	}
+	if bc.Parallelism == 0 {
+		bc.Parallelism = influxdb.DefaultBackfillParallelism
+	}
+
+	t, err := e.ts.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := schedulePoints(t, bc.Start, bc.End)
+	if err != nil {
+		return nil, err
+	}
+
+	bctx, cancel := context.WithCancel(icontext.SetAuthorizer(context.Background(), t.Authorization))
+	b := &backfill{
+		Backfill: influxdb.Backfill{
+			ID:          e.idGen.ID(),
+			TaskID:      taskID,
+			Start:       bc.Start.UTC(),
+			End:         bc.End.UTC(),
+			Parallelism: bc.Parallelism,
+			Status:      influxdb.RunStarted.String(),
+			Total:       len(points),
+			CreatedAt:   time.Now().UTC(),
+		},
+		cancel: cancel,
+	}
+
+	e.backfillMu.Lock()
+	e.pruneBackfillsLocked()
+	e.backfills[b.ID] = b
+	bf := b.Backfill
+	e.backfillMu.Unlock()
+
+	go e.runBackfill(bctx, b, points)
+	return &bf, nil
+}
+
+// schedulePoints returns the points of the schedule of t between start and end, inclusive.
+func schedulePoints(t *influxdb.Task, start, end time.Time) ([]time.Time, error) {
+	sch, from, err := scheduler.NewSchedule(t.EffectiveCron(), start.Add(-time.Second))
+	if err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "task has an invalid schedule",
+			Err:  err,
+		}
+	}
+
+	var points []time.Time
+	for {
+		next, err := sch.Next(from)
+		if err != nil {
+			return nil, err
+		}
+		if next.After(end) {
+			break
+		}
+		if !next.Before(start) {
+			if len(points) == maxBackfillRuns {
+				return nil, &influxdb.Error{
+					Code: influxdb.EInvalid,
+					Msg:  fmt.Sprintf("backfill would create more than %d runs", maxBackfillRuns),
+				}
+			}
+			points = append(points, next)
+		}
+		from = next
+	}
+
+	if len(points) == 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "task is not scheduled to run between backfill start and end",
+		}
+	}
+	return points, nil
+}
+
+func (e *Executor) runBackfill(ctx context.Context, b *backfill, points []time.Time) {
+	defer b.cancel()
+
+	var (
+		sem = make(chan struct{}, b.Parallelism)
+		wg  sync.WaitGroup
+	)
+
+	for _, scheduledFor := range points {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		p, err := e.createRun(ctx, b.TaskID, scheduledFor, time.Now())
+		if err != nil {
+			e.log.Info("Failed to create backfill run", zap.String("taskID", b.TaskID.String()), zap.Time("scheduledFor", scheduledFor), zap.Error(err))
+			e.finishBackfillRun(ctx, b, err)
+			<-sem
+			continue
+		}
+		e.startWorker()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-p.Done()
+			e.finishBackfillRun(ctx, b, p.Error())
+			<-sem
+		}()
+	}
+	wg.Wait()
+
+	e.backfillMu.Lock()
+	defer e.backfillMu.Unlock()
+
+	switch {
+	case ctx.Err() != nil:
+		b.Status = influxdb.RunCanceled.String()
+	case b.Failed > 0:
+		b.Status = influxdb.RunFail.String()
+	default:
+		b.Status = influxdb.RunSuccess.String()
+	}
+	finishedAt := time.Now().UTC()
+	b.FinishedAt = &finishedAt
+}
+
+// finishBackfillRun records the outcome of a run of a backfill. Runs that
+// failed because they were canceled are not counted.
+func (e *Executor) finishBackfillRun(ctx context.Context, b *backfill, err error) {
+	if err == influxdb.ErrRunCanceled || (err != nil && ctx.Err() != nil) {
+		return
+	}
+
+	e.backfillMu.Lock()
+	defer e.backfillMu.Unlock()
+
+	b.Completed++
+	if err != nil {
+		b.Failed++
+	}
+}
+
+// FindBackfillByID returns the progress of a backfill of a task.
+func (e *Executor) FindBackfillByID(ctx context.Context, taskID, id influxdb.ID) (*influxdb.Backfill, error) {
+	e.backfillMu.Lock()
+	defer e.backfillMu.Unlock()
+
+	b, ok := e.backfills[id]
+	if !ok || b.TaskID != taskID {
+		return nil, influxdb.ErrBackfillNotFound
+	}
+	bf := b.Backfill
+	return &bf, nil
+}
+
+// FindBackfills returns the backfills of a task, oldest first.
+func (e *Executor) FindBackfills(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Backfill, error) {
+	e.backfillMu.Lock()
+	defer e.backfillMu.Unlock()
+
+	e.pruneBackfillsLocked()
+
+	bfs := []*influxdb.Backfill{}
+	for _, b := range e.backfills {
+		if b.TaskID == taskID {
+			bf := b.Backfill
+			bfs = append(bfs, &bf)
+		}
+	}
+	sort.Slice(bfs, func(i, j int) bool {
+		return bfs[i].CreatedAt.Before(bfs[j].CreatedAt)
+	})
+	return bfs, nil
+}
+
+// CancelBackfill stops a backfill of a task from queuing more runs, and
+// cancels its runs that are queued or executing.
+func (e *Executor) CancelBackfill(ctx context.Context, taskID, id influxdb.ID) error {
+	e.backfillMu.Lock()
+	defer e.backfillMu.Unlock()
+
+	b, ok := e.backfills[id]
+	if !ok || b.TaskID != taskID {
+		return influxdb.ErrBackfillNotFound
+	}
+	if !b.Done() {
+		b.Status = influxdb.RunCanceled.String()
+	}
+	b.cancel()
+	return nil
+}
+
+// pruneBackfillsLocked removes backfills that finished more than
+// backfillRetention ago. It must be called with backfillMu held.
+func (e *Executor) pruneBackfillsLocked() {
+	for id, b := range e.backfills {
+		if b.FinishedAt != nil && time.Since(*b.FinishedAt) > backfillRetention {
+			delete(e.backfills, id)
+		}
+	}
+}
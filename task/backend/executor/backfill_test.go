@@ -0,0 +1,175 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/query"
+	"go.uber.org/zap/zaptest"
+)
+
+// nowQueryService is a query.AsyncQueryService that records the now time of
+// every query it executes. Queries succeed at once, unless block is set, in
+// which case they run until they are canceled.
+type nowQueryService struct {
+	block bool
+
+	mu   sync.Mutex
+	nows []time.Time
+}
+
+func (s *nowQueryService) Query(ctx context.Context, req *query.Request) (flux.Query, error) {
+	s.mu.Lock()
+	s.nows = append(s.nows, req.Compiler.(lang.ASTCompiler).Now)
+	s.mu.Unlock()
+
+	fq := &fakeQuery{
+		wait:    make(chan struct{}),
+		results: make(chan flux.Result),
+	}
+	if !s.block {
+		close(fq.wait)
+	}
+	go fq.run(ctx)
+	return fq, nil
+}
+
+func (s *nowQueryService) Nows() []time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nows := append([]time.Time(nil), s.nows...)
+	sort.Slice(nows, func(i, j int) bool { return nows[i].Before(nows[j]) })
+	return nows
+}
+
+func backfillSystem(t *testing.T, qs *nowQueryService) (*Executor, *influxdb.Task) {
+	t.Helper()
+
+	i := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	ex, _ := NewExecutor(zaptest.NewLogger(t), query.QueryServiceBridge{AsyncQueryService: qs}, i, i, i)
+	tc := createCreds(t, i)
+
+	ctx := icontext.SetAuthorizer(context.Background(), tc.Auth)
+	task, err := i.CreateTask(ctx, influxdb.TaskCreate{
+		OrganizationID: tc.OrgID,
+		OwnerID:        tc.Auth.GetUserID(),
+		Flux:           fmt.Sprintf(fmtTestScript, t.Name()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ex, task
+}
+
+func waitForBackfill(t *testing.T, ex *Executor, b *influxdb.Backfill) *influxdb.Backfill {
+	t.Helper()
+
+	for i := 0; i < 500; i++ {
+		b, err := ex.FindBackfillByID(context.Background(), b.TaskID, b.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.Done() {
+			return b
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("backfill did not finish in time")
+	return nil
+}
+
+func TestExecutor_Backfill(t *testing.T) {
+	qs := &nowQueryService{}
+	ex, task := backfillSystem(t, qs)
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	b, err := ex.CreateBackfill(context.Background(), task.ID, influxdb.BackfillCreate{
+		Start:       start,
+		End:         start.Add(150 * time.Second),
+		Parallelism: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Total != 3 || b.Parallelism != 2 {
+		t.Fatalf("unexpected backfill: %+v", b)
+	}
+
+	b = waitForBackfill(t, ex, b)
+	if b.Status != influxdb.RunSuccess.String() || b.Completed != 3 || b.Failed != 0 || b.FinishedAt == nil {
+		t.Fatalf("unexpected finished backfill: %+v", b)
+	}
+
+	nows := qs.Nows()
+	if len(nows) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(nows))
+	}
+	for i, now := range nows {
+		if exp := start.Add(time.Duration(i) * time.Minute); !now.Equal(exp) {
+			t.Errorf("run %d: expected now %s, got %s", i, exp, now)
+		}
+	}
+
+	bs, err := ex.FindBackfills(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bs) != 1 || bs[0].ID != b.ID {
+		t.Fatalf("unexpected backfills: %+v", bs)
+	}
+}
+
+func TestExecutor_CancelBackfill(t *testing.T) {
+	qs := &nowQueryService{block: true}
+	ex, task := backfillSystem(t, qs)
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	b, err := ex.CreateBackfill(context.Background(), task.ID, influxdb.BackfillCreate{
+		Start: start,
+		End:   start.Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ex.CancelBackfill(context.Background(), task.ID, b.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	b = waitForBackfill(t, ex, b)
+	if b.Status != influxdb.RunCanceled.String() || b.Completed >= b.Total {
+		t.Fatalf("unexpected canceled backfill: %+v", b)
+	}
+
+	if err := ex.CancelBackfill(context.Background(), task.ID+1, b.ID); err != influxdb.ErrBackfillNotFound {
+		t.Fatalf("expected backfill not found, got %v", err)
+	}
+}
+
+func TestExecutor_CreateBackfill_Invalid(t *testing.T) {
+	ex, task := backfillSystem(t, &nowQueryService{})
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, bc := range []influxdb.BackfillCreate{
+		{Start: start},
+		{Start: start, End: start.Add(-time.Minute)},
+		{Start: start, End: time.Now().Add(time.Hour)},
+		{Start: start, End: start.Add(time.Hour), Parallelism: influxdb.MaxBackfillParallelism + 1},
+		{Start: start.Add(time.Second), End: start.Add(59 * time.Second)},
+	} {
+		if _, err := ex.CreateBackfill(context.Background(), task.ID, bc); influxdb.ErrorCode(err) != influxdb.EInvalid {
+			t.Errorf("%+v: expected invalid error, got %v", bc, err)
+		}
+	}
+}
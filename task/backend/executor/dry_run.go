@@ -0,0 +1,197 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/query"
+	stdlib "github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/v2/task/options"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+var _ influxdb.TaskDryRunService = (*Executor)(nil)
+
+// DryRunTask executes a task once for req.ScheduledFor with the task's
+// authorization, and returns the points its to() calls write instead of
+// writing them to storage.
+//
+// A dry run is not a run of the task: it is not recorded, it does not update
+// the task and it is not subject to the executor's limit func.
+func (e *Executor) DryRunTask(ctx context.Context, taskID influxdb.ID, req influxdb.TaskDryRunRequest) (*influxdb.TaskDryRun, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	t, err := e.ts.FindTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !t.Authorization.GetUserID().Valid() {
+		t.Authorization.UserID = t.OwnerID
+	}
+
+	script := t.Flux
+	if req.Flux != nil {
+		if _, err := options.FromScript(*req.Flux); err != nil {
+			return nil, influxdb.ErrTaskOptionParse(err)
+		}
+		script = *req.Flux
+	}
+
+	scheduledFor := req.ScheduledFor
+	if scheduledFor.IsZero() {
+		scheduledFor = time.Now()
+	}
+	scheduledFor = scheduledFor.UTC()
+
+	ctx = icontext.SetAuthorizer(ctx, t.Authorization)
+	compiler, err := e.buildCompiler(ctx, script, scheduledFor)
+	if err != nil {
+		return nil, influxdb.ErrFluxParseError(err)
+	}
+	pc := &planCompiler{Compiler: compiler}
+	w := &dryRunPointsWriter{}
+
+	it, err := e.qs.Query(stdlib.ContextWithPointsWriter(ctx, w), &query.Request{
+		Authorization:  t.Authorization,
+		OrganizationID: t.OrganizationID,
+		Compiler:       pc,
+	})
+	if err != nil {
+		return nil, influxdb.ErrQueryError(err)
+	}
+
+	var (
+		results bytes.Buffer
+		runErr  error
+	)
+	enc := csv.NewResultEncoder(csv.DefaultEncoderConfig())
+	for runErr == nil && it.More() {
+		if _, err := enc.Encode(&results, it.Next()); err != nil {
+			runErr = err
+		}
+	}
+	it.Release()
+
+	if runErr != nil {
+		return nil, influxdb.ErrRunExecutionError(runErr)
+	}
+	if err := it.Err(); err != nil {
+		return nil, influxdb.ErrResultIteratorError(err)
+	}
+
+	return &influxdb.TaskDryRun{
+		TaskID:       t.ID,
+		ScheduledFor: scheduledFor,
+		Results:      results.String(),
+		Writes:       w.writes(),
+		Plan:         pc.plan(),
+		Statistics:   it.Statistics(),
+	}, nil
+}
+
+// planCompiler is a flux.Compiler that keeps the program it compiles, so that
+// the plan of the program can be read once the program has been executed.
+type planCompiler struct {
+	flux.Compiler
+	program flux.Program
+}
+
+func (c *planCompiler) Compile(ctx context.Context) (flux.Program, error) {
+	p, err := c.Compiler.Compile(ctx)
+	c.program = p
+	return p, err
+}
+
+// plan returns the formatted plan of the compiled program, or an empty string
+// if it is unknown.
+func (c *planCompiler) plan() string {
+	var spec *plan.Spec
+	switch p := c.program.(type) {
+	case *lang.AstProgram:
+		spec = p.PlanSpec
+	case *lang.Program:
+		spec = p.PlanSpec
+	}
+	if spec == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", plan.Formatted(spec))
+}
+
+// dryRunPointsWriter keeps the points written to it, as line protocol, by bucket.
+type dryRunPointsWriter struct {
+	mu      sync.Mutex
+	buckets []influxdb.ID
+	points  map[influxdb.ID]*bytes.Buffer
+}
+
+// WritePoints keeps points, which are exploded as they are for storage.
+func (w *dryRunPointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.points == nil {
+		w.points = make(map[influxdb.ID]*bytes.Buffer)
+	}
+
+	for _, p := range points {
+		_, bucketID := tsdb.DecodeNameSlice(p.Name())
+
+		var measurement string
+		var tags models.Tags
+		for _, tag := range p.Tags() {
+			switch string(tag.Key) {
+			case models.MeasurementTagKey:
+				measurement = string(tag.Value)
+			case models.FieldKeyTagKey:
+			default:
+				tags = append(tags, tag)
+			}
+		}
+		fields, err := p.Fields()
+		if err != nil {
+			return err
+		}
+		pt, err := models.NewPoint(measurement, tags, fields, p.Time())
+		if err != nil {
+			return err
+		}
+
+		buf, ok := w.points[bucketID]
+		if !ok {
+			buf = &bytes.Buffer{}
+			w.points[bucketID] = buf
+			w.buckets = append(w.buckets, bucketID)
+		}
+		buf.WriteString(pt.String())
+		buf.WriteByte('\n')
+	}
+	return nil
+}
+
+// writes returns the points written, by bucket in the order they were first written to.
+func (w *dryRunPointsWriter) writes() []influxdb.TaskDryRunWrite {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	writes := make([]influxdb.TaskDryRunWrite, 0, len(w.buckets))
+	for _, id := range w.buckets {
+		writes = append(writes, influxdb.TaskDryRunWrite{
+			BucketID: id,
+			Points:   w.points[id].String(),
+		})
+	}
+	return writes
+}
@@ -0,0 +1,91 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/query"
+	stdlib "github.com/influxdata/influxdb/v2/query/stdlib/influxdata/influxdb"
+	"github.com/influxdata/influxdb/v2/tsdb"
+)
+
+// writingQueryService is a query.AsyncQueryService that writes a point with
+// the points writer of the storage dependencies it injects, as the to()
+// function does, and then succeeds.
+type writingQueryService struct {
+	orgID, bucketID influxdb.ID
+	now             time.Time
+}
+
+func (s *writingQueryService) Query(ctx context.Context, req *query.Request) (flux.Query, error) {
+	s.now = req.Compiler.(*planCompiler).Compiler.(lang.ASTCompiler).Now
+
+	ctx = stdlib.StorageDependencies{}.Inject(ctx)
+	pt, err := models.NewPoint(
+		tsdb.EncodeNameString(s.orgID, s.bucketID),
+		models.NewTags(map[string]string{
+			models.MeasurementTagKey: "cpu",
+			models.FieldKeyTagKey:    "usage",
+			"host":                   "a",
+		}),
+		models.Fields{"usage": 1.5},
+		time.Unix(100, 0),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := stdlib.GetStorageDependencies(ctx).ToDeps.PointsWriter.WritePoints(ctx, []models.Point{pt}); err != nil {
+		return nil, err
+	}
+
+	fq := &fakeQuery{
+		wait:    make(chan struct{}),
+		results: make(chan flux.Result),
+	}
+	close(fq.wait)
+	go fq.run(ctx)
+	return fq, nil
+}
+
+func TestExecutor_DryRunTask(t *testing.T) {
+	qs := &writingQueryService{bucketID: 2}
+	ex, task := backfillSystem(t, nil)
+	ex.qs = query.QueryServiceBridge{AsyncQueryService: qs}
+	qs.orgID = task.OrganizationID
+
+	scheduledFor := time.Unix(1000, 0).UTC()
+	dr, err := ex.DryRunTask(context.Background(), task.ID, influxdb.TaskDryRunRequest{ScheduledFor: scheduledFor})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !qs.now.Equal(scheduledFor) || !dr.ScheduledFor.Equal(scheduledFor) {
+		t.Fatalf("expected the task to be executed for %s, got %s", scheduledFor, qs.now)
+	}
+	if !strings.Contains(dr.Results, ",res,") {
+		t.Fatalf("expected the results of the task, got:\n%s", dr.Results)
+	}
+	if len(dr.Writes) != 1 || dr.Writes[0].BucketID != 2 || dr.Writes[0].Points != "cpu,host=a usage=1.5 100000000000\n" {
+		t.Fatalf("unexpected writes %+v", dr.Writes)
+	}
+
+	// dry runs do not update the task
+	found, err := ex.ts.FindTaskByID(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found.LatestCompleted.Equal(task.LatestCompleted) {
+		t.Fatalf("expected the task to be unchanged, got latest completed %s", found.LatestCompleted)
+	}
+
+	invalid := "from(bucket: "
+	if _, err := ex.DryRunTask(context.Background(), task.ID, influxdb.TaskDryRunRequest{Flux: &invalid}); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected an invalid error for invalid flux, got %v", err)
+	}
+}
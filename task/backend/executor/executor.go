@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -12,14 +13,24 @@ import (
 	icontext "github.com/influxdata/influxdb/v2/context"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/query"
+	"github.com/influxdata/influxdb/v2/snowflake"
 	"github.com/influxdata/influxdb/v2/task/backend"
 	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+	"github.com/influxdata/influxdb/v2/task/options"
 	"go.uber.org/zap"
 )
 
 const (
 	maxPromises       = 1000
 	defaultMaxWorkers = 100
+
+	// DefaultRetryBackoff is how long the executor waits before the first
+	// automatic retry of a failed run.
+	DefaultRetryBackoff = 5 * time.Second
+
+	// DefaultMaxRetryBackoff is the longest the executor waits before an
+	// automatic retry of a failed run.
+	DefaultMaxRetryBackoff = 5 * time.Minute
 )
 
 var _ scheduler.Executor = (*Executor)(nil)
@@ -46,9 +57,17 @@ func MultiLimit(limits ...LimitFunc) LimitFunc {
 // LimitFunc is a function the executor will use to
 type LimitFunc func(*influxdb.Task, *influxdb.Run) error
 
+// RunFinishedFunc is called with the outcome of a scheduled run once it is final, i.e.
+// the run succeeded, or it failed and will not be retried.
+type RunFinishedFunc func(id scheduler.ID, scheduledFor time.Time, err error)
+
 type executorConfig struct {
-	maxWorkers    int
-	buildCompiler CompilerBuilderFunc
+	maxWorkers      int
+	buildCompiler   CompilerBuilderFunc
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+	notifier        Notifier
+	orgLimits       OrgLimits
 }
 
 type executorOption func(*executorConfig)
@@ -60,6 +79,15 @@ func WithMaxWorkers(n int) executorOption {
 	}
 }
 
+// WithRetryBackoff specifies how long the Executor waits before retrying a
+// failed run. The wait doubles with each attempt, up to max.
+func WithRetryBackoff(initial, max time.Duration) executorOption {
+	return func(o *executorConfig) {
+		o.retryBackoff = initial
+		o.maxRetryBackoff = max
+	}
+}
+
 // CompilerBuilderFunc is a function that yields a new flux.Compiler. The
 // context.Context provided can be assumed to be an authorized context.
 type CompilerBuilderFunc func(ctx context.Context, query string, now time.Time) (flux.Compiler, error)
@@ -75,14 +103,20 @@ func WithCompilerBuilder(builder CompilerBuilderFunc) executorOption {
 // NewExecutor creates a new task executor
 func NewExecutor(log *zap.Logger, qs query.QueryService, as influxdb.AuthorizationService, ts influxdb.TaskService, tcs backend.TaskControlService, opts ...executorOption) (*Executor, *ExecutorMetrics) {
 	cfg := &executorConfig{
-		maxWorkers:    defaultMaxWorkers,
-		buildCompiler: NewASTCompiler,
+		maxWorkers:      defaultMaxWorkers,
+		buildCompiler:   NewASTCompiler,
+		retryBackoff:    DefaultRetryBackoff,
+		maxRetryBackoff: DefaultMaxRetryBackoff,
 	}
 	for _, opt := range opts {
 		opt(cfg)
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
 	e := &Executor{
+		ctx:    ctx,
+		cancel: cancel,
+
 		log: log,
 		ts:  ts,
 		tcs: tcs,
@@ -90,10 +124,18 @@ func NewExecutor(log *zap.Logger, qs query.QueryService, as influxdb.Authorizati
 		as:  as,
 
 		currentPromises: sync.Map{},
-		promiseQueue:    make(chan *promise, maxPromises),
+		queue:           newRunQueue(maxPromises, cfg.orgLimits),
 		workerLimit:     make(chan struct{}, cfg.maxWorkers),
 		limitFunc:       func(*influxdb.Task, *influxdb.Run) error { return nil }, // noop
 		buildCompiler:   cfg.buildCompiler,
+		retryBackoff:    cfg.retryBackoff,
+		maxRetryBackoff: cfg.maxRetryBackoff,
+		notifier:        cfg.notifier,
+
+		orgLimits: cfg.orgLimits,
+
+		idGen:     snowflake.NewDefaultIDGenerator(),
+		backfills: make(map[influxdb.ID]*backfill),
 	}
 
 	e.metrics = NewExecutorMetrics(e)
@@ -108,6 +150,10 @@ func NewExecutor(log *zap.Logger, qs query.QueryService, as influxdb.Authorizati
 
 // Executor it a task specific executor that works with the new scheduler system.
 type Executor struct {
+	// ctx is canceled when the executor is closed.
+	ctx    context.Context
+	cancel context.CancelFunc
+
 	log *zap.Logger
 	ts  influxdb.TaskService
 	tcs backend.TaskControlService
@@ -121,15 +167,39 @@ type Executor struct {
 	currentPromises sync.Map
 
 	// keep a pool of promise's we have in queue
-	promiseQueue chan *promise
+	queue *runQueue
 
 	limitFunc LimitFunc
 
+	runFinishedFunc RunFinishedFunc
+
 	// keep a pool of execution workers.
 	workerPool  sync.Pool
 	workerLimit chan struct{}
 
 	buildCompiler CompilerBuilderFunc
+
+	retryBackoff    time.Duration
+	maxRetryBackoff time.Duration
+
+	notifier Notifier
+	// notifying tracks the notifications being sent.
+	notifying sync.WaitGroup
+
+	// orgLimits hold the memory quota of the query of each run.
+	orgLimits OrgLimits
+
+	idGen      influxdb.IDGenerator
+	backfillMu sync.Mutex
+	backfills  map[influxdb.ID]*backfill
+}
+
+// Close stops the executor from queuing the runs it retries, and cancels the
+// notifications being sent. Retries still waiting on their backoff are left
+// currently running, to be resumed on restart.
+func (e *Executor) Close() {
+	e.cancel()
+	e.notifying.Wait()
 }
 
 // SetLimitFunc sets the limit func for this task executor
@@ -137,6 +207,12 @@ func (e *Executor) SetLimitFunc(l LimitFunc) {
 	e.limitFunc = l
 }
 
+// SetRunFinishedFunc sets the func this task executor calls with the final
+// outcome of every run.
+func (e *Executor) SetRunFinishedFunc(fn RunFinishedFunc) {
+	e.runFinishedFunc = fn
+}
+
 // Execute is a executor to satisfy the needs of tasks
 func (e *Executor) Execute(ctx context.Context, id scheduler.ID, scheduledFor time.Time, runAt time.Time) error {
 	_, err := e.PromisedExecute(ctx, id, scheduledFor, runAt)
@@ -173,6 +249,19 @@ func (e *Executor) ManualRun(ctx context.Context, id influxdb.ID, runID influxdb
 	return p, err
 }
 
+// TriggerRun runs the task id, with the time range of the writes that
+// triggered the run set as the triggerStart and triggerStop task options.
+func (e *Executor) TriggerRun(ctx context.Context, id influxdb.ID, trigger influxdb.RunTrigger) (Promise, error) {
+	r, err := e.tcs.CreateTriggeredRun(ctx, id, trigger)
+	if err != nil {
+		return nil, err
+	}
+	p, err := e.createPromise(ctx, r)
+
+	e.startWorker()
+	return p, err
+}
+
 func (e *Executor) ResumeCurrentRun(ctx context.Context, id influxdb.ID, runID influxdb.ID) (Promise, error) {
 	cr, err := e.tcs.CurrentlyRunning(ctx, id)
 	if err != nil {
@@ -254,27 +343,114 @@ func (e *Executor) createPromise(ctx context.Context, run *influxdb.Run) (*promi
 		t.Authorization.UserID = t.OwnerID
 	}
 
-	ctx, cancel := context.WithCancel(ctx)
-	// create promise
-	p := &promise{
-		run:        run,
-		task:       t,
-		auth:       t.Authorization,
-		createdAt:  time.Now().UTC(),
-		done:       make(chan struct{}),
-		ctx:        ctx,
-		cancelFunc: cancel,
-	}
+	p := newPromise(ctx, t, run)
 
 	// insert promise into queue to be worked
 	// when the queue gets full we will hand and apply back pressure to the scheduler
-	e.promiseQueue <- p
+	e.queue.push(p)
 
 	// insert the promise into the registry
 	e.currentPromises.Store(run.ID, p)
 	return p, nil
 }
 
+// retry creates the next attempt of the failed run of p, if the task's retry
+// option allows for another attempt, and queues it once its backoff has passed.
+// It reports whether the run will be retried.
+func (e *Executor) retry(p *promise) bool {
+	o, err := options.FromScript(p.task.Flux)
+	if err != nil || o.Retry == nil {
+		return false
+	}
+
+	attempt := p.run.Attempt
+	if attempt == 0 {
+		attempt = 1
+	}
+	if int64(attempt) >= *o.Retry {
+		return false
+	}
+
+	run, err := e.tcs.CreateRetryRun(p.ctx, p.task.ID, p.run.ID)
+	if err != nil {
+		e.log.Error("Failed to create retry run", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
+		return false
+	}
+
+	backoff := e.retryBackoffFor(attempt)
+	e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Retrying as run %s (attempt %d of %d) in %s", run.ID, run.Attempt, *o.Retry, backoff))
+	e.tcs.AddRunLog(p.ctx, p.task.ID, run.ID, time.Now().UTC(), fmt.Sprintf("Retry of failed run %s (attempt %d of %d)", p.run.ID, run.Attempt, *o.Retry))
+	e.metrics.RetryRun(p.task)
+
+	// the retry outlives the failed run, so it must not be canceled along with it,
+	// but it must not outlive the executor.
+	ctx := icontext.SetAuthorizer(e.ctx, p.task.Authorization)
+	if lease, ok := icontext.GetTaskLease(p.ctx); ok {
+		// the retry is fenced by the lease the failed run was created under
+		ctx = icontext.SetTaskLease(ctx, lease)
+	}
+	rp := newPromise(ctx, p.task, run)
+	e.currentPromises.Store(run.ID, rp)
+
+	go func() {
+		select {
+		case <-time.After(backoff):
+		case <-rp.ctx.Done():
+			if e.ctx.Err() != nil {
+				// the executor is closed: leave the retry to be resumed on restart.
+				rp.err = influxdb.ErrRunCanceled
+				close(rp.done)
+				e.currentPromises.Delete(rp.run.ID)
+				return
+			}
+			e.cancelQueued(rp)
+			return
+		}
+
+		e.queue.push(rp)
+		e.startWorker()
+	}()
+	return true
+}
+
+// retryBackoffFor returns how long to wait before retrying a run that failed
+// on the given attempt.
+func (e *Executor) retryBackoffFor(attempt int) time.Duration {
+	backoff := e.retryBackoff
+	for i := 1; i < attempt && backoff < e.maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > e.maxRetryBackoff {
+		backoff = e.maxRetryBackoff
+	}
+	return backoff
+}
+
+// cancelQueued finishes the run of a promise that was canceled before a worker
+// picked it up.
+func (e *Executor) cancelQueued(p *promise) {
+	ctx := icontext.SetAuthorizer(context.Background(), p.task.Authorization)
+	e.tcs.AddRunLog(ctx, p.task.ID, p.run.ID, time.Now().UTC(), "Run canceled")
+	e.tcs.UpdateRunState(ctx, p.task.ID, p.run.ID, time.Now().UTC(), influxdb.RunCanceled)
+	if _, err := e.tcs.FinishRun(ctx, p.task.ID, p.run.ID); err != nil {
+		e.log.Error("Failed to finish run", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
+	}
+
+	p.err = influxdb.ErrRunCanceled
+	close(p.done)
+	e.currentPromises.Delete(p.run.ID)
+	e.runFinished(p, p.err)
+}
+
+// runFinished reports the final outcome of the run of p to the run finished
+// func. It is not called for runs that fail and are retried, nor for triggered
+// runs, which are not scheduled.
+func (e *Executor) runFinished(p *promise, err error) {
+	if e.runFinishedFunc != nil && p.run.Trigger == nil {
+		e.runFinishedFunc(scheduler.ID(p.task.ID), p.run.ScheduledFor, err)
+	}
+}
+
 type workerMaker struct {
 	e *Executor
 }
@@ -300,21 +476,21 @@ type worker struct {
 func (w *worker) work() {
 	// loop until we have no more work to do in the promise queue
 	for {
-		var prom *promise
 		// check to see if we can execute
-		select {
-		case p, ok := <-w.e.promiseQueue:
-
-			if !ok {
-				// the promiseQueue has been closed
-				return
-			}
-			prom = p
-		default:
-			// if nothing is left in the queue we are done
+		prom, ok := w.e.queue.pop()
+		if !ok {
+			// if nothing is left in the queue, or the orgs of the queued
+			// promises are at their limit, we are done
 			return
 		}
 
+		// the promise was canceled while it was queued
+		if prom.ctx.Err() != nil {
+			w.e.cancelQueued(prom)
+			w.e.queue.done(prom)
+			continue
+		}
+
 		// check to make sure we are below the limits.
 		for {
 			err := w.e.limitFunc(prom.task, prom.run)
@@ -329,13 +505,19 @@ func (w *worker) work() {
 			select {
 			// If done the promise was canceled
 			case <-prom.ctx.Done():
-				w.e.tcs.AddRunLog(prom.ctx, prom.task.ID, prom.run.ID, time.Now().UTC(), "Run canceled")
-				w.e.tcs.UpdateRunState(prom.ctx, prom.task.ID, prom.run.ID, time.Now().UTC(), influxdb.RunCanceled)
-				prom.err = influxdb.ErrRunCanceled
-				close(prom.done)
-				return
 			case <-time.After(time.Second):
 			}
+			if prom.ctx.Err() != nil {
+				break
+			}
+		}
+
+		// the promise was canceled while it waited on the limits, the worker
+		// moves on to the rest of the queue
+		if prom.ctx.Err() != nil {
+			w.e.cancelQueued(prom)
+			w.e.queue.done(prom)
+			continue
 		}
 
 		// execute the promise
@@ -346,6 +528,9 @@ func (w *worker) work() {
 
 		// remove promise from registry
 		w.e.currentPromises.Delete(prom.run.ID)
+
+		// let the next promise of the org run
+		w.e.queue.done(prom)
 	}
 }
 
@@ -380,12 +565,13 @@ func (w *worker) finish(p *promise, rs influxdb.RunStatus, err error) {
 	w.e.metrics.FinishRun(p.task, rs, rd)
 
 	// log error
+	var retried bool
 	if err != nil {
 		w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), err.Error())
 		w.e.log.Debug("Execution failed", zap.Error(err), zap.String("taskID", p.task.ID.String()))
 		w.e.metrics.LogError(p.task.Type, err)
 
-		if backend.IsUnrecoverable(err) {
+		if isUnrecoverable(err) {
 			// TODO (al): once user notification system is put in place, this code should be uncommented
 			// if we get an error that requires user intervention to fix, deactivate the task and alert the user
 			// inactive := string(backend.TaskInactive)
@@ -395,6 +581,9 @@ func (w *worker) finish(p *promise, rs influxdb.RunStatus, err error) {
 			w.e.tcs.AddRunLog(p.ctx, p.task.ID, p.run.ID, time.Now().UTC(), fmt.Sprintf("Task encountered unrecoverable error, requires admin action: %v", err.Error()))
 			// add to metrics
 			w.e.metrics.LogUnrecoverableError(p.task.ID, err)
+		} else if p.ctx.Err() == nil {
+			// the run was not canceled, so it may succeed if it is tried again
+			retried = w.e.retry(p)
 		}
 
 		p.err = err
@@ -402,9 +591,17 @@ func (w *worker) finish(p *promise, rs influxdb.RunStatus, err error) {
 		w.e.log.Debug("Completed successfully", zap.String("taskID", p.task.ID.String()))
 	}
 
+	if !retried {
+		w.notify(ctx, p, rs, err)
+	}
+
 	if _, err := w.e.tcs.FinishRun(p.ctx, p.task.ID, p.run.ID); err != nil {
 		w.e.log.Error("Failed to finish run", zap.String("taskID", p.task.ID.String()), zap.String("runID", p.run.ID.String()), zap.Error(err))
 	}
+
+	if !retried {
+		w.e.runFinished(p, err)
+	}
 }
 
 func (w *worker) executeQuery(p *promise) {
@@ -414,17 +611,29 @@ func (w *worker) executeQuery(p *promise) {
 	// start
 	w.start(p)
 
-	ctx = icontext.SetAuthorizer(ctx, p.task.Authorization)
-	compiler, err := w.buildCompiler(ctx, p.task.Flux, p.run.ScheduledFor)
+	script := p.task.Flux
+	if tr := p.run.Trigger; tr != nil {
+		s, err := options.WithTriggerRange(script, tr.Start, tr.Stop)
+		if err != nil {
+			w.finish(p, influxdb.RunFail, influxdb.ErrFluxParseError(err))
+			return
+		}
+		script = s
+	}
+
+	// the points the run writes do not trigger the task again
+	ctx = icontext.SetTaskRun(icontext.SetAuthorizer(ctx, p.task.Authorization), p.task.ID)
+	compiler, err := w.buildCompiler(ctx, script, p.run.ScheduledFor)
 	if err != nil {
 		w.finish(p, influxdb.RunFail, influxdb.ErrFluxParseError(err))
 		return
 	}
 
 	req := &query.Request{
-		Authorization:  p.auth,
-		OrganizationID: p.task.OrganizationID,
-		Compiler:       compiler,
+		Authorization:    p.auth,
+		OrganizationID:   p.task.OrganizationID,
+		Compiler:         compiler,
+		MemoryBytesQuota: w.e.orgLimits.of(p.task.OrganizationID).MemoryBytesQuota,
 	}
 	req.WithReturnNoContent(true)
 	it, err := w.e.qs.Query(ctx, req)
@@ -465,6 +674,25 @@ func (w *worker) executeQuery(p *promise) {
 	w.finish(p, influxdb.RunSuccess, nil)
 }
 
+// isUnrecoverable reports whether err requires user intervention to resolve,
+// in which case the run must not be retried.
+func isUnrecoverable(err error) bool {
+	if backend.IsUnrecoverable(err) {
+		return true
+	}
+	for err != nil {
+		if _, ok := err.(*scheduler.ErrUnrecoverable); ok {
+			return true
+		}
+		if ierr, ok := err.(*influxdb.Error); ok {
+			err = ierr.Err
+			continue
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
+
 // RunsActive returns the current number of workers, which is equivalent to
 // the number of runs actively running
 func (e *Executor) RunsActive() int {
@@ -478,7 +706,12 @@ func (e *Executor) WorkersBusy() float64 {
 
 // PromiseQueueUsage returns the percent of the Promise Queue that is currently filled
 func (e *Executor) PromiseQueueUsage() float64 {
-	return float64(len(e.promiseQueue)) / float64(cap(e.promiseQueue))
+	return float64(e.queue.len()) / float64(e.queue.cap())
+}
+
+// OrgRuns returns the number of queued runs, and of runs being worked, by org.
+func (e *Executor) OrgRuns() (queued, active map[influxdb.ID]int) {
+	return e.queue.orgStats()
 }
 
 // promise represents a promise the executor makes to finish a run's execution asynchronously.
@@ -497,6 +730,19 @@ type promise struct {
 	cancelFunc context.CancelFunc
 }
 
+func newPromise(ctx context.Context, t *influxdb.Task, run *influxdb.Run) *promise {
+	ctx, cancel := context.WithCancel(ctx)
+	return &promise{
+		run:        run,
+		task:       t,
+		auth:       t.Authorization,
+		createdAt:  time.Now().UTC(),
+		done:       make(chan struct{}),
+		ctx:        ctx,
+		cancelFunc: cancel,
+	}
+}
+
 // ID is the id of the run that was created
 func (p *promise) ID() influxdb.ID {
 	return p.run.ID
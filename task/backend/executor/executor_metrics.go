@@ -15,6 +15,7 @@ type ExecutorMetrics struct {
 	errorsCounter        *prometheus.CounterVec
 	manualRunsCounter    *prometheus.CounterVec
 	resumeRunsCounter    *prometheus.CounterVec
+	retryRunsCounter     *prometheus.CounterVec
 	unrecoverableCounter *prometheus.CounterVec
 	runLatency           *prometheus.HistogramVec
 }
@@ -23,6 +24,8 @@ type runCollector struct {
 	totalRunsActive   *prometheus.Desc
 	workersBusy       *prometheus.Desc
 	promiseQueueUsage *prometheus.Desc
+	orgRunsQueued     *prometheus.Desc
+	orgRunsActive     *prometheus.Desc
 	ex                *Executor
 }
 
@@ -84,6 +87,13 @@ func NewExecutorMetrics(ex *Executor) *ExecutorMetrics {
 			Help:      "Total number of runs resumed by task ID",
 		}, []string{"taskID"}),
 
+		retryRunsCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "retry_runs_counter",
+			Help:      "Total number of failed runs retried automatically by task ID",
+		}, []string{"taskID"}),
+
 		runLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: namespace,
 			Subsystem: subsystem,
@@ -114,6 +124,18 @@ func NewRunCollector(ex *Executor) prometheus.Collector {
 			nil,
 			prometheus.Labels{},
 		),
+		orgRunsQueued: prometheus.NewDesc(
+			"task_executor_org_runs_queued",
+			"Number of runs waiting in the promise queue by org",
+			[]string{"orgID"},
+			prometheus.Labels{},
+		),
+		orgRunsActive: prometheus.NewDesc(
+			"task_executor_org_runs_active",
+			"Number of workers currently running tasks by org",
+			[]string{"orgID"},
+			prometheus.Labels{},
+		),
 		ex: ex,
 	}
 }
@@ -128,6 +150,7 @@ func (em *ExecutorMetrics) PrometheusCollectors() []prometheus.Collector {
 		em.runDuration,
 		em.manualRunsCounter,
 		em.resumeRunsCounter,
+		em.retryRunsCounter,
 		em.unrecoverableCounter,
 		em.runLatency,
 	}
@@ -177,6 +200,8 @@ func (r *runCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- r.workersBusy
 	ch <- r.promiseQueueUsage
 	ch <- r.totalRunsActive
+	ch <- r.orgRunsQueued
+	ch <- r.orgRunsActive
 }
 
 // Collect returns the current state of all metrics of the run collector.
@@ -186,4 +211,17 @@ func (r *runCollector) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(r.promiseQueueUsage, prometheus.GaugeValue, r.ex.PromiseQueueUsage())
 
 	ch <- prometheus.MustNewConstMetric(r.totalRunsActive, prometheus.GaugeValue, float64(r.ex.RunsActive()))
+
+	queued, active := r.ex.OrgRuns()
+	for orgID, n := range queued {
+		ch <- prometheus.MustNewConstMetric(r.orgRunsQueued, prometheus.GaugeValue, float64(n), orgID.String())
+	}
+	for orgID, n := range active {
+		ch <- prometheus.MustNewConstMetric(r.orgRunsActive, prometheus.GaugeValue, float64(n), orgID.String())
+	}
+}
+
+// RetryRun increments the count of failed runs retried automatically for the given task.
+func (em *ExecutorMetrics) RetryRun(task *influxdb.Task) {
+	em.retryRunsCounter.WithLabelValues(task.ID.String()).Inc()
 }
@@ -46,7 +46,7 @@ type tes struct {
 	tc      testCreds
 }
 
-func taskExecutorSystem(t *testing.T) tes {
+func taskExecutorSystem(t *testing.T, opts ...executorOption) tes {
 	var (
 		aqs = newFakeQueryService()
 		qs  = query.QueryServiceBridge{
@@ -54,7 +54,7 @@ func taskExecutorSystem(t *testing.T) tes {
 		}
 		i           = kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
 		tcs         = &taskControlService{TaskControlService: i}
-		ex, metrics = NewExecutor(zaptest.NewLogger(t), qs, i, i, tcs)
+		ex, metrics = NewExecutor(zaptest.NewLogger(t), qs, i, i, tcs, opts...)
 	)
 	return tes{
 		svc:     aqs,
@@ -73,9 +73,14 @@ func TestTaskExecutor(t *testing.T) {
 	t.Run("ResumeRun", testResumingRun)
 	t.Run("WorkerLimit", testWorkerLimit)
 	t.Run("LimitFunc", testLimitFunc)
+	t.Run("LimitFuncCancel", testLimitFuncCancel)
 	t.Run("Metrics", testMetrics)
 	t.Run("IteratorFailure", testIteratorFailure)
 	t.Run("ErrorHandling", testErrorHandling)
+	t.Run("Retry", testRetry)
+	t.Run("RetryUnrecoverable", testRetryUnrecoverable)
+	t.Run("RetryClose", testRetryClose)
+	t.Run("OrgLimits", testOrgLimits)
 }
 
 func testQuerySuccess(t *testing.T) {
@@ -341,6 +346,74 @@ func testLimitFunc(t *testing.T) {
 	}
 }
 
+func testLimitFuncCancel(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t, WithMaxWorkers(1))
+
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	var (
+		tasks   []*influxdb.Task
+		scripts []string
+	)
+	for i := 0; i < 2; i++ {
+		script := fmt.Sprintf(fmtTestScript, fmt.Sprintf("%s-%d", t.Name(), i))
+		task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+		scripts = append(scripts, script)
+	}
+
+	// the first task never gets below its limit
+	waiting := make(chan struct{}, 1)
+	tes.ex.SetLimitFunc(func(task *influxdb.Task, _ *influxdb.Run) error {
+		if task.ID == tasks[0].ID {
+			select {
+			case waiting <- struct{}{}:
+			default:
+			}
+			return errors.New("never there")
+		}
+		return nil
+	})
+
+	blocked, err := tes.ex.PromisedExecute(ctx, scheduler.ID(tasks[0].ID), time.Unix(123, 0), time.Unix(123, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-waiting
+	next, err := tes.ex.PromisedExecute(ctx, scheduler.ID(tasks[1].ID), time.Unix(123, 0), time.Unix(123, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the only worker cancels the waiting run and moves on to the next one
+	blocked.Cancel(ctx)
+	<-blocked.Done()
+	if err := blocked.Error(); err != influxdb.ErrRunCanceled {
+		t.Fatalf("expected the run to be canceled, got %v", err)
+	}
+
+	tes.svc.WaitForQueryLive(t, scripts[1])
+	tes.svc.SucceedQuery(scripts[1])
+	<-next.Done()
+	if err := next.Error(); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := tes.i.CurrentlyRunning(ctx, tasks[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 0 {
+		t.Fatalf("expected the canceled run to be finished, got %v", runs)
+	}
+	if _, ok := tes.ex.currentPromises.Load(blocked.ID()); ok {
+		t.Fatal("expected the canceled run to leave the executor")
+	}
+}
+
 func testMetrics(t *testing.T) {
 	t.Parallel()
 	tes := taskExecutorSystem(t)
@@ -383,6 +456,10 @@ func testMetrics(t *testing.T) {
 	if got := *m.Gauge.Value; got != 1 {
 		t.Fatalf("expected 1 total runs active, got %v", got)
 	}
+	m = promtest.MustFindMetric(t, mg, "task_executor_org_runs_active", map[string]string{"orgID": tes.tc.OrgID.String()})
+	if got := *m.Gauge.Value; got != 1 {
+		t.Fatalf("expected 1 run active for the org, got %v", got)
+	}
 
 	tes.svc.SucceedQuery(script)
 	<-promise.Done()
@@ -531,6 +608,247 @@ func testErrorHandling(t *testing.T) {
 	*/
 }
 
+func testRetry(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t, WithRetryBackoff(time.Millisecond, time.Millisecond))
+
+	reg := prom.NewRegistry(zaptest.NewLogger(t))
+	reg.MustRegister(tes.metrics.PrometheusCollectors()...)
+
+	var (
+		finishedMu sync.Mutex
+		finished   []error
+	)
+	tes.ex.SetRunFinishedFunc(func(_ scheduler.ID, scheduledFor time.Time, err error) {
+		finishedMu.Lock()
+		defer finishedMu.Unlock()
+		if !scheduledFor.Equal(time.Unix(123, 0)) {
+			t.Errorf("unexpected scheduled for %s", scheduledFor)
+		}
+		finished = append(finished, err)
+	})
+
+	script := fmt.Sprintf(fmtTestRetryScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.FailQuery(script, errors.New("transient error"))
+
+	<-first.Done()
+	if got := first.Error(); got == nil {
+		t.Fatal("got no error when I should have")
+	}
+	finishedMu.Lock()
+	if len(finished) != 0 {
+		t.Fatalf("expected a retried run not to be reported as finished, got %v", finished)
+	}
+	finishedMu.Unlock()
+
+	// the failed run should link to its retry
+	failed := tes.tcs.run
+	if !strings.Contains(failed.Log[len(failed.Log)-1].Message, "Retrying as run") {
+		t.Fatalf("expected failed run to log its retry, got %q", failed.Log[len(failed.Log)-1].Message)
+	}
+
+	runs, err := tes.i.CurrentlyRunning(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 retry run, got %d", len(runs))
+	}
+	retry := runs[0]
+	if retry.Attempt != 2 || retry.RetryOf != failed.ID || !retry.ScheduledFor.Equal(failed.ScheduledFor) {
+		t.Fatalf("unexpected retry run: %+v", retry)
+	}
+
+	val, ok := tes.ex.currentPromises.Load(retry.ID)
+	if !ok {
+		t.Fatal("expected a promise for the retry run")
+	}
+	retryPromise := val.(*promise)
+
+	tes.svc.WaitForQueryLive(t, script)
+	tes.svc.FailQuery(script, errors.New("transient error"))
+
+	<-retryPromise.Done()
+	if got := retryPromise.Error(); got == nil {
+		t.Fatal("got no error when I should have")
+	}
+	finishedMu.Lock()
+	if len(finished) != 1 || finished[0] == nil {
+		t.Fatalf("expected the last attempt to be reported as failed, got %v", finished)
+	}
+	finishedMu.Unlock()
+
+	// the last attempt allowed by the retry option should not be retried
+	if runs, err := tes.i.CurrentlyRunning(context.Background(), task.ID); err != nil {
+		t.Fatal(err)
+	} else if len(runs) != 0 {
+		t.Fatalf("expected no more retry runs, got %d", len(runs))
+	}
+	if got := tes.tcs.run; got.ID != retry.ID || got.Attempt != 2 || got.Status != influxdb.RunFail.String() {
+		t.Fatalf("unexpected finished retry run: %+v", got)
+	}
+
+	mg := promtest.MustGather(t, reg)
+	m := promtest.MustFindMetric(t, mg, "task_executor_retry_runs_counter", map[string]string{"taskID": task.ID.String()})
+	if got := *m.Counter.Value; got != 1 {
+		t.Fatalf("expected 1 retried run, got %v", got)
+	}
+}
+
+func testRetryUnrecoverable(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t, WithRetryBackoff(time.Millisecond, time.Millisecond))
+
+	script := fmt.Sprintf(fmtTestRetryScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tes.svc.FailNextQuery(&scheduler.ErrUnrecoverable{})
+
+	promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-promise.Done()
+	if got := promise.Error(); got == nil {
+		t.Fatal("got no error when I should have")
+	}
+
+	if runs, err := tes.i.CurrentlyRunning(context.Background(), task.ID); err != nil {
+		t.Fatal(err)
+	} else if len(runs) != 0 {
+		t.Fatalf("expected unrecoverable error not to be retried, got %d runs", len(runs))
+	}
+}
+
+func testRetryClose(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t, WithRetryBackoff(time.Hour, time.Hour))
+
+	script := fmt.Sprintf(fmtTestRetryScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tes.svc.FailNextQuery(errors.New("transient error"))
+
+	first, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(126, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-first.Done()
+
+	runs, err := tes.i.CurrentlyRunning(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 {
+		t.Fatalf("expected 1 retry run, got %d", len(runs))
+	}
+	val, ok := tes.ex.currentPromises.Load(runs[0].ID)
+	if !ok {
+		t.Fatal("expected a promise for the retry run")
+	}
+	retryPromise := val.(*promise)
+
+	// closing the executor should stop the retry waiting on its backoff
+	tes.ex.Close()
+
+	select {
+	case <-retryPromise.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("retry was not stopped by closing the executor")
+	}
+	if got := retryPromise.Error(); got != influxdb.ErrRunCanceled {
+		t.Fatalf("expected retry to be canceled, got %v", got)
+	}
+
+	// the retry should be left to be resumed on restart
+	if runs, err := tes.i.CurrentlyRunning(context.Background(), task.ID); err != nil {
+		t.Fatal(err)
+	} else if len(runs) != 1 || runs[0].Status != influxdb.RunScheduled.String() {
+		t.Fatalf("expected the retry run to still be scheduled, got %+v", runs)
+	}
+}
+
+func TestExecutor_retryBackoffFor(t *testing.T) {
+	ex, _ := NewExecutor(zaptest.NewLogger(t), nil, nil, nil, nil, WithRetryBackoff(time.Second, 5*time.Second))
+	for attempt, exp := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 5 * time.Second,
+		9: 5 * time.Second,
+	} {
+		if got := ex.retryBackoffFor(attempt); got != exp {
+			t.Errorf("attempt %d: expected backoff %s, got %s", attempt, exp, got)
+		}
+	}
+}
+
+func TestExecutor_TriggerRun(t *testing.T) {
+	scripts := make(chan string, 1)
+	tes := taskExecutorSystem(t, WithCompilerBuilder(func(ctx context.Context, query string, now time.Time) (flux.Compiler, error) {
+		scripts <- query
+		return NewASTCompiler(ctx, query, now)
+	}))
+
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trigger := influxdb.RunTrigger{Start: time.Unix(100, 0), Stop: time.Unix(200, 0)}
+	promise, err := tes.ex.TriggerRun(ctx, task.ID, trigger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run, err := tes.i.FindRunByID(context.Background(), task.ID, promise.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Trigger == nil || !run.Trigger.Start.Equal(trigger.Start) || !run.Trigger.Stop.Equal(trigger.Stop) {
+		t.Fatalf("expected a triggered run, got %+v", run)
+	}
+
+	got := <-scripts
+	if !strings.Contains(got, "triggerStart: 1970-01-01T00:01:40Z") || !strings.Contains(got, "triggerStop: 1970-01-01T00:03:20Z") {
+		t.Fatalf("expected the trigger range in the task option, got:\n%s", got)
+	}
+	promise.Cancel(context.Background())
+	<-promise.Done()
+
+	// triggered runs are not part of the schedule of the task
+	found, err := tes.i.FindTaskByID(context.Background(), task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found.LatestCompleted.Equal(task.LatestCompleted) {
+		t.Fatalf("expected latest completed to be unchanged, got %s", found.LatestCompleted)
+	}
+}
+
 type taskControlService struct {
 	backend.TaskControlService
 
@@ -547,3 +865,54 @@ func (t *taskControlService) FinishRun(ctx context.Context, taskID influxdb.ID,
 	t.run, err = t.TaskControlService.FinishRun(ctx, taskID, runID)
 	return t.run, err
 }
+
+func testOrgLimits(t *testing.T) {
+	t.Parallel()
+	tes := taskExecutorSystem(t, WithOrgLimits(OrgLimits{Concurrency: 1}))
+	reg := prom.NewRegistry(zaptest.NewLogger(t))
+	reg.MustRegister(tes.metrics.PrometheusCollectors()...)
+
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	var (
+		scripts  []string
+		promises []Promise
+	)
+	for i := 0; i < 2; i++ {
+		script := fmt.Sprintf(fmtTestScript, fmt.Sprintf("%s-%d", t.Name(), i))
+		task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{OrganizationID: tes.tc.OrgID, OwnerID: tes.tc.Auth.GetUserID(), Flux: script})
+		if err != nil {
+			t.Fatal(err)
+		}
+		promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(123, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		scripts = append(scripts, script)
+		promises = append(promises, promise)
+	}
+
+	// the org may only run one task at once, the other waits in the queue
+	tes.svc.WaitForQueryLive(t, scripts[0])
+	mg := promtest.MustGather(t, reg)
+	m := promtest.MustFindMetric(t, mg, "task_executor_org_runs_queued", map[string]string{"orgID": tes.tc.OrgID.String()})
+	if got := *m.Gauge.Value; got != 1 {
+		t.Fatalf("expected 1 run queued for the org, got %v", got)
+	}
+	m = promtest.MustFindMetric(t, mg, "task_executor_org_runs_active", map[string]string{"orgID": tes.tc.OrgID.String()})
+	if got := *m.Gauge.Value; got != 1 {
+		t.Fatalf("expected 1 run active for the org, got %v", got)
+	}
+
+	tes.svc.SucceedQuery(scripts[0])
+	<-promises[0].Done()
+
+	tes.svc.WaitForQueryLive(t, scripts[1])
+	tes.svc.SucceedQuery(scripts[1])
+	<-promises[1].Done()
+
+	for _, p := range promises {
+		if err := p.Error(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
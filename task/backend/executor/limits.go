@@ -2,12 +2,90 @@ package executor
 
 import (
 	"context"
+	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/task/options"
 )
 
+// OrgLimits are the limits the executor applies to the runs of the tasks of
+// each org, so that the tasks of one org cannot monopolize the executor.
+type OrgLimits struct {
+	// Concurrency is the maximum number of runs of an org that are worked at
+	// once. The queued runs of orgs below their limit are worked in turns.
+	// Zero does not limit the runs.
+	Concurrency int
+
+	// MemoryBytesQuota is the maximum number of bytes the query of a run may
+	// use. Zero leaves the query to the memory quota of the query controller.
+	MemoryBytesQuota int64
+
+	// Overrides are the limits of specific orgs, in place of the limits of
+	// all orgs. A zero limit of an override keeps the limit of all orgs.
+	Overrides map[influxdb.ID]OrgLimits
+}
+
+// of returns the limits of the org orgID.
+func (l OrgLimits) of(orgID influxdb.ID) OrgLimits {
+	limits := OrgLimits{Concurrency: l.Concurrency, MemoryBytesQuota: l.MemoryBytesQuota}
+	o, ok := l.Overrides[orgID]
+	if !ok {
+		return limits
+	}
+	if o.Concurrency > 0 {
+		limits.Concurrency = o.Concurrency
+	}
+	if o.MemoryBytesQuota > 0 {
+		limits.MemoryBytesQuota = o.MemoryBytesQuota
+	}
+	return limits
+}
+
+// ParseOrgLimits parses the limits of an org, of the form
+// orgID=concurrency[:memoryBytes].
+func ParseOrgLimits(s string) (influxdb.ID, OrgLimits, error) {
+	var (
+		limits OrgLimits
+		orgID  influxdb.ID
+	)
+	invalid := fmt.Errorf("org limits %q are not of the form orgID=concurrency[:memoryBytes]", s)
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return orgID, limits, invalid
+	}
+	if err := orgID.DecodeFromString(s[:i]); err != nil {
+		return orgID, limits, invalid
+	}
+
+	concurrency, memory := s[i+1:], ""
+	if j := strings.Index(concurrency, ":"); j >= 0 {
+		concurrency, memory = concurrency[:j], concurrency[j+1:]
+	}
+	n, err := strconv.Atoi(concurrency)
+	if err != nil || n < 0 {
+		return orgID, limits, invalid
+	}
+	limits.Concurrency = n
+	if memory != "" {
+		m, err := strconv.ParseInt(memory, 10, 64)
+		if err != nil || m < 0 {
+			return orgID, limits, invalid
+		}
+		limits.MemoryBytesQuota = m
+	}
+	return orgID, limits, nil
+}
+
+// WithOrgLimits is an Executor option that applies limits to the runs of each org.
+func WithOrgLimits(l OrgLimits) executorOption {
+	return func(o *executorConfig) {
+		o.orgLimits = l
+	}
+}
+
 // ConcurrencyLimit creates a concurrency limit func that uses the executor to determine
 // if the task has exceeded the concurrency limit.
 func ConcurrencyLimit(exec *Executor) LimitFunc {
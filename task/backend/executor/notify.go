@@ -0,0 +1,314 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"go.uber.org/zap"
+)
+
+const (
+	// notifyTimeout is how long the executor waits for a notification to be sent.
+	notifyTimeout = 10 * time.Second
+
+	pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+)
+
+// TaskNotificationEvent is a failure, or a recovery from failures, of a task
+// that is notified to the notification endpoint of the task.
+type TaskNotificationEvent struct {
+	Task         *influxdb.Task
+	RunID        influxdb.ID
+	ScheduledFor time.Time
+
+	// Recovered is true when the run succeeded after notified failures.
+	Recovered bool
+
+	// Failures is the number of consecutive failed runs, including the run
+	// that failed, or that preceded the run that recovered.
+	Failures int
+
+	// Err is the error of the failed run.
+	Err error
+}
+
+// Message returns a human readable description of the event.
+func (ev TaskNotificationEvent) Message() string {
+	if ev.Recovered {
+		return fmt.Sprintf("Task %q (%s) recovered after %d consecutive failed runs", ev.Task.Name, ev.Task.ID, ev.Failures)
+	}
+	msg := fmt.Sprintf("Task %q (%s) failed %d consecutive times", ev.Task.Name, ev.Task.ID, ev.Failures)
+	if ev.Err != nil {
+		msg += ": " + ev.Err.Error()
+	}
+	return msg
+}
+
+// Notifier sends the failures and recoveries of tasks to notification endpoints.
+type Notifier interface {
+	Notify(ctx context.Context, endpointID influxdb.ID, ev TaskNotificationEvent) error
+}
+
+// WithNotifier is an Executor option that notifies the failures and recoveries
+// of tasks that configure a notification. Without a Notifier, nothing is notified.
+func WithNotifier(n Notifier) executorOption {
+	return func(o *executorConfig) {
+		o.notifier = n
+	}
+}
+
+// notificationEndpointFinder finds the notification endpoints a task notifies.
+type notificationEndpointFinder interface {
+	FindNotificationEndpointByID(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error)
+}
+
+// EndpointNotifier is a Notifier that sends notifications to the HTTP, Slack
+// and PagerDuty notification endpoints, with the secrets of the endpoints
+// loaded from a SecretService.
+type EndpointNotifier struct {
+	endpoints notificationEndpointFinder
+	secrets   influxdb.SecretService
+
+	client       *http.Client
+	pagerDutyURL string
+}
+
+// NewEndpointNotifier creates a Notifier that sends notifications to the
+// notification endpoints found in endpoints.
+func NewEndpointNotifier(endpoints notificationEndpointFinder, secrets influxdb.SecretService) *EndpointNotifier {
+	return &EndpointNotifier{
+		endpoints:    endpoints,
+		secrets:      secrets,
+		client:       &http.Client{Timeout: notifyTimeout},
+		pagerDutyURL: pagerDutyEventsURL,
+	}
+}
+
+// Notify sends ev to the notification endpoint endpointID.
+func (n *EndpointNotifier) Notify(ctx context.Context, endpointID influxdb.ID, ev TaskNotificationEvent) error {
+	e, err := n.endpoints.FindNotificationEndpointByID(ctx, endpointID)
+	if err != nil {
+		return err
+	}
+	if e.GetStatus() != influxdb.Active {
+		return nil
+	}
+
+	switch e := e.(type) {
+	case *endpoint.HTTP:
+		return n.notifyHTTP(ctx, e, ev)
+	case *endpoint.Slack:
+		return n.notifySlack(ctx, e, ev)
+	case *endpoint.PagerDuty:
+		return n.notifyPagerDuty(ctx, e, ev)
+	}
+	return fmt.Errorf("notification endpoint %s of type %q cannot notify tasks", endpointID, e.Type())
+}
+
+func (n *EndpointNotifier) notifyHTTP(ctx context.Context, e *endpoint.HTTP, ev TaskNotificationEvent) error {
+	status := "failed"
+	if ev.Recovered {
+		status = "recovered"
+	}
+	body := map[string]interface{}{
+		"taskID":              ev.Task.ID,
+		"taskName":            ev.Task.Name,
+		"orgID":               ev.Task.OrganizationID,
+		"runID":               ev.RunID,
+		"scheduledFor":        ev.ScheduledFor,
+		"status":              status,
+		"consecutiveFailures": ev.Failures,
+		"message":             ev.Message(),
+	}
+	if ev.Err != nil {
+		body["error"] = ev.Err.Error()
+	}
+
+	method := e.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := newJSONRequest(ctx, method, e.URL, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range e.Headers {
+		req.Header.Set(k, v)
+	}
+
+	switch e.AuthMethod {
+	case "basic":
+		username, err := n.loadSecret(ctx, e.GetOrgID(), e.Username)
+		if err != nil {
+			return err
+		}
+		password, err := n.loadSecret(ctx, e.GetOrgID(), e.Password)
+		if err != nil {
+			return err
+		}
+		req.SetBasicAuth(username, password)
+	case "bearer":
+		token, err := n.loadSecret(ctx, e.GetOrgID(), e.Token)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return n.do(req)
+}
+
+func (n *EndpointNotifier) notifySlack(ctx context.Context, e *endpoint.Slack, ev TaskNotificationEvent) error {
+	color := "danger"
+	if ev.Recovered {
+		color = "good"
+	}
+	req, err := newJSONRequest(ctx, http.MethodPost, e.URL, map[string]interface{}{
+		"attachments": []map[string]interface{}{{
+			"color":     color,
+			"text":      ev.Message(),
+			"mrkdwn_in": []string{"text"},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	if e.Token.Key != "" {
+		token, err := n.loadSecret(ctx, e.GetOrgID(), e.Token)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return n.do(req)
+}
+
+func (n *EndpointNotifier) notifyPagerDuty(ctx context.Context, e *endpoint.PagerDuty, ev TaskNotificationEvent) error {
+	routingKey, err := n.loadSecret(ctx, e.GetOrgID(), e.RoutingKey)
+	if err != nil {
+		return err
+	}
+	action := "trigger"
+	if ev.Recovered {
+		action = "resolve"
+	}
+	req, err := newJSONRequest(ctx, http.MethodPost, n.pagerDutyURL, map[string]interface{}{
+		"routing_key":  routingKey,
+		"event_action": action,
+		"dedup_key":    ev.Task.ID.String(),
+		"client":       "influxdata",
+		"client_url":   e.ClientURL,
+		"payload": map[string]interface{}{
+			"summary":   ev.Message(),
+			"source":    ev.Task.Name,
+			"severity":  "error",
+			"timestamp": ev.ScheduledFor.Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return n.do(req)
+}
+
+// loadSecret returns the value of the secret field of an endpoint of orgID.
+func (n *EndpointNotifier) loadSecret(ctx context.Context, orgID influxdb.ID, f influxdb.SecretField) (string, error) {
+	if f.Value != nil {
+		return *f.Value, nil
+	}
+	return n.secrets.LoadSecret(ctx, orgID, f.Key)
+}
+
+func (n *EndpointNotifier) do(req *http.Request) error {
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("notification endpoint responded with status %s", resp.Status)
+	}
+	return nil
+}
+
+func newJSONRequest(ctx context.Context, method, url string, body interface{}) (*http.Request, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req.WithContext(ctx), nil
+}
+
+// notify counts the consecutive failed runs of the task of p, once the run of
+// p is final, and notifies the notification endpoint of the task of the run
+// that reaches the failure threshold of the notification, and of the recovery
+// that follows it. Canceled runs are neither counted nor notified. The
+// notification is sent in the background, so that a slow endpoint does not
+// hold up the worker.
+func (w *worker) notify(ctx context.Context, p *promise, rs influxdb.RunStatus, runErr error) {
+	switch {
+	case rs == influxdb.RunSuccess:
+	case rs == influxdb.RunFail && p.ctx.Err() == nil:
+	default:
+		return
+	}
+
+	failures, err := w.e.tcs.CountRunResult(ctx, p.task.ID, rs)
+	if err != nil {
+		w.e.log.Error("Failed to count the failures of task", zap.String("taskID", p.task.ID.String()), zap.Error(err))
+		return
+	}
+
+	if w.e.notifier == nil || failures == 0 {
+		return
+	}
+	t, err := w.e.ts.FindTaskByID(ctx, p.task.ID)
+	if err != nil {
+		w.e.log.Error("Failed to find task to notify its failures", zap.String("taskID", p.task.ID.String()), zap.Error(err))
+		return
+	}
+	if t.Notification == nil {
+		return
+	}
+	switch rs {
+	case influxdb.RunFail:
+		if failures != t.Notification.Threshold() {
+			return
+		}
+	case influxdb.RunSuccess:
+		if !t.Notification.Recovery || failures < t.Notification.Threshold() {
+			return
+		}
+	}
+
+	ev := TaskNotificationEvent{
+		Task:         t,
+		RunID:        p.run.ID,
+		ScheduledFor: p.run.ScheduledFor,
+		Recovered:    rs == influxdb.RunSuccess,
+		Failures:     failures,
+		Err:          runErr,
+	}
+	nctx := icontext.SetAuthorizer(w.e.ctx, p.task.Authorization)
+	w.e.notifying.Add(1)
+	go func() {
+		defer w.e.notifying.Done()
+
+		nctx, cancel := context.WithTimeout(nctx, notifyTimeout)
+		defer cancel()
+		if err := w.e.notifier.Notify(nctx, t.Notification.EndpointID, ev); err != nil {
+			w.e.log.Info("Failed to notify task failure", zap.String("taskID", t.ID.String()), zap.String("endpointID", t.Notification.EndpointID.String()), zap.Error(err))
+		}
+	}()
+}
@@ -0,0 +1,128 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/notification/endpoint"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+)
+
+func TestExecutor_Notify(t *testing.T) {
+	notified := make(chan map[string]interface{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		notified <- body
+	}))
+	defer server.Close()
+
+	tes := taskExecutorSystem(t)
+	tes.ex.notifier = NewEndpointNotifier(tes.i, tes.i)
+
+	ctx := icontext.SetAuthorizer(context.Background(), tes.tc.Auth)
+	e := &endpoint.HTTP{
+		Base: endpoint.Base{
+			Name:   "hook",
+			OrgID:  idPtr(tes.tc.OrgID),
+			Status: influxdb.Active,
+		},
+		URL:        server.URL,
+		Method:     http.MethodPost,
+		AuthMethod: "none",
+	}
+	if err := tes.i.CreateNotificationEndpoint(ctx, e, tes.tc.Auth.GetUserID()); err != nil {
+		t.Fatal(err)
+	}
+
+	script := fmt.Sprintf(fmtTestScript, t.Name())
+	task, err := tes.i.CreateTask(ctx, influxdb.TaskCreate{
+		OrganizationID: tes.tc.OrgID,
+		OwnerID:        tes.tc.Auth.GetUserID(),
+		Flux:           script,
+		Notification:   &influxdb.TaskNotification{EndpointID: e.GetID(), Failures: 2, Recovery: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the fake query service only knows of successful queries executed for 123s
+	execute := func(fail bool) {
+		t.Helper()
+		if fail {
+			tes.svc.FailNextQuery(&scheduler.ErrUnrecoverable{})
+		}
+		promise, err := tes.ex.PromisedExecute(ctx, scheduler.ID(task.ID), time.Unix(123, 0), time.Unix(123, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !fail {
+			tes.svc.WaitForQueryLive(t, script)
+			tes.svc.SucceedQuery(script)
+		}
+		<-promise.Done()
+		tes.ex.notifying.Wait()
+	}
+	expectFailures := func(exp int) {
+		t.Helper()
+		found, err := tes.i.FindTaskByID(context.Background(), task.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.ConsecutiveFailures != exp {
+			t.Fatalf("expected %d consecutive failures, got %d", exp, found.ConsecutiveFailures)
+		}
+	}
+	expectNotified := func(status string, failures float64) {
+		t.Helper()
+		select {
+		case body := <-notified:
+			if body["status"] != status || body["consecutiveFailures"] != failures || body["taskID"] != task.ID.String() {
+				t.Fatalf("unexpected notification %v", body)
+			}
+		default:
+			t.Fatalf("expected a %s notification", status)
+		}
+	}
+
+	// the first failure is below the threshold of the notification
+	execute(true)
+	expectFailures(1)
+	if len(notified) != 0 {
+		t.Fatalf("expected no notification below the threshold, got %v", <-notified)
+	}
+
+	execute(true)
+	expectFailures(2)
+	expectNotified("failed", 2)
+
+	// the failure is notified once, when the threshold is reached
+	execute(true)
+	expectFailures(3)
+	if len(notified) != 0 {
+		t.Fatalf("expected no notification past the threshold, got %v", <-notified)
+	}
+
+	execute(false)
+	expectFailures(0)
+	expectNotified("recovered", 3)
+
+	// a success that does not follow failures is not notified
+	execute(false)
+	if len(notified) != 0 {
+		t.Fatalf("expected no notification, got %v", <-notified)
+	}
+}
+
+func idPtr(id influxdb.ID) *influxdb.ID {
+	return &id
+}
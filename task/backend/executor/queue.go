@@ -0,0 +1,135 @@
+package executor
+
+import (
+	"sync"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// runQueue is a queue of promises that is fair between organizations: the
+// organizations with queued promises take turns, so that the promises of one
+// organization do not hold back the promises of the others. The promises it
+// hands out count as running for their organization until they are done.
+//
+// The queue holds a fixed number of promises, pushing to a full queue blocks
+// until a promise is popped. Canceled promises are popped even when their
+// organization is at its limit, so that they do not hold on to the queue.
+type runQueue struct {
+	slots chan struct{}
+
+	// limits hold the maximum number of running promises of each
+	// organization; zero does not limit the promises.
+	limits OrgLimits
+
+	mu      sync.Mutex
+	queued  map[influxdb.ID][]*promise
+	running map[influxdb.ID]int
+	// orgs are the organizations with queued promises, in the order of their turns.
+	orgs []influxdb.ID
+}
+
+func newRunQueue(size int, limits OrgLimits) *runQueue {
+	return &runQueue{
+		slots:   make(chan struct{}, size),
+		limits:  limits,
+		queued:  make(map[influxdb.ID][]*promise),
+		running: make(map[influxdb.ID]int),
+	}
+}
+
+// push queues p behind the queued promises of its organization. It blocks
+// while the queue is full.
+func (q *runQueue) push(p *promise) {
+	q.slots <- struct{}{}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	org := p.task.OrganizationID
+	if len(q.queued[org]) == 0 {
+		q.orgs = append(q.orgs, org)
+	}
+	q.queued[org] = append(q.queued[org], p)
+}
+
+// pop removes the oldest promise of the first organization in turn that is
+// below its concurrency, and puts the organization at the back of the turns.
+// A canceled promise of an organization at its concurrency is removed in its
+// place, without changing the turns. It reports false when no queued promise
+// may run.
+func (q *runQueue) pop() (*promise, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, org := range q.orgs {
+		ps := q.queued[org]
+		j := 0
+		if concurrency := q.limits.of(org).Concurrency; concurrency > 0 && q.running[org] >= concurrency {
+			for j < len(ps) && ps[j].ctx.Err() == nil {
+				j++
+			}
+			if j == len(ps) {
+				continue
+			}
+		}
+
+		p := ps[j]
+		copy(ps[j:], ps[j+1:])
+		ps[len(ps)-1] = nil
+		ps = ps[:len(ps)-1]
+		if len(ps) == 0 {
+			delete(q.queued, org)
+		} else {
+			q.queued[org] = ps
+		}
+		if len(ps) == 0 || j == 0 {
+			copy(q.orgs[i:], q.orgs[i+1:])
+			q.orgs = q.orgs[:len(q.orgs)-1]
+			if len(ps) > 0 {
+				q.orgs = append(q.orgs, org)
+			}
+		}
+		q.running[org]++
+
+		<-q.slots
+		return p, true
+	}
+	return nil, false
+}
+
+// done stops counting p, which was popped from the queue, as running.
+func (q *runQueue) done(p *promise) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	org := p.task.OrganizationID
+	if q.running[org]--; q.running[org] <= 0 {
+		delete(q.running, org)
+	}
+}
+
+// len returns the number of queued promises.
+func (q *runQueue) len() int {
+	return len(q.slots)
+}
+
+// cap returns the number of promises the queue holds.
+func (q *runQueue) cap() int {
+	return cap(q.slots)
+}
+
+// orgStats returns the number of queued and running promises by organization.
+func (q *runQueue) orgStats() (queued, running map[influxdb.ID]int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queued = make(map[influxdb.ID]int, len(q.queued))
+	for org, ps := range q.queued {
+		queued[org] = len(ps)
+	}
+	running = make(map[influxdb.ID]int, len(q.running))
+	for org, n := range q.running {
+		running[org] = n
+	}
+	return queued, running
+}
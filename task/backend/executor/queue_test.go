@@ -0,0 +1,142 @@
+package executor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+func TestRunQueue(t *testing.T) {
+	const (
+		orgA = influxdb.ID(1)
+		orgB = influxdb.ID(2)
+	)
+	newOrgPromise := func(orgID influxdb.ID, runID influxdb.ID) *promise {
+		return newPromise(context.Background(), &influxdb.Task{OrganizationID: orgID}, &influxdb.Run{ID: runID})
+	}
+	expectPop := func(t *testing.T, q *runQueue, runID influxdb.ID) *promise {
+		t.Helper()
+		p, ok := q.pop()
+		if !ok {
+			t.Fatalf("expected run %s to be popped, got none", runID)
+		}
+		if p.run.ID != runID {
+			t.Fatalf("expected run %s to be popped, got %s", runID, p.run.ID)
+		}
+		return p
+	}
+
+	t.Run("orgs take turns", func(t *testing.T) {
+		q := newRunQueue(10, OrgLimits{})
+		q.push(newOrgPromise(orgA, 1))
+		q.push(newOrgPromise(orgA, 2))
+		q.push(newOrgPromise(orgA, 3))
+		q.push(newOrgPromise(orgB, 4))
+		q.push(newOrgPromise(orgB, 5))
+
+		for _, id := range []influxdb.ID{1, 4, 2, 5, 3} {
+			expectPop(t, q, id)
+		}
+		if p, ok := q.pop(); ok {
+			t.Fatalf("expected an empty queue, got run %s", p.run.ID)
+		}
+		if q.len() != 0 {
+			t.Fatalf("expected an empty queue, got %d queued", q.len())
+		}
+	})
+
+	t.Run("org concurrency", func(t *testing.T) {
+		q := newRunQueue(10, OrgLimits{Concurrency: 1})
+		q.push(newOrgPromise(orgA, 1))
+		q.push(newOrgPromise(orgA, 2))
+		q.push(newOrgPromise(orgB, 3))
+
+		a := expectPop(t, q, 1)
+		expectPop(t, q, 3)
+		if p, ok := q.pop(); ok {
+			t.Fatalf("expected the runs of orgs at their limit to be held, got run %s", p.run.ID)
+		}
+
+		queued, running := q.orgStats()
+		if queued[orgA] != 1 || queued[orgB] != 0 {
+			t.Fatalf("unexpected queued runs by org %v", queued)
+		}
+		if running[orgA] != 1 || running[orgB] != 1 {
+			t.Fatalf("unexpected running runs by org %v", running)
+		}
+
+		q.done(a)
+		expectPop(t, q, 2)
+	})
+
+	t.Run("org overrides", func(t *testing.T) {
+		q := newRunQueue(10, OrgLimits{Concurrency: 1, Overrides: map[influxdb.ID]OrgLimits{orgB: {Concurrency: 2}}})
+		q.push(newOrgPromise(orgA, 1))
+		q.push(newOrgPromise(orgA, 2))
+		q.push(newOrgPromise(orgB, 3))
+		q.push(newOrgPromise(orgB, 4))
+
+		expectPop(t, q, 1)
+		expectPop(t, q, 3)
+		expectPop(t, q, 4)
+		if p, ok := q.pop(); ok {
+			t.Fatalf("expected the runs of orgs at their limit to be held, got run %s", p.run.ID)
+		}
+	})
+
+	t.Run("canceled runs of orgs at their limit", func(t *testing.T) {
+		q := newRunQueue(10, OrgLimits{Concurrency: 1})
+		q.push(newOrgPromise(orgA, 1))
+		q.push(newOrgPromise(orgA, 2))
+		canceled := newOrgPromise(orgA, 3)
+		q.push(canceled)
+
+		expectPop(t, q, 1)
+		if p, ok := q.pop(); ok {
+			t.Fatalf("expected the runs of orgs at their limit to be held, got run %s", p.run.ID)
+		}
+
+		// the canceled run leaves the queue, ahead of the runs it was queued behind
+		canceled.cancelFunc()
+		expectPop(t, q, 3)
+		if q.len() != 1 {
+			t.Fatalf("expected a single queued run, got %d", q.len())
+		}
+		queued, _ := q.orgStats()
+		if queued[orgA] != 1 {
+			t.Fatalf("unexpected queued runs by org %v", queued)
+		}
+	})
+}
+
+func TestParseOrgLimits(t *testing.T) {
+	for _, tt := range []struct {
+		s      string
+		orgID  influxdb.ID
+		limits OrgLimits
+		err    bool
+	}{
+		{s: "0000000000000001=2", orgID: 1, limits: OrgLimits{Concurrency: 2}},
+		{s: "0000000000000001=0:1024", orgID: 1, limits: OrgLimits{MemoryBytesQuota: 1024}},
+		{s: "0000000000000001", err: true},
+		{s: "org=2", err: true},
+		{s: "0000000000000001=-1", err: true},
+		{s: "0000000000000001=2:many", err: true},
+	} {
+		orgID, limits, err := ParseOrgLimits(tt.s)
+		if tt.err {
+			if err == nil {
+				t.Errorf("expected an error parsing %q", tt.s)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %v", tt.s, err)
+			continue
+		}
+		if orgID != tt.orgID || limits.Concurrency != tt.limits.Concurrency || limits.MemoryBytesQuota != tt.limits.MemoryBytesQuota {
+			t.Errorf("unexpected limits %s %+v parsing %q", orgID, limits, tt.s)
+		}
+	}
+}
@@ -295,3 +295,12 @@ option task = {
 			every: 1m,
 }
 from(bucket: "one") |> to(bucket: "two", orgID: "0000000000000000")`
+
+// fmtTestRetryScript is fmtTestScript with two attempts allowed per run.
+const fmtTestRetryScript = `
+option task = {
+			name: %q,
+			every: 1m,
+			retry: 2,
+}
+from(bucket: "one") |> to(bucket: "two", orgID: "0000000000000000")`
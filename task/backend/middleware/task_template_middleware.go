@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// CoordinatingTaskTemplateService acts as a TaskTemplateService decorator that
+// publishes the changes to the tasks rendered again from an updated template.
+type CoordinatingTaskTemplateService struct {
+	influxdb.TaskTemplateService
+	coordinator Coordinator
+	taskService influxdb.TaskService
+}
+
+// NewTaskTemplateService constructs a new coordinating task template service.
+func NewTaskTemplateService(tts influxdb.TaskTemplateService, ts influxdb.TaskService, coordinator Coordinator) *CoordinatingTaskTemplateService {
+	return &CoordinatingTaskTemplateService{
+		TaskTemplateService: tts,
+		coordinator:         coordinator,
+		taskService:         ts,
+	}
+}
+
+// UpdateTaskTemplate updates a task template and publishes the changes to the
+// tasks instantiated from it so the task owners can act on them.
+func (s *CoordinatingTaskTemplateService) UpdateTaskTemplate(ctx context.Context, id influxdb.ID, upd influxdb.TaskTemplateUpdate) (*influxdb.TaskTemplate, error) {
+	t, err := s.TaskTemplateService.FindTaskTemplateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.templateTasks(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err = s.TaskTemplateService.UpdateTaskTemplate(ctx, id, upd)
+	if err != nil {
+		return t, err
+	}
+
+	for _, task := range from {
+		to, err := s.taskService.FindTaskByID(ctx, task.ID)
+		if err != nil {
+			return t, err
+		}
+		if err := s.coordinator.TaskUpdated(ctx, task, to); err != nil {
+			return t, err
+		}
+	}
+	return t, nil
+}
+
+// templateTasks returns all the tasks instantiated from a template.
+func (s *CoordinatingTaskTemplateService) templateTasks(ctx context.Context, t *influxdb.TaskTemplate) ([]*influxdb.Task, error) {
+	filter := influxdb.TaskFilter{
+		OrganizationID: &t.OrganizationID,
+		TemplateID:     &t.ID,
+		Limit:          influxdb.TaskMaxPageSize,
+	}
+
+	var tasks []*influxdb.Task
+	for {
+		ts, _, err := s.taskService.FindTasks(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, ts...)
+		if len(ts) < filter.Limit {
+			return tasks, nil
+		}
+		filter.After = &ts[len(ts)-1].ID
+	}
+}
@@ -0,0 +1,50 @@
+package middleware_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/task/backend/middleware"
+)
+
+func TestTaskTemplateUpdate(t *testing.T) {
+	mocks := newMockServices()
+	ttService := mock.NewTaskTemplateService()
+	ttService.FindTaskTemplateByIDF = func(_ context.Context, id influxdb.ID) (*influxdb.TaskTemplate, error) {
+		return &influxdb.TaskTemplate{ID: id, OrganizationID: 1}, nil
+	}
+	ttService.UpdateTaskTemplateF = func(_ context.Context, id influxdb.ID, _ influxdb.TaskTemplateUpdate) (*influxdb.TaskTemplate, error) {
+		return &influxdb.TaskTemplate{ID: id, OrganizationID: 1}, nil
+	}
+
+	var filter influxdb.TaskFilter
+	mocks.taskSvc.FindTasksFn = func(_ context.Context, f influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+		filter = f
+		return []*influxdb.Task{{ID: 4, TemplateID: 2}}, 1, nil
+	}
+	mocks.taskSvc.FindTaskByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Task, error) {
+		return &influxdb.Task{ID: id, TemplateID: 2, Flux: "rendered"}, nil
+	}
+
+	ttsvc := middleware.NewTaskTemplateService(ttService, mocks.taskSvc, mocks.pipingCoordinator)
+	ch := mocks.pipingCoordinator.taskUpdatedChan()
+
+	if _, err := ttsvc.UpdateTaskTemplate(context.Background(), 2, influxdb.TaskTemplateUpdate{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if filter.TemplateID == nil || *filter.TemplateID != 2 || filter.OrganizationID == nil || *filter.OrganizationID != 1 {
+		t.Fatalf("unexpected task filter %+v", filter)
+	}
+
+	select {
+	case task := <-ch:
+		if task.ID != 4 || task.Flux != "rendered" {
+			t.Fatalf("task sent to coordinator doesn't match expected")
+		}
+	default:
+		t.Fatal("didn't receive task")
+	}
+}
@@ -0,0 +1,211 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/predicate"
+	"github.com/influxdata/influxdb/v2/query"
+	"go.uber.org/zap"
+)
+
+// DefaultRunPruneInterval is how often the runs of all tasks are pruned.
+const DefaultRunPruneInterval = time.Hour
+
+// RunRetention bounds the history of runs kept for every task that does not
+// set its own.
+type RunRetention struct {
+	// MaxAge is how long runs are kept. Zero keeps completed runs for as long as
+	// the system bucket does, and runs that were never finished forever.
+	MaxAge time.Duration
+
+	// MaxCount is how many of the most recent completed runs are kept. Zero keeps all of them.
+	MaxCount int
+}
+
+// RunPruneService deletes the records of runs that were never finished.
+type RunPruneService interface {
+	// PruneRuns deletes the records of the runs of a task that were last active
+	// before the given time, and returns how many it deleted.
+	PruneRuns(ctx context.Context, taskID influxdb.ID, before time.Time) (int, error)
+}
+
+// RunPruner enforces a RunRetention on the runs of all tasks, both on the
+// records of runs in the task store and on the completed runs recorded in the
+// system bucket of their org. The run retention of a task overrides it.
+type RunPruner struct {
+	log       *zap.Logger
+	ts        TaskService
+	bs        influxdb.BucketService
+	rs        RunPruneService
+	ds        influxdb.DeleteService
+	qs        query.QueryService
+	retention RunRetention
+}
+
+// NewRunPruner creates a RunPruner that enforces retention.
+func NewRunPruner(log *zap.Logger, ts TaskService, bs influxdb.BucketService, rs RunPruneService, ds influxdb.DeleteService, qs query.QueryService, retention RunRetention) *RunPruner {
+	return &RunPruner{
+		log:       log,
+		ts:        ts,
+		bs:        bs,
+		rs:        rs,
+		ds:        ds,
+		qs:        qs,
+		retention: retention,
+	}
+}
+
+// Run prunes the runs of all tasks every interval, and returns when ctx is done.
+func (p *RunPruner) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := p.Prune(ctx); err != nil {
+			p.log.Error("Failed to prune task runs", zap.Error(err))
+		}
+	}
+}
+
+// Prune prunes the runs of all tasks.
+func (p *RunPruner) Prune(ctx context.Context) error {
+	tasks, err := findAllTasks(ctx, p.ts)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if err := p.PruneTask(ctx, task); err != nil {
+			p.log.Error("Failed to prune task runs", zap.String("taskID", task.ID.String()), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// retentionOf returns the retention of the runs of a task.
+func (p *RunPruner) retentionOf(task *influxdb.Task) RunRetention {
+	retention := p.retention
+	if task.RunRetention == nil {
+		return retention
+	}
+	if task.RunRetention.MaxAge.Duration > 0 {
+		retention.MaxAge = task.RunRetention.MaxAge.Duration
+	}
+	if task.RunRetention.MaxCount > 0 {
+		retention.MaxCount = task.RunRetention.MaxCount
+	}
+	return retention
+}
+
+// PruneTask prunes the runs of a task.
+func (p *RunPruner) PruneTask(ctx context.Context, task *influxdb.Task) error {
+	retention := p.retentionOf(task)
+	if retention.MaxAge <= 0 && retention.MaxCount <= 0 {
+		return nil
+	}
+
+	// max is the time of the latest completed run to delete
+	max := int64(math.MinInt64)
+	if retention.MaxAge > 0 {
+		before := now().Add(-retention.MaxAge)
+		n, err := p.rs.PruneRuns(ctx, task.ID, before)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			p.log.Info("Pruned unfinished task runs", zap.String("taskID", task.ID.String()), zap.Int("runs", n))
+		}
+		max = before.UnixNano() - 1
+	}
+
+	sb, err := p.bs.FindBucketByName(ctx, task.OrganizationID, influxdb.TasksSystemBucketName)
+	if err != nil {
+		return err
+	}
+
+	if retention.MaxCount > 0 {
+		t, ok, err := p.oldestRunToPrune(ctx, task, sb.ID, retention.MaxCount)
+		if err != nil {
+			return err
+		}
+		if ok && t > max {
+			max = t
+		}
+	}
+	if max == math.MinInt64 {
+		return nil
+	}
+
+	pred, err := predicate.New(predicate.LogicalNode{
+		Operator: predicate.LogicalAnd,
+		Children: [2]predicate.Node{
+			predicate.TagRuleNode{Tag: influxdb.Tag{Key: "_measurement", Value: "runs"}, Operator: influxdb.Equal},
+			predicate.TagRuleNode{Tag: influxdb.Tag{Key: taskIDTag, Value: task.ID.String()}, Operator: influxdb.Equal},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return p.ds.DeleteBucketRangePredicate(ctx, task.OrganizationID, sb.ID, math.MinInt64, max, pred)
+}
+
+// oldestRunToPrune returns the time of the most recent completed run of a task
+// that is past the maximum count of runs to keep, if there is one.
+func (p *RunPruner) oldestRunToPrune(ctx context.Context, task *influxdb.Task, bucketID influxdb.ID, maxCount int) (int64, bool, error) {
+	script := fmt.Sprintf(`from(bucketID: %q)
+	  |> range(start: 0)
+	  |> filter(fn: (r) => r._measurement == "runs" and r.taskID == %q and r._field == %q)
+	  |> group()
+	  |> sort(columns: ["_time"], desc: true)
+	  |> limit(n: 1, offset: %d)
+	  `, bucketID.String(), task.ID.String(), runIDField, maxCount)
+
+	// At this point we are behind authorization
+	// so we are faking a read only permission to the org's system bucket
+	auth := systemBucketAuth(task.OrganizationID, bucketID)
+	request := &query.Request{Authorization: auth, OrganizationID: task.OrganizationID, Compiler: lang.FluxCompiler{Query: script}}
+
+	// storage restricts the reads to the permissions of the authorizer on the context
+	ittr, err := p.qs.Query(icontext.SetAuthorizer(ctx, auth), request)
+	if err != nil {
+		return 0, false, err
+	}
+	defer ittr.Release()
+
+	var (
+		t     int64
+		found bool
+	)
+	for ittr.More() {
+		err := ittr.Next().Tables().Do(func(tbl flux.Table) error {
+			return tbl.Do(func(cr flux.ColReader) error {
+				j := execute.ColIdx("_time", cr.Cols())
+				if j < 0 || cr.Len() == 0 || !cr.Times(j).IsValid(0) {
+					return nil
+				}
+				t, found = cr.Times(j).Value(0), true
+				return nil
+			})
+		})
+		if err != nil {
+			return 0, false, err
+		}
+	}
+	if err := ittr.Err(); err != nil {
+		return 0, false, fmt.Errorf("unexpected internal error while decoding run response: %v", err)
+	}
+	return t, found, nil
+}
@@ -0,0 +1,143 @@
+package backend_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/inmem"
+	"github.com/influxdata/influxdb/v2/kv"
+	"github.com/influxdata/influxdb/v2/mock"
+	"github.com/influxdata/influxdb/v2/task/backend"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRunPruner(t *testing.T) {
+	ctx := context.Background()
+	svc := kv.NewService(zaptest.NewLogger(t), inmem.NewKVStore())
+	if err := svc.Initialize(ctx); err != nil {
+		t.Fatalf("error initializing kv service: %v", err)
+	}
+
+	org := &influxdb.Organization{Name: "org"}
+	if err := svc.CreateOrganization(ctx, org); err != nil {
+		t.Fatal(err)
+	}
+	sb, err := svc.FindBucketByName(ctx, org.ID, influxdb.TasksSystemBucketName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ab := newAnalyticalBackend(t, svc, svc)
+	defer ab.Close(t)
+
+	task := &influxdb.Task{ID: 1, OrganizationID: org.ID, Organization: org.Name}
+	mockTS := &mock.TaskService{
+		FindTaskByIDFn: func(context.Context, influxdb.ID) (*influxdb.Task, error) {
+			return task, nil
+		},
+		FindTasksFn: func(_ context.Context, filter influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+			if filter.After != nil {
+				return nil, 0, nil
+			}
+			return []*influxdb.Task{task}, 1, nil
+		},
+		FindRunsFn: func(context.Context, influxdb.RunFilter) ([]*influxdb.Run, int, error) {
+			return nil, 0, nil
+		},
+	}
+	mockRS := &runPruneService{}
+
+	var (
+		logger = zaptest.NewLogger(t)
+		rr     = backend.NewStoragePointsWriterRecorder(logger, ab.PointsWriter())
+		as     = backend.NewAnalyticalRunStorage(logger, mockTS, svc, nil, rr, ab.QueryService())
+		now    = time.Now().UTC().Truncate(time.Second)
+	)
+
+	// record a completed run every hour for the last four hours
+	for i := 4; i > 0; i-- {
+		at := now.Add(-time.Duration(i) * time.Hour)
+		run := &influxdb.Run{
+			ID:           influxdb.ID(10 - i),
+			TaskID:       task.ID,
+			Status:       influxdb.RunSuccess.String(),
+			ScheduledFor: at,
+			StartedAt:    at,
+			FinishedAt:   at.Add(time.Second),
+		}
+		if err := rr.Record(ctx, org.ID, org.Name, sb.ID, sb.Name, run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expectRuns := func(exp ...influxdb.ID) {
+		t.Helper()
+
+		runs, _, err := as.FindRuns(ctx, influxdb.RunFilter{Task: task.ID, Cursor: &influxdb.RunCursor{}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []influxdb.ID
+		for _, r := range runs {
+			got = append(got, r.ID)
+		}
+		if len(got) != len(exp) {
+			t.Fatalf("expected runs %v, got %v", exp, got)
+		}
+		for i := range exp {
+			if got[i] != exp[i] {
+				t.Fatalf("expected runs %v, got %v", exp, got)
+			}
+		}
+	}
+	expectRuns(9, 8, 7, 6)
+
+	// keep the three most recent runs
+	pruner := backend.NewRunPruner(logger, mockTS, svc, mockRS, ab.storageEngine, ab.QueryService(), backend.RunRetention{MaxCount: 3})
+	if err := pruner.Prune(ctx); err != nil {
+		t.Fatal(err)
+	}
+	expectRuns(9, 8, 7)
+	if mockRS.calls != 0 {
+		t.Fatalf("expected unfinished runs not to be pruned without a max age, got %d calls", mockRS.calls)
+	}
+
+	// keep the runs of the last two and a half hours
+	pruner = backend.NewRunPruner(logger, mockTS, svc, mockRS, ab.storageEngine, ab.QueryService(), backend.RunRetention{MaxAge: 150 * time.Minute, MaxCount: 3})
+	if err := pruner.Prune(ctx); err != nil {
+		t.Fatal(err)
+	}
+	expectRuns(9, 8)
+	if mockRS.calls != 1 || mockRS.taskID != task.ID {
+		t.Fatalf("expected the unfinished runs of the task to be pruned, got %d calls for %s", mockRS.calls, mockRS.taskID)
+	}
+	if exp := now.Add(-150 * time.Minute); mockRS.before.Before(exp) || mockRS.before.After(exp.Add(time.Minute)) {
+		t.Fatalf("unexpected time to prune unfinished runs before %s", mockRS.before)
+	}
+
+	// the retention of the task overrides the one of all tasks
+	task.RunRetention = &influxdb.TaskRunRetention{MaxCount: 1}
+	pruner = backend.NewRunPruner(logger, mockTS, svc, mockRS, ab.storageEngine, ab.QueryService(), backend.RunRetention{})
+	if err := pruner.Prune(ctx); err != nil {
+		t.Fatal(err)
+	}
+	expectRuns(9)
+	if mockRS.calls != 1 {
+		t.Fatalf("expected unfinished runs not to be pruned without a max age, got %d calls", mockRS.calls)
+	}
+}
+
+type runPruneService struct {
+	calls  int
+	taskID influxdb.ID
+	before time.Time
+}
+
+func (s *runPruneService) PruneRuns(_ context.Context, taskID influxdb.ID, before time.Time) (int, error) {
+	s.calls++
+	s.taskID = taskID
+	s.before = before
+	return 0, nil
+}
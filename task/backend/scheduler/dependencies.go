@@ -0,0 +1,210 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+)
+
+// maxUpstreamResults is the number of run outcomes kept for every Schedulable
+// that others depend on.
+const maxUpstreamResults = 100
+
+// ErrDependencyCycle is returned by Schedule when the upstream Schedulables of
+// a DependentSchedulable would make it depend on itself.
+var ErrDependencyCycle = errors.New("schedulable dependencies form a cycle")
+
+// runResult is the outcome of a run of a Schedulable. A run that was handed to
+// the executor but has not finished is not done.
+type runResult struct {
+	done bool
+	err  error
+}
+
+// setUpstream replaces the upstream Schedulables of id in the DAG.
+// It must be called with the lock held.
+func (s *TreeScheduler) setUpstream(id ID, upstream []ID) error {
+	for _, up := range upstream {
+		if up == id || s.dependsOn(up, id) {
+			return ErrDependencyCycle
+		}
+	}
+
+	for _, up := range s.upstream[id] {
+		delete(s.downstream[up], id)
+		if len(s.downstream[up]) == 0 {
+			delete(s.downstream, up)
+			delete(s.results, up)
+		}
+	}
+	delete(s.upstream, id)
+
+	if len(upstream) == 0 {
+		return nil
+	}
+	s.upstream[id] = append([]ID(nil), upstream...)
+	for _, up := range upstream {
+		if s.downstream[up] == nil {
+			s.downstream[up] = map[ID]struct{}{}
+		}
+		s.downstream[up][id] = struct{}{}
+	}
+	return nil
+}
+
+// dependsOn reports whether id depends on target, directly or transitively.
+// It must be called with the lock held.
+func (s *TreeScheduler) dependsOn(id, target ID) bool {
+	visited := map[ID]bool{}
+	stack := []ID{id}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if cur == target {
+			return true
+		}
+		if visited[cur] {
+			continue
+		}
+		visited[cur] = true
+		stack = append(stack, s.upstream[cur]...)
+	}
+	return false
+}
+
+// upstreamState reports whether the run of it must wait for runs of its
+// upstream Schedulables, or returns an ErrUpstreamFailed if it must be skipped.
+//
+// The run for a scheduled time depends on the latest run of each upstream
+// Schedulable that is scheduled for the same time or before it, so upstream
+// Schedulables may run on other schedules than it. The run waits while such an
+// upstream run has yet to be executed or is still running, and is skipped if it
+// failed. If the outcome of that upstream run is not known, because it ran
+// before the scheduler started or its outcome was pruned, the run goes ahead,
+// unless the upstream Schedulable is no longer scheduled.
+// It must be called with the lock held.
+func (s *TreeScheduler) upstreamState(it Item) (pending bool, err error) {
+	for _, up := range s.upstream[it.id] {
+		next, scheduled := s.nextScheduled(up)
+		if scheduled && next <= it.next {
+			// the latest upstream run for the time has yet to be executed
+			pending = true
+			continue
+		}
+
+		res, ok := s.latestResult(up, it.next)
+		switch {
+		case ok && !res.done:
+			pending = true
+		case ok && res.err == ErrLeaseHeld:
+			// the upstream runs on another scheduler, and so must this run
+			return false, ErrLeaseHeld
+		case ok && res.err != nil:
+			return false, &ErrUpstreamFailed{Upstream: up, Err: res.err}
+		case !ok && !scheduled:
+			return false, &ErrUpstreamFailed{Upstream: up}
+		}
+	}
+	return pending, nil
+}
+
+// latestResult returns the outcome of the latest run of id scheduled for the
+// time next or before it.
+// It must be called with the lock held.
+func (s *TreeScheduler) latestResult(id ID, next int64) (res runResult, ok bool) {
+	var latest int64
+	for t, r := range s.results[id] {
+		if t <= next && (!ok || t > latest) {
+			latest, res, ok = t, r, true
+		}
+	}
+	return res, ok
+}
+
+// nextScheduled returns the next time id is scheduled for.
+// It must be called with the lock held.
+func (s *TreeScheduler) nextScheduled(id ID) (int64, bool) {
+	if it, ok := s.blocked[id]; ok {
+		return it.next, true
+	}
+	when, ok := s.nextTime[id]
+	if !ok {
+		return 0, false
+	}
+	if i := s.priorityQueue.Get(Item{id: id, when: when}); i != nil {
+		return i.(Item).next, true
+	}
+	return 0, false
+}
+
+// recordResult records the outcome of the run of id for the scheduled time
+// next, if other Schedulables depend on id.
+// It must be called with the lock held.
+func (s *TreeScheduler) recordResult(id ID, next int64, res runResult) {
+	if len(s.downstream[id]) == 0 {
+		return
+	}
+
+	results := s.results[id]
+	if results == nil {
+		results = map[int64]runResult{}
+		s.results[id] = results
+	}
+	results[next] = res
+
+	if len(results) > maxUpstreamResults {
+		oldest := next
+		for t := range results {
+			if t < oldest {
+				oldest = t
+			}
+		}
+		delete(results, oldest)
+	}
+}
+
+// unblockDownstream puts the blocked runs that depend on id back into the
+// tree, once they no longer have to wait for their upstream runs.
+// It must be called with the lock held, and not while iterating the tree.
+func (s *TreeScheduler) unblockDownstream(id ID) {
+	for d := range s.downstream[id] {
+		it, ok := s.blocked[d]
+		if !ok {
+			continue
+		}
+		if pending, _ := s.upstreamState(it); pending {
+			continue
+		}
+		delete(s.blocked, d)
+		s.insert(it)
+	}
+}
+
+// insert puts it into the tree, and makes sure the timer fires when it is due.
+// It must be called with the lock held.
+func (s *TreeScheduler) insert(it Item) {
+	s.nextTime[it.id] = it.when
+	s.priorityQueue.ReplaceOrInsert(it)
+
+	if when := it.When(); s.when.IsZero() || s.when.After(when) {
+		s.when = when
+		s.timer.Stop()
+		until := s.when.Sub(s.time.Now())
+		if until <= 0 {
+			s.timer.Reset(0)
+		} else {
+			s.timer.Reset(until)
+		}
+	}
+}
+
+// RunFinished records the final outcome of the run of a Schedulable for a
+// scheduled time. Runs of Schedulables that depend on it, for that time or a
+// later one, are executed once all of their upstream runs have succeeded, and
+// skipped if any of them failed.
+func (s *TreeScheduler) RunFinished(id ID, scheduledFor time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.recordResult(id, scheduledFor.UTC().Unix(), runResult{done: true, err: err})
+	s.unblockDownstream(id)
+}
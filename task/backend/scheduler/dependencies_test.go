@@ -0,0 +1,231 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+)
+
+type mockDependentSchedulable struct {
+	mockSchedulable
+	upstream []ID
+}
+
+func (s mockDependentSchedulable) Upstream() []ID {
+	return s.upstream
+}
+
+type execution struct {
+	id           ID
+	scheduledFor time.Time
+}
+
+func advance(sch *TreeScheduler, mockTime *clock.Mock, d time.Duration) {
+	go func() {
+		sch.mu.Lock()
+		mockTime.Add(d)
+		sch.mu.Unlock()
+	}()
+}
+
+func expectExecution(t *testing.T, c chan execution, exp execution) {
+	t.Helper()
+
+	select {
+	case got := <-c:
+		if got.id != exp.id || !got.scheduledFor.Equal(exp.scheduledFor) {
+			t.Fatalf("expected execution of %d for %s, got %d for %s", exp.id, exp.scheduledFor, got.id, got.scheduledFor)
+		}
+	case <-time.After(6 * time.Second):
+		t.Fatalf("test timed out, expected execution of %d for %s", exp.id, exp.scheduledFor)
+	}
+}
+
+func expectNoExecution(t *testing.T, c chan execution) {
+	t.Helper()
+
+	select {
+	case got := <-c:
+		t.Fatalf("expected no execution, got %d for %s", got.id, got.scheduledFor)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+func expectUpstreamFailed(t *testing.T, errs chan error, upstream ID) {
+	t.Helper()
+
+	select {
+	case err := <-errs:
+		var uerr *ErrUpstreamFailed
+		if !errors.As(err, &uerr) || uerr.Upstream != upstream {
+			t.Fatalf("expected upstream failed error, got %v", err)
+		}
+	case <-time.After(6 * time.Second):
+		t.Fatal("test timed out, expected the skipped run to be reported")
+	}
+}
+
+// newDependencyScheduler returns a scheduler at 00:00:30 that sends the runs
+// it executes to c, and the errors it reports to errs.
+func newDependencyScheduler(t *testing.T) (sch *TreeScheduler, mockTime *clock.Mock, c chan execution, errs chan error) {
+	t.Helper()
+
+	c = make(chan execution, 100)
+	exe := &mockExecutor{fn: func(l *sync.Mutex, ctx context.Context, id ID, scheduledFor time.Time) {
+		c <- execution{id: id, scheduledFor: scheduledFor.UTC()}
+	}}
+	errs = make(chan error, 100)
+	mockTime = clock.NewMock()
+	mockTime.Set(time.Date(2020, 1, 1, 0, 0, 30, 0, time.UTC))
+	sch, _, err := NewScheduler(
+		exe,
+		&mockSchedulableService{},
+		WithTime(mockTime),
+		WithOnErrorFn(func(_ context.Context, _ ID, _ time.Time, err error) {
+			errs <- err
+		}),
+		WithMaxConcurrentWorkers(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sch, mockTime, c, errs
+}
+
+func TestTreeScheduler_Dependencies(t *testing.T) {
+	sch, mockTime, c, errs := newDependencyScheduler(t)
+	defer sch.Stop()
+
+	schedule, ts, err := NewSchedule("@every 1m", mockTime.Now().UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.Schedule(mockSchedulable{id: 1, schedule: schedule, lastScheduled: ts}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.Schedule(mockDependentSchedulable{
+		mockSchedulable: mockSchedulable{id: 2, schedule: schedule, lastScheduled: ts},
+		upstream:        []ID{1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the downstream task waits for the upstream run to succeed
+	first := time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC)
+	advance(sch, mockTime, time.Minute)
+	expectExecution(t, c, execution{id: 1, scheduledFor: first})
+	expectNoExecution(t, c)
+
+	sch.RunFinished(1, first, nil)
+	advance(sch, mockTime, time.Second)
+	expectExecution(t, c, execution{id: 2, scheduledFor: first})
+
+	// the downstream run is skipped when the upstream run fails
+	second := first.Add(time.Minute)
+	advance(sch, mockTime, time.Minute)
+	expectExecution(t, c, execution{id: 1, scheduledFor: second})
+
+	sch.RunFinished(1, second, errors.New("upstream failed"))
+	advance(sch, mockTime, time.Second)
+	expectNoExecution(t, c)
+
+	expectUpstreamFailed(t, errs, 1)
+
+	// tasks must not depend on themselves
+	if err := sch.Schedule(mockDependentSchedulable{
+		mockSchedulable: mockSchedulable{id: 1, schedule: schedule, lastScheduled: ts},
+		upstream:        []ID{2},
+	}); err != ErrDependencyCycle {
+		t.Fatalf("expected dependency cycle error, got %v", err)
+	}
+}
+
+func TestTreeScheduler_Dependencies_Schedules(t *testing.T) {
+	sch, mockTime, c, errs := newDependencyScheduler(t)
+	defer sch.Stop()
+
+	upSchedule, upTS, err := NewSchedule("@every 2m", mockTime.Now().UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedule, ts, err := NewSchedule("@every 1m", mockTime.Now().UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.Schedule(mockSchedulable{id: 1, schedule: upSchedule, lastScheduled: upTS}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.Schedule(mockDependentSchedulable{
+		mockSchedulable: mockSchedulable{id: 2, schedule: schedule, lastScheduled: ts},
+		upstream:        []ID{1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the upstream task has no known run before 00:01, so the downstream run goes ahead
+	advance(sch, mockTime, 30*time.Second)
+	expectExecution(t, c, execution{id: 2, scheduledFor: time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC)})
+
+	// at 00:02 the downstream run waits for the upstream run for the same time
+	second := time.Date(2020, 1, 1, 0, 2, 0, 0, time.UTC)
+	advance(sch, mockTime, time.Minute)
+	expectExecution(t, c, execution{id: 1, scheduledFor: second})
+	expectNoExecution(t, c)
+
+	sch.RunFinished(1, second, nil)
+	advance(sch, mockTime, time.Second)
+	expectExecution(t, c, execution{id: 2, scheduledFor: second})
+
+	// at 00:03 the downstream run depends on the upstream run for 00:02
+	advance(sch, mockTime, time.Minute-time.Second)
+	expectExecution(t, c, execution{id: 2, scheduledFor: second.Add(time.Minute)})
+
+	// at 00:04 the upstream run fails, so the downstream runs for 00:04 and 00:05 are skipped
+	fourth := second.Add(2 * time.Minute)
+	advance(sch, mockTime, time.Minute)
+	expectExecution(t, c, execution{id: 1, scheduledFor: fourth})
+
+	sch.RunFinished(1, fourth, errors.New("upstream failed"))
+	advance(sch, mockTime, time.Second)
+	expectNoExecution(t, c)
+	expectUpstreamFailed(t, errs, 1)
+
+	advance(sch, mockTime, time.Minute-time.Second)
+	expectNoExecution(t, c)
+	expectUpstreamFailed(t, errs, 1)
+}
+
+func TestTreeScheduler_Dependencies_LateUpstream(t *testing.T) {
+	sch, mockTime, c, _ := newDependencyScheduler(t)
+	defer sch.Stop()
+
+	schedule, ts, err := NewSchedule("@every 1m", mockTime.Now().UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.Schedule(mockSchedulable{id: 1, schedule: schedule, offset: 30 * time.Second, lastScheduled: ts}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sch.Schedule(mockDependentSchedulable{
+		mockSchedulable: mockSchedulable{id: 2, schedule: schedule, lastScheduled: ts},
+		upstream:        []ID{1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the downstream run waits for the upstream run, which is executed 30s later
+	first := time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC)
+	advance(sch, mockTime, 30*time.Second)
+	expectNoExecution(t, c)
+
+	advance(sch, mockTime, 30*time.Second)
+	expectExecution(t, c, execution{id: 1, scheduledFor: first})
+	expectNoExecution(t, c)
+
+	sch.RunFinished(1, first, nil)
+	advance(sch, mockTime, time.Second)
+	expectExecution(t, c, execution{id: 2, scheduledFor: first})
+}
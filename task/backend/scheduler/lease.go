@@ -0,0 +1,167 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrLeaseHeld is returned by a Leaser when another scheduler holds the lease on a Schedulable.
+var ErrLeaseHeld = errors.New("schedulable is leased by another scheduler")
+
+const (
+	// leaseAttempts is how many times a worker tries to acquire a lease, when
+	// the Leaser fails for another reason than ErrLeaseHeld.
+	leaseAttempts = 3
+	// leaseRetryBackoff is how long a worker waits before it first tries to
+	// acquire a lease again. It doubles with every attempt.
+	leaseRetryBackoff = 100 * time.Millisecond
+)
+
+// Leaser grants schedulers exclusive, expiring ownership of Schedulables, so
+// that several schedulers can share the same Schedulables without executing
+// them more than once.
+type Leaser interface {
+	// Lease acquires, or renews, the lease of the scheduler on id. It returns a
+	// context that fences the work done under the lease, or ErrLeaseHeld if
+	// another scheduler holds the lease.
+	Lease(ctx context.Context, id ID) (context.Context, error)
+
+	// Release gives up the lease of the scheduler on id.
+	Release(ctx context.Context, id ID) error
+}
+
+// WithLeaser is an option that makes a TreeScheduler execute a Schedulable
+// only while it holds its lease, and renew the leases it holds every renewEvery.
+// A nil Leaser leaves the TreeScheduler executing every Schedulable it is given.
+func WithLeaser(l Leaser, renewEvery time.Duration) treeSchedulerOptFunc {
+	return func(t *TreeScheduler) error {
+		if l == nil {
+			return nil
+		}
+		if renewEvery <= 0 {
+			return errors.New("lease renewal interval must be positive")
+		}
+		t.leaser = l
+		t.renewEvery = renewEvery
+		return nil
+	}
+}
+
+// lease returns the context to execute the run of it with. When the scheduler
+// shares its Schedulables with other schedulers, it acquires the lease on the
+// Schedulable first, and returns ErrLeaseHeld if another scheduler holds it.
+// Other errors of the Leaser are retried, and the last one is returned once
+// the attempts run out or the scheduler stops.
+func (s *TreeScheduler) lease(ctx context.Context, it Item) (context.Context, error) {
+	if s.leaser == nil {
+		return ctx, nil
+	}
+	if it.upstreamErr == ErrLeaseHeld {
+		// the upstream runs are executed by another scheduler, which executes
+		// this run as well.
+		s.releaseLease(it.id)
+		return nil, ErrLeaseHeld
+	}
+
+	lctx, err := s.leaser.Lease(ctx, it.id)
+	for attempt, backoff := 1, leaseRetryBackoff; err != nil && err != ErrLeaseHeld && attempt < leaseAttempts; attempt, backoff = attempt+1, backoff*2 {
+		select {
+		case <-s.done:
+			return nil, err
+		case <-time.After(backoff):
+		}
+		lctx, err = s.leaser.Lease(ctx, it.id)
+	}
+
+	s.leaseMu.Lock()
+	defer s.leaseMu.Unlock()
+
+	_, owned := s.leases[it.id]
+	if err != nil {
+		if err == ErrLeaseHeld && owned {
+			delete(s.leases, it.id)
+			s.sm.leaseChange(leaseLost, len(s.leases))
+		}
+		return nil, err
+	}
+	s.leases[it.id] = struct{}{}
+	if !owned {
+		s.sm.leaseChange(leaseAcquired, len(s.leases))
+	}
+	return lctx, nil
+}
+
+// releaseLease gives up the lease on id, if the scheduler holds it.
+func (s *TreeScheduler) releaseLease(id ID) {
+	if s.leaser == nil {
+		return
+	}
+
+	s.leaseMu.Lock()
+	defer s.leaseMu.Unlock()
+
+	if _, owned := s.leases[id]; !owned {
+		return
+	}
+	delete(s.leases, id)
+	s.sm.leaseChange(leaseReleased, len(s.leases))
+
+	if err := s.leaser.Release(context.Background(), id); err != nil {
+		s.onErr(context.Background(), id, time.Time{}, err)
+	}
+}
+
+// releaseLeases gives up all leases the scheduler holds.
+func (s *TreeScheduler) releaseLeases() {
+	s.leaseMu.Lock()
+	ids := make([]ID, 0, len(s.leases))
+	for id := range s.leases {
+		ids = append(ids, id)
+	}
+	s.leaseMu.Unlock()
+
+	for _, id := range ids {
+		s.releaseLease(id)
+	}
+}
+
+// renewLeases renews the leases the scheduler holds every renewEvery, so that
+// other schedulers do not take over Schedulables between their runs.
+func (s *TreeScheduler) renewLeases() {
+	defer s.wg.Done()
+
+	ticker := s.time.Ticker(s.renewEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+
+		s.leaseMu.Lock()
+		ids := make([]ID, 0, len(s.leases))
+		for id := range s.leases {
+			ids = append(ids, id)
+		}
+		s.leaseMu.Unlock()
+
+		for _, id := range ids {
+			_, err := s.leaser.Lease(context.Background(), id)
+
+			s.leaseMu.Lock()
+			if _, owned := s.leases[id]; owned {
+				switch {
+				case err == ErrLeaseHeld:
+					delete(s.leases, id)
+					s.sm.leaseChange(leaseLost, len(s.leases))
+				case err != nil:
+					s.onErr(context.Background(), id, time.Time{}, err)
+				}
+			}
+			s.leaseMu.Unlock()
+		}
+	}
+}
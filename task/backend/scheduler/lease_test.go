@@ -0,0 +1,260 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+)
+
+// mockLeases are leases shared by several schedulers.
+type mockLeases struct {
+	mu     sync.Mutex
+	time   clock.Clock
+	ttl    time.Duration
+	owners map[ID]string
+	expiry map[ID]time.Time
+}
+
+func newMockLeases(t clock.Clock, ttl time.Duration) *mockLeases {
+	return &mockLeases{time: t, ttl: ttl, owners: map[ID]string{}, expiry: map[ID]time.Time{}}
+}
+
+// mockLeaser leases Schedulables to one owner.
+type mockLeaser struct {
+	*mockLeases
+	owner string
+}
+
+func (l mockLeaser) Lease(ctx context.Context, id ID) (context.Context, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.time.Now()
+	if owner, ok := l.owners[id]; ok && owner != l.owner && now.Before(l.expiry[id]) {
+		return nil, ErrLeaseHeld
+	}
+	l.owners[id] = l.owner
+	l.expiry[id] = now.Add(l.ttl)
+	return ctx, nil
+}
+
+func (l mockLeaser) Release(ctx context.Context, id ID) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.owners[id] == l.owner {
+		delete(l.owners, id)
+		delete(l.expiry, id)
+	}
+	return nil
+}
+
+type ownedExecution struct {
+	owner string
+	execution
+}
+
+func TestTreeScheduler_Leases(t *testing.T) {
+	c := make(chan ownedExecution, 100)
+	errs := make(chan error, 100)
+	mockTime := clock.NewMock()
+	mockTime.Set(time.Date(2020, 1, 1, 0, 0, 30, 0, time.UTC))
+	leases := newMockLeases(mockTime, 30*time.Second)
+
+	newScheduler := func(owner string) *TreeScheduler {
+		exe := &mockExecutor{fn: func(l *sync.Mutex, ctx context.Context, id ID, scheduledFor time.Time) {
+			c <- ownedExecution{owner: owner, execution: execution{id: id, scheduledFor: scheduledFor.UTC()}}
+		}}
+		sch, _, err := NewScheduler(
+			exe,
+			&mockSchedulableService{},
+			WithTime(mockTime),
+			WithOnErrorFn(func(_ context.Context, _ ID, _ time.Time, err error) {
+				errs <- err
+			}),
+			WithLeaser(mockLeaser{mockLeases: leases, owner: owner}, 10*time.Second),
+			WithMaxConcurrentWorkers(20))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sch
+	}
+	schedulers := map[string]*TreeScheduler{
+		"a": newScheduler("a"),
+		"b": newScheduler("b"),
+	}
+
+	advance := func(d time.Duration) {
+		go func() {
+			schedulers["a"].mu.Lock()
+			schedulers["b"].mu.Lock()
+			mockTime.Add(d)
+			schedulers["b"].mu.Unlock()
+			schedulers["a"].mu.Unlock()
+		}()
+	}
+	expectExecution := func(exp execution) string {
+		t.Helper()
+
+		select {
+		case got := <-c:
+			if got.id != exp.id || !got.scheduledFor.Equal(exp.scheduledFor) {
+				t.Fatalf("expected execution of %d for %s, got %d for %s", exp.id, exp.scheduledFor, got.id, got.scheduledFor)
+			}
+			return got.owner
+		case <-time.After(6 * time.Second):
+			t.Fatalf("test timed out, expected execution of %d for %s", exp.id, exp.scheduledFor)
+		}
+		return ""
+	}
+	expectNoExecution := func() {
+		t.Helper()
+
+		select {
+		case got := <-c:
+			t.Fatalf("expected no execution, got %d for %s by %s", got.id, got.scheduledFor, got.owner)
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+
+	schedule, ts, err := NewSchedule("@every 1m", mockTime.Now().UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sch := range schedulers {
+		if err := sch.Schedule(mockSchedulable{id: 1, schedule: schedule, lastScheduled: ts}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// only one of the schedulers executes the run
+	first := time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC)
+	advance(time.Minute)
+	owner := expectExecution(execution{id: 1, scheduledFor: first})
+	expectNoExecution()
+
+	// the owner keeps its lease between runs
+	advance(time.Minute)
+	if got := expectExecution(execution{id: 1, scheduledFor: first.Add(time.Minute)}); got != owner {
+		t.Fatalf("expected %s to keep executing, got %s", owner, got)
+	}
+	expectNoExecution()
+
+	// the other scheduler takes over once the owner stops
+	other := "a"
+	if owner == "a" {
+		other = "b"
+	}
+	schedulers[owner].Stop()
+	defer schedulers[other].Stop()
+
+	advance = func(d time.Duration) {
+		go func() {
+			schedulers[other].mu.Lock()
+			mockTime.Add(d)
+			schedulers[other].mu.Unlock()
+		}()
+	}
+	advance(time.Minute)
+	if got := expectExecution(execution{id: 1, scheduledFor: first.Add(2 * time.Minute)}); got != other {
+		t.Fatalf("expected %s to take over, got %s", other, got)
+	}
+
+	select {
+	case err := <-errs:
+		t.Fatalf("unexpected error %v", err)
+	default:
+	}
+}
+
+// failingLeaser fails to lease Schedulables a number of times, before it grants
+// every lease.
+type failingLeaser struct {
+	mu       sync.Mutex
+	failures int
+	err      error
+}
+
+func (l *failingLeaser) Lease(ctx context.Context, id ID) (context.Context, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.failures > 0 {
+		l.failures--
+		return nil, l.err
+	}
+	return ctx, nil
+}
+
+func (l *failingLeaser) Release(ctx context.Context, id ID) error {
+	return nil
+}
+
+func TestTreeScheduler_LeaseErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		failures int
+		executed bool
+	}{
+		{name: "retried", failures: leaseAttempts - 1, executed: true},
+		{name: "attempts run out", failures: leaseAttempts, executed: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := make(chan execution, 100)
+			errs := make(chan error, 100)
+			mockTime := clock.NewMock()
+			mockTime.Set(time.Date(2020, 1, 1, 0, 0, 30, 0, time.UTC))
+			leaseErr := errors.New("store unavailable")
+
+			exe := &mockExecutor{fn: func(l *sync.Mutex, ctx context.Context, id ID, scheduledFor time.Time) {
+				c <- execution{id: id, scheduledFor: scheduledFor.UTC()}
+			}}
+			sch, _, err := NewScheduler(
+				exe,
+				&mockSchedulableService{},
+				WithTime(mockTime),
+				WithOnErrorFn(func(_ context.Context, _ ID, _ time.Time, err error) {
+					errs <- err
+				}),
+				WithLeaser(&failingLeaser{failures: tt.failures, err: leaseErr}, 10*time.Second),
+				WithMaxConcurrentWorkers(20))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sch.Stop()
+
+			schedule, ts, err := NewSchedule("@every 1m", mockTime.Now().UTC())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := sch.Schedule(mockSchedulable{id: 1, schedule: schedule, lastScheduled: ts}); err != nil {
+				t.Fatal(err)
+			}
+
+			advance(sch, mockTime, time.Minute)
+			if tt.executed {
+				expectExecution(t, c, execution{id: 1, scheduledFor: time.Date(2020, 1, 1, 0, 1, 0, 0, time.UTC)})
+				select {
+				case err := <-errs:
+					t.Fatalf("unexpected error %v", err)
+				default:
+				}
+				return
+			}
+
+			select {
+			case err := <-errs:
+				if err != leaseErr {
+					t.Fatalf("expected lease error, got %v", err)
+				}
+			case <-time.After(6 * time.Second):
+				t.Fatal("test timed out, expected the lease error to be reported")
+			}
+			expectNoExecution(t, c)
+		})
+	}
+}
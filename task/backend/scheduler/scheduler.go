@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
@@ -44,6 +45,16 @@ type Schedulable interface {
 	LastScheduled() time.Time
 }
 
+// DependentSchedulable is a Schedulable that depends on other Schedulables.
+// Its run for a scheduled time is executed only after the latest runs of all of
+// its upstream Schedulables scheduled at or before that time have succeeded.
+type DependentSchedulable interface {
+	Schedulable
+
+	// Upstream returns the IDs of the Schedulables this Schedulable depends on.
+	Upstream() []ID
+}
+
 // SchedulableService encapsulates the work necessary to schedule a job
 type SchedulableService interface {
 
@@ -126,3 +137,22 @@ func (e *ErrUnrecoverable) Error() string {
 func (e *ErrUnrecoverable) Unwrap() error {
 	return e.error
 }
+
+// ErrUpstreamFailed is the error reported for a run of a DependentSchedulable
+// that was skipped, because the latest run of one of its upstream Schedulables
+// for its scheduled time failed, or the upstream Schedulable is not scheduled.
+type ErrUpstreamFailed struct {
+	Upstream ID
+	Err      error
+}
+
+func (e *ErrUpstreamFailed) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("upstream %016x failed: %v", uint64(e.Upstream), e.Err)
+	}
+	return fmt.Sprintf("upstream %016x did not run", uint64(e.Upstream))
+}
+
+func (e *ErrUpstreamFailed) Unwrap() error {
+	return e.Err
+}
@@ -16,8 +16,18 @@ type SchedulerMetrics struct {
 	executingTasks *executingTasks
 	scheduleDelay  prometheus.Summary
 	executeDelta   prometheus.Summary
+
+	leaseChanges *prometheus.CounterVec
+	leasesOwned  prometheus.Gauge
 }
 
+// The changes of ownership of a Schedulable by a scheduler that shares it with others.
+const (
+	leaseAcquired = "acquired"
+	leaseLost     = "lost"
+	leaseReleased = "released"
+)
+
 type executingTasks struct {
 	desc *prometheus.Desc
 	ts   *TreeScheduler
@@ -76,6 +86,20 @@ func NewSchedulerMetrics(te *TreeScheduler) *SchedulerMetrics {
 			Help:       "The duration in seconds between a run starting and finishing.",
 			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
 		}),
+
+		leaseChanges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "lease_changes_total",
+			Help:      "Total number of times the scheduler acquired, lost or released the lease on a task.",
+		}, []string{"change"}),
+
+		leasesOwned: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "leases_owned",
+			Help:      "Number of tasks the scheduler currently holds the lease on.",
+		}),
 	}
 }
 
@@ -90,6 +114,8 @@ func (em *SchedulerMetrics) PrometheusCollectors() []prometheus.Collector {
 		em.executingTasks,
 		em.scheduleDelay,
 		em.executeDelta,
+		em.leaseChanges,
+		em.leasesOwned,
 	}
 }
 
@@ -105,6 +131,11 @@ func (em *SchedulerMetrics) release(taskID ID) {
 	em.releaseCalls.Inc()
 }
 
+func (em *SchedulerMetrics) leaseChange(change string, owned int) {
+	em.leaseChanges.WithLabelValues(change).Inc()
+	em.leasesOwned.Set(float64(owned))
+}
+
 func (em *SchedulerMetrics) reportScheduleDelay(d time.Duration) {
 	em.scheduleDelay.Observe(d.Seconds())
 }
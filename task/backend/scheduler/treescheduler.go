@@ -60,6 +60,25 @@ const (
 // Removing a task from the scheduler acquires a write lock, deletes the task from the uniqueness index and from the
 // btree, then releases the lock.  We do not have to readjust the time on delete, because, if the minimum task isn't
 // ready yet, the main loop just resets the timer and keeps going.
+//
+// Dependencies:
+//
+// A DependentSchedulable declares upstream tasks, which the scheduler keeps as a DAG of upstream and downstream edges.
+// When a task with upstream tasks is due, the main loop checks the outcome of the latest run of each upstream task
+// scheduled at or before the task's scheduled time, so upstream tasks may run on other schedules.  If one of them is
+// still running, or has yet to be executed, the task is moved from the btree into a map of blocked tasks, and put
+// back once RunFinished reports the outcome of its last upstream run.  If an upstream run failed, or the upstream
+// task is no longer scheduled and has no known run, the task's run is skipped and reported through the ErrorFunc as
+// an ErrUpstreamFailed.
+//
+// Leases:
+//
+// Several schedulers can share the same tasks when they are given a Leaser.  Every scheduler keeps every task in its
+// btree, but a worker only executes a run once it has acquired the task's lease, and skips it while another scheduler
+// holds it.  The leases a scheduler holds are renewed in the background, and released when it stops, so that another
+// scheduler takes over the task's next run.  A skipped run is reported to RunFinished as ErrLeaseHeld, so that the
+// runs of the tasks that depend on it are left to the same scheduler.  A worker retries other errors of the Leaser a
+// few times, and then reports the error through the ErrorFunc and to RunFinished, without executing the run.
 type TreeScheduler struct {
 	mu            sync.RWMutex
 	priorityQueue *btree.BTree
@@ -75,6 +94,16 @@ type TreeScheduler struct {
 	checkpointer  SchedulableService
 	items         *itemList
 
+	upstream   map[ID][]ID                // upstream tasks of each task that has any
+	downstream map[ID]map[ID]struct{}     // tasks that depend on each upstream task
+	results    map[ID]map[int64]runResult // outcomes of the runs of upstream tasks by scheduled time
+	blocked    map[ID]Item                // tasks that are waiting on upstream runs
+
+	leaser     Leaser
+	renewEvery time.Duration
+	leaseMu    sync.Mutex
+	leases     map[ID]struct{} // tasks the scheduler holds the lease on
+
 	sm *SchedulerMetrics
 }
 
@@ -120,6 +149,11 @@ func NewScheduler(executor Executor, checkpointer SchedulableService, opts ...tr
 		done:          make(chan struct{}, 1),
 		checkpointer:  checkpointer,
 		items:         &itemList{},
+		upstream:      map[ID][]ID{},
+		downstream:    map[ID]map[ID]struct{}{},
+		results:       map[ID]map[int64]runResult{},
+		blocked:       map[ID]Item{},
+		leases:        map[ID]struct{}{},
 	}
 
 	// apply options
@@ -148,6 +182,10 @@ func NewScheduler(executor Executor, checkpointer SchedulableService, opts ...tr
 	if executor == nil {
 		return nil, nil, errors.New("executor must be a non-nil function")
 	}
+	if s.leaser != nil {
+		s.wg.Add(1)
+		go s.renewLeases()
+	}
 	s.wg.Add(1)
 	go func() {
 		defer s.wg.Done()
@@ -207,6 +245,9 @@ func (s *TreeScheduler) Stop() {
 	close(s.done)
 	s.mu.Unlock()
 	s.wg.Wait()
+
+	// let other schedulers take over at once
+	s.releaseLeases()
 }
 
 // itemList is a list of items for deleting and inserting.  We have to do them seperately instead of just a re-add,
@@ -248,6 +289,16 @@ func (s *TreeScheduler) iterator(ts time.Time) btree.ItemIterator {
 		if time.Unix(it.next+it.Offset, 0).After(ts) {
 			return false
 		}
+		if len(s.upstream[it.id]) > 0 {
+			pending, err := s.upstreamState(it)
+			if pending {
+				// take the task out of the tree until its upstream runs finish
+				s.items.toDelete = append(s.items.toDelete, it)
+				s.blocked[it.id] = it
+				return true
+			}
+			it.upstreamErr = err
+		}
 		// distribute to the right worker.
 		{
 			buf := [8]byte{}
@@ -256,6 +307,7 @@ func (s *TreeScheduler) iterator(ts time.Time) btree.ItemIterator {
 			select {
 			case s.workchans[wc] <- it:
 				s.items.toDelete = append(s.items.toDelete, it)
+				s.recordResult(it.id, it.next, runResult{})
 				if err := it.updateNext(); err != nil {
 					// in this error case we can't schedule next, so we have to drop the task
 					s.onErr(context.Background(), it.id, it.Next(), &ErrUnrecoverable{err})
@@ -292,6 +344,14 @@ func (s *TreeScheduler) release(taskID ID) {
 	delete(s.nextTime, taskID)
 }
 
+// releaseDependencies removes a task from the DAG and the blocked tasks, and
+// lets tasks that wait on it find out that it is no longer scheduled.
+func (s *TreeScheduler) releaseDependencies(taskID ID) {
+	delete(s.blocked, taskID)
+	s.setUpstream(taskID, nil)
+	s.unblockDownstream(taskID)
+}
+
 // Release releases a task.
 // Release also cancels the running task.
 // Task deletion would be faster if the tree supported deleting ranges.
@@ -299,7 +359,9 @@ func (s *TreeScheduler) Release(taskID ID) error {
 	s.sm.release(taskID)
 	s.mu.Lock()
 	s.release(taskID)
+	s.releaseDependencies(taskID)
 	s.mu.Unlock()
+	s.releaseLease(taskID)
 	return nil
 }
 
@@ -311,7 +373,29 @@ func (s *TreeScheduler) work(ctx context.Context, ch chan Item) {
 	}()
 	for it = range ch {
 		t := time.Unix(it.next, 0)
-		err := func() (err error) {
+		ectx, err := s.lease(ctx, it)
+		if err == ErrLeaseHeld {
+			// the run is left to the scheduler that holds the lease, and so are
+			// the runs that depend on it.
+			s.RunFinished(it.id, t, ErrLeaseHeld)
+			continue
+		}
+		if err != nil {
+			// the lease could not be acquired, so no scheduler can fence the run.
+			s.onErr(ctx, it.id, it.Next(), err)
+			s.RunFinished(it.id, t, err)
+			continue
+		}
+		if it.upstreamErr != nil {
+			// an upstream run did not succeed, so this run is skipped
+			s.onErr(ctx, it.id, it.Next(), it.upstreamErr)
+			s.RunFinished(it.id, t, it.upstreamErr)
+			if err := s.checkpointer.UpdateLastScheduled(ctx, it.id, t); err != nil {
+				s.onErr(ctx, it.id, it.Next(), err)
+			}
+			continue
+		}
+		err = func() (err error) {
 			defer func() {
 				if r := recover(); r != nil {
 					err = &ErrUnrecoverable{errors.New("executor panicked")}
@@ -321,13 +405,15 @@ func (s *TreeScheduler) work(ctx context.Context, ch chan Item) {
 			s.sm.reportScheduleDelay(time.Since(it.Next()))
 			preExec := time.Now()
 			// execute
-			err = s.executor.Execute(ctx, it.id, t, it.When())
+			err = s.executor.Execute(ectx, it.id, t, it.When())
 			// report how long execution took
 			s.sm.reportExecution(err, time.Since(preExec))
 			return err
 		}()
 		if err != nil {
 			s.onErr(ctx, it.id, it.Next(), err)
+			// no run was created, so tasks that depend on it must not wait for one
+			s.RunFinished(it.id, t, err)
 		}
 		// TODO(docmerlin): we can increase performance by making the call to UpdateLastScheduled async
 		if err := s.checkpointer.UpdateLastScheduled(ctx, it.id, t); err != nil {
@@ -357,6 +443,17 @@ func (s *TreeScheduler) Schedule(sch Schedulable) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	var upstream []ID
+	if ds, ok := sch.(DependentSchedulable); ok {
+		upstream = ds.Upstream()
+	}
+	if err := s.setUpstream(it.id, upstream); err != nil {
+		s.sm.scheduleFail(it.id)
+		s.onErr(context.Background(), it.id, time.Time{}, err)
+		return err
+	}
+	delete(s.blocked, it.id)
+
 	nt = nt.Add(sch.Offset())
 	if s.when.IsZero() || s.when.After(nt) {
 		s.when = nt
@@ -391,6 +488,9 @@ type Item struct {
 	cron   Schedule
 	next   int64
 	Offset int64
+
+	// upstreamErr is set when the run must be skipped because an upstream run did not succeed.
+	upstreamErr error
 }
 
 func (it Item) Next() time.Time {
@@ -14,6 +14,13 @@ type TaskControlService interface {
 	// CreateRun creates a run with a scheduled for time.
 	CreateRun(ctx context.Context, taskID influxdb.ID, scheduledFor time.Time, runAt time.Time) (*influxdb.Run, error)
 
+	// CreateRetryRun creates the next attempt of the failed, currently running run runID.
+	// The new run is scheduled for the same time, with the same trigger, and linked to runID.
+	CreateRetryRun(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error)
+
+	// CreateTriggeredRun creates a run, scheduled for now, of the writes in the time range of trigger.
+	CreateTriggeredRun(ctx context.Context, taskID influxdb.ID, trigger influxdb.RunTrigger) (*influxdb.Run, error)
+
 	CurrentlyRunning(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Run, error)
 	ManualRuns(ctx context.Context, taskID influxdb.ID) ([]*influxdb.Run, error)
 
@@ -23,6 +30,10 @@ type TaskControlService interface {
 	// FinishRun removes runID from the list of running tasks and if its `ScheduledFor` is later then last completed update it.
 	FinishRun(ctx context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error)
 
+	// CountRunResult counts the final state of a run in the consecutive failures of the task,
+	// and returns the consecutive failed runs including the run, or preceding it if it succeeded.
+	CountRunResult(ctx context.Context, taskID influxdb.ID, state influxdb.RunStatus) (int, error)
+
 	// UpdateRunState sets the run state at the respective time.
 	UpdateRunState(ctx context.Context, taskID, runID influxdb.ID, when time.Time, state influxdb.RunStatus) error
 
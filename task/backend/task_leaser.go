@@ -0,0 +1,43 @@
+package backend
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/task/backend/scheduler"
+)
+
+var _ scheduler.Leaser = (*TaskLeaser)(nil)
+
+// TaskLeaser implements the scheduler.Leaser interface on top of a TaskLeaseService,
+// so that several schedulers sharing a store partition its tasks between them.
+type TaskLeaser struct {
+	ls    influxdb.TaskLeaseService
+	owner string
+	ttl   time.Duration
+}
+
+// NewTaskLeaser initializes a new TaskLeaser that leases tasks to owner for ttl.
+func NewTaskLeaser(ls influxdb.TaskLeaseService, owner string, ttl time.Duration) *TaskLeaser {
+	return &TaskLeaser{ls: ls, owner: owner, ttl: ttl}
+}
+
+// Lease acquires, or renews, the lease on a task. The returned context carries
+// the lease, so that runs recorded with it are rejected once the lease is lost.
+func (l *TaskLeaser) Lease(ctx context.Context, id scheduler.ID) (context.Context, error) {
+	lease, err := l.ls.AcquireTaskLease(ctx, influxdb.ID(id), l.owner, l.ttl)
+	if err == influxdb.ErrTaskLeaseHeld {
+		return nil, scheduler.ErrLeaseHeld
+	}
+	if err != nil {
+		return nil, err
+	}
+	return icontext.SetTaskLease(ctx, lease), nil
+}
+
+// Release gives up the lease on a task.
+func (l *TaskLeaser) Release(ctx context.Context, id scheduler.ID) error {
+	return l.ls.ReleaseTaskLease(ctx, influxdb.ID(id), l.owner)
+}
@@ -0,0 +1,252 @@
+package backend
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/storage"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap"
+)
+
+// DefaultTriggerDebounce is how long a task waits after a write before it runs,
+// when its trigger does not set a debounce.
+const DefaultTriggerDebounce = time.Second
+
+// TriggerRunFunc runs a task for the writes in the time range of trigger.
+type TriggerRunFunc func(ctx context.Context, taskID influxdb.ID, trigger influxdb.RunTrigger) error
+
+// WriteTrigger runs the active tasks that have a trigger when points are
+// written to the bucket, and measurement, of their trigger. The writes that
+// happen while a task waits for its debounce trigger a single run, of the time
+// range of all of their points. The points a run writes do not trigger its
+// own task.
+type WriteTrigger struct {
+	log *zap.Logger
+
+	// ctx is canceled when the trigger is closed.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	run     TriggerRunFunc
+	tasks   map[influxdb.ID]*triggeredTask
+	buckets map[influxdb.ID]map[influxdb.ID]*triggeredTask
+}
+
+// triggeredTask is a task triggered by writes, and the time range of the
+// writes since its last run.
+type triggeredTask struct {
+	id      influxdb.ID
+	trigger influxdb.TaskTrigger
+
+	start, stop time.Time
+	timer       *time.Timer
+}
+
+// NewWriteTrigger creates a WriteTrigger that does not run tasks until its
+// run func is set, and stops running them when ctx is done or it is closed.
+func NewWriteTrigger(ctx context.Context, log *zap.Logger) *WriteTrigger {
+	ctx, cancel := context.WithCancel(ctx)
+	return &WriteTrigger{
+		log:     log,
+		ctx:     ctx,
+		cancel:  cancel,
+		tasks:   make(map[influxdb.ID]*triggeredTask),
+		buckets: make(map[influxdb.ID]map[influxdb.ID]*triggeredTask),
+	}
+}
+
+// Close stops running triggered tasks, and drops the writes they have not run for.
+func (t *WriteTrigger) Close() {
+	t.cancel()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, tt := range t.tasks {
+		t.remove(tt)
+	}
+}
+
+// SetRunFunc sets the func that runs triggered tasks.
+func (t *WriteTrigger) SetRunFunc(fn TriggerRunFunc) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.run = fn
+}
+
+// SetTask triggers task on writes to the bucket of its trigger, or stops
+// triggering it if it has no trigger or is inactive.
+func (t *WriteTrigger) SetTask(task *influxdb.Task) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if tt, ok := t.tasks[task.ID]; ok {
+		if task.Trigger != nil && task.Status == string(influxdb.TaskActive) && *task.Trigger == tt.trigger {
+			return
+		}
+		t.remove(tt)
+	}
+	if task.Trigger == nil || task.Status != string(influxdb.TaskActive) {
+		return
+	}
+
+	tt := &triggeredTask{id: task.ID, trigger: *task.Trigger}
+	t.tasks[task.ID] = tt
+	if t.buckets[tt.trigger.BucketID] == nil {
+		t.buckets[tt.trigger.BucketID] = make(map[influxdb.ID]*triggeredTask)
+	}
+	t.buckets[tt.trigger.BucketID][task.ID] = tt
+}
+
+// RemoveTask stops triggering the task id, and drops the writes it has not run for.
+func (t *WriteTrigger) RemoveTask(id influxdb.ID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if tt, ok := t.tasks[id]; ok {
+		t.remove(tt)
+	}
+}
+
+// remove must be called with the lock held.
+func (t *WriteTrigger) remove(tt *triggeredTask) {
+	if tt.timer != nil {
+		tt.timer.Stop()
+	}
+	delete(t.tasks, tt.id)
+	delete(t.buckets[tt.trigger.BucketID], tt.id)
+	if len(t.buckets[tt.trigger.BucketID]) == 0 {
+		delete(t.buckets, tt.trigger.BucketID)
+	}
+}
+
+// Written triggers the tasks of the bucket bucketID, and measurement, for
+// points written in the time range [start, stop).
+func (t *WriteTrigger) Written(bucketID influxdb.ID, measurement string, start, stop time.Time) {
+	t.writtenBy(0, bucketID, measurement, start, stop)
+}
+
+// writtenBy triggers the tasks of the bucket bucketID, and measurement, other
+// than the task writer, if valid, whose run wrote the points.
+func (t *WriteTrigger) writtenBy(writer, bucketID influxdb.ID, measurement string, start, stop time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, tt := range t.buckets[bucketID] {
+		if tt.id == writer {
+			continue
+		}
+		if tt.trigger.Measurement != "" && tt.trigger.Measurement != measurement {
+			continue
+		}
+
+		if tt.start.IsZero() || start.Before(tt.start) {
+			tt.start = start
+		}
+		if stop.After(tt.stop) {
+			tt.stop = stop
+		}
+		if tt.timer != nil {
+			continue
+		}
+
+		debounce := tt.trigger.Debounce.Duration
+		if debounce <= 0 {
+			debounce = DefaultTriggerDebounce
+		}
+		tt := tt
+		tt.timer = time.AfterFunc(debounce, func() { t.fire(tt) })
+	}
+}
+
+// fire runs tt for the writes since its last run.
+func (t *WriteTrigger) fire(tt *triggeredTask) {
+	t.mu.Lock()
+	if t.tasks[tt.id] != tt {
+		// the task was removed, or its trigger changed, since it was triggered
+		t.mu.Unlock()
+		return
+	}
+	trigger := influxdb.RunTrigger{Start: tt.start, Stop: tt.stop}
+	tt.start, tt.stop, tt.timer = time.Time{}, time.Time{}, nil
+	run := t.run
+	t.mu.Unlock()
+
+	if run == nil || t.ctx.Err() != nil {
+		return
+	}
+	if err := run(t.ctx, tt.id, trigger); err != nil {
+		t.log.Info("Failed to run triggered task", zap.String("taskID", tt.id.String()), zap.Error(err))
+	}
+}
+
+// written triggers the tasks of the buckets and measurements of points, which
+// are exploded as they are for storage, other than the task whose run wrote
+// them with ctx.
+func (t *WriteTrigger) written(ctx context.Context, points []models.Point) {
+	t.mu.Lock()
+	empty := len(t.buckets) == 0
+	t.mu.Unlock()
+	if empty {
+		return
+	}
+
+	type series struct {
+		bucketID    influxdb.ID
+		measurement string
+	}
+	ranges := make(map[series]*influxdb.RunTrigger)
+	for _, p := range points {
+		_, bucketID := tsdb.DecodeNameSlice(p.Name())
+		s := series{
+			bucketID:    bucketID,
+			measurement: string(p.Tags().Get(models.MeasurementTagKeyBytes)),
+		}
+		// the range is exclusive of its stop, which must include the point
+		start, stop := p.Time(), p.Time().Add(1)
+		if r, ok := ranges[s]; !ok {
+			ranges[s] = &influxdb.RunTrigger{Start: start, Stop: stop}
+		} else {
+			if start.Before(r.Start) {
+				r.Start = start
+			}
+			if stop.After(r.Stop) {
+				r.Stop = stop
+			}
+		}
+	}
+
+	writer, _ := icontext.GetTaskRun(ctx)
+	for s, r := range ranges {
+		t.writtenBy(writer, s.bucketID, s.measurement, r.Start, r.Stop)
+	}
+}
+
+// TriggerPointsWriter is a storage.PointsWriter that informs a WriteTrigger
+// of the points it writes.
+type TriggerPointsWriter struct {
+	storage.PointsWriter
+	trigger *WriteTrigger
+}
+
+// NewTriggerPointsWriter creates a TriggerPointsWriter that writes points
+// with w, and then triggers the tasks of trigger.
+func NewTriggerPointsWriter(w storage.PointsWriter, trigger *WriteTrigger) *TriggerPointsWriter {
+	return &TriggerPointsWriter{PointsWriter: w, trigger: trigger}
+}
+
+// WritePoints writes points, and triggers the tasks of the buckets and
+// measurements of the points once they are written. The task whose run
+// writes the points is not triggered.
+func (w *TriggerPointsWriter) WritePoints(ctx context.Context, points []models.Point) error {
+	if err := w.PointsWriter.WritePoints(ctx, points); err != nil {
+		return err
+	}
+	w.trigger.written(ctx, points)
+	return nil
+}
@@ -0,0 +1,126 @@
+package backend_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+	icontext "github.com/influxdata/influxdb/v2/context"
+	"github.com/influxdata/influxdb/v2/models"
+	"github.com/influxdata/influxdb/v2/task/backend"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"go.uber.org/zap/zaptest"
+)
+
+type nopPointsWriter struct{}
+
+func (nopPointsWriter) WritePoints(context.Context, []models.Point) error { return nil }
+
+func TestWriteTrigger(t *testing.T) {
+	const (
+		orgID    = influxdb.ID(1)
+		bucketID = influxdb.ID(2)
+		taskID   = influxdb.ID(3)
+	)
+
+	type triggered struct {
+		taskID  influxdb.ID
+		trigger influxdb.RunTrigger
+	}
+	runs := make(chan triggered, 10)
+
+	wt := backend.NewWriteTrigger(context.Background(), zaptest.NewLogger(t))
+	defer wt.Close()
+	wt.SetRunFunc(func(_ context.Context, id influxdb.ID, trigger influxdb.RunTrigger) error {
+		runs <- triggered{taskID: id, trigger: trigger}
+		return nil
+	})
+	wt.SetTask(&influxdb.Task{
+		ID:     taskID,
+		Status: string(influxdb.TaskActive),
+		Trigger: &influxdb.TaskTrigger{
+			BucketID:    bucketID,
+			Measurement: "cpu",
+			Debounce:    influxdb.Duration{Duration: 50 * time.Millisecond},
+		},
+	})
+
+	w := backend.NewTriggerPointsWriter(nopPointsWriter{}, wt)
+	writeWith := func(ctx context.Context, measurement string, bucketID influxdb.ID, ts ...int64) {
+		t.Helper()
+		var points []models.Point
+		for _, sec := range ts {
+			pt, err := models.NewPoint(
+				tsdb.EncodeNameString(orgID, bucketID),
+				models.NewTags(map[string]string{
+					models.MeasurementTagKey: measurement,
+					models.FieldKeyTagKey:    "usage",
+				}),
+				models.Fields{"usage": 1.0},
+				time.Unix(sec, 0),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			points = append(points, pt)
+		}
+		if err := w.WritePoints(ctx, points); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(measurement string, bucketID influxdb.ID, ts ...int64) {
+		t.Helper()
+		writeWith(context.Background(), measurement, bucketID, ts...)
+	}
+	expectNoRun := func(msg string) {
+		t.Helper()
+		select {
+		case r := <-runs:
+			t.Fatalf("expected %s, got %+v", msg, r)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	// the writes during the debounce trigger a single run of all of their points
+	write("cpu", bucketID, 20, 10)
+	write("cpu", bucketID, 30)
+	// writes of other measurements and buckets do not trigger the task
+	write("mem", bucketID, 5)
+	write("cpu", influxdb.ID(4), 40)
+
+	select {
+	case r := <-runs:
+		exp := influxdb.RunTrigger{Start: time.Unix(10, 0), Stop: time.Unix(30, 1)}
+		if r.taskID != taskID || !r.trigger.Start.Equal(exp.Start) || !r.trigger.Stop.Equal(exp.Stop) {
+			t.Fatalf("unexpected triggered run %+v, expected %+v", r, exp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the task to be triggered")
+	}
+
+	expectNoRun("a single triggered run")
+
+	// the points written by a run of the task do not trigger it again
+	writeWith(icontext.SetTaskRun(context.Background(), taskID), "cpu", bucketID, 60)
+	expectNoRun("the task not to trigger itself")
+
+	// inactive tasks are not triggered
+	wt.SetTask(&influxdb.Task{
+		ID:      taskID,
+		Status:  string(influxdb.TaskInactive),
+		Trigger: &influxdb.TaskTrigger{BucketID: bucketID},
+	})
+	write("cpu", bucketID, 50)
+	expectNoRun("an inactive task not to be triggered")
+
+	// a closed trigger drops the writes it has not run for
+	wt.SetTask(&influxdb.Task{
+		ID:      taskID,
+		Status:  string(influxdb.TaskActive),
+		Trigger: &influxdb.TaskTrigger{BucketID: bucketID, Debounce: influxdb.Duration{Duration: 50 * time.Millisecond}},
+	})
+	write("cpu", bucketID, 70)
+	wt.Close()
+	expectNoRun("a closed trigger not to run tasks")
+}
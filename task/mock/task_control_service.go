@@ -73,6 +73,50 @@ func (t *TaskControlService) CreateRun(_ context.Context, taskID influxdb.ID, sc
 	return runs[runID], nil
 }
 
+func (t *TaskControlService) CreateRetryRun(_ context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev, ok := t.runs[taskID][runID]
+	if !ok {
+		return nil, influxdb.ErrRunNotFound
+	}
+	if prev.Status != influxdb.RunFail.String() {
+		return nil, influxdb.ErrRunNotFailed
+	}
+	attempt := prev.Attempt
+	if attempt == 0 {
+		attempt = 1
+	}
+
+	id := idgen.ID()
+	t.runs[taskID][id] = &influxdb.Run{
+		ID:           id,
+		ScheduledFor: prev.ScheduledFor,
+		Attempt:      attempt + 1,
+		RetryOf:      runID,
+	}
+	return t.runs[taskID][id], nil
+}
+
+func (t *TaskControlService) CreateTriggeredRun(_ context.Context, taskID influxdb.ID, trigger influxdb.RunTrigger) (*influxdb.Run, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	runID := idgen.ID()
+	runs, ok := t.runs[taskID]
+	if !ok {
+		runs = make(map[influxdb.ID]*influxdb.Run)
+	}
+	runs[runID] = &influxdb.Run{
+		ID:           runID,
+		ScheduledFor: time.Now().UTC().Truncate(time.Second),
+		Trigger:      &trigger,
+	}
+	t.runs[taskID] = runs
+	return runs[runID], nil
+}
+
 func (t *TaskControlService) StartManualRun(_ context.Context, taskID, runID influxdb.ID) (*influxdb.Run, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -129,6 +173,26 @@ func (t *TaskControlService) ManualRuns(ctx context.Context, taskID influxdb.ID)
 	return []*influxdb.Run{}, nil
 }
 
+// CountRunResult counts the final state of a run in the consecutive failures of the task.
+func (d *TaskControlService) CountRunResult(ctx context.Context, taskID influxdb.ID, state influxdb.RunStatus) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	task, ok := d.tasks[taskID]
+	if !ok {
+		return 0, nil
+	}
+	failures := task.ConsecutiveFailures
+	switch state {
+	case influxdb.RunFail:
+		failures++
+		task.ConsecutiveFailures = failures
+	case influxdb.RunSuccess:
+		task.ConsecutiveFailures = 0
+	}
+	return failures, nil
+}
+
 // UpdateRunState sets the run state at the respective time.
 func (d *TaskControlService) UpdateRunState(ctx context.Context, taskID, runID influxdb.ID, when time.Time, state influxdb.RunStatus) error {
 	d.mu.Lock()
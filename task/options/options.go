@@ -147,6 +147,12 @@ const (
 	optOffset      = "offset"
 	optConcurrency = "concurrency"
 	optRetry       = "retry"
+
+	// optTriggerStart and optTriggerStop are the time range of the writes that
+	// triggered a run, set by the executor. The values in the script are the
+	// ones used by scheduled runs.
+	optTriggerStart = "triggerStart"
+	optTriggerStop  = "triggerStop"
 )
 
 // contains is a helper function to see if an array of strings contains a string
@@ -193,6 +199,51 @@ func grabTaskOptionAST(p *ast.Package, keys ...string) map[string]ast.Expression
 	return res
 }
 
+// WithTriggerRange returns script with the triggerStart and triggerStop task
+// options set to start and stop, the time range of the writes that triggered
+// a run of the task.
+func WithTriggerRange(script string, start, stop time.Time) (string, error) {
+	p, err := flux.Parse(script)
+	if err != nil {
+		return "", err
+	}
+
+	for _, f := range p.Files {
+		for _, st := range f.Body {
+			opt, ok := st.(*ast.OptionStatement)
+			if !ok {
+				continue
+			}
+			asmt, ok := opt.Assignment.(*ast.VariableAssignment)
+			if !ok || asmt.ID.Key() != "task" {
+				continue
+			}
+			obj, ok := asmt.Init.(*ast.ObjectExpression)
+			if !ok {
+				return "", ErrMissingRequiredTaskOption("task")
+			}
+			setProperty(obj, optTriggerStart, &ast.DateTimeLiteral{Value: start.UTC()})
+			setProperty(obj, optTriggerStop, &ast.DateTimeLiteral{Value: stop.UTC()})
+			return ast.Format(p), nil
+		}
+	}
+	return "", ErrMissingRequiredTaskOption("task")
+}
+
+// setProperty sets the property key of obj to v, replacing its value if obj has it.
+func setProperty(obj *ast.ObjectExpression, key string, v ast.Expression) {
+	for _, prop := range obj.Properties {
+		if prop.Key.Key() == key {
+			prop.Value = v
+			return
+		}
+	}
+	obj.Properties = append(obj.Properties, &ast.Property{
+		Key:   &ast.Identifier{Name: key},
+		Value: v,
+	})
+}
+
 func newDeps() flux.Dependencies {
 	deps := flux.NewDefaultDependencies()
 	deps.Deps.HTTPClient = httpClient{}
@@ -414,7 +465,7 @@ func validateOptionNames(o values.Object) error {
 	var unexpected []string
 	o.Range(func(name string, _ values.Value) {
 		switch name {
-		case optName, optCron, optEvery, optOffset, optConcurrency, optRetry:
+		case optName, optCron, optEvery, optOffset, optConcurrency, optRetry, optTriggerStart, optTriggerStop:
 			// Known option. Nothing to do.
 		default:
 			unexpected = append(unexpected, name)
@@ -423,7 +474,7 @@ func validateOptionNames(o values.Object) error {
 
 	if len(unexpected) > 0 {
 		u := strings.Join(unexpected, ", ")
-		v := strings.Join([]string{optName, optCron, optEvery, optOffset, optConcurrency, optRetry}, ", ")
+		v := strings.Join([]string{optName, optCron, optEvery, optOffset, optConcurrency, optRetry, optTriggerStart, optTriggerStop}, ", ")
 		return fmt.Errorf("unknown task option(s): %s. valid options are %s", u, v)
 	}
 
@@ -146,7 +146,7 @@ func TestFromScriptWithUnknownOptions(t *testing.T) {
 		t.Errorf("expected error to mention unrecognized options, but it said: %v", err)
 	}
 
-	validOpts := []string{"name", "cron", "every", "offset", "concurrency", "retry"}
+	validOpts := []string{"name", "cron", "every", "offset", "concurrency", "retry", "triggerStart", "triggerStop"}
 	for _, o := range validOpts {
 		if !strings.Contains(msg, o) {
 			t.Errorf("expected error to mention valid option %q but it said: %v", o, err)
@@ -154,6 +154,39 @@ func TestFromScriptWithUnknownOptions(t *testing.T) {
 	}
 }
 
+func TestWithTriggerRange(t *testing.T) {
+	script := `option task = {name: "x", every: 1h, triggerStart: -1h, triggerStop: 0s}
+from(bucket: "b") |> range(start: task.triggerStart, stop: task.triggerStop)`
+	if _, err := options.FromScript(script); err != nil {
+		t.Fatal(err)
+	}
+
+	start, stop := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 1, 1, 0, 5, 0, 0, time.UTC)
+	got, err := options.WithTriggerRange(script, start, stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "triggerStart: 2020-01-01T00:00:00Z") || !strings.Contains(got, "triggerStop: 2020-01-01T00:05:00Z") {
+		t.Fatalf("expected the trigger range in the task option, got:\n%s", got)
+	}
+	if _, err := options.FromScript(got); err != nil {
+		t.Fatal(err)
+	}
+
+	// the range is added to a task option without it
+	got, err = options.WithTriggerRange(`option task = {name: "x", every: 1h} from(bucket: "b") |> range(start: -1h)`, start, stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, "triggerStop: 2020-01-01T00:05:00Z") {
+		t.Fatalf("expected the trigger range in the task option, got:\n%s", got)
+	}
+
+	if _, err := options.WithTriggerRange(`from(bucket: "b") |> range(start: -1h)`, start, stop); err == nil {
+		t.Fatal("expected an error for a script without task option")
+	}
+}
+
 func TestValidate(t *testing.T) {
 	good := options.Options{Name: "x", Cron: "* * * * *", Concurrency: pointer.Int64(1), Retry: pointer.Int64(1)}
 	if err := good.Validate(); err != nil {
@@ -98,6 +98,11 @@ func TestTaskService(t *testing.T, fn BackendComponentFactory, testCategory ...s
 					testTaskType(t, sys)
 				})
 
+				t.Run("Task Dependencies", func(t *testing.T) {
+					t.Parallel()
+					testTaskDependencies(t, sys)
+				})
+
 			})
 		case "analytical":
 			t.Run("AnalyticalTaskService", func(t *testing.T) {
@@ -105,6 +110,10 @@ func TestTaskService(t *testing.T, fn BackendComponentFactory, testCategory ...s
 					t.Parallel()
 					testRunStorage(t, sys)
 				})
+				t.Run("Task Run Paging", func(t *testing.T) {
+					t.Parallel()
+					testRunPaging(t, sys)
+				})
 				t.Run("Task RetryRun", func(t *testing.T) {
 					t.Parallel()
 					testRetryAcrossStorage(t, sys)
@@ -1316,6 +1325,73 @@ func testManualRun(t *testing.T, s *System) {
 	}
 }
 
+func testRunPaging(t *testing.T, sys *System) {
+	cr := creds(t, sys)
+
+	ct := influxdb.TaskCreate{
+		OrganizationID: cr.OrgID,
+		Flux:           fmt.Sprintf(scriptFmt, 0),
+		OwnerID:        cr.UserID,
+	}
+	task, err := sys.TaskService.CreateTask(icontext.SetAuthorizer(sys.Ctx, cr.Authorizer()), ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the runs are created from the least to the most recently scheduled,
+	// and every other one of them completes.
+	now := time.Now().UTC().Truncate(time.Second)
+	var expected []influxdb.ID
+	for i := 4; i > 0; i-- {
+		scheduledFor := now.Add(-time.Duration(i) * time.Minute)
+		run, err := sys.TaskControlService.CreateRun(sys.Ctx, task.ID, scheduledFor, scheduledFor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sys.TaskControlService.UpdateRunState(sys.Ctx, task.ID, run.ID, now.Add(-time.Duration(i)*time.Second), influxdb.RunStarted); err != nil {
+			t.Fatal(err)
+		}
+		if i%2 == 0 {
+			if err := sys.TaskControlService.UpdateRunState(sys.Ctx, task.ID, run.ID, now, influxdb.RunSuccess); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := sys.TaskControlService.FinishRun(sys.Ctx, task.ID, run.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+		expected = append([]influxdb.ID{run.ID}, expected...)
+	}
+
+	for _, limit := range []int{1, 3} {
+		var got []influxdb.ID
+		cursor := &influxdb.RunCursor{}
+		for {
+			runs, _, err := sys.TaskService.FindRuns(sys.Ctx, influxdb.RunFilter{Task: task.ID, Limit: limit, Cursor: cursor})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(runs) > limit {
+				t.Fatalf("expected at most %d runs, got %d", limit, len(runs))
+			}
+			if len(runs) == 0 {
+				break
+			}
+			for _, r := range runs {
+				got = append(got, r.ID)
+			}
+			cursor = influxdb.NewRunCursor(runs[len(runs)-1])
+		}
+
+		if diff := cmp.Diff(expected, got); diff != "" {
+			t.Fatalf("unexpected runs paging with limit %d: %s", limit, diff)
+		}
+	}
+
+	if _, err := influxdb.ParseRunCursor("not a cursor"); err != influxdb.ErrInvalidRunCursor {
+		t.Fatalf("expected invalid run cursor, got %v", err)
+	}
+}
+
 func testRunStorage(t *testing.T, sys *System) {
 	cr := creds(t, sys)
 
@@ -1824,3 +1900,89 @@ func testTaskType(t *testing.T, sys *System) {
 		t.Fatalf("failed to return tasks with wildcard, expected 3, got %d", len(tasks))
 	}
 }
+
+func testTaskDependencies(t *testing.T, sys *System) {
+	cr := creds(t, sys)
+	authorizedCtx := icontext.SetAuthorizer(sys.Ctx, cr.Authorizer())
+
+	create := func(upstream ...influxdb.ID) (*influxdb.Task, error) {
+		return sys.TaskService.CreateTask(authorizedCtx, influxdb.TaskCreate{
+			OrganizationID: cr.OrgID,
+			Flux:           fmt.Sprintf(scriptFmt, 0),
+			OwnerID:        cr.UserID,
+			Upstream:       upstream,
+		})
+	}
+
+	a, err := create()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := create(a.ID, a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(b.Upstream, []influxdb.ID{a.ID}) {
+		t.Fatalf("expected upstream %v, got %v", []influxdb.ID{a.ID}, b.Upstream)
+	}
+	c, err := create(b.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := sys.TaskService.FindTaskByID(authorizedCtx, c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(found.Upstream, []influxdb.ID{b.ID}) {
+		t.Fatalf("expected upstream %v, got %v", []influxdb.ID{b.ID}, found.Upstream)
+	}
+
+	if _, err := create(influxdb.ID(1)); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid upstream error, got %v", err)
+	}
+
+	// a -> b -> c -> a is a cycle
+	upstream := []influxdb.ID{c.ID}
+	if _, err := sys.TaskService.UpdateTask(authorizedCtx, a.ID, influxdb.TaskUpdate{Upstream: &upstream}); err != influxdb.ErrTaskDependencyCycle {
+		t.Fatalf("expected dependency cycle error, got %v", err)
+	}
+	upstream = []influxdb.ID{a.ID}
+	if _, err := sys.TaskService.UpdateTask(authorizedCtx, a.ID, influxdb.TaskUpdate{Upstream: &upstream}); err != influxdb.ErrTaskDependencyCycle {
+		t.Fatalf("expected dependency cycle error, got %v", err)
+	}
+
+	downstream, _, err := sys.TaskService.FindTasks(authorizedCtx, influxdb.TaskFilter{OrganizationID: &cr.OrgID, Upstream: &a.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(downstream) != 1 || downstream[0].ID != b.ID {
+		t.Fatalf("expected task %s downstream of %s, got %v", b.ID, a.ID, downstream)
+	}
+
+	if err := sys.TaskService.DeleteTask(authorizedCtx, a.ID); err != influxdb.ErrTaskHasDownstream {
+		t.Fatalf("expected task has downstream error, got %v", err)
+	}
+
+	upstream = []influxdb.ID{}
+	b, err = sys.TaskService.UpdateTask(authorizedCtx, b.ID, influxdb.TaskUpdate{Upstream: &upstream})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Upstream) != 0 {
+		t.Fatalf("expected no upstream, got %v", b.Upstream)
+	}
+	if err := sys.TaskService.DeleteTask(authorizedCtx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	// deleting the last task downstream of b lets b be deleted
+	if err := sys.TaskService.DeleteTask(authorizedCtx, b.ID); err != influxdb.ErrTaskHasDownstream {
+		t.Fatalf("expected task has downstream error, got %v", err)
+	}
+	if err := sys.TaskService.DeleteTask(authorizedCtx, c.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := sys.TaskService.DeleteTask(authorizedCtx, b.ID); err != nil {
+		t.Fatal(err)
+	}
+}
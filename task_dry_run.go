@@ -0,0 +1,51 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/flux"
+)
+
+// TaskDryRunRequest is the set of values to execute a task once with, without
+// writing to storage.
+type TaskDryRunRequest struct {
+	// ScheduledFor is the time the task is executed for, which is the value of
+	// now() in its Flux. It defaults to the current time.
+	ScheduledFor time.Time `json:"scheduledFor,omitempty"`
+
+	// Flux replaces the Flux of the task, to try out a change before saving it.
+	Flux *string `json:"flux,omitempty"`
+}
+
+// TaskDryRun is the outcome of executing a task once with the writes of its
+// to() calls returned instead of written to storage.
+type TaskDryRun struct {
+	TaskID       ID        `json:"taskID"`
+	ScheduledFor time.Time `json:"scheduledFor"`
+
+	// Results are the tables yielded by the task, as annotated CSV.
+	Results string `json:"results"`
+
+	// Writes are the points the task would have written, by bucket.
+	Writes []TaskDryRunWrite `json:"writes"`
+
+	// Plan is the physical plan the task was executed with.
+	Plan       string          `json:"plan"`
+	Statistics flux.Statistics `json:"statistics"`
+}
+
+// TaskDryRunWrite are the points a task would have written to a bucket.
+type TaskDryRunWrite struct {
+	BucketID ID `json:"bucketID"`
+
+	// Points are the points, as line protocol.
+	Points string `json:"points"`
+}
+
+// TaskDryRunService executes tasks without writing to storage.
+type TaskDryRunService interface {
+	// DryRunTask executes a task once for a chosen time, and returns the
+	// tables it yields and the points its to() calls would have written.
+	DryRunTask(ctx context.Context, taskID ID, req TaskDryRunRequest) (*TaskDryRun, error)
+}
@@ -47,6 +47,18 @@ var (
 		Msg:  "run not found",
 	}
 
+	// ErrBackfillNotFound is returned when searching for a single backfill that doesn't exist.
+	ErrBackfillNotFound = &Error{
+		Code: ENotFound,
+		Msg:  "backfill not found",
+	}
+
+	// ErrRunNotFailed is returned when retrying a run that has not failed.
+	ErrRunNotFailed = &Error{
+		Code: EConflict,
+		Msg:  "run has not failed",
+	}
+
 	ErrRunKeyNotFound = &Error{
 		Code: ENotFound,
 		Msg:  "run key not found",
@@ -78,11 +90,65 @@ var (
 		Msg:  "run limit is out of bounds, must be between 1 and 500",
 	}
 
+	// ErrInvalidRunCursor is returned when FindRuns is called with a cursor that cannot be parsed.
+	ErrInvalidRunCursor = &Error{
+		Code: EInvalid,
+		Msg:  "invalid run cursor",
+	}
+
 	// ErrInvalidOwnerID is called when trying to create a task with out a valid ownerID
 	ErrInvalidOwnerID = &Error{
 		Code: EInvalid,
 		Msg:  "cannot create task with invalid ownerID",
 	}
+
+	// ErrTaskDependencyCycle is returned when the upstream tasks of a task would make it depend on itself.
+	ErrTaskDependencyCycle = &Error{
+		Code: EInvalid,
+		Msg:  "task dependencies would form a cycle",
+	}
+
+	// ErrTaskLeaseHeld is returned when acquiring the lease on a task that another scheduler holds.
+	ErrTaskLeaseHeld = &Error{
+		Code: EConflict,
+		Msg:  "task is leased by another scheduler",
+	}
+
+	// ErrTaskLeaseLost is returned when a scheduler records a run of a task it no longer holds the lease on.
+	ErrTaskLeaseLost = &Error{
+		Code: EConflict,
+		Msg:  "task lease was lost to another scheduler",
+	}
+
+	// ErrTaskLeaseNotFound is returned when a task has never been leased.
+	ErrTaskLeaseNotFound = &Error{
+		Code: ENotFound,
+		Msg:  "task lease not found",
+	}
+
+	// ErrTaskHasDownstream is returned when deleting a task that other tasks depend on.
+	ErrTaskHasDownstream = &Error{
+		Code: EConflict,
+		Msg:  "task is upstream of other tasks",
+	}
+
+	// ErrTaskTemplateNotFound is returned when a task template is not found.
+	ErrTaskTemplateNotFound = &Error{
+		Code: ENotFound,
+		Msg:  "task template not found",
+	}
+
+	// ErrTaskTemplateInUse is returned when deleting a task template that tasks are instantiated from.
+	ErrTaskTemplateInUse = &Error{
+		Code: EConflict,
+		Msg:  "task template is used by tasks",
+	}
+
+	// ErrTaskNotFromTemplate is returned when setting the template parameters of a task that was not instantiated from a template.
+	ErrTaskNotFromTemplate = &Error{
+		Code: EInvalid,
+		Msg:  "task is not instantiated from a template",
+	}
 )
 
 // ErrFluxParseError is returned when an error is thrown by Flux.Parse in the task executor
@@ -178,3 +244,55 @@ func ErrTaskConcurrencyLimitReached(runsInFront int) *Error {
 		Op:   "taskExecutor",
 	}
 }
+
+// ErrInvalidUpstreamTask is returned when a task declares an upstream task that
+// does not exist or belongs to another organization.
+func ErrInvalidUpstreamTask(id ID) *Error {
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("invalid upstream task %s", id),
+		Op:   "task",
+	}
+}
+
+// ErrInvalidTaskNotificationEndpoint is returned when a task notifies a
+// notification endpoint that does not exist or belongs to another organization.
+func ErrInvalidTaskNotificationEndpoint(id ID) *Error {
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("invalid notification endpoint %s", id),
+		Op:   "task",
+	}
+}
+
+// ErrInvalidTaskTriggerBucket is returned when a task is triggered by writes to
+// a bucket that does not exist or belongs to another organization.
+func ErrInvalidTaskTriggerBucket(id ID) *Error {
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("invalid trigger bucket %s", id),
+		Op:   "task",
+	}
+}
+
+// ErrInvalidTaskTemplate is returned when a task template declares invalid
+// parameters, or its Flux cannot be parsed.
+func ErrInvalidTaskTemplate(err error) *Error {
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("invalid task template; Err: %v", err),
+		Op:   "taskTemplate",
+		Err:  err,
+	}
+}
+
+// ErrTaskTemplateRender is returned when a task cannot be rendered from a
+// task template with the values of its parameters.
+func ErrTaskTemplateRender(err error) *Error {
+	return &Error{
+		Code: EInvalid,
+		Msg:  fmt.Sprintf("could not render task template; Err: %v", err),
+		Op:   "taskTemplate",
+		Err:  err,
+	}
+}
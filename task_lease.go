@@ -0,0 +1,39 @@
+package influxdb
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultTaskLeaseTTL is how long a scheduler owns a task after it last
+// acquired or renewed its lease.
+const DefaultTaskLeaseTTL = 30 * time.Second
+
+// TaskLease is the exclusive, expiring ownership of a task by one of the
+// schedulers that share a store.
+type TaskLease struct {
+	TaskID  ID        `json:"taskID"`
+	Owner   string    `json:"owner"`
+	Token   uint64    `json:"token"` // Token increases every time the task changes owner, and fences out stale owners
+	Expires time.Time `json:"expires"`
+}
+
+// Expired reports whether the lease has expired at t.
+func (l *TaskLease) Expired(t time.Time) bool {
+	return !t.Before(l.Expires)
+}
+
+// TaskLeaseService hands out leases on tasks, so that several schedulers can
+// share the tasks of a store without running them more than once.
+type TaskLeaseService interface {
+	// AcquireTaskLease acquires, or renews, the lease of owner on a task for ttl.
+	// It returns ErrTaskLeaseHeld while another owner holds an unexpired lease.
+	AcquireTaskLease(ctx context.Context, taskID ID, owner string, ttl time.Duration) (*TaskLease, error)
+
+	// ReleaseTaskLease gives up the lease of owner on a task, so that another
+	// owner can acquire it without waiting for it to expire.
+	ReleaseTaskLease(ctx context.Context, taskID ID, owner string) error
+
+	// FindTaskLease returns the current lease on a task.
+	FindTaskLease(ctx context.Context, taskID ID) (*TaskLease, error)
+}
@@ -0,0 +1,371 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/parser"
+)
+
+// TaskTemplateParamsIdent is the identifier the Flux of a task template refers
+// to its parameters with, as in params.bucket.
+const TaskTemplateParamsIdent = "params"
+
+// TaskTemplateParamType is the type of the value of a task template parameter.
+type TaskTemplateParamType string
+
+// The types of task template parameters.
+const (
+	TaskTemplateParamString   TaskTemplateParamType = "string"
+	TaskTemplateParamInt      TaskTemplateParamType = "int"
+	TaskTemplateParamFloat    TaskTemplateParamType = "float"
+	TaskTemplateParamBool     TaskTemplateParamType = "bool"
+	TaskTemplateParamDuration TaskTemplateParamType = "duration"
+	TaskTemplateParamTime     TaskTemplateParamType = "time"
+)
+
+var taskTemplateParamName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// TaskTemplateParam is a typed parameter declared by a task template.
+type TaskTemplateParam struct {
+	Name        string                `json:"name"`
+	Type        TaskTemplateParamType `json:"type"`
+	Description string                `json:"description,omitempty"`
+
+	// Default is the value of the parameter when a task does not set it.
+	// A parameter without a default is required.
+	Default interface{} `json:"default,omitempty"`
+}
+
+// Valid returns an error if the parameter is not valid.
+func (p TaskTemplateParam) Valid() error {
+	if !taskTemplateParamName.MatchString(p.Name) {
+		return fmt.Errorf("invalid parameter name %q", p.Name)
+	}
+	switch p.Type {
+	case TaskTemplateParamString, TaskTemplateParamInt, TaskTemplateParamFloat,
+		TaskTemplateParamBool, TaskTemplateParamDuration, TaskTemplateParamTime:
+	default:
+		return fmt.Errorf("invalid type %q of parameter %q", p.Type, p.Name)
+	}
+	if p.Default != nil {
+		if _, err := p.literal(p.Default); err != nil {
+			return fmt.Errorf("invalid default of parameter %q: %v", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// literal converts a value of the parameter to a Flux literal. Values may be
+// given as strings for every type, so that they can be set from the command line.
+func (p TaskTemplateParam) literal(v interface{}) (ast.Expression, error) {
+	switch p.Type {
+	case TaskTemplateParamString:
+		if s, ok := v.(string); ok {
+			return &ast.StringLiteral{Value: s}, nil
+		}
+	case TaskTemplateParamInt:
+		switch v := v.(type) {
+		case int:
+			return &ast.IntegerLiteral{Value: int64(v)}, nil
+		case int64:
+			return &ast.IntegerLiteral{Value: v}, nil
+		case float64:
+			if v == float64(int64(v)) {
+				return &ast.IntegerLiteral{Value: int64(v)}, nil
+			}
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				return &ast.IntegerLiteral{Value: i}, nil
+			}
+		case string:
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return &ast.IntegerLiteral{Value: i}, nil
+			}
+		}
+	case TaskTemplateParamFloat:
+		switch v := v.(type) {
+		case int:
+			return &ast.FloatLiteral{Value: float64(v)}, nil
+		case int64:
+			return &ast.FloatLiteral{Value: float64(v)}, nil
+		case float64:
+			return &ast.FloatLiteral{Value: v}, nil
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				return &ast.FloatLiteral{Value: f}, nil
+			}
+		case string:
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return &ast.FloatLiteral{Value: f}, nil
+			}
+		}
+	case TaskTemplateParamBool:
+		switch v := v.(type) {
+		case bool:
+			return &ast.BooleanLiteral{Value: v}, nil
+		case string:
+			if b, err := strconv.ParseBool(v); err == nil {
+				return &ast.BooleanLiteral{Value: b}, nil
+			}
+		}
+	case TaskTemplateParamDuration:
+		if s, ok := v.(string); ok {
+			if lit, err := parser.ParseSignedDuration(s); err == nil {
+				return lit, nil
+			}
+		}
+	case TaskTemplateParamTime:
+		switch v := v.(type) {
+		case time.Time:
+			return &ast.DateTimeLiteral{Value: v}, nil
+		case string:
+			if lit, err := parser.ParseTime(v); err == nil {
+				return lit, nil
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid type %q", p.Type)
+	}
+	return nil, fmt.Errorf("%v is not a valid %s", v, p.Type)
+}
+
+// TaskTemplate is Flux shared by many tasks, which differ only by the values
+// of the parameters the template declares. The Flux refers to the value of a
+// parameter as params.<name>, and every task instantiated from the template
+// is rendered again when the template changes.
+type TaskTemplate struct {
+	ID             ID                  `json:"id"`
+	OrganizationID ID                  `json:"orgID"`
+	Name           string              `json:"name"`
+	Description    string              `json:"description,omitempty"`
+	Flux           string              `json:"flux"`
+	Params         []TaskTemplateParam `json:"params,omitempty"`
+	CreatedAt      time.Time           `json:"createdAt,omitempty"`
+	UpdatedAt      time.Time           `json:"updatedAt,omitempty"`
+}
+
+// Valid returns an error if the template is not valid: its parameters must be
+// valid and unique, and its Flux must only refer to parameters it declares.
+func (t *TaskTemplate) Valid() error {
+	if t.Name == "" {
+		return &Error{Code: EInvalid, Msg: "task template name is empty"}
+	}
+
+	seen := make(map[string]bool, len(t.Params))
+	for _, p := range t.Params {
+		if err := p.Valid(); err != nil {
+			return ErrInvalidTaskTemplate(err)
+		}
+		if seen[p.Name] {
+			return ErrInvalidTaskTemplate(fmt.Errorf("parameter %q is declared more than once", p.Name))
+		}
+		seen[p.Name] = true
+	}
+
+	file, err := parseTemplateFlux(t.Flux)
+	if err != nil {
+		return ErrInvalidTaskTemplate(err)
+	}
+	err = replaceTemplateParams(reflect.ValueOf(file), func(name string) (ast.Expression, error) {
+		if !seen[name] {
+			return nil, fmt.Errorf("undeclared parameter %q", name)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return ErrInvalidTaskTemplate(err)
+	}
+	return nil
+}
+
+// Render returns the Flux of a task instantiated from the template with the
+// given values of its parameters. Parameters without a value take their default.
+func (t *TaskTemplate) Render(values map[string]interface{}) (string, error) {
+	params := make(map[string]TaskTemplateParam, len(t.Params))
+	for _, p := range t.Params {
+		params[p.Name] = p
+	}
+	for name := range values {
+		if _, ok := params[name]; !ok {
+			return "", ErrTaskTemplateRender(fmt.Errorf("unknown parameter %q", name))
+		}
+	}
+
+	literals := make(map[string]ast.Expression, len(params))
+	for _, p := range t.Params {
+		name := p.Name
+		v, ok := values[name]
+		if !ok || v == nil {
+			v = p.Default
+		}
+		if v == nil {
+			return "", ErrTaskTemplateRender(fmt.Errorf("missing value of parameter %q", name))
+		}
+		lit, err := p.literal(v)
+		if err != nil {
+			return "", ErrTaskTemplateRender(fmt.Errorf("invalid value of parameter %q: %v", name, err))
+		}
+		literals[name] = lit
+	}
+
+	file, err := parseTemplateFlux(t.Flux)
+	if err != nil {
+		return "", ErrTaskTemplateRender(err)
+	}
+	err = replaceTemplateParams(reflect.ValueOf(file), func(name string) (ast.Expression, error) {
+		lit, ok := literals[name]
+		if !ok {
+			return nil, fmt.Errorf("undeclared parameter %q", name)
+		}
+		return lit.Copy().(ast.Expression), nil
+	})
+	if err != nil {
+		return "", ErrTaskTemplateRender(err)
+	}
+	return ast.Format(file), nil
+}
+
+func parseTemplateFlux(flux string) (*ast.File, error) {
+	pkg, err := safeParseSource(flux)
+	if err != nil {
+		return nil, err
+	}
+	if ast.Check(pkg) > 0 {
+		return nil, ast.GetError(pkg)
+	}
+	if len(pkg.Files) != 1 {
+		return nil, errors.New("expected a single file of flux")
+	}
+	return pkg.Files[0], nil
+}
+
+var (
+	astExpressionType = reflect.TypeOf((*ast.Expression)(nil)).Elem()
+	astPkgPath        = reflect.TypeOf(ast.File{}).PkgPath()
+)
+
+// replaceTemplateParams walks the AST below v and calls replace with the name
+// of every parameter that is referred to. References are replaced by the
+// expression replace returns, unless it is nil.
+func replaceTemplateParams(v reflect.Value, replace func(name string) (ast.Expression, error)) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return replaceTemplateParams(v.Elem(), replace)
+	case reflect.Interface:
+		if v.IsNil() || !v.CanInterface() {
+			return nil
+		}
+		if m, ok := v.Interface().(*ast.MemberExpression); ok {
+			if id, ok := m.Object.(*ast.Identifier); ok && id.Name == TaskTemplateParamsIdent {
+				expr, err := replace(m.Property.Key())
+				if err != nil {
+					return err
+				}
+				if expr == nil {
+					return nil
+				}
+				if !v.CanSet() || v.Type() != astExpressionType {
+					return fmt.Errorf("parameter %q cannot be used at %s", m.Property.Key(), m.Location())
+				}
+				v.Set(reflect.ValueOf(expr))
+				return nil
+			}
+		}
+		return replaceTemplateParams(v.Elem(), replace)
+	case reflect.Struct:
+		// only nodes of the AST can refer to parameters
+		if v.Type().PkgPath() != astPkgPath {
+			return nil
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if err := replaceTemplateParams(v.Field(i), replace); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := replaceTemplateParams(v.Index(i), replace); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// TaskTemplateUpdate is the set of changes to a task template.
+type TaskTemplateUpdate struct {
+	Name        *string              `json:"name,omitempty"`
+	Description *string              `json:"description,omitempty"`
+	Flux        *string              `json:"flux,omitempty"`
+	Params      *[]TaskTemplateParam `json:"params,omitempty"`
+}
+
+// Apply applies the update to a task template.
+func (u TaskTemplateUpdate) Apply(t *TaskTemplate) {
+	if u.Name != nil {
+		t.Name = *u.Name
+	}
+	if u.Description != nil {
+		t.Description = *u.Description
+	}
+	if u.Flux != nil {
+		t.Flux = *u.Flux
+	}
+	if u.Params != nil {
+		t.Params = *u.Params
+	}
+}
+
+// TaskTemplateFilter represents a set of filters that restrict the returned task templates.
+type TaskTemplateFilter struct {
+	OrganizationID *ID
+	Name           *string
+}
+
+// TaskTemplateDiff is the change of the Flux of a task instantiated from a
+// template that an update of the template makes.
+type TaskTemplateDiff struct {
+	TaskID   ID     `json:"taskID"`
+	TaskName string `json:"taskName"`
+	OldFlux  string `json:"oldFlux"`
+	NewFlux  string `json:"newFlux,omitempty"`
+
+	// Error is why the task cannot be rendered from the updated template.
+	Error string `json:"error,omitempty"`
+}
+
+// TaskTemplateService represents a service for managing task templates.
+type TaskTemplateService interface {
+	// FindTaskTemplateByID returns a single task template.
+	FindTaskTemplateByID(ctx context.Context, id ID) (*TaskTemplate, error)
+
+	// FindTaskTemplates returns the task templates that match a filter and their count.
+	FindTaskTemplates(ctx context.Context, filter TaskTemplateFilter) ([]*TaskTemplate, int, error)
+
+	// CreateTaskTemplate creates a new task template and sets t.ID with the new identifier.
+	CreateTaskTemplate(ctx context.Context, t *TaskTemplate) error
+
+	// UpdateTaskTemplate updates a task template and renders the tasks
+	// instantiated from it again. Nothing is updated if any of the tasks
+	// cannot be rendered from the updated template.
+	UpdateTaskTemplate(ctx context.Context, id ID, upd TaskTemplateUpdate) (*TaskTemplate, error)
+
+	// PreviewTaskTemplateUpdate returns how an update of a task template would
+	// change the tasks instantiated from it, without updating anything.
+	PreviewTaskTemplateUpdate(ctx context.Context, id ID, upd TaskTemplateUpdate) ([]*TaskTemplateDiff, error)
+
+	// DeleteTaskTemplate removes a task template. Templates that tasks are
+	// instantiated from cannot be removed.
+	DeleteTaskTemplate(ctx context.Context, id ID) error
+}
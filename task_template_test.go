@@ -0,0 +1,154 @@
+package influxdb_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/task/options"
+)
+
+func TestTaskTemplate_Valid(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    influxdb.TaskTemplate
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			tmpl: influxdb.TaskTemplate{
+				Name: "downsample",
+				Flux: `option task = {name: params.name, every: params.every}
+from(bucket: params.bucket) |> range(start: -params.every)`,
+				Params: []influxdb.TaskTemplateParam{
+					{Name: "name", Type: influxdb.TaskTemplateParamString},
+					{Name: "every", Type: influxdb.TaskTemplateParamDuration, Default: "1h"},
+					{Name: "bucket", Type: influxdb.TaskTemplateParamString},
+				},
+			},
+		},
+		{
+			name: "missing name",
+			tmpl: influxdb.TaskTemplate{
+				Flux: `from(bucket: "b") |> range(start: -1h)`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid flux",
+			tmpl: influxdb.TaskTemplate{
+				Name: "downsample",
+				Flux: `from(bucket: `,
+			},
+			wantErr: true,
+		},
+		{
+			name: "undeclared parameter",
+			tmpl: influxdb.TaskTemplate{
+				Name: "downsample",
+				Flux: `from(bucket: params.bucket) |> range(start: -1h)`,
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate parameter",
+			tmpl: influxdb.TaskTemplate{
+				Name: "downsample",
+				Flux: `from(bucket: params.bucket) |> range(start: -1h)`,
+				Params: []influxdb.TaskTemplateParam{
+					{Name: "bucket", Type: influxdb.TaskTemplateParamString},
+					{Name: "bucket", Type: influxdb.TaskTemplateParamString},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid parameter type",
+			tmpl: influxdb.TaskTemplate{
+				Name: "downsample",
+				Flux: `from(bucket: params.bucket) |> range(start: -1h)`,
+				Params: []influxdb.TaskTemplateParam{
+					{Name: "bucket", Type: "table"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "default of the wrong type",
+			tmpl: influxdb.TaskTemplate{
+				Name: "downsample",
+				Flux: `from(bucket: "b") |> range(start: -params.every)`,
+				Params: []influxdb.TaskTemplateParam{
+					{Name: "every", Type: influxdb.TaskTemplateParamDuration, Default: "an hour"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tmpl.Valid()
+			if tt.wantErr && err == nil {
+				t.Fatal("expected an error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestTaskTemplate_Render(t *testing.T) {
+	tmpl := influxdb.TaskTemplate{
+		Name: "downsample",
+		Flux: `option task = {name: params.name, every: params.every}
+
+from(bucket: params.bucket)
+	|> range(start: -params.every)
+	|> filter(fn: (r) => r._value > params.threshold and params.enabled)`,
+		Params: []influxdb.TaskTemplateParam{
+			{Name: "name", Type: influxdb.TaskTemplateParamString},
+			{Name: "every", Type: influxdb.TaskTemplateParamDuration, Default: "1h"},
+			{Name: "bucket", Type: influxdb.TaskTemplateParamString},
+			{Name: "threshold", Type: influxdb.TaskTemplateParamFloat, Default: 0.5},
+			{Name: "enabled", Type: influxdb.TaskTemplateParamBool, Default: true},
+		},
+	}
+
+	flux, err := tmpl.Render(map[string]interface{}{
+		"name":      "cpu",
+		"bucket":    "telegraf",
+		"threshold": "90",
+		"every":     "10m",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"cpu"`, `bucket: "telegraf"`, `-10m`, `90.0`, `true`} {
+		if !strings.Contains(flux, want) {
+			t.Fatalf("expected rendered flux to contain %s, got:\n%s", want, flux)
+		}
+	}
+	if strings.Contains(flux, "params.") {
+		t.Fatalf("expected every parameter to be replaced, got:\n%s", flux)
+	}
+
+	opts, err := options.FromScript(flux)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.Name != "cpu" || opts.Every.String() != "10m" {
+		t.Fatalf("unexpected options %+v", opts)
+	}
+
+	if _, err := tmpl.Render(map[string]interface{}{"name": "cpu"}); err == nil {
+		t.Fatal("expected an error for a missing required parameter")
+	}
+	if _, err := tmpl.Render(map[string]interface{}{"name": "cpu", "bucket": "b", "other": 1}); err == nil {
+		t.Fatal("expected an error for an unknown parameter")
+	}
+	if _, err := tmpl.Render(map[string]interface{}{"name": "cpu", "bucket": 1}); err == nil {
+		t.Fatal("expected an error for a parameter of the wrong type")
+	}
+}
@@ -381,6 +381,14 @@ func (s *Store) UpdateBucket(ctx context.Context, tx kv.Tx, id influxdb.ID, upd
 		bucket.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.StorageSettings != nil {
+		bucket.StorageSettings = nil
+		if !upd.StorageSettings.IsZero() {
+			settings := *upd.StorageSettings
+			bucket.StorageSettings = &settings
+		}
+	}
+
 	v, err := marshalBucket(bucket)
 	if err != nil {
 		return nil, err
@@ -3,6 +3,7 @@ package testing
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 	"time"
@@ -314,6 +315,149 @@ func Migrator(t *testing.T, store kv.Store, newMigrator func(*zap.Logger, NowFun
 		migrationFour.assertUpCalled(t, 2)
 	})
 
+	t.Run("PlanDown() lists the migrations after the named migration", func(t *testing.T) {
+		migrations, err := migrator.PlanDown(ctx, store, "migration two")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := []string{"migration four (up)", "migration three (up)"}; !reflect.DeepEqual(expected, migrationStates(migrations)) {
+			t.Errorf("expected %v, found %v", expected, migrationStates(migrations))
+		}
+
+		// assert nothing was undone
+		migrationThree.assertDownCalled(t, 1)
+		migrationFour.assertDownCalled(t, 1)
+	})
+
+	t.Run("DownTo() undoes the migrations after the named migration", func(t *testing.T) {
+		if err := migrator.DownTo(ctx, store, "migration two"); err != nil {
+			t.Fatal(err)
+		}
+
+		migrations, err := migrator.List(ctx, store)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := []string{
+			"migration one (up)",
+			"migration two (up)",
+			"migration three (down)",
+			"migration four (down)",
+		}; !reflect.DeepEqual(expected, migrationStates(migrations)) {
+			t.Errorf("expected %v, found %v", expected, migrationStates(migrations))
+		}
+
+		migrationOne.assertDownCalled(t, 1)
+		migrationTwo.assertDownCalled(t, 1)
+		migrationThree.assertDownCalled(t, 2)
+		migrationFour.assertDownCalled(t, 2)
+	})
+
+	t.Run("PlanUp() lists the outstanding migrations up to the named migration", func(t *testing.T) {
+		migrations, err := migrator.PlanUp(ctx, store, "migration three")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := []string{"migration three (down)"}; !reflect.DeepEqual(expected, migrationStates(migrations)) {
+			t.Errorf("expected %v, found %v", expected, migrationStates(migrations))
+		}
+
+		// assert nothing was applied
+		migrationThree.assertUpCalled(t, 2)
+	})
+
+	t.Run("UpTo() applies the outstanding migrations up to the named migration", func(t *testing.T) {
+		if err := migrator.UpTo(ctx, store, "migration three"); err != nil {
+			t.Fatal(err)
+		}
+
+		migrations, err := migrator.List(ctx, store)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := []string{
+			"migration one (up)",
+			"migration two (up)",
+			"migration three (up)",
+			"migration four (down)",
+		}; !reflect.DeepEqual(expected, migrationStates(migrations)) {
+			t.Errorf("expected %v, found %v", expected, migrationStates(migrations))
+		}
+
+		migrationThree.assertUpCalled(t, 3)
+		migrationFour.assertUpCalled(t, 2)
+	})
+
+	t.Run("UpTo() and DownTo() unknown migration errors as expected", func(t *testing.T) {
+		if err := migrator.UpTo(ctx, store, "migration five"); !errors.Is(err, kv.ErrMigrationNotFound) {
+			t.Errorf("expected migration not found error, found %v", err)
+		}
+
+		if err := migrator.DownTo(ctx, store, "migration five"); !errors.Is(err, kv.ErrMigrationNotFound) {
+			t.Errorf("expected migration not found error, found %v", err)
+		}
+	})
+
+	t.Run("Up() applies the remaining migrations", func(t *testing.T) {
+		if err := migrator.Up(ctx, store); err != nil {
+			t.Fatal(err)
+		}
+
+		migrations, err := migrator.List(ctx, store)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := []string{
+			"migration one (up)",
+			"migration two (up)",
+			"migration three (up)",
+			"migration four (up)",
+		}; !reflect.DeepEqual(expected, migrationStates(migrations)) {
+			t.Errorf("expected %v, found %v", expected, migrationStates(migrations))
+		}
+
+		migrationThree.assertUpCalled(t, 3)
+		migrationFour.assertUpCalled(t, 3)
+	})
+
+	t.Run("DownTo() past an irreversible migration undoes nothing", func(t *testing.T) {
+		migrator.AddMigrations(kv.NewIrreversibleMigration("migration five", func(context.Context, kv.Store) error {
+			return nil
+		}))
+		if err := migrator.Up(ctx, store); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := migrator.PlanDown(ctx, store, "migration three"); !errors.Is(err, kv.ErrMigrationIrreversible) {
+			t.Errorf("expected migration irreversible error, found %v", err)
+		}
+		if err := migrator.DownTo(ctx, store, "migration three"); !errors.Is(err, kv.ErrMigrationIrreversible) {
+			t.Errorf("expected migration irreversible error, found %v", err)
+		}
+
+		migrations, err := migrator.List(ctx, store)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := []string{
+			"migration one (up)",
+			"migration two (up)",
+			"migration three (up)",
+			"migration four (up)",
+			"migration five (up)",
+		}; !reflect.DeepEqual(expected, migrationStates(migrations)) {
+			t.Errorf("expected %v, found %v", expected, migrationStates(migrations))
+		}
+
+		migrationFour.assertDownCalled(t, 2)
+	})
+
 	t.Run("List() missing migration spec errors as expected", func(t *testing.T) {
 		// remove last specification from migration list
 		migrator.MigrationSpecs = migrator.MigrationSpecs[:len(migrator.MigrationSpecs)-1]
@@ -325,6 +469,15 @@ func Migrator(t *testing.T, store kv.Store, newMigrator func(*zap.Logger, NowFun
 	})
 }
 
+// migrationStates returns the names and states of migrations.
+func migrationStates(migrations []kv.Migration) []string {
+	states := make([]string, 0, len(migrations))
+	for _, m := range migrations {
+		states = append(states, fmt.Sprintf("%s (%s)", m.Name, m.State))
+	}
+	return states
+}
+
 func newMigration(name string) *spyMigrationSpec {
 	return &spyMigrationSpec{name: name}
 }
@@ -602,15 +602,20 @@ func (i *Index) DropMeasurement(name []byte) error {
 	// Store results.
 	errC := make(chan error, i.PartitionN)
 
+	// The workers may still be running after the results are received, so
+	// they must not read the partitions of the index, which are replaced when
+	// it is reopened.
+	partitions := i.partitions
+
 	var pidx uint32 // Index of maximum Partition being worked on.
 	for k := 0; k < n; k++ {
 		go func() {
 			for {
 				idx := int(atomic.AddUint32(&pidx, 1) - 1) // Get next partition to work on.
-				if idx >= len(i.partitions) {
+				if idx >= len(partitions) {
 					return // No more work.
 				}
-				errC <- i.partitions[idx].DropMeasurement(name)
+				errC <- partitions[idx].DropMeasurement(name)
 			}
 		}()
 	}
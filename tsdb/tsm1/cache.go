@@ -9,6 +9,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/models"
 	"github.com/influxdata/influxdb/v2/storage/wal"
@@ -55,6 +56,17 @@ type Cache struct {
 	tracker       *cacheTracker
 	lastSnapshot  time.Time
 	lastWriteTime time.Time
+
+	// buckets tracks the size and last write time of each bucket written since
+	// the cache was created. Buckets are removed when they are deleted or have
+	// not been written for longer than any setting using them.
+	buckets map[influxdb.ID]BucketCacheStats
+}
+
+// BucketCacheStats holds the cache statistics of a bucket.
+type BucketCacheStats struct {
+	Size      uint64    // The approximate size of the bucket's data written since the last snapshot.
+	LastWrite time.Time // The last time the bucket was written to.
 }
 
 // NewCache returns an instance of a cache which will use a maximum of maxSize bytes of memory.
@@ -128,6 +140,7 @@ func (c *Cache) WriteMulti(values map[string][]Value) error {
 	c.mu.RUnlock()
 
 	var bytesWrittenErr uint64
+	bucketSizes := make(map[influxdb.ID]uint64, 1)
 
 	// We'll optimistically set size here, and then decrement it for write errors.
 	for k, v := range values {
@@ -137,10 +150,16 @@ func (c *Cache) WriteMulti(values map[string][]Value) error {
 			werr = err
 			addedSize -= uint64(Values(v).Size())
 			bytesWrittenErr += uint64(Values(v).Size())
+			continue
 		}
 
+		var size uint64
 		if newKey {
 			addedSize += uint64(len(k))
+			size += uint64(len(k))
+		}
+		if len(k) >= 16 {
+			bucketSizes[bucketIDFromKey(k)] += size + uint64(Values(v).Size())
 		}
 	}
 
@@ -160,6 +179,15 @@ func (c *Cache) WriteMulti(values map[string][]Value) error {
 
 	c.mu.Lock()
 	c.lastWriteTime = time.Now()
+	if c.buckets == nil {
+		c.buckets = make(map[influxdb.ID]BucketCacheStats, len(bucketSizes))
+	}
+	for bucket, size := range bucketSizes {
+		stats := c.buckets[bucket]
+		stats.Size += size
+		stats.LastWrite = c.lastWriteTime
+		c.buckets[bucket] = stats
+	}
 	c.mu.Unlock()
 
 	return werr
@@ -202,6 +230,10 @@ func (c *Cache) Snapshot() (*Cache, error) {
 	c.store.reset()
 	c.tracker.SetCacheSize(0)
 	c.lastSnapshot = time.Now()
+	for bucket, stats := range c.buckets {
+		stats.Size = 0
+		c.buckets[bucket] = stats
+	}
 
 	c.tracker.AddSnapshottedBytes(snapshotSize) // increment the number of bytes added to the snapshot
 	c.tracker.SetDiskBytes(0)
@@ -477,6 +509,11 @@ func (c *Cache) DeleteBucketRange(ctx context.Context, name string, min, max int
 		c.store.remove([]byte(k))
 	}
 
+	// Forget a bucket that has been deleted.
+	if pred == nil && min == math.MinInt64 && max == math.MaxInt64 && len(name) == 16 {
+		delete(c.buckets, bucketIDFromKey(name))
+	}
+
 	c.tracker.DecCacheSize(total)
 	c.tracker.SetMemBytes(uint64(c.Size()))
 }
@@ -567,6 +604,56 @@ func (c *Cache) LastWriteTime() time.Time {
 	return c.lastWriteTime
 }
 
+// bucketIDFromKey decodes the bucket ID from the org and bucket prefix of a
+// series key. It reads the string in place to avoid converting every key
+// written to a []byte.
+func bucketIDFromKey(k string) influxdb.ID {
+	var id uint64
+	for i := 8; i < 16; i++ {
+		id = id<<8 | uint64(k[i])
+	}
+	return influxdb.ID(id)
+}
+
+// pruneBucketStats removes the statistics of buckets with no data written
+// since the last snapshot that were last written before t.
+func (c *Cache) pruneBucketStats(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for bucket, stats := range c.buckets {
+		if stats.Size == 0 && stats.LastWrite.Before(t) {
+			delete(c.buckets, bucket)
+		}
+	}
+}
+
+// BucketStats returns the statistics of each bucket written since the cache
+// was created.
+func (c *Cache) BucketStats() map[influxdb.ID]BucketCacheStats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	stats := make(map[influxdb.ID]BucketCacheStats, len(c.buckets))
+	for bucket, s := range c.buckets {
+		stats[bucket] = s
+	}
+	return stats
+}
+
+// bucketLastWrites returns the last write time of each bucket written since
+// the cache was created.
+func (c *Cache) bucketLastWrites() map[influxdb.ID]time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	writes := make(map[influxdb.ID]time.Time, len(c.buckets))
+	for bucket, s := range c.buckets {
+		writes[bucket] = s.LastWrite
+	}
+	return writes
+}
+
 // Age returns the age of the cache, which is the duration since it was last
 // snapshotted.
 func (c *Cache) Age() time.Duration {
@@ -14,10 +14,12 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/golang/snappy"
 	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/storage/wal"
+	"github.com/influxdata/influxdb/v2/tsdb"
 )
 
 func TestCache_NewCache(t *testing.T) {
@@ -135,6 +137,49 @@ func TestCache_WriteMulti_Stats(t *testing.T) {
 	}
 }
 
+func TestCache_BucketStats(t *testing.T) {
+	key := func(bucket influxdb.ID) string {
+		name := tsdb.EncodeName(1, bucket)
+		return string(name[:]) + ",k=v#!~#f"
+	}
+	c := NewCache(0)
+
+	if err := c.WriteMulti(map[string][]Value{key(2): {NewValue(1, 1.0)}, key(3): {NewValue(1, 1.0)}}); err != nil {
+		t.Fatal(err)
+	}
+	stats := c.BucketStats()
+	if got, exp := len(stats), 2; got != exp {
+		t.Fatalf("bucket count mismatch: got %v, exp %v", got, exp)
+	} else if stats[2].Size == 0 || stats[3].Size == 0 {
+		t.Fatalf("expected bucket sizes to be set: %v", stats)
+	}
+
+	// Deleting a bucket removes its statistics.
+	name := tsdb.EncodeName(1, 2)
+	c.DeleteBucketRange(context.Background(), string(name[:]), math.MinInt64, math.MaxInt64, nil)
+	if _, ok := c.BucketStats()[2]; ok {
+		t.Fatal("expected statistics of deleted bucket to be removed")
+	}
+
+	// Buckets written since the last snapshot are not pruned.
+	c.pruneBucketStats(time.Now().Add(time.Hour))
+	if _, ok := c.BucketStats()[3]; !ok {
+		t.Fatal("expected statistics of bucket in cache to be kept")
+	}
+
+	if _, err := c.Snapshot(); err != nil {
+		t.Fatal(err)
+	}
+	c.pruneBucketStats(time.Now().Add(-time.Hour))
+	if _, ok := c.BucketStats()[3]; !ok {
+		t.Fatal("expected statistics of recently written bucket to be kept")
+	}
+	c.pruneBucketStats(time.Now().Add(time.Hour))
+	if got := len(c.BucketStats()); got != 0 {
+		t.Fatalf("expected statistics of cold bucket to be pruned, got %v", got)
+	}
+}
+
 func TestCache_CacheWriteMulti_TypeConflict(t *testing.T) {
 	v0 := NewValue(1, 1.0)
 	v1 := NewValue(2, 2.0)
@@ -0,0 +1,336 @@
+package tsm1
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/klauspost/compress/zstd"
+)
+
+// StringCodec identifies how the values of a string block are compressed.
+// Every codec is versioned by the 4 bit header of the encoded values, so blocks
+// written with different codecs can be mixed freely within and across files.
+type StringCodec string
+
+const (
+	// StringCodecSnappy compresses the values using Snappy. It is the default.
+	StringCodecSnappy StringCodec = "snappy"
+
+	// StringCodecZstd compresses the values using Zstandard, which trades some
+	// encoding speed for a better ratio on long, repetitive strings such as logs.
+	StringCodecZstd StringCodec = "zstd"
+
+	// StringCodecDictionary stores each distinct value once, followed by the
+	// index of each value in the dictionary. Blocks with too many distinct
+	// values fall back to StringCodecSnappy.
+	StringCodecDictionary StringCodec = "dictionary"
+)
+
+const (
+	// stringCompressedZstd is a compressed encoding using Zstandard compression.
+	stringCompressedZstd = 2
+
+	// stringCompressedDictionary is a dictionary encoding, compressed using Snappy.
+	stringCompressedDictionary = 3
+
+	// maxDictionaryEntries is the maximum number of distinct values in a
+	// dictionary encoded block.
+	maxDictionaryEntries = 256
+)
+
+// ParseStringCodec returns the StringCodec named by s.
+func ParseStringCodec(s string) (StringCodec, error) {
+	switch c := StringCodec(s); c {
+	case StringCodecSnappy, StringCodecZstd, StringCodecDictionary:
+		return c, nil
+	case "":
+		return StringCodecSnappy, nil
+	default:
+		return "", fmt.Errorf("unknown string codec %q", s)
+	}
+}
+
+// header returns the encoding header of values compressed with c.
+func (c StringCodec) header() byte {
+	switch c {
+	case StringCodecZstd:
+		return stringCompressedZstd << 4
+	case StringCodecDictionary:
+		return stringCompressedDictionary << 4
+	default:
+		return stringCompressedSnappy << 4
+	}
+}
+
+// BlockCodec returns the name of the encoding used for the values of block.
+func BlockCodec(block []byte) (string, error) {
+	if len(block) == 0 {
+		return "", fmt.Errorf("BlockCodec: empty block")
+	}
+
+	_, vb, err := unpackBlock(block[1:])
+	if err != nil {
+		return "", err
+	} else if len(vb) == 0 {
+		return "", fmt.Errorf("BlockCodec: empty values")
+	}
+
+	enc := vb[0] >> 4
+	switch block[0] {
+	case BlockFloat64:
+		if enc == floatCompressedGorilla {
+			return "gorilla", nil
+		}
+	case BlockInteger, BlockUnsigned:
+		switch enc {
+		case intUncompressed:
+			return "uncompressed", nil
+		case intCompressedSimple:
+			return "simple8b", nil
+		case intCompressedRLE:
+			return "rle", nil
+		}
+	case BlockBoolean:
+		if enc == booleanCompressedBitPacked {
+			return "bitpacked", nil
+		}
+	case BlockString:
+		switch enc {
+		case stringCompressedSnappy:
+			return string(StringCodecSnappy), nil
+		case stringCompressedZstd:
+			return string(StringCodecZstd), nil
+		case stringCompressedDictionary:
+			return string(StringCodecDictionary), nil
+		}
+	default:
+		return "", fmt.Errorf("unknown block type: %d", block[0])
+	}
+	return "", fmt.Errorf("unknown encoding %d for block type %d", enc, block[0])
+}
+
+// StringCodecs selects the codec used for string blocks written by snapshots
+// and compactions, optionally overridden per bucket.
+type StringCodecs struct {
+	Default StringCodec
+	Buckets map[influxdb.ID]StringCodec
+}
+
+// NewStringCodecs returns the StringCodecs described by the configuration.
+func NewStringCodecs(c CompressionConfig) (*StringCodecs, error) {
+	def, err := ParseStringCodec(c.StringCodec)
+	if err != nil {
+		return nil, err
+	}
+
+	codecs := &StringCodecs{
+		Default: def,
+		Buckets: make(map[influxdb.ID]StringCodec, len(c.BucketStringCodecs)),
+	}
+	for id, name := range c.BucketStringCodecs {
+		bucketID, err := influxdb.IDFromString(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket id %q: %v", id, err)
+		}
+		codec, err := ParseStringCodec(name)
+		if err != nil {
+			return nil, err
+		}
+		codecs.Buckets[*bucketID] = codec
+	}
+	return codecs, nil
+}
+
+// Codec returns the codec for string blocks of the TSM key.
+func (c *StringCodecs) Codec(key []byte) StringCodec {
+	if len(c.Buckets) > 0 && len(key) >= 16 {
+		_, bucket := tsdb.DecodeNameSlice(key)
+		if codec, ok := c.Buckets[bucket]; ok {
+			return codec
+		}
+	}
+	return c.Default
+}
+
+// Transcode returns block with its values compressed using the codec for key.
+// Blocks of other types, or already using the codec, are returned unchanged.
+func (c *StringCodecs) Transcode(key, block []byte) ([]byte, error) {
+	if len(block) == 0 || block[0] != BlockString {
+		return block, nil
+	}
+
+	tb, vb, err := unpackBlock(block[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	codec := c.Codec(key)
+	if len(vb) == 0 || vb[0] == codec.header() {
+		return block, nil
+	}
+
+	data, err := decompressStrings(vb)
+	if err != nil {
+		return nil, err
+	}
+	return packBlock(nil, BlockString, tb, compressStrings(codec, nil, data)), nil
+}
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+// initZstd creates the shared zstd encoder and decoder. EncodeAll and
+// DecodeAll are safe for concurrent use.
+func initZstd() {
+	zstdOnce.Do(func() {
+		var err error
+		if zstdEncoder, err = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1)); err != nil {
+			panic(err)
+		}
+		if zstdDecoder, err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1)); err != nil {
+			panic(err)
+		}
+	})
+}
+
+// compressStrings appends the header and compressed form of data, a sequence of
+// uvarint length prefixed strings, to dst.
+func compressStrings(codec StringCodec, dst, data []byte) []byte {
+	switch codec {
+	case StringCodecZstd:
+		initZstd()
+		dst = append(dst, codec.header())
+		return zstdEncoder.EncodeAll(data, dst)
+
+	case StringCodecDictionary:
+		if b, ok := encodeStringDictionary(data); ok {
+			dst = append(dst, codec.header())
+			return append(dst, snappy.Encode(nil, b)...)
+		}
+	}
+
+	dst = append(dst, StringCodecSnappy.header())
+	return append(dst, snappy.Encode(nil, data)...)
+}
+
+// decompressStrings returns the uvarint length prefixed strings held by the
+// encoded values b. The returned slice is always newly allocated.
+func decompressStrings(b []byte) ([]byte, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	switch b[0] >> 4 {
+	case stringCompressedSnappy:
+		data, err := snappy.Decode(nil, b[1:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode string block: %v", err.Error())
+		}
+		return data, nil
+
+	case stringCompressedZstd:
+		initZstd()
+		data, err := zstdDecoder.DecodeAll(b[1:], nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode string block: %v", err.Error())
+		}
+		return data, nil
+
+	case stringCompressedDictionary:
+		dict, err := snappy.Decode(nil, b[1:])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode string block: %v", err.Error())
+		}
+		return decodeStringDictionary(dict)
+
+	default:
+		return nil, fmt.Errorf("failed to decode string block: unknown encoding %d", b[0]>>4)
+	}
+}
+
+// encodeStringDictionary converts the uvarint length prefixed strings in data
+// to a dictionary encoding:
+//
+//	<uvarint entries> (<uvarint length> <bytes>)... <uvarint index>...
+//
+// It returns false if data holds too many distinct strings.
+func encodeStringDictionary(data []byte) ([]byte, bool) {
+	var (
+		entries []string
+		indexes []int
+		lookup  = make(map[string]int)
+	)
+
+	for i := 0; i < len(data); {
+		length, n := binary.Uvarint(data[i:])
+		if n <= 0 || i+n+int(length) > len(data) {
+			return nil, false
+		}
+		s := string(data[i+n : i+n+int(length)])
+		i += n + int(length)
+
+		idx, ok := lookup[s]
+		if !ok {
+			if len(entries) == maxDictionaryEntries {
+				return nil, false
+			}
+			idx = len(entries)
+			lookup[s] = idx
+			entries = append(entries, s)
+		}
+		indexes = append(indexes, idx)
+	}
+
+	b := make([]byte, 0, len(data)/2)
+	var buf [binary.MaxVarintLen64]byte
+	b = append(b, buf[:binary.PutUvarint(buf[:], uint64(len(entries)))]...)
+	for _, s := range entries {
+		b = append(b, buf[:binary.PutUvarint(buf[:], uint64(len(s)))]...)
+		b = append(b, s...)
+	}
+	for _, idx := range indexes {
+		b = append(b, buf[:binary.PutUvarint(buf[:], uint64(idx))]...)
+	}
+	return b, true
+}
+
+// decodeStringDictionary expands a dictionary encoding into uvarint length
+// prefixed strings.
+func decodeStringDictionary(b []byte) ([]byte, error) {
+	count, n := binary.Uvarint(b)
+	if n <= 0 || count > maxDictionaryEntries {
+		return nil, fmt.Errorf("stringDictionary: invalid entry count")
+	}
+	b = b[n:]
+
+	entries := make([][]byte, count)
+	for i := range entries {
+		length, n := binary.Uvarint(b)
+		if n <= 0 || uint64(len(b)-n) < length {
+			return nil, fmt.Errorf("stringDictionary: invalid entry")
+		}
+		entries[i] = b[n : n+int(length)]
+		b = b[n+int(length):]
+	}
+
+	data := make([]byte, 0, len(b)*8)
+	var buf [binary.MaxVarintLen64]byte
+	for len(b) > 0 {
+		idx, n := binary.Uvarint(b)
+		if n <= 0 || idx >= count {
+			return nil, fmt.Errorf("stringDictionary: invalid index")
+		}
+		b = b[n:]
+
+		data = append(data, buf[:binary.PutUvarint(buf[:], uint64(len(entries[idx])))]...)
+		data = append(data, entries[idx]...)
+	}
+	return data, nil
+}
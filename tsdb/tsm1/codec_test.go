@@ -0,0 +1,164 @@
+package tsm1_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/influxdb/v2"
+	"github.com/influxdata/influxdb/v2/tsdb"
+	"github.com/influxdata/influxdb/v2/tsdb/cursors"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+)
+
+func TestStringCodecs_Transcode(t *testing.T) {
+	lowCardinality := make([]tsm1.Value, 1000)
+	highCardinality := make([]tsm1.Value, 1000)
+	for i := range lowCardinality {
+		lowCardinality[i] = tsm1.NewValue(int64(i), fmt.Sprintf("level=%d", i%4))
+		highCardinality[i] = tsm1.NewValue(int64(i), fmt.Sprintf("request %d served in %dms", i, i%97))
+	}
+
+	tests := []struct {
+		codec  tsm1.StringCodec
+		values []tsm1.Value
+		exp    string
+	}{
+		{codec: tsm1.StringCodecSnappy, values: highCardinality, exp: "snappy"},
+		{codec: tsm1.StringCodecZstd, values: highCardinality, exp: "zstd"},
+		{codec: tsm1.StringCodecZstd, values: []tsm1.Value{tsm1.NewValue(0, "")}, exp: "zstd"},
+		{codec: tsm1.StringCodecDictionary, values: lowCardinality, exp: "dictionary"},
+		{codec: tsm1.StringCodecDictionary, values: highCardinality, exp: "snappy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.exp+"/"+string(tt.codec), func(t *testing.T) {
+			block, err := tsm1.Values(tt.values).Encode(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			codecs := &tsm1.StringCodecs{Default: tt.codec}
+			block, err = codecs.Transcode([]byte("cpu"), block)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, err := tsm1.BlockCodec(block); err != nil {
+				t.Fatal(err)
+			} else if got != tt.exp {
+				t.Fatalf("codec mismatch: got %v, exp %v", got, tt.exp)
+			}
+
+			// Values path.
+			values, err := tsm1.DecodeBlock(block, nil)
+			if err != nil {
+				t.Fatal(err)
+			} else if !reflect.DeepEqual(values, tt.values) {
+				t.Fatalf("values mismatch: got %v, exp %v", values, tt.values)
+			}
+
+			// Array path.
+			var a cursors.StringArray
+			if err := tsm1.DecodeStringArrayBlock(block, &a); err != nil {
+				t.Fatal(err)
+			}
+			for i, v := range tt.values {
+				if got, exp := a.Values[i], v.(tsm1.StringValue).RawValue(); got != exp {
+					t.Fatalf("value %d mismatch: got %q, exp %q", i, got, exp)
+				}
+			}
+		})
+	}
+}
+
+func TestStringCodecs_Codec(t *testing.T) {
+	codecs, err := tsm1.NewStringCodecs(tsm1.CompressionConfig{
+		StringCodec:        "snappy",
+		BucketStringCodecs: map[string]string{"0000000000000002": "zstd"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := func(bucket influxdb.ID) []byte {
+		return append(tsdb.EncodeNameSlice(1, bucket), ",host=A#!~#msg"...)
+	}
+	if got, exp := codecs.Codec(key(2)), tsm1.StringCodecZstd; got != exp {
+		t.Fatalf("codec mismatch: got %v, exp %v", got, exp)
+	}
+	if got, exp := codecs.Codec(key(3)), tsm1.StringCodecSnappy; got != exp {
+		t.Fatalf("codec mismatch: got %v, exp %v", got, exp)
+	}
+
+	if _, err := tsm1.NewStringCodecs(tsm1.CompressionConfig{StringCodec: "lz4"}); err == nil {
+		t.Fatal("expected error for unknown codec")
+	}
+}
+
+func TestCompactor_Snapshot_StringCodec(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	snappyKey := string(tsdb.EncodeNameSlice(1, 2)) + ",host=A#!~#msg"
+	zstdKey := string(tsdb.EncodeNameSlice(1, 3)) + ",host=A#!~#msg"
+	exp := []tsm1.Value{
+		tsm1.NewValue(1, strings.Repeat("GET /api/v2/query 200 ", 10)),
+		tsm1.NewValue(2, strings.Repeat("GET /api/v2/write 204 ", 10)),
+	}
+
+	c := tsm1.NewCache(0)
+	for _, key := range []string{snappyKey, zstdKey} {
+		if err := c.Write([]byte(key), exp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	codecs, err := tsm1.NewStringCodecs(tsm1.CompressionConfig{
+		StringCodec:        "snappy",
+		BucketStringCodecs: map[string]string{"0000000000000003": "zstd"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compactor := tsm1.NewCompactor()
+	compactor.Dir = dir
+	compactor.FileStore = &fakeFileStore{}
+	compactor.StringCodecs = codecs
+	compactor.Open()
+
+	files, err := compactor.WriteSnapshot(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := MustOpenTSMReader(files[0])
+	defer r.Close()
+
+	for key, codec := range map[string]string{snappyKey: "snappy", zstdKey: "zstd"} {
+		entries, err := r.ReadEntries([]byte(key), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, block, err := r.ReadBytes(&entries[0], nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := tsm1.BlockCodec(block); err != nil {
+			t.Fatal(err)
+		} else if got != codec {
+			t.Fatalf("codec mismatch: got %v, exp %v", got, codec)
+		}
+
+		values, err := r.ReadAll([]byte(key))
+		if err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(values, exp) {
+			t.Fatalf("values mismatch: got %v, exp %v", values, exp)
+		}
+	}
+}
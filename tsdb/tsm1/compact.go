@@ -26,6 +26,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/influxdata/influxdb/v2"
 	"github.com/influxdata/influxdb/v2/kit/tracing"
 	"github.com/influxdata/influxdb/v2/pkg/limiter"
 	"github.com/influxdata/influxdb/v2/tsdb/cursors"
@@ -120,9 +121,20 @@ type DefaultPlanner struct {
 	// infrequently as the plans are more expensive to run.
 	forceFull bool
 
+	// forceOptimize causes the next optimize plan to include groups of fully
+	// compacted generations that would normally be too small to be worth it.
+	forceOptimize bool
+
 	// filesInUse is the set of files that have been returned as part of a plan and might
 	// be being compacted.  Two plans should not return the same file at any given time.
 	filesInUse map[string]struct{}
+
+	// bucketColdDurations overrides compactFullWriteColdDuration for buckets.
+	bucketColdDurations map[influxdb.ID]time.Duration
+
+	// bucketWrites returns the last write time of each bucket written since the
+	// engine was opened. If nil, bucketColdDurations are not applied.
+	bucketWrites func() map[influxdb.ID]time.Time
 }
 
 type fileStore interface {
@@ -140,6 +152,60 @@ func NewDefaultPlanner(fs fileStore, writeColdDuration time.Duration) *DefaultPl
 	}
 }
 
+// SetBucketFullWriteColdDuration overrides the full write cold duration for the
+// bucket. A duration of zero removes the override.
+func (c *DefaultPlanner) SetBucketFullWriteColdDuration(bucketID influxdb.ID, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Copy on write, so writeCold can use the map without holding the lock.
+	durations := make(map[influxdb.ID]time.Duration, len(c.bucketColdDurations)+1)
+	for id, d := range c.bucketColdDurations {
+		durations[id] = d
+	}
+	if d > 0 {
+		durations[bucketID] = d
+	} else {
+		delete(durations, bucketID)
+	}
+	c.bucketColdDurations = durations
+}
+
+// writeCold returns true if nothing has been written for long enough to plan a
+// full compaction. Buckets with their own full write cold duration must each
+// have gone without writes for that duration, while all other buckets must have
+// gone without writes since lastWrite for the default duration.
+func (c *DefaultPlanner) writeCold(lastWrite time.Time) bool {
+	c.mu.RLock()
+	durations := c.bucketColdDurations
+	c.mu.RUnlock()
+
+	var writes map[influxdb.ID]time.Time
+	if len(durations) > 0 && c.bucketWrites != nil {
+		writes = c.bucketWrites()
+	}
+	if len(writes) == 0 {
+		return c.compactFullWriteColdDuration > 0 && time.Since(lastWrite) > c.compactFullWriteColdDuration
+	}
+
+	var defaultWritten bool
+	for id, t := range writes {
+		d, ok := durations[id]
+		if !ok {
+			defaultWritten = true
+			continue
+		}
+		if time.Since(t) <= d {
+			return false
+		}
+	}
+
+	if defaultWritten {
+		return c.compactFullWriteColdDuration > 0 && time.Since(lastWrite) > c.compactFullWriteColdDuration
+	}
+	return true
+}
+
 // tsmGeneration represents the TSM files within a generation.
 // 000001-01.tsm, 000001-02.tsm would be in the same generation
 // 000001 each with different sequence numbers.
@@ -217,6 +283,14 @@ func (c *DefaultPlanner) ForceFull() {
 	c.forceFull = true
 }
 
+// ForceOptimize causes the planner to return an optimize plan for any group of
+// two or more fully compacted generations the next time one is requested.
+func (c *DefaultPlanner) ForceOptimize() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.forceOptimize = true
+}
+
 // PlanLevel returns a set of TSM files to rewrite for a specific level.
 func (c *DefaultPlanner) PlanLevel(level int) []CompactionGroup {
 	// If a full plan has been requested, don't plan any levels which will prevent
@@ -317,12 +391,21 @@ func (c *DefaultPlanner) PlanLevel(level int) []CompactionGroup {
 func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 	// If a full plan has been requested, don't plan any levels which will prevent
 	// the full plan from acquiring them.
-	c.mu.RLock()
+	c.mu.Lock()
 	if c.forceFull {
-		c.mu.RUnlock()
+		c.mu.Unlock()
 		return nil
 	}
-	c.mu.RUnlock()
+	force := c.forceOptimize
+	c.forceOptimize = false
+	c.mu.Unlock()
+
+	// Unless an optimize plan has been requested, only optimize groups with
+	// enough generations to be worthwhile.
+	minGenerations := 4
+	if force {
+		minGenerations = 2
+	}
 
 	// Determine the generations from all files on disk.  We need to treat
 	// a generation conceptually as a single file even though it may be
@@ -382,7 +465,7 @@ func (c *DefaultPlanner) PlanOptimize() []CompactionGroup {
 	var cGroups []CompactionGroup
 	for _, group := range levelGroups {
 		// Skip the group if it's not worthwhile to optimize it
-		if len(group) < 4 && !group.hasTombstones() {
+		if len(group) < minGenerations && !group.hasTombstones() {
 			continue
 		}
 
@@ -413,7 +496,7 @@ func (c *DefaultPlanner) Plan(lastWrite time.Time) []CompactionGroup {
 	c.mu.RUnlock()
 
 	// first check if we should be doing a full compaction because nothing has been written in a long time
-	if forceFull || c.compactFullWriteColdDuration > 0 && time.Since(lastWrite) > c.compactFullWriteColdDuration && len(generations) > 1 {
+	if forceFull || c.writeCold(lastWrite) && len(generations) > 1 {
 
 		// Reset the full schedule if we planned because of it.
 		if forceFull {
@@ -620,6 +703,11 @@ func (c *DefaultPlanner) findGenerations(skipInUse bool) tsmGenerations {
 	tsmStats := c.FileStore.Stats()
 	generations := make(map[int]*tsmGeneration, len(tsmStats))
 	for _, f := range tsmStats {
+		// Files in the cold tier are never compacted.
+		if f.Cold {
+			continue
+		}
+
 		gen, _, _ := c.ParseFileName(f.Path)
 
 		// Skip any files that are assigned to a current compaction plan
@@ -698,6 +786,10 @@ type Compactor struct {
 	// RateLimit is the limit for disk writes for all concurrent compactions.
 	RateLimit limiter.Rate
 
+	// StringCodecs selects the codec of the string blocks written. If nil, blocks
+	// are written as they are encoded.
+	StringCodecs *StringCodecs
+
 	formatFileName FormatFileNameFunc
 	parseFileName  ParseFileNameFunc
 
@@ -716,6 +808,9 @@ type Compactor struct {
 	compactionsInterrupt chan struct{}
 
 	files map[string]struct{}
+
+	// progress tracks the running compactions by the first of their input files.
+	progress map[string]*compactionProgress
 }
 
 // NewCompactor returns a new instance of Compactor.
@@ -749,6 +844,7 @@ func (c *Compactor) Open() {
 	c.snapshotLatencies = &latencies{values: make([]time.Duration, 4)}
 
 	c.files = make(map[string]struct{})
+	c.progress = make(map[string]*compactionProgress)
 }
 
 // Close disables the Compactor.
@@ -946,7 +1042,71 @@ func (c *Compactor) compact(fast bool, tsmFiles []string) ([]string, error) {
 		return nil, err
 	}
 
-	return c.writeNewFiles(maxGeneration, maxSequence, tsmFiles, tsm, true)
+	progress := &compactionProgress{}
+	for _, tr := range trs {
+		progress.total += int64(tr.Size())
+	}
+
+	c.mu.Lock()
+	c.progress[tsmFiles[0]] = progress
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.progress, tsmFiles[0])
+		c.mu.Unlock()
+	}()
+
+	return c.writeNewFiles(maxGeneration, maxSequence, tsmFiles, &progressKeyIterator{KeyIterator: tsm, progress: progress}, true)
+}
+
+// Progress returns the estimated fraction of the input of the running
+// compaction of tsmFiles that has been written. It returns false if the
+// compaction is not running.
+func (c *Compactor) Progress(tsmFiles []string) (float64, bool) {
+	if len(tsmFiles) == 0 {
+		return 0, false
+	}
+
+	c.mu.RLock()
+	progress, ok := c.progress[tsmFiles[0]]
+	c.mu.RUnlock()
+	if !ok {
+		return 0, false
+	}
+	return progress.fraction(), true
+}
+
+// compactionProgress tracks the bytes of blocks written by a compaction
+// against the size of its input files.
+type compactionProgress struct {
+	total   int64
+	written int64 // accessed atomically
+}
+
+// fraction returns the fraction of the input written, which is an estimate
+// since merged and deleted blocks shrink the output.
+func (p *compactionProgress) fraction() float64 {
+	if p.total <= 0 {
+		return 0
+	}
+	f := float64(atomic.LoadInt64(&p.written)) / float64(p.total)
+	if f > 1 {
+		f = 1
+	}
+	return f
+}
+
+// progressKeyIterator records the size of the blocks read from a KeyIterator.
+type progressKeyIterator struct {
+	KeyIterator
+	progress *compactionProgress
+}
+
+func (k *progressKeyIterator) Read() ([]byte, int64, int64, []byte, error) {
+	key, minTime, maxTime, block, err := k.KeyIterator.Read()
+	atomic.AddInt64(&k.progress.written, int64(len(block)))
+	return key, minTime, maxTime, block, err
 }
 
 // CompactFull writes multiple smaller TSM files into 1 or more larger files.
@@ -1161,6 +1321,12 @@ func (c *Compactor) write(path string, iter KeyIterator, throttle bool) (err err
 			return fmt.Errorf("invalid index entry for block. min=%d, max=%d", minTime, maxTime)
 		}
 
+		if c.StringCodecs != nil {
+			if block, err = c.StringCodecs.Transcode(key, block); err != nil {
+				return err
+			}
+		}
+
 		// Write the key and value
 		if err := w.WriteBlock(key, minTime, maxTime, block); err == ErrMaxBlocksExceeded {
 			if err := w.WriteIndex(); err != nil {
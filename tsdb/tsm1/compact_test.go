@@ -2364,6 +2364,47 @@ func TestDefaultPlanner_PlanOptimize_Optimized(t *testing.T) {
 	}
 }
 
+func TestDefaultPlanner_PlanOptimize_Forced(t *testing.T) {
+	data := []tsm1.FileStat{
+		{
+			Path: "01-04.tsm1",
+			Size: 251 * 1024 * 1024,
+		},
+		{
+			Path: "02-04.tsm1",
+			Size: 1 * 1024 * 1024,
+		},
+	}
+
+	cp := tsm1.NewDefaultPlanner(
+		&fakeFileStore{
+			PathsFn: func() []tsm1.FileStat {
+				return data
+			},
+		}, tsm1.DefaultCompactFullWriteColdDuration,
+	)
+
+	// Two generations are normally not worth optimizing.
+	if got := cp.PlanOptimize(); len(got) != 0 {
+		t.Fatalf("tsm file plan length mismatch: got %v, exp %v", len(got), 0)
+	}
+
+	cp.ForceOptimize()
+	tsm := cp.PlanOptimize()
+	if exp, got := 1, len(tsm); got != exp {
+		t.Fatalf("tsm file plan length mismatch: got %v, exp %v", got, exp)
+	}
+	if exp, got := len(data), len(tsm[0]); got != exp {
+		t.Fatalf("tsm file length mismatch: got %v, exp %v", got, exp)
+	}
+	cp.Release(tsm)
+
+	// The request only applies to the next plan.
+	if got := cp.PlanOptimize(); len(got) != 0 {
+		t.Fatalf("tsm file plan length mismatch: got %v, exp %v", len(got), 0)
+	}
+}
+
 func TestDefaultPlanner_PlanOptimize_Tombstones(t *testing.T) {
 	data := []tsm1.FileStat{
 		{
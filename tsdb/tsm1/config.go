@@ -1,6 +1,7 @@
 package tsm1
 
 import (
+	"errors"
 	"runtime"
 	"time"
 
@@ -33,8 +34,10 @@ type Config struct {
 	// preallocation to improve throughput. Currently used in the series file.
 	LargeSeriesWriteThreshold int `toml:"large-series-write-threshold"`
 
-	Compaction CompactionConfig `toml:"compaction"`
-	Cache      CacheConfig      `toml:"cache"`
+	Compaction  CompactionConfig  `toml:"compaction"`
+	Cache       CacheConfig       `toml:"cache"`
+	Tier        TierConfig        `toml:"tier"`
+	Compression CompressionConfig `toml:"compression"`
 }
 
 // NewConfig constructs a Config with the default values.
@@ -51,6 +54,8 @@ func NewConfig() Config {
 			ThroughputBurst:       toml.Size(DefaultCompactThroughputBurst),
 			MaxConcurrent:         DefaultCompactMaxConcurrent,
 		},
+		Tier:        NewTierConfig(),
+		Compression: NewCompressionConfig(),
 	}
 }
 
@@ -125,6 +130,121 @@ func NewCacheConfig() CacheConfig {
 	}
 }
 
+// Default cold tier configuration values.
+const (
+	DefaultTierColdAfter     = toml.Duration(0)                // Defaults to off.
+	DefaultTierCheckInterval = toml.Duration(10 * time.Minute) // Ten minutes
+
+	DefaultS3DialTimeout     = toml.Duration(30 * time.Second) // Thirty seconds
+	DefaultS3ResponseTimeout = toml.Duration(time.Minute)      // One minute
+	DefaultS3RequestTimeout  = toml.Duration(15 * time.Minute) // Fifteen minutes
+)
+
+// TierConfig holds the configuration for relocating fully compacted TSM files
+// to a secondary, cheaper storage tier. At most one of Dir or S3 may be set.
+type TierConfig struct {
+	// Dir is a directory, usually on a different volume to the engine path,
+	// where cold TSM files are moved to.
+	Dir string `toml:"dir"`
+
+	// S3 configures an S3-compatible object store where cold TSM files are
+	// uploaded to.
+	S3 S3Config `toml:"s3"`
+
+	// ColdAfter is the age of the newest point in a fully compacted TSM file
+	// after which the file is moved to the cold tier. A value of 0 disables
+	// relocation, but files already in the cold tier remain readable.
+	ColdAfter toml.Duration `toml:"cold-after"`
+
+	// CheckInterval is how often the engine looks for files to relocate.
+	CheckInterval toml.Duration `toml:"check-interval"`
+}
+
+// NewTierConfig initialises a new TierConfig with default values.
+func NewTierConfig() TierConfig {
+	return TierConfig{
+		ColdAfter:     DefaultTierColdAfter,
+		CheckInterval: DefaultTierCheckInterval,
+	}
+}
+
+// Enabled returns true if a cold tier has been configured.
+func (c TierConfig) Enabled() bool {
+	return c.Dir != "" || c.S3.Endpoint != ""
+}
+
+// Validate returns an error if the configuration is invalid.
+func (c TierConfig) Validate() error {
+	if c.Dir != "" && c.S3.Endpoint != "" {
+		return errors.New("tier: only one of dir or s3 may be configured")
+	}
+	if c.S3.Endpoint != "" && c.S3.Bucket == "" {
+		return errors.New("tier: s3 bucket must be set")
+	}
+	if c.ColdAfter < 0 {
+		return errors.New("tier: cold-after must not be negative")
+	}
+	if c.ColdAfter > 0 && c.CheckInterval <= 0 {
+		return errors.New("tier: check-interval must be positive")
+	}
+	return nil
+}
+
+// S3Config holds the connection details of an S3-compatible object store.
+type S3Config struct {
+	// Endpoint is the base URL of the object store, e.g. https://s3.us-east-1.amazonaws.com.
+	Endpoint string `toml:"endpoint"`
+
+	// Bucket is the name of the bucket holding the cold TSM files.
+	Bucket string `toml:"bucket"`
+
+	// Prefix is prepended to the name of every object written to the bucket.
+	Prefix string `toml:"prefix"`
+
+	// Region is used when signing requests. Defaults to us-east-1.
+	Region string `toml:"region"`
+
+	// AccessKeyID and SecretAccessKey are the credentials used to sign requests.
+	// Requests are sent unsigned if they are not set.
+	AccessKeyID     string `toml:"access-key-id"`
+	SecretAccessKey string `toml:"secret-access-key"`
+
+	// DialTimeout bounds connecting to the object store, including the TLS
+	// handshake. Defaults to DefaultS3DialTimeout.
+	DialTimeout toml.Duration `toml:"dial-timeout"`
+
+	// ResponseTimeout bounds waiting for the response headers after a
+	// request is sent. Defaults to DefaultS3ResponseTimeout.
+	ResponseTimeout toml.Duration `toml:"response-timeout"`
+
+	// RequestTimeout bounds a whole request, including uploading or
+	// downloading the body, so it must allow for transferring the largest
+	// TSM file. Defaults to DefaultS3RequestTimeout.
+	RequestTimeout toml.Duration `toml:"request-timeout"`
+}
+
+// DefaultStringCodec is the default codec for the values of string blocks.
+const DefaultStringCodec = string(StringCodecSnappy)
+
+// CompressionConfig holds the configuration of the codecs used for blocks
+// written by snapshots and compactions. Existing blocks are rewritten with the
+// configured codec as they are compacted.
+type CompressionConfig struct {
+	// StringCodec is the codec for the values of string blocks: snappy, zstd or
+	// dictionary.
+	StringCodec string `toml:"string-codec"`
+
+	// BucketStringCodecs overrides StringCodec for the buckets with the given IDs.
+	BucketStringCodecs map[string]string `toml:"bucket-string-codecs"`
+}
+
+// NewCompressionConfig initialises a new CompressionConfig with default values.
+func NewCompressionConfig() CompressionConfig {
+	return CompressionConfig{
+		StringCodec: DefaultStringCodec,
+	}
+}
+
 // Default WAL configuration values.
 const (
 	DefaultWALEnabled    = true
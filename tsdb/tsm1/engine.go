@@ -174,6 +174,17 @@ type Engine struct {
 
 	scheduler   *scheduler
 	snapshotter Snapshotter
+
+	tierConfig TierConfig
+	tierDone   chan struct{}   // channel to signal cold tier relocation to stop
+	tierWG     *sync.WaitGroup // waitgroup for the cold tier relocation goroutine
+
+	compressionConfig CompressionConfig
+
+	bucketSettings bucketSettings // storage settings overridden for buckets
+
+	compactions       compactionRegistry // active and queued compactions
+	compactionsPaused bool               // compactions paused by PauseCompactions
 }
 
 // NewEngine returns a new instance of Engine.
@@ -211,6 +222,8 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 		maxCompactions = runtime.GOMAXPROCS(0)
 	}
 
+	planner := NewDefaultPlanner(fs, time.Duration(config.Compaction.FullWriteColdDuration))
+
 	logger := zap.NewNop()
 	e := &Engine{
 		path:   path,
@@ -222,8 +235,7 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 
 		FileStore: fs,
 		Compactor: c,
-		CompactionPlan: NewDefaultPlanner(fs,
-			time.Duration(config.Compaction.FullWriteColdDuration)),
+		CompactionPlan: planner,
 
 		CacheFlushMemorySizeThreshold:  uint64(config.Cache.SnapshotMemorySize),
 		CacheFlushWriteColdDuration:    time.Duration(config.Cache.SnapshotWriteColdDuration),
@@ -234,8 +246,12 @@ func NewEngine(path string, idx *tsi1.Index, config Config, options ...EngineOpt
 		fullCompactionSemaphore:        influxdb.NopSemaphore,
 		scheduler:                      newScheduler(maxCompactions),
 		snapshotter:                    new(noSnapshotter),
+		tierConfig:                     config.Tier,
+		compressionConfig:              config.Compression,
 	}
 
+	planner.bucketWrites = func() map[influxdb.ID]time.Time { return e.Cache.bucketLastWrites() }
+
 	for _, option := range options {
 		option(e)
 	}
@@ -520,6 +536,7 @@ func (e *Engine) initTrackers() {
 	e.FileStore.tracker = newFileTracker(bms.fileMetrics, e.defaultMetricLabels)
 	e.Cache.tracker = newCacheTracker(bms.cacheMetrics, e.defaultMetricLabels)
 	e.readTracker = newReadTracker(bms.readMetrics, e.defaultMetricLabels)
+	e.FileStore.coldTracker = newColdTierTracker(bms.coldTierMetrics, e.defaultMetricLabels)
 
 	e.scheduler.setCompactionTracker(e.compactionTracker)
 }
@@ -555,21 +572,40 @@ func (e *Engine) Open(ctx context.Context) (err error) {
 		return err
 	}
 
+	if e.FileStore.cold == nil && e.tierConfig.Enabled() {
+		store, err := NewObjectStore(e.tierConfig)
+		if err != nil {
+			return err
+		}
+		e.FileStore.WithColdTier(store)
+	}
+
 	if err := e.FileStore.Open(ctx); err != nil {
 		return err
 	}
 
+	if e.Compactor.StringCodecs == nil {
+		if e.Compactor.StringCodecs, err = NewStringCodecs(e.compressionConfig); err != nil {
+			return err
+		}
+	}
+
 	e.Compactor.Open()
 
 	if e.enableCompactionsOnOpen {
 		e.SetCompactionsEnabled(true)
 	}
 
+	if e.FileStore.cold != nil && e.tierConfig.ColdAfter > 0 {
+		e.enableColdTierRelocation()
+	}
+
 	return nil
 }
 
 // Close closes the engine. Subsequent calls to Close are a nop.
 func (e *Engine) Close() error {
+	e.disableColdTierRelocation()
 	e.SetCompactionsEnabled(false)
 
 	// Lock now and close everything else down.
@@ -793,6 +829,11 @@ func (t *compactionTracker) SetQueue(level compactionLevel, length uint64) {
 	t.metrics.CompactionQueue.With(labels).Set(float64(length))
 }
 
+// queueLen returns the compaction queue depth for the provided level.
+func (t *compactionTracker) queueLen(level compactionLevel) uint64 {
+	return atomic.LoadUint64(&t.queue[level])
+}
+
 // SetOptimiseQueue sets the queue depth for Optimisation compactions.
 func (t *compactionTracker) SetOptimiseQueue(length uint64) { t.SetQueue(4, length) }
 
@@ -914,6 +955,10 @@ func (e *Engine) compactCache() {
 				continue
 			}
 
+			// Buckets are only added to the cache statistics when written,
+			// so they only need pruning when the cache is snapshotted.
+			e.pruneBucketCacheStats(time.Now())
+
 			span, ctx := tracing.StartSpanFromContextWithOperationName(context.Background(), "compact cache")
 			span.LogKV("path", e.path)
 
@@ -943,11 +988,12 @@ const (
 )
 
 // ShouldCompactCache returns a status indicating if the Cache should be
-// snapshotted. There are three situations when the cache should be snapshotted:
+// snapshotted. There are four situations when the cache should be snapshotted:
 //
 // - the Cache size is over its flush size threshold;
-// - the Cache has not been snapshotted for longer than its flush time threshold; or
-// - the Cache has not been written since the write cold threshold.
+// - the Cache has not been snapshotted for longer than its flush time threshold;
+// - the Cache has not been written since the write cold threshold; or
+// - a bucket is over the flush size or write cold thresholds in its settings.
 //
 func (e *Engine) ShouldCompactCache(t time.Time) CacheStatus {
 	sz := e.Cache.Size()
@@ -969,7 +1015,9 @@ func (e *Engine) ShouldCompactCache(t time.Time) CacheStatus {
 	if t.Sub(e.Cache.LastWriteTime()) > e.CacheFlushWriteColdDuration {
 		return CacheStatusColdNoWrites
 	}
-	return CacheStatusOkay
+
+	// A bucket has exceeded its own thresholds.
+	return e.bucketCacheStatus(t)
 }
 
 func (e *Engine) lastModified() time.Time {
@@ -1048,6 +1096,12 @@ func (e *Engine) compact(wg *sync.WaitGroup) {
 				}
 			}
 
+			// Record the plans we didn't start as queued.
+			e.compactions.setQueued(1, level1Groups)
+			e.compactions.setQueued(2, level2Groups)
+			e.compactions.setQueued(3, level3Groups)
+			e.compactions.setQueued(4, level4Groups)
+
 			// Release all the plans we didn't start.
 			e.CompactionPlan.Release(level1Groups)
 			e.CompactionPlan.Release(level2Groups)
@@ -1224,6 +1278,8 @@ func (s *compactionStrategy) compactGroup(ctx context.Context) {
 
 	now := time.Now()
 	group := s.group
+	defer s.engine.compactions.start(s.level, group)()
+
 	log, logEnd := logger.NewOperation(ctx, s.logger, "TSM compaction", "tsm1_compact_group")
 	defer logEnd()
 
@@ -0,0 +1,113 @@
+package tsm1
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// bucketSettings holds the storage settings overridden for buckets.
+type bucketSettings struct {
+	mu sync.RWMutex
+	m  map[influxdb.ID]influxdb.BucketStorageSettings
+}
+
+func (s *bucketSettings) set(bucketID influxdb.ID, settings *influxdb.BucketStorageSettings) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if settings.IsZero() {
+		delete(s.m, bucketID)
+		return
+	}
+	if s.m == nil {
+		s.m = make(map[influxdb.ID]influxdb.BucketStorageSettings)
+	}
+	s.m[bucketID] = *settings
+}
+
+func (s *bucketSettings) get(bucketID influxdb.ID) (influxdb.BucketStorageSettings, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	settings, ok := s.m[bucketID]
+	return settings, ok
+}
+
+func (s *bucketSettings) len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.m)
+}
+
+// SetBucketSettings overrides the compaction planner and cache snapshot settings
+// for the data of a bucket. Nil or zero settings remove the overrides.
+//
+// The cache and TSM files hold the data of every bucket, so the cache settings
+// of a bucket can only cause the cache to be snapshot sooner than configured.
+func (e *Engine) SetBucketSettings(bucketID influxdb.ID, settings *influxdb.BucketStorageSettings) {
+	e.bucketSettings.set(bucketID, settings)
+
+	if p, ok := e.CompactionPlan.(*DefaultPlanner); ok {
+		var d time.Duration
+		if settings != nil {
+			d = settings.CompactFullWriteColdDuration
+		}
+		p.SetBucketFullWriteColdDuration(bucketID, d)
+	}
+}
+
+// BucketSettings returns the settings overridden for the bucket, if any.
+func (e *Engine) BucketSettings(bucketID influxdb.ID) (influxdb.BucketStorageSettings, bool) {
+	return e.bucketSettings.get(bucketID)
+}
+
+// bucketCacheStatus returns the status of the cache based on the settings of
+// the buckets written to it.
+func (e *Engine) bucketCacheStatus(t time.Time) CacheStatus {
+	if e.bucketSettings.len() == 0 {
+		return CacheStatusOkay
+	}
+
+	for bucketID, stats := range e.Cache.BucketStats() {
+		if stats.Size == 0 {
+			continue
+		}
+
+		settings, ok := e.bucketSettings.get(bucketID)
+		if !ok {
+			continue
+		}
+
+		if settings.CacheSnapshotMemorySize > 0 && stats.Size > settings.CacheSnapshotMemorySize {
+			return CacheStatusSizeExceeded
+		}
+		if settings.CacheSnapshotWriteColdDuration > 0 && t.Sub(stats.LastWrite) > settings.CacheSnapshotWriteColdDuration {
+			return CacheStatusColdNoWrites
+		}
+	}
+	return CacheStatusOkay
+}
+
+// pruneBucketCacheStats removes the cache statistics of buckets that have not
+// been written for longer than the cold durations using them, so the
+// statistics do not grow with every bucket ever written.
+func (e *Engine) pruneBucketCacheStats(t time.Time) {
+	d := e.CacheFlushWriteColdDuration
+	if p, ok := e.CompactionPlan.(*DefaultPlanner); ok && p.compactFullWriteColdDuration > d {
+		d = p.compactFullWriteColdDuration
+	}
+
+	e.bucketSettings.mu.RLock()
+	for _, settings := range e.bucketSettings.m {
+		if settings.CompactFullWriteColdDuration > d {
+			d = settings.CompactFullWriteColdDuration
+		}
+		if settings.CacheSnapshotWriteColdDuration > d {
+			d = settings.CacheSnapshotWriteColdDuration
+		}
+	}
+	e.bucketSettings.mu.RUnlock()
+
+	e.Cache.pruneBucketStats(t.Add(-d))
+}
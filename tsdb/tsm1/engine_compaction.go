@@ -0,0 +1,154 @@
+package tsm1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2"
+)
+
+// compactionLevelNames names the compaction levels reported by CompactionStatus.
+var compactionLevelNames = map[compactionLevel]string{
+	1: "1",
+	2: "2",
+	3: "3",
+	4: "optimize",
+	5: "full",
+}
+
+// activeCompaction is a compaction group being compacted.
+type activeCompaction struct {
+	level   compactionLevel
+	group   CompactionGroup
+	started time.Time
+}
+
+// compactionRegistry holds the active and queued compactions of the engine.
+type compactionRegistry struct {
+	mu     sync.RWMutex
+	active map[*activeCompaction]struct{}
+	queued [6][]CompactionGroup
+}
+
+// start registers group as being compacted and returns a function that
+// unregisters it.
+func (r *compactionRegistry) start(level compactionLevel, group CompactionGroup) func() {
+	c := &activeCompaction{level: level, group: group, started: time.Now()}
+
+	r.mu.Lock()
+	if r.active == nil {
+		r.active = make(map[*activeCompaction]struct{})
+	}
+	r.active[c] = struct{}{}
+	r.mu.Unlock()
+
+	return func() {
+		r.mu.Lock()
+		delete(r.active, c)
+		r.mu.Unlock()
+	}
+}
+
+// setQueued sets the groups planned for level that have not been started.
+func (r *compactionRegistry) setQueued(level compactionLevel, groups []CompactionGroup) {
+	queued := make([]CompactionGroup, len(groups))
+	copy(queued, groups)
+
+	r.mu.Lock()
+	r.queued[level] = queued
+	r.mu.Unlock()
+}
+
+// PauseCompactions aborts any running level, optimize and full compactions and
+// prevents new ones from starting until ResumeCompactions is called. Unlike
+// SetCompactionsEnabled, the cache continues to be snapshot to TSM files.
+func (e *Engine) PauseCompactions() {
+	e.mu.Lock()
+	if e.compactionsPaused {
+		e.mu.Unlock()
+		return
+	}
+	e.compactionsPaused = true
+	e.mu.Unlock()
+
+	e.disableLevelCompactions(true)
+}
+
+// ResumeCompactions restarts compactions paused by PauseCompactions.
+func (e *Engine) ResumeCompactions() {
+	e.mu.Lock()
+	if !e.compactionsPaused {
+		e.mu.Unlock()
+		return
+	}
+	e.compactionsPaused = false
+	e.mu.Unlock()
+
+	e.enableLevelCompactions(true)
+}
+
+// CompactionsPaused returns true if compactions have been paused.
+func (e *Engine) CompactionsPaused() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.compactionsPaused
+}
+
+// ScheduleOptimizeCompaction requests that the next optimize plan includes
+// every group of fully compacted generations, rather than only those large
+// enough to normally be worth optimizing.
+func (e *Engine) ScheduleOptimizeCompaction(ctx context.Context) error {
+	p, ok := e.CompactionPlan.(*DefaultPlanner)
+	if !ok {
+		return fmt.Errorf("optimize compactions are not supported by %T", e.CompactionPlan)
+	}
+	p.ForceOptimize()
+	return nil
+}
+
+// CompactionStatus returns the active and queued compactions of each level.
+func (e *Engine) CompactionStatus() *influxdb.CompactionStatus {
+	status := &influxdb.CompactionStatus{Paused: e.CompactionsPaused()}
+
+	e.compactions.mu.RLock()
+	defer e.compactions.mu.RUnlock()
+
+	for level := compactionLevel(1); level <= 5; level++ {
+		ls := influxdb.CompactionLevelStatus{
+			Level:     compactionLevelNames[level],
+			Active:    e.compactionTracker.Active(int(level)),
+			Queued:    e.compactionTracker.queueLen(level),
+			Completed: e.compactionTracker.Completed(int(level)),
+			Errors:    e.compactionTracker.Errors(int(level)),
+		}
+
+		for c := range e.compactions.active {
+			if c.level != level {
+				continue
+			}
+			started := c.started
+			progress, _ := e.Compactor.Progress(c.group)
+			ls.Compactions = append(ls.Compactions, influxdb.Compaction{
+				Active:    true,
+				Files:     append([]string(nil), c.group...),
+				StartedAt: &started,
+				Progress:  progress,
+			})
+		}
+		sort.Slice(ls.Compactions, func(i, j int) bool {
+			return ls.Compactions[i].StartedAt.Before(*ls.Compactions[j].StartedAt)
+		})
+
+		for _, group := range e.compactions.queued[level] {
+			ls.Compactions = append(ls.Compactions, influxdb.Compaction{
+				Files: append([]string(nil), group...),
+			})
+		}
+
+		status.Levels = append(status.Levels, ls)
+	}
+	return status
+}
@@ -175,6 +175,109 @@ func TestEngine_ShouldCompactCache(t *testing.T) {
 	}
 }
 
+func TestEngine_ShouldCompactCache_BucketSettings(t *testing.T) {
+	nowTime := time.Now()
+
+	e, err := NewEngine(tsm1.NewConfig(), t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// mock the planner so compactions don't run during the test
+	e.CompactionPlan = &mockPlanner{}
+	e.SetEnabled(false)
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatalf("failed to open tsm1 engine: %s", err.Error())
+	}
+	defer e.Close()
+
+	const org, small, cold influxdb.ID = 1, 2, 3
+	e.SetBucketSettings(small, &influxdb.BucketStorageSettings{CacheSnapshotMemorySize: 1})
+	e.SetBucketSettings(cold, &influxdb.BucketStorageSettings{CacheSnapshotWriteColdDuration: time.Minute})
+
+	write := func(bucket influxdb.ID) {
+		t.Helper()
+		name := tsdb.EncodeName(org, bucket)
+		pt := models.MustNewPoint(string(name[:]), models.NewTags(map[string]string{"k": "v"}), models.Fields{"f": int64(1)}, nowTime)
+		if err := e.writePoints(pt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(4)
+	if got, exp := e.ShouldCompactCache(nowTime), tsm1.CacheStatusOkay; got != exp {
+		t.Fatalf("got status %v, exp status %v - bucket without settings written, so should not compact", got, exp)
+	}
+
+	write(cold)
+	if got, exp := e.ShouldCompactCache(nowTime), tsm1.CacheStatusOkay; got != exp {
+		t.Fatalf("got status %v, exp status %v - bucket recently written, so should not compact", got, exp)
+	}
+	if got, exp := e.ShouldCompactCache(nowTime.Add(2*time.Minute)), tsm1.CacheStatusColdNoWrites; got != exp {
+		t.Fatalf("got status %v, exp status %v - bucket write cold threshold exceeded, so should compact", got, exp)
+	}
+
+	write(small)
+	if got, exp := e.ShouldCompactCache(nowTime), tsm1.CacheStatusSizeExceeded; got != exp {
+		t.Fatalf("got status %v, exp status %v - bucket cache size > snapshot threshold, so should compact", got, exp)
+	}
+
+	e.SetBucketSettings(small, nil)
+	if _, ok := e.BucketSettings(small); ok {
+		t.Fatal("expected bucket settings to be removed")
+	}
+	if got, exp := e.ShouldCompactCache(nowTime), tsm1.CacheStatusOkay; got != exp {
+		t.Fatalf("got status %v, exp status %v - bucket settings removed, so should not compact", got, exp)
+	}
+}
+
+func TestEngine_PauseCompactions(t *testing.T) {
+	e, err := NewEngine(tsm1.NewConfig(), t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// mock the planner so compactions don't run during the test
+	e.CompactionPlan = &mockPlanner{}
+	if err := e.Open(context.Background()); err != nil {
+		t.Fatalf("failed to open tsm1 engine: %s", err.Error())
+	}
+	defer e.Close()
+
+	e.PauseCompactions()
+	if !e.CompactionsPaused() {
+		t.Fatal("expected compactions to be paused")
+	}
+
+	status := e.CompactionStatus()
+	if !status.Paused {
+		t.Fatal("expected status to report compactions paused")
+	}
+	var levels []string
+	for _, l := range status.Levels {
+		levels = append(levels, l.Level)
+	}
+	if got, exp := strings.Join(levels, ","), "1,2,3,optimize,full"; got != exp {
+		t.Fatalf("levels mismatch: got %v, exp %v", got, exp)
+	}
+
+	// Scheduling a full compaction does not resume paused compactions.
+	if err := e.ScheduleFullCompaction(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !e.CompactionsPaused() {
+		t.Fatal("expected compactions to remain paused")
+	}
+
+	e.ResumeCompactions()
+	if e.CompactionsPaused() {
+		t.Fatal("expected compactions to be resumed")
+	}
+	if e.CompactionStatus().Paused {
+		t.Fatal("expected status to report compactions resumed")
+	}
+}
+
 func makeBlockTypeSlice(n int) []byte {
 	r := make([]byte, n)
 	b := tsm1.BlockFloat64
@@ -0,0 +1,100 @@
+package tsm1
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/kit/tracing"
+	"go.uber.org/zap"
+)
+
+// SetColdTier sets the object store that cold TSM files are relocated to. It
+// overrides the configured tier and must be called before the engine is opened.
+func (e *Engine) SetColdTier(store ObjectStore) {
+	e.FileStore.WithColdTier(store)
+}
+
+// RelocateColdFiles moves fully compacted TSM files whose newest point is older
+// than the configured cold-after duration to the cold tier. It returns the
+// number of files that were moved.
+func (e *Engine) RelocateColdFiles(ctx context.Context) (int, error) {
+	span, ctx := tracing.StartSpanFromContext(ctx)
+	defer span.Finish()
+
+	before := time.Now().Add(-time.Duration(e.tierConfig.ColdAfter))
+	if len(e.FileStore.ColdCandidates(before)) == 0 {
+		return 0, nil
+	}
+
+	// Disable and abort running compactions so that the files being relocated
+	// are not rewritten underneath us. Snapshots continue so that writes are
+	// not rejected while files are copied.
+	e.disableLevelCompactions(true)
+	defer e.enableLevelCompactions(true)
+
+	return e.FileStore.RelocateColdFiles(ctx, before)
+}
+
+func (e *Engine) enableColdTierRelocation() {
+	e.mu.Lock()
+	if e.tierDone != nil {
+		e.mu.Unlock()
+		return
+	}
+
+	e.tierDone = make(chan struct{})
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	e.tierWG = wg
+	done := e.tierDone
+	e.mu.Unlock()
+
+	go func() { defer wg.Done(); e.relocateColdFiles(done) }()
+}
+
+func (e *Engine) disableColdTierRelocation() {
+	e.mu.Lock()
+	if e.tierDone == nil {
+		e.mu.Unlock()
+		return
+	}
+
+	close(e.tierDone)
+	wg := e.tierWG
+	e.tierDone, e.tierWG = nil, nil
+	e.mu.Unlock()
+
+	wg.Wait()
+}
+
+// relocateColdFiles periodically moves cold TSM files to the cold tier until
+// done is closed.
+func (e *Engine) relocateColdFiles(done chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	t := time.NewTicker(time.Duration(e.tierConfig.CheckInterval))
+	defer t.Stop()
+	for {
+		select {
+		case <-done:
+			return
+
+		case <-t.C:
+			n, err := e.RelocateColdFiles(ctx)
+			if err != nil && ctx.Err() == nil {
+				e.logger.Info("Error relocating files to cold tier", zap.Error(err))
+			} else if n > 0 {
+				e.logger.Info("Relocated files to cold tier", zap.Int("tsm1_files_n", n))
+			}
+		}
+	}
+}
@@ -223,12 +223,16 @@ type FileStore struct {
 	parseFileName ParseFileNameFunc
 
 	obs FileStoreObserver
+
+	cold        ObjectStore // If set, the tier that cold TSM files are relocated to.
+	coldTracker *coldTierTracker
 }
 
 // FileStat holds information about a TSM file on disk.
 type FileStat struct {
 	Path             string
 	HasTombstone     bool
+	Cold             bool // True if the file has been relocated to the cold tier.
 	Size             uint32
 	LastModified     int64
 	MinTime, MaxTime int64
@@ -265,6 +269,7 @@ func NewFileStore(dir string) *FileStore {
 		obs:           noFileStoreObserver{},
 		parseFileName: DefaultParseFileName,
 		tracker:       newFileTracker(newFileMetrics(nil), nil),
+		coldTracker:   newColdTierTracker(newColdTierMetrics(nil), nil),
 	}
 	fs.purger.fileStore = fs
 	return fs
@@ -732,6 +737,10 @@ func (f *FileStore) Open(ctx context.Context) error {
 	sort.Sort(tsmReaders(f.files))
 	f.tracker.SetBytes(sizes)
 	f.tracker.SetFileCount(counts)
+
+	if f.cold != nil {
+		return f.openColdFiles(ctx)
+	}
 	return nil
 }
 
@@ -1030,28 +1039,9 @@ func (f *FileStore) replace(oldFiles, newFiles []string, updatedFn func(r []TSMF
 	f.lastFileStats = nil
 	f.files = active
 	sort.Sort(tsmReaders(f.files))
-	f.tracker.ClearFileCounts()
-	f.tracker.ClearDiskSizes()
 
 	// Recalculate the disk size stat
-	sizes := make(map[int]uint64, 4)
-	counts := make(map[int]uint64, 4)
-	for _, file := range f.files {
-		size := uint64(file.Size())
-		for _, ts := range file.TombstoneFiles() {
-			size += uint64(ts.Size)
-		}
-		_, seq, err := f.parseFileName(file.Path())
-		if err != nil {
-			return err
-		}
-		sizes[seq] += size
-		counts[seq]++
-	}
-	f.tracker.SetBytes(sizes)
-	f.tracker.SetFileCount(counts)
-
-	return nil
+	return f.recalculateFileStats()
 }
 
 // LastModified returns the last time the file store was updated with new
@@ -1248,13 +1238,25 @@ func (f *FileStore) CreateSnapshot(ctx context.Context) (backupID int, backupDir
 		return 0, "", err
 	}
 	for _, tsmf := range files {
+		// Files in the cold tier may be on another volume or in an object
+		// store, so are copied if they cannot be linked.
+		cold := isColdFile(tsmf)
+
 		newpath := filepath.Join(backupDirFullPath, filepath.Base(tsmf.Path()))
-		if err := os.Link(tsmf.Path(), newpath); err != nil {
+		if cold {
+			if err := copyColdFile(tsmf.(*TSMReader), newpath); err != nil {
+				return 0, "", fmt.Errorf("error copying cold tsm file: %q", err)
+			}
+		} else if err := os.Link(tsmf.Path(), newpath); err != nil {
 			return 0, "", fmt.Errorf("error creating tsm hard link: %q", err)
 		}
 		for _, tf := range tsmf.TombstoneFiles() {
 			newpath := filepath.Join(backupDirFullPath, filepath.Base(tf.Path))
-			if err := os.Link(tf.Path, newpath); err != nil {
+			if err := os.Link(tf.Path, newpath); err != nil && cold {
+				if err := copyFile(tf.Path, newpath); err != nil {
+					return 0, "", fmt.Errorf("error copying cold tombstone file: %q", err)
+				}
+			} else if err != nil {
 				return 0, "", fmt.Errorf("error creating tombstone hard link: %q", err)
 			}
 		}
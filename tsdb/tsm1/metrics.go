@@ -26,6 +26,7 @@ func PrometheusCollectors() []prometheus.Collector {
 		collectors = append(collectors, bms.fileMetrics.PrometheusCollectors()...)
 		collectors = append(collectors, bms.cacheMetrics.PrometheusCollectors()...)
 		collectors = append(collectors, bms.readMetrics.PrometheusCollectors()...)
+		collectors = append(collectors, bms.coldTierMetrics.PrometheusCollectors()...)
 	}
 	return collectors
 }
@@ -37,6 +38,7 @@ const compactionSubsystem = "compactions" // sub-system associated with metrics
 const fileStoreSubsystem = "tsm_files"    // sub-system associated with metrics for TSM files.
 const cacheSubsystem = "cache"            // sub-system associated with metrics for the cache.
 const readSubsystem = "reads"             // sub-system associated with metrics for reads.
+const coldTierSubsystem = "tsm_cold_tier" // sub-system associated with metrics for the cold storage tier.
 
 // blockMetrics are a set of metrics concerned with tracking data about block storage.
 type blockMetrics struct {
@@ -45,6 +47,7 @@ type blockMetrics struct {
 	*fileMetrics
 	*cacheMetrics
 	*readMetrics
+	*coldTierMetrics
 }
 
 // newBlockMetrics initialises the prometheus metrics for the block subsystem.
@@ -55,6 +58,7 @@ func newBlockMetrics(labels prometheus.Labels) *blockMetrics {
 		fileMetrics:       newFileMetrics(labels),
 		cacheMetrics:      newCacheMetrics(labels),
 		readMetrics:       newReadMetrics(labels),
+		coldTierMetrics:   newColdTierMetrics(labels),
 	}
 }
 
@@ -65,6 +69,7 @@ func (m *blockMetrics) PrometheusCollectors() []prometheus.Collector {
 	metrics = append(metrics, m.fileMetrics.PrometheusCollectors()...)
 	metrics = append(metrics, m.cacheMetrics.PrometheusCollectors()...)
 	metrics = append(metrics, m.readMetrics.PrometheusCollectors()...)
+	metrics = append(metrics, m.coldTierMetrics.PrometheusCollectors()...)
 	return metrics
 }
 
@@ -288,3 +293,102 @@ func (m *readMetrics) PrometheusCollectors() []prometheus.Collector {
 		m.Seeks,
 	}
 }
+
+// coldTierMetrics are a set of metrics concerned with tracking TSM files that
+// have been relocated to the cold storage tier.
+type coldTierMetrics struct {
+	DiskSize *prometheus.GaugeVec
+	Files    *prometheus.GaugeVec
+
+	Reads     *prometheus.CounterVec
+	ReadBytes *prometheus.CounterVec
+
+	FetchDuration *prometheus.HistogramVec
+	IndexCache    *prometheus.CounterVec
+
+	// The following metrics include a ``"status" = {ok, error}` label
+	Fetches     *prometheus.CounterVec
+	Relocations *prometheus.CounterVec
+}
+
+// newColdTierMetrics initialises the prometheus metrics for the cold storage tier.
+func newColdTierMetrics(labels prometheus.Labels) *coldTierMetrics {
+	var names []string
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	statusNames := append(append([]string(nil), names...), "status")
+	sort.Strings(statusNames)
+
+	cacheNames := append(append([]string(nil), names...), "result")
+	sort.Strings(cacheNames)
+
+	return &coldTierMetrics{
+		DiskSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: coldTierSubsystem,
+			Name:      "bytes",
+			Help:      "Number of bytes used by TSM files in the cold tier.",
+		}, names),
+		Files: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: coldTierSubsystem,
+			Name:      "files",
+			Help:      "Number of TSM files in the cold tier.",
+		}, names),
+		Reads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: coldTierSubsystem,
+			Name:      "block_reads_total",
+			Help:      "Number of blocks read from TSM files in the cold tier.",
+		}, names),
+		ReadBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: coldTierSubsystem,
+			Name:      "block_read_bytes_total",
+			Help:      "Number of block bytes read from TSM files in the cold tier.",
+		}, names),
+		FetchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: coldTierSubsystem,
+			Name:      "fetch_duration_seconds",
+			Help:      "Time taken to fetch a byte range from the object store.",
+			// 12 buckets spaced exponentially between 1ms and ~4s.
+			Buckets: prometheus.ExponentialBuckets(0.001, 2, 12),
+		}, names),
+		IndexCache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: coldTierSubsystem,
+			Name:      "index_cache_total",
+			Help:      "Number of TSM index loads served from (hit) or missing (miss) the local index cache.",
+		}, cacheNames),
+		Fetches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: coldTierSubsystem,
+			Name:      "fetches_total",
+			Help:      "Number of byte range fetches from the object store.",
+		}, statusNames),
+		Relocations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: coldTierSubsystem,
+			Name:      "relocations_total",
+			Help:      "Number of TSM files moved to the cold tier.",
+		}, statusNames),
+	}
+}
+
+// PrometheusCollectors satisfies the prom.PrometheusCollector interface.
+func (m *coldTierMetrics) PrometheusCollectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.DiskSize,
+		m.Files,
+		m.Reads,
+		m.ReadBytes,
+		m.FetchDuration,
+		m.IndexCache,
+		m.Fetches,
+		m.Relocations,
+	}
+}
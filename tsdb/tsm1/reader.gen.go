@@ -15,6 +15,9 @@ func (t *TSMReader) ReadFloatBlockAt(entry *IndexEntry, vals *[]FloatValue) ([]F
 	t.mu.RLock()
 	v, err := t.accessor.readFloatBlock(entry, vals)
 	t.mu.RUnlock()
+	if t.cold != nil {
+		t.cold.AddRead(entry)
+	}
 	return v, err
 }
 
@@ -23,6 +26,9 @@ func (t *TSMReader) ReadFloatArrayBlockAt(entry *IndexEntry, vals *cursors.Float
 	t.mu.RLock()
 	err := t.accessor.readFloatArrayBlock(entry, vals)
 	t.mu.RUnlock()
+	if t.cold != nil {
+		t.cold.AddRead(entry)
+	}
 	return err
 }
 
@@ -31,6 +37,9 @@ func (t *TSMReader) ReadIntegerBlockAt(entry *IndexEntry, vals *[]IntegerValue)
 	t.mu.RLock()
 	v, err := t.accessor.readIntegerBlock(entry, vals)
 	t.mu.RUnlock()
+	if t.cold != nil {
+		t.cold.AddRead(entry)
+	}
 	return v, err
 }
 
@@ -39,6 +48,9 @@ func (t *TSMReader) ReadIntegerArrayBlockAt(entry *IndexEntry, vals *cursors.Int
 	t.mu.RLock()
 	err := t.accessor.readIntegerArrayBlock(entry, vals)
 	t.mu.RUnlock()
+	if t.cold != nil {
+		t.cold.AddRead(entry)
+	}
 	return err
 }
 
@@ -47,6 +59,9 @@ func (t *TSMReader) ReadUnsignedBlockAt(entry *IndexEntry, vals *[]UnsignedValue
 	t.mu.RLock()
 	v, err := t.accessor.readUnsignedBlock(entry, vals)
 	t.mu.RUnlock()
+	if t.cold != nil {
+		t.cold.AddRead(entry)
+	}
 	return v, err
 }
 
@@ -55,6 +70,9 @@ func (t *TSMReader) ReadUnsignedArrayBlockAt(entry *IndexEntry, vals *cursors.Un
 	t.mu.RLock()
 	err := t.accessor.readUnsignedArrayBlock(entry, vals)
 	t.mu.RUnlock()
+	if t.cold != nil {
+		t.cold.AddRead(entry)
+	}
 	return err
 }
 
@@ -63,6 +81,9 @@ func (t *TSMReader) ReadStringBlockAt(entry *IndexEntry, vals *[]StringValue) ([
 	t.mu.RLock()
 	v, err := t.accessor.readStringBlock(entry, vals)
 	t.mu.RUnlock()
+	if t.cold != nil {
+		t.cold.AddRead(entry)
+	}
 	return v, err
 }
 
@@ -71,6 +92,9 @@ func (t *TSMReader) ReadStringArrayBlockAt(entry *IndexEntry, vals *cursors.Stri
 	t.mu.RLock()
 	err := t.accessor.readStringArrayBlock(entry, vals)
 	t.mu.RUnlock()
+	if t.cold != nil {
+		t.cold.AddRead(entry)
+	}
 	return err
 }
 
@@ -79,6 +103,9 @@ func (t *TSMReader) ReadBooleanBlockAt(entry *IndexEntry, vals *[]BooleanValue)
 	t.mu.RLock()
 	v, err := t.accessor.readBooleanBlock(entry, vals)
 	t.mu.RUnlock()
+	if t.cold != nil {
+		t.cold.AddRead(entry)
+	}
 	return v, err
 }
 
@@ -87,6 +114,9 @@ func (t *TSMReader) ReadBooleanArrayBlockAt(entry *IndexEntry, vals *cursors.Boo
 	t.mu.RLock()
 	err := t.accessor.readBooleanArrayBlock(entry, vals)
 	t.mu.RUnlock()
+	if t.cold != nil {
+		t.cold.AddRead(entry)
+	}
 	return err
 }
 
@@ -283,3 +313,83 @@ func (m *mmapAccessor) readBooleanArrayBlock(entry *IndexEntry, values *cursors.
 
 	return err
 }
+
+func (o *objectAccessor) readFloatBlock(entry *IndexEntry, values *[]FloatValue) ([]FloatValue, error) {
+	b, err := o.block(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeFloatBlock(b, values)
+}
+
+func (o *objectAccessor) readFloatArrayBlock(entry *IndexEntry, values *cursors.FloatArray) error {
+	b, err := o.block(entry)
+	if err != nil {
+		return err
+	}
+	return DecodeFloatArrayBlock(b, values)
+}
+
+func (o *objectAccessor) readIntegerBlock(entry *IndexEntry, values *[]IntegerValue) ([]IntegerValue, error) {
+	b, err := o.block(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeIntegerBlock(b, values)
+}
+
+func (o *objectAccessor) readIntegerArrayBlock(entry *IndexEntry, values *cursors.IntegerArray) error {
+	b, err := o.block(entry)
+	if err != nil {
+		return err
+	}
+	return DecodeIntegerArrayBlock(b, values)
+}
+
+func (o *objectAccessor) readUnsignedBlock(entry *IndexEntry, values *[]UnsignedValue) ([]UnsignedValue, error) {
+	b, err := o.block(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeUnsignedBlock(b, values)
+}
+
+func (o *objectAccessor) readUnsignedArrayBlock(entry *IndexEntry, values *cursors.UnsignedArray) error {
+	b, err := o.block(entry)
+	if err != nil {
+		return err
+	}
+	return DecodeUnsignedArrayBlock(b, values)
+}
+
+func (o *objectAccessor) readStringBlock(entry *IndexEntry, values *[]StringValue) ([]StringValue, error) {
+	b, err := o.block(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeStringBlock(b, values)
+}
+
+func (o *objectAccessor) readStringArrayBlock(entry *IndexEntry, values *cursors.StringArray) error {
+	b, err := o.block(entry)
+	if err != nil {
+		return err
+	}
+	return DecodeStringArrayBlock(b, values)
+}
+
+func (o *objectAccessor) readBooleanBlock(entry *IndexEntry, values *[]BooleanValue) ([]BooleanValue, error) {
+	b, err := o.block(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBooleanBlock(b, values)
+}
+
+func (o *objectAccessor) readBooleanArrayBlock(entry *IndexEntry, values *cursors.BooleanArray) error {
+	b, err := o.block(entry)
+	if err != nil {
+		return err
+	}
+	return DecodeBooleanArrayBlock(b, values)
+}
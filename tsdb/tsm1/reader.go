@@ -40,6 +40,9 @@ type TSMReader struct {
 
 	// deleteMu limits concurrent deletes
 	deleteMu sync.Mutex
+
+	// cold tracks reads if the file has been relocated to the cold tier.
+	cold *coldTierTracker
 }
 
 type tsmReaderOption func(*TSMReader)
@@ -57,6 +60,14 @@ var WithTSMReaderLogger = func(logger *zap.Logger) tsmReaderOption {
 	}
 }
 
+// withColdTier marks the reader as belonging to the cold tier, recording reads
+// with the tracker.
+func withColdTier(tracker *coldTierTracker) tsmReaderOption {
+	return func(r *TSMReader) {
+		r.cold = tracker
+	}
+}
+
 // NewTSMReader returns a new TSMReader from the given file.
 func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 	t := &TSMReader{
@@ -78,19 +89,23 @@ func NewTSMReader(f *os.File, options ...tsmReaderOption) (*TSMReader, error) {
 		mmapWillNeed: t.madviseWillNeed,
 	}
 
+	if err := t.init(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// init loads the index and tombstones through the reader's accessor.
+func (t *TSMReader) init() error {
 	index, err := t.accessor.init()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	t.index = index
 	t.tombstoner = NewTombstoner(t.Path(), index.MaybeContainsKey)
 
-	if err := t.applyTombstones(); err != nil {
-		return nil, err
-	}
-
-	return t, nil
+	return t.applyTombstones()
 }
 
 // WithObserver sets the observer for the TSM reader.
@@ -169,6 +184,9 @@ func (t *TSMReader) ReadAt(entry *IndexEntry, vals []Value) ([]Value, error) {
 	t.mu.RLock()
 	v, err := t.accessor.readBlock(entry, vals)
 	t.mu.RUnlock()
+	if t.cold != nil {
+		t.cold.AddRead(entry)
+	}
 	return v, err
 }
 
@@ -286,6 +304,10 @@ func (t *TSMReader) remove() error {
 	if err := t.tombstoner.Delete(); err != nil {
 		return err
 	}
+
+	if o, ok := t.accessor.(*objectAccessor); ok {
+		return o.remove()
+	}
 	return nil
 }
 
@@ -463,6 +485,7 @@ func (t *TSMReader) Stats() FileStat {
 		MinKey:       minKey,
 		MaxKey:       maxKey,
 		HasTombstone: t.tombstoner.HasTombstones(),
+		Cold:         t.cold != nil,
 	}
 }
 
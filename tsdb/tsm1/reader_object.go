@@ -0,0 +1,328 @@
+package tsm1
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/pkg/fs"
+	"go.uber.org/zap"
+)
+
+// indexCacheExtension is the extension of the local copy of the index of a TSM
+// file held in an object store.
+const indexCacheExtension = "idx"
+
+// newObjectTSMReader returns a new TSMReader for the object described by info.
+// The reader is identified by the local path, alongside which the index cache
+// and tombstones are stored.
+func newObjectTSMReader(store ObjectStore, info ObjectInfo, path string, options ...tsmReaderOption) (*TSMReader, error) {
+	t := &TSMReader{
+		logger: zap.NewNop(),
+	}
+	for _, option := range options {
+		option(t)
+	}
+
+	t.size = info.Size
+	t.lastModified = info.ModTime.UnixNano()
+	t.accessor = &objectAccessor{
+		logger:  t.logger,
+		store:   store,
+		name:    info.Name,
+		size:    info.Size,
+		tracker: t.cold,
+		_path:   path,
+	}
+
+	if err := t.init(); err != nil {
+		t.accessor.close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// objectAccessor is a blockAccessor for TSM files held in an ObjectStore. The
+// index is cached on local disk and memory mapped, while blocks are fetched
+// from the store as they are read.
+type objectAccessor struct {
+	logger  *zap.Logger
+	store   ObjectStore
+	name    string // Name of the object in the store.
+	size    int64
+	tracker *coldTierTracker
+
+	mu     sync.RWMutex
+	b      []byte // The index, either memory mapped or held on the heap.
+	mapped bool
+	closed bool
+	_path  string // If the reader is renamed then this gets updated
+
+	index *indirectIndex
+}
+
+// ReadAt implements io.ReaderAt by fetching a byte range of the object.
+func (o *objectAccessor) ReadAt(p []byte, off int64) (int, error) {
+	start := time.Now()
+	n, err := o.store.ReadAt(context.Background(), o.name, p, off)
+	if o.tracker != nil {
+		o.tracker.Fetched(err, time.Since(start))
+	}
+	return n, err
+}
+
+func (o *objectAccessor) init() (*indirectIndex, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := verifyVersion(io.NewSectionReader(o, 0, o.size)); err != nil {
+		return nil, err
+	}
+
+	if o.size < 8 {
+		return nil, fmt.Errorf("objectAccessor: object too small for indirectIndex")
+	}
+
+	var footer [8]byte
+	if _, err := o.ReadAt(footer[:], o.size-8); err != nil {
+		return nil, err
+	}
+
+	indexOfsPos := uint64(o.size - 8)
+	indexStart := binary.BigEndian.Uint64(footer[:])
+	if indexStart >= indexOfsPos {
+		return nil, fmt.Errorf("objectAccessor: invalid indexStart")
+	}
+
+	if err := o.loadIndex(int64(indexStart), int64(indexOfsPos-indexStart)); err != nil {
+		return nil, err
+	}
+
+	o.index = NewIndirectIndex()
+	if err := o.index.UnmarshalBinary(o.b); err != nil {
+		return nil, err
+	}
+	o.index.logger = o.logger
+
+	return o.index, nil
+}
+
+// loadIndex loads the n byte index starting at off, preferring the local cache.
+func (o *objectAccessor) loadIndex(off, n int64) error {
+	path := o.indexCachePath()
+	if fi, err := os.Stat(path); err == nil && fi.Size() == n {
+		if err := o.mmapIndex(path); err == nil {
+			o.indexLoaded(true)
+			return nil
+		}
+	}
+	o.indexLoaded(false)
+
+	buf := make([]byte, n)
+	if _, err := o.ReadAt(buf, off); err != nil {
+		return err
+	}
+
+	// If the cache cannot be written then the index is held on the heap.
+	tmp := path + "." + TmpTSMFileExtension
+	if err := ioutil.WriteFile(tmp, buf, 0666); err != nil {
+		o.logger.Info("Unable to write index cache", zap.String("path", path), zap.Error(err))
+		o.b = buf
+		return nil
+	} else if err := fs.RenameFile(tmp, path); err != nil {
+		o.logger.Info("Unable to write index cache", zap.String("path", path), zap.Error(err))
+		o.b = buf
+		return nil
+	}
+
+	if err := o.mmapIndex(path); err != nil {
+		o.b = buf
+	}
+	return nil
+}
+
+func (o *objectAccessor) mmapIndex(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	b, err := mmap(f, 0, int(stat.Size()))
+	if err != nil {
+		return err
+	}
+	o.b, o.mapped = b, true
+	return nil
+}
+
+func (o *objectAccessor) indexLoaded(hit bool) {
+	if o.tracker != nil {
+		o.tracker.IndexLoaded(hit)
+	}
+}
+
+func (o *objectAccessor) indexCachePath() string {
+	return o._path + "." + indexCacheExtension
+}
+
+// fetch returns the raw block, including its checksum, for entry.
+func (o *objectAccessor) fetch(entry *IndexEntry) ([]byte, error) {
+	o.mu.RLock()
+	closed := o.closed
+	o.mu.RUnlock()
+	if closed {
+		return nil, ErrTSMClosed
+	}
+
+	if entry.Offset+int64(entry.Size) > o.size {
+		return nil, fmt.Errorf("objectAccessor: block exceeds object size")
+	}
+
+	b := make([]byte, entry.Size)
+	if _, err := o.ReadAt(b, entry.Offset); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// block returns the encoded block, after its 4 byte checksum, for entry.
+func (o *objectAccessor) block(entry *IndexEntry) ([]byte, error) {
+	b, err := o.fetch(entry)
+	if err != nil {
+		return nil, err
+	}
+	return b[4:], nil
+}
+
+func (o *objectAccessor) read(key []byte, timestamp int64) ([]Value, error) {
+	entry := o.index.Entry(key, timestamp)
+	if entry == nil {
+		return nil, nil
+	}
+
+	return o.readBlock(entry, nil)
+}
+
+func (o *objectAccessor) readBlock(entry *IndexEntry, values []Value) ([]Value, error) {
+	b, err := o.block(entry)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBlock(b, values)
+}
+
+func (o *objectAccessor) readBytes(entry *IndexEntry, _ []byte) (uint32, []byte, error) {
+	b, err := o.fetch(entry)
+	if err != nil {
+		return 0, nil, err
+	}
+	return binary.BigEndian.Uint32(b[:4]), b[4:], nil
+}
+
+// readAll returns all values for a key in all blocks.
+func (o *objectAccessor) readAll(key []byte) ([]Value, error) {
+	blocks, err := o.index.ReadEntries(key, nil)
+	if len(blocks) == 0 || err != nil {
+		return nil, err
+	}
+
+	tombstones := o.index.TombstoneRange(key, nil)
+
+	var temp []Value
+	var values []Value
+	for _, block := range blocks {
+		var skip bool
+		for _, t := range tombstones {
+			// Should we skip this block because it contains points that have been deleted
+			if t.Min <= block.MinTime && t.Max >= block.MaxTime {
+				skip = true
+				break
+			}
+		}
+
+		if skip {
+			continue
+		}
+
+		temp, err = o.readBlock(&block, temp[:0])
+		if err != nil {
+			return nil, err
+		}
+
+		// Filter out any values that were deleted
+		for _, t := range tombstones {
+			temp = Values(temp).Exclude(t.Min, t.Max)
+		}
+
+		values = append(values, temp...)
+	}
+
+	return values, nil
+}
+
+// rename changes the local path identifying the file. The object itself keeps
+// its name.
+func (o *objectAccessor) rename(path string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	old := o.indexCachePath()
+	o._path = path
+	if err := fs.RenameFileWithReplacement(old, o.indexCachePath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (o *objectAccessor) path() string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o._path
+}
+
+func (o *objectAccessor) close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+
+	if o.mapped {
+		if err := munmap(o.b); err != nil {
+			return err
+		}
+	}
+	o.b, o.mapped = nil, false
+	return nil
+}
+
+// free is a no-op, as blocks are not retained once read.
+func (o *objectAccessor) free() error { return nil }
+
+// remove deletes the object and its local index cache.
+func (o *objectAccessor) remove() error {
+	o.mu.RLock()
+	path := o.indexCachePath()
+	o.mu.RUnlock()
+
+	if err := o.store.Delete(context.Background(), o.name); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
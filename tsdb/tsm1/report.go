@@ -31,6 +31,7 @@ type Report struct {
 	Pattern         string       // Providing "01.tsm" for example would filter for level 1 files.
 	Detailed        bool         // Detailed will segment cardinality by tag keys.
 	Exact           bool         // Exact determines if estimation or exact methods are used to determine cardinality.
+	Codecs          bool         // Codecs reads every block to determine compression ratios per codec.
 }
 
 // ReportSummary provides a summary of the cardinalities in the processed fileset.
@@ -44,6 +45,25 @@ type ReportSummary struct {
 	Measurements map[string]uint64 // The exact or estimated unique set of series keys segmented by the measurement tag.
 	FieldKeys    map[string]uint64 // The exact or estimated unique set of series keys segmented by the field tag.
 	TagKeys      map[string]uint64 // The exact or estimated unique set of series keys segmented by tag keys.
+
+	// These are calculated when the codecs flag is in use.
+	Codecs map[string]*ReportCodecStats // Block statistics segmented by block type and codec, e.g. "string/zstd".
+}
+
+// ReportCodecStats summarises the blocks written with a codec.
+type ReportCodecStats struct {
+	Blocks   uint64
+	Points   uint64
+	Size     uint64 // The encoded size of the blocks.
+	Original uint64 // The uncompressed size of the timestamps and values.
+}
+
+// Ratio returns the compression ratio of the blocks.
+func (s *ReportCodecStats) Ratio() float64 {
+	if s.Size == 0 {
+		return 0
+	}
+	return float64(s.Original) / float64(s.Size)
 }
 
 func newReportSummary() *ReportSummary {
@@ -53,6 +73,7 @@ func newReportSummary() *ReportSummary {
 		Measurements:  map[string]uint64{},
 		FieldKeys:     map[string]uint64{},
 		TagKeys:       map[string]uint64{},
+		Codecs:        map[string]*ReportCodecStats{},
 	}
 }
 
@@ -95,6 +116,8 @@ func (r *Report) Run(print bool) (*ReportSummary, error) {
 	fCardinalities := map[string]counter{} // The exact or estimated unique set of series keys segmented by the field tag.
 	tCardinalities := map[string]counter{} // The exact or estimated unique set of series keys segmented by tag keys.
 
+	codecStats := map[string]*ReportCodecStats{} // Calculated when the codecs flag is in use.
+
 	start := time.Now()
 
 	tw := tabwriter.NewWriter(r.Stdout, 8, 2, 1, ' ', 0)
@@ -208,6 +231,13 @@ func (r *Report) Run(print bool) (*ReportSummary, error) {
 			}
 		}
 
+		if r.Codecs {
+			if err := r.addCodecStats(reader, codecStats); err != nil {
+				reader.Close()
+				return nil, fmt.Errorf("error: %s: %v. Exiting", path, err)
+			}
+		}
+
 		minT, maxT := reader.TimeRange()
 		if minT < minTime {
 			minTime = minT
@@ -296,6 +326,26 @@ func (r *Report) Run(print bool) (*ReportSummary, error) {
 		}
 	}
 
+	if r.Codecs {
+		fmt.Printf("\n  Compression By Codec (%d):\n", len(codecStats))
+		ctw := tabwriter.NewWriter(os.Stdout, 8, 2, 1, ' ', 0)
+		fmt.Fprintln(ctw, strings.Join([]string{"    Codec", "Blocks", "Points", "Size", "Ratio"}, "\t"))
+		for _, codec := range sortCodecKeys(codecStats) {
+			stats := codecStats[codec]
+			summary.Codecs[codec] = stats
+			fmt.Fprintln(ctw, strings.Join([]string{
+				"    - " + codec,
+				strconv.FormatUint(stats.Blocks, 10),
+				strconv.FormatUint(stats.Points, 10),
+				strconv.FormatUint(stats.Size, 10),
+				strconv.FormatFloat(stats.Ratio(), 'f', 2, 64),
+			}, "\t"))
+		}
+		if err := ctw.Flush(); err != nil {
+			return nil, err
+		}
+	}
+
 	fmt.Printf("\nCompleted in %s\n", time.Since(start))
 	return summary, nil
 }
@@ -310,6 +360,91 @@ func sortKeys(vals map[string]counter) (keys []string) {
 	return keys
 }
 
+// addCodecStats adds the statistics of every block in reader to stats.
+func (r *Report) addCodecStats(reader *TSMReader, stats map[string]*ReportCodecStats) error {
+	itr := reader.BlockIterator()
+	for itr.Next() {
+		key, _, _, typ, _, buf, err := itr.Read()
+		if err != nil {
+			return err
+		}
+
+		org, bucket := tsdb.DecodeNameSlice(key)
+		if r.OrgID != nil && *r.OrgID != org {
+			continue
+		} else if r.BucketID != nil && *r.BucketID != bucket {
+			continue
+		}
+
+		codec, err := BlockCodec(buf)
+		if err != nil {
+			return err
+		}
+
+		tb, vb, err := unpackBlock(buf[1:])
+		if err != nil {
+			return err
+		}
+		points := uint64(CountTimestamps(tb))
+
+		// Timestamps and numeric values are 8 bytes each, booleans 1 byte and
+		// strings their length prefixed size.
+		original := points * 8
+		switch typ {
+		case BlockBoolean:
+			original += points
+		case BlockString:
+			data, err := decompressStrings(vb)
+			if err != nil {
+				return err
+			}
+			original += uint64(len(data))
+		default:
+			original += points * 8
+		}
+
+		name := blockTypeName(typ) + "/" + codec
+		s := stats[name]
+		if s == nil {
+			s = &ReportCodecStats{}
+			stats[name] = s
+		}
+		s.Blocks++
+		s.Points += points
+		s.Size += uint64(len(buf))
+		s.Original += original
+	}
+	return itr.Err()
+}
+
+// blockTypeName returns the name of the TSM block type.
+func blockTypeName(typ byte) string {
+	switch typ {
+	case BlockFloat64:
+		return "float"
+	case BlockInteger:
+		return "integer"
+	case BlockUnsigned:
+		return "unsigned"
+	case BlockBoolean:
+		return "boolean"
+	case BlockString:
+		return "string"
+	default:
+		return "unknown"
+	}
+}
+
+// sortCodecKeys returns the sorted set of a map's keys.
+func sortCodecKeys(vals map[string]*ReportCodecStats) (keys []string) {
+	for k := range vals {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 // counter abstracts a a method of counting keys.
 type counter interface {
 	Add(key []byte)
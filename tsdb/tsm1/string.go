@@ -68,15 +68,10 @@ type StringDecoder struct {
 // SetBytes initializes the decoder with bytes to read from.
 // This must be called before calling any other method.
 func (e *StringDecoder) SetBytes(b []byte) error {
-	// First byte stores the encoding type, only have snappy format
-	// currently so ignore for now.
-	var data []byte
-	if len(b) > 0 {
-		var err error
-		data, err = snappy.Decode(nil, b[1:])
-		if err != nil {
-			return fmt.Errorf("failed to decode string block: %v", err.Error())
-		}
+	// First byte stores the encoding type, see StringCodec.
+	data, err := decompressStrings(b)
+	if err != nil {
+		return err
 	}
 
 	e.b = data
@@ -0,0 +1,731 @@
+package tsm1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/pkg/fs"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// ColdTierDirName is the name of the directory, within the engine path, that
+// holds the index cache and tombstones of TSM files stored in an object store.
+const ColdTierDirName = "_cold"
+
+// ErrObjectNotFound is returned by an ObjectStore when an object does not exist.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectInfo describes an object held by an ObjectStore.
+type ObjectInfo struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// ObjectStore is a flat namespace of immutable objects used as the cold tier
+// for TSM files.
+type ObjectStore interface {
+	// Put stores size bytes read from r as the object name, replacing any
+	// existing object. The object must not be visible until it is complete.
+	Put(ctx context.Context, name string, r io.Reader, size int64) error
+
+	// ReadAt reads len(p) bytes of the object name starting at offset off.
+	ReadAt(ctx context.Context, name string, p []byte, off int64) (int, error)
+
+	// Stat returns the ObjectInfo for the object name.
+	Stat(ctx context.Context, name string) (ObjectInfo, error)
+
+	// Delete removes the object name. Deleting a missing object is not an error.
+	Delete(ctx context.Context, name string) error
+
+	// List returns all objects in the store.
+	List(ctx context.Context) ([]ObjectInfo, error)
+}
+
+// localObjectStore is implemented by object stores which keep objects as
+// regular files, allowing TSM files to be memory mapped in place.
+type localObjectStore interface {
+	Path(name string) string
+}
+
+// NewObjectStore returns the ObjectStore described by the configuration, or
+// nil if no cold tier is configured.
+func NewObjectStore(c TierConfig) (ObjectStore, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c.Dir != "":
+		return NewDirObjectStore(c.Dir), nil
+	case c.S3.Endpoint != "":
+		return NewS3ObjectStore(c.S3)
+	}
+	return nil, nil
+}
+
+// DirObjectStore is an ObjectStore backed by a directory, which is usually on
+// a different volume to the engine path.
+type DirObjectStore struct {
+	dir string
+}
+
+// NewDirObjectStore returns a new DirObjectStore rooted at dir.
+func NewDirObjectStore(dir string) *DirObjectStore {
+	return &DirObjectStore{dir: dir}
+}
+
+// Path returns the path of the file holding the object name.
+func (s *DirObjectStore) Path(name string) string {
+	return filepath.Join(s.dir, name)
+}
+
+// Put writes the object to a temporary file and renames it into place once
+// it has been synced.
+func (s *DirObjectStore) Put(ctx context.Context, name string, r io.Reader, size int64) error {
+	if err := os.MkdirAll(s.dir, 0777); err != nil {
+		return err
+	}
+
+	tmp := s.Path(name) + "." + TmpTSMFileExtension
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	if n, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	} else if n != size {
+		f.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("short write for object %s: wrote %d of %d bytes", name, n, size)
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := fs.RenameFile(tmp, s.Path(name)); err != nil {
+		return err
+	}
+	return fs.SyncDir(s.dir)
+}
+
+// ReadAt reads from the file holding the object name.
+func (s *DirObjectStore) ReadAt(ctx context.Context, name string, p []byte, off int64) (int, error) {
+	f, err := os.Open(s.Path(name))
+	if os.IsNotExist(err) {
+		return 0, ErrObjectNotFound
+	} else if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return f.ReadAt(p, off)
+}
+
+// Stat returns the size and modification time of the file holding the object name.
+func (s *DirObjectStore) Stat(ctx context.Context, name string) (ObjectInfo, error) {
+	fi, err := os.Stat(s.Path(name))
+	if os.IsNotExist(err) {
+		return ObjectInfo{}, ErrObjectNotFound
+	} else if err != nil {
+		return ObjectInfo{}, err
+	}
+	return ObjectInfo{Name: name, Size: fi.Size(), ModTime: fi.ModTime()}, nil
+}
+
+// Delete removes the file holding the object name.
+func (s *DirObjectStore) Delete(ctx context.Context, name string) error {
+	if err := os.Remove(s.Path(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// List returns all complete objects in the directory.
+func (s *DirObjectStore) List(ctx context.Context) ([]ObjectInfo, error) {
+	fis, err := ioutil.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	objects := make([]ObjectInfo, 0, len(fis))
+	for _, fi := range fis {
+		if fi.IsDir() || strings.HasSuffix(fi.Name(), "."+TmpTSMFileExtension) {
+			continue
+		}
+		objects = append(objects, ObjectInfo{Name: fi.Name(), Size: fi.Size(), ModTime: fi.ModTime()})
+	}
+	return objects, nil
+}
+
+// coldTierTracker tracks the files and reads of the cold storage tier.
+//
+// As well as being responsible for providing atomic reads and writes to the
+// statistics, coldTierTracker also mirrors any changes to the external
+// prometheus metrics, which the Engine exposes.
+type coldTierTracker struct {
+	metrics   *coldTierMetrics
+	labels    prometheus.Labels
+	reads     uint64
+	readBytes uint64
+}
+
+func newColdTierTracker(metrics *coldTierMetrics, defaultLabels prometheus.Labels) *coldTierTracker {
+	return &coldTierTracker{metrics: metrics, labels: defaultLabels}
+}
+
+// Labels returns a copy of the default labels used by the tracker's metrics.
+// The returned map is safe for modification.
+func (t *coldTierTracker) Labels() prometheus.Labels {
+	labels := make(prometheus.Labels, len(t.labels))
+	for k, v := range t.labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// Reads returns the number of blocks read from cold files.
+func (t *coldTierTracker) Reads() uint64 { return atomic.LoadUint64(&t.reads) }
+
+// ReadBytes returns the number of block bytes read from cold files.
+func (t *coldTierTracker) ReadBytes() uint64 { return atomic.LoadUint64(&t.readBytes) }
+
+// SetFiles sets the number and total size of the files in the cold tier.
+func (t *coldTierTracker) SetFiles(n int, bytes uint64) {
+	labels := t.Labels()
+	t.metrics.Files.With(labels).Set(float64(n))
+	t.metrics.DiskSize.With(labels).Set(float64(bytes))
+}
+
+// AddRead records a block read from a cold file.
+func (t *coldTierTracker) AddRead(entry *IndexEntry) {
+	atomic.AddUint64(&t.reads, 1)
+	atomic.AddUint64(&t.readBytes, uint64(entry.Size))
+
+	labels := t.Labels()
+	t.metrics.Reads.With(labels).Inc()
+	t.metrics.ReadBytes.With(labels).Add(float64(entry.Size))
+}
+
+// Fetched records a byte range fetched from the object store.
+func (t *coldTierTracker) Fetched(err error, duration time.Duration) {
+	labels := t.Labels()
+	if err == nil {
+		t.metrics.FetchDuration.With(labels).Observe(duration.Seconds())
+		labels["status"] = "ok"
+	} else {
+		labels["status"] = "error"
+	}
+	t.metrics.Fetches.With(labels).Inc()
+}
+
+// IndexLoaded records whether a TSM index was loaded from the local cache.
+func (t *coldTierTracker) IndexLoaded(hit bool) {
+	labels := t.Labels()
+	labels["result"] = "miss"
+	if hit {
+		labels["result"] = "hit"
+	}
+	t.metrics.IndexCache.With(labels).Inc()
+}
+
+// Relocated records an attempt to move a file to the cold tier.
+func (t *coldTierTracker) Relocated(success bool) {
+	labels := t.Labels()
+	labels["status"] = "ok"
+	if !success {
+		labels["status"] = "error"
+	}
+	t.metrics.Relocations.With(labels).Inc()
+}
+
+// WithColdTier sets the object store that cold TSM files are relocated to and
+// read from. It must be called before Open.
+func (f *FileStore) WithColdTier(store ObjectStore) {
+	f.cold = store
+}
+
+// isColdFile returns true if the file has been relocated to the cold tier.
+func isColdFile(file TSMFile) bool {
+	r, ok := file.(*TSMReader)
+	return ok && r.cold != nil
+}
+
+// coldPath returns the local path that identifies the cold file name. For
+// directory backed stores this is the file itself, otherwise it is a path in
+// the local cold tier directory, which holds the index cache and tombstones.
+func (f *FileStore) coldPath(name string) string {
+	if s, ok := f.cold.(localObjectStore); ok {
+		return s.Path(name)
+	}
+	return filepath.Join(f.dir, ColdTierDirName, name)
+}
+
+// openColdReader opens a reader for the cold file described by info.
+func (f *FileStore) openColdReader(ctx context.Context, info ObjectInfo) (*TSMReader, error) {
+	options := []tsmReaderOption{
+		WithTSMReaderLogger(f.logger),
+		withColdTier(f.coldTracker),
+	}
+
+	if s, ok := f.cold.(localObjectStore); ok {
+		file, err := os.Open(s.Path(info.Name))
+		if err != nil {
+			return nil, err
+		}
+		r, err := NewTSMReader(file, append(options, WithMadviseWillNeed(f.tsmMMAPWillNeed))...)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		r.WithObserver(f.obs)
+		return r, nil
+	}
+
+	if err := os.MkdirAll(filepath.Join(f.dir, ColdTierDirName), 0777); err != nil {
+		return nil, err
+	}
+	r, err := newObjectTSMReader(f.cold, info, f.coldPath(info.Name), options...)
+	if err != nil {
+		return nil, err
+	}
+	r.WithObserver(f.obs)
+	return r, nil
+}
+
+// openColdFiles loads the files in the cold tier. It assumes the write lock is
+// held and that the hot files have already been loaded.
+func (f *FileStore) openColdFiles(ctx context.Context) error {
+	objects, err := f.cold.List(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot list cold tier: %v", err)
+	}
+
+	hot := make(map[string]struct{}, len(f.files))
+	for _, file := range f.files {
+		hot[filepath.Base(file.Path())] = struct{}{}
+	}
+
+	cold := make(map[string]struct{}, len(objects))
+	for _, info := range objects {
+		if filepath.Ext(info.Name) != "."+TSMFileExtension {
+			continue
+		}
+
+		// A file present in both tiers was not fully relocated before the
+		// process exited. The hot copy is authoritative.
+		if _, ok := hot[info.Name]; ok {
+			f.logger.Info("Removing incompletely relocated cold file", zap.String("name", info.Name))
+			if err := f.cold.Delete(ctx, info.Name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		generation, _, err := f.parseFileName(info.Name)
+		if err != nil {
+			return err
+		}
+		if f.currentGenerationFunc == nil && generation >= f.currentGeneration {
+			f.currentGeneration = generation + 1
+		}
+
+		f.openLimiter.Take()
+		start := time.Now()
+		r, err := f.openColdReader(ctx, info)
+		f.openLimiter.Release()
+		if err != nil {
+			return fmt.Errorf("cannot open cold file %s: %v", info.Name, err)
+		}
+		f.logger.Info("Opened cold file",
+			zap.String("path", r.Path()),
+			zap.Duration("duration", time.Since(start)))
+
+		cold[info.Name] = struct{}{}
+		f.files = append(f.files, r)
+	}
+
+	// Remove index caches of objects that no longer exist.
+	if _, ok := f.cold.(localObjectStore); !ok {
+		caches, err := filepath.Glob(filepath.Join(f.dir, ColdTierDirName, "*."+indexCacheExtension))
+		if err != nil {
+			return err
+		}
+		for _, path := range caches {
+			name := strings.TrimSuffix(filepath.Base(path), "."+indexCacheExtension)
+			if _, ok := cold[name]; !ok {
+				if err := os.Remove(path); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	sort.Sort(tsmReaders(f.files))
+	f.updateColdTracker()
+	return nil
+}
+
+// updateColdTracker recalculates the cold tier file statistics. It assumes the
+// lock is held.
+func (f *FileStore) updateColdTracker() {
+	var n int
+	var size uint64
+	for _, file := range f.files {
+		if !isColdFile(file) {
+			continue
+		}
+		n++
+		size += uint64(file.Size())
+	}
+	f.coldTracker.SetFiles(n, size)
+}
+
+// ColdCandidates returns the paths of the fully compacted TSM files which only
+// hold data older than before and are not yet in the cold tier.
+func (f *FileStore) ColdCandidates(before time.Time) []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.cold == nil {
+		return nil
+	}
+
+	var paths []string
+	for _, file := range f.files {
+		if isColdFile(file) {
+			continue
+		}
+		if _, seq, err := f.parseFileName(file.Path()); err != nil || seq < 4 {
+			continue
+		}
+		if _, maxTime := file.TimeRange(); maxTime >= before.UnixNano() {
+			continue
+		}
+		paths = append(paths, file.Path())
+	}
+	return paths
+}
+
+// RelocateColdFiles moves the files returned by ColdCandidates to the cold tier.
+// Files remain readable throughout. Callers must ensure the files are not being
+// compacted. It returns the number of files that were moved.
+func (f *FileStore) RelocateColdFiles(ctx context.Context, before time.Time) (int, error) {
+	var n int
+	for _, path := range f.ColdCandidates(before) {
+		if err := ctx.Err(); err != nil {
+			return n, err
+		}
+
+		r := f.TSMReader(path)
+		if r == nil {
+			continue // Removed by a concurrent operation.
+		}
+
+		start := time.Now()
+		err := f.relocate(ctx, r)
+		f.coldTracker.Relocated(err == nil)
+		if err != nil {
+			return n, fmt.Errorf("cannot relocate %s to cold tier: %v", path, err)
+		}
+		f.logger.Info("Relocated file to cold tier",
+			zap.String("path", path),
+			zap.Duration("duration", time.Since(start)))
+		n++
+	}
+	return n, nil
+}
+
+// relocate copies r to the cold tier and swaps the cold copy into the file
+// store. The reference held on r is released.
+func (f *FileStore) relocate(ctx context.Context, r *TSMReader) error {
+	name := filepath.Base(r.Path())
+	dst := f.coldPath(name)
+
+	if err := f.uploadFile(ctx, r.Path(), name); err != nil {
+		r.Unref()
+		return err
+	}
+
+	// Copy the tombstones and statistics alongside the cold file, holding the
+	// delete lock so no tombstone is written part way through.
+	r.deleteMu.Lock()
+	tombstones := r.TombstoneFiles()
+	err := copySidecarFiles(r.Path(), tombstones, dst)
+	r.deleteMu.Unlock()
+	if err != nil {
+		r.Unref()
+		return err
+	}
+
+	info, err := f.cold.Stat(ctx, name)
+	if err != nil {
+		r.Unref()
+		return err
+	}
+	cold, err := f.openColdReader(ctx, info)
+	if err != nil {
+		r.Unref()
+		return err
+	}
+
+	f.mu.Lock()
+	found := false
+	for i, file := range f.files {
+		if file == TSMFile(r) {
+			f.files[i] = cold
+			found = true
+			break
+		}
+	}
+	if !found {
+		f.mu.Unlock()
+		r.Unref()
+		cold.Close()
+		return fmt.Errorf("file %s was removed during relocation", r.Path())
+	}
+	// The file store changed, so ensure the planner notices.
+	f.lastModified = f.lastModified.UTC().Add(1)
+	f.lastFileStats = nil
+	err = f.recalculateFileStats()
+	f.mu.Unlock()
+	if err != nil {
+		r.Unref()
+		return err
+	}
+
+	// Deletes which began before the swap may have added tombstones to the
+	// hot file after they were copied. Replay them against the cold file.
+	r.deleteMu.Lock()
+	err = replayTombstones(r, tombstones, cold)
+	r.deleteMu.Unlock()
+	r.Unref()
+	if err != nil {
+		return err
+	}
+
+	if err := f.obs.FileUnlinking(r.Path()); err != nil {
+		return err
+	}
+	for _, t := range r.TombstoneFiles() {
+		if err := f.obs.FileUnlinking(t.Path); err != nil {
+			return err
+		}
+	}
+
+	// Remove the hot file. If queries are still using it, move it out of the
+	// way first so that it is not opened as a hot file after a restart, which
+	// would discard the cold copy and any tombstones written to it since.
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !r.InUse() {
+		if err := r.Close(); err != nil {
+			return err
+		}
+		return r.Remove()
+	}
+
+	deletes := []string{StatsFilename(r.Path())}
+	for _, t := range r.TombstoneFiles() {
+		deletes = append(deletes, t.Path)
+	}
+	if err := r.Rename(fmt.Sprintf("%s.%s", r.Path(), TmpTSMFileExtension)); err != nil {
+		return err
+	}
+	for _, path := range deletes {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	f.purger.add([]TSMFile{r})
+	return nil
+}
+
+// uploadFile copies the local file at path to the cold tier as name.
+func (f *FileStore) uploadFile(ctx context.Context, path, name string) error {
+	fd, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	fi, err := fd.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := f.cold.Put(ctx, name, fd, fi.Size()); err != nil {
+		return err
+	}
+
+	// Preserve the modification time so the planner's view of the file store
+	// does not change.
+	if s, ok := f.cold.(localObjectStore); ok {
+		return os.Chtimes(s.Path(name), fi.ModTime(), fi.ModTime())
+	}
+	return nil
+}
+
+// recalculateFileStats recalculates the hot and cold file statistics. It
+// assumes the write lock is held.
+func (f *FileStore) recalculateFileStats() error {
+	f.tracker.ClearFileCounts()
+	f.tracker.ClearDiskSizes()
+
+	sizes := make(map[int]uint64, 4)
+	counts := make(map[int]uint64, 4)
+	for _, file := range f.files {
+		if isColdFile(file) {
+			continue
+		}
+		size := uint64(file.Size())
+		for _, ts := range file.TombstoneFiles() {
+			size += uint64(ts.Size)
+		}
+		_, seq, err := f.parseFileName(file.Path())
+		if err != nil {
+			return err
+		}
+		sizes[seq] += size
+		counts[seq]++
+	}
+	f.tracker.SetBytes(sizes)
+	f.tracker.SetFileCount(counts)
+
+	if f.cold != nil {
+		f.updateColdTracker()
+	}
+	return nil
+}
+
+// copySidecarFiles copies the tombstone and statistics files of the TSM file
+// at src to sit alongside the TSM file at dst.
+func copySidecarFiles(src string, tombstones []FileStat, dst string) error {
+	dir := filepath.Dir(dst)
+	for _, t := range tombstones {
+		if err := copyFile(t.Path, filepath.Join(dir, filepath.Base(t.Path))); err != nil {
+			return err
+		}
+	}
+
+	stats := StatsFilename(src)
+	if _, err := os.Stat(stats); os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return copyFile(stats, StatsFilename(dst))
+}
+
+// replayTombstones applies the tombstones written to src since the snapshot
+// of its tombstone files was taken to dst.
+func replayTombstones(src *TSMReader, snapshot []FileStat, dst *TSMReader) error {
+	var before, after int64
+	for _, t := range snapshot {
+		before += int64(t.Size)
+	}
+	for _, t := range src.TombstoneFiles() {
+		after += int64(t.Size)
+	}
+	if before == after {
+		return nil
+	}
+
+	// Tombstones are idempotent, so replaying all of them is safe.
+	return src.tombstoner.Walk(func(ts Tombstone) error {
+		if ts.Prefix {
+			pred, err := UnmarshalPredicate(ts.Predicate)
+			if err != nil {
+				return err
+			}
+			return dst.DeletePrefix(ts.Key, ts.Min, ts.Max, pred, nil)
+		}
+		key := append([]byte(nil), ts.Key...)
+		return dst.DeleteRange([][]byte{key}, ts.Min, ts.Max)
+	})
+}
+
+// copyColdFile writes a local copy of the cold file r to dst.
+func copyColdFile(r *TSMReader, dst string) error {
+	o, ok := r.accessor.(*objectAccessor)
+	if !ok {
+		if err := os.Link(r.Path(), dst); err == nil {
+			return nil
+		}
+		return copyFile(r.Path(), dst)
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	// Fetch large ranges to limit the number of requests made to the store.
+	buf := make([]byte, 4<<20)
+	for off := int64(0); off < o.size; {
+		n := int64(len(buf))
+		if rem := o.size - off; rem < n {
+			n = rem
+		}
+		if _, err := o.ReadAt(buf[:n], off); err != nil {
+			out.Close()
+			return err
+		}
+		if _, err := out.Write(buf[:n]); err != nil {
+			out.Close()
+			return err
+		}
+		off += n
+	}
+
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// copyFile copies the file at src to dst, syncing it to disk.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0666)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
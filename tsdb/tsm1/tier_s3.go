@@ -0,0 +1,366 @@
+package tsm1
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultS3Region is the region used to sign requests if none is configured.
+	DefaultS3Region = "us-east-1"
+
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3TimeFormat      = "20060102T150405Z"
+)
+
+// S3ObjectStore is an ObjectStore backed by a bucket of an S3-compatible
+// object store. Requests use path-style addressing and, when credentials are
+// configured, are signed with AWS Signature Version 4.
+type S3ObjectStore struct {
+	endpoint *url.URL
+	config   S3Config
+
+	Client *http.Client
+	now    func() time.Time
+}
+
+// NewS3ObjectStore returns a new S3ObjectStore for the configured bucket.
+func NewS3ObjectStore(c S3Config) (*S3ObjectStore, error) {
+	u, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %v", err)
+	} else if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint: %q", c.Endpoint)
+	}
+	if c.Region == "" {
+		c.Region = DefaultS3Region
+	}
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = DefaultS3DialTimeout
+	}
+	if c.ResponseTimeout <= 0 {
+		c.ResponseTimeout = DefaultS3ResponseTimeout
+	}
+	if c.RequestTimeout <= 0 {
+		c.RequestTimeout = DefaultS3RequestTimeout
+	}
+
+	return &S3ObjectStore{
+		endpoint: u,
+		config:   c,
+		Client:   newS3Client(c),
+		now:      time.Now,
+	}, nil
+}
+
+// newS3Client returns an http.Client with the timeouts of the configuration,
+// so a stalled object store cannot block relocating or reading files forever.
+func newS3Client(c S3Config) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   time.Duration(c.DialTimeout),
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   time.Duration(c.DialTimeout),
+			ResponseHeaderTimeout: time.Duration(c.ResponseTimeout),
+			ExpectContinueTimeout: time.Second,
+			IdleConnTimeout:       90 * time.Second,
+			MaxIdleConns:          16,
+		},
+		Timeout: time.Duration(c.RequestTimeout),
+	}
+}
+
+// Put uploads the object in a single request.
+func (s *S3ObjectStore) Put(ctx context.Context, name string, r io.Reader, size int64) error {
+	req, err := s.newRequest(ctx, http.MethodPut, s.key(name), nil, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// ReadAt fetches the byte range [off, off+len(p)) of the object.
+func (s *S3ObjectStore) ReadAt(ctx context.Context, name string, p []byte, off int64) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	req, err := s.newRequest(ctx, http.MethodGet, s.key(name), nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+
+	resp, err := s.do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	n, err := io.ReadFull(resp.Body, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Stat issues a HEAD request for the object.
+func (s *S3ObjectStore) Stat(ctx context.Context, name string) (ObjectInfo, error) {
+	req, err := s.newRequest(ctx, http.MethodHead, s.key(name), nil, nil)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	resp.Body.Close()
+
+	info := ObjectInfo{Name: name, Size: resp.ContentLength}
+	if lm := resp.Header.Get("Last-Modified"); lm != "" {
+		if t, err := http.ParseTime(lm); err == nil {
+			info.ModTime = t
+		}
+	}
+	return info, nil
+}
+
+// Delete removes the object.
+func (s *S3ObjectStore) Delete(ctx context.Context, name string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, s.key(name), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req)
+	if err == ErrObjectNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// s3ListResult is the response of a ListObjectsV2 request.
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns all objects under the configured prefix.
+func (s *S3ObjectStore) List(ctx context.Context) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	var token string
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {s.config.Prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		req, err := s.newRequest(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := s.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid s3 list response: %v", err)
+		}
+
+		for _, c := range result.Contents {
+			objects = append(objects, ObjectInfo{
+				Name:    strings.TrimPrefix(c.Key, s.config.Prefix),
+				Size:    c.Size,
+				ModTime: c.LastModified,
+			})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *S3ObjectStore) key(name string) string {
+	return s.config.Prefix + name
+}
+
+// newRequest returns a signed request for key in the configured bucket.
+func (s *S3ObjectStore) newRequest(ctx context.Context, method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.config.Bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawQuery = s3EncodeQuery(query)
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	s.sign(req)
+	return req, nil
+}
+
+// do executes the request, converting unsuccessful responses into errors.
+func (s *S3ObjectStore) do(req *http.Request) (*http.Response, error) {
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrObjectNotFound
+	}
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, bytes.TrimSpace(msg))
+}
+
+// sign adds an AWS Signature Version 4 authorization header to the request.
+// The payload is not included in the signature.
+func (s *S3ObjectStore) sign(req *http.Request) {
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+	if s.config.AccessKeyID == "" {
+		return
+	}
+
+	now := s.now().UTC()
+	amzDate := now.Format(s3TimeFormat)
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": s3UnsignedPayload,
+		"x-amz-date":           amzDate,
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + strings.TrimSpace(headers[k]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EncodePath(req.URL.Path),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		s3UnsignedPayload,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := s3HMAC([]byte("AWS4"+s.config.SecretAccessKey), date)
+	key = s3HMAC(key, s.config.Region)
+	key = s3HMAC(key, "s3")
+	key = s3HMAC(key, "aws4_request")
+	signature := hex.EncodeToString(s3HMAC(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3EncodeQuery encodes the query parameters sorted by key, as required for
+// the canonical request.
+func s3EncodeQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, s3Escape(k, true)+"="+s3Escape(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3EncodePath URI encodes every segment of the path.
+func s3EncodePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return s3Escape(path, false)
+}
+
+// s3Escape percent-encodes every byte of s other than the unreserved
+// characters, and '/' unless encodeSlash is set.
+func s3Escape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,315 @@
+package tsm1_test
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/influxdb/v2/pkg/fs"
+	"github.com/influxdata/influxdb/v2/toml"
+	"github.com/influxdata/influxdb/v2/tsdb/tsm1"
+)
+
+func TestFileStore_RelocateColdFiles_Dir(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+	coldDir := MustTempDir()
+	defer os.RemoveAll(coldDir)
+
+	testFileStoreRelocateColdFiles(t, dir, func() tsm1.ObjectStore {
+		return tsm1.NewDirObjectStore(coldDir)
+	})
+}
+
+func TestFileStore_RelocateColdFiles_S3(t *testing.T) {
+	dir := MustTempDir()
+	defer os.RemoveAll(dir)
+
+	s3 := newFakeS3()
+	srv := httptest.NewServer(s3)
+	defer srv.Close()
+
+	testFileStoreRelocateColdFiles(t, dir, func() tsm1.ObjectStore {
+		store, err := tsm1.NewS3ObjectStore(tsm1.S3Config{
+			Endpoint:        srv.URL,
+			Bucket:          "bucket",
+			Prefix:          "shard/",
+			AccessKeyID:     "id",
+			SecretAccessKey: "secret",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return store
+	})
+
+	if got := s3.count(); got != 1 {
+		t.Fatalf("object count mismatch: got %v, exp %v", got, 1)
+	}
+}
+
+func TestS3ObjectStore_ResponseTimeout(t *testing.T) {
+	// An object store that never responds.
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	store, err := tsm1.NewS3ObjectStore(tsm1.S3Config{
+		Endpoint:        srv.URL,
+		Bucket:          "bucket",
+		ResponseTimeout: toml.Duration(50 * time.Millisecond),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := store.Stat(context.Background(), "000000001-000000001.tsm")
+		errc <- err
+	}()
+
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Fatal("expected timeout error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("request to a stalled object store did not time out")
+	}
+}
+
+func testFileStoreRelocateColdFiles(t *testing.T, dir string, newStore func() tsm1.ObjectStore) {
+	t.Helper()
+
+	// A fully compacted file holding old data and a level 1 file.
+	if _, err := newCompactedFile(dir, 1, 4, keyValues{"cpu", []tsm1.Value{tsm1.NewValue(0, 1.0), tsm1.NewValue(1, 2.0)}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newCompactedFile(dir, 2, 1, keyValues{"cpu", []tsm1.Value{tsm1.NewValue(2, 3.0)}}); err != nil {
+		t.Fatal(err)
+	}
+
+	fstore := tsm1.NewFileStore(dir)
+	fstore.WithColdTier(newStore())
+	if err := fstore.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	before := time.Unix(0, 100)
+	if got := fstore.ColdCandidates(before); len(got) != 1 {
+		t.Fatalf("candidate count mismatch: got %v, exp %v", len(got), 1)
+	}
+
+	n, err := fstore.RelocateColdFiles(context.Background(), before)
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("relocated count mismatch: got %v, exp %v", n, 1)
+	}
+
+	if got := fstore.ColdCandidates(before); len(got) != 0 {
+		t.Fatalf("candidate count mismatch: got %v, exp %v", len(got), 0)
+	}
+
+	var cold int
+	for _, stat := range fstore.Stats() {
+		if stat.Cold {
+			cold++
+		}
+	}
+	if cold != 1 {
+		t.Fatalf("cold file count mismatch: got %v, exp %v", cold, 1)
+	}
+
+	// Reads span the hot and cold tiers transparently.
+	checkFileStoreValues(t, fstore, []float64{1.0, 2.0, 3.0})
+
+	// Deletes apply to cold files and survive a restart.
+	if err := fstore.DeleteRange([][]byte{[]byte("cpu")}, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	checkFileStoreValues(t, fstore, []float64{2.0, 3.0})
+
+	if err := fstore.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fstore = tsm1.NewFileStore(dir)
+	fstore.WithColdTier(newStore())
+	if err := fstore.Open(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer fstore.Close()
+
+	if got, exp := fstore.Count(), 2; got != exp {
+		t.Fatalf("file count mismatch: got %v, exp %v", got, exp)
+	}
+	checkFileStoreValues(t, fstore, []float64{2.0, 3.0})
+
+	// Snapshots hold a local copy of cold files.
+	_, snapshot, err := fstore.CreateSnapshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := filepath.Glob(filepath.Join(snapshot, "*."+tsm1.TSMFileExtension))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) != 2 {
+		t.Fatalf("snapshot file count mismatch: got %v, exp %v", len(files), 2)
+	}
+}
+
+func checkFileStoreValues(t *testing.T, fstore *tsm1.FileStore, exp []float64) {
+	t.Helper()
+
+	buf := make([]tsm1.FloatValue, 1000)
+	c := fstore.KeyCursor(context.Background(), []byte("cpu"), 0, true)
+	defer c.Close()
+
+	var got []float64
+	for {
+		values, err := c.ReadFloatBlock(&buf)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(values) == 0 {
+			break
+		}
+		for _, v := range values {
+			got = append(got, v.Value().(float64))
+		}
+		c.Next()
+	}
+
+	if fmt.Sprint(got) != fmt.Sprint(exp) {
+		t.Fatalf("values mismatch: got %v, exp %v", got, exp)
+	}
+}
+
+// newCompactedFile writes a TSM file with the given generation and sequence.
+func newCompactedFile(dir string, gen, seq int, v keyValues) (string, error) {
+	f := MustTempFile(dir)
+	w, err := tsm1.NewTSMWriter(f)
+	if err != nil {
+		return "", err
+	}
+
+	if err := w.Write([]byte(v.key), v.values); err != nil {
+		return "", err
+	}
+
+	if err := w.WriteIndex(); err != nil {
+		return "", err
+	}
+
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	name := filepath.Join(dir, tsm1.DefaultFormatFileName(gen, seq)+"."+tsm1.TSMFileExtension)
+	return name, fs.RenameFile(f.Name(), name)
+}
+
+// fakeS3 is an in-memory stand-in for the subset of the S3 API used by
+// S3ObjectStore.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: make(map[string][]byte)}
+}
+
+func (s *fakeS3) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.objects)
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=id/") {
+		http.Error(w, "unsigned request", http.StatusForbidden)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/bucket")
+	key = strings.TrimPrefix(key, "/")
+
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		var keys []string
+		for k := range s.objects {
+			if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		type content struct {
+			Key  string
+			Size int64
+		}
+		var result struct {
+			XMLName  xml.Name `xml:"ListBucketResult"`
+			Contents []content
+		}
+		for _, k := range keys {
+			result.Contents = append(result.Contents, content{Key: k, Size: int64(len(s.objects[k]))})
+		}
+		xml.NewEncoder(w).Encode(result)
+
+	case r.Method == http.MethodPut:
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.objects[key] = b
+
+	case r.Method == http.MethodHead:
+		b, ok := s.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(b)))
+
+	case r.Method == http.MethodGet:
+		b, ok := s.objects[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		var start, end int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil || end >= len(b) {
+			http.Error(w, "invalid range", http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(b[start : end+1])
+
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		http.Error(w, "unsupported", http.StatusMethodNotAllowed)
+	}
+}